@@ -0,0 +1,124 @@
+package agent
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/shirou/gopsutil/v3/cpu"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/pkg/logger"
+)
+
+const (
+	defaultAdaptiveCPUThreshold = 90
+	defaultAdaptiveSlowRounds   = 3
+	defaultAdaptiveMaxFactor    = 4
+	hostCPUSampleInterval       = 5 * time.Second
+)
+
+var adaptiveIntervalFactor = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "adaptive_interval_factor",
+	Help: "How many times the interval of the input is stretched because of high load.",
+}, []string{"plugin"})
+
+func init() {
+	prometheus.MustRegister(adaptiveIntervalFactor)
+}
+
+var (
+	// the cpu usage of host in percent, as float64 bits
+	hostCPUUsage     uint64
+	hostCPUUsageOnce sync.Once
+)
+
+func sampleHostCPU() {
+	for {
+		if ps, err := cpu.Percent(0, false); err == nil && len(ps) > 0 {
+			atomic.StoreUint64(&hostCPUUsage, math.Float64bits(ps[0]))
+		}
+		time.Sleep(hostCPUSampleInterval)
+	}
+}
+
+// adaptiveInterval stretches the interval of an input while the load is high, see config.AdaptiveInterval
+type adaptiveInterval struct {
+	cpuThreshold float64
+	slowRounds   int
+	maxFactor    int
+
+	plugin string
+	log    *logger.Logger
+	factor int
+	slow   int
+	fast   int
+}
+
+// newAdaptiveInterval returns nil if the interval of input is not adaptive
+func newAdaptiveInterval(plugin string, priority config.Priority, log *logger.Logger) *adaptiveInterval {
+	conf := config.Config.AdaptiveInterval
+	if conf == nil || !conf.Enable || priority != config.PriorityLow {
+		return nil
+	}
+
+	a := &adaptiveInterval{
+		cpuThreshold: conf.CPUThreshold,
+		slowRounds:   conf.SlowRounds,
+		maxFactor:    conf.MaxFactor,
+		plugin:       plugin,
+		log:          log,
+		factor:       1,
+	}
+
+	if a.cpuThreshold <= 0 {
+		a.cpuThreshold = defaultAdaptiveCPUThreshold
+	}
+	if a.slowRounds <= 0 {
+		a.slowRounds = defaultAdaptiveSlowRounds
+	}
+	if a.maxFactor <= 1 {
+		a.maxFactor = defaultAdaptiveMaxFactor
+	}
+
+	hostCPUUsageOnce.Do(func() { go sampleHostCPU() })
+	adaptiveIntervalFactor.WithLabelValues(plugin).Set(1)
+	return a
+}
+
+// next returns the interval of the next round, after a gathering took elapsed
+func (a *adaptiveInterval) next(interval, elapsed time.Duration) time.Duration {
+	usage := math.Float64frombits(atomic.LoadUint64(&hostCPUUsage))
+	if elapsed > interval || usage >= a.cpuThreshold {
+		a.slow++
+		a.fast = 0
+	} else {
+		a.fast++
+		a.slow = 0
+	}
+
+	factor := a.factor
+	switch {
+	case a.slow >= a.slowRounds && a.factor < a.maxFactor:
+		factor = a.factor * 2
+		a.slow = 0
+	case a.fast >= a.slowRounds && a.factor > 1:
+		factor = a.factor / 2
+		a.fast = 0
+	}
+
+	if factor > a.maxFactor {
+		factor = a.maxFactor
+	}
+
+	if factor != a.factor {
+		a.log.With("elapsed", elapsed, "cpu_usage", usage).Infof("interval changed from %s to %s",
+			interval*time.Duration(a.factor), interval*time.Duration(factor))
+		a.factor = factor
+		adaptiveIntervalFactor.WithLabelValues(a.plugin).Set(float64(factor))
+	}
+
+	return interval * time.Duration(a.factor)
+}
@@ -2,9 +2,13 @@ package agent
 
 import (
 	"errors"
-	"log"
+
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/pkg/state"
 )
 
+var agentLog = logger.New("agent")
+
 type Agent struct {
 	agents []AgentModule
 }
@@ -36,38 +40,39 @@ func NewAgent() (*Agent, error) {
 }
 
 func (a *Agent) Start() {
-	log.Println("I! agent starting")
+	agentLog.Infof("agent starting")
 	for _, agent := range a.agents {
 		if agent == nil {
 			continue
 		}
 		if err := agent.Start(); err != nil {
-			log.Printf("E! start [%T] err: [%+v]", agent, err)
+			agentLog.Errorf("start [%T] err: [%+v]", agent, err)
 		} else {
-			log.Printf("I! [%T] started", agent)
+			agentLog.Infof("[%T] started", agent)
 		}
 	}
-	log.Println("I! agent started")
+	agentLog.Infof("agent started")
 }
 
 func (a *Agent) Stop() {
-	log.Println("I! agent stopping")
+	agentLog.Infof("agent stopping")
 	for _, agent := range a.agents {
 		if agent == nil {
 			continue
 		}
 		if err := agent.Stop(); err != nil {
-			log.Printf("E! stop [%T] err: [%+v]", agent, err)
+			agentLog.Errorf("stop [%T] err: [%+v]", agent, err)
 		} else {
-			log.Printf("I! [%T] stopped", agent)
+			agentLog.Infof("[%T] stopped", agent)
 		}
 	}
-	log.Println("I! agent stopped")
+	state.Flush()
+	agentLog.Infof("agent stopped")
 }
 
 func (a *Agent) Reload() {
-	log.Println("I! agent reloading")
+	agentLog.Infof("agent reloading")
 	a.Stop()
 	a.Start()
-	log.Println("I! agent reloaded")
+	agentLog.Infof("agent reloaded")
 }
@@ -0,0 +1,111 @@
+package agent
+
+import (
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"flashcat.cloud/categraf/pkg/conv"
+	"flashcat.cloud/categraf/types"
+)
+
+var (
+	circuitBreakerOpen = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "circuit_breaker_open",
+		Help: "Whether the circuit breaker of the input instance is open, gathers are skipped when open.",
+	}, []string{"plugin", "instance"})
+
+	circuitBreakerTrips = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "circuit_breaker_trips_total",
+		Help: "Number of times the circuit breaker of the input instance tripped.",
+	}, []string{"plugin", "instance"})
+)
+
+func init() {
+	prometheus.MustRegister(circuitBreakerOpen, circuitBreakerTrips)
+}
+
+// circuitBreaker stops gathering a failing instance for a while after threshold consecutive failures,
+// the waiting time starts from the instance interval and doubles on every failed retry, up to maxBackoff
+type circuitBreaker struct {
+	threshold  int
+	maxBackoff time.Duration
+
+	failures  int
+	backoff   time.Duration
+	openUntil time.Time
+}
+
+func newCircuitBreaker(threshold int, maxBackoff time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold:  threshold,
+		maxBackoff: maxBackoff,
+	}
+}
+
+func (cb *circuitBreaker) enabled() bool {
+	return cb != nil && cb.threshold > 0
+}
+
+func (cb *circuitBreaker) isOpen() bool {
+	return cb.failures >= cb.threshold
+}
+
+// allow reports whether the instance should be gathered now
+func (cb *circuitBreaker) allow(now time.Time) bool {
+	if !cb.enabled() || !cb.isOpen() {
+		return true
+	}
+	// half open, let one gather through to probe the target
+	return !now.Before(cb.openUntil)
+}
+
+// record updates the state with the result of a gather, returns true if the breaker opened or closed
+func (cb *circuitBreaker) record(failed bool, interval time.Duration, now time.Time) bool {
+	if !cb.enabled() {
+		return false
+	}
+
+	if !failed {
+		wasOpen := cb.isOpen()
+		cb.failures = 0
+		cb.backoff = 0
+		return wasOpen
+	}
+
+	cb.failures++
+	if !cb.isOpen() {
+		return false
+	}
+
+	if cb.backoff == 0 {
+		cb.backoff = interval
+	} else {
+		cb.backoff *= 2
+	}
+	if cb.maxBackoff > 0 && cb.backoff > cb.maxBackoff {
+		cb.backoff = cb.maxBackoff
+	}
+	cb.openUntil = now.Add(cb.backoff)
+
+	return cb.failures == cb.threshold
+}
+
+// gatherFailed follows the convention of inputs: the gather is treated as failed
+// if up samples (up or *_up) are reported and all of them are 0
+func gatherFailed(slist *types.SampleList) bool {
+	ups, downs := 0, 0
+	slist.Range(func(s *types.Sample) bool {
+		if s == nil || (s.Metric != "up" && !strings.HasSuffix(s.Metric, "_up")) {
+			return true
+		}
+		ups++
+		if v, err := conv.ToFloat64(s.Value); err == nil && v == 0 {
+			downs++
+			return true
+		}
+		return false
+	})
+	return ups > 0 && ups == downs
+}
@@ -0,0 +1,442 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/toolkits/pkg/file"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/filter"
+)
+
+// ConfigProblem is found by CheckConfigs, Line is 0 if unknown
+type ConfigProblem struct {
+	File    string
+	Line    int
+	Message string
+	// the warnings do not fail the check, e.g. the inputs not built in
+	Warning bool
+}
+
+func (p ConfigProblem) String() string {
+	level := "E!"
+	if p.Warning {
+		level = "W!"
+	}
+
+	if p.Line > 0 {
+		return fmt.Sprintf("%s %s:%d: %s", level, p.File, p.Line, p.Message)
+	}
+	return fmt.Sprintf("%s %s: %s", level, p.File, p.Message)
+}
+
+type configChecker struct {
+	problems []ConfigProblem
+}
+
+func (c *configChecker) add(file string, line int, format string, args ...interface{}) {
+	c.problems = append(c.problems, ConfigProblem{File: file, Line: line, Message: fmt.Sprintf(format, args...)})
+}
+
+func (c *configChecker) warn(file string, line int, format string, args ...interface{}) {
+	c.problems = append(c.problems, ConfigProblem{File: file, Line: line, Message: fmt.Sprintf(format, args...), Warning: true})
+}
+
+// CheckConfigs loads the toml files of the config dir and the input dirs one by one, the ones of the overlays too, checks
+// unknown keys, the files referenced, the regular expressions and globs, and the internal configs
+// of inputs, the inputs are not initialized so that nothing is connected
+func CheckConfigs(configDir string) []ConfigProblem {
+	c := &configChecker{}
+
+	if !file.IsExist(configDir) {
+		c.add(configDir, 0, "config dir not found")
+		return c.problems
+	}
+	for _, layer := range config.Layers(configDir, "") {
+		c.checkDir(layer, func() interface{} { return &config.ConfigType{} })
+	}
+
+	for _, p := range c.problems {
+		if !p.Warning {
+			return c.problems
+		}
+	}
+
+	// the global labels and hostname are used by the labels of inputs
+	if err := config.InitConfig(configDir, false, false, 0, ""); err != nil {
+		c.add(path.Join(configDir, "config.toml"), 0, "%v", err)
+		return c.problems
+	}
+
+	for _, p := range config.Config.Global.Providers {
+		if name := strings.ToLower(p); name != "local" && name != "http" {
+			c.add(path.Join(configDir, "config.toml"), 0, "unknown provider: %s", p)
+		}
+	}
+
+	dirs, err := config.DirsUnderLayers(configDir)
+	if err != nil {
+		c.add(configDir, 0, "%v", err)
+		return c.problems
+	}
+
+	for _, dir := range dirs {
+		if !strings.HasPrefix(dir, "input.") {
+			continue
+		}
+
+		inputKey := strings.TrimPrefix(dir, "input.")
+		creator, has := inputs.InputCreators[inputKey]
+		if !has {
+			// e.g. the inputs of build tags, and the examples to be renamed
+			c.warn(path.Join(configDir, dir), 0, "input %s is not supported by this build, ignored", inputKey)
+			continue
+		}
+
+		for _, layer := range config.Layers(configDir, dir) {
+			c.checkDir(layer, func() interface{} { return creator() })
+		}
+	}
+
+	return c.problems
+}
+
+// checkDir checks the toml files of dir one by one, the dirs of overlays not existing are skipped
+func (c *configChecker) checkDir(dir string, ptr func() interface{}) {
+	if !file.IsExist(dir) {
+		return
+	}
+
+	files, err := file.FilesUnder(dir)
+	if err != nil {
+		c.add(dir, 0, "failed to list files: %v", err)
+		return
+	}
+
+	for _, f := range files {
+		if strings.HasSuffix(f, ".toml") {
+			c.checkFile(path.Join(dir, f), ptr())
+		}
+	}
+}
+
+// RunConfigCheck prints the problems found by CheckConfigs, and returns the exit code
+func RunConfigCheck(configDir string, w io.Writer) int {
+	problems := CheckConfigs(configDir)
+	sortProblems(problems)
+
+	errs := 0
+	for _, p := range problems {
+		fmt.Fprintln(w, p.String())
+		if !p.Warning {
+			errs++
+		}
+	}
+
+	if errs > 0 {
+		fmt.Fprintf(w, "%d errors found in %s\n", errs, configDir)
+		return 1
+	}
+
+	fmt.Fprintf(w, "configs in %s are ok\n", configDir)
+	return 0
+}
+
+var (
+	errorLineRegexp   = regexp.MustCompile(`line (\d+)`)
+	errorPrefixRegexp = regexp.MustCompile(`^toml: line \d+( \(last key "[^"]*"\))?: `)
+)
+
+func (c *configChecker) checkFile(fpath string, ptr interface{}) {
+	bs, err := os.ReadFile(fpath)
+	if err != nil {
+		c.add(fpath, 0, "%v", err)
+		return
+	}
+
+	md, err := toml.Decode(string(bs), ptr)
+	if err != nil {
+		var perr toml.ParseError
+		if errors.As(err, &perr) {
+			c.add(fpath, perr.Position.Line, "%s", errorPrefixRegexp.ReplaceAllString(perr.Error(), ""))
+			return
+		}
+
+		line := 0
+		if m := errorLineRegexp.FindStringSubmatch(err.Error()); m != nil {
+			line, _ = strconv.Atoi(m[1])
+		}
+		c.add(fpath, line, "%v", err)
+		return
+	}
+
+	lines := indexLines(string(bs))
+
+	// the keys of array tables are listed once for every table
+	undecoded := make(map[string]int)
+	for _, key := range md.Undecoded() {
+		k := key.String()
+		undecoded[k]++
+
+		// the keys in an unknown table are not reported again
+		if i := strings.LastIndex(k, "."); i > 0 {
+			if _, has := undecoded[k[:i]]; has {
+				continue
+			}
+		}
+		c.add(fpath, lines.nth(k, undecoded[k]-1), "unknown key: %s", k)
+	}
+
+	w := &fieldWalker{checker: c, file: fpath, lines: lines}
+	w.walk(reflect.ValueOf(ptr), "", true, 0)
+}
+
+type internalConfig interface {
+	InitInternalConfig() error
+}
+
+type fieldWalker struct {
+	checker *configChecker
+	file    string
+	lines   lineIndex
+}
+
+// the options are rarely nested deeper, and the fields of runtime are not walked into
+const maxWalkDepth = 8
+
+// walk checks the fields of v by their toml names, path is the toml path of v, e.g. instances[0]
+func (w *fieldWalker) walk(v reflect.Value, path string, initInternal bool, depth int) {
+	if depth > maxWalkDepth {
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			w.walk(v.Elem(), path, initInternal, depth)
+		}
+		return
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			w.walk(v.Index(i), fmt.Sprintf("%s[%d]", path, i), true, depth+1)
+		}
+		return
+	case reflect.Struct:
+	default:
+		return
+	}
+
+	if initInternal && v.CanAddr() {
+		if ic, ok := v.Addr().Interface().(internalConfig); ok {
+			if err := ic.InitInternalConfig(); err != nil {
+				w.checker.add(w.file, w.lines.find(path), "%s: %v", strings.TrimPrefix(path, "."), err)
+			}
+		}
+	}
+
+	// the files of disabled sections are not required, e.g. [prometheus] enable = false
+	if enable := v.FieldByName("Enable"); enable.IsValid() && enable.Kind() == reflect.Bool && !enable.Bool() {
+		return
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+
+		name := strings.Split(field.Tag.Get("toml"), ",")[0]
+		if name == "-" {
+			continue
+		}
+
+		if field.Anonymous && name == "" {
+			w.walk(v.Field(i), path, false, depth+1)
+			continue
+		}
+
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+
+		fieldPath := path + "." + name
+		fv := v.Field(i)
+		if values, ok := stringValues(fv); ok {
+			w.check(fieldPath, name, values)
+			continue
+		}
+		w.walk(fv, fieldPath, true, depth+1)
+	}
+}
+
+func stringValues(v reflect.Value) ([]string, bool) {
+	switch {
+	case v.Kind() == reflect.String:
+		if v.String() == "" {
+			return nil, true
+		}
+		return []string{v.String()}, true
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.String:
+		values := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			values = append(values, v.Index(i).String())
+		}
+		return values, true
+	}
+	return nil, false
+}
+
+// check the values of field name by the naming conventions of options
+func (w *fieldWalker) check(fieldPath, name string, values []string) {
+	if len(values) == 0 {
+		return
+	}
+
+	line := w.lines.find(fieldPath)
+	key := strings.TrimPrefix(fieldPath, ".")
+
+	switch {
+	case strings.HasSuffix(name, "regex") || strings.HasSuffix(name, "regexp") || strings.HasSuffix(name, "pattern"):
+		for _, s := range values {
+			if _, err := regexp.Compile(s); err != nil {
+				w.checker.add(w.file, line, "%s: invalid regular expression %q: %v", key, s, err)
+			}
+		}
+	case strings.HasSuffix(name, "include") || strings.HasSuffix(name, "exclude"):
+		if _, err := filter.Compile(values); err != nil {
+			w.checker.add(w.file, line, "%s: invalid glob %q: %v", key, values, err)
+		}
+	case name == "tls_ca" || name == "tls_cert" || name == "tls_key" || name == "tls_allowed_cacerts" ||
+		strings.HasSuffix(name, "_file"):
+		for _, s := range values {
+			// the paths of globs and urls are not files
+			if filter.HasMeta(s) || strings.Contains(s, "://") {
+				continue
+			}
+			if _, err := os.Stat(s); err != nil {
+				w.checker.add(w.file, line, "%s: %v", key, err)
+			}
+		}
+	}
+}
+
+// lineIndex maps the toml paths of tables and keys to their lines, e.g.
+// instances[1].labels, and instances.labels for all of the array tables
+type lineIndex map[string][]int
+
+var arrayIndexRegexp = regexp.MustCompile(`\[\d+\]`)
+
+func indexLines(data string) lineIndex {
+	index := make(lineIndex)
+	record := func(p string, line int) {
+		index[p] = append(index[p], line)
+		if plain := arrayIndexRegexp.ReplaceAllString(p, ""); plain != p {
+			index[plain] = append(index[plain], line)
+		}
+	}
+
+	// the counts of array tables, by the path of the parent with indexes
+	counts := make(map[string]int)
+	// the current indexes of array tables, by the path without indexes
+	current := make(map[string]string)
+	table := ""
+
+	for i, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			array := strings.HasPrefix(line, "[[")
+			end := strings.Index(line, "]")
+			if end < 0 {
+				continue
+			}
+
+			name := strings.Trim(line[:end], "[ ")
+			segments := strings.Split(name, ".")
+			p := ""
+			for j, seg := range segments {
+				plain := strings.Join(segments[:j+1], ".")
+				seg = strings.Trim(strings.TrimSpace(seg), `"'`)
+				if j == len(segments)-1 && array {
+					n := counts[p+"."+seg]
+					counts[p+"."+seg]++
+					current[plain] = fmt.Sprintf("%s.%s[%d]", p, seg, n)
+					p = current[plain]
+				} else if cur, has := current[plain]; has {
+					p = cur
+				} else {
+					p += "." + seg
+				}
+			}
+
+			table = p
+			record(table, i+1)
+			continue
+		}
+
+		eq := strings.Index(line, "=")
+		if eq <= 0 {
+			continue
+		}
+
+		key := strings.Trim(strings.TrimSpace(line[:eq]), `"'`)
+		if strings.ContainsAny(key, ` "',[]{}`) {
+			continue
+		}
+		record(table+"."+key, i+1)
+	}
+	return index
+}
+
+// find returns the line of the toml path, or its closest parent found
+func (idx lineIndex) find(p string) int {
+	return idx.nth(p, 0)
+}
+
+// nth returns the line of the nth occurrence of the toml path
+func (idx lineIndex) nth(p string, n int) int {
+	if !strings.HasPrefix(p, ".") {
+		p = "." + p
+	}
+
+	for p != "" {
+		if lines := idx[p]; len(lines) > 0 {
+			if n < len(lines) {
+				return lines[n]
+			}
+			return lines[0]
+		}
+
+		i := strings.LastIndexAny(p, ".[")
+		if i < 0 {
+			break
+		}
+		p = p[:i]
+	}
+	return 0
+}
+
+// sortProblems orders the problems by file and line
+func sortProblems(problems []ConfigProblem) {
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].File != problems[j].File {
+			return problems[i].File < problems[j].File
+		}
+		return problems[i].Line < problems[j].Line
+	})
+}
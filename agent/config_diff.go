@@ -0,0 +1,452 @@
+package agent
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/toolkits/pkg/file"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/cfg"
+)
+
+var (
+	diffSecretKeyRegexp   = regexp.MustCompile(`(?i)(passw(or)?d|_pass$|^pass$|pwd|secret|token|api_?key|access_?key|private_?key|credential|community)`)
+	diffSecretValueRegexp = regexp.MustCompile(`(?i)(authorization|bearer |basic |api-key|x-auth)`)
+	diffIndexRegexp       = regexp.MustCompile(`\[\d+\]`)
+
+	durationType     = reflect.TypeOf(config.Duration(0))
+	timeDurationType = reflect.TypeOf(time.Duration(0))
+)
+
+const inputFilePrefix = "input."
+
+// ConfigChange is found by DiffConfigs, Op is one of + (added), - (removed), ~ (changed) and ! (newly filtered)
+type ConfigChange struct {
+	Op      string
+	Target  string
+	Message string
+}
+
+func (c ConfigChange) String() string {
+	if c.Message == "" {
+		return c.Op + " " + c.Target
+	}
+	return c.Op + " " + c.Target + ": " + c.Message
+}
+
+type configTree struct {
+	dir    string
+	global *config.ConfigType
+	// input key to the input loaded, nil if the input is not supported by this build
+	inputs map[string]inputs.Input
+}
+
+// DiffConfigs loads the config trees of oldDir and newDir, and returns the inputs and instances which would be
+// added, removed or changed, and the metrics which would newly be dropped, the inputs are not initialized
+func DiffConfigs(oldDir, newDir string) ([]ConfigChange, error) {
+	oldTree, err := loadConfigTree(oldDir)
+	if err != nil {
+		return nil, err
+	}
+	newTree, err := loadConfigTree(newDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []ConfigChange
+	changes = append(changes, diffFields("config", flattenConfig(oldTree.global), flattenConfig(newTree.global))...)
+	changes = append(changes, diffFilters("config", reflect.ValueOf(oldTree.global), reflect.ValueOf(newTree.global))...)
+
+	keys := make(map[string]struct{}, len(oldTree.inputs)+len(newTree.inputs))
+	for k := range oldTree.inputs {
+		keys[k] = struct{}{}
+	}
+	for k := range newTree.inputs {
+		keys[k] = struct{}{}
+	}
+
+	names := make([]string, 0, len(keys))
+	for k := range keys {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		target := inputFilePrefix + name
+		oldIn, inOld := oldTree.inputs[name]
+		newIn, inNew := newTree.inputs[name]
+
+		switch {
+		case !inOld:
+			changes = append(changes, ConfigChange{Op: "+", Target: target})
+		case !inNew:
+			changes = append(changes, ConfigChange{Op: "-", Target: target})
+		case oldIn == nil || newIn == nil:
+			// not supported by this build, the files are compared as they are
+			if !sameInputFiles(path.Join(oldDir, target), path.Join(newDir, target)) {
+				changes = append(changes, ConfigChange{Op: "~", Target: target, Message: "files changed, input not supported by this build"})
+			}
+		default:
+			changes = append(changes, diffInput(target, oldIn, newIn)...)
+		}
+	}
+
+	return changes, nil
+}
+
+// RunConfigDiff prints the changes found by DiffConfigs, exits 1 if there's any change like diff(1), 2 on errors
+func RunConfigDiff(oldDir, newDir string, w io.Writer) int {
+	changes, err := DiffConfigs(oldDir, newDir)
+	if err != nil {
+		fmt.Fprintln(w, "E!", err)
+		return 2
+	}
+
+	for _, c := range changes {
+		fmt.Fprintln(w, c.String())
+	}
+
+	if len(changes) > 0 {
+		fmt.Fprintf(w, "%d changes found from %s to %s\n", len(changes), oldDir, newDir)
+		return 1
+	}
+
+	fmt.Fprintf(w, "no changes from %s to %s\n", oldDir, newDir)
+	return 0
+}
+
+func loadConfigTree(dir string) (*configTree, error) {
+	if !file.IsExist(path.Join(dir, "config.toml")) {
+		return nil, fmt.Errorf("configuration file(%s) not found", path.Join(dir, "config.toml"))
+	}
+
+	t := &configTree{dir: dir, global: &config.ConfigType{}, inputs: make(map[string]inputs.Input)}
+	// the overlays are merged onto both of the trees
+	if err := cfg.LoadConfigByDirs(config.Layers(dir, ""), t.global); err != nil {
+		return nil, fmt.Errorf("failed to load configs of dir: %s err:%s", dir, err)
+	}
+
+	dirs, err := config.DirsUnderLayers(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, d := range dirs {
+		if !strings.HasPrefix(d, inputFilePrefix) {
+			continue
+		}
+
+		inputKey := strings.TrimPrefix(d, inputFilePrefix)
+		creator, has := inputs.InputCreators[inputKey]
+		if !has {
+			t.inputs[inputKey] = nil
+			continue
+		}
+
+		input := creator()
+		if err := cfg.LoadConfigByDirs(config.Layers(dir, d), input); err != nil {
+			return nil, fmt.Errorf("failed to load configs of dir: %s err:%s", path.Join(dir, d), err)
+		}
+		t.inputs[inputKey] = input
+	}
+
+	return t, nil
+}
+
+func diffInput(target string, oldIn, newIn inputs.Input) []ConfigChange {
+	oldFields, newFields := flattenConfig(oldIn), flattenConfig(newIn)
+
+	// the fields of plugin level, the instances are compared one by one
+	oldPlugin, newPlugin := make(map[string]string), make(map[string]string)
+	for k, v := range oldFields {
+		if !strings.HasPrefix(k, "instances[") {
+			oldPlugin[k] = v
+		}
+	}
+	for k, v := range newFields {
+		if !strings.HasPrefix(k, "instances[") {
+			newPlugin[k] = v
+		}
+	}
+
+	var changes []ConfigChange
+	changes = append(changes, diffFields(target, oldPlugin, newPlugin)...)
+	changes = append(changes, diffFilters(target, reflect.ValueOf(oldIn), reflect.ValueOf(newIn))...)
+
+	oldInstances, newInstances := inputs.MayGetInstances(oldIn), inputs.MayGetInstances(newIn)
+	for i := 0; i < len(oldInstances) || i < len(newInstances); i++ {
+		insTarget := fmt.Sprintf("%s instances[%d]", target, i)
+		switch {
+		case i >= len(oldInstances):
+			changes = append(changes, ConfigChange{Op: "+", Target: insTarget})
+		case i >= len(newInstances):
+			changes = append(changes, ConfigChange{Op: "-", Target: insTarget})
+		default:
+			changes = append(changes, diffFields(insTarget, flattenConfig(oldInstances[i]), flattenConfig(newInstances[i]))...)
+			changes = append(changes, diffFilters(insTarget, reflect.ValueOf(oldInstances[i]), reflect.ValueOf(newInstances[i]))...)
+		}
+	}
+
+	return changes
+}
+
+// diffFields returns the changed fields, one change for all of them
+func diffFields(target string, oldFields, newFields map[string]string) []ConfigChange {
+	keys := make([]string, 0, len(newFields))
+	for k, v := range newFields {
+		if old, has := oldFields[k]; !has || old != v {
+			keys = append(keys, k)
+		}
+	}
+	for k := range oldFields {
+		if _, has := newFields[k]; !has {
+			keys = append(keys, k)
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		oldValue, has := oldFields[k]
+		if !has {
+			oldValue = "(unset)"
+		}
+		newValue, has := newFields[k]
+		if !has {
+			newValue = "(unset)"
+		}
+
+		if isSecret(k, oldValue) || isSecret(k, newValue) {
+			oldValue, newValue = "<redacted>", "<redacted>"
+		}
+		parts = append(parts, fmt.Sprintf("%s %s -> %s", k, oldValue, newValue))
+	}
+
+	return []ConfigChange{{Op: "~", Target: target, Message: strings.Join(parts, ", ")}}
+}
+
+func isSecret(key, value string) bool {
+	if i := strings.LastIndex(key, "."); i >= 0 {
+		key = key[i+1:]
+	}
+	key = diffIndexRegexp.ReplaceAllString(key, "")
+	if strings.EqualFold(key, "metrics_pass") {
+		return false
+	}
+	return diffSecretKeyRegexp.MatchString(key) || diffSecretValueRegexp.MatchString(value)
+}
+
+// diffFilters returns the metrics newly filtered, by metrics_drop, metrics_pass and blocklist
+func diffFilters(target string, oldValue, newValue reflect.Value) []ConfigChange {
+	oldValue, newValue = reflect.Indirect(oldValue), reflect.Indirect(newValue)
+	if oldValue.Kind() != reflect.Struct || newValue.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var changes []ConfigChange
+	oldDrop, newDrop := stringsField(oldValue, "MetricsDrop"), stringsField(newValue, "MetricsDrop")
+	for _, p := range newDrop {
+		if !containsString(oldDrop, p) {
+			changes = append(changes, ConfigChange{Op: "!", Target: target, Message: "metrics_drop " + strconv.Quote(p)})
+		}
+	}
+
+	oldPass, newPass := stringsField(oldValue, "MetricsPass"), stringsField(newValue, "MetricsPass")
+	if len(oldPass) == 0 && len(newPass) > 0 {
+		changes = append(changes, ConfigChange{Op: "!", Target: target, Message: "metrics not matching metrics_pass " + fmt.Sprintf("%q", newPass)})
+	} else if len(newPass) > 0 {
+		for _, p := range oldPass {
+			if !containsString(newPass, p) {
+				changes = append(changes, ConfigChange{Op: "!", Target: target, Message: "metrics_pass " + strconv.Quote(p) + " removed"})
+			}
+		}
+	}
+
+	oldRules, newRules := blocklistRules(oldValue), blocklistRules(newValue)
+	for _, r := range newRules {
+		if !containsString(oldRules, r) {
+			changes = append(changes, ConfigChange{Op: "!", Target: target, Message: "blocklist " + r})
+		}
+	}
+
+	return changes
+}
+
+func stringsField(v reflect.Value, name string) []string {
+	f := v.FieldByName(name)
+	if !f.IsValid() {
+		return nil
+	}
+	ss, _ := f.Interface().([]string)
+	return ss
+}
+
+func blocklistRules(v reflect.Value) []string {
+	f := v.FieldByName("Blocklist")
+	if !f.IsValid() {
+		return nil
+	}
+	bl, _ := f.Interface().(config.Blocklist)
+
+	rules := make([]string, 0, len(bl))
+	for _, r := range bl {
+		if r == nil {
+			continue
+		}
+		fields := flattenConfig(r)
+		keys := make([]string, 0, len(fields))
+		for k := range fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		parts := make([]string, 0, len(keys))
+		for _, k := range keys {
+			parts = append(parts, k+"="+fields[k])
+		}
+		rules = append(rules, "{"+strings.Join(parts, " ")+"}")
+	}
+	return rules
+}
+
+func containsString(ss []string, s string) bool {
+	for _, x := range ss {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+// flattenConfig returns the exported fields of ptr by their toml paths, e.g. instances[0].labels.region
+func flattenConfig(ptr interface{}) map[string]string {
+	out := make(map[string]string)
+	flattenValue(reflect.ValueOf(ptr), "", out, 0)
+	return out
+}
+
+func flattenValue(v reflect.Value, key string, out map[string]string, depth int) {
+	if depth > maxWalkDepth {
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			flattenValue(v.Elem(), key, out, depth)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if sf.PkgPath != "" {
+				continue
+			}
+
+			name := strings.Split(sf.Tag.Get("toml"), ",")[0]
+			if name == "-" {
+				continue
+			}
+
+			if sf.Anonymous && name == "" {
+				flattenValue(v.Field(i), key, out, depth+1)
+				continue
+			}
+
+			if name == "" {
+				name = sf.Name
+			}
+			flattenValue(v.Field(i), joinKey(key, name), out, depth+1)
+		}
+	case reflect.Map:
+		keys := v.MapKeys()
+		for _, k := range keys {
+			flattenValue(v.MapIndex(k), joinKey(key, fmt.Sprint(k.Interface())), out, depth+1)
+		}
+	case reflect.Slice, reflect.Array:
+		if v.Len() == 0 {
+			return
+		}
+		if isScalarKind(v.Type().Elem().Kind()) {
+			items := make([]string, 0, v.Len())
+			for i := 0; i < v.Len(); i++ {
+				items = append(items, scalarString(v.Index(i)))
+			}
+			out[key] = "[" + strings.Join(items, ", ") + "]"
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			flattenValue(v.Index(i), fmt.Sprintf("%s[%d]", key, i), out, depth+1)
+		}
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer, reflect.Invalid:
+	default:
+		// the zero values are the same as unset
+		if !v.IsZero() {
+			out[key] = scalarString(v)
+		}
+	}
+}
+
+func joinKey(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+func isScalarKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Ptr, reflect.Interface, reflect.Struct, reflect.Map, reflect.Slice, reflect.Array,
+		reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return false
+	}
+	return true
+}
+
+func scalarString(v reflect.Value) string {
+	switch {
+	case v.Type() == durationType || v.Type() == timeDurationType:
+		return time.Duration(v.Int()).String()
+	case v.Kind() == reflect.String:
+		return strconv.Quote(v.String())
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// sameInputFiles compares the files of input dirs byte by byte
+func sameInputFiles(oldDir, newDir string) bool {
+	oldFiles, err1 := file.FilesUnder(oldDir)
+	newFiles, err2 := file.FilesUnder(newDir)
+	if err1 != nil || err2 != nil || len(oldFiles) != len(newFiles) {
+		return false
+	}
+
+	sort.Strings(oldFiles)
+	sort.Strings(newFiles)
+	for i := range oldFiles {
+		if oldFiles[i] != newFiles[i] {
+			return false
+		}
+		a, err1 := os.ReadFile(path.Join(oldDir, oldFiles[i]))
+		b, err2 := os.ReadFile(path.Join(newDir, newFiles[i]))
+		if err1 != nil || err2 != nil || !bytes.Equal(a, b) {
+			return false
+		}
+	}
+	return true
+}
@@ -3,8 +3,6 @@
 package agent
 
 import (
-	"log"
-
 	coreconfig "flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/ibex"
 )
@@ -16,7 +14,7 @@ func NewIbexAgent() AgentModule {
 	if coreconfig.Config == nil ||
 		coreconfig.Config.Ibex == nil ||
 		!coreconfig.Config.Ibex.Enable {
-		log.Println("I! ibex agent disabled!")
+		agentLog.Infof("ibex agent disabled!")
 		return nil
 	}
 	return &IbexAgent{}
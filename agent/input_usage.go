@@ -0,0 +1,82 @@
+package agent
+
+import (
+	"runtime"
+	"runtime/metrics"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/types"
+)
+
+const heapAllocsMetric = "/gc/heap/allocs:bytes"
+
+var (
+	inputCPUSeconds = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "input_cpu_seconds_total",
+		Help: "CPU time spent by the goroutine gathering the input instance, linux only and if global.input_cpu_usage is set, the goroutines started by the input are not counted.",
+	}, []string{"plugin", "instance"})
+
+	inputAllocBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "input_alloc_bytes_total",
+		Help: "Heap allocated by the process while gathering the input instance, the overlapped gathers of others are counted as well.",
+	}, []string{"plugin", "instance"})
+)
+
+func init() {
+	prometheus.MustRegister(inputCPUSeconds, inputAllocBytes)
+}
+
+// gatherMeasured gathers t and accounts the cpu and memory it takes, the cpu time is accounted only if
+// global.input_cpu_usage is set, the goroutine is locked to its thread during the gather so the cpu time
+// of the thread is the one of the gather
+func (r *InputReader) gatherMeasured(t interface{}, instance string, slist *types.SampleList) {
+	if _, ok := t.(inputs.SampleGatherer); !ok {
+		return
+	}
+
+	var (
+		cpu   time.Duration
+		cpuOK bool
+	)
+	if config.Config.Global.InputCPUUsage {
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		cpu, cpuOK = threadCPUTime()
+	}
+	alloc := heapAllocs()
+	// accounted even if the gather panics
+	defer func() {
+		if cpuOK {
+			if now, ok := threadCPUTime(); ok && now >= cpu {
+				inputCPUSeconds.WithLabelValues(r.inputName, instance).Add((now - cpu).Seconds())
+			}
+		}
+		if now := heapAllocs(); now >= alloc {
+			inputAllocBytes.WithLabelValues(r.inputName, instance).Add(float64(now - alloc))
+		}
+	}()
+
+	inputs.MayGather(t, slist)
+}
+
+func heapAllocs() uint64 {
+	s := []metrics.Sample{{Name: heapAllocsMetric}}
+	metrics.Read(s)
+	if s[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return s[0].Value.Uint64()
+}
+
+// deleteInputUsage deletes the series of the plugin level and of the instances of a reader,
+// the other readers of the same input keep theirs
+func deleteInputUsage(plugin string, instanceIDs []string) {
+	for _, instance := range append([]string{""}, instanceIDs...) {
+		inputCPUSeconds.DeleteLabelValues(plugin, instance)
+		inputAllocBytes.DeleteLabelValues(plugin, instance)
+	}
+}
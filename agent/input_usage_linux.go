@@ -0,0 +1,20 @@
+//go:build linux
+// +build linux
+
+package agent
+
+import (
+	"syscall"
+	"time"
+)
+
+// RUSAGE_THREAD of getrusage(2)
+const rusageThread = 1
+
+func threadCPUTime() (time.Duration, bool) {
+	var ru syscall.Rusage
+	if err := syscall.Getrusage(rusageThread, &ru); err != nil {
+		return 0, false
+	}
+	return time.Duration(ru.Utime.Nano() + ru.Stime.Nano()), true
+}
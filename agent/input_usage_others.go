@@ -0,0 +1,11 @@
+//go:build !linux
+// +build !linux
+
+package agent
+
+import "time"
+
+// the cpu time of threads is not accounted except on linux
+func threadCPUTime() (time.Duration, bool) {
+	return 0, false
+}
@@ -0,0 +1,114 @@
+//go:build !minimal
+
+package agent
+
+import (
+	// auto registry
+	_ "flashcat.cloud/categraf/inputs/active_directory"
+	_ "flashcat.cloud/categraf/inputs/airflow"
+	_ "flashcat.cloud/categraf/inputs/aliyun"
+	_ "flashcat.cloud/categraf/inputs/amqp_consumer"
+	_ "flashcat.cloud/categraf/inputs/arp_packet"
+	_ "flashcat.cloud/categraf/inputs/bgp"
+	_ "flashcat.cloud/categraf/inputs/blackbox"
+	_ "flashcat.cloud/categraf/inputs/cadvisor"
+	_ "flashcat.cloud/categraf/inputs/canary"
+	_ "flashcat.cloud/categraf/inputs/cert_manager"
+	_ "flashcat.cloud/categraf/inputs/clickhouse"
+	_ "flashcat.cloud/categraf/inputs/clock"
+	_ "flashcat.cloud/categraf/inputs/cloudwatch"
+	_ "flashcat.cloud/categraf/inputs/conntrack"
+	_ "flashcat.cloud/categraf/inputs/coredns"
+	_ "flashcat.cloud/categraf/inputs/cpu"
+	_ "flashcat.cloud/categraf/inputs/cronjob"
+	_ "flashcat.cloud/categraf/inputs/disk"
+	_ "flashcat.cloud/categraf/inputs/diskio"
+	_ "flashcat.cloud/categraf/inputs/dns_query"
+	_ "flashcat.cloud/categraf/inputs/dns_server"
+	_ "flashcat.cloud/categraf/inputs/docker"
+	_ "flashcat.cloud/categraf/inputs/dolphinscheduler"
+	_ "flashcat.cloud/categraf/inputs/dotnet"
+	_ "flashcat.cloud/categraf/inputs/elasticsearch"
+	_ "flashcat.cloud/categraf/inputs/envoy"
+	_ "flashcat.cloud/categraf/inputs/exchange"
+	_ "flashcat.cloud/categraf/inputs/exec"
+	_ "flashcat.cloud/categraf/inputs/flink"
+	_ "flashcat.cloud/categraf/inputs/gitea"
+	_ "flashcat.cloud/categraf/inputs/gitlab"
+	_ "flashcat.cloud/categraf/inputs/gitlab_ci"
+	_ "flashcat.cloud/categraf/inputs/gnmi"
+	_ "flashcat.cloud/categraf/inputs/greenplum"
+	_ "flashcat.cloud/categraf/inputs/hadoop_hdfs"
+	_ "flashcat.cloud/categraf/inputs/hadoop_yarn"
+	_ "flashcat.cloud/categraf/inputs/haproxy"
+	_ "flashcat.cloud/categraf/inputs/harbor"
+	_ "flashcat.cloud/categraf/inputs/http_response"
+	_ "flashcat.cloud/categraf/inputs/iis"
+	_ "flashcat.cloud/categraf/inputs/ingress_nginx"
+	_ "flashcat.cloud/categraf/inputs/interrupts"
+	_ "flashcat.cloud/categraf/inputs/ipvs"
+	_ "flashcat.cloud/categraf/inputs/jenkins"
+	_ "flashcat.cloud/categraf/inputs/jolokia_agent"
+	_ "flashcat.cloud/categraf/inputs/jolokia_proxy"
+	_ "flashcat.cloud/categraf/inputs/jstat"
+	_ "flashcat.cloud/categraf/inputs/kafka"
+	_ "flashcat.cloud/categraf/inputs/kafka_consumer"
+	_ "flashcat.cloud/categraf/inputs/kernel"
+	_ "flashcat.cloud/categraf/inputs/kernel_vmstat"
+	_ "flashcat.cloud/categraf/inputs/keycloak"
+	_ "flashcat.cloud/categraf/inputs/kmsg"
+	_ "flashcat.cloud/categraf/inputs/kube_events"
+	_ "flashcat.cloud/categraf/inputs/kubernetes"
+	_ "flashcat.cloud/categraf/inputs/launchd"
+	_ "flashcat.cloud/categraf/inputs/linux_sysctl_fs"
+	_ "flashcat.cloud/categraf/inputs/logstash"
+	_ "flashcat.cloud/categraf/inputs/mem"
+	_ "flashcat.cloud/categraf/inputs/mongodb"
+	_ "flashcat.cloud/categraf/inputs/msmq"
+	_ "flashcat.cloud/categraf/inputs/mtail"
+	_ "flashcat.cloud/categraf/inputs/mysql"
+	_ "flashcat.cloud/categraf/inputs/mysql_slowlog"
+	_ "flashcat.cloud/categraf/inputs/net"
+	_ "flashcat.cloud/categraf/inputs/net_response"
+	_ "flashcat.cloud/categraf/inputs/netstat"
+	_ "flashcat.cloud/categraf/inputs/netstat_filter"
+	_ "flashcat.cloud/categraf/inputs/nexus"
+	_ "flashcat.cloud/categraf/inputs/nfsclient"
+	_ "flashcat.cloud/categraf/inputs/nginx"
+	_ "flashcat.cloud/categraf/inputs/nginx_upstream_check"
+	_ "flashcat.cloud/categraf/inputs/nodejs"
+	_ "flashcat.cloud/categraf/inputs/ntp"
+	_ "flashcat.cloud/categraf/inputs/nvidia_smi"
+	_ "flashcat.cloud/categraf/inputs/oracle"
+	_ "flashcat.cloud/categraf/inputs/packages"
+	_ "flashcat.cloud/categraf/inputs/phpfpm"
+	_ "flashcat.cloud/categraf/inputs/ping"
+	_ "flashcat.cloud/categraf/inputs/postgresql"
+	_ "flashcat.cloud/categraf/inputs/processes"
+	_ "flashcat.cloud/categraf/inputs/procstat"
+	_ "flashcat.cloud/categraf/inputs/prometheus"
+	_ "flashcat.cloud/categraf/inputs/psi"
+	_ "flashcat.cloud/categraf/inputs/rabbitmq"
+	_ "flashcat.cloud/categraf/inputs/redis"
+	_ "flashcat.cloud/categraf/inputs/redis_consumer"
+	_ "flashcat.cloud/categraf/inputs/redis_sentinel"
+	_ "flashcat.cloud/categraf/inputs/rocketmq_offset"
+	_ "flashcat.cloud/categraf/inputs/self_metrics"
+	_ "flashcat.cloud/categraf/inputs/snmp"
+	_ "flashcat.cloud/categraf/inputs/sockstat"
+	_ "flashcat.cloud/categraf/inputs/spark"
+	_ "flashcat.cloud/categraf/inputs/sqlite"
+	_ "flashcat.cloud/categraf/inputs/sqlserver"
+	_ "flashcat.cloud/categraf/inputs/ssh"
+	_ "flashcat.cloud/categraf/inputs/switch_legacy"
+	_ "flashcat.cloud/categraf/inputs/system"
+	_ "flashcat.cloud/categraf/inputs/systemd"
+	_ "flashcat.cloud/categraf/inputs/telemetry_dialout"
+	_ "flashcat.cloud/categraf/inputs/tencentcloud"
+	_ "flashcat.cloud/categraf/inputs/tomcat"
+	_ "flashcat.cloud/categraf/inputs/vault"
+	_ "flashcat.cloud/categraf/inputs/vsphere"
+	_ "flashcat.cloud/categraf/inputs/webhook"
+	_ "flashcat.cloud/categraf/inputs/xskyapi"
+	_ "flashcat.cloud/categraf/inputs/zookeeper"
+)
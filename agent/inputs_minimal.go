@@ -0,0 +1,25 @@
+//go:build minimal
+
+package agent
+
+// the inputs of the minimal build, for the embedded devices with little storage,
+// the others are added by INPUTS of make build-minimal, e.g. INPUTS="ping redis"
+import (
+	// auto registry
+	_ "flashcat.cloud/categraf/inputs/cpu"
+	_ "flashcat.cloud/categraf/inputs/disk"
+	_ "flashcat.cloud/categraf/inputs/diskio"
+	_ "flashcat.cloud/categraf/inputs/exec"
+	_ "flashcat.cloud/categraf/inputs/http_response"
+	_ "flashcat.cloud/categraf/inputs/kernel"
+	_ "flashcat.cloud/categraf/inputs/kernel_vmstat"
+	_ "flashcat.cloud/categraf/inputs/linux_sysctl_fs"
+	_ "flashcat.cloud/categraf/inputs/mem"
+	_ "flashcat.cloud/categraf/inputs/net"
+	_ "flashcat.cloud/categraf/inputs/net_response"
+	_ "flashcat.cloud/categraf/inputs/netstat"
+	_ "flashcat.cloud/categraf/inputs/processes"
+	_ "flashcat.cloud/categraf/inputs/procstat"
+	_ "flashcat.cloud/categraf/inputs/self_metrics"
+	_ "flashcat.cloud/categraf/inputs/system"
+)
@@ -11,13 +11,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
 	"os"
 	"time"
 
 	"flashcat.cloud/categraf/logs/auditor"
 	"flashcat.cloud/categraf/logs/client"
 	"flashcat.cloud/categraf/logs/diagnostic"
+	"flashcat.cloud/categraf/logs/input/channel"
 	"flashcat.cloud/categraf/logs/input/container"
 	"flashcat.cloud/categraf/logs/input/file"
 	"flashcat.cloud/categraf/logs/input/journald"
@@ -60,9 +60,9 @@ type LogsAgent struct {
 
 // NewLogsAgent returns a new Logs LogsAgent
 func NewLogsAgent() AgentModule {
-	if coreconfig.Config == nil ||
-		!coreconfig.Config.Logs.Enable ||
-		(len(coreconfig.Config.Logs.Items) == 0 && coreconfig.Config.Logs.CollectContainerAll == false) {
+	// logs of metrics inputs, e.g. kube_events, are sent through log channels,
+	// so the logs agent runs even if no items configured
+	if coreconfig.Config == nil || !coreconfig.Config.Logs.Enable {
 		return nil
 	}
 
@@ -70,20 +70,20 @@ func NewLogsAgent() AgentModule {
 	if err != nil {
 		message := fmt.Sprintf("Invalid endpoints: %v", err)
 		status.AddGlobalError("invalid endpoints", message)
-		log.Println("E!", errors.New(message))
+		agentLog.Errorf("%v", errors.New(message))
 		return nil
 	}
 	processingRules, err := GlobalProcessingRules()
 	if err != nil {
 		message := fmt.Sprintf("Invalid processing rules: %v", err)
 		status.AddGlobalError(invalidProcessingRules, message)
-		log.Println("E!", errors.New(message))
+		agentLog.Errorf("%v", errors.New(message))
 		return nil
 	}
 
 	sources := logsconfig.NewLogSources()
 	services := logService.NewServices()
-	log.Println("I! Starting logs-agent...")
+	agentLog.Infof("Starting logs-agent...")
 
 	// setup the auditor
 	// We pass the health handle to the auditor because it's the end of the pipeline and the most
@@ -117,9 +117,10 @@ func NewLogsAgent() AgentModule {
 			file.DefaultSleepDuration, validatePodContainerID, time.Duration(time.Duration(coreconfig.FileScanPeriod())*time.Second)),
 		listener.NewLauncher(sources, coreconfig.LogFrameSize(), pipelineProvider),
 		journald.NewLauncher(sources, pipelineProvider, auditor),
+		channel.NewLauncher(sources, pipelineProvider),
 	}
 	if coreconfig.GetContainerCollectAll() {
-		log.Println("collect docker logs...")
+		agentLog.Infof("collect docker logs...")
 		inputs = append(inputs, container.NewLauncher(containerLaunchables))
 	}
 
@@ -141,7 +142,7 @@ func (la *LogsAgent) Start() error {
 	if coreconfig.GetContainerCollectAll() {
 		// collect container all
 		if coreconfig.Config.DebugMode {
-			log.Println("Adding ContainerCollectAll source to the Logs Agent")
+			agentLog.Infof("Adding ContainerCollectAll source to the Logs Agent")
 		}
 		kubesource := logsconfig.NewLogSource(logsconfig.ContainerCollectAll,
 			&logsconfig.LogsConfig{
@@ -160,12 +161,14 @@ func (la *LogsAgent) Start() error {
 		}
 		source := logsconfig.NewLogSource(c.Name, c)
 		if err := c.Validate(); err != nil {
-			log.Println("W! Invalid logs configuration:", err)
+			agentLog.Warnf("Invalid logs configuration: %v", err)
 			source.Status.Error(err)
 			continue
 		}
 		la.sources.AddSource(source)
 	}
+
+	coreconfig.AttachLogChannels(la.sources)
 	return nil
 }
 
@@ -187,6 +190,8 @@ func (a *LogsAgent) Flush(ctx context.Context) {
 // Stop stops all the elements of the data pipeline
 // in the right order to prevent data loss
 func (a *LogsAgent) Stop() error {
+	coreconfig.DetachLogChannels()
+
 	inputs := restart.NewParallelStopper()
 	for _, input := range a.inputs {
 		inputs.Add(input)
@@ -213,7 +218,7 @@ func (a *LogsAgent) Stop() error {
 	select {
 	case <-c:
 	case <-time.After(timeout):
-		log.Println("I! Timed out when stopping logs-agent, forcing it to stop now")
+		agentLog.Infof("Timed out when stopping logs-agent, forcing it to stop now")
 		// We force all destinations to read/flush all the messages they get without
 		// trying to write to the network.
 		a.destinationsCtx.Stop()
@@ -225,7 +230,7 @@ func (a *LogsAgent) Stop() error {
 		select {
 		case <-c:
 		case <-timeout.C:
-			log.Println("W! Force close of the Logs LogsAgent, dumping the Go routines.")
+			agentLog.Warnf("Force close of the Logs LogsAgent, dumping the Go routines.")
 		}
 	}
 	return nil
@@ -11,6 +11,8 @@ import (
 
 	coreconfig "flashcat.cloud/categraf/config"
 	logsconfig "flashcat.cloud/categraf/config/logs"
+	"flashcat.cloud/categraf/logs/serializers"
+	"flashcat.cloud/categraf/pkg/spool"
 )
 
 var logsEndpoints = map[string]int{
@@ -33,16 +35,142 @@ func BuildEndpoints(intakeTrackType logsconfig.IntakeTrackType, intakeProtocol l
 func BuildEndpointsWithConfig(endpointPrefix string, intakeTrackType logsconfig.IntakeTrackType, intakeProtocol logsconfig.IntakeProtocol, intakeOrigin logsconfig.IntakeOrigin) (*logsconfig.Endpoints, error) {
 	logsConfig := coreconfig.Config.Logs
 
+	var (
+		endpoints *logsconfig.Endpoints
+		err       error
+	)
 	switch logsConfig.SendType {
 	case "http":
-		return BuildHTTPEndpointsWithConfig(endpointPrefix, intakeTrackType, intakeProtocol, intakeOrigin)
-	case "tcp":
-		return buildTCPEndpoints(logsConfig)
+		endpoints, err = BuildHTTPEndpointsWithConfig(endpointPrefix, intakeTrackType, intakeProtocol, intakeOrigin)
 	case "kafka":
-		return buildKafkaEndpoints(logsConfig)
+		endpoints, err = buildKafkaEndpoints(logsConfig)
+	default:
+		endpoints, err = buildTCPEndpoints(logsConfig)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := buildStrategy(endpoints, logsConfig); err != nil {
+		return nil, err
+	}
+	buildSpool(endpoints, logsConfig)
+	return endpoints, buildAdditionalEndpoints(endpoints, logsConfig)
+}
+
+// buildSpool sets the spool of the payloads failed to send, the logs of all sources are kept
+// while the main endpoint is unreachable, the additional endpoints are not spooled
+func buildSpool(endpoints *logsconfig.Endpoints, logsConfig coreconfig.Logs) {
+	if logsConfig.SpoolDir == "" {
+		return
+	}
+	endpoints.SpoolDir = logsConfig.SpoolDir
+	maxSize := logsConfig.SpoolMaxSizeMB
+	if maxSize <= 0 {
+		maxSize = spool.DefaultMaxSizeMB
+	}
+	endpoints.SpoolMaxSize = maxSize * 1024 * 1024
+}
+
+// buildStrategy sets how the messages are sent to the endpoints, in batches by default for http,
+// and one by one for kafka and tcp. The strategy applies per pipeline, the additional endpoints are
+// sent the same batches as the main endpoint
+func buildStrategy(endpoints *logsconfig.Endpoints, logsConfig coreconfig.Logs) error {
+	switch logsConfig.SendStrategy {
+	case "":
+		endpoints.Strategy = logsconfig.StreamStrategy
+		if endpoints.Type == "http" {
+			endpoints.Strategy = logsconfig.BatchStrategy
+		}
+	case logsconfig.StreamStrategy, logsconfig.BatchStrategy:
+		endpoints.Strategy = logsConfig.SendStrategy
+	default:
+		return fmt.Errorf("unknown send_strategy %q, must be stream or batch", logsConfig.SendStrategy)
+	}
+	if endpoints.Strategy == logsconfig.BatchStrategy && endpoints.Type == "tcp" {
+		// a batch would be a single frame of length_prefix
+		return fmt.Errorf("send_strategy batch is not supported by send_type tcp")
+	}
+
+	if logsConfig.MaxBatchSize > 0 {
+		endpoints.BatchMaxSize = logsConfig.MaxBatchSize
+	}
+	if logsConfig.MaxPayloadBytes > 0 {
+		endpoints.BatchMaxContentSize = logsConfig.MaxPayloadBytes
+	}
+	// flush_interval overrides batch_wait in seconds
+	if logsConfig.FlushInterval > 0 {
+		endpoints.BatchWait = time.Duration(logsConfig.FlushInterval)
+	}
+	if endpoints.BatchWait <= 0 {
+		endpoints.BatchWait = 5 * time.Second
+	}
+	return nil
+}
+
+// buildAdditionalEndpoints sets the format of the main endpoint and adds the additional endpoints,
+// which are sent to by the same send type, with the address, the credential and the format of their own
+func buildAdditionalEndpoints(endpoints *logsconfig.Endpoints, logsConfig coreconfig.Logs) error {
+	endpoints.Main.Format = logsConfig.Format
+	compression, err := buildCompression(logsConfig.UseCompression, logsConfig.Compression)
+	if err != nil {
+		return err
+	}
+	endpoints.Main.Compression = compression
+	endpoints.Main.UseCompression = compression != ""
+	for _, c := range logsConfig.AdditionalEndpoints {
+		if c.SendTo == "" {
+			return fmt.Errorf("empty send_to is not allowed in additional_endpoints")
+		}
+		endpoint := endpoints.Main
+		// kafka brokers are concatenated with ","
+		host, port, err := parseAddress(strings.Split(c.SendTo, ",")[0])
+		if err != nil {
+			return fmt.Errorf("could not parse %s: %v", c.SendTo, err)
+		}
+		endpoint.Addr = c.SendTo
+		endpoint.Host = host
+		endpoint.Port = port
+		endpoint.UseSSL = c.SendWithTLS
+		compression, err := buildCompression(c.UseCompression, c.Compression)
+		if err != nil {
+			return fmt.Errorf("additional endpoint %s: %v", c.SendTo, err)
+		}
+		endpoint.Compression = compression
+		endpoint.UseCompression = compression != ""
+		if c.CompressionLevel != 0 {
+			endpoint.CompressionLevel = c.CompressionLevel
+		}
+		if c.APIKey != "" {
+			endpoint.APIKey = strings.TrimSpace(c.APIKey)
+		}
+		if c.Topic != "" {
+			endpoint.Topic = c.Topic
+		}
+		endpoint.Format = c.Format
+		endpoints.Additionals = append(endpoints.Additionals, endpoint)
+	}
+
+	for _, endpoint := range append([]logsconfig.Endpoint{endpoints.Main}, endpoints.Additionals...) {
+		if _, err := serializers.ForEndpoint(endpoints, endpoint); err != nil {
+			return fmt.Errorf("endpoint %s: %v", endpoint.Host, err)
+		}
+	}
+	return nil
+}
 
+// buildCompression returns the algorithm of compression, gzip if only use_compression is set,
+// empty if the payloads are not compressed
+func buildCompression(useCompression bool, compression string) (string, error) {
+	switch compression {
+	case "":
+		if useCompression {
+			return logsconfig.GzipCompression, nil
+		}
+		return "", nil
+	case logsconfig.GzipCompression, logsconfig.ZstdCompression:
+		return compression, nil
 	}
-	return buildTCPEndpoints(logsConfig)
+	return "", fmt.Errorf("unknown compression %q, must be gzip or zstd", compression)
 }
 
 func buildKafkaEndpoints(logsConfig coreconfig.Logs) (*logsconfig.Endpoints, error) {
@@ -90,10 +218,26 @@ func buildKafkaEndpoints(logsConfig coreconfig.Logs) (*logsconfig.Endpoints, err
 }
 
 func buildTCPEndpoints(logsConfig coreconfig.Logs) (*logsconfig.Endpoints, error) {
+	tcpConfig := logsConfig.TCP
 	main := logsconfig.Endpoint{
 		APIKey:                  logsConfig.APIKey,
 		ProxyAddress:            "",
-		ConnectionResetInterval: 0,
+		ConnectionResetInterval: time.Duration(tcpConfig.ConnectionResetInterval),
+		Framing:                 tcpConfig.Framing,
+		KeepAlive:               time.Duration(tcpConfig.KeepAlive),
+		WriteTimeout:            time.Duration(tcpConfig.WriteTimeout),
+		ReconnectBackoffMax:     time.Duration(tcpConfig.ReconnectBackoffMax),
+	}
+	switch main.Framing {
+	case "", logsconfig.NewlineFraming, logsconfig.LengthPrefixFraming:
+	default:
+		return nil, fmt.Errorf("unknown framing %q, must be newline or length_prefix", main.Framing)
+	}
+	if main.WriteTimeout == 0 {
+		main.WriteTimeout = 30 * time.Second
+	}
+	if main.ReconnectBackoffMax <= 0 {
+		main.ReconnectBackoffMax = 2 * time.Minute
 	}
 
 	if len(logsConfig.SendTo) != 0 {
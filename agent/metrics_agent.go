@@ -2,7 +2,6 @@ package agent
 
 import (
 	"errors"
-	"log"
 	"strings"
 	"sync"
 
@@ -10,69 +9,6 @@ import (
 	"flashcat.cloud/categraf/inputs"
 	"flashcat.cloud/categraf/pkg/cfg"
 	"flashcat.cloud/categraf/types"
-
-	// auto registry
-	_ "flashcat.cloud/categraf/inputs/aliyun"
-	_ "flashcat.cloud/categraf/inputs/arp_packet"
-	_ "flashcat.cloud/categraf/inputs/cadvisor"
-	_ "flashcat.cloud/categraf/inputs/clickhouse"
-	_ "flashcat.cloud/categraf/inputs/cloudwatch"
-	_ "flashcat.cloud/categraf/inputs/conntrack"
-	_ "flashcat.cloud/categraf/inputs/cpu"
-	_ "flashcat.cloud/categraf/inputs/disk"
-	_ "flashcat.cloud/categraf/inputs/diskio"
-	_ "flashcat.cloud/categraf/inputs/dns_query"
-	_ "flashcat.cloud/categraf/inputs/docker"
-	_ "flashcat.cloud/categraf/inputs/elasticsearch"
-	_ "flashcat.cloud/categraf/inputs/exec"
-	_ "flashcat.cloud/categraf/inputs/greenplum"
-	_ "flashcat.cloud/categraf/inputs/haproxy"
-	_ "flashcat.cloud/categraf/inputs/http_response"
-	_ "flashcat.cloud/categraf/inputs/ipvs"
-	_ "flashcat.cloud/categraf/inputs/jenkins"
-	_ "flashcat.cloud/categraf/inputs/jolokia_agent"
-	_ "flashcat.cloud/categraf/inputs/jolokia_proxy"
-	_ "flashcat.cloud/categraf/inputs/kafka"
-	_ "flashcat.cloud/categraf/inputs/kernel"
-	_ "flashcat.cloud/categraf/inputs/kernel_vmstat"
-	_ "flashcat.cloud/categraf/inputs/kubernetes"
-	_ "flashcat.cloud/categraf/inputs/linux_sysctl_fs"
-	_ "flashcat.cloud/categraf/inputs/logstash"
-	_ "flashcat.cloud/categraf/inputs/mem"
-	_ "flashcat.cloud/categraf/inputs/mongodb"
-	_ "flashcat.cloud/categraf/inputs/mtail"
-	_ "flashcat.cloud/categraf/inputs/mysql"
-	_ "flashcat.cloud/categraf/inputs/net"
-	_ "flashcat.cloud/categraf/inputs/net_response"
-	_ "flashcat.cloud/categraf/inputs/netstat"
-	_ "flashcat.cloud/categraf/inputs/netstat_filter"
-	_ "flashcat.cloud/categraf/inputs/nfsclient"
-	_ "flashcat.cloud/categraf/inputs/nginx"
-	_ "flashcat.cloud/categraf/inputs/nginx_upstream_check"
-	_ "flashcat.cloud/categraf/inputs/ntp"
-	_ "flashcat.cloud/categraf/inputs/nvidia_smi"
-	_ "flashcat.cloud/categraf/inputs/oracle"
-	_ "flashcat.cloud/categraf/inputs/phpfpm"
-	_ "flashcat.cloud/categraf/inputs/ping"
-	_ "flashcat.cloud/categraf/inputs/postgresql"
-	_ "flashcat.cloud/categraf/inputs/processes"
-	_ "flashcat.cloud/categraf/inputs/procstat"
-	_ "flashcat.cloud/categraf/inputs/prometheus"
-	_ "flashcat.cloud/categraf/inputs/rabbitmq"
-	_ "flashcat.cloud/categraf/inputs/redis"
-	_ "flashcat.cloud/categraf/inputs/redis_sentinel"
-	_ "flashcat.cloud/categraf/inputs/rocketmq_offset"
-	_ "flashcat.cloud/categraf/inputs/self_metrics"
-	_ "flashcat.cloud/categraf/inputs/snmp"
-	_ "flashcat.cloud/categraf/inputs/sockstat"
-	_ "flashcat.cloud/categraf/inputs/sqlserver"
-	_ "flashcat.cloud/categraf/inputs/switch_legacy"
-	_ "flashcat.cloud/categraf/inputs/system"
-	_ "flashcat.cloud/categraf/inputs/systemd"
-	_ "flashcat.cloud/categraf/inputs/tomcat"
-	_ "flashcat.cloud/categraf/inputs/vsphere"
-	_ "flashcat.cloud/categraf/inputs/xskyapi"
-	_ "flashcat.cloud/categraf/inputs/zookeeper"
 )
 
 type MetricsAgent struct {
@@ -129,6 +65,8 @@ func (r *Readers) Iter() map[string]map[string]*InputReader {
 
 func NewMetricsAgent() AgentModule {
 	c := config.Config
+	initRecorder()
+
 	agent := &MetricsAgent{
 		InputFilters: parseFilter(c.InputFilters),
 		InputReaders: NewReaders(),
@@ -136,7 +74,7 @@ func NewMetricsAgent() AgentModule {
 
 	provider, err := inputs.NewProvider(c, agent)
 	if err != nil {
-		log.Println("E! init metrics agent error: ", err)
+		agentLog.Errorf("init metrics agent error:  %v", err)
 		return nil
 	}
 	agent.InputProvider = provider
@@ -155,7 +93,7 @@ func (ma *MetricsAgent) FilterPass(inputKey string) bool {
 
 func (ma *MetricsAgent) Start() error {
 	if _, err := ma.InputProvider.LoadConfig(); err != nil {
-		log.Println("E! input provider load config get err: ", err)
+		agentLog.Errorf("input provider load config get err:  %v", err)
 	}
 	ma.InputProvider.StartReloader()
 
@@ -165,7 +103,7 @@ func (ma *MetricsAgent) Start() error {
 	}
 
 	if len(names) == 0 {
-		log.Println("I! no inputs")
+		agentLog.Infof("no inputs")
 		return nil
 	}
 
@@ -177,7 +115,7 @@ func (ma *MetricsAgent) Start() error {
 
 		configs, err := ma.InputProvider.GetInputConfig(name)
 		if err != nil {
-			log.Println("E! failed to get configuration of plugin:", name, "error:", err)
+			agentLog.Errorf("failed to get configuration of plugin: %v error: %v", name, err)
 			continue
 		}
 
@@ -206,13 +144,13 @@ func (ma *MetricsAgent) RegisterInput(name string, configs []cfg.ConfigWithForma
 
 	creator, has := inputs.InputCreators[inputKey]
 	if !has {
-		log.Println("E! input:", name, "not supported")
+		agentLog.Errorf("input: %v not supported", name)
 		return
 	}
 
 	newInputs, err := ma.InputProvider.LoadInputConfig(configs, creator())
 	if err != nil {
-		log.Println("E! failed to load configuration of plugin:", name, "error:", err)
+		agentLog.Errorf("failed to load configuration of plugin: %v error: %v", name, err)
 		return
 	}
 
@@ -222,15 +160,17 @@ func (ma *MetricsAgent) RegisterInput(name string, configs []cfg.ConfigWithForma
 }
 
 func (ma *MetricsAgent) inputGo(name string, sum string, input inputs.Input) {
+	ids := instanceIDs(sum, inputs.MayGetInstances(input))
+
 	var err error
 	if err = input.InitInternalConfig(); err != nil {
-		log.Println("E! failed to init input:", name, "error:", err)
+		agentLog.Errorf("failed to init input: %v error: %v", name, err)
 		return
 	}
 
 	if err = inputs.MayInit(input); err != nil {
 		if !errors.Is(err, types.ErrInstancesEmpty) {
-			log.Println("E! failed to init input:", name, "error:", err)
+			agentLog.Errorf("failed to init input: %v error: %v", name, err)
 		}
 		return
 	}
@@ -240,13 +180,13 @@ func (ma *MetricsAgent) inputGo(name string, sum string, input inputs.Input) {
 		empty := true
 		for i := 0; i < len(instances); i++ {
 			if err := instances[i].InitInternalConfig(); err != nil {
-				log.Println("E! failed to init input:", name, "error:", err)
+				agentLog.Errorf("failed to init input: %v error: %v", name, err)
 				continue
 			}
 
 			if err := inputs.MayInit(instances[i]); err != nil {
 				if !errors.Is(err, types.ErrInstancesEmpty) {
-					log.Println("E! failed to init input:", name, "error:", err)
+					agentLog.Errorf("failed to init input: %v error: %v", name, err)
 				}
 				continue
 			}
@@ -257,16 +197,16 @@ func (ma *MetricsAgent) inputGo(name string, sum string, input inputs.Input) {
 		if empty {
 			if config.Config.DebugMode {
 				_, inputKey := inputs.ParseInputName(name)
-				log.Printf("W! no instances for input:%s", inputKey)
+				agentLog.Warnf("no instances for input:%s", inputKey)
 			}
 			return
 		}
 	}
 
-	reader := newInputReader(name, input)
+	reader := newInputReader(name, input, ids)
 	go reader.startInput()
 	ma.InputReaders.Add(name, sum, reader)
-	log.Println("I! input:", name, "started")
+	agentLog.Infof("input: %v started", name)
 }
 
 func (ma *MetricsAgent) DeregisterInput(name string, sum string) {
@@ -277,9 +217,9 @@ func (ma *MetricsAgent) DeregisterInput(name string, sum string) {
 			}
 		}
 		ma.InputReaders.Del(name, sum)
-		log.Printf("I! input: %s[checksum:%s] stopped", name, sum)
+		agentLog.Infof("input: %s[checksum:%s] stopped", name, sum)
 	} else {
-		log.Printf("W! dereigster input name [%s] not found", name)
+		agentLog.Warnf("dereigster input name [%s] not found", name)
 	}
 }
 
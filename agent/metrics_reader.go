@@ -1,37 +1,64 @@
 package agent
 
 import (
-	"log"
+	"fmt"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/leader"
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/pkg/pause"
 	"flashcat.cloud/categraf/pkg/runtimex"
 	"flashcat.cloud/categraf/types"
 	"flashcat.cloud/categraf/writer"
 )
 
 type InputReader struct {
+	// accessed atomically, needs to be first to ensure 64 bit alignment on 32 bit platforms
+	runCounter uint64
 	inputName  string
+	inputKey   string
 	input      inputs.Input
 	quitChan   chan struct{}
-	runCounter uint64
 	waitGroup  sync.WaitGroup
+	interval   time.Duration
+	breakers   []*circuitBreaker
+	// the ids of the instances, the instance label of their metrics, see instanceIDs
+	instanceIDs []string
+	log         *logger.Logger
+	// nil if the interval is not adaptive
+	adaptive *adaptiveInterval
 }
 
-func newInputReader(inputName string, in inputs.Input) *InputReader {
+func newInputReader(inputName string, in inputs.Input, instanceIDs []string) *InputReader {
+	_, inputKey := inputs.ParseInputName(inputName)
+	log := logger.New("input."+inputKey).With("input", inputName)
 	return &InputReader{
-		inputName: inputName,
-		input:     in,
-		quitChan:  make(chan struct{}, 1),
+		inputName:   inputName,
+		inputKey:    inputKey,
+		input:       in,
+		quitChan:    make(chan struct{}, 1),
+		instanceIDs: instanceIDs,
+		log:         log,
+		adaptive:    newAdaptiveInterval(inputName, in.GetPriority(), log),
 	}
 }
 
 func (r *InputReader) Stop() {
 	r.quitChan <- struct{}{}
 	inputs.MayDrop(r.input)
+	// only the series of this reader, the other readers of the same input, e.g. of other configs, keep theirs
+	pluginUp.DeleteLabelValues(r.inputName, "")
+	for _, id := range r.instanceIDs {
+		pluginUp.DeleteLabelValues(r.inputName, id)
+		circuitBreakerOpen.DeleteLabelValues(r.inputName, id)
+		circuitBreakerTrips.DeleteLabelValues(r.inputName, id)
+	}
+	adaptiveIntervalFactor.DeleteLabelValues(r.inputName)
+	deleteInputUsage(r.inputName, r.instanceIDs)
 }
 
 func (r *InputReader) startInput() {
@@ -39,6 +66,7 @@ func (r *InputReader) startInput() {
 	if r.input.GetInterval() > 0 {
 		interval = time.Duration(r.input.GetInterval())
 	}
+	r.interval = interval
 	timer := time.NewTimer(0 * time.Second)
 	defer timer.Stop()
 	var start time.Time
@@ -50,17 +78,18 @@ func (r *InputReader) startInput() {
 			return
 		case <-timer.C:
 			start = time.Now()
-			if config.Config.DebugMode {
-				log.Println("D!", r.inputName, ": before gather once")
-			}
+			r.log.Debugf("before gather once")
 
 			r.gatherOnce()
 
-			if config.Config.DebugMode {
-				log.Println("D!", r.inputName, ": after gather once,", "duration:", time.Since(start))
+			r.log.With("duration", time.Since(start)).Debugf("after gather once")
+
+			wait := interval
+			if r.adaptive != nil {
+				wait = r.adaptive.next(interval, time.Since(start))
 			}
 
-			next := interval - time.Since(start)
+			next := wait - time.Since(start)
 			if next < 0 {
 				next = 0
 			}
@@ -72,28 +101,50 @@ func (r *InputReader) startInput() {
 func (r *InputReader) gatherOnce() {
 	defer func() {
 		if rc := recover(); rc != nil {
-			log.Println("E!", r.inputName, ": gather metrics panic:", r, string(runtimex.Stack(3)))
+			r.log.Errorf("gather metrics panic: %v %s", rc, runtimex.Stack(3))
+			pluginUp.WithLabelValues(r.inputName, "").Set(0)
 		}
 	}()
 
+	if pause.Paused(r.inputName, "") {
+		r.log.Debugf("input is paused, skip gathering")
+		return
+	}
+
+	if !leader.Allowed(r.inputKey) {
+		r.log.Debugf("not the leader of the cluster scoped input, skip gathering")
+		return
+	}
+
 	// plugin level, for system plugins
 	slist := types.NewSampleList()
-	inputs.MayGather(r.input, slist)
+	r.gatherMeasured(r.input, "", slist)
+	samplesRecorder.record(r.inputName, "", slist)
 	r.forward(r.input.Process(slist))
 
 	instances := inputs.MayGetInstances(r.input)
 	if len(instances) == 0 {
+		pluginUp.WithLabelValues(r.inputName, "").Set(1)
 		return
 	}
 
 	atomic.AddUint64(&r.runCounter, 1)
 
+	if r.breakers == nil {
+		r.breakers = make([]*circuitBreaker, len(instances))
+		for i := 0; i < len(instances); i++ {
+			if threshold, maxBackoff := instances[i].GetCircuitBreaker(); threshold > 0 {
+				r.breakers[i] = newCircuitBreaker(threshold, maxBackoff)
+			}
+		}
+	}
+
 	for i := 0; i < len(instances); i++ {
 		if !instances[i].Initialized() {
 			continue
 		}
 		r.waitGroup.Add(1)
-		go func(ins inputs.Instance) {
+		go func(idx int, ins inputs.Instance) {
 			defer r.waitGroup.Done()
 
 			it := ins.GetIntervalTimes()
@@ -104,19 +155,93 @@ func (r *InputReader) gatherOnce() {
 				}
 			}
 
+			if pause.Paused(r.inputName, r.instanceIDs[idx]) {
+				r.log.With("instance", idx).Debugf("instance is paused, skip gathering")
+				return
+			}
+
+			maintaining, skip := ins.InMaintenance(time.Now())
+			if skip {
+				r.log.With("instance", idx).Debugf("in maintenance window, skip gathering")
+				return
+			}
+
+			cb := r.breakers[idx]
+			if !cb.allow(time.Now()) {
+				r.log.With("instance", idx).Debugf("circuit breaker is open, skip gathering")
+				return
+			}
+
 			insList := types.NewSampleList()
-			inputs.MayGather(ins, insList)
-			r.forward(ins.Process(insList))
-		}(instances[i])
+			failed := r.gatherInstance(idx, ins, insList)
+			samplesRecorder.record(r.inputName, fmt.Sprint(idx), insList)
+			r.recordGather(idx, ins, failed)
+			processed := ins.Process(insList)
+			if maintaining {
+				processed.Range(func(s *types.Sample) bool {
+					s.Labels[config.MaintenanceLabelKey] = "1"
+					return true
+				})
+			}
+			r.forward(processed)
+		}(i, instances[i])
 	}
 
 	r.waitGroup.Wait()
 }
 
+// gatherInstance returns true if the gather failed, see gatherFailed
+func (r *InputReader) gatherInstance(idx int, ins inputs.Instance, slist *types.SampleList) (failed bool) {
+	defer func() {
+		if rc := recover(); rc != nil {
+			r.log.Errorf("gather metrics panic: %v %s", rc, runtimex.Stack(3))
+			failed = true
+		}
+	}()
+
+	r.gatherMeasured(ins, r.instanceIDs[idx], slist)
+	return gatherFailed(slist)
+}
+
+func (r *InputReader) recordGather(idx int, ins inputs.Instance, failed bool) {
+	instance := r.instanceIDs[idx]
+	if failed {
+		pluginUp.WithLabelValues(r.inputName, instance).Set(0)
+	} else {
+		pluginUp.WithLabelValues(r.inputName, instance).Set(1)
+	}
+
+	cb := r.breakers[idx]
+	if !cb.enabled() {
+		return
+	}
+
+	interval := r.interval
+	if it := ins.GetIntervalTimes(); it > 0 {
+		interval *= time.Duration(it)
+	}
+
+	if cb.record(failed, interval, time.Now()) {
+		if cb.isOpen() {
+			circuitBreakerTrips.WithLabelValues(r.inputName, instance).Inc()
+			r.log.With("instance", idx).Warnf("failed %d times in a row, circuit breaker opened", cb.failures)
+		} else {
+			r.log.With("instance", idx).Infof("recovered, circuit breaker closed")
+		}
+	}
+
+	if cb.isOpen() {
+		circuitBreakerOpen.WithLabelValues(r.inputName, instance).Set(1)
+	} else {
+		circuitBreakerOpen.WithLabelValues(r.inputName, instance).Set(0)
+	}
+}
+
 func (r *InputReader) forward(slist *types.SampleList) {
 	if slist == nil {
 		return
 	}
 	arr := slist.PopBackAll()
-	writer.WriteSamples(arr)
+	writer.WriteSamplesWithPriority(arr, r.input.GetPriority())
+	types.ReleaseSamples(arr)
 }
@@ -0,0 +1,47 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"flashcat.cloud/categraf/inputs"
+)
+
+// pluginUp is the heartbeat of inputs, exposed as categraf_plugin_up by self_metrics input.
+// a gather is treated as failed if it panics or all of its up samples are 0, see gatherFailed
+var pluginUp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "plugin_up",
+	Help: "Whether the last gather of the input instance succeeded, instance is the id of the instance config, empty for plugins without instances.",
+}, []string{"plugin", "instance"})
+
+func init() {
+	prometheus.MustRegister(pluginUp)
+}
+
+// instanceIDs identifies the instances by the hashes of their configs, so the series of an instance are kept across
+// reloads, and never collide with the ones of the other readers of the same input, i.e. of the configs of other sums.
+// The ids need to be taken before Init, which may fill the instances with clients. If the config of an instance
+// can't be hashed, the sum and the index of the instance are hashed instead, the same instances are told apart by
+// their indexes too
+func instanceIDs(sum string, instances []inputs.Instance) []string {
+	ids := make([]string, len(instances))
+	seen := make(map[string]bool, len(instances))
+	for i, ins := range instances {
+		bs, err := json.Marshal(ins)
+		if err != nil {
+			bs = []byte(fmt.Sprintf("%s#%d", sum, i))
+		}
+		h := fnv.New32a()
+		h.Write(bs)
+		id := fmt.Sprintf("%08x", h.Sum32())
+		if seen[id] {
+			id = fmt.Sprintf("%s-%d", id, i)
+		}
+		seen[id] = true
+		ids[i] = id
+	}
+	return ids
+}
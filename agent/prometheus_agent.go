@@ -3,8 +3,6 @@
 package agent
 
 import (
-	"log"
-
 	coreconfig "flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/prometheus"
 )
@@ -16,7 +14,7 @@ func NewPrometheusAgent() AgentModule {
 	if coreconfig.Config == nil ||
 		coreconfig.Config.Prometheus == nil ||
 		!coreconfig.Config.Prometheus.Enable {
-		log.Println("I! prometheus scraping disabled!")
+		agentLog.Infof("prometheus scraping disabled!")
 		return nil
 	}
 	return &PrometheusAgent{}
@@ -24,12 +22,12 @@ func NewPrometheusAgent() AgentModule {
 
 func (pa *PrometheusAgent) Start() error {
 	go prometheus.Start()
-	log.Println("I! prometheus scraping started!")
+	agentLog.Infof("prometheus scraping started!")
 	return nil
 }
 
 func (pa *PrometheusAgent) Stop() error {
 	prometheus.Stop()
-	log.Println("I! prometheus scraping stopped!")
+	agentLog.Infof("prometheus scraping stopped!")
 	return nil
 }
@@ -0,0 +1,119 @@
+package agent
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/filter"
+	"flashcat.cloud/categraf/types"
+)
+
+const defaultRecorderMaxSizeMB = 100
+
+// RecordedBatch is a line of the file of recorder, the samples gathered once by an instance, before its processors
+type RecordedBatch struct {
+	Time  time.Time `json:"time"`
+	Input string    `json:"input"`
+	// the index of instance, empty for the samples of plugin level
+	Instance string          `json:"instance,omitempty"`
+	Samples  []*types.Sample `json:"samples"`
+}
+
+type recorder struct {
+	sync.Mutex
+	file    *os.File
+	size    int64
+	maxSize int64
+	inputs  filter.Filter
+}
+
+var (
+	samplesRecorder     *recorder
+	samplesRecorderOnce sync.Once
+)
+
+// initRecorder opens the file of recorder once, it's kept across reloads
+func initRecorder() {
+	samplesRecorderOnce.Do(func() {
+		conf := config.Config.Recorder
+		if conf == nil || !conf.Enable || conf.File == "" {
+			return
+		}
+
+		rec := &recorder{maxSize: conf.MaxSizeMB * 1024 * 1024}
+		if rec.maxSize <= 0 {
+			rec.maxSize = defaultRecorderMaxSizeMB * 1024 * 1024
+		}
+
+		if len(conf.Inputs) > 0 {
+			var err error
+			if rec.inputs, err = filter.Compile(conf.Inputs); err != nil {
+				agentLog.Errorf("failed to compile inputs of recorder: %v", err)
+				return
+			}
+		}
+
+		f, err := os.OpenFile(conf.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			agentLog.Errorf("failed to open file of recorder: %v", err)
+			return
+		}
+
+		if st, err := f.Stat(); err == nil {
+			rec.size = st.Size()
+		}
+		rec.file = f
+		samplesRecorder = rec
+		agentLog.Infof("recording samples to %v", conf.File)
+	})
+}
+
+// record writes the samples of slist without popping them, the file is not written any more once it's full
+func (rec *recorder) record(inputName, instance string, slist *types.SampleList) {
+	if rec == nil || slist.Len() == 0 {
+		return
+	}
+
+	if rec.inputs != nil {
+		if _, inputKey := inputs.ParseInputName(inputName); !rec.inputs.Match(inputKey) {
+			return
+		}
+	}
+
+	batch := RecordedBatch{Time: time.Now(), Input: inputName, Instance: instance}
+	slist.Range(func(s *types.Sample) bool {
+		batch.Samples = append(batch.Samples, s)
+		return true
+	})
+
+	bs, err := json.Marshal(batch)
+	if err != nil {
+		agentLog.Errorf("failed to encode samples of %v for recorder: %v", inputName, err)
+		return
+	}
+	bs = append(bs, '\n')
+
+	rec.Lock()
+	defer rec.Unlock()
+
+	if rec.file == nil {
+		return
+	}
+
+	if rec.size+int64(len(bs)) > rec.maxSize {
+		agentLog.Warnf("file of recorder is full, recording stopped: %v", rec.file.Name())
+		rec.file.Close()
+		rec.file = nil
+		return
+	}
+
+	n, err := rec.file.Write(bs)
+	rec.size += int64(n)
+	if err != nil {
+		agentLog.Errorf("failed to write file of recorder: %v", err)
+	}
+}
@@ -0,0 +1,142 @@
+package agent
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/cfg"
+	"flashcat.cloud/categraf/types"
+	"flashcat.cloud/categraf/writer"
+)
+
+// the samples of a batch are in one line
+const maxReplayLineSize = 64 * 1024 * 1024
+
+type replayInput struct {
+	input     inputs.Input
+	instances []inputs.Instance
+}
+
+// RunReplay feeds the batches recorded by the recorder through the processors of the inputs in the config dir,
+// and the global blocklist and writers, the samples are printed instead in test mode. the inputs
+// are not initialized, so nothing is connected. returns the exit code
+func RunReplay(file string, w io.Writer) int {
+	f, err := os.Open(file)
+	if err != nil {
+		fmt.Fprintln(w, "E! failed to open file:", err)
+		return 1
+	}
+	defer f.Close()
+
+	var (
+		loaded = make(map[string]*replayInput)
+		lineNo int
+		// batches, samples read and samples written
+		batches, read, written int
+		errs                   int
+	)
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), maxReplayLineSize)
+	for scanner.Scan() {
+		lineNo++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var batch RecordedBatch
+		if err := json.Unmarshal(scanner.Bytes(), &batch); err != nil {
+			fmt.Fprintf(w, "E! %s:%d: %v\n", file, lineNo, err)
+			errs++
+			continue
+		}
+
+		in, has := loaded[batch.Input]
+		if !has {
+			in, err = loadReplayInput(batch.Input)
+			if err != nil {
+				fmt.Fprintf(w, "E! %s:%d: %v\n", file, lineNo, err)
+				errs++
+			}
+			loaded[batch.Input] = in
+		}
+		if in == nil {
+			continue
+		}
+
+		var processor interface {
+			Process(*types.SampleList) *types.SampleList
+		} = in.input
+
+		if batch.Instance != "" {
+			idx, err := strconv.Atoi(batch.Instance)
+			if err != nil || idx < 0 || idx >= len(in.instances) {
+				fmt.Fprintf(w, "W! %s:%d: instance %s of input %s not found in %s, skipped\n",
+					file, lineNo, batch.Instance, batch.Input, config.Config.ConfigDir)
+				continue
+			}
+			processor = in.instances[idx]
+		}
+
+		slist := types.NewSampleList()
+		for _, s := range batch.Samples {
+			if s == nil {
+				continue
+			}
+			if s.Labels == nil {
+				s.Labels = make(map[string]string)
+			}
+			slist.PushFront(s)
+		}
+		batches++
+		read += slist.Len()
+
+		samples := processor.Process(slist).PopBackAll()
+		written += len(samples)
+		writer.WriteSamplesNow(samples)
+	}
+
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintf(w, "E! %s:%d: %v\n", file, lineNo+1, err)
+		return 1
+	}
+
+	fmt.Fprintf(w, "%d batches replayed, %d samples read, %d samples after processors\n", batches, read, written)
+	if errs > 0 {
+		return 1
+	}
+	return 0
+}
+
+// loadReplayInput loads the configs of the input like the local provider, and initializes the internal configs only
+func loadReplayInput(name string) (*replayInput, error) {
+	_, inputKey := inputs.ParseInputName(name)
+	creator, has := inputs.InputCreators[inputKey]
+	if !has {
+		return nil, fmt.Errorf("input %s is not supported by this build", inputKey)
+	}
+
+	input := creator()
+	if err := cfg.LoadConfigByDirs(config.Layers(config.Config.ConfigDir, inputFilePrefix+inputKey), input); err != nil {
+		return nil, fmt.Errorf("failed to load configs of input %s: %v", inputKey, err)
+	}
+
+	if err := input.InitInternalConfig(); err != nil {
+		return nil, fmt.Errorf("failed to init input %s: %v", inputKey, err)
+	}
+
+	instances := inputs.MayGetInstances(input)
+	for i := range instances {
+		if err := instances[i].InitInternalConfig(); err != nil {
+			return nil, fmt.Errorf("failed to init instance %d of input %s: %v", i, inputKey, err)
+		}
+	}
+
+	return &replayInput{input: input, instances: instances}, nil
+}
@@ -4,7 +4,6 @@ package agent
 
 import (
 	"context"
-	"log"
 
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/traces"
@@ -16,16 +15,16 @@ type TracesAgent struct {
 
 func NewTracesAgent() AgentModule {
 	if config.Config.Traces == nil || !config.Config.Traces.Enable {
-		log.Println("I! traces agent disabled!")
+		agentLog.Infof("traces agent disabled!")
 		return nil
 	}
 	col, err := traces.New(config.Config.Traces)
 	if err != nil {
-		log.Println("E! failed to create traces agent:", err)
+		agentLog.Errorf("failed to create traces agent: %v", err)
 		return nil
 	}
 	if col == nil {
-		log.Println("E! failed to create traces agent, collector is nil")
+		agentLog.Errorf("failed to create traces agent, collector is nil")
 		return nil
 	}
 	return &TracesAgent{
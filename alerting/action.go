@@ -0,0 +1,185 @@
+package alerting
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/pkg/cmdx"
+)
+
+const defaultActionTimeout = 10 * time.Second
+
+type action interface {
+	name() string
+	fire(event *Event) error
+}
+
+func newActions(confs []*config.AlertAction) ([]action, error) {
+	actions := make([]action, 0, len(confs))
+	for _, c := range confs {
+		timeout := time.Duration(c.Timeout)
+		if timeout <= 0 {
+			timeout = defaultActionTimeout
+		}
+
+		switch c.Type {
+		case "exec":
+			if len(c.Command) == 0 {
+				return nil, fmt.Errorf("exec action: command is required")
+			}
+			actions = append(actions, &execAction{command: c.Command, timeout: timeout})
+		case "file":
+			if c.Path == "" {
+				return nil, fmt.Errorf("file action: path is required")
+			}
+			if err := os.MkdirAll(filepath.Dir(c.Path), 0755); err != nil {
+				return nil, fmt.Errorf("file action: %v", err)
+			}
+			actions = append(actions, &fileAction{path: c.Path})
+		case "webhook":
+			if c.Url == "" {
+				return nil, fmt.Errorf("webhook action: url is required")
+			}
+			if len(c.Headers)%2 != 0 {
+				return nil, fmt.Errorf("webhook action: headers must be key value pairs")
+			}
+			actions = append(actions, &webhookAction{
+				url:     c.Url,
+				headers: c.Headers,
+				client:  &http.Client{Timeout: timeout},
+			})
+		case "syslog":
+			tag := c.Tag
+			if tag == "" {
+				tag = "categraf"
+			}
+			a, err := newSyslogAction(c.Address, tag)
+			if err != nil {
+				return nil, fmt.Errorf("syslog action: %v", err)
+			}
+			actions = append(actions, a)
+		case "smtp":
+			if len(c.To) == 0 {
+				return nil, fmt.Errorf("smtp action: to is required")
+			}
+			actions = append(actions, &smtpAction{
+				server:   c.SMTPServer,
+				username: c.SMTPUsername,
+				password: c.SMTPPassword,
+				from:     c.From,
+				to:       c.To,
+				timeout:  timeout,
+			})
+		default:
+			return nil, fmt.Errorf("unknown action type: %s", c.Type)
+		}
+	}
+	return actions, nil
+}
+
+// execAction passes the event to command by stdin in json,
+// rule and status are also available in env CATEGRAF_ALERT_RULE and CATEGRAF_ALERT_STATUS
+type execAction struct {
+	command []string
+	timeout time.Duration
+}
+
+func (a *execAction) name() string { return "exec" }
+
+func (a *execAction) fire(event *Event) error {
+	bs, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	var stdout bytes.Buffer
+	cmd := exec.Command(a.command[0], a.command[1:]...)
+	cmd.Stdin = bytes.NewReader(bs)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stdout
+	cmd.Env = append(os.Environ(), "CATEGRAF_ALERT_RULE="+event.Rule, "CATEGRAF_ALERT_STATUS="+event.Status)
+
+	err, isTimeout := cmdx.RunTimeout(cmd, a.timeout)
+	if isTimeout {
+		return fmt.Errorf("command %v timeout after %s", a.command, a.timeout)
+	}
+	if err != nil {
+		return fmt.Errorf("command %v: %v, output: %s", a.command, err, stdout.String())
+	}
+	return nil
+}
+
+// fileAction appends events to a file in json lines
+type fileAction struct {
+	sync.Mutex
+	path string
+}
+
+func (a *fileAction) name() string { return "file" }
+
+func (a *fileAction) fire(event *Event) error {
+	bs, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	a.Lock()
+	defer a.Unlock()
+
+	f, err := os.OpenFile(a.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(bs, '\n'))
+	return err
+}
+
+// webhookAction posts the event to url in json
+type webhookAction struct {
+	url     string
+	headers []string
+	client  *http.Client
+}
+
+func (a *webhookAction) name() string { return "webhook" }
+
+func (a *webhookAction) fire(event *Event) error {
+	bs, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, a.url, bytes.NewReader(bs))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "categraf")
+	for i := 0; i < len(a.headers); i += 2 {
+		req.Header.Set(a.headers[i], a.headers[i+1])
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("webhook %s response status: %d, body: %s", a.url, resp.StatusCode, body)
+	}
+	return nil
+}
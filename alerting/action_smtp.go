@@ -0,0 +1,156 @@
+package alerting
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/smtp"
+	"sort"
+	"strings"
+	"time"
+
+	"flashcat.cloud/categraf/config"
+)
+
+// smtpAction mails the event by the smtp server, or directly to the mx of the recipients if no
+// server configured, so it works without any other service, e.g. when the network to the mail relay is down
+type smtpAction struct {
+	server   string
+	username string
+	password string
+	// categraf@hostname by default
+	from    string
+	to      []string
+	timeout time.Duration
+}
+
+func (a *smtpAction) name() string { return "smtp" }
+
+func (a *smtpAction) fire(event *Event) error {
+	from := a.from
+	if from == "" {
+		from = "categraf@" + event.Hostname
+	}
+
+	msg, err := a.message(event, from)
+	if err != nil {
+		return err
+	}
+
+	if a.server != "" {
+		return sendMail(a.server, a.username, a.password, from, a.to, msg, a.timeout)
+	}
+
+	// the recipients of a domain are sent to its mx of the highest preference reachable
+	domains := make(map[string][]string)
+	for _, to := range a.to {
+		i := strings.LastIndex(to, "@")
+		if i < 0 {
+			return fmt.Errorf("invalid recipient %s", to)
+		}
+		domain := to[i+1:]
+		domains[domain] = append(domains[domain], to)
+	}
+
+	var errs []string
+	for domain, to := range domains {
+		if err := sendDirect(domain, from, to, msg, a.timeout); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func (a *smtpAction) message(event *Event, from string) ([]byte, error) {
+	bs, err := json.MarshalIndent(event, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(a.to, ", "))
+	fmt.Fprintf(&buf, "Subject: [categraf] %s %s on %s\r\n", event.Rule, event.Status, event.Hostname)
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Unix(event.Time, 0).Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	buf.Write(bytes.ReplaceAll(bs, []byte("\n"), []byte("\r\n")))
+	buf.WriteString("\r\n")
+	return buf.Bytes(), nil
+}
+
+func sendDirect(domain, from string, to []string, msg []byte, timeout time.Duration) error {
+	mxs, err := net.LookupMX(domain)
+	if err != nil {
+		return fmt.Errorf("failed to lookup mx of %s: %v", domain, err)
+	}
+	sort.Slice(mxs, func(i, j int) bool { return mxs[i].Pref < mxs[j].Pref })
+
+	for _, mx := range mxs {
+		addr := net.JoinHostPort(strings.TrimSuffix(mx.Host, "."), "25")
+		if err = sendMail(addr, "", "", from, to, msg, timeout); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("failed to send to mx of %s: %v", domain, err)
+}
+
+// sendMail is smtp.SendMail with the timeout of the whole session, STARTTLS is used if supported
+func sendMail(addr, username, password, from string, to []string, msg []byte, timeout time.Duration) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if err := c.Hello(config.Config.GetHostname()); err != nil {
+		return err
+	}
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if username != "" {
+		if err := c.Auth(smtp.PlainAuth("", username, password, host)); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Mail(from); err != nil {
+		return err
+	}
+	for _, rcpt := range to {
+		if err := c.Rcpt(rcpt); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
@@ -0,0 +1,51 @@
+//go:build !windows
+// +build !windows
+
+package alerting
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/syslog"
+	"strings"
+)
+
+// syslogAction logs the event in json, firing events at err level and resolved ones at info level
+type syslogAction struct {
+	network string
+	address string
+	tag     string
+}
+
+func newSyslogAction(address, tag string) (action, error) {
+	a := &syslogAction{tag: tag}
+	if address != "" {
+		parts := strings.SplitN(address, "://", 2)
+		if len(parts) != 2 || (parts[0] != "udp" && parts[0] != "tcp") {
+			return nil, fmt.Errorf("invalid address %s, e.g. udp://127.0.0.1:514", address)
+		}
+		a.network, a.address = parts[0], parts[1]
+	}
+	return a, nil
+}
+
+func (a *syslogAction) name() string { return "syslog" }
+
+func (a *syslogAction) fire(event *Event) error {
+	bs, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	w, err := syslog.Dial(a.network, a.address, syslog.LOG_DAEMON|syslog.LOG_ERR, a.tag)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	msg := fmt.Sprintf("alert %s %s: %s", event.Rule, event.Status, bs)
+	if event.Status == "resolved" {
+		return w.Info(msg)
+	}
+	return w.Err(msg)
+}
@@ -0,0 +1,12 @@
+//go:build windows
+// +build windows
+
+package alerting
+
+import (
+	"fmt"
+)
+
+func newSyslogAction(_, _ string) (action, error) {
+	return nil, fmt.Errorf("syslog action is not supported on windows")
+}
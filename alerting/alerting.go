@@ -0,0 +1,419 @@
+package alerting
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/pkg/conv"
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/pkg/state"
+	"flashcat.cloud/categraf/types"
+)
+
+var alertingLog = logger.New("alerting")
+
+const (
+	defaultCheckInterval = 15 * time.Second
+	defaultStaleAfter    = 5 * time.Minute
+
+	// the namespace of the states of the threshold rules in pkg/state, keyed by the rule names
+	stateNamespace = "alerting"
+)
+
+var (
+	alertEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "alerting_events_total",
+		Help: "Number of alert events fired by in-agent rules.",
+	}, []string{"rule", "status"})
+
+	alertActionErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "alerting_action_errors_total",
+		Help: "Number of failed alert actions.",
+	}, []string{"rule", "type"})
+)
+
+func init() {
+	prometheus.MustRegister(alertEvents, alertActionErrors)
+}
+
+// Event is passed to actions when an alert fires or resolves
+type Event struct {
+	Rule     string            `json:"rule"`
+	Status   string            `json:"status"` // firing | resolved
+	Metric   string            `json:"metric,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Value    float64           `json:"value"`
+	Hostname string            `json:"hostname"`
+	Time     int64             `json:"time"`
+}
+
+type alertState struct {
+	metric   string
+	labels   map[string]string
+	value    float64
+	since    time.Time
+	firing   bool
+	notified time.Time
+	// the series is forgotten once it's not seen for StaleAfter
+	lastSeen time.Time
+	// the time the value stops matching, and the value then, the state is resolved by the next check
+	until         time.Time
+	resolvedValue float64
+}
+
+// savedState is an alertState persisted across restarts, so the alerts firing are not notified again,
+// and the pending ones keep how long they have lasted for
+type savedState struct {
+	Metric        string            `json:"metric"`
+	Labels        map[string]string `json:"labels"`
+	Value         float64           `json:"value"`
+	Since         time.Time         `json:"since"`
+	Firing        bool              `json:"firing"`
+	Notified      time.Time         `json:"notified"`
+	LastSeen      time.Time         `json:"last_seen"`
+	Until         time.Time         `json:"until"`
+	ResolvedValue float64           `json:"resolved_value"`
+}
+
+type rule struct {
+	*config.AlertRule
+	actions []action
+
+	// threshold rule, keyed by series
+	states map[string]*alertState
+
+	// absence rule
+	lastSeen time.Time
+	absent   *alertState
+}
+
+type engine struct {
+	sync.Mutex
+	rules []*rule
+}
+
+var e *engine
+
+// Init compiles the rules and starts checking, Observe is a no-op if alerting is disabled
+func Init() error {
+	conf := config.Config.Alerting
+	if conf == nil || !conf.Enable || len(conf.Rules) == 0 {
+		return nil
+	}
+
+	eng := &engine{}
+	now := time.Now()
+	for i, r := range conf.Rules {
+		if r.Name == "" {
+			r.Name = fmt.Sprintf("rule_%d", i)
+		}
+		if err := r.SampleMatcher.Init(); err != nil {
+			return fmt.Errorf("alerting rule %s: %v", r.Name, err)
+		}
+		if !r.HasValue() && r.AbsentFor <= 0 {
+			return fmt.Errorf("alerting rule %s: either value or absent_for is required", r.Name)
+		}
+		if r.StaleAfter <= 0 {
+			r.StaleAfter = config.Duration(defaultStaleAfter)
+		}
+
+		actions, err := newActions(r.Actions)
+		if err != nil {
+			return fmt.Errorf("alerting rule %s: %v", r.Name, err)
+		}
+
+		nr := &rule{
+			AlertRule: r,
+			actions:   actions,
+			states:    make(map[string]*alertState),
+			lastSeen:  now,
+		}
+		nr.restore()
+		eng.rules = append(eng.rules, nr)
+	}
+
+	interval := time.Duration(conf.CheckInterval)
+	if interval <= 0 {
+		interval = defaultCheckInterval
+	}
+
+	e = eng
+	// the samples of inputs are observed before downsample, the others by the routers writing them
+	config.ObserveSamples = Observe
+	go e.loopCheck(interval)
+	alertingLog.Infof("alerting started, rules: %v", len(e.rules))
+	return nil
+}
+
+// Observe records samples before writing, e.g. by the processing of inputs and the pushgateway api,
+// the rules are evaluated by the checks every check_interval, so the writing path only pays the matching
+func Observe(samples []*types.Sample) {
+	if e == nil || len(samples) == 0 {
+		return
+	}
+
+	// the rules are immutable, the samples are matched out of the lock
+	matched := make([][]*types.Sample, len(e.rules))
+	for i, r := range e.rules {
+		for _, s := range samples {
+			if s != nil && r.MatchSeries(s) {
+				matched[i] = append(matched[i], s)
+			}
+		}
+	}
+
+	now := time.Now()
+	e.Lock()
+	defer e.Unlock()
+
+	for i, r := range e.rules {
+		if len(matched[i]) == 0 {
+			continue
+		}
+		r.lastSeen = now
+		if !r.HasValue() {
+			continue
+		}
+		for _, s := range matched[i] {
+			r.observe(s, now)
+		}
+	}
+}
+
+func (r *rule) observe(s *types.Sample, now time.Time) {
+	key := stateKey(s.Metric, s.Labels)
+	st, has := r.states[key]
+
+	value, _ := conv.ToFloat64(s.Value)
+	if !r.MatchValue(s.Value) {
+		if has && st.until.IsZero() {
+			st.until = now
+			st.resolvedValue = value
+		}
+		return
+	}
+
+	if has && !st.until.IsZero() {
+		if st.firing {
+			// matches again before the check, it's still firing
+			st.until = time.Time{}
+		} else {
+			// a pending alert restarts
+			has = false
+		}
+	}
+	if !has {
+		labels := make(map[string]string, len(s.Labels))
+		for k, v := range s.Labels {
+			labels[k] = v
+		}
+		st = &alertState{metric: s.Metric, labels: labels, since: now}
+		r.states[key] = st
+	}
+	st.value = value
+	st.lastSeen = now
+}
+
+// check fires pending alerts lasting for r.For, absence and repeated notifications,
+// and resolves the alerts of the series gone, e.g. of an unmounted disk
+func (r *rule) check(now time.Time) {
+	for key, st := range r.states {
+		if now.Sub(st.lastSeen) >= time.Duration(r.StaleAfter) {
+			if st.firing {
+				r.notify(st, "resolved", now)
+			}
+			delete(r.states, key)
+			continue
+		}
+
+		// the condition held from since till until, or till now if it still holds,
+		// so a spike between two checks fires too
+		held := now
+		if !st.until.IsZero() {
+			held = st.until
+		}
+		if !st.firing {
+			if held.Sub(st.since) >= time.Duration(r.For) {
+				st.firing = true
+				r.notify(st, "firing", now)
+			}
+		} else if st.until.IsZero() && r.RepeatInterval > 0 && now.Sub(st.notified) >= time.Duration(r.RepeatInterval) {
+			r.notify(st, "firing", now)
+		}
+
+		if !st.until.IsZero() {
+			if st.firing {
+				st.value = st.resolvedValue
+				r.notify(st, "resolved", now)
+			}
+			delete(r.states, key)
+		}
+	}
+
+	if r.AbsentFor <= 0 {
+		return
+	}
+
+	if r.absent != nil && r.lastSeen.After(r.absent.since) {
+		r.notify(r.absent, "resolved", now)
+		r.absent = nil
+	}
+
+	if r.absent == nil {
+		if now.Sub(r.lastSeen) >= time.Duration(r.AbsentFor) {
+			r.absent = &alertState{since: r.lastSeen, firing: true}
+			r.notify(r.absent, "firing", now)
+		}
+		return
+	}
+
+	if r.RepeatInterval > 0 && now.Sub(r.absent.notified) >= time.Duration(r.RepeatInterval) {
+		r.notify(r.absent, "firing", now)
+	}
+}
+
+func (e *engine) loopCheck(interval time.Duration) {
+	for {
+		time.Sleep(interval)
+		now := time.Now()
+		e.Lock()
+		for _, r := range e.rules {
+			r.check(now)
+			r.save()
+		}
+		e.Unlock()
+	}
+}
+
+// restore loads the states of the threshold rule saved before restart, the series not seen since
+// are resolved by the first check as stale. The absence is not restored, it's counted from the start
+func (r *rule) restore() {
+	if !r.HasValue() {
+		return
+	}
+	// a list rather than the map of states, the keys are not valid utf-8 of json
+	var saved []*savedState
+	if !state.Get(stateNamespace, r.Name, &saved, 0) {
+		return
+	}
+	for _, s := range saved {
+		r.states[stateKey(s.Metric, s.Labels)] = &alertState{
+			metric:        s.Metric,
+			labels:        s.Labels,
+			value:         s.Value,
+			since:         s.Since,
+			firing:        s.Firing,
+			notified:      s.Notified,
+			lastSeen:      s.LastSeen,
+			until:         s.Until,
+			resolvedValue: s.ResolvedValue,
+		}
+	}
+}
+
+func (r *rule) save() {
+	if !r.HasValue() {
+		return
+	}
+	saved := make([]*savedState, 0, len(r.states))
+	for _, st := range r.states {
+		saved = append(saved, &savedState{
+			Metric:        st.metric,
+			Labels:        st.labels,
+			Value:         st.value,
+			Since:         st.since,
+			Firing:        st.firing,
+			Notified:      st.notified,
+			LastSeen:      st.lastSeen,
+			Until:         st.until,
+			ResolvedValue: st.resolvedValue,
+		})
+	}
+	if err := state.Put(stateNamespace, r.Name, saved); err != nil {
+		alertingLog.Warnf("failed to save the states of rule %s: %v", r.Name, err)
+	}
+}
+
+// notify runs actions asynchronously, so the writing path never blocks on actions
+func (r *rule) notify(st *alertState, status string, now time.Time) {
+	st.notified = now
+	alertEvents.WithLabelValues(r.Name, status).Inc()
+
+	event := &Event{
+		Rule:     r.Name,
+		Status:   status,
+		Metric:   st.metric,
+		Labels:   st.labels,
+		Value:    st.value,
+		Hostname: config.Config.GetHostname(),
+		Time:     now.Unix(),
+	}
+
+	alertingLog.Infof("alert %s %s, metric: %s labels: %v value: %v", r.Name, status, event.Metric, event.Labels, event.Value)
+	fire(r.actions, event)
+}
+
+func fire(actions []action, event *Event) {
+	for _, a := range actions {
+		go func(a action) {
+			if err := a.fire(event); err != nil {
+				alertActionErrors.WithLabelValues(event.Rule, a.name()).Inc()
+				alertingLog.Errorf("alert %s: failed to run %s action: %v", event.Rule, a.name(), err)
+			}
+		}(a)
+	}
+}
+
+// Notifier fires the actions of an alert raised out of rules, e.g. by writers when the backends are unreachable
+type Notifier struct {
+	rule    string
+	actions []action
+}
+
+func NewNotifier(rule string, confs []*config.AlertAction) (*Notifier, error) {
+	if len(confs) == 0 {
+		return nil, fmt.Errorf("alert %s: actions are required", rule)
+	}
+	actions, err := newActions(confs)
+	if err != nil {
+		return nil, fmt.Errorf("alert %s: %v", rule, err)
+	}
+	return &Notifier{rule: rule, actions: actions}, nil
+}
+
+// Notify runs the actions asynchronously, status is firing or resolved
+func (n *Notifier) Notify(status string, labels map[string]string, value float64) {
+	alertEvents.WithLabelValues(n.rule, status).Inc()
+
+	event := &Event{
+		Rule:     n.rule,
+		Status:   status,
+		Labels:   labels,
+		Value:    value,
+		Hostname: config.Config.GetHostname(),
+		Time:     time.Now().Unix(),
+	}
+
+	alertingLog.Infof("alert %s %s, labels: %v value: %v", n.rule, status, labels, value)
+	fire(n.actions, event)
+}
+
+// stateKey identifies the series of the states of a threshold rule
+func stateKey(metric string, labels map[string]string) string {
+	return metric + "\xfe" + labelsKey(labels)
+}
+
+func labelsKey(labels map[string]string) string {
+	arr := make([]string, 0, len(labels))
+	for k, v := range labels {
+		arr = append(arr, k+"\xff"+v)
+	}
+	sort.Strings(arr)
+	return strings.Join(arr, "\xfe")
+}
@@ -0,0 +1,145 @@
+package alerting
+
+import (
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/pkg/state"
+	"flashcat.cloud/categraf/types"
+)
+
+func eventsTotal(t *testing.T, rule, status string) float64 {
+	m := &dto.Metric{}
+	require.NoError(t, alertEvents.WithLabelValues(rule, status).Write(m))
+	return m.GetCounter().GetValue()
+}
+
+// a spike within a downsample interval is averaged out of the samples written, but fires the rules in agent
+func TestSpikeWithinDownsampleInterval(t *testing.T) {
+	config.Config = &config.ConfigType{
+		Global: config.Global{OmitHostname: true},
+		Alerting: &config.AlertingConfig{
+			Enable:        true,
+			CheckInterval: config.Duration(time.Hour),
+			Rules: []*config.AlertRule{{
+				Name:          "cpu_spike",
+				SampleMatcher: config.SampleMatcher{Metrics: []string{"cpu_usage_active"}, Value: "> 90"},
+			}},
+		},
+	}
+	config.Hostname = &config.HostnameCache{}
+	defer func() {
+		e = nil
+		config.ObserveSamples = nil
+	}()
+	require.NoError(t, Init())
+
+	ic := &config.InternalConfig{
+		Downsample: &config.Downsample{Interval: config.Duration(time.Hour), Aggregations: []string{"avg"}},
+	}
+	require.NoError(t, ic.InitInternalConfig())
+
+	for _, v := range []float64{10, 95, 10} {
+		slist := types.NewSampleList()
+		slist.PushSample("cpu", "usage_active", v, map[string]string{"cpu": "cpu-total"})
+		// the samples are buffered till the end of the interval
+		assert.Equal(t, 0, ic.Process(slist).Len())
+	}
+
+	e.Lock()
+	for _, r := range e.rules {
+		r.check(time.Now())
+	}
+	e.Unlock()
+
+	assert.Equal(t, float64(1), eventsTotal(t, "cpu_spike", "firing"))
+	assert.Equal(t, float64(1), eventsTotal(t, "cpu_spike", "resolved"))
+}
+
+// the samples are only recorded by Observe, the alerts are fired and resolved by the checks
+func TestObserveRecordsOnly(t *testing.T) {
+	config.Hostname = &config.HostnameCache{}
+	r := &rule{
+		AlertRule: &config.AlertRule{
+			Name:          "disk_full",
+			SampleMatcher: config.SampleMatcher{Metrics: []string{"disk_used_percent"}, Value: "> 90"},
+			For:           config.Duration(time.Minute),
+			StaleAfter:    config.Duration(defaultStaleAfter),
+		},
+		states: make(map[string]*alertState),
+	}
+	require.NoError(t, r.SampleMatcher.Init())
+	e = &engine{rules: []*rule{r}}
+	defer func() {
+		e = nil
+	}()
+
+	observe := func(v float64) {
+		Observe([]*types.Sample{types.NewSample("disk", "used_percent", v, map[string]string{"path": "/"})})
+	}
+
+	observe(95)
+	assert.Equal(t, float64(0), eventsTotal(t, "disk_full", "firing"))
+
+	// pending for less than for
+	r.check(time.Now())
+	assert.Equal(t, float64(0), eventsTotal(t, "disk_full", "firing"))
+
+	now := time.Now().Add(2 * time.Minute)
+	r.check(now)
+	assert.Equal(t, float64(1), eventsTotal(t, "disk_full", "firing"))
+
+	observe(50)
+	assert.Equal(t, float64(0), eventsTotal(t, "disk_full", "resolved"))
+	r.check(now)
+	assert.Equal(t, float64(1), eventsTotal(t, "disk_full", "resolved"))
+	assert.Empty(t, r.states)
+
+	// a pending alert back to normal before lasting for is not fired
+	observe(95)
+	observe(50)
+	r.check(time.Now().Add(2 * time.Minute))
+	assert.Equal(t, float64(1), eventsTotal(t, "disk_full", "firing"))
+	assert.Empty(t, r.states)
+}
+
+// the alerts firing are restored after restart, and not notified again
+func TestRestoreStates(t *testing.T) {
+	config.Hostname = &config.HostnameCache{}
+	newRule := func() *rule {
+		r := &rule{
+			AlertRule: &config.AlertRule{
+				Name:          "mem_full",
+				SampleMatcher: config.SampleMatcher{Metrics: []string{"mem_used_percent"}, Value: "> 90"},
+				StaleAfter:    config.Duration(defaultStaleAfter),
+			},
+			states: make(map[string]*alertState),
+		}
+		require.NoError(t, r.SampleMatcher.Init())
+		r.restore()
+		return r
+	}
+	defer state.Delete(stateNamespace, "mem_full")
+
+	r := newRule()
+	now := time.Now()
+	r.observe(types.NewSample("mem", "used_percent", 95, nil), now)
+	r.check(now)
+	r.save()
+	require.Equal(t, float64(1), eventsTotal(t, "mem_full", "firing"))
+
+	r = newRule()
+	require.Len(t, r.states, 1)
+	r.observe(types.NewSample("mem", "used_percent", 96, nil), now)
+	r.check(now)
+	assert.Equal(t, float64(1), eventsTotal(t, "mem_full", "firing"))
+
+	r.observe(types.NewSample("mem", "used_percent", 50, nil), now)
+	r.check(now)
+	assert.Equal(t, float64(1), eventsTotal(t, "mem_full", "resolved"))
+}
@@ -0,0 +1,54 @@
+//go:build !no_api
+
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"flashcat.cloud/categraf/inputs/cronjob"
+)
+
+// cronjobReport receives the start and finish of cron jobs, the event is start,
+// success, fail or the exit code, the duration is optional, e.g. 90s or 90
+func cronjobReport(c *gin.Context) {
+	job := c.Param("job")
+	event := c.Param("event")
+
+	var err error
+	switch event {
+	case "start":
+		err = cronjob.Start(job)
+	case "success":
+		err = cronjob.Finish(job, 0, parseJobDuration(c.Query("duration")))
+	case "fail":
+		err = cronjob.Finish(job, 1, parseJobDuration(c.Query("duration")))
+	default:
+		code, perr := strconv.Atoi(event)
+		if perr != nil {
+			c.String(http.StatusBadRequest, "invalid event: "+event)
+			return
+		}
+		err = cronjob.Finish(job, code, parseJobDuration(c.Query("duration")))
+	}
+
+	if err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.String(http.StatusOK, "ok")
+}
+
+func parseJobDuration(s string) time.Duration {
+	if d, err := time.ParseDuration(s); err == nil {
+		return d
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.Duration(f * float64(time.Second))
+	}
+	return 0
+}
@@ -0,0 +1,33 @@
+//go:build !no_api
+
+package api
+
+import (
+	"net/http/pprof"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// debugPprof serves the profiles of net/http/pprof, e.g. /debug/pprof/goroutine?debug=2
+func debugPprof(c *gin.Context) {
+	name := strings.Trim(c.Param("name"), "/")
+	switch name {
+	case "":
+		pprof.Index(c.Writer, c.Request)
+	case "cmdline":
+		pprof.Cmdline(c.Writer, c.Request)
+	case "profile":
+		pprof.Profile(c.Writer, c.Request)
+	case "symbol":
+		pprof.Symbol(c.Writer, c.Request)
+	case "trace":
+		pprof.Trace(c.Writer, c.Request)
+	default:
+		pprof.Handler(name).ServeHTTP(c.Writer, c.Request)
+	}
+}
+
+// debugMetrics serves the self metrics of categraf, which are gathered by input self_metrics too
+var debugMetrics = gin.WrapH(promhttp.Handler())
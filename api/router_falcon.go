@@ -1,9 +1,10 @@
+//go:build !no_api
+
 package api
 
 import (
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
 	"strconv"
 	"strings"
@@ -14,9 +15,12 @@ import (
 	"github.com/prometheus/prometheus/prompb"
 
 	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/writer"
 )
 
+var apiLog = logger.New("api")
+
 type FalconMetric struct {
 	Metric       string      `json:"metric"`
 	Endpoint     string      `json:"endpoint"`
@@ -207,7 +211,7 @@ func openFalcon(c *gin.Context) {
 	}
 
 	if fail > 0 {
-		log.Println("falcon forwarder error, message:", string(bytes))
+		apiLog.Infof("falcon forwarder error, message: %v", string(bytes))
 	}
 
 	writer.WriteTimeSeries(series)
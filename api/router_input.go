@@ -0,0 +1,42 @@
+//go:build !no_api
+
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"flashcat.cloud/categraf/pkg/pause"
+)
+
+// pausedInputs returns the paused input instances, of both the api and the pause file
+func pausedInputs(c *gin.Context) {
+	c.JSON(http.StatusOK, pause.List())
+}
+
+// pauseInput pauses the instance of input, or all instances without instance, the instance is the id of
+// the instance label of categraf_plugin_up, e.g. PUT /api/inputs/mysql/pause?instance=3f2a9c1b&duration=2h&reason=upgrading
+func pauseInput(c *gin.Context) {
+	var d time.Duration
+	if s := c.Query("duration"); s != "" {
+		var err error
+		if d, err = time.ParseDuration(s); err != nil || d <= 0 {
+			c.String(http.StatusBadRequest, "invalid duration: "+s)
+			return
+		}
+	}
+
+	e := pause.Pause(c.Param("input"), c.Query("instance"), d, c.Query("reason"))
+	c.JSON(http.StatusOK, e)
+}
+
+// resumeInput resumes the instance of input paused through the api
+func resumeInput(c *gin.Context) {
+	if err := pause.Resume(c.Param("input"), c.Query("instance")); err != nil {
+		c.String(http.StatusConflict, err.Error())
+		return
+	}
+	c.String(http.StatusOK, "ok")
+}
@@ -0,0 +1,72 @@
+//go:build !no_api
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"flashcat.cloud/categraf/config"
+)
+
+const maxK8sAuditPayloadBytes = 32 * 1024 * 1024
+
+var k8sAuditEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "k8s_audit_events_total",
+	Help: "Number of kubernetes audit events received by the webhook.",
+}, []string{"status"})
+
+func init() {
+	prometheus.MustRegister(k8sAuditEvents)
+}
+
+var (
+	k8sAuditChannel     *config.LogChannel
+	k8sAuditChannelOnce sync.Once
+)
+
+// k8sAudit receives the EventList of kubernetes audit webhook backend,
+// each event is forwarded as a log message with the fields extracted into tags
+func k8sAudit(c *gin.Context) {
+	k8sAuditChannelOnce.Do(func() {
+		k8sAuditChannel = config.NewLogChannel("k8s_audit_webhook", "kubernetes", "k8s_audit", "k8s_audit")
+	})
+
+	body, err := ioutil.ReadAll(io.LimitReader(c.Request.Body, maxK8sAuditPayloadBytes))
+	if err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var list struct {
+		Items []json.RawMessage `json:"items"`
+	}
+	if err := json.Unmarshal(body, &list); err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	for _, item := range list.Items {
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, item); err != nil {
+			k8sAuditEvents.WithLabelValues("invalid").Inc()
+			continue
+		}
+
+		if k8sAuditChannel.Send(buf.Bytes()) {
+			k8sAuditEvents.WithLabelValues("forwarded").Inc()
+		} else {
+			// the logs agent is not running or falls behind
+			k8sAuditEvents.WithLabelValues("dropped").Inc()
+		}
+	}
+
+	c.String(http.StatusOK, "ok")
+}
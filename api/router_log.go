@@ -0,0 +1,40 @@
+//go:build !no_api
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"flashcat.cloud/categraf/pkg/logger"
+)
+
+// logLevels returns the default level and the levels of components
+func logLevels(c *gin.Context) {
+	level, levels := logger.Levels()
+	components := make(map[string]string, len(levels))
+	for component, l := range levels {
+		components[component] = l.String()
+	}
+	c.JSON(http.StatusOK, gin.H{"level": level.String(), "components": components})
+}
+
+// setLogLevel changes the level of the component, or the default level without component,
+// e.g. PUT /api/log/levels/input.mysql?level=debug
+func setLogLevel(c *gin.Context) {
+	level, err := logger.ParseLevel(c.Query("level"))
+	if err != nil || c.Query("level") == "" {
+		c.String(http.StatusBadRequest, "invalid level: "+c.Query("level"))
+		return
+	}
+
+	logger.SetLevel(c.Param("component"), level)
+	c.String(http.StatusOK, "ok")
+}
+
+// resetLogLevel removes the level of the component, the default level applies to it again
+func resetLogLevel(c *gin.Context) {
+	logger.ResetLevel(c.Param("component"))
+	c.String(http.StatusOK, "ok")
+}
@@ -0,0 +1,18 @@
+//go:build !no_api
+
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"flashcat.cloud/categraf/writer"
+)
+
+// exposeMetrics serves the last values of collected series in prometheus text format
+func exposeMetrics(c *gin.Context) {
+	c.Header("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	c.Status(200)
+	if err := writer.WriteText(c.Writer); err != nil {
+		apiLog.Errorf("failed to write metrics: %v", err)
+	}
+}
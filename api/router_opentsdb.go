@@ -1,9 +1,10 @@
+//go:build !no_api
+
 package api
 
 import (
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
 	"strconv"
 	"strings"
@@ -156,7 +157,7 @@ func openTSDB(c *gin.Context) {
 	series := make([]prompb.TimeSeries, 0, count)
 	for i := 0; i < len(list); i++ {
 		if err := list[i].Clean(ts); err != nil {
-			log.Println("clean opentsdb sample:", err)
+			apiLog.Infof("clean opentsdb sample: %v", err)
 			if fail == 0 {
 				msg = fmt.Sprintf("%s , Error clean: %s", msg, err.Error())
 			}
@@ -179,7 +180,7 @@ func openTSDB(c *gin.Context) {
 
 		pt, err := list[i].ToProm()
 		if err != nil {
-			log.Println("convert opentsdb sample:", err)
+			apiLog.Infof("convert opentsdb sample: %v", err)
 			if fail == 0 {
 				msg = fmt.Sprintf("%s , Error toprom: %s", msg, err.Error())
 			}
@@ -192,7 +193,7 @@ func openTSDB(c *gin.Context) {
 	}
 
 	if fail > 0 {
-		log.Println("opentsdb forwarder error, message:", string(bytes))
+		apiLog.Infof("opentsdb forwarder error, message: %v", string(bytes))
 	}
 
 	writer.WriteTimeSeries(series)
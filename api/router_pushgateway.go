@@ -1,3 +1,5 @@
+//go:build !no_api
+
 package api
 
 import (
@@ -6,6 +8,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"flashcat.cloud/categraf/alerting"
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/parser/prometheus"
 	"flashcat.cloud/categraf/types"
@@ -65,6 +68,8 @@ func pushgateway(c *gin.Context) {
 		}
 
 	}
+	// the samples of inputs are observed by their processing, but these are written directly
+	alerting.Observe(samples)
 	writer.WriteSamples(samples)
 	c.String(200, "forwarding...")
 }
@@ -0,0 +1,262 @@
+//go:build !no_api
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/pkg/spool"
+)
+
+const (
+	defaultRelayQueueSize  = 1000
+	defaultRelayTimeout    = 10 * time.Second
+	maxRelayPayloadBytes   = 32 * 1024 * 1024
+	maxRelayRetryBackoff   = 30 * time.Second
+	relayRetryBackoffStart = time.Second
+	relaySpoolFileSuffix   = ".relay"
+)
+
+var relayPayloads = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "relay_payloads_total",
+	Help: "Number of payloads received from other agents by the relay.",
+}, []string{"type", "status"})
+
+func init() {
+	prometheus.MustRegister(relayPayloads)
+}
+
+// headers of the logs http destination, forwarded as is
+var relayLogsHeaders = []string{"Content-Type", "Content-Encoding", "CATEGRAF-API-KEY", "CATEGRAF-PROTOCOL", "CATEGRAF-ORIGIN", "CATEGRAF-ORIGIN-VERSION"}
+
+type relayPayload struct {
+	Path   string      `json:"path"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+// logsRelay buffers logs payloads in memory, or in the spool if spool_dir is set, and forwards them upstream,
+// payloads are retried until upstream accepts or rejects them
+type logsRelay struct {
+	upstream string
+	queue    chan *relayPayload
+	client   *http.Client
+
+	// the payloads accepted are spooled before acked, so they survive the restarts of relay
+	spool *spool.Spool
+	// wakes up the replay once a payload is spooled
+	spooled chan struct{}
+}
+
+var logsForwarder *logsRelay
+
+func relayEnabled() bool {
+	conf := config.Config.HTTP
+	return conf != nil && conf.Relay != nil && conf.Relay.Enable
+}
+
+func initLogsRelay() {
+	conf := config.Config.HTTP.Relay
+	if conf.LogsUpstream == "" {
+		return
+	}
+
+	size := conf.LogsQueueSize
+	if size <= 0 {
+		size = defaultRelayQueueSize
+	}
+
+	timeout := time.Duration(conf.Timeout)
+	if timeout <= 0 {
+		timeout = defaultRelayTimeout
+	}
+
+	r := &logsRelay{
+		upstream: strings.TrimRight(conf.LogsUpstream, "/"),
+		queue:    make(chan *relayPayload, size),
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: &http.Transport{Proxy: http.ProxyFromEnvironment},
+		},
+	}
+
+	if conf.SpoolDir != "" {
+		maxSize := conf.SpoolMaxSizeMB
+		if maxSize <= 0 {
+			maxSize = spool.DefaultMaxSizeMB
+		}
+		sp, err := spool.Open(spool.Dir(conf.SpoolDir, r.upstream), relaySpoolFileSuffix, maxSize*1024*1024)
+		if err != nil {
+			// the payloads are buffered in memory then
+			apiLog.Errorf("relay: failed to open spool %s: %v", conf.SpoolDir, err)
+		} else {
+			r.spool = sp
+			r.spooled = make(chan struct{}, 1)
+		}
+	}
+
+	logsForwarder = r
+	if r.spool != nil {
+		go r.loopReplay()
+		return
+	}
+	go r.loopForward()
+}
+
+func relayLogs(c *gin.Context) {
+	// one more byte to tell the payloads over the limit, which must not be forwarded truncated
+	body, err := ioutil.ReadAll(io.LimitReader(c.Request.Body, maxRelayPayloadBytes+1))
+	if err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(body) > maxRelayPayloadBytes {
+		relayPayloads.WithLabelValues("logs", "too_large").Inc()
+		c.String(http.StatusRequestEntityTooLarge, fmt.Sprintf("payload exceeds %d bytes", maxRelayPayloadBytes))
+		return
+	}
+
+	p := &relayPayload{
+		Path:   c.Request.URL.Path,
+		Header: make(http.Header),
+		Body:   body,
+	}
+	for _, key := range relayLogsHeaders {
+		if v := c.GetHeader(key); v != "" {
+			p.Header.Set(key, v)
+		}
+	}
+
+	if logsForwarder.spool != nil {
+		if err := logsForwarder.put(p); err != nil {
+			relayPayloads.WithLabelValues("logs", "rejected").Inc()
+			apiLog.Errorf("relay: failed to spool logs payload: %v", err)
+			c.String(http.StatusServiceUnavailable, "failed to spool payload")
+			return
+		}
+		relayPayloads.WithLabelValues("logs", "spooled").Inc()
+		c.String(http.StatusOK, "forwarding...")
+		return
+	}
+
+	select {
+	case logsForwarder.queue <- p:
+		relayPayloads.WithLabelValues("logs", "queued").Inc()
+		c.String(http.StatusOK, "forwarding...")
+	default:
+		// the sender of agents retries on 5xx
+		relayPayloads.WithLabelValues("logs", "rejected").Inc()
+		c.String(http.StatusServiceUnavailable, "relay queue is full")
+	}
+}
+
+func (r *logsRelay) loopForward() {
+	for p := range r.queue {
+		backoff := relayRetryBackoffStart
+		for {
+			retry, err := r.forward(p)
+			if err == nil {
+				break
+			}
+			if !retry {
+				relayPayloads.WithLabelValues("logs", "dropped").Inc()
+				apiLog.Warnf("relay: drop logs payload rejected by upstream: %v", err)
+				break
+			}
+
+			apiLog.Warnf("relay: failed to forward logs payload, retry in %v : %v", backoff, err)
+			time.Sleep(backoff)
+			if backoff *= 2; backoff > maxRelayRetryBackoff {
+				backoff = maxRelayRetryBackoff
+			}
+		}
+	}
+}
+
+// put spools the payload and wakes up the replay
+func (r *logsRelay) put(p *relayPayload) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	if err := r.spool.Put(data, ""); err != nil {
+		return err
+	}
+	select {
+	case r.spooled <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// loopReplay forwards the spooled payloads oldest first, and retries every spool.ReplayInterval
+// while upstream is unreachable
+func (r *logsRelay) loopReplay() {
+	for {
+		select {
+		case <-r.spooled:
+		case <-time.After(spool.ReplayInterval):
+		}
+
+		for {
+			name, _, data, err := r.spool.Oldest()
+			if name == "" {
+				break
+			}
+			p := new(relayPayload)
+			if err == nil {
+				err = json.Unmarshal(data, p)
+			}
+			if err != nil {
+				apiLog.Warnf("relay: drop the broken spooled payload %v: %v", name, err)
+				r.spool.Remove(name)
+				continue
+			}
+
+			retry, err := r.forward(p)
+			if err != nil && retry {
+				apiLog.Warnf("relay: failed to forward logs payload, retry in %v : %v", spool.ReplayInterval, err)
+				break
+			}
+			if err != nil {
+				relayPayloads.WithLabelValues("logs", "dropped").Inc()
+				apiLog.Warnf("relay: drop logs payload rejected by upstream: %v", err)
+			}
+			r.spool.Remove(name)
+		}
+	}
+}
+
+func (r *logsRelay) forward(p *relayPayload) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, r.upstream+p.Path, bytes.NewReader(p.Body))
+	if err != nil {
+		return false, err
+	}
+	req.Header = p.Header.Clone()
+	req.Header.Set("User-Agent", "categraf")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		err = fmt.Errorf("upstream response status: %d, body: %s", resp.StatusCode, body)
+		return resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests, err
+	}
+	io.Copy(ioutil.Discard, resp.Body)
+	return false, nil
+}
@@ -1,3 +1,5 @@
+//go:build !no_api
+
 package api
 
 import (
@@ -50,6 +52,18 @@ func remoteWrite(c *gin.Context) {
 		}
 	}
 
+	if relayEnabled() {
+		// buffered by the queues and spool of writers, agents retry on 5xx
+		if !writer.QueueTimeSeries(req.Timeseries) {
+			relayPayloads.WithLabelValues("remotewrite", "rejected").Inc()
+			c.String(http.StatusServiceUnavailable, "queue is full")
+			return
+		}
+		relayPayloads.WithLabelValues("remotewrite", "queued").Inc()
+		c.String(200, "forwarding...")
+		return
+	}
+
 	writer.WriteTimeSeries(req.Timeseries)
 	c.String(200, "forwarding...")
 }
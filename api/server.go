@@ -1,8 +1,12 @@
+//go:build !no_api
+
 package api
 
 import (
 	"crypto/tls"
-	"log"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
 	"net/http"
 	"strings"
 	"time"
@@ -11,6 +15,7 @@ import (
 
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/pkg/aop"
+	"flashcat.cloud/categraf/writer"
 )
 
 func Start() {
@@ -49,11 +54,20 @@ func Start() {
 		IdleTimeout:  time.Duration(conf.IdleTimeout) * time.Second,
 	}
 
-	log.Println("I! http server listening on:", conf.Address)
+	apiLog.Infof("http server listening on: %v", conf.Address)
 
 	var err error
 	if conf.CertFile != "" && conf.KeyFile != "" {
 		srv.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		if conf.ClientCA != "" {
+			pool, err := loadClientCA(conf.ClientCA)
+			if err != nil {
+				apiLog.Errorf("failed to load client ca: %v", err)
+				return
+			}
+			srv.TLSConfig.ClientCAs = pool
+			srv.TLSConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		}
 		err = srv.ListenAndServeTLS(conf.CertFile, conf.KeyFile)
 	} else {
 		err = srv.ListenAndServe()
@@ -64,14 +78,61 @@ func Start() {
 	}
 }
 
+func loadClientCA(file string) (*x509.CertPool, error) {
+	pem, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificate found in %s", file)
+	}
+	return pool, nil
+}
+
 func configRoutes(r *gin.Engine) {
 	r.GET("/ping", func(c *gin.Context) {
 		c.String(200, "pong")
 	})
 
+	if writer.ExposeEnabled() {
+		r.GET("/metrics", exposeMetrics)
+	}
+
+	if relayEnabled() {
+		initLogsRelay()
+		if logsForwarder != nil {
+			// the paths of logs http destination
+			r.POST("/v1/input", relayLogs)
+			r.POST("/api/v2/:track", relayLogs)
+		}
+	}
+
+	if config.Config.HTTP.Pprof {
+		r.GET("/debug/pprof/*name", debugPprof)
+		r.POST("/debug/pprof/*name", debugPprof)
+		r.GET("/debug/metrics", debugMetrics)
+	}
+
+	l := r.Group("/api/log")
+	l.GET("/levels", logLevels)
+	l.PUT("/levels", setLogLevel)
+	l.PUT("/levels/:component", setLogLevel)
+	l.DELETE("/levels/:component", resetLogLevel)
+
+	i := r.Group("/api/inputs")
+	i.GET("/paused", pausedInputs)
+	i.PUT("/:input/pause", pauseInput)
+	i.DELETE("/:input/pause", resumeInput)
+
 	g := r.Group("/api/push")
 	g.POST("/opentsdb", openTSDB)
 	g.POST("/openfalcon", openFalcon)
 	g.POST("/remotewrite", remoteWrite)
 	g.POST("/pushgateway", pushgateway)
+	g.POST("/cronjob/:job/:event", cronjobReport)
+
+	if config.Config.HTTP.K8sAuditWebhook {
+		g.POST("/k8s-audit", k8sAudit)
+	}
 }
@@ -0,0 +1,6 @@
+//go:build no_api
+
+package api
+
+// Start does nothing, the http server of api is not built in
+func Start() {}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"flashcat.cloud/categraf/config"
+)
+
+const (
+	// the files larger are truncated, e.g. the logs and the configs generated
+	maxBundleFileSize = 4 << 20
+	bundleTimeout     = 30 * time.Second
+)
+
+// the captures of the running categraf, which requires pprof = true in [http]
+var bundleCaptures = []struct {
+	path string
+	file string
+}{
+	{"/debug/pprof/goroutine?debug=2", "goroutines.txt"},
+	{"/debug/pprof/heap", "heap.pprof"},
+	{"/debug/pprof/allocs", "allocs.pprof"},
+	{"/debug/metrics", "self_metrics.txt"},
+	{"/api/log/levels", "log_levels.json"},
+}
+
+var (
+	secretKeyRegexp   = regexp.MustCompile(`(?i)^(\s*#*\s*["']?([\w.-]*(passw(or)?d|_pass|^pass|pwd|secret|token|api_?key|access_?key|private_?key|credential|community)[\w.-]*)["']?\s*[=:]\s*)(.+)$`)
+	secretValueRegexp = regexp.MustCompile(`(?i)(authorization|bearer |basic |api-key|x-auth)`)
+	urlUserinfoRegexp = regexp.MustCompile(`([\w.+-]+):([^@\s"'/:]+)@`)
+)
+
+// debugBundle writes the profiles and self metrics of the running categraf, its recent logs and
+// the configs with secrets redacted to a tarball for support cases
+func debugBundle(output string) int {
+	if output == "" {
+		output = fmt.Sprintf("categraf-debug-%s.tar.gz", time.Now().Format("20060102-150405"))
+	}
+	if !filepath.IsAbs(output) {
+		output = filepath.Join(workDir, output)
+	}
+
+	f, err := os.Create(output)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "E! failed to create bundle:", err)
+		return 1
+	}
+	defer f.Close()
+
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+	now := time.Now()
+
+	add := func(name string, data []byte) {
+		hdr := &tar.Header{Name: "categraf-debug/" + name, Mode: 0644, Size: int64(len(data)), ModTime: now}
+		if err := tw.WriteHeader(hdr); err == nil {
+			tw.Write(data)
+		}
+	}
+
+	var problems []string
+	if err := config.InitConfig(*configDir, false, false, 0, ""); err != nil {
+		problems = append(problems, fmt.Sprintf("failed to init config: %v", err))
+	}
+
+	if config.Config != nil {
+		base, err := config.Config.HTTP.LocalURL()
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("the running categraf is not captured: %v", err))
+		} else {
+			problems = append(problems, captureRunning(base, add)...)
+		}
+
+		if name := config.Config.Log.FileName; name != "" && name != "stdout" && name != "stderr" {
+			if data, err := readTail(name, maxBundleFileSize); err != nil {
+				problems = append(problems, fmt.Sprintf("failed to read log file: %v", err))
+			} else {
+				add("categraf.log", data)
+			}
+		}
+	}
+
+	problems = append(problems, addConfigs(*configDir, add)...)
+
+	var info bytes.Buffer
+	hostname, _ := os.Hostname()
+	fmt.Fprintf(&info, "version: %s\n", config.Version)
+	fmt.Fprintf(&info, "go: %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&info, "hostname: %s\n", hostname)
+	fmt.Fprintf(&info, "time: %s\n", now.Format(time.RFC3339))
+	fmt.Fprintf(&info, "configs: %s\n", *configDir)
+	for _, p := range problems {
+		fmt.Fprintf(&info, "problem: %s\n", p)
+	}
+	add("info.txt", info.Bytes())
+
+	if err := tw.Close(); err != nil {
+		fmt.Fprintln(os.Stderr, "E! failed to write bundle:", err)
+		return 1
+	}
+	if err := gw.Close(); err != nil {
+		fmt.Fprintln(os.Stderr, "E! failed to write bundle:", err)
+		return 1
+	}
+
+	for _, p := range problems {
+		fmt.Fprintln(os.Stderr, "W!", p)
+	}
+	fmt.Println("debug bundle written:", output)
+	return 0
+}
+
+func captureRunning(base string, add func(string, []byte)) []string {
+	client := &http.Client{
+		Timeout: bundleTimeout,
+		Transport: &http.Transport{
+			// the certificate of categraf is rarely issued for 127.0.0.1
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+
+	var problems []string
+	for _, c := range bundleCaptures {
+		res, err := client.Get(base + c.path)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("failed to capture %s: %v", c.path, err))
+			continue
+		}
+
+		data, err := io.ReadAll(io.LimitReader(res.Body, maxBundleFileSize))
+		res.Body.Close()
+
+		switch {
+		case err != nil:
+			problems = append(problems, fmt.Sprintf("failed to capture %s: %v", c.path, err))
+		case res.StatusCode == http.StatusNotFound && strings.HasPrefix(c.path, "/debug/"):
+			problems = append(problems, fmt.Sprintf("%s is not found, set pprof = true in [http] of the running categraf", c.path))
+		case res.StatusCode != http.StatusOK:
+			problems = append(problems, fmt.Sprintf("failed to capture %s: status code %d", c.path, res.StatusCode))
+		default:
+			add(c.file, data)
+		}
+	}
+	return problems
+}
+
+// addConfigs adds the configs under dir with the secrets redacted
+func addConfigs(dir string, add func(string, []byte)) []string {
+	var problems []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			problems = append(problems, err.Error())
+			return nil
+		}
+
+		if info.IsDir() {
+			return nil
+		}
+
+		switch filepath.Ext(path) {
+		case ".toml", ".yaml", ".yml", ".json", ".conf":
+		default:
+			return nil
+		}
+
+		data, err := readTail(path, maxBundleFileSize)
+		if err != nil {
+			problems = append(problems, err.Error())
+			return nil
+		}
+
+		rel, _ := filepath.Rel(dir, path)
+		add("conf/"+filepath.ToSlash(rel), redactConfig(data))
+		return nil
+	})
+
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+	return problems
+}
+
+// redactConfig replaces the values of secret keys, the authorization headers and the passwords in urls
+func redactConfig(data []byte) []byte {
+	lines := strings.Split(string(data), "\n")
+	for i, line := range lines {
+		if m := secretKeyRegexp.FindStringSubmatch(line); m != nil && !strings.EqualFold(m[2], "metrics_pass") {
+			lines[i] = m[1] + `"<redacted>"`
+			continue
+		}
+
+		if eq := strings.IndexAny(line, "=:"); eq > 0 && secretValueRegexp.MatchString(line[eq:]) {
+			lines[i] = line[:eq+1] + ` "<redacted>"`
+			continue
+		}
+
+		lines[i] = urlUserinfoRegexp.ReplaceAllString(line, "$1:<redacted>@")
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
+
+// readTail reads the last max bytes of the file
+func readTail(name string, max int64) ([]byte, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	st, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	if st.Size() > max {
+		if _, err := f.Seek(st.Size()-max, io.SeekStart); err != nil {
+			return nil, err
+		}
+	}
+	return io.ReadAll(f)
+}
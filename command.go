@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+
+	"flashcat.cloud/categraf/agent"
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/scaffold"
+	"flashcat.cloud/categraf/writer"
+)
+
+// the samples shipped in conf are built in, so that the configs can be initialized without them
+//
+//go:embed conf/*.toml conf/input.*/*.toml
+var sampleConfigs embed.FS
+
+// runCommand runs the sub commands, e.g. categraf config check, returns false if args is not a command
+func runCommand(args []string) (bool, int) {
+	if len(args) < 2 {
+		return false, 0
+	}
+
+	if args[0] == "replay" {
+		if len(args) != 2 {
+			fmt.Fprintln(os.Stderr, "usage: categraf [--test] replay <recorded.jsonl>")
+			return true, 2
+		}
+		return true, replay(args[1])
+	}
+
+	switch args[0] + " " + args[1] {
+	case "config check":
+		return true, agent.RunConfigCheck(*configDir, os.Stdout)
+	case "config diff":
+		if len(args) != 4 {
+			fmt.Fprintln(os.Stderr, "usage: categraf config diff <old-conf-dir> <new-conf-dir>")
+			return true, 2
+		}
+		return true, agent.RunConfigDiff(args[2], args[3], os.Stdout)
+	case "config init":
+		if len(args) != 3 {
+			fmt.Fprintln(os.Stderr, "usage: categraf config init <input|writer|config|logs|...>")
+			return true, 2
+		}
+		return true, configInit(args[2])
+	case "plugin new":
+		if len(args) != 3 {
+			fmt.Fprintln(os.Stderr, "usage: categraf plugin new <name>")
+			return true, 2
+		}
+		return true, pluginNew(args[2])
+	case "debug bundle":
+		if len(args) > 3 {
+			fmt.Fprintln(os.Stderr, "usage: categraf debug bundle [output.tar.gz]")
+			return true, 2
+		}
+		output := ""
+		if len(args) == 3 {
+			output = args[2]
+		}
+		return true, debugBundle(output)
+	}
+	return false, 0
+}
+
+// replay feeds the recorded samples through the processors of the configs and the writers, or prints them with --test
+func replay(file string) int {
+	if err := config.InitConfig(*configDir, *debugMode, *testMode, 0, ""); err != nil {
+		fmt.Fprintln(os.Stderr, "E! failed to init config:", err)
+		return 1
+	}
+
+	if !config.Config.TestMode {
+		if err := writer.InitWriters(); err != nil {
+			fmt.Fprintln(os.Stderr, "E! failed to init writer:", err)
+			return 1
+		}
+	}
+	return agent.RunReplay(file, os.Stderr)
+}
+
+// configInit prints the sample config of name, e.g. categraf config init mysql > conf/input.mysql/mysql.toml
+func configInit(name string) int {
+	var (
+		data []byte
+		err  error
+	)
+
+	switch {
+	case name == "writer" || name == "writers":
+		data, err = writerSample()
+	case inputs.InputCreators[name] != nil:
+		data, err = inputSample(name)
+	default:
+		data, err = sampleConfigs.ReadFile(path.Join("conf", name+".toml"))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "E! unknown config: %s, one of writer, %s, or the inputs: %s\n",
+				name, strings.Join(topSamples(), ", "), strings.Join(inputNames(), ", "))
+			return 1
+		}
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "E! failed to init config of", name, "error:", err)
+		return 1
+	}
+
+	os.Stdout.Write(data)
+	return 0
+}
+
+// inputSample returns the samples shipped, or the options of the input with their default values
+func inputSample(name string) ([]byte, error) {
+	files, err := fs.Glob(sampleConfigs, path.Join("conf", "input."+name, "*.toml"))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(files) > 0 {
+		var buf bytes.Buffer
+		for _, f := range files {
+			bs, err := sampleConfigs.ReadFile(f)
+			if err != nil {
+				return nil, err
+			}
+			if len(files) > 1 {
+				fmt.Fprintf(&buf, "# %s\n", path.Base(f))
+			}
+			buf.Write(bs)
+		}
+		return buf.Bytes(), nil
+	}
+
+	input := inputs.InputCreators[name]()
+
+	// one instance shows the options of instances
+	v := reflect.ValueOf(input)
+	if v.Kind() == reflect.Ptr {
+		if f := v.Elem().FieldByName("Instances"); f.IsValid() && f.Kind() == reflect.Slice && f.Type().Elem().Kind() == reflect.Ptr {
+			f.Set(reflect.Append(f, reflect.New(f.Type().Elem().Elem())))
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(input); err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "[") {
+			out.WriteString(line + "\n")
+			continue
+		}
+		out.WriteString("# " + line + "\n")
+	}
+	return out.Bytes(), nil
+}
+
+// writerSample returns the [[writers]] of config.toml
+func writerSample() ([]byte, error) {
+	bs, err := sampleConfigs.ReadFile("conf/config.toml")
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	in := false
+	for _, line := range strings.Split(string(bs), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") {
+			in = strings.HasPrefix(trimmed, "[[writers]]") || strings.HasPrefix(trimmed, "[writers.") ||
+				strings.HasPrefix(trimmed, "[[writers.")
+		}
+		if in {
+			out.WriteString(line + "\n")
+		}
+	}
+
+	if out.Len() == 0 {
+		return nil, fmt.Errorf("[[writers]] not found in sample config.toml")
+	}
+	return append(bytes.TrimRight(out.Bytes(), "\n"), '\n'), nil
+}
+
+func topSamples() []string {
+	files, _ := fs.Glob(sampleConfigs, "conf/*.toml")
+	names := make([]string, 0, len(files))
+	for _, f := range files {
+		names = append(names, strings.TrimSuffix(path.Base(f), ".toml"))
+	}
+	return names
+}
+
+func inputNames() []string {
+	names := make([]string, 0, len(inputs.InputCreators))
+	for name := range inputs.InputCreators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// pluginNew creates the skeleton of an input in the repository of the working dir
+func pluginNew(name string) int {
+	files, err := scaffold.NewInput(workDir, name)
+	for _, f := range files {
+		fmt.Println("written:", f)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "E! failed to create input", name, "error:", err)
+		return 1
+	}
+	return 0
+}
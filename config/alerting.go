@@ -0,0 +1,61 @@
+package config
+
+// AlertingConfig evaluates rules against collected samples in agent,
+// actions are fired locally, so alerts still work when the central system is unreachable
+type AlertingConfig struct {
+	Enable        bool         `toml:"enable"`
+	CheckInterval Duration     `toml:"check_interval"`
+	Rules         []*AlertRule `toml:"rules"`
+}
+
+// AlertRule is a threshold rule if value configured, or an absence rule if absent_for configured
+type AlertRule struct {
+	Name string `toml:"name"`
+	SampleMatcher
+
+	// the condition must hold for a while before firing
+	For Duration `toml:"for"`
+	// fires if no matching sample within absent_for
+	AbsentFor Duration `toml:"absent_for"`
+	// fires again while the alert is still firing, 0 means never
+	RepeatInterval Duration `toml:"repeat_interval"`
+	// the series of threshold rules not seen for stale_after are resolved and forgotten, 5m by default
+	StaleAfter Duration `toml:"stale_after"`
+
+	Actions []*AlertAction `toml:"actions"`
+}
+
+type AlertAction struct {
+	// exec | file | webhook | syslog | smtp
+	Type string `toml:"type"`
+
+	// exec: the event is passed to command by stdin in json
+	Command []string `toml:"command"`
+	// file: events are appended to path in json lines
+	Path string `toml:"path"`
+	// webhook: the event is posted to url in json
+	Url     string   `toml:"url"`
+	Headers []string `toml:"headers"`
+	// syslog: the event is logged to the local syslog, or the remote one if address configured, e.g. udp://10.0.0.1:514
+	Address string `toml:"address"`
+	Tag     string `toml:"tag"`
+	// smtp: the event is mailed to, by smtp_server if configured, otherwise directly to the mx of the recipients
+	SMTPServer   string   `toml:"smtp_server"`
+	SMTPUsername string   `toml:"smtp_username"`
+	SMTPPassword string   `toml:"smtp_password"`
+	From         string   `toml:"from"`
+	To           []string `toml:"to"`
+
+	Timeout Duration `toml:"timeout"`
+}
+
+// WriterFailureAlert fires local actions when all writers have been failing for a while,
+// so operators learn about a broken telemetry path by another way
+type WriterFailureAlert struct {
+	Enable bool `toml:"enable"`
+	// fires if no request succeeded within after, while requests are failing
+	After Duration `toml:"after"`
+	// fires again while the writers are still failing, 0 means never
+	RepeatInterval Duration       `toml:"repeat_interval"`
+	Actions        []*AlertAction `toml:"actions"`
+}
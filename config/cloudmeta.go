@@ -0,0 +1,36 @@
+package config
+
+import "log"
+
+type CloudMeta struct {
+	Enable      bool     `toml:"enable"`
+	Providers   []string `toml:"providers"`
+	Timeout     Duration `toml:"timeout"`
+	LabelPrefix string   `toml:"label_prefix"`
+}
+
+// fillCloudMeta detects cloud instance metadata and injects them into global labels,
+// labels configured explicitly take precedence
+func (c *ConfigType) fillCloudMeta() {
+	if !c.Global.CloudMeta.Enable {
+		return
+	}
+
+	meta, err := getCloudMeta()
+	if err != nil {
+		log.Println("W! failed to detect cloud metadata:", err)
+		return
+	}
+
+	if c.Global.Labels == nil {
+		c.Global.Labels = make(map[string]string)
+	}
+
+	for k, v := range meta.Labels(c.Global.CloudMeta.LabelPrefix) {
+		if _, has := c.Global.Labels[k]; !has {
+			c.Global.Labels[k] = v
+		}
+	}
+
+	log.Printf("I! cloud metadata detected, provider: %s, instance_id: %s, region: %s", meta.Provider, meta.InstanceID, meta.Region)
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"net"
 	"os"
@@ -11,7 +12,7 @@ import (
 	"flashcat.cloud/categraf/config/traces"
 	"flashcat.cloud/categraf/pkg/cfg"
 	"flashcat.cloud/categraf/pkg/tls"
-	jsoniter "github.com/json-iterator/go"
+	"flashcat.cloud/categraf/types"
 	"github.com/toolkits/pkg/file"
 )
 
@@ -29,6 +30,18 @@ type Global struct {
 	Precision    string            `toml:"precision"`
 	Interval     Duration          `toml:"interval"`
 	Providers    []string          `toml:"providers"`
+	CloudMeta    CloudMeta         `toml:"cloud_meta"`
+	K8sMeta      K8sMeta           `toml:"k8s_meta"`
+
+	// state of inputs persisted across restarts
+	StateDir string `toml:"state_dir"`
+	// inputs listed in the file are paused, see pkg/pause
+	PauseFile string `toml:"pause_file"`
+	// account the cpu time of every gather, the gathering goroutines are locked to their threads
+	InputCPUUsage bool `toml:"input_cpu_usage"`
+
+	HostnameSources []string `toml:"hostname_sources"`
+	HostnameRefresh Duration `toml:"hostname_refresh_interval"`
 }
 
 type Log struct {
@@ -38,11 +51,28 @@ type Log struct {
 	MaxBackups int    `toml:"max_backups"`
 	LocalTime  bool   `toml:"local_time"`
 	Compress   bool   `toml:"compress"`
+
+	// debug | info | warn | error, debug if -debug
+	Level string `toml:"level"`
+	// text | json
+	Format string `toml:"format"`
+	// levels of components, e.g. "input.mysql" = "debug"
+	Levels map[string]string `toml:"levels"`
 }
 
 type WriterOpt struct {
 	Batch    int `toml:"batch"`
 	ChanSize int `toml:"chan_size"`
+	// the batch is flushed once it has batch samples or batch_max_bytes, or it's older than linger
+	BatchMaxBytes int      `toml:"batch_max_bytes"`
+	Linger        Duration `toml:"linger"`
+
+	// edge buffering, requests failed to send are spooled and resent later
+	SpoolDir        string `toml:"spool_dir"`
+	SpoolMaxSizeMB  int64  `toml:"spool_max_size_mb"`
+	CorrectTimeSkew bool   `toml:"correct_time_skew"`
+
+	FailureAlert *WriterFailureAlert `toml:"failure_alert"`
 }
 
 type WriterOption struct {
@@ -51,9 +81,88 @@ type WriterOption struct {
 	BasicAuthPass string   `toml:"basic_auth_pass"`
 	Headers       []string `toml:"headers"`
 
-	Timeout             int64 `toml:"timeout"`
-	DialTimeout         int64 `toml:"dial_timeout"`
-	MaxIdleConnsPerHost int   `toml:"max_idle_conns_per_host"`
+	Timeout     int64 `toml:"timeout"`
+	DialTimeout int64 `toml:"dial_timeout"`
+	TransportOption
+
+	Precision string `toml:"precision"`
+	Uint64As  string `toml:"uint64_as"`
+	BoolAs    string `toml:"bool_as"`
+	// the exemplars of the samples are sent by the remote write writers, unless the receiver rejects them
+	DropExemplars bool `toml:"drop_exemplars"`
+	// the wire format of the requests: remote_write (default) | influx | json | carbon | otlp,
+	// the requests are posted to url, or written to the connection of tcp://host:port
+	Format string `toml:"format"`
+
+	// only the samples of which route_label matches route_values (globs) are sent to this writer,
+	// or those matched by no other writer routed by route_label if route_default
+	RouteLabel   string        `toml:"route_label"`
+	RouteValues  []string      `toml:"route_values"`
+	RouteDefault bool          `toml:"route_default"`
+	Tenant       *WriterTenant `toml:"tenant"`
+}
+
+// WriterTenant splits the samples of a writer to tenants by a label, e.g. the tenants of mimir or cortex,
+// each request has the samples of one tenant and is sent with the header of it
+type WriterTenant struct {
+	Label string `toml:"label"`
+	// X-Scope-OrgID by default
+	Header string `toml:"header"`
+	// the values of label mapped to tenants, the value itself is the tenant if not mapped
+	Tenants map[string]string `toml:"tenants"`
+	// the tenant of the samples without label, they are dropped if empty
+	Default string `toml:"default"`
+	// remove label from the samples sent
+	DropLabel bool `toml:"drop_label"`
+}
+
+// AdaptiveInterval stretches the intervals of the inputs of low priority while the load is high, i.e. their
+// gathering takes longer than interval, or the cpu usage of host is above cpu_threshold, for slow_rounds in a row
+type AdaptiveInterval struct {
+	Enable bool `toml:"enable"`
+	// percent of all cpus
+	CPUThreshold float64 `toml:"cpu_threshold"`
+	SlowRounds   int     `toml:"slow_rounds"`
+	// the interval is doubled every slow_rounds up to max_factor times, and halved back after slow_rounds without high load
+	MaxFactor int `toml:"max_factor"`
+}
+
+// Resources limits the footprint of categraf on shared hosts
+type Resources struct {
+	// soft limit of the memory of go runtime, the garbage collection is more frequent near it, see GOMEMLIMIT
+	MemoryLimitMB int64 `toml:"memory_limit_mb"`
+	// the cpus executing go code simultaneously, see GOMAXPROCS
+	MaxProcs int `toml:"max_procs"`
+
+	// the caches are dropped and the buffers flushed once rss is above rss_threshold_mb
+	RSSThresholdMB   int64    `toml:"rss_threshold_mb"`
+	WatchdogInterval Duration `toml:"watchdog_interval"`
+
+	// linux only, categraf moves itself into the cgroup (v2) of the path, e.g. categraf for /sys/fs/cgroup/categraf,
+	// which is created with the limits of cpu (cores) and memory
+	Cgroup         string  `toml:"cgroup"`
+	CgroupCPUs     float64 `toml:"cgroup_cpus"`
+	CgroupMemoryMB int64   `toml:"cgroup_memory_mb"`
+}
+
+// Recorder records the samples gathered by inputs into file before their processors, one batch per line,
+// so that the processors and the writers can be developed offline by categraf replay
+type Recorder struct {
+	Enable bool   `toml:"enable"`
+	File   string `toml:"file"`
+	// the input keys recorded, support glob, all inputs if empty
+	Inputs []string `toml:"inputs"`
+	// recording stops once the file reaches max_size_mb
+	MaxSizeMB int64 `toml:"max_size_mb"`
+}
+
+// CardinalityLimit limits distinct values of every tag key
+type CardinalityLimit struct {
+	Enable          bool     `toml:"enable"`
+	MaxValuesPerTag int      `toml:"max_values_per_tag"`
+	Action          string   `toml:"action"` // overflow | drop
+	ExcludeTags     []string `toml:"exclude_tags"`
+	ResetInterval   Duration `toml:"reset_interval"`
 }
 
 type HTTP struct {
@@ -66,6 +175,56 @@ type HTTP struct {
 	ReadTimeout  int    `toml:"read_timeout"`
 	WriteTimeout int    `toml:"write_timeout"`
 	IdleTimeout  int    `toml:"idle_timeout"`
+
+	// expose the last values of collected series on /metrics
+	ExposeMetrics bool     `toml:"expose_metrics"`
+	MetricsTTL    Duration `toml:"metrics_ttl"`
+
+	// verify client certificates if configured
+	ClientCA string `toml:"client_ca"`
+
+	Relay *Relay `toml:"relay"`
+
+	// receive kubernetes audit events on /api/push/k8s-audit, forwarded to the logs agent
+	K8sAuditWebhook bool `toml:"k8s_audit_webhook"`
+
+	// serve /debug/pprof and the self metrics on /debug/metrics
+	Pprof bool `toml:"pprof"`
+}
+
+// LocalURL returns the url of the http server for the commands on the same host, e.g. http://127.0.0.1:9100
+func (h *HTTP) LocalURL() (string, error) {
+	if h == nil || !h.Enable {
+		return "", fmt.Errorf("http server of categraf is not enabled")
+	}
+
+	host, port, err := net.SplitHostPort(h.Address)
+	if err != nil {
+		return "", fmt.Errorf("invalid http address %s: %v", h.Address, err)
+	}
+
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+
+	scheme := "http"
+	if h.CertFile != "" && h.KeyFile != "" {
+		scheme = "https"
+	}
+	return scheme + "://" + net.JoinHostPort(host, port), nil
+}
+
+// Relay forwards the payloads of other agents upstream
+type Relay struct {
+	Enable bool `toml:"enable"`
+	// logs payloads received on /v1/input are forwarded to logs_upstream, e.g. http://10.2.3.4:17878
+	LogsUpstream  string   `toml:"logs_upstream"`
+	LogsQueueSize int      `toml:"logs_queue_size"`
+	Timeout       Duration `toml:"timeout"`
+	// the logs payloads are spooled in spool_dir before acked instead of the queue in memory if set,
+	// up to spool_max_size_mb, then the oldest are evicted
+	SpoolDir       string `toml:"spool_dir"`
+	SpoolMaxSizeMB int64  `toml:"spool_max_size_mb"`
 }
 
 type IbexConfig struct {
@@ -73,6 +232,12 @@ type IbexConfig struct {
 	Interval Duration `toml:"interval"`
 	MetaDir  string   `toml:"meta_dir"`
 	Servers  []string `toml:"servers"`
+
+	MaxConcurrentTasks int      `toml:"max_concurrent_tasks"`
+	MaxOutputBytes     int      `toml:"max_output_bytes"`
+	AllowCommands      []string `toml:"allow_commands"`
+	DenyCommands       []string `toml:"deny_commands"`
+	AuditLog           string   `toml:"audit_log"`
 }
 
 type HeartbeatConfig struct {
@@ -90,6 +255,11 @@ type HeartbeatConfig struct {
 	tls.ClientConfig
 }
 
+// InventoryConfig reports the inventory of host, e.g. os, cpu, memory, disks and ips, to url
+type InventoryConfig struct {
+	HeartbeatConfig
+}
+
 type ConfigType struct {
 	// from console args
 	ConfigDir    string
@@ -101,6 +271,7 @@ type ConfigType struct {
 	Global     Global           `toml:"global"`
 	WriterOpt  WriterOpt        `toml:"writer_opt"`
 	Writers    []WriterOption   `toml:"writers"`
+	Blocklist  Blocklist        `toml:"blocklist"`
 	Logs       Logs             `toml:"logs"`
 	Traces     *traces.Config   `toml:"traces"`
 	HTTP       *HTTP            `toml:"http"`
@@ -110,6 +281,15 @@ type ConfigType struct {
 	Log        Log              `toml:"log"`
 
 	HTTPProviderConfig *HTTPProviderConfig `toml:"http_provider"`
+	CardinalityLimit   *CardinalityLimit   `toml:"cardinality_limit"`
+	AdaptiveInterval   *AdaptiveInterval   `toml:"adaptive_interval"`
+	Resources          *Resources          `toml:"resources"`
+	Alerting           *AlertingConfig     `toml:"alerting"`
+	Inventory          *InventoryConfig    `toml:"inventory"`
+	Recorder           *Recorder           `toml:"recorder"`
+	Signing            *Signing            `toml:"signing"`
+	LeaderElection     *LeaderElection     `toml:"leader_election"`
+	MetricRegistry     *MetricRegistry     `toml:"metric_registry"`
 }
 
 var Config *ConfigType
@@ -127,7 +307,7 @@ func InitConfig(configDir string, debugMode, testMode bool, interval int64, inpu
 		InputFilters: inputFilters,
 	}
 
-	if err := cfg.LoadConfigByDir(configDir, Config); err != nil {
+	if err := cfg.LoadConfigByDirs(Layers(configDir, ""), Config); err != nil {
 		return fmt.Errorf("failed to load configs of dir: %s err:%s", configDir, err)
 	}
 
@@ -139,6 +319,18 @@ func InitConfig(configDir string, debugMode, testMode bool, interval int64, inpu
 		Config.Global.Precision = "ms"
 	}
 
+	if err := types.CheckPrecision(Config.Global.Precision); err != nil {
+		return err
+	}
+
+	if err := Config.Blocklist.Init(); err != nil {
+		return err
+	}
+
+	if cl := Config.CardinalityLimit; cl != nil && cl.Action != "" && cl.Action != "overflow" && cl.Action != "drop" {
+		return fmt.Errorf("invalid cardinality_limit.action: %s", cl.Action)
+	}
+
 	if Config.WriterOpt.ChanSize <= 0 {
 		Config.WriterOpt.ChanSize = 1000000
 	}
@@ -155,12 +347,14 @@ func InitConfig(configDir string, debugMode, testMode bool, interval int64, inpu
 		return err
 	}
 
-	if err := traces.Parse(Config.Traces); err != nil {
+	Config.fillCloudMeta()
+	Config.fillK8sMeta()
+
+	if err := traces.Parse(Config.Traces, Config.hostAttributes(), Config.Global.CloudMeta.LabelPrefix); err != nil {
 		return err
 	}
 
 	if Config.Global.PrintConfigs {
-		json := jsoniter.ConfigCompatibleWithStandardLibrary
 		bs, err := json.MarshalIndent(Config, "", "    ")
 		if err != nil {
 			fmt.Println(err)
@@ -172,6 +366,18 @@ func InitConfig(configDir string, debugMode, testMode bool, interval int64, inpu
 	return nil
 }
 
+// hostAttributes are the host tags injected into traces
+func (c *ConfigType) hostAttributes() map[string]string {
+	attrs := make(map[string]string, len(c.Global.Labels)+1)
+	for k, v := range c.Global.Labels {
+		attrs[k] = v
+	}
+	if !c.Global.OmitHostname {
+		attrs["host.name"] = c.GetHostname()
+	}
+	return attrs
+}
+
 func (c *ConfigType) fillIP() error {
 	if !strings.Contains(c.Global.Hostname, "$ip") {
 		return nil
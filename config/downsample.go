@@ -0,0 +1,160 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"flashcat.cloud/categraf/pkg/conv"
+	"flashcat.cloud/categraf/pkg/filter"
+	"flashcat.cloud/categraf/types"
+)
+
+// Downsample buffers samples gathered within interval and writes the aggregated values,
+// metric name is kept if only one aggregation configured, otherwise suffixed with _<aggregation>
+type Downsample struct {
+	Interval Duration `toml:"interval"`
+	// avg | min | max | sum | count | last
+	Aggregations []string `toml:"aggregations"`
+	// metrics to downsample, support glob, all metrics if empty
+	Metrics []string `toml:"metrics"`
+
+	metricsFilter filter.Filter
+
+	sync.Mutex
+	start  time.Time
+	series map[string]*downsampleSeries
+	keys   []string
+}
+
+type downsampleSeries struct {
+	metric string
+	labels map[string]string
+	count  int
+	sum    float64
+	min    float64
+	max    float64
+	last   float64
+}
+
+func (d *Downsample) init() error {
+	if d.Interval <= 0 {
+		return fmt.Errorf("downsample: interval is required")
+	}
+
+	if len(d.Aggregations) == 0 {
+		d.Aggregations = []string{"avg"}
+	}
+	for _, agg := range d.Aggregations {
+		switch agg {
+		case "avg", "min", "max", "sum", "count", "last":
+		default:
+			return fmt.Errorf("downsample: invalid aggregation %s", agg)
+		}
+	}
+
+	var err error
+	if len(d.Metrics) > 0 {
+		d.metricsFilter, err = filter.Compile(d.Metrics)
+		if err != nil {
+			return err
+		}
+	}
+
+	d.series = make(map[string]*downsampleSeries)
+	return nil
+}
+
+func (d *Downsample) add(s *types.Sample) bool {
+	if d.metricsFilter != nil && !d.metricsFilter.Match(s.Metric) {
+		return false
+	}
+
+	v, err := conv.ToFloat64(s.Value)
+	if err != nil || math.IsNaN(v) {
+		return false
+	}
+
+	key := s.Metric + "\xfe" + labelsKey(s.Labels)
+	ds, has := d.series[key]
+	if !has {
+		ds = &downsampleSeries{metric: s.Metric, labels: s.Labels, min: v, max: v}
+		d.series[key] = ds
+		d.keys = append(d.keys, key)
+	}
+
+	ds.count++
+	ds.sum += v
+	ds.last = v
+	if v < ds.min {
+		ds.min = v
+	}
+	if v > ds.max {
+		ds.max = v
+	}
+	return true
+}
+
+func (ds *downsampleSeries) value(agg string) float64 {
+	switch agg {
+	case "min":
+		return ds.min
+	case "max":
+		return ds.max
+	case "sum":
+		return ds.sum
+	case "count":
+		return float64(ds.count)
+	case "last":
+		return ds.last
+	default:
+		return ds.sum / float64(ds.count)
+	}
+}
+
+func (d *Downsample) flush(now time.Time) []*types.Sample {
+	ret := make([]*types.Sample, 0, len(d.keys)*len(d.Aggregations))
+	for _, key := range d.keys {
+		ds := d.series[key]
+		for _, agg := range d.Aggregations {
+			metric := ds.metric
+			if len(d.Aggregations) > 1 {
+				metric = metric + "_" + agg
+			}
+			s := types.NewSample("", metric, ds.value(agg), ds.labels)
+			s.Timestamp = now
+			ret = append(ret, s)
+		}
+	}
+
+	d.series = make(map[string]*downsampleSeries, len(d.keys))
+	d.keys = d.keys[:0]
+	d.start = now
+	return ret
+}
+
+// process buffers samples to downsample, the aggregated values are pushed once interval elapsed
+func (d *Downsample) process(slist *types.SampleList) *types.SampleList {
+	d.Lock()
+	defer d.Unlock()
+
+	now := time.Now()
+	if d.start.IsZero() {
+		d.start = now
+	}
+
+	nlst := types.NewSampleList()
+	ss := slist.PopBackAll()
+	for _, s := range ss {
+		if !d.add(s) {
+			nlst.PushFront(s)
+		}
+	}
+	types.ReleaseSamples(ss)
+
+	if now.Sub(d.start) >= time.Duration(d.Interval) {
+		nlst.PushFrontN(d.flush(now))
+	}
+	return nlst
+}
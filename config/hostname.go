@@ -1,15 +1,25 @@
 package config
 
 import (
+	"bytes"
+	"fmt"
 	"log"
+	"net"
 	"os"
+	"strings"
 	"sync"
+	"text/template"
 	"time"
+
+	"flashcat.cloud/categraf/pkg/cloudmeta"
 )
 
 type HostnameCache struct {
 	name string
 	sync.RWMutex
+
+	sources []string
+	refresh time.Duration
 }
 
 var Hostname *HostnameCache
@@ -31,29 +41,205 @@ func (c *HostnameCache) Set(name string) {
 	c.Unlock()
 }
 
+// hostname sources, tried in order, the first non-empty result wins:
+//
+//	os                  os hostname
+//	fqdn                fully qualified domain name
+//	cloud_instance_id   instance id from the cloud metadata service
+//	env:NAME            value of environment variable NAME
+//	file:/path          first line of the file
+//	template:TEXT       label template, e.g. template:{{ short_hostname }}-{{ env "POD_NAME" }}
+const (
+	hostnameSourceOS         = "os"
+	hostnameSourceFQDN       = "fqdn"
+	hostnameSourceCloud      = "cloud_instance_id"
+	hostnameSourceEnvPrefix  = "env:"
+	hostnameSourceFilePrefix = "file:"
+	hostnameSourceTplPrefix  = "template:"
+)
+
 func InitHostname() error {
-	hostname, err := os.Hostname()
-	if err != nil {
-		return err
+	var sources []string
+	var refresh time.Duration
+	if Config != nil {
+		sources = Config.Global.HostnameSources
+		refresh = time.Duration(Config.Global.HostnameRefresh)
+	}
+
+	if len(sources) == 0 {
+		sources = []string{hostnameSourceOS}
+	}
+
+	for _, source := range sources {
+		if err := checkHostnameSource(source); err != nil {
+			return err
+		}
+	}
+
+	if refresh <= 0 {
+		refresh = time.Second
+		if len(sources) > 1 || sources[0] != hostnameSourceOS {
+			// other sources may be expensive, e.g. dns lookup or metadata service
+			refresh = time.Minute
+		}
 	}
 
 	Hostname = &HostnameCache{
-		name: hostname,
+		sources: sources,
+		refresh: refresh,
 	}
 
+	hostname, err := Hostname.resolve()
+	if err != nil {
+		return err
+	}
+	Hostname.name = hostname
+
 	go Hostname.update()
 
 	return nil
 }
 
+func checkHostnameSource(source string) error {
+	switch {
+	case source == hostnameSourceOS, source == hostnameSourceFQDN, source == hostnameSourceCloud:
+		return nil
+	case strings.HasPrefix(source, hostnameSourceEnvPrefix), strings.HasPrefix(source, hostnameSourceFilePrefix):
+		return nil
+	case strings.HasPrefix(source, hostnameSourceTplPrefix):
+		_, err := template.New("hostname").Funcs(labelFuncs).Parse(strings.TrimPrefix(source, hostnameSourceTplPrefix))
+		if err != nil {
+			return fmt.Errorf("failed to parse hostname source %s: %v", source, err)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown hostname source: %s", source)
+}
+
+// resolve walks through the sources, falls back to os hostname if all of them are empty
+func (c *HostnameCache) resolve() (string, error) {
+	for _, source := range c.sources {
+		name, err := resolveHostname(source)
+		if err != nil {
+			if Config != nil && Config.DebugMode {
+				log.Println("D! failed to resolve hostname from", source, "error:", err)
+			}
+			continue
+		}
+
+		name = strings.TrimSpace(name)
+		if name != "" {
+			return name, nil
+		}
+	}
+
+	return os.Hostname()
+}
+
+func resolveHostname(source string) (string, error) {
+	switch {
+	case source == hostnameSourceOS:
+		return os.Hostname()
+	case source == hostnameSourceFQDN:
+		return GetFQDN()
+	case source == hostnameSourceCloud:
+		meta, err := getCloudMeta()
+		if err != nil {
+			return "", err
+		}
+		return meta.InstanceID, nil
+	case strings.HasPrefix(source, hostnameSourceEnvPrefix):
+		return os.Getenv(strings.TrimPrefix(source, hostnameSourceEnvPrefix)), nil
+	case strings.HasPrefix(source, hostnameSourceFilePrefix):
+		bs, err := os.ReadFile(strings.TrimPrefix(source, hostnameSourceFilePrefix))
+		if err != nil {
+			return "", err
+		}
+		return strings.SplitN(string(bs), "\n", 2)[0], nil
+	case strings.HasPrefix(source, hostnameSourceTplPrefix):
+		// the template may use hostname, so render it with os hostname
+		tpl, err := template.New("hostname").Funcs(labelFuncs).Funcs(template.FuncMap{
+			"hostname": func() string { name, _ := os.Hostname(); return name },
+		}).Parse(strings.TrimPrefix(source, hostnameSourceTplPrefix))
+		if err != nil {
+			return "", err
+		}
+		var buf bytes.Buffer
+		if err = tpl.Execute(&buf, nil); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	}
+	return "", fmt.Errorf("unknown hostname source: %s", source)
+}
+
 func (c *HostnameCache) update() {
 	for {
-		time.Sleep(time.Second)
-		name, err := os.Hostname()
+		time.Sleep(c.refresh)
+		name, err := c.resolve()
 		if err != nil {
 			log.Println("E! failed to get hostname:", err)
-		} else {
-			Hostname.Set(name)
+			continue
+		}
+
+		if old := c.Get(); old != name {
+			log.Printf("I! hostname changed from %s to %s", old, name)
+			c.Set(name)
+		}
+	}
+}
+
+// GetFQDN resolves the fully qualified domain name of this machine,
+// falls back to the os hostname if no name with a domain is found
+func GetFQDN() (string, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return "", err
+	}
+
+	if strings.Contains(hostname, ".") {
+		return hostname, nil
+	}
+
+	ips, err := net.LookupIP(hostname)
+	if err != nil {
+		return hostname, nil
+	}
+
+	for _, ip := range ips {
+		names, err := net.LookupAddr(ip.String())
+		if err != nil {
+			continue
+		}
+		for _, name := range names {
+			name = strings.TrimSuffix(name, ".")
+			if strings.Contains(name, ".") {
+				return name, nil
+			}
 		}
 	}
+
+	return hostname, nil
+}
+
+var (
+	cloudMetaOnce sync.Once
+	cloudMetaData *cloudmeta.Metadata
+	cloudMetaErr  error
+)
+
+// getCloudMeta detects the cloud metadata once, the result is shared by hostname resolving and global labels
+func getCloudMeta() (*cloudmeta.Metadata, error) {
+	cloudMetaOnce.Do(func() {
+		var providers []string
+		timeout := 2 * time.Second
+		if Config != nil {
+			providers = Config.Global.CloudMeta.Providers
+			if t := time.Duration(Config.Global.CloudMeta.Timeout); t > 0 {
+				timeout = t
+			}
+		}
+		cloudMetaData, cloudMetaErr = cloudmeta.Detect(providers, timeout)
+	})
+	return cloudMetaData, cloudMetaErr
 }
@@ -10,6 +10,10 @@ import (
 
 const agentHostnameLabelKey = "agent_hostname"
 
+// ObserveSamples sees the samples processed before downsample, i.e. the in-agent alert rules,
+// so the spikes within a downsample interval are still seen by the rules
+var ObserveSamples func(samples []*types.Sample)
+
 type ProcessorEnum struct {
 	Metrics       []string `toml:"metrics"` // support glob
 	MetricsFilter filter.Filter
@@ -32,6 +36,18 @@ type InternalConfig struct {
 	// mapping value
 	ProcessorEnum []*ProcessorEnum `toml:"processor_enum"`
 
+	// convert raw observations to histogram buckets
+	ProcessorHistogram []*ProcessorHistogram `toml:"processor_histogram"`
+
+	// derive new samples from samples with the same labels
+	ProcessorExpr []*ProcessorExpr `toml:"processor_expr"`
+
+	// drop samples after labels appended
+	Blocklist Blocklist `toml:"blocklist"`
+
+	// write aggregated values over a longer interval
+	Downsample *Downsample `toml:"downsample"`
+
 	// whether instance initial success
 	inited bool `toml:"-"`
 }
@@ -45,6 +61,10 @@ func (ic *InternalConfig) GetLabels() map[string]string {
 }
 
 func (ic *InternalConfig) InitInternalConfig() error {
+	if err := renderLabels(ic.Labels); err != nil {
+		return err
+	}
+
 	if len(ic.MetricsDrop) > 0 {
 		var err error
 		ic.MetricsDropFilter, err = filter.Compile(ic.MetricsDrop)
@@ -61,6 +81,28 @@ func (ic *InternalConfig) InitInternalConfig() error {
 		}
 	}
 
+	if err := ic.Blocklist.Init(); err != nil {
+		return err
+	}
+
+	if ic.Downsample != nil {
+		if err := ic.Downsample.init(); err != nil {
+			return err
+		}
+	}
+
+	for i := 0; i < len(ic.ProcessorHistogram); i++ {
+		if err := ic.ProcessorHistogram[i].init(); err != nil {
+			return err
+		}
+	}
+
+	for i := 0; i < len(ic.ProcessorExpr); i++ {
+		if err := ic.ProcessorExpr[i].init(); err != nil {
+			return err
+		}
+	}
+
 	for i := 0; i < len(ic.ProcessorEnum); i++ {
 		if len(ic.ProcessorEnum[i].Metrics) > 0 {
 			var err error
@@ -145,8 +187,38 @@ func (ic *InternalConfig) Process(slist *types.SampleList) *types.SampleList {
 			}
 		}
 
+		if ic.Blocklist.Match(ss[i]) {
+			continue
+		}
+
 		nlst.PushFront(ss[i])
 	}
+	types.ReleaseSamples(ss)
+
+	if len(ic.ProcessorHistogram) > 0 {
+		nlst = aggregateHistograms(ic.ProcessorHistogram, nlst)
+	}
+
+	if len(ic.ProcessorExpr) > 0 {
+		for _, s := range deriveSamples(ic.ProcessorExpr, nlst) {
+			if !ic.Blocklist.Match(s) {
+				nlst.PushFront(s)
+			}
+		}
+	}
+
+	if ObserveSamples != nil && nlst.Len() > 0 {
+		observed := make([]*types.Sample, 0, nlst.Len())
+		nlst.Range(func(s *types.Sample) bool {
+			observed = append(observed, s)
+			return true
+		})
+		ObserveSamples(observed)
+	}
+
+	if ic.Downsample != nil {
+		nlst = ic.Downsample.process(nlst)
+	}
 
 	return nlst
 }
@@ -162,17 +234,98 @@ func (ic *InternalConfig) SetInitialized() {
 type PluginConfig struct {
 	InternalConfig
 	Interval Duration `toml:"interval"`
+	// high | normal | low, see Priority
+	Priority Priority `toml:"priority"`
 }
 
 func (pc *PluginConfig) GetInterval() Duration {
 	return pc.Interval
 }
 
+func (pc *PluginConfig) GetPriority() Priority {
+	return pc.Priority
+}
+
+// Priority of the samples of inputs, when the queues of writers are full, the samples of
+// low priority are dropped first, and those of high priority are always retained
+type Priority int
+
+const (
+	PriorityLow Priority = iota - 1
+	PriorityNormal
+	PriorityHigh
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityLow:
+		return "low"
+	case PriorityHigh:
+		return "high"
+	}
+	return "normal"
+}
+
+func (p Priority) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+func (p *Priority) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "low":
+		*p = PriorityLow
+	case "", "normal":
+		*p = PriorityNormal
+	case "high":
+		*p = PriorityHigh
+	default:
+		return fmt.Errorf("invalid priority: %s, high, normal or low", text)
+	}
+	return nil
+}
+
 type InstanceConfig struct {
 	InternalConfig
 	IntervalTimes int64 `toml:"interval_times"`
+
+	// circuit breaker, disabled if failure_threshold is 0
+	FailureThreshold int      `toml:"failure_threshold"`
+	MaxBackoff       Duration `toml:"max_backoff"`
+
+	Maintenance []*Maintenance `toml:"maintenance"`
+}
+
+func (ic *InstanceConfig) InitInternalConfig() error {
+	if err := ic.InternalConfig.InitInternalConfig(); err != nil {
+		return err
+	}
+
+	for i := 0; i < len(ic.Maintenance); i++ {
+		if err := ic.Maintenance[i].init(); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (ic *InstanceConfig) GetIntervalTimes() int64 {
 	return ic.IntervalTimes
 }
+
+func (ic *InstanceConfig) GetCircuitBreaker() (int, time.Duration) {
+	return ic.FailureThreshold, time.Duration(ic.MaxBackoff)
+}
+
+// InMaintenance returns whether t is in any maintenance window, and whether gathering should be skipped
+func (ic *InstanceConfig) InMaintenance(t time.Time) (active bool, skip bool) {
+	for _, m := range ic.Maintenance {
+		if m.expr == nil || !m.active(t) {
+			continue
+		}
+		active = true
+		if m.Skip {
+			return true, true
+		}
+	}
+	return active, false
+}
@@ -0,0 +1,17 @@
+package config
+
+type K8sMeta struct {
+	Enable bool `toml:"enable"`
+	// the keys of the labels and annotations injected, globs are supported, e.g. topology.kubernetes.io/*
+	NodeLabels     []string `toml:"node_labels"`
+	PodLabels      []string `toml:"pod_labels"`
+	PodAnnotations []string `toml:"pod_annotations"`
+	// the keys renamed, e.g. "topology.kubernetes.io/zone" = "zone", the others are sanitized,
+	// e.g. app.kubernetes.io/name is app_kubernetes_io_name
+	Rename      map[string]string `toml:"rename"`
+	LabelPrefix string            `toml:"label_prefix"`
+
+	DownwardAPIDir string   `toml:"downward_api_dir"`
+	Kubeconfig     string   `toml:"kubeconfig"`
+	Timeout        Duration `toml:"timeout"`
+}
@@ -0,0 +1,78 @@
+//go:build !minimal
+
+package config
+
+import (
+	"log"
+	"regexp"
+	"time"
+
+	"flashcat.cloud/categraf/pkg/filter"
+	"flashcat.cloud/categraf/pkg/k8smeta"
+)
+
+var invalidLabelChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// fillK8sMeta injects the selected labels of the node and the pod of categraf into global labels,
+// labels configured explicitly take precedence
+func (c *ConfigType) fillK8sMeta() {
+	conf := c.Global.K8sMeta
+	if !conf.Enable {
+		return
+	}
+
+	timeout := time.Duration(conf.Timeout)
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	meta, err := k8smeta.Detect(k8smeta.Options{
+		Kubeconfig:     conf.Kubeconfig,
+		DownwardAPIDir: conf.DownwardAPIDir,
+		NodeLabels:     len(conf.NodeLabels) > 0,
+		Timeout:        timeout,
+	})
+	if err != nil {
+		log.Println("W! failed to detect kubernetes metadata:", err)
+		return
+	}
+
+	if c.Global.Labels == nil {
+		c.Global.Labels = make(map[string]string)
+	}
+
+	n := 0
+	for _, s := range []struct {
+		patterns []string
+		values   map[string]string
+	}{
+		{conf.NodeLabels, meta.NodeLabels},
+		{conf.PodLabels, meta.PodLabels},
+		{conf.PodAnnotations, meta.PodAnnotations},
+	} {
+		f, err := filter.Compile(s.patterns)
+		if err != nil {
+			log.Println("W! failed to compile the keys of kubernetes metadata:", err)
+			continue
+		}
+		if f == nil {
+			continue
+		}
+
+		for k, v := range s.values {
+			if !f.Match(k) {
+				continue
+			}
+			name, ok := conf.Rename[k]
+			if !ok {
+				name = invalidLabelChars.ReplaceAllString(k, "_")
+			}
+			name = conf.LabelPrefix + name
+			if _, has := c.Global.Labels[name]; !has {
+				c.Global.Labels[name] = v
+				n++
+			}
+		}
+	}
+
+	log.Printf("I! kubernetes metadata detected, node: %s, pod: %s/%s, labels injected: %d", meta.NodeName, meta.Namespace, meta.PodName, n)
+}
@@ -0,0 +1,12 @@
+//go:build minimal
+
+package config
+
+import "log"
+
+// fillK8sMeta does nothing, the kubernetes client is not built into the minimal build to save the size
+func (c *ConfigType) fillK8sMeta() {
+	if c.Global.K8sMeta.Enable {
+		log.Println("W! kubernetes metadata is not supported by the minimal build")
+	}
+}
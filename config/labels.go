@@ -0,0 +1,93 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// labelFuncs are the functions usable in label templates, e.g.
+//
+//	labels = { region = "{{ env \"DATACENTER\" }}", host = "{{ short_hostname }}" }
+var labelFuncs = template.FuncMap{
+	"env":            os.Getenv,
+	"hostname":       labelHostname,
+	"short_hostname": func() string { return strings.SplitN(labelHostname(), ".", 2)[0] },
+	"domain":         labelDomain,
+	"fqdn":           labelFQDN,
+	"ip":             labelIP,
+	"agent_hostname": func() string {
+		if Config == nil {
+			return labelHostname()
+		}
+		return Config.GetHostname()
+	},
+	"default": func(def, v string) string {
+		if v == "" {
+			return def
+		}
+		return v
+	},
+	"lower":   strings.ToLower,
+	"upper":   strings.ToUpper,
+	"replace": func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+}
+
+func labelHostname() string {
+	if Hostname != nil {
+		return Hostname.Get()
+	}
+	name, _ := os.Hostname()
+	return name
+}
+
+func labelFQDN() string {
+	name, err := GetFQDN()
+	if err != nil {
+		return labelHostname()
+	}
+	return name
+}
+
+func labelDomain() string {
+	parts := strings.SplitN(labelFQDN(), ".", 2)
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[1]
+}
+
+func labelIP() string {
+	if Config != nil && Config.Global.IP != "" {
+		return Config.Global.IP
+	}
+	ip, err := GetOutboundIP()
+	if err != nil {
+		return ""
+	}
+	return ip.String()
+}
+
+// renderLabels evaluates the label values containing templates, the others are kept as is
+func renderLabels(labels map[string]string) error {
+	for k, v := range labels {
+		if !strings.Contains(v, "{{") {
+			continue
+		}
+
+		tpl, err := template.New(k).Funcs(labelFuncs).Option("missingkey=zero").Parse(v)
+		if err != nil {
+			return fmt.Errorf("failed to parse template of label %s: %v", k, err)
+		}
+
+		var buf bytes.Buffer
+		if err = tpl.Execute(&buf, nil); err != nil {
+			return fmt.Errorf("failed to render template of label %s: %v", k, err)
+		}
+
+		labels[k] = buf.String()
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package config
+
+// LeaderElection elects one of the categraf replicas by a kubernetes lease, e.g. of a daemonset,
+// the cluster scoped inputs run only on the leader while the others run on every replica
+type LeaderElection struct {
+	Enable bool `toml:"enable"`
+	// the names of the cluster scoped inputs, globs are supported, e.g. kube_state_metrics, kube_events
+	Inputs []string `toml:"inputs"`
+
+	// the lease categraf-leader in the namespace of the pod by default
+	LeaseName      string `toml:"lease_name"`
+	LeaseNamespace string `toml:"lease_namespace"`
+	// env POD_NAME or the hostname by default
+	Identity string `toml:"identity"`
+
+	// 15s, 10s, 2s by default, see k8s.io/client-go/tools/leaderelection
+	LeaseDuration Duration `toml:"lease_duration"`
+	RenewDeadline Duration `toml:"renew_deadline"`
+	RetryPeriod   Duration `toml:"retry_period"`
+
+	// in cluster config is used if empty
+	Kubeconfig string `toml:"kubeconfig"`
+}
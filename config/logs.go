@@ -21,9 +21,19 @@ type (
 		SendType              string                       `json:"send_type" toml:"send_type"`
 		UseCompression        bool                         `json:"use_compression" toml:"use_compression"`
 		CompressionLevel      int                          `json:"compression_level" toml:"compression_level"`
+		Compression           string                       `json:"compression" toml:"compression"`
 		SendWithTLS           bool                         `json:"send_with_tls" toml:"send_with_tls"`
+		Format                string                       `json:"format" toml:"format"`
+		AdditionalEndpoints   []LogsEndpoint               `json:"additional_endpoints" toml:"additional_endpoints"`
+		TCP                   LogsTCP                      `json:"tcp" toml:"tcp"`
 		BatchWait             int                          `json:"batch_wait" toml:"batch_wait"`
+		SendStrategy          string                       `json:"send_strategy" toml:"send_strategy"`
+		MaxBatchSize          int                          `json:"max_batch_size" toml:"max_batch_size"`
+		MaxPayloadBytes       int                          `json:"max_payload_bytes" toml:"max_payload_bytes"`
+		FlushInterval         Duration                     `json:"flush_interval" toml:"flush_interval"`
 		RunPath               string                       `json:"run_path" toml:"run_path"`
+		SpoolDir              string                       `json:"spool_dir" toml:"spool_dir"`
+		SpoolMaxSizeMB        int64                        `json:"spool_max_size_mb" toml:"spool_max_size_mb"`
 		OpenFilesLimit        int                          `json:"open_files_limit" toml:"open_files_limit"`
 		ScanPeriod            int                          `json:"scan_period" toml:"scan_period"`
 		FrameSize             int                          `json:"frame_size" toml:"frame_size"`
@@ -35,6 +45,30 @@ type (
 		KafkaConfig
 		KubeConfig
 	}
+	// LogsEndpoint is an additional endpoint of send_type, the logs are sent to it too
+	LogsEndpoint struct {
+		SendTo           string `json:"send_to" toml:"send_to"`
+		APIKey           string `json:"api_key" toml:"api_key"`
+		Topic            string `json:"topic" toml:"topic"`
+		UseCompression   bool   `json:"use_compression" toml:"use_compression"`
+		Compression      string `json:"compression" toml:"compression"`
+		CompressionLevel int    `json:"compression_level" toml:"compression_level"`
+		SendWithTLS      bool   `json:"send_with_tls" toml:"send_with_tls"`
+		Format           string `json:"format" toml:"format"`
+	}
+	// LogsTCP is the settings of the connections of send_type tcp
+	LogsTCP struct {
+		// newline or length_prefix, newline by default
+		Framing string `json:"framing" toml:"framing"`
+		// the period of keepalive probes, 15s by default, negative disables the probes
+		KeepAlive Duration `json:"keep_alive" toml:"keep_alive"`
+		// the connection is reconnected if a write is blocked longer, 30s by default
+		WriteTimeout Duration `json:"write_timeout" toml:"write_timeout"`
+		// the connection is reconnected periodically if set, e.g. to rebalance behind a load balancer
+		ConnectionResetInterval Duration `json:"connection_reset_interval" toml:"connection_reset_interval"`
+		// the max backoff between reconnections, 2m by default
+		ReconnectBackoffMax Duration `json:"reconnect_backoff_max" toml:"reconnect_backoff_max"`
+	}
 	KafkaConfig struct {
 		Topic   string   `json:"topic" toml:"topic"`
 		Brokers []string `json:"brokers" toml:"brokers"`
@@ -31,6 +31,42 @@ const (
 	EPIntakeVersion2
 )
 
+// The formats of the payloads sent to an endpoint.
+const (
+	// RawFormat sends the lines in RFC5424, one line per message
+	RawFormat = "raw"
+	// JSONFormat sends the messages in JSON envelopes with tags, batched in a JSON array
+	JSONFormat = "json"
+	// NDJSONFormat sends the JSON envelopes batched one per line
+	NDJSONFormat = "ndjson"
+	// DatadogFormat sends the messages in the JSON of the logs intake of Datadog
+	DatadogFormat = "datadog"
+	// ProtoFormat sends the messages in protobuf, one by one
+	ProtoFormat = "proto"
+)
+
+// The algorithms of compression of the payloads sent to http and kafka.
+const (
+	GzipCompression = "gzip"
+	ZstdCompression = "zstd"
+)
+
+// The framings of the messages sent over tcp.
+const (
+	// NewlineFraming appends a line break after each message
+	NewlineFraming = "newline"
+	// LengthPrefixFraming prepends the length of each message as a big-endian uint32
+	LengthPrefixFraming = "length_prefix"
+)
+
+// The strategies of sending the messages to the endpoints.
+const (
+	// StreamStrategy sends the messages one by one
+	StreamStrategy = "stream"
+	// BatchStrategy buffers the messages and sends them in a payload when the batch is full or outdated
+	BatchStrategy = "batch"
+)
+
 // Endpoint holds all the organization and network parameters to send logs
 type Endpoint struct {
 	APIKey                  string `mapstructure:"api_key" json:"api_key"`
@@ -44,6 +80,9 @@ type Endpoint struct {
 	ProxyAddress            string
 	ConnectionResetInterval time.Duration
 
+	// gzip or zstd, gzip if empty with UseCompression
+	Compression string
+
 	BackoffFactor    float64
 	BackoffBase      float64
 	BackoffMax       float64
@@ -54,6 +93,15 @@ type Endpoint struct {
 	TrackType IntakeTrackType
 	Protocol  IntakeProtocol
 	Origin    IntakeOrigin
+
+	// the format of the payloads, the default of the send type if empty
+	Format string
+
+	// the settings of tcp connections
+	Framing             string
+	KeepAlive           time.Duration
+	WriteTimeout        time.Duration
+	ReconnectBackoffMax time.Duration
 }
 
 // Endpoints holds the main endpoint and additional ones to dualship logs.
@@ -62,8 +110,20 @@ type Endpoints struct {
 	Additionals            []Endpoint
 	UseProto               bool
 	Type                   string
+	Strategy               string
 	BatchWait              time.Duration
 	BatchMaxConcurrentSend int
 	BatchMaxSize           int
 	BatchMaxContentSize    int
+	// the payloads failed to send to the main endpoint are spooled if set, up to SpoolMaxSize bytes
+	SpoolDir     string
+	SpoolMaxSize int64
+}
+
+// Batched tells if the messages are sent in batches, which is the default of http
+func (e *Endpoints) Batched() bool {
+	if e.Strategy == "" {
+		return e.Type == "http"
+	}
+	return e.Strategy == BatchStrategy
 }
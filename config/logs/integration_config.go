@@ -22,6 +22,11 @@ const (
 	WindowsEventType  = "windows_event"
 	SnmpTrapsType     = "snmp_traps"
 	StringChannelType = "string_channel"
+	UnixType          = "unix"
+
+	// formats of the content, fields are extracted into tags
+	K8sAuditFormat = "k8s_audit"
+	AuditdFormat   = "auditd"
 
 	// UTF16BE for UTF-16 Big endian encoding
 	UTF16BE string = "utf-16-be"
@@ -47,8 +52,9 @@ type (
 
 		Port        int    // Network
 		IdleTimeout string `mapstructure:"idle_timeout" json:"idle_timeout" toml:"idle_timeout"` // Network
-		Path        string // File, Journald
+		Path        string // File, Journald, Unix
 		Topic       string `mapstructure:"topic" json:"topic" toml:"topic"`
+		Format      string `mapstructure:"format" json:"format" toml:"format"` // k8s_audit or auditd, fields are added to tags
 
 		Encoding     string   `mapstructure:"encoding" json:"encoding" toml:"encoding"`                   // File
 		ExcludePaths []string `mapstructure:"exclude_paths" json:"exclude_paths" toml:"exclude_paths"`    // File
@@ -145,6 +151,13 @@ func (c *LogsConfig) Validate() error {
 		return fmt.Errorf("tcp source must have a port")
 	case c.Type == UDPType && c.Port == 0:
 		return fmt.Errorf("udp source must have a port")
+	case c.Type == UnixType && c.Path == "":
+		return fmt.Errorf("unix source must have a path")
+	}
+	switch c.Format {
+	case "", K8sAuditFormat, AuditdFormat:
+	default:
+		return fmt.Errorf("unknown format '%v', must be %v or %v", c.Format, K8sAuditFormat, AuditdFormat)
 	}
 	err := ValidateProcessingRules(c.ProcessingRules)
 	if err != nil {
@@ -0,0 +1,99 @@
+//go:build !no_logs
+
+package config
+
+import (
+	"sync"
+
+	logsconfig "flashcat.cloud/categraf/config/logs"
+)
+
+const logChannelSize = 1000
+
+// LogChannel forwards the messages produced by metrics inputs to the logs agent,
+// messages are dropped when the logs agent is not running or falls behind
+type LogChannel struct {
+	name    string
+	service string
+	source  string
+	format  string
+	ch      chan *logsconfig.ChannelMessage
+}
+
+var logChannels = struct {
+	sync.RWMutex
+	items   map[string]*LogChannel
+	sources *logsconfig.LogSources
+}{items: make(map[string]*LogChannel)}
+
+// NewLogChannel returns the log channel of name, channels of the same name are shared,
+// so inputs reloaded keep sending to the same logs source, format is the format of logs items
+func NewLogChannel(name, service, source, format string) *LogChannel {
+	logChannels.Lock()
+	defer logChannels.Unlock()
+
+	if c, has := logChannels.items[name]; has {
+		return c
+	}
+
+	c := &LogChannel{name: name, service: service, source: source, format: format}
+	logChannels.items[name] = c
+	if logChannels.sources != nil {
+		c.attach(logChannels.sources)
+	}
+	return c
+}
+
+// Send returns false if the message is dropped
+func (c *LogChannel) Send(content []byte) bool {
+	logChannels.RLock()
+	defer logChannels.RUnlock()
+
+	if c.ch == nil {
+		return false
+	}
+
+	select {
+	case c.ch <- &logsconfig.ChannelMessage{Content: content}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *LogChannel) attach(sources *logsconfig.LogSources) {
+	c.ch = make(chan *logsconfig.ChannelMessage, logChannelSize)
+	source := logsconfig.NewLogSource(c.name, &logsconfig.LogsConfig{
+		Type:    logsconfig.StringChannelType,
+		Service: c.service,
+		Source:  c.source,
+		Format:  c.format,
+		Channel: c.ch,
+	})
+	// AddSource blocks until the launcher takes the source
+	go sources.AddSource(source)
+}
+
+// AttachLogChannels adds the log channels to the sources of logs agent,
+// including the channels created later
+func AttachLogChannels(sources *logsconfig.LogSources) {
+	logChannels.Lock()
+	defer logChannels.Unlock()
+
+	logChannels.sources = sources
+	for _, c := range logChannels.items {
+		c.attach(sources)
+	}
+}
+
+// DetachLogChannels stops sending to the logs agent, must be called before
+// the channel launcher closes the channels on stop
+func DetachLogChannels() {
+	logChannels.Lock()
+	defer logChannels.Unlock()
+
+	logChannels.sources = nil
+	for _, c := range logChannels.items {
+		c.ch = nil
+	}
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/cronexpr"
+)
+
+const MaintenanceLabelKey = "maintenance"
+
+// Maintenance is a window starting at the cron schedule and lasting for duration, e.g. of backups.
+// samples gathered in the window are labeled with maintenance=1, or not gathered at all if skip is true
+type Maintenance struct {
+	Schedule string   `toml:"schedule"`
+	Duration Duration `toml:"duration"`
+	Skip     bool     `toml:"skip"`
+
+	expr *cronexpr.Expression
+}
+
+func (m *Maintenance) init() error {
+	if m.Duration <= 0 {
+		return fmt.Errorf("duration of maintenance %q should be positive", m.Schedule)
+	}
+
+	expr, err := cronexpr.Parse(m.Schedule)
+	if err != nil {
+		return fmt.Errorf("invalid schedule of maintenance %q: %v", m.Schedule, err)
+	}
+	m.expr = expr
+	return nil
+}
+
+// active returns true if a window started in the duration before t
+func (m *Maintenance) active(t time.Time) bool {
+	start := m.expr.Next(t.Add(-time.Duration(m.Duration)))
+	return !start.IsZero() && !start.After(t)
+}
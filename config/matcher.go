@@ -0,0 +1,186 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"flashcat.cloud/categraf/pkg/conv"
+	"flashcat.cloud/categraf/pkg/filter"
+	"flashcat.cloud/categraf/types"
+)
+
+// SampleMatcher matches samples satisfying all of its conditions,
+// conditions not configured are ignored
+type SampleMatcher struct {
+	// metric name globs
+	Metrics []string `toml:"metrics"`
+	// metric name regular expressions
+	MetricsRegex []string `toml:"metrics_regex"`
+	// tag key to value glob, all of them must match
+	Tags map[string]string `toml:"tags"`
+	// value predicate, e.g. "< 0", ">= 1e12", "== NaN"
+	Value string `toml:"value"`
+
+	metricsFilter filter.Filter
+	metricsRegex  []*regexp.Regexp
+	tagsFilter    map[string]filter.Filter
+	valueOp       string
+	valueArg      float64
+}
+
+// Blocklist is a list of matchers, a sample is dropped if it matches any of them
+type Blocklist []*SampleMatcher
+
+func (bl Blocklist) Init() error {
+	for i := 0; i < len(bl); i++ {
+		if err := bl[i].Init(); err != nil {
+			return fmt.Errorf("blocklist rule %d: %v", i, err)
+		}
+	}
+	return nil
+}
+
+func (r *SampleMatcher) Init() error {
+	var err error
+	if len(r.Metrics) > 0 {
+		r.metricsFilter, err = filter.Compile(r.Metrics)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, expr := range r.MetricsRegex {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return err
+		}
+		r.metricsRegex = append(r.metricsRegex, re)
+	}
+
+	if len(r.Tags) > 0 {
+		r.tagsFilter = make(map[string]filter.Filter, len(r.Tags))
+		for k, v := range r.Tags {
+			f, err := filter.Compile([]string{v})
+			if err != nil {
+				return err
+			}
+			r.tagsFilter[k] = f
+		}
+	}
+
+	if r.Value != "" {
+		r.valueOp, r.valueArg, err = parseValuePredicate(r.Value)
+		if err != nil {
+			return err
+		}
+	}
+
+	if r.metricsFilter == nil && len(r.metricsRegex) == 0 && len(r.tagsFilter) == 0 && r.valueOp == "" {
+		return fmt.Errorf("no condition configured")
+	}
+
+	return nil
+}
+
+// parseValuePredicate parses predicates like "< 0" or ">=1e9", NaN and Inf are supported
+func parseValuePredicate(s string) (string, float64, error) {
+	s = strings.TrimSpace(s)
+	for _, op := range []string{"<=", ">=", "==", "!=", "<", ">"} {
+		if !strings.HasPrefix(s, op) {
+			continue
+		}
+		arg, err := strconv.ParseFloat(strings.TrimSpace(s[len(op):]), 64)
+		if err != nil {
+			return "", 0, fmt.Errorf("invalid value predicate %q: %v", s, err)
+		}
+		return op, arg, nil
+	}
+	return "", 0, fmt.Errorf("invalid value predicate %q, operator must be one of <, <=, >, >=, ==, !=", s)
+}
+
+// HasValue reports whether the value condition is configured
+func (r *SampleMatcher) HasValue() bool {
+	return r.valueOp != ""
+}
+
+// MatchValue reports whether the value satisfies the value condition
+func (r *SampleMatcher) MatchValue(value interface{}) bool {
+	v, err := conv.ToFloat64(value)
+	if err != nil {
+		return false
+	}
+
+	if math.IsNaN(r.valueArg) {
+		switch r.valueOp {
+		case "==":
+			return math.IsNaN(v)
+		case "!=":
+			return !math.IsNaN(v)
+		}
+		return false
+	}
+
+	switch r.valueOp {
+	case "<":
+		return v < r.valueArg
+	case "<=":
+		return v <= r.valueArg
+	case ">":
+		return v > r.valueArg
+	case ">=":
+		return v >= r.valueArg
+	case "==":
+		return v == r.valueArg
+	case "!=":
+		return v != r.valueArg
+	}
+	return false
+}
+
+// MatchSeries checks the metric name and tags only
+func (r *SampleMatcher) MatchSeries(s *types.Sample) bool {
+	if r.metricsFilter != nil && !r.metricsFilter.Match(s.Metric) {
+		return false
+	}
+
+	if len(r.metricsRegex) > 0 {
+		matched := false
+		for _, re := range r.metricsRegex {
+			if re.MatchString(s.Metric) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	for k, f := range r.tagsFilter {
+		v, has := s.Labels[k]
+		if !has || !f.Match(v) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (r *SampleMatcher) Match(s *types.Sample) bool {
+	if !r.MatchSeries(s) {
+		return false
+	}
+	return r.valueOp == "" || r.MatchValue(s.Value)
+}
+
+func (bl Blocklist) Match(s *types.Sample) bool {
+	for _, r := range bl {
+		if r.Match(s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,28 @@
+package config
+
+// MetricRegistry validates the samples written against the metrics declared by teams,
+// the violations are counted by categraf_metric_registry_violations_total
+type MetricRegistry struct {
+	Enable bool `toml:"enable"`
+	// globs of the registry files, each declares [[metrics]] of name, type, unit and help
+	Files []string `toml:"files"`
+	// flag | drop, the samples of unknown, miscased or badly named metrics are dropped if drop
+	Action string `toml:"action"`
+	// the regular expression the names of metrics must match, ^[a-z_:][a-z0-9_:]*$ by default
+	NamePattern string `toml:"name_pattern"`
+	// globs of the metrics not validated, e.g. of the inputs not covered by the registry yet
+	ExcludeMetrics []string `toml:"exclude_metrics"`
+	// the distinct metric names counted by the violations, the others are counted as __overflow__
+	MaxTrackedMetrics int `toml:"max_tracked_metrics"`
+}
+
+// MetricDeclaration is a metric declared in the registry files
+type MetricDeclaration struct {
+	// globs are supported, e.g. mysql_global_status_*
+	Name string `toml:"name"`
+	// counter | gauge | histogram | summary | untyped, counters must not be negative
+	Type string `toml:"type"`
+	// the base unit, e.g. seconds or bytes, the name must have the suffix of it
+	Unit string `toml:"unit"`
+	Help string `toml:"help"`
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/toolkits/pkg/file"
+)
+
+// Overlays are the config dirs merged onto the config dir in order, e.g. of the environment and then the host,
+// the config.toml and the input.* dirs of them are merged by cfg.MergeTOML, set before InitConfig
+var Overlays []string
+
+// ParseOverlays expands the env in the dirs separated by comma, the dirs not existing are skipped,
+// so that e.g. conf.d/host/${HOSTNAME} is shipped fleet-wide while only some of the hosts have it
+func ParseOverlays(s string) []string {
+	var dirs []string
+	for _, dir := range strings.Split(s, ",") {
+		dir = strings.TrimSpace(os.ExpandEnv(dir))
+		if dir == "" {
+			continue
+		}
+		if !file.IsExist(dir) {
+			log.Println("I! config overlay", dir, "not found, skipped")
+			continue
+		}
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
+
+// Layers returns the dir of rel under the config dir, followed by the ones under the overlays
+func Layers(configDir string, rel string) []string {
+	dirs := make([]string, 0, len(Overlays)+1)
+	dirs = append(dirs, path.Join(configDir, rel))
+	for _, o := range Overlays {
+		dirs = append(dirs, path.Join(o, rel))
+	}
+	return dirs
+}
+
+// DirsUnderLayers returns the names of the dirs under the config dir and the overlays, e.g. input.mysql,
+// in the order they are found, the dirs of the same name are listed once
+func DirsUnderLayers(configDir string) ([]string, error) {
+	var names []string
+	seen := make(map[string]struct{})
+	for _, layer := range Layers(configDir, "") {
+		dirs, err := file.DirsUnder(layer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get dirs under %s : %v", layer, err)
+		}
+		for _, dir := range dirs {
+			if _, has := seen[dir]; !has {
+				seen[dir] = struct{}{}
+				names = append(names, dir)
+			}
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,135 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/antonmedv/expr"
+	"github.com/antonmedv/expr/ast"
+	"github.com/antonmedv/expr/parser"
+	"github.com/antonmedv/expr/vm"
+
+	"flashcat.cloud/categraf/pkg/conv"
+	"flashcat.cloud/categraf/types"
+)
+
+// ProcessorExpr derives a new sample from samples with the same labels in a batch,
+// e.g. metric = "mem_used_percent", expr = "mem_used / mem_total * 100"
+type ProcessorExpr struct {
+	Metric string `toml:"metric"`
+	Expr   string `toml:"expr"`
+
+	program *vm.Program
+	idents  []string
+}
+
+type identCollector struct {
+	idents map[string]struct{}
+}
+
+func (c *identCollector) Enter(node *ast.Node) {}
+
+func (c *identCollector) Exit(node *ast.Node) {
+	if n, ok := (*node).(*ast.IdentifierNode); ok {
+		c.idents[n.Value] = struct{}{}
+	}
+}
+
+func (p *ProcessorExpr) init() error {
+	if p.Metric == "" || p.Expr == "" {
+		return fmt.Errorf("processor_expr: metric and expr are required")
+	}
+
+	tree, err := parser.Parse(p.Expr)
+	if err != nil {
+		return fmt.Errorf("processor_expr %s: %v", p.Metric, err)
+	}
+
+	collector := &identCollector{idents: map[string]struct{}{}}
+	ast.Walk(&tree.Node, collector)
+	if len(collector.idents) == 0 {
+		return fmt.Errorf("processor_expr %s: no metric referenced", p.Metric)
+	}
+	for ident := range collector.idents {
+		p.idents = append(p.idents, ident)
+	}
+
+	p.program, err = expr.Compile(p.Expr, expr.Env(map[string]float64{}), expr.AllowUndefinedVariables(), expr.AsFloat64())
+	if err != nil {
+		return fmt.Errorf("processor_expr %s: %v", p.Metric, err)
+	}
+	return nil
+}
+
+func (p *ProcessorExpr) eval(env map[string]float64) (float64, bool) {
+	for _, ident := range p.idents {
+		if _, has := env[ident]; !has {
+			return 0, false
+		}
+	}
+
+	out, err := expr.Run(p.program, env)
+	if err != nil {
+		return 0, false
+	}
+
+	v, ok := out.(float64)
+	if !ok || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, false
+	}
+	return v, true
+}
+
+type exprGroup struct {
+	sample *types.Sample
+	env    map[string]float64
+}
+
+// deriveSamples evaluates expressions against every group of samples sharing the same labels
+func deriveSamples(exprs []*ProcessorExpr, slist *types.SampleList) []*types.Sample {
+	groups := make(map[string]*exprGroup)
+	var keys []string
+
+	slist.Range(func(s *types.Sample) bool {
+		v, err := conv.ToFloat64(s.Value)
+		if err != nil {
+			return true
+		}
+
+		key := labelsKey(s.Labels)
+		g, has := groups[key]
+		if !has {
+			g = &exprGroup{sample: s, env: make(map[string]float64)}
+			groups[key] = g
+			keys = append(keys, key)
+		}
+		g.env[s.Metric] = v
+		return true
+	})
+
+	var ret []*types.Sample
+	for _, key := range keys {
+		g := groups[key]
+		for _, p := range exprs {
+			v, ok := p.eval(g.env)
+			if !ok {
+				continue
+			}
+			s := types.NewSample("", p.Metric, v, g.sample.Labels)
+			s.Timestamp = g.sample.Timestamp
+			ret = append(ret, s)
+		}
+	}
+	return ret
+}
+
+func labelsKey(labels map[string]string) string {
+	arr := make([]string, 0, len(labels))
+	for k, v := range labels {
+		arr = append(arr, k+"\xff"+v)
+	}
+	sort.Strings(arr)
+	return strings.Join(arr, "\xfe")
+}
@@ -0,0 +1,166 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"flashcat.cloud/categraf/pkg/conv"
+	"flashcat.cloud/categraf/pkg/filter"
+	"flashcat.cloud/categraf/types"
+)
+
+const defaultHistogramExpiration = 10 * time.Minute
+
+var defaultHistogramBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// ProcessorHistogram converts raw observations into cumulative histogram buckets,
+// <metric>_bucket, <metric>_sum and <metric>_count are written instead of the observations
+type ProcessorHistogram struct {
+	Metrics      []string  `toml:"metrics"` // support glob
+	Buckets      []float64 `toml:"buckets"`
+	KeepOriginal bool      `toml:"keep_original"`
+	// series without observations within expiration are forgotten
+	Expiration Duration `toml:"expiration"`
+
+	metricsFilter filter.Filter
+	bucketLabels  []string
+
+	sync.Mutex
+	series map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	metric  string
+	labels  map[string]string
+	counts  []uint64
+	count   uint64
+	sum     float64
+	updated time.Time
+}
+
+func (p *ProcessorHistogram) init() error {
+	if len(p.Metrics) == 0 {
+		return fmt.Errorf("processor_histogram: metrics is required")
+	}
+
+	var err error
+	p.metricsFilter, err = filter.Compile(p.Metrics)
+	if err != nil {
+		return err
+	}
+
+	if len(p.Buckets) == 0 {
+		p.Buckets = defaultHistogramBuckets
+	}
+	if !sort.Float64sAreSorted(p.Buckets) {
+		return fmt.Errorf("processor_histogram: buckets must be in increasing order")
+	}
+
+	p.bucketLabels = make([]string, 0, len(p.Buckets)+1)
+	for _, b := range p.Buckets {
+		p.bucketLabels = append(p.bucketLabels, strconv.FormatFloat(b, 'g', -1, 64))
+	}
+	p.bucketLabels = append(p.bucketLabels, "+Inf")
+
+	if p.Expiration <= 0 {
+		p.Expiration = Duration(defaultHistogramExpiration)
+	}
+
+	p.series = make(map[string]*histogramSeries)
+	return nil
+}
+
+// observe returns false if the sample is not an observation of the processor
+func (p *ProcessorHistogram) observe(s *types.Sample) bool {
+	if !p.metricsFilter.Match(s.Metric) {
+		return false
+	}
+
+	v, err := conv.ToFloat64(s.Value)
+	if err != nil || math.IsNaN(v) {
+		return false
+	}
+
+	key := s.Metric + "\xfe" + labelsKey(s.Labels)
+	hs, has := p.series[key]
+	if !has {
+		hs = &histogramSeries{
+			metric: s.Metric,
+			labels: s.Labels,
+			counts: make([]uint64, len(p.Buckets)+1),
+		}
+		p.series[key] = hs
+	}
+
+	// counts of le buckets, accumulated when emitting
+	idx := sort.SearchFloat64s(p.Buckets, v)
+	hs.counts[idx]++
+	hs.count++
+	hs.sum += v
+	hs.updated = time.Now()
+	return true
+}
+
+// collect emits all tracked series, so the cumulative counters keep continuous
+func (p *ProcessorHistogram) collect(now time.Time) []*types.Sample {
+	ret := make([]*types.Sample, 0, len(p.series)*(len(p.Buckets)+3))
+	for key, hs := range p.series {
+		if now.Sub(hs.updated) > time.Duration(p.Expiration) {
+			delete(p.series, key)
+			continue
+		}
+
+		var cumulative uint64
+		for i, c := range hs.counts {
+			cumulative += c
+			s := types.NewSample("", hs.metric+"_bucket", cumulative, hs.labels, map[string]string{"le": p.bucketLabels[i]})
+			s.Timestamp = now
+			ret = append(ret, s)
+		}
+
+		sum := types.NewSample("", hs.metric+"_sum", hs.sum, hs.labels)
+		sum.Timestamp = now
+		count := types.NewSample("", hs.metric+"_count", hs.count, hs.labels)
+		count.Timestamp = now
+		ret = append(ret, sum, count)
+	}
+	return ret
+}
+
+// aggregateHistograms feeds the observations in slist to processors and pushes the buckets instead
+func aggregateHistograms(procs []*ProcessorHistogram, slist *types.SampleList) *types.SampleList {
+	for _, p := range procs {
+		p.Lock()
+	}
+	defer func() {
+		for _, p := range procs {
+			p.Unlock()
+		}
+	}()
+
+	nlst := types.NewSampleList()
+	ss := slist.PopBackAll()
+	for _, s := range ss {
+		var observed, keep bool
+		for _, p := range procs {
+			if p.observe(s) {
+				observed = true
+				keep = keep || p.KeepOriginal
+			}
+		}
+		if !observed || keep {
+			nlst.PushFront(s)
+		}
+	}
+	types.ReleaseSamples(ss)
+
+	now := time.Now()
+	for _, p := range procs {
+		nlst.PushFrontN(p.collect(now))
+	}
+	return nlst
+}
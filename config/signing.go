@@ -0,0 +1,21 @@
+package config
+
+// Signing signs the payloads of remote write and logs over http, so receivers can authenticate
+// which host produced which data, e.g. in multi-tenant environments
+type Signing struct {
+	Enable bool `toml:"enable"`
+	// hmac | cert
+	Mode string `toml:"mode"`
+
+	// hmac: the keys in lines of "<key_id> <secret>", the last one signs, the file is reloaded once changed,
+	// so keys are rotated by appending the new one, while receivers still accept the previous ones
+	KeyFile string `toml:"key_file"`
+	// hmac: or the key inline
+	KeyID string `toml:"key_id"`
+	Key   string `toml:"key"`
+
+	// cert: signs by the private key of the certificate identifying the host by mtls, the key id is the sha256
+	// fingerprint of the certificate, the files are reloaded once changed
+	TLSCert string `toml:"tls_cert"`
+	TLSKey  string `toml:"tls_key"`
+}
@@ -27,6 +27,14 @@ import (
 	"go.opentelemetry.io/collector/receiver/otlpreceiver"
 )
 
+var extraProcessors []component.ProcessorFactory
+
+// RegisterProcessorFactory registers processors implemented by categraf itself,
+// which can't be imported here directly because they depend on the config package
+func RegisterProcessorFactory(f component.ProcessorFactory) {
+	extraProcessors = append(extraProcessors, f)
+}
+
 // Add more factories here if you need
 func components() (component.Factories, error) {
 	extensions, err := component.MakeExtensionFactoryMap(
@@ -62,14 +70,14 @@ func components() (component.Factories, error) {
 		return component.Factories{}, err
 	}
 
-	processors, err := component.MakeProcessorFactoryMap(
+	processors, err := component.MakeProcessorFactoryMap(append([]component.ProcessorFactory{
 		batchprocessor.NewFactory(),
 		attributesprocessor.NewFactory(),
 		tailsamplingprocessor.NewFactory(),
 		resourceprocessor.NewFactory(),
 		spanprocessor.NewFactory(),
 		memorylimiterprocessor.NewFactory(),
-	)
+	}, extraProcessors...)...)
 	if err != nil {
 		return component.Factories{}, err
 	}
@@ -6,6 +6,8 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"sort"
+	"strings"
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config"
@@ -19,24 +21,43 @@ import (
 // Config defines the OpenTelemetry Collector configuration.
 //
 //	Enable:     enable tracing or not.
+//	HostAttributes: inject host tags (host.name and global labels) into resource attributes of spans.
+//	SemanticConventions: rename the host tags by the semantic conventions of otel, e.g. region to cloud.region,
+//	            and add the attributes detected, e.g. os.type, host.arch and k8s.pod.name.
+//	SemanticMapping: the host tags renamed, taking precedence over the semantic conventions, e.g. bu: service.namespace.
 //	UnParsed:   loaded as map[string]interface{} from the raw config file.
 //	Parsed:     retrieved and validated from the UnParsed contents.
 //	Factories:  struct holds in a single type all component factories that can be handled by the Config.
 //	            We only create the needed factories as default, if you need more, import and init these by components.go
 type Config struct {
-	Enable    bool                   `toml:"enable"  yaml:"enable"  json:"enable"`
-	UnParsed  map[string]interface{} `toml:",inline" yaml:",inline" json:",inline"`
-	Parsed    *config.Config         `toml:"-"       yaml:"-"       json:"parsed"`
-	Factories component.Factories    `toml:"-"       yaml:"-"       json:"-"`
+	Enable         bool                   `toml:"enable"          yaml:"enable"          json:"enable"`
+	HostAttributes bool                   `toml:"host_attributes" yaml:"host_attributes" json:"host_attributes"`
+	UnParsed       map[string]interface{} `toml:",inline"         yaml:",inline"         json:",inline"`
+	Parsed         *config.Config         `toml:"-"               yaml:"-"               json:"parsed"`
+	Factories      component.Factories    `toml:"-"               yaml:"-"               json:"-"`
+
+	SemanticConventions bool              `toml:"semantic_conventions" yaml:"semantic_conventions" json:"semantic_conventions"`
+	SemanticMapping     map[string]string `toml:"semantic_mapping"     yaml:"semantic_mapping"     json:"semantic_mapping"`
 }
 
-// Parse parse the UnParsed contents to Parsed
-func Parse(c *Config) error {
+const hostAttributesProcessor = "resource/categraf_host"
+
+// Parse parse the UnParsed contents to Parsed, hostAttrs are injected if HostAttributes enabled,
+// cloudPrefix is the prefix of the labels of cloud metadata in hostAttrs
+func Parse(c *Config, hostAttrs map[string]string, cloudPrefix string) error {
 	if c == nil || len(c.UnParsed) == 0 || !c.Enable {
 		log.Println("I! tracing disabled")
 		return nil
 	}
 
+	if c.SemanticConventions {
+		hostAttrs = semanticAttributes(hostAttrs, cloudPrefix, c.SemanticMapping)
+	}
+
+	if c.HostAttributes && len(hostAttrs) > 0 {
+		injectHostAttributes(c.UnParsed, hostAttrs)
+	}
+
 	ymlCfg, err := yaml.Marshal(c.UnParsed)
 	if err != nil {
 		return fmt.Errorf("unable to marshal trace config, %v", err)
@@ -71,3 +92,57 @@ func makeMapProvidersMap(providers ...confmap.Provider) map[string]confmap.Provi
 	}
 	return ret
 }
+
+// injectHostAttributes adds a resource processor inserting the host attributes,
+// attributes reported by applications are kept, and puts it into every traces pipeline
+func injectHostAttributes(unParsed map[string]interface{}, hostAttrs map[string]string) {
+	keys := make([]string, 0, len(hostAttrs))
+	for k := range hostAttrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	actions := make([]interface{}, 0, len(keys))
+	for _, k := range keys {
+		actions = append(actions, map[string]interface{}{
+			"key":    k,
+			"value":  hostAttrs[k],
+			"action": "insert",
+		})
+	}
+
+	processors, ok := unParsed["processors"].(map[string]interface{})
+	if !ok {
+		processors = make(map[string]interface{})
+		unParsed["processors"] = processors
+	}
+	processors[hostAttributesProcessor] = map[string]interface{}{"attributes": actions}
+
+	svc, ok := unParsed["service"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	pipelines, ok := svc["pipelines"].(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	for name, v := range pipelines {
+		if name != "traces" && !strings.HasPrefix(name, "traces/") {
+			continue
+		}
+		pipeline, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		names, _ := pipeline["processors"].([]interface{})
+		// memory_limiter should be the first one
+		idx := 0
+		if len(names) > 0 && names[0] == "memory_limiter" {
+			idx = 1
+		}
+		names = append(names[:idx], append([]interface{}{hostAttributesProcessor}, names[idx:]...)...)
+		pipeline["processors"] = names
+	}
+}
@@ -5,6 +5,6 @@ package traces
 type Config struct {
 }
 
-func Parse(c *Config) error {
+func Parse(c *Config, hostAttrs map[string]string, cloudPrefix string) error {
 	return nil
 }
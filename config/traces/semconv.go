@@ -0,0 +1,118 @@
+//go:build !no_traces
+
+package traces
+
+import (
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// the default tags of categraf renamed to the semantic conventions of otel resources,
+// see https://opentelemetry.io/docs/specs/semconv/resource/
+var semconvKeys = map[string]string{
+	"ident":          "host.name",
+	"hostname":       "host.name",
+	"ip":             "host.ip",
+	"env":            "deployment.environment",
+	"service":        "service.name",
+	"cluster":        "k8s.cluster.name",
+	"namespace":      "k8s.namespace.name",
+	"pod":            "k8s.pod.name",
+	"pod_name":       "k8s.pod.name",
+	"node":           "k8s.node.name",
+	"node_name":      "k8s.node.name",
+	"container":      "container.name",
+	"container_name": "container.name",
+}
+
+// the labels of global.cloud_meta, prefixed by label_prefix
+var semconvCloudKeys = map[string]string{
+	"cloud_provider": "cloud.provider",
+	"region":         "cloud.region",
+	"zone":           "cloud.availability_zone",
+	"account_id":     "cloud.account.id",
+	"instance_id":    "host.id",
+	"instance_type":  "host.type",
+}
+
+var semconvCloudProviders = map[string]string{
+	"aliyun":  "alibaba_cloud",
+	"tencent": "tencent_cloud",
+}
+
+var semconvArchs = map[string]string{
+	"386":   "x86",
+	"arm":   "arm32",
+	"ppc64": "ppc64",
+	"s390x": "s390x",
+	"amd64": "amd64",
+	"arm64": "arm64",
+}
+
+// the environments of the downward api of kubernetes, e.g.
+// env: [{name: POD_NAME, valueFrom: {fieldRef: {fieldPath: metadata.name}}}]
+var semconvEnvs = map[string]string{
+	"POD_NAME":      "k8s.pod.name",
+	"POD_NAMESPACE": "k8s.namespace.name",
+	"NODE_NAME":     "k8s.node.name",
+}
+
+// semanticAttributes renames the host tags by the semantic conventions, or mapping which takes precedence,
+// and adds the attributes detected, e.g. os.type and k8s.pod.name. the tags not renamed are kept as is,
+// and take precedence over the renamed ones, e.g. host.name over ident, which take precedence over the detected
+func semanticAttributes(hostAttrs map[string]string, cloudPrefix string, mapping map[string]string) map[string]string {
+	ret := make(map[string]string, len(hostAttrs)+4)
+	for env, key := range semconvEnvs {
+		if v := os.Getenv(env); v != "" {
+			ret[key] = v
+		}
+	}
+	ret["os.type"] = runtime.GOOS
+	if arch, ok := semconvArchs[runtime.GOARCH]; ok {
+		ret["host.arch"] = arch
+	}
+
+	keys := make([]string, 0, len(hostAttrs))
+	for k := range hostAttrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var kept []string
+	for _, k := range keys {
+		key, ok := semanticKey(k, cloudPrefix, mapping)
+		if !ok {
+			kept = append(kept, k)
+			continue
+		}
+
+		v := hostAttrs[k]
+		if key == "cloud.provider" {
+			if provider, ok := semconvCloudProviders[v]; ok {
+				v = provider
+			}
+		}
+		ret[key] = v
+	}
+	for _, k := range kept {
+		ret[k] = hostAttrs[k]
+	}
+	return ret
+}
+
+func semanticKey(k, cloudPrefix string, mapping map[string]string) (string, bool) {
+	if key, ok := mapping[k]; ok {
+		return key, true
+	}
+	if key, ok := semconvKeys[k]; ok {
+		return key, true
+	}
+	if strings.HasPrefix(k, cloudPrefix) {
+		if key, ok := semconvCloudKeys[strings.TrimPrefix(k, cloudPrefix)]; ok {
+			return key, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"crypto/tls"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// TransportOption tunes the connections of the http clients, e.g. of the writers and the scrapes
+type TransportOption struct {
+	MaxIdleConns        int      `toml:"max_idle_conns"`
+	MaxIdleConnsPerHost int      `toml:"max_idle_conns_per_host"`
+	IdleConnTimeout     Duration `toml:"idle_conn_timeout"`
+	// true or false enables or disables http/2 of https, the default of go is kept if not set
+	HTTP2 *bool `toml:"http2"`
+	// the idle connections are closed at the interval, so the new connections resolve the domain
+	// again, instead of pinning to one ip of the load balanced receivers forever
+	DNSRefreshInterval Duration `toml:"dns_refresh_interval"`
+}
+
+// RoundTripper applies the options to t
+func (o *TransportOption) RoundTripper(t *http.Transport) http.RoundTripper {
+	if o.MaxIdleConns > 0 {
+		t.MaxIdleConns = o.MaxIdleConns
+	}
+	if o.MaxIdleConnsPerHost > 0 {
+		t.MaxIdleConnsPerHost = o.MaxIdleConnsPerHost
+	}
+	if o.IdleConnTimeout > 0 {
+		t.IdleConnTimeout = time.Duration(o.IdleConnTimeout)
+	}
+
+	if o.HTTP2 != nil {
+		if *o.HTTP2 {
+			t.ForceAttemptHTTP2 = true
+		} else {
+			// a non-nil empty map disables http/2
+			t.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+		}
+	}
+
+	if o.DNSRefreshInterval <= 0 {
+		return t
+	}
+	return &refreshTransport{Transport: t, interval: time.Duration(o.DNSRefreshInterval), last: time.Now()}
+}
+
+type refreshTransport struct {
+	*http.Transport
+	interval time.Duration
+
+	sync.Mutex
+	last time.Time
+}
+
+func (t *refreshTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.Lock()
+	if time.Since(t.last) >= t.interval {
+		t.last = time.Now()
+		t.CloseIdleConnections()
+	}
+	t.Unlock()
+	return t.Transport.RoundTrip(req)
+}
+
+// ResponseCacheOption reuses the samples parsed from the last response of a url of the scrapes,
+// instead of parsing the same text again, e.g. of the exporters changing slowly
+type ResponseCacheOption struct {
+	// the samples parsed are reused in it if the response is not modified or identical, 0 disables the cache
+	ResponseCacheTTL Duration `toml:"response_cache_ttl"`
+	// send If-None-Match and If-Modified-Since of the last response, so the exporters answer 304 if not modified
+	ConditionalRequests bool `toml:"conditional_requests"`
+}
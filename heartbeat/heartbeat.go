@@ -5,7 +5,6 @@ import (
 	"compress/gzip"
 	"encoding/json"
 	"io/ioutil"
-	"log"
 	"net"
 	"net/http"
 	"runtime"
@@ -14,9 +13,12 @@ import (
 
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs/system"
+	"flashcat.cloud/categraf/pkg/logger"
 	cpuUtil "github.com/shirou/gopsutil/v3/cpu"
 )
 
+var heartbeatLog = logger.New("heartbeat")
+
 const collinterval = 3
 
 func Work() {
@@ -39,9 +41,9 @@ func Work() {
 		interval = 4
 	}
 
-	client, err := newHTTPClient()
+	client, err := newHTTPClient(conf)
 	if err != nil {
-		log.Println("E! failed to create heartbeat client:", err)
+		heartbeatLog.Errorf("failed to create heartbeat client: %v", err)
 		return
 	}
 
@@ -53,27 +55,27 @@ func Work() {
 	}
 }
 
-func newHTTPClient() (*http.Client, error) {
-	proxy, err := config.Config.Heartbeat.Proxy()
+func newHTTPClient(conf *config.HeartbeatConfig) (*http.Client, error) {
+	proxy, err := conf.Proxy()
 	if err != nil {
 		return nil, err
 	}
 
-	timeout := time.Duration(config.Config.Heartbeat.Timeout) * time.Millisecond
+	timeout := time.Duration(conf.Timeout) * time.Millisecond
 
 	trans := &http.Transport{
 		Proxy: proxy,
 		DialContext: (&net.Dialer{
-			Timeout: time.Duration(config.Config.Heartbeat.DialTimeout) * time.Millisecond,
+			Timeout: time.Duration(conf.DialTimeout) * time.Millisecond,
 		}).DialContext,
 		ResponseHeaderTimeout: timeout,
-		MaxIdleConnsPerHost:   config.Config.Heartbeat.MaxIdleConnsPerHost,
+		MaxIdleConnsPerHost:   conf.MaxIdleConnsPerHost,
 	}
 
-	if strings.HasPrefix(config.Config.Heartbeat.Url, "https:") {
-		tlsCfg, err := config.Config.Heartbeat.TLSConfig()
+	if strings.HasPrefix(conf.Url, "https:") {
+		tlsCfg, err := conf.TLSConfig()
 		if err != nil {
-			log.Println("E! failed to init tls:", err)
+			heartbeatLog.Errorf("failed to init tls: %v", err)
 			return nil, err
 		}
 
@@ -106,70 +108,77 @@ func work(version string, ps *system.SystemPS, client *http.Client) {
 
 	bs, err := json.Marshal(data)
 	if err != nil {
-		log.Println("E! failed to marshal heartbeat request:", err)
+		heartbeatLog.Errorf("failed to marshal heartbeat request: %v", err)
 		return
 	}
 
+	post(config.Config.Heartbeat, client, version, bs, "heartbeat")
+}
+
+// post sends the json body gzipped to conf.Url, kind is the name in logs, e.g. heartbeat
+func post(conf *config.HeartbeatConfig, client *http.Client, version string, bs []byte, kind string) bool {
 	var buf bytes.Buffer
 	g := gzip.NewWriter(&buf)
-	if _, err = g.Write(bs); err != nil {
-		log.Println("E! failed to write gzip buffer:", err)
-		return
+	if _, err := g.Write(bs); err != nil {
+		heartbeatLog.Errorf("failed to write gzip buffer: %v", err)
+		return false
 	}
 
-	if err = g.Close(); err != nil {
-		log.Println("E! failed to close gzip buffer:", err)
-		return
+	if err := g.Close(); err != nil {
+		heartbeatLog.Errorf("failed to close gzip buffer: %v", err)
+		return false
 	}
 
-	req, err := http.NewRequest("POST", config.Config.Heartbeat.Url, &buf)
+	req, err := http.NewRequest("POST", conf.Url, &buf)
 	if err != nil {
-		log.Println("E! failed to new heartbeat request:", err)
-		return
+		heartbeatLog.Infof("%v %v", "E! failed to new "+kind+" request:", err)
+		return false
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Content-Encoding", "gzip")
 	req.Header.Set("User-Agent", "categraf/"+version)
 
-	for i := 0; i < len(config.Config.Heartbeat.Headers); i += 2 {
-		req.Header.Add(config.Config.Heartbeat.Headers[i], config.Config.Heartbeat.Headers[i+1])
-		if config.Config.Heartbeat.Headers[i] == "Host" {
-			req.Host = config.Config.Heartbeat.Headers[i+1]
+	for i := 0; i < len(conf.Headers); i += 2 {
+		req.Header.Add(conf.Headers[i], conf.Headers[i+1])
+		if conf.Headers[i] == "Host" {
+			req.Host = conf.Headers[i+1]
 		}
 	}
 
-	if config.Config.Heartbeat.BasicAuthPass != "" {
-		req.SetBasicAuth(config.Config.Heartbeat.BasicAuthUser, config.Config.Heartbeat.BasicAuthPass)
+	if conf.BasicAuthPass != "" {
+		req.SetBasicAuth(conf.BasicAuthUser, conf.BasicAuthPass)
 	}
 
 	res, err := client.Do(req)
 	if err != nil {
-		log.Println("E! failed to do heartbeat:", err)
-		return
+		heartbeatLog.Infof("%v %v", "E! failed to do "+kind+":", err)
+		return false
 	}
 
+	defer res.Body.Close()
+
 	if res.StatusCode/100 != 2 {
-		log.Println("E! heartbeat status code:", res.StatusCode)
-		return
+		heartbeatLog.Infof("%v %v", "E! "+kind+" status code:", res.StatusCode)
+		return false
 	}
 
-	defer res.Body.Close()
 	bs, err = ioutil.ReadAll(res.Body)
 	if err != nil {
-		log.Println("E! failed to read heartbeat response body:", err)
-		return
+		heartbeatLog.Infof("%v %v", "E! failed to read "+kind+" response body:", err)
+		return false
 	}
 
 	if config.Config.DebugMode {
-		log.Println("D! heartbeat response:", string(bs), "status code:", res.StatusCode)
+		heartbeatLog.Infof("%v %v status code: %v", "D! "+kind+" response:", string(bs), res.StatusCode)
 	}
+	return true
 }
 
 func memUsage(ps *system.SystemPS) float64 {
 	vm, err := ps.VMStat()
 	if err != nil {
-		log.Println("E! failed to get vmstat:", err)
+		heartbeatLog.Errorf("failed to get vmstat: %v", err)
 		return 0
 	}
 
@@ -187,7 +196,7 @@ func cpuUsage(ps *system.SystemPS) float64 {
 	// first
 	times, err := ps.CPUTimes(false, true)
 	if err != nil {
-		log.Println("E! failed to collect cpu_util:", err)
+		heartbeatLog.Errorf("failed to collect cpu_util: %v", err)
 		return 0
 	}
 
@@ -203,7 +212,7 @@ func cpuUsage(ps *system.SystemPS) float64 {
 	// sencond
 	times, err = ps.CPUTimes(false, true)
 	if err != nil {
-		log.Println("E! failed to collect cpu_util:", err)
+		heartbeatLog.Errorf("failed to collect cpu_util: %v", err)
 		return 0
 	}
 
@@ -216,7 +225,7 @@ func cpuUsage(ps *system.SystemPS) float64 {
 	// compute
 	totalDelta := total - lastTotal
 	if totalDelta < 0 {
-		log.Println("W! current total CPU time is less than previous total CPU time")
+		heartbeatLog.Warnf("current total CPU time is less than previous total CPU time")
 		return 0
 	}
 
@@ -0,0 +1,181 @@
+package heartbeat
+
+import (
+	"encoding/json"
+	"net"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/shirou/gopsutil/v3/host"
+	"github.com/shirou/gopsutil/v3/mem"
+
+	"flashcat.cloud/categraf/config"
+)
+
+const defaultInventoryInterval = 3600
+
+// Inventory is the metadata of host, posted to the host metadata api of CMDB or nightingale
+type Inventory struct {
+	Hostname        string            `json:"hostname"`
+	AgentVersion    string            `json:"agent_version"`
+	OS              string            `json:"os"`
+	Arch            string            `json:"arch"`
+	Platform        string            `json:"platform"`
+	PlatformFamily  string            `json:"platform_family"`
+	PlatformVersion string            `json:"platform_version"`
+	KernelVersion   string            `json:"kernel_version"`
+	Virtualization  string            `json:"virtualization,omitempty"`
+	HostID          string            `json:"host_id,omitempty"`
+	BootTime        uint64            `json:"boot_time"`
+	CPUModel        string            `json:"cpu_model"`
+	CPUNum          int               `json:"cpu_num"`
+	CPUCores        int               `json:"cpu_cores"`
+	MemTotal        uint64            `json:"mem_total"`
+	SwapTotal       uint64            `json:"swap_total"`
+	Disks           []InventoryDisk   `json:"disks"`
+	IPs             []string          `json:"ips"`
+	Labels          map[string]string `json:"labels,omitempty"`
+	Unixtime        int64             `json:"unixtime"`
+}
+
+type InventoryDisk struct {
+	Device     string `json:"device"`
+	Mountpoint string `json:"mountpoint"`
+	Fstype     string `json:"fstype"`
+	Total      uint64 `json:"total"`
+}
+
+// WorkInventory reports the inventory at start and every interval
+func WorkInventory() {
+	conf := config.Config.Inventory
+	if conf == nil || !conf.Enable {
+		return
+	}
+
+	interval := conf.Interval
+	if interval <= 0 {
+		interval = defaultInventoryInterval
+	}
+
+	client, err := newHTTPClient(&conf.HeartbeatConfig)
+	if err != nil {
+		heartbeatLog.Errorf("failed to create inventory client: %v", err)
+		return
+	}
+
+	version := strings.Split(config.Version, "-")[0]
+	for {
+		bs, err := json.Marshal(collectInventory(version))
+		if err != nil {
+			heartbeatLog.Errorf("failed to marshal inventory: %v", err)
+		} else {
+			post(&conf.HeartbeatConfig, client, version, bs, "inventory")
+		}
+		time.Sleep(time.Duration(interval) * time.Second)
+	}
+}
+
+// collectInventory collects what it can, items failed to collect are left empty
+func collectInventory(version string) *Inventory {
+	inv := &Inventory{
+		Hostname:     config.Config.GetHostname(),
+		AgentVersion: version,
+		OS:           runtime.GOOS,
+		Arch:         runtime.GOARCH,
+		CPUNum:       runtime.NumCPU(),
+		Labels:       config.Config.Global.Labels,
+		Unixtime:     time.Now().UnixMilli(),
+	}
+
+	if info, err := host.Info(); err != nil {
+		heartbeatLog.Warnf("failed to get host info: %v", err)
+	} else {
+		inv.Platform = info.Platform
+		inv.PlatformFamily = info.PlatformFamily
+		inv.PlatformVersion = info.PlatformVersion
+		inv.KernelVersion = info.KernelVersion
+		inv.Virtualization = info.VirtualizationSystem
+		inv.HostID = info.HostID
+		inv.BootTime = info.BootTime
+	}
+
+	if infos, err := cpu.Info(); err != nil {
+		heartbeatLog.Warnf("failed to get cpu info: %v", err)
+	} else if len(infos) > 0 {
+		inv.CPUModel = infos[0].ModelName
+	}
+
+	if cores, err := cpu.Counts(false); err != nil {
+		heartbeatLog.Warnf("failed to get cpu cores: %v", err)
+	} else {
+		inv.CPUCores = cores
+	}
+
+	if vm, err := mem.VirtualMemory(); err != nil {
+		heartbeatLog.Warnf("failed to get memory: %v", err)
+	} else {
+		inv.MemTotal = vm.Total
+	}
+
+	if swap, err := mem.SwapMemory(); err == nil {
+		inv.SwapTotal = swap.Total
+	}
+
+	inv.Disks = collectDisks()
+	inv.IPs = collectIPs()
+	return inv
+}
+
+func collectDisks() []InventoryDisk {
+	partitions, err := disk.Partitions(false)
+	if err != nil {
+		heartbeatLog.Warnf("failed to get disk partitions: %v", err)
+		return nil
+	}
+
+	disks := make([]InventoryDisk, 0, len(partitions))
+	for _, p := range partitions {
+		d := InventoryDisk{Device: p.Device, Mountpoint: p.Mountpoint, Fstype: p.Fstype}
+		if usage, err := disk.Usage(p.Mountpoint); err == nil {
+			d.Total = usage.Total
+		}
+		disks = append(disks, d)
+	}
+	return disks
+}
+
+// collectIPs returns the addresses of interfaces up, except loopback and link local
+func collectIPs() []string {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		heartbeatLog.Warnf("failed to get interfaces: %v", err)
+		return nil
+	}
+
+	var ips []string
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+
+		for _, addr := range addrs {
+			ipnet, ok := addr.(*net.IPNet)
+			if !ok || ipnet.IP.IsLinkLocalUnicast() || ipnet.IP.IsLoopback() {
+				continue
+			}
+			ips = append(ips, ipnet.IP.String())
+		}
+	}
+
+	sort.Strings(ips)
+	return ips
+}
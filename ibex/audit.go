@@ -0,0 +1,78 @@
+//go:build !no_ibex
+
+package ibex
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/pkg/logger"
+)
+
+var ibexLog = logger.New("ibex")
+
+// auditRecord is one line of the audit log, in json format
+type auditRecord struct {
+	Time    string `json:"time"`
+	TaskId  int64  `json:"task_id"`
+	Clock   int64  `json:"clock"`
+	Event   string `json:"event"`
+	Account string `json:"account,omitempty"`
+	Args    string `json:"args,omitempty"`
+	Script  string `json:"script_sha256,omitempty"`
+	Status  string `json:"status,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+var auditLock sync.Mutex
+
+func auditLogFile() string {
+	if config.Config.Ibex.AuditLog != "" {
+		return config.Config.Ibex.AuditLog
+	}
+	return filepath.Join(config.Config.Ibex.MetaDir, "audit.log")
+}
+
+// audit appends a record of task t, script is hashed instead of written as is
+func audit(t *Task, event, script, status, reason string) {
+	rec := auditRecord{
+		Time:    time.Now().Format(time.RFC3339),
+		TaskId:  t.Id,
+		Clock:   t.Clock,
+		Event:   event,
+		Account: t.Account,
+		Args:    t.Args,
+		Status:  status,
+		Reason:  reason,
+	}
+	if script != "" {
+		sum := sha256.Sum256([]byte(script))
+		rec.Script = hex.EncodeToString(sum[:])
+	}
+
+	bs, err := json.Marshal(rec)
+	if err != nil {
+		ibexLog.Errorf("failed to marshal ibex audit record: %v", err)
+		return
+	}
+
+	auditLock.Lock()
+	defer auditLock.Unlock()
+
+	f, err := os.OpenFile(auditLogFile(), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		ibexLog.Errorf("failed to open ibex audit log: %v", err)
+		return
+	}
+	defer f.Close()
+
+	if _, err = f.Write(append(bs, '\n')); err != nil {
+		ibexLog.Errorf("failed to write ibex audit log: %v", err)
+	}
+}
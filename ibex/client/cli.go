@@ -6,7 +6,6 @@ import (
 	"bufio"
 	"fmt"
 	"io"
-	"log"
 	"math"
 	"net"
 	"net/rpc"
@@ -18,8 +17,11 @@ import (
 
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/ibex/types"
+	"flashcat.cloud/categraf/pkg/logger"
 )
 
+var clientLog = logger.New("ibex.client")
+
 var cli *gobrpc.RPCClient
 
 func getCli() *gobrpc.RPCClient {
@@ -43,7 +45,7 @@ func getCli() *gobrpc.RPCClient {
 		begin := time.Now()
 		conn, err := net.DialTimeout("tcp", addr, time.Second*5)
 		if err != nil {
-			log.Printf("W! dial %s fail: %s", addr, err)
+			clientLog.Warnf("dial %s fail: %s", addr, err)
 			continue
 		}
 
@@ -64,7 +66,7 @@ func getCli() *gobrpc.RPCClient {
 		var out string
 		err = c.Call("Server.Ping", "", &out)
 		if err != nil {
-			log.Printf("W! ping %s fail: %s", addr, err)
+			clientLog.Warnf("ping %s fail: %s", addr, err)
 			continue
 		}
 		use := time.Since(begin).Nanoseconds()
@@ -77,11 +79,11 @@ func getCli() *gobrpc.RPCClient {
 	}
 
 	if address == "" {
-		log.Println("E! no job server found")
+		clientLog.Errorf("no job server found")
 		return nil
 	}
 
-	log.Printf("I! choose server: %s, duration: %dms", address, duration/1000000)
+	clientLog.Infof("choose server: %s, duration: %dms", address, duration/1000000)
 
 	for addr, c := range acm {
 		if addr == address {
@@ -119,13 +121,13 @@ func Meta(id int64) (script string, args string, account string, err error) {
 	var resp types.TaskMetaResponse
 	err = GetCli().Call("Server.GetTaskMeta", id, &resp)
 	if err != nil {
-		log.Println("E! rpc call Server.GetTaskMeta:", err)
+		clientLog.Errorf("rpc call Server.GetTaskMeta: %v", err)
 		CloseCli()
 		return
 	}
 
 	if resp.Message != "" {
-		log.Println("E! rpc call Server.GetTaskMeta:", resp.Message)
+		clientLog.Errorf("rpc call Server.GetTaskMeta: %v", resp.Message)
 		err = fmt.Errorf(resp.Message)
 		return
 	}
@@ -4,7 +4,6 @@ package ibex
 
 import (
 	"context"
-	"log"
 	"os"
 	"os/signal"
 	"syscall"
@@ -16,7 +15,7 @@ import (
 )
 
 func heartbeatCron(ctx context.Context, ib *config.IbexConfig) {
-	log.Println("I! ibex agent start rolling request Server.Report.")
+	ibexLog.Infof("ibex agent start rolling request Server.Report.")
 	interval := time.Duration(ib.Interval)
 	for {
 		select {
@@ -39,13 +38,13 @@ func heartbeat() {
 	err := client.GetCli().Call("Server.Report", req, &resp)
 
 	if err != nil {
-		log.Println("E! rpc call Server.Report fail:", err)
+		ibexLog.Errorf("rpc call Server.Report fail: %v", err)
 		client.CloseCli()
 		return
 	}
 
 	if resp.Message != "" {
-		log.Println("E! error from server:", resp.Message)
+		ibexLog.Errorf("error from server: %v", resp.Message)
 		return
 	}
 
@@ -61,7 +60,7 @@ func heartbeat() {
 	}
 
 	if len(assigned) > 0 {
-		log.Println("I! assigned tasks:", mapKeys(assigned))
+		ibexLog.Infof("assigned tasks: %v", mapKeys(assigned))
 	}
 
 	Locals.Clean(assigned)
@@ -84,7 +83,7 @@ func Start() {
 EXIT:
 	for {
 		sig := <-sc
-		log.Println("I! ibex agent received signal:", sig.String())
+		ibexLog.Infof("ibex agent received signal: %v", sig.String())
 		switch sig {
 		case syscall.SIGQUIT, syscall.SIGTERM, syscall.SIGINT:
 			break EXIT
@@ -0,0 +1,128 @@
+//go:build !no_ibex
+
+package ibex
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"flashcat.cloud/categraf/config"
+)
+
+const defaultMaxOutputBytes = 65535
+
+var (
+	policyOnce    sync.Once
+	allowCommands []*regexp.Regexp
+	denyCommands  []*regexp.Regexp
+)
+
+func compilePolicy() {
+	ib := config.Config.Ibex
+	allowCommands = compileCommands(ib.AllowCommands)
+	denyCommands = compileCommands(ib.DenyCommands)
+}
+
+func compileCommands(exprs []string) []*regexp.Regexp {
+	ret := make([]*regexp.Regexp, 0, len(exprs))
+	for _, expr := range exprs {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			// ignore the broken pattern would loosen the policy, so match everything instead
+			ibexLog.Errorf("failed to compile ibex command pattern %s: %v", expr, err)
+			re = regexp.MustCompile(".*")
+		}
+		ret = append(ret, re)
+	}
+	return ret
+}
+
+// checkScript checks every line of the script (comments and blank lines are skipped) and the args:
+// a line matched by any of deny_commands is rejected, and if allow_commands is set,
+// each line must be matched by one of them. It's a guard rail instead of a sandbox,
+// since shell scripts can build commands dynamically.
+func checkScript(script, args string) error {
+	policyOnce.Do(compilePolicy)
+
+	if len(allowCommands) == 0 && len(denyCommands) == 0 {
+		return nil
+	}
+
+	for _, re := range denyCommands {
+		if args != "" && re.MatchString(args) {
+			return fmt.Errorf("args matches denied command pattern %s", re)
+		}
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(script))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(strings.ToLower(line), "rem ") {
+			continue
+		}
+
+		for _, re := range denyCommands {
+			if re.MatchString(line) {
+				return fmt.Errorf("line %d matches denied command pattern %s", n, re)
+			}
+		}
+
+		if len(allowCommands) == 0 {
+			continue
+		}
+
+		allowed := false
+		for _, re := range allowCommands {
+			if re.MatchString(line) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("line %d is not allowed by allow_commands", n)
+		}
+	}
+
+	return scanner.Err()
+}
+
+func maxOutputBytes() int {
+	if n := config.Config.Ibex.MaxOutputBytes; n > 0 {
+		return n
+	}
+	return defaultMaxOutputBytes
+}
+
+// outputWriter keeps the tail of the output, at most 2 * max bytes are held in memory
+type outputWriter struct {
+	t   *Task
+	buf *bytes.Buffer
+	max int
+}
+
+func (w *outputWriter) Write(p []byte) (int, error) {
+	w.t.Lock()
+	defer w.t.Unlock()
+
+	n, err := w.buf.Write(p)
+	if l := w.buf.Len(); l > 2*w.max {
+		w.buf.Next(l - w.max)
+	}
+	return n, err
+}
+
+// runningTasks counts the tasks with alive processes
+func (lt *LocalTasksT) runningTasks() int {
+	count := 0
+	for _, t := range lt.M {
+		if t.GetAlive() || t.GetStatus() == "running" {
+			count++
+		}
+	}
+	return count
+}
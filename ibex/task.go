@@ -5,7 +5,6 @@ package ibex
 import (
 	"bytes"
 	"fmt"
-	"log"
 	"os/exec"
 	"os/user"
 	"path/filepath"
@@ -100,15 +99,15 @@ func (t *Task) loadResult() {
 
 	t.Status, err = file.ReadStringTrim(doneFlag)
 	if err != nil {
-		log.Printf("E! read file %s fail %v", doneFlag, err)
+		ibexLog.Errorf("read file %s fail %v", doneFlag, err)
 	}
 	stdout, err := file.ReadString(stdoutFile)
 	if err != nil {
-		log.Printf("E! read file %s fail %v", stdoutFile, err)
+		ibexLog.Errorf("read file %s fail %v", stdoutFile, err)
 	}
 	stderr, err := file.ReadString(stderrFile)
 	if err != nil {
-		log.Printf("E! read file %s fail %v", stderrFile, err)
+		ibexLog.Errorf("read file %s fail %v", stderrFile, err)
 	}
 
 	t.Stdout = *bytes.NewBufferString(stdout)
@@ -124,7 +123,7 @@ func (t *Task) prepare() error {
 	IdDir := filepath.Join(config.Config.Ibex.MetaDir, fmt.Sprint(t.Id))
 	err := file.EnsureDir(IdDir)
 	if err != nil {
-		log.Printf("E! mkdir -p %s fail: %v", IdDir, err)
+		ibexLog.Errorf("mkdir -p %s fail: %v", IdDir, err)
 		return err
 	}
 
@@ -134,14 +133,14 @@ func (t *Task) prepare() error {
 		argsFile := filepath.Join(IdDir, "args")
 		args, err := file.ReadStringTrim(argsFile)
 		if err != nil {
-			log.Printf("E! read %s fail %v", argsFile, err)
+			ibexLog.Errorf("read %s fail %v", argsFile, err)
 			return err
 		}
 
 		accountFile := filepath.Join(IdDir, "account")
 		account, err := file.ReadStringTrim(accountFile)
 		if err != nil {
-			log.Printf("E! read %s fail %v", accountFile, err)
+			ibexLog.Errorf("read %s fail %v", accountFile, err)
 			return err
 		}
 
@@ -151,7 +150,7 @@ func (t *Task) prepare() error {
 		// 从远端读取，再写入磁盘
 		script, args, account, err := client.Meta(t.Id)
 		if err != nil {
-			log.Println("E! query task meta fail:", err)
+			ibexLog.Errorf("query task meta fail: %v", err)
 			return err
 		}
 
@@ -160,19 +159,19 @@ func (t *Task) prepare() error {
 			scriptFile := filepath.Join(IdDir, "script.bat")
 			_, err = file.WriteString(scriptFile, fmt.Sprintf("@echo off\r\n%s", script))
 			if err != nil {
-				log.Printf("E! write script to %s fail: %v", scriptFile, err)
+				ibexLog.Errorf("write script to %s fail: %v", scriptFile, err)
 				return err
 			}
 		default:
 			scriptFile := filepath.Join(IdDir, "script")
 			_, err = file.WriteString(scriptFile, script)
 			if err != nil {
-				log.Printf("E! write script to %s fail: %v", scriptFile, err)
+				ibexLog.Errorf("write script to %s fail: %v", scriptFile, err)
 				return err
 			}
 			out, err := sys.CmdOutTrim("chmod", "+x", scriptFile)
 			if err != nil {
-				log.Printf("E! chmod +x %s fail %v. output: %s", scriptFile, err, out)
+				ibexLog.Errorf("chmod +x %s fail %v. output: %s", scriptFile, err, out)
 				return err
 			}
 		}
@@ -180,20 +179,20 @@ func (t *Task) prepare() error {
 		argsFile := filepath.Join(IdDir, "args")
 		_, err = file.WriteString(argsFile, args)
 		if err != nil {
-			log.Printf("E! write args to %s fail: %v", argsFile, err)
+			ibexLog.Errorf("write args to %s fail: %v", argsFile, err)
 			return err
 		}
 
 		accountFile := filepath.Join(IdDir, "account")
 		_, err = file.WriteString(accountFile, account)
 		if err != nil {
-			log.Printf("E! write account to %s fail: %v", accountFile, err)
+			ibexLog.Errorf("write account to %s fail: %v", accountFile, err)
 			return err
 		}
 
 		_, err = file.WriteString(writeFlag, "")
 		if err != nil {
-			log.Printf("E! create %s flag file fail: %v", writeFlag, err)
+			ibexLog.Errorf("create %s flag file fail: %v", writeFlag, err)
 			return err
 		}
 
@@ -227,7 +226,24 @@ func (t *Task) start() {
 
 	scriptFile, err := filepath.Abs(filepath.Join(config.Config.Ibex.MetaDir, fmt.Sprint(t.Id), scriptFileType))
 	if err != nil {
-		log.Println("E! cannot get current absolute path:", err)
+		ibexLog.Errorf("cannot get current absolute path: %v", err)
+		return
+	}
+
+	script, err := file.ReadString(scriptFile)
+	if err != nil {
+		ibexLog.Errorf("read script %s fail: %v", scriptFile, err)
+		return
+	}
+
+	if err = checkScript(script, t.Args); err != nil {
+		ibexLog.Warnf("task[%d] rejected: %v", t.Id, err)
+		t.SetStatus("failed")
+		t.Lock()
+		t.Stderr.WriteString("rejected by categraf: " + err.Error())
+		t.Unlock()
+		audit(t, "reject", script, "failed", err.Error())
+		persistResult(t)
 		return
 	}
 
@@ -236,7 +252,7 @@ func (t *Task) start() {
 
 	loginUser, err := user.Current()
 	if err != nil {
-		log.Println("E! cannot get current login user:", err)
+		ibexLog.Errorf("cannot get current login user: %v", err)
 		return
 	}
 
@@ -259,15 +275,18 @@ func (t *Task) start() {
 		}
 	}
 
-	cmd.Stdout = &t.Stdout
-	cmd.Stderr = &t.Stderr
+	max := maxOutputBytes()
+	cmd.Stdout = &outputWriter{t: t, buf: &t.Stdout, max: max}
+	cmd.Stderr = &outputWriter{t: t, buf: &t.Stderr, max: max}
 	t.Cmd = cmd
 
 	err = CmdStart(cmd)
 	if err != nil {
-		log.Printf("E! cannot start cmd of task[%d]: %v", t.Id, err)
+		ibexLog.Errorf("cannot start cmd of task[%d]: %v", t.Id, err)
+		audit(t, "start", script, "failed", err.Error())
 		return
 	}
+	audit(t, "start", script, "running", "")
 
 	go runProcess(t)
 }
@@ -284,20 +303,21 @@ func runProcess(t *Task) {
 	if err != nil {
 		if strings.Contains(err.Error(), "signal: killed") {
 			t.SetStatus("killed")
-			log.Printf("D! process of task[%d] killed", t.Id)
+			ibexLog.Debugf("process of task[%d] killed", t.Id)
 		} else if strings.Contains(err.Error(), "signal: terminated") {
 			// kill children process manually
 			t.SetStatus("killed")
-			log.Printf("D! process of task[%d] terminated", t.Id)
+			ibexLog.Debugf("process of task[%d] terminated", t.Id)
 		} else {
 			t.SetStatus("failed")
-			log.Printf("D! process of task[%d] return error: %v", t.Id, err)
+			ibexLog.Debugf("process of task[%d] return error: %v", t.Id, err)
 		}
 	} else {
 		t.SetStatus("success")
-		log.Printf("D! process of task[%d] done", t.Id)
+		ibexLog.Debugf("process of task[%d] done", t.Id)
 	}
 
+	audit(t, "finish", "", t.GetStatus(), "")
 	persistResult(t)
 }
 
@@ -317,16 +337,17 @@ func killProcess(t *Task) {
 	t.SetAlive(true)
 	defer t.SetAlive(false)
 
-	log.Printf("D! begin kill process of task[%d]", t.Id)
+	ibexLog.Debugf("begin kill process of task[%d]", t.Id)
 
 	err := CmdKill(t.Cmd)
 	if err != nil {
 		t.SetStatus("killfailed")
-		log.Printf("D! kill process of task[%d] fail: %v", t.Id, err)
+		ibexLog.Debugf("kill process of task[%d] fail: %v", t.Id, err)
 	} else {
 		t.SetStatus("killed")
-		log.Printf("D! process of task[%d] killed", t.Id)
+		ibexLog.Debugf("process of task[%d] killed", t.Id)
 	}
 
+	audit(t, "kill", "", t.GetStatus(), "")
 	persistResult(t)
 }
@@ -3,8 +3,7 @@
 package ibex
 
 import (
-	"log"
-
+	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/ibex/types"
 )
 
@@ -30,15 +29,16 @@ func (lt *LocalTasksT) ReportTasks() []types.ReportTask {
 
 		stdoutLen := len(rt.Stdout)
 		stderrLen := len(rt.Stderr)
+		max := maxOutputBytes()
 
 		// 输出太长的话，截断，要不然把数据库撑爆了
-		if stdoutLen > 65535 {
-			start := stdoutLen - 65535
+		if stdoutLen > max {
+			start := stdoutLen - max
 			rt.Stdout = rt.Stdout[start:]
 		}
 
-		if stderrLen > 65535 {
-			start := stderrLen - 65535
+		if stderrLen > max {
+			start := stderrLen - max
 			rt.Stderr = rt.Stderr[start:]
 		}
 
@@ -59,11 +59,18 @@ func (lt *LocalTasksT) SetTask(t *Task) {
 
 func (lt *LocalTasksT) AssignTask(at types.AssignTask) {
 	local, found := lt.GetTask(at.Id)
+	if found && local.Clock == at.Clock && local.Action == at.Action {
+		// ignore repeat task
+		return
+	}
+
+	// 并发数达到上限，先不接收，等下次心跳服务端再次下发
+	if limit := config.Config.Ibex.MaxConcurrentTasks; at.Action == "start" && limit > 0 && lt.runningTasks() >= limit {
+		ibexLog.Warnf("task %d delayed, running tasks reach max_concurrent_tasks: %d", at.Id, limit)
+		return
+	}
+
 	if found {
-		if local.Clock == at.Clock && local.Action == at.Action {
-			// ignore repeat task
-			return
-		}
 
 		local.Clock = at.Clock
 		local.Action = at.Action
@@ -92,7 +99,7 @@ func (lt *LocalTasksT) AssignTask(at types.AssignTask) {
 		local.SetStatus("running")
 		local.start()
 	} else {
-		log.Printf("W! unknown action: %s of task %d", at.Action, at.Id)
+		ibexLog.Warnf("unknown action: %s of task %d", at.Action, at.Id)
 	}
 }
 
@@ -0,0 +1,335 @@
+package airflow
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/httpx"
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/pkg/tls"
+	"flashcat.cloud/categraf/types"
+)
+
+var airflowLog = logger.New("input.airflow")
+
+const (
+	inputName = "airflow"
+
+	apiPrefix         = "/api/v1"
+	healthPath        = "/health"
+	importErrorsPath  = "/importErrors"
+	dagRunsPath       = "/dags/~/dagRuns"
+	taskInstancesPath = "/dags/~/dagRuns/~/taskInstances"
+
+	statusHealthy = "healthy"
+	pageSize      = 100
+)
+
+type Airflow struct {
+	config.PluginConfig
+	Instances []*Instance `toml:"instances"`
+}
+
+type Instance struct {
+	config.InstanceConfig
+
+	// the webserver, e.g. http://airflow:8080, the basic_auth backend of the api is required
+	URL      string          `toml:"url"`
+	Username string          `toml:"username"`
+	Password string          `toml:"password"`
+	Timeout  config.Duration `toml:"timeout"`
+	tls.ClientConfig
+
+	client *http.Client
+
+	// the failures counted by the end date of dag runs and tasks, since the first gather
+	lastEnd        time.Time
+	dagRunFailures map[string]float64
+	taskFailures   map[taskKey]float64
+}
+
+type taskKey struct {
+	dagID  string
+	taskID string
+}
+
+type component struct {
+	Status string `json:"status"`
+}
+
+type taskInstance struct {
+	DagID      string `json:"dag_id"`
+	TaskID     string `json:"task_id"`
+	QueuedWhen string `json:"queued_when"`
+}
+
+var _ inputs.SampleGatherer = new(Instance)
+var _ inputs.Input = new(Airflow)
+var _ inputs.InstancesGetter = new(Airflow)
+
+func init() {
+	inputs.Add(inputName, func() inputs.Input {
+		return &Airflow{}
+	})
+}
+
+func (a *Airflow) Clone() inputs.Input {
+	return &Airflow{}
+}
+
+func (a *Airflow) Name() string {
+	return inputName
+}
+
+func (a *Airflow) GetInstances() []inputs.Instance {
+	ret := make([]inputs.Instance, len(a.Instances))
+	for i := 0; i < len(a.Instances); i++ {
+		ret[i] = a.Instances[i]
+	}
+	return ret
+}
+
+func (ins *Instance) Init() error {
+	if ins.URL == "" {
+		return types.ErrInstancesEmpty
+	}
+	if ins.Timeout <= 0 {
+		ins.Timeout = config.Duration(5 * time.Second)
+	}
+	ins.URL = strings.TrimRight(ins.URL, "/")
+	ins.dagRunFailures = make(map[string]float64)
+	ins.taskFailures = make(map[taskKey]float64)
+
+	tlsConfig, err := ins.ClientConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+	ins.client = &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		},
+		Timeout: time.Duration(ins.Timeout),
+	}
+	return nil
+}
+
+func (ins *Instance) Gather(slist *types.SampleList) {
+	// the health endpoint does not require credentials
+	var health struct {
+		Metadatabase component `json:"metadatabase"`
+		Scheduler    struct {
+			component
+			LatestHeartbeat string `json:"latest_scheduler_heartbeat"`
+		} `json:"scheduler"`
+		Triggerer    *component `json:"triggerer"`
+		DagProcessor *component `json:"dag_processor"`
+	}
+	if err := ins.get(healthPath, nil, &health); err != nil {
+		airflowLog.Errorf("failed to get health of airflow: %v error: %v", ins.URL, err)
+		slist.PushSample(inputName, "up", 0)
+		return
+	}
+	slist.PushSample(inputName, "up", 1)
+	slist.PushSample(inputName, "metadatabase_healthy", health.Metadatabase.Status == statusHealthy)
+	slist.PushSample(inputName, "scheduler_healthy", health.Scheduler.Status == statusHealthy)
+	if heartbeat, err := time.Parse(time.RFC3339, health.Scheduler.LatestHeartbeat); err == nil {
+		slist.PushSample(inputName, "scheduler_heartbeat_age_seconds", time.Since(heartbeat).Seconds())
+	}
+	// the status is null if the component is not deployed
+	if health.Triggerer != nil && health.Triggerer.Status != "" {
+		slist.PushSample(inputName, "triggerer_healthy", health.Triggerer.Status == statusHealthy)
+	}
+	if health.DagProcessor != nil && health.DagProcessor.Status != "" {
+		slist.PushSample(inputName, "dag_processor_healthy", health.DagProcessor.Status == statusHealthy)
+	}
+
+	if total, err := ins.count(importErrorsPath, nil); err != nil {
+		airflowLog.Errorf("failed to count import errors of airflow: %v error: %v", ins.URL, err)
+	} else {
+		slist.PushSample(inputName, "import_errors", total)
+	}
+
+	for _, state := range []string{"queued", "running"} {
+		total, err := ins.count(dagRunsPath, url.Values{"state": {state}})
+		if err != nil {
+			airflowLog.Errorf("failed to count dag runs of airflow: %v error: %v", ins.URL, err)
+			continue
+		}
+		slist.PushSample(inputName, "dag_runs", total, map[string]string{"state": state})
+	}
+
+	ins.gatherQueuedTasks(slist)
+	ins.gatherFailures(slist)
+}
+
+// gatherQueuedTasks reports the age of the oldest queued task, the tasks are stuck in queued
+// if no worker picks them, e.g. the pool or the queue of celery is full
+func (ins *Instance) gatherQueuedTasks(slist *types.SampleList) {
+	var tasks []taskInstance
+	err := ins.list(taskInstancesPath, url.Values{"state": {"queued"}}, func(raw json.RawMessage) (int, error) {
+		var page struct {
+			TaskInstances []taskInstance `json:"task_instances"`
+		}
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return 0, err
+		}
+		tasks = append(tasks, page.TaskInstances...)
+		return len(page.TaskInstances), nil
+	})
+	if err != nil {
+		airflowLog.Errorf("failed to list queued tasks of airflow: %v error: %v", ins.URL, err)
+		return
+	}
+
+	var oldest float64
+	for _, t := range tasks {
+		queued, err := time.Parse(time.RFC3339, t.QueuedWhen)
+		if err != nil {
+			continue
+		}
+		if age := time.Since(queued).Seconds(); age > oldest {
+			oldest = age
+		}
+	}
+	slist.PushSample(inputName, "queued_tasks", len(tasks))
+	slist.PushSample(inputName, "queued_task_oldest_age_seconds", oldest)
+}
+
+// gatherFailures counts the dag runs and tasks failed since the last gather by the end date,
+// the counters start at the first gather
+func (ins *Instance) gatherFailures(slist *types.SampleList) {
+	now := time.Now().UTC()
+	if ins.lastEnd.IsZero() {
+		ins.lastEnd = now
+	}
+
+	window := url.Values{
+		"state":        {"failed"},
+		"end_date_gte": {ins.lastEnd.Format(time.RFC3339Nano)},
+		"end_date_lte": {now.Format(time.RFC3339Nano)},
+	}
+
+	dagRuns := make(map[string]float64)
+	err := ins.list(dagRunsPath, window, func(raw json.RawMessage) (int, error) {
+		var page struct {
+			DagRuns []struct {
+				DagID string `json:"dag_id"`
+			} `json:"dag_runs"`
+		}
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return 0, err
+		}
+		for _, r := range page.DagRuns {
+			dagRuns[r.DagID]++
+		}
+		return len(page.DagRuns), nil
+	})
+	if err != nil {
+		airflowLog.Errorf("failed to list failed dag runs of airflow: %v error: %v", ins.URL, err)
+		return
+	}
+
+	tasks := make(map[taskKey]float64)
+	err = ins.list(taskInstancesPath, window, func(raw json.RawMessage) (int, error) {
+		var page struct {
+			TaskInstances []taskInstance `json:"task_instances"`
+		}
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return 0, err
+		}
+		for _, t := range page.TaskInstances {
+			tasks[taskKey{dagID: t.DagID, taskID: t.TaskID}]++
+		}
+		return len(page.TaskInstances), nil
+	})
+	if err != nil {
+		airflowLog.Errorf("failed to list failed tasks of airflow: %v error: %v", ins.URL, err)
+		return
+	}
+
+	// the end date is inclusive on both sides, the next window starts after now
+	ins.lastEnd = now.Add(time.Microsecond)
+	for dagID, n := range dagRuns {
+		ins.dagRunFailures[dagID] += n
+	}
+	for key, n := range tasks {
+		ins.taskFailures[key] += n
+	}
+
+	for dagID, n := range ins.dagRunFailures {
+		slist.PushSample(inputName, "dag_run_failures_total", n, map[string]string{"dag_id": dagID})
+	}
+	for key, n := range ins.taskFailures {
+		slist.PushSample(inputName, "task_failures_total", n, map[string]string{"dag_id": key.dagID, "task_id": key.taskID})
+	}
+}
+
+// count returns total_entries of the collection
+func (ins *Instance) count(path string, query url.Values) (float64, error) {
+	q := url.Values{"limit": {"1"}}
+	for k, v := range query {
+		q[k] = v
+	}
+
+	var page struct {
+		TotalEntries float64 `json:"total_entries"`
+	}
+	err := ins.get(path, q, &page)
+	return page.TotalEntries, err
+}
+
+// list pages through the collection, add returns the number of the items of the page
+func (ins *Instance) list(path string, query url.Values, add func(json.RawMessage) (int, error)) error {
+	for offset := 0; ; {
+		q := url.Values{"limit": {strconv.Itoa(pageSize)}, "offset": {strconv.Itoa(offset)}}
+		for k, v := range query {
+			q[k] = v
+		}
+
+		var raw json.RawMessage
+		if err := ins.get(path, q, &raw); err != nil {
+			return err
+		}
+		n, err := add(raw)
+		if err != nil {
+			return err
+		}
+
+		var page struct {
+			TotalEntries int `json:"total_entries"`
+		}
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return err
+		}
+		offset += n
+		if n == 0 || offset >= page.TotalEntries {
+			return nil
+		}
+	}
+}
+
+func (ins *Instance) get(path string, query url.Values, v interface{}) error {
+	u := ins.URL + apiPrefix + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	if ins.Username != "" || ins.Password != "" {
+		req.SetBasicAuth(ins.Username, ins.Password)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	_, err = httpx.DoJSON(ins.client, req, path, v)
+	return err
+}
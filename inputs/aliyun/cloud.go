@@ -3,7 +3,6 @@ package aliyun
 import (
 	"context"
 	"fmt"
-	"log"
 	"sync"
 	"time"
 
@@ -16,10 +15,13 @@ import (
 	internalTypes "flashcat.cloud/categraf/inputs/aliyun/internal/types"
 	"flashcat.cloud/categraf/pkg/cache"
 	"flashcat.cloud/categraf/pkg/limiter"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/pkg/stringx"
 	"flashcat.cloud/categraf/types"
 )
 
+var aliyunLog = logger.New("input.aliyun")
+
 const (
 	inputName = "aliyun"
 	timefmt   = "2006-01-02 15:04:05"
@@ -71,6 +73,12 @@ type (
 		AccessKeySecret *string `toml:"access_key_secret"`
 		Region          *string `toml:"region"`
 		Endpoint        *string `toml:"endpoint"`
+
+		// RAM credentials
+		SecurityToken   *string `toml:"security_token"`
+		RoleArn         *string `toml:"role_arn"`
+		RoleSessionName *string `toml:"role_session_name"`
+		EcsRamRole      *string `toml:"ecs_ram_role"`
 	}
 
 	MetricFilter struct {
@@ -110,12 +118,13 @@ var _ inputs.InstancesGetter = new(Aliyun)
 
 func (ins *Instance) Init() error {
 	if ins == nil ||
-		ins.AccessKeySecret == nil ||
-		ins.AccessKeyID == nil ||
 		ins.Region == nil ||
 		ins.Endpoint == nil {
 		return types.ErrInstancesEmpty
 	}
+	if (ins.AccessKeySecret == nil || ins.AccessKeyID == nil) && ins.EcsRamRole == nil {
+		return types.ErrInstancesEmpty
+	}
 	if ins.BatchSize == 0 {
 		ins.BatchSize = 500
 	}
@@ -150,12 +159,6 @@ func (s *Aliyun) GetInstances() []inputs.Instance {
 }
 
 func (ins *Instance) initialize() error {
-	if len(*ins.AccessKeyID) == 0 {
-		return fmt.Errorf("%s", "access_key_id is required")
-	}
-	if len(*ins.AccessKeySecret) == 0 {
-		return fmt.Errorf("%s", "E! access_key_secret is required")
-	}
 	if len(*ins.Region) == 0 {
 		return fmt.Errorf("%s", "region is required")
 	}
@@ -164,7 +167,10 @@ func (ins *Instance) initialize() error {
 	}
 
 	if ins.client == nil {
-		cms := manager.NewCmsClient(*ins.AccessKeyID, *ins.AccessKeySecret, *ins.Region, *ins.Endpoint)
+		cms, err := ins.cmsOption()
+		if err != nil {
+			return err
+		}
 		m, err := manager.New(cms)
 		if err != nil {
 			return fmt.Errorf("connect to aliyun error, %s", err)
@@ -175,7 +181,7 @@ func (ins *Instance) initialize() error {
 	if ins.metaCache.Size() == 0 {
 		hosts, err := ins.client.GetEcsHosts()
 		if err != nil {
-			log.Println(err)
+			aliyunLog.Infof("%v", err)
 			return err
 		}
 		for _, host := range hosts {
@@ -240,7 +246,7 @@ func (ins *Instance) getFilteredMetrics() ([]filteredMetric, error) {
 
 	if config.Config.DebugMode {
 		for _, m := range metrics {
-			log.Println("D!", m.Namespace, m.MetricName, m.Dimensions)
+			aliyunLog.Debugf("%v %v %v", m.Namespace, m.MetricName, m.Dimensions)
 		}
 	}
 
@@ -271,7 +277,7 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 	} else {
 		filteredMetrics, err := ins.getFilteredMetrics()
 		if err != nil {
-			log.Println("E!", err)
+			aliyunLog.Errorf("%v", err)
 			return
 		}
 		for _, filtered := range filteredMetrics {
@@ -307,7 +313,7 @@ func (ins *Instance) sendMetrics(metric internalTypes.Metric, wg *sync.WaitGroup
 	}
 	points, err := ins.client.GetMetric(ctx, req)
 	if err != nil {
-		log.Println("E! get metrics error,", err)
+		aliyunLog.Errorf("get metrics error, %v", err)
 		return
 	}
 	for _, point := range points {
@@ -383,7 +389,7 @@ func (ins *Instance) fetchNamespaceMetrics(namespaces []string) ([]internalTypes
 		}
 		resp, err := ins.client.ListMetrics(context.Background(), params)
 		if err != nil {
-			log.Printf("E! failed to list metrics with namespace %s: %v", namespace, err)
+			aliyunLog.Errorf("failed to list metrics with namespace %s: %v", namespace, err)
 			// skip problem namespace on error and continue to next namespace
 			return nil, err
 		}
@@ -0,0 +1,62 @@
+package aliyun
+
+import (
+	"fmt"
+
+	"github.com/alibabacloud-go/tea/tea"
+	credential "github.com/aliyun/credentials-go/credentials"
+
+	"flashcat.cloud/categraf/inputs/aliyun/internal/manager"
+)
+
+// cmsOption chooses the credential type by the configured fields, the order is:
+// 1) ecs_ram_role, credentials are fetched from the ecs metadata service
+// 2) role_arn, assume role with access key via sts
+// 3) security_token, sts token with access key
+// 4) access key
+func (c *Credential) cmsOption() (manager.Option, error) {
+	notEmpty := func(s *string) bool {
+		return s != nil && len(*s) != 0
+	}
+
+	cfg := new(credential.Config)
+	switch {
+	case notEmpty(c.EcsRamRole):
+		cfg.SetType("ecs_ram_role").SetRoleName(*c.EcsRamRole)
+	case notEmpty(c.RoleArn):
+		if !notEmpty(c.AccessKeyID) || !notEmpty(c.AccessKeySecret) {
+			return nil, fmt.Errorf("%s", "access_key_id and access_key_secret are required for role_arn")
+		}
+		sessionName := "categraf"
+		if notEmpty(c.RoleSessionName) {
+			sessionName = *c.RoleSessionName
+		}
+		cfg.SetType("ram_role_arn").
+			SetAccessKeyId(*c.AccessKeyID).
+			SetAccessKeySecret(*c.AccessKeySecret).
+			SetRoleArn(*c.RoleArn).
+			SetRoleSessionName(sessionName)
+	case notEmpty(c.SecurityToken):
+		if !notEmpty(c.AccessKeyID) || !notEmpty(c.AccessKeySecret) {
+			return nil, fmt.Errorf("%s", "access_key_id and access_key_secret are required for security_token")
+		}
+		cfg.SetType("sts").
+			SetAccessKeyId(*c.AccessKeyID).
+			SetAccessKeySecret(*c.AccessKeySecret).
+			SetSecurityToken(*c.SecurityToken)
+	default:
+		if !notEmpty(c.AccessKeyID) {
+			return nil, fmt.Errorf("%s", "access_key_id is required")
+		}
+		if !notEmpty(c.AccessKeySecret) {
+			return nil, fmt.Errorf("%s", "access_key_secret is required")
+		}
+		return manager.NewCmsClient(*c.AccessKeyID, *c.AccessKeySecret, *c.Region, *c.Endpoint), nil
+	}
+
+	cred, err := credential.NewCredential(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s credential: %v", tea.StringValue(cfg.Type), err)
+	}
+	return manager.NewCmsClientWithCredential(cred, *c.Region, *c.Endpoint), nil
+}
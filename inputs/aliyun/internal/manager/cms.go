@@ -4,18 +4,21 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"strconv"
 
 	cms20190101 "github.com/alibabacloud-go/cms-20190101/v8/client"
 	cms2021101 "github.com/alibabacloud-go/cms-export-20211101/v2/client"
 	openapi "github.com/alibabacloud-go/darabonba-openapi/v2/client"
 	"github.com/alibabacloud-go/tea/tea"
+	credential "github.com/aliyun/credentials-go/credentials"
 
 	"flashcat.cloud/categraf/inputs/aliyun/internal/types"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/pkg/stringx"
 )
 
+var managerLog = logger.New("input.aliyun.internal.manager")
+
 const (
 	DefaultPageNum  = 1
 	DefaultPageSize = 30
@@ -132,7 +135,7 @@ func (m *Manager) GetMetric(ctx context.Context, req *cms20190101.DescribeMetric
 		req.NextToken = resp.Body.NextToken
 		resp, err = m.cms.DescribeMetricList(req)
 		if err != nil {
-			log.Println(err)
+			managerLog.Infof("%v", err)
 			continue
 		}
 		points, err := m.dataPointConverter(*req.MetricName, *req.Namespace, *resp.Body.Datapoints)
@@ -211,6 +214,34 @@ func NewCmsClient(key, secret, region, endpoint string) Option {
 	}
 }
 
+// NewCmsClientWithCredential creates cms client with a RAM credential, e.g. sts token, ram role arn or ecs ram role
+func NewCmsClientWithCredential(cred credential.Credential, region, endpoint string) Option {
+	if len(region) == 0 {
+		panic("region for cms is required")
+	}
+	if len(endpoint) == 0 {
+		panic("endpoint for cms is required")
+	}
+	return func(m *Manager) error {
+		m.cms = &cmsClient{
+			region:   region,
+			endpoint: endpoint,
+		}
+		config := &openapi.Config{
+			Credential: cred,
+			RegionId:   &m.cms.region,
+			Endpoint:   &m.cms.endpoint,
+		}
+
+		cms, err := cms20190101.NewClient(config)
+		if err != nil {
+			return err
+		}
+		m.cms.Client = cms
+		return nil
+	}
+}
+
 func NewCmsV2Client(key, secret, region, endpoint string) Option {
 	if len(key) == 0 {
 		panic("accessKey for cms batch exporter is required")
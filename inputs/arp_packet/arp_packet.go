@@ -6,19 +6,21 @@ package arp_packet
 import (
 	"errors"
 	"fmt"
-	"log"
 	"net"
 	"sync"
 	"time"
 
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/types"
 	"github.com/google/gopacket"
 	"github.com/google/gopacket/layers"
 	"github.com/google/gopacket/pcap"
 )
 
+var arpPacketLog = logger.New("input.arp_packet")
+
 const inputName = "arp_packet"
 
 type ArpPacket struct {
@@ -92,7 +94,7 @@ func (ins *Instance) Init() error {
 	var err error
 	ins.LocalIP, err = ins.GetInterfaceIpv4Addr(ins.Ethdevice)
 	if err != nil {
-		log.Println("E!", err)
+		arpPacketLog.Errorf("%v", err)
 		return types.ErrInstancesEmpty
 	}
 	ins.snapshot_len = 1024
@@ -101,11 +103,11 @@ func (ins *Instance) Init() error {
 	// Open device
 	ins.EthHandle, err = pcap.OpenLive(ins.Ethdevice, ins.snapshot_len, ins.promiscuous, ins.timeout)
 	if err != nil {
-		log.Println("E!", err)
+		arpPacketLog.Errorf("%v", err)
 		return types.ErrInstancesEmpty
 	}
 	go ins.arpStat()
-	log.Println("I! start arp stat")
+	arpPacketLog.Infof("start arp stat")
 	return nil
 }
 func (ins *Instance) Gather(slist *types.SampleList) {
@@ -138,8 +140,8 @@ func (ins *Instance) arpStat() {
 				sourceAddr := sip.String()
 				dip = arp.DstProtAddress
 				if sourceAddr == ins.LocalIP {
-					log.Println("I! ARPResp: SourceProtAddress:", sourceAddr, " mac:", macs)
-					log.Println("I! ARPResp: DstProtAddress:", dip.String(), " mac:", macd)
+					arpPacketLog.Infof("ARPResp: SourceProtAddress: %v  mac: %v", sourceAddr, macs)
+					arpPacketLog.Infof("ARPResp: DstProtAddress: %v  mac: %v", dip.String(), macd)
 					ins.mutex.Lock()
 					ins.resARP++
 					ins.mutex.Unlock()
@@ -154,8 +156,8 @@ func (ins *Instance) arpStat() {
 				sourceAddr := sip.String()
 				dip = arp.DstProtAddress
 				if sourceAddr == ins.LocalIP {
-					log.Println("I! ARPReq: SourceProtAddress:", sourceAddr, " mac:", macs)
-					log.Println("I! ARPReq: DstProtAddress:", dip.String(), " mac:", macd)
+					arpPacketLog.Infof("ARPReq: SourceProtAddress: %v  mac: %v", sourceAddr, macs)
+					arpPacketLog.Infof("ARPReq: DstProtAddress: %v  mac: %v", dip.String(), macd)
 					ins.mutex.Lock()
 					ins.reqARP++
 					ins.mutex.Unlock()
@@ -0,0 +1,169 @@
+package bgp
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/cmdx"
+	"flashcat.cloud/categraf/types"
+)
+
+const inputName = "bgp"
+
+const (
+	SourceFRR  = "frr"
+	SourceBIRD = "bird"
+	SourceSNMP = "snmp"
+)
+
+// the states of the finite state machine of BGP, the values of bgpPeerState of BGP4-MIB
+var peerStates = map[string]int{
+	"idle":        1,
+	"connect":     2,
+	"active":      3,
+	"opensent":    4,
+	"openconfirm": 5,
+	"established": 6,
+}
+
+type Instance struct {
+	config.InstanceConfig
+
+	// frr (default) | bird | snmp
+	Source  string          `toml:"source"`
+	Timeout config.Duration `toml:"timeout"`
+
+	// frr: the command of vtysh, e.g. "sudo vtysh", show bgp vrf all summary json is appended
+	VtyshCommand string `toml:"vtysh_command"`
+	// bird: the command of birdc, e.g. "birdc -s /run/bird/bird.ctl", -r show protocols all is appended
+	BirdcCommand string `toml:"birdc_command"`
+
+	// snmp: the devices of BGP4-MIB, host or host:port
+	Agents    []string `toml:"agents"`
+	Community string   `toml:"community"`
+	// 1 or 2 (default), i.e. 2c
+	Version uint8 `toml:"version"`
+	Retries int   `toml:"retries"`
+	// snmp: count the rows of bgp4PathAttrTable by peer as the prefixes received,
+	// the table has a row per path, which is walked on every gather
+	SNMPPrefixes bool `toml:"snmp_prefixes"`
+}
+
+type BGP struct {
+	config.PluginConfig
+	Instances []*Instance `toml:"instances"`
+}
+
+func init() {
+	inputs.Add(inputName, func() inputs.Input {
+		return &BGP{}
+	})
+}
+
+func (b *BGP) Clone() inputs.Input {
+	return &BGP{}
+}
+
+func (b *BGP) Name() string {
+	return inputName
+}
+
+func (b *BGP) GetInstances() []inputs.Instance {
+	ret := make([]inputs.Instance, len(b.Instances))
+	for i := 0; i < len(b.Instances); i++ {
+		ret[i] = b.Instances[i]
+	}
+	return ret
+}
+
+func (ins *Instance) Init() error {
+	if ins.Timeout <= 0 {
+		ins.Timeout = config.Duration(5 * time.Second)
+	}
+
+	switch ins.Source {
+	case "", SourceFRR:
+		if ins.VtyshCommand == "" {
+			return types.ErrInstancesEmpty
+		}
+		ins.Source = SourceFRR
+	case SourceBIRD:
+		if ins.BirdcCommand == "" {
+			return types.ErrInstancesEmpty
+		}
+	case SourceSNMP:
+		if len(ins.Agents) == 0 {
+			return types.ErrInstancesEmpty
+		}
+		if ins.Community == "" {
+			ins.Community = "public"
+		}
+		switch ins.Version {
+		case 0:
+			ins.Version = 2
+		case 1, 2:
+		default:
+			return fmt.Errorf("unsupported snmp version %d, must be 1 or 2", ins.Version)
+		}
+	default:
+		return fmt.Errorf("unknown source %q, must be one of frr, bird and snmp", ins.Source)
+	}
+	return nil
+}
+
+func (ins *Instance) Gather(slist *types.SampleList) {
+	switch ins.Source {
+	case SourceFRR:
+		ins.gatherFRR(slist)
+	case SourceBIRD:
+		ins.gatherBIRD(slist)
+	case SourceSNMP:
+		ins.gatherSNMP(slist)
+	}
+}
+
+// run runs command with args appended, the command may have arguments of its own, e.g. sudo vtysh
+func (ins *Instance) run(command string, args ...string) ([]byte, error) {
+	cmdAndArgs := append(strings.Fields(command), args...)
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(cmdAndArgs[0], cmdAndArgs[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err, timeout := cmdx.RunTimeout(cmd, time.Duration(ins.Timeout))
+	if timeout {
+		return nil, fmt.Errorf("run command: %s timeout", strings.Join(cmdAndArgs, " "))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to run command: %s | error: %v | stderr: %s", strings.Join(cmdAndArgs, " "), err, stderr.String())
+	}
+	return stdout.Bytes(), nil
+}
+
+// peerState returns the value of bgpPeerState of state, e.g. 1 of "Idle (Admin)" of frr, 0 if unknown
+func peerState(state string) int {
+	state = strings.ToLower(state)
+	if i := strings.IndexAny(state, " ("); i > 0 {
+		state = state[:i]
+	}
+	return peerStates[state]
+}
+
+// pushPeer pushes the state of a peer, and its uptime if established and known
+func pushPeer(slist *types.SampleList, state int, uptime time.Duration, tags map[string]string) {
+	up := 0
+	if state == peerStates["established"] {
+		up = 1
+		if uptime > 0 {
+			slist.PushSample(inputName, "peer_uptime_seconds", uptime.Seconds(), tags)
+		}
+	}
+	slist.PushSample(inputName, "peer_up", up, tags)
+	slist.PushSample(inputName, "peer_state", state, tags)
+}
@@ -0,0 +1,148 @@
+package bgp
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"strings"
+
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/types"
+)
+
+var bgpLog = logger.New("input.bgp")
+
+// birdProtocol is a BGP protocol in show protocols all of bird
+type birdProtocol struct {
+	name   string
+	state  string
+	tags   map[string]string
+	routes []birdRoutes
+}
+
+// birdRoutes is the line of routes of a channel, e.g. "Routes: 5 imported, 1 filtered, 3 exported, 5 preferred",
+// the channel is empty for bird 1.x, which has no channels
+type birdRoutes struct {
+	channel string
+	counts  map[string]float64
+}
+
+func (ins *Instance) gatherBIRD(slist *types.SampleList) {
+	out, err := ins.run(ins.BirdcCommand, "-r", "show", "protocols", "all")
+	if err != nil {
+		slist.PushSample(inputName, "up", 0)
+		bgpLog.Errorf("failed to gather bgp of bird: %v", err)
+		return
+	}
+	slist.PushSample(inputName, "up", 1)
+
+	for _, p := range parseBIRD(out) {
+		tags := map[string]string{"protocol": p.name}
+		for k, v := range p.tags {
+			tags[k] = v
+		}
+		// bird tells the time of the last change of state only, in the timeformat configured
+		pushPeer(slist, peerState(p.state), 0, tags)
+
+		for _, r := range p.routes {
+			routeTags := tags
+			if r.channel != "" {
+				routeTags = map[string]string{"channel": r.channel}
+				for k, v := range tags {
+					routeTags[k] = v
+				}
+			}
+			for name, metric := range map[string]string{
+				"imported": "peer_prefixes_received",
+				"exported": "peer_prefixes_sent",
+				"filtered": "peer_prefixes_filtered",
+			} {
+				if v, has := r.counts[name]; has {
+					slist.PushSample(inputName, metric, v, routeTags)
+				}
+			}
+		}
+	}
+}
+
+// parseBIRD parses the BGP protocols, of which the header lines are not indented, e.g.
+//
+//	bgp1       BGP        ---        up     2023-01-01    Established
+//	  BGP state:          Established
+//	    Neighbor address: 10.0.0.2
+//	    Neighbor AS:      65001
+//	    Local AS:         65000
+//	  Channel ipv4
+//	    Routes:         5 imported, 3 exported, 5 preferred
+func parseBIRD(out []byte) []*birdProtocol {
+	var (
+		ret     []*birdProtocol
+		current *birdProtocol
+		channel string
+	)
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		if line[0] != ' ' && line[0] != '\t' {
+			current = nil
+			channel = ""
+			fields := strings.Fields(line)
+			// the other protocols, the banner and the header of the table are skipped
+			if len(fields) >= 4 && fields[1] == "BGP" {
+				current = &birdProtocol{name: fields[0], state: "idle", tags: map[string]string{}}
+				ret = append(ret, current)
+			}
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "Channel ") {
+			channel = strings.TrimSpace(strings.TrimPrefix(line, "Channel "))
+			continue
+		}
+
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch key {
+		case "BGP state":
+			current.state = value
+		case "Neighbor address":
+			current.tags["peer"] = value
+		case "Neighbor AS":
+			current.tags["remote_as"] = value
+		case "Local AS":
+			current.tags["local_as"] = value
+		case "Description":
+			current.tags["description"] = value
+		case "Routes":
+			current.routes = append(current.routes, birdRoutes{channel: channel, counts: parseBIRDRoutes(value)})
+		}
+	}
+	return ret
+}
+
+// parseBIRDRoutes parses "5 imported, 1 filtered, 3 exported, 5 preferred"
+func parseBIRDRoutes(s string) map[string]float64 {
+	counts := map[string]float64{}
+	for _, part := range strings.Split(s, ",") {
+		fields := strings.Fields(part)
+		if len(fields) != 2 {
+			continue
+		}
+		if v, err := strconv.ParseFloat(fields[0], 64); err == nil {
+			counts[fields[1]] = v
+		}
+	}
+	return counts
+}
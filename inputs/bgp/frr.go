@@ -0,0 +1,98 @@
+package bgp
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"flashcat.cloud/categraf/types"
+)
+
+// the summary of an address family of a vrf in show bgp vrf all summary json of frr
+type frrSummary struct {
+	AS    json.RawMessage    `json:"as"`
+	Peers map[string]frrPeer `json:"peers"`
+}
+
+type frrPeer struct {
+	// a number, or a string of the 4-byte AS in asdot
+	RemoteAs json.RawMessage `json:"remoteAs"`
+	Desc     string          `json:"desc"`
+	State    string          `json:"state"`
+	// the prefixes are absent unless established
+	PfxRcd                 *float64 `json:"pfxRcd"`
+	PfxSnt                 *float64 `json:"pfxSnt"`
+	MsgRcvd                float64  `json:"msgRcvd"`
+	MsgSent                float64  `json:"msgSent"`
+	PeerUptimeMsec         int64    `json:"peerUptimeMsec"`
+	ConnectionsEstablished float64  `json:"connectionsEstablished"`
+	ConnectionsDropped     float64  `json:"connectionsDropped"`
+}
+
+func (ins *Instance) gatherFRR(slist *types.SampleList) {
+	out, err := ins.run(ins.VtyshCommand, "-c", "show bgp vrf all summary json")
+	if err != nil {
+		slist.PushSample(inputName, "up", 0)
+		bgpLog.Errorf("failed to gather bgp of frr: %v", err)
+		return
+	}
+
+	if err := parseFRR(out, slist); err != nil {
+		slist.PushSample(inputName, "up", 0)
+		bgpLog.Errorf("failed to parse bgp summary of frr: %v", err)
+		return
+	}
+	slist.PushSample(inputName, "up", 1)
+}
+
+// parseFRR parses the summaries by vrf and address family, e.g. {"default": {"ipv4Unicast": {...}}}
+func parseFRR(out []byte, slist *types.SampleList) error {
+	var vrfs map[string]map[string]json.RawMessage
+	if err := json.Unmarshal(out, &vrfs); err != nil {
+		return err
+	}
+
+	for vrf, afis := range vrfs {
+		for afi, raw := range afis {
+			var summary frrSummary
+			if err := json.Unmarshal(raw, &summary); err != nil || summary.Peers == nil {
+				// not an address family, e.g. the vrf id
+				continue
+			}
+			for addr, p := range summary.Peers {
+				tags := map[string]string{
+					"vrf":       vrf,
+					"afi":       afi,
+					"peer":      addr,
+					"remote_as": asLabel(p.RemoteAs),
+					"local_as":  asLabel(summary.AS),
+				}
+				if p.Desc != "" {
+					tags["description"] = p.Desc
+				}
+
+				pushPeer(slist, peerState(p.State), time.Duration(p.PeerUptimeMsec)*time.Millisecond, tags)
+				adminEnabled := 1
+				if strings.Contains(p.State, "Admin") {
+					adminEnabled = 0
+				}
+				slist.PushSample(inputName, "peer_admin_enabled", adminEnabled, tags)
+				if p.PfxRcd != nil {
+					slist.PushSample(inputName, "peer_prefixes_received", *p.PfxRcd, tags)
+				}
+				if p.PfxSnt != nil {
+					slist.PushSample(inputName, "peer_prefixes_sent", *p.PfxSnt, tags)
+				}
+				slist.PushSample(inputName, "peer_messages_received", p.MsgRcvd, tags)
+				slist.PushSample(inputName, "peer_messages_sent", p.MsgSent, tags)
+				slist.PushSample(inputName, "peer_connections_established", p.ConnectionsEstablished, tags)
+				slist.PushSample(inputName, "peer_connections_dropped", p.ConnectionsDropped, tags)
+			}
+		}
+	}
+	return nil
+}
+
+func asLabel(raw json.RawMessage) string {
+	return strings.Trim(string(raw), `"`)
+}
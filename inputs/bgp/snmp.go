@@ -0,0 +1,181 @@
+package bgp
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+
+	"flashcat.cloud/categraf/types"
+)
+
+// bgpPeerTable of BGP4-MIB, indexed by the remote addresses of peers
+const bgpPeerTableOid = ".1.3.6.1.2.1.15.3.1"
+
+// bgp4PathAttrPeer of BGP4-MIB, the column of bgp4PathAttrTable of the peers which the paths are received from,
+// one row per path of IPv4
+const bgp4PathAttrPeerOid = ".1.3.6.1.2.1.15.6.1.1"
+
+// the columns of bgpPeerTable
+const (
+	bgpPeerState                     = 2
+	bgpPeerAdminStatus               = 3
+	bgpPeerRemoteAs                  = 9
+	bgpPeerInUpdates                 = 10
+	bgpPeerOutUpdates                = 11
+	bgpPeerFsmEstablishedTransitions = 15
+	bgpPeerFsmEstablishedTime        = 16
+)
+
+func (ins *Instance) gatherSNMP(slist *types.SampleList) {
+	wg := new(sync.WaitGroup)
+	for _, agent := range ins.Agents {
+		wg.Add(1)
+		go func(agent string) {
+			defer wg.Done()
+			tags := map[string]string{"agent": agent}
+			if err := ins.gatherAgent(slist, agent, tags); err != nil {
+				slist.PushSample(inputName, "up", 0, tags)
+				bgpLog.Errorf("failed to gather bgp of snmp agent: %v error: %v", agent, err)
+				return
+			}
+			slist.PushSample(inputName, "up", 1, tags)
+		}(agent)
+	}
+	wg.Wait()
+}
+
+func (ins *Instance) gatherAgent(slist *types.SampleList, agent string, tags map[string]string) error {
+	host, port := agent, uint16(161)
+	if h, p, err := net.SplitHostPort(agent); err == nil {
+		n, err := strconv.ParseUint(p, 10, 16)
+		if err != nil {
+			return fmt.Errorf("invalid port of agent: %v", err)
+		}
+		host, port = h, uint16(n)
+	}
+
+	gs := &gosnmp.GoSNMP{
+		Target:             host,
+		Port:               port,
+		Community:          ins.Community,
+		Version:            gosnmp.Version2c,
+		Timeout:            time.Duration(ins.Timeout),
+		Retries:            ins.Retries,
+		MaxRepetitions:     gosnmp.Default.MaxRepetitions,
+		ExponentialTimeout: true,
+	}
+	if ins.Version == 1 {
+		gs.Version = gosnmp.Version1
+	}
+	if err := gs.Connect(); err != nil {
+		return err
+	}
+	defer gs.Conn.Close()
+
+	pdus, err := walk(gs, bgpPeerTableOid)
+	if err != nil {
+		return err
+	}
+
+	// peer -> column -> value
+	peers := map[string]map[int]interface{}{}
+	for _, pdu := range pdus {
+		column, peer, ok := splitPeerOid(pdu.Name)
+		if !ok {
+			continue
+		}
+		if peers[peer] == nil {
+			peers[peer] = map[int]interface{}{}
+		}
+		peers[peer][column] = pdu.Value
+	}
+
+	// peer -> the number of the paths received
+	var prefixes map[string]int
+	if ins.SNMPPrefixes {
+		pdus, err := walk(gs, bgp4PathAttrPeerOid)
+		if err != nil {
+			return fmt.Errorf("failed to walk bgp4PathAttrTable: %v", err)
+		}
+		prefixes = map[string]int{}
+		for _, pdu := range pdus {
+			prefixes[fmt.Sprint(pdu.Value)]++
+		}
+	}
+
+	for peer, columns := range peers {
+		peerTags := map[string]string{"peer": peer}
+		for k, v := range tags {
+			peerTags[k] = v
+		}
+		if v, has := columns[bgpPeerRemoteAs]; has {
+			peerTags["remote_as"] = fmt.Sprint(toInt64(v))
+		}
+
+		var state int
+		if v, has := columns[bgpPeerState]; has {
+			state = int(toInt64(v))
+		}
+		var uptime time.Duration
+		if v, has := columns[bgpPeerFsmEstablishedTime]; has {
+			uptime = time.Duration(toInt64(v)) * time.Second
+		}
+		pushPeer(slist, state, uptime, peerTags)
+
+		if v, has := columns[bgpPeerAdminStatus]; has {
+			// stop(1) or start(2)
+			adminEnabled := 0
+			if toInt64(v) == 2 {
+				adminEnabled = 1
+			}
+			slist.PushSample(inputName, "peer_admin_enabled", adminEnabled, peerTags)
+		}
+		for column, metric := range map[int]string{
+			bgpPeerInUpdates:                 "peer_updates_received",
+			bgpPeerOutUpdates:                "peer_updates_sent",
+			bgpPeerFsmEstablishedTransitions: "peer_established_transitions",
+		} {
+			if v, has := columns[column]; has {
+				slist.PushSample(inputName, metric, toInt64(v), peerTags)
+			}
+		}
+		if prefixes != nil {
+			slist.PushSample(inputName, "peer_prefixes_received", prefixes[peer], peerTags)
+		}
+	}
+	return nil
+}
+
+func walk(gs *gosnmp.GoSNMP, oid string) ([]gosnmp.SnmpPDU, error) {
+	if gs.Version == gosnmp.Version1 {
+		return gs.WalkAll(oid)
+	}
+	return gs.BulkWalkAll(oid)
+}
+
+// splitPeerOid splits the oid of bgpPeerTable into the column and the address of peer,
+// e.g. 2 and 10.0.0.2 of .1.3.6.1.2.1.15.3.1.2.10.0.0.2
+func splitPeerOid(oid string) (int, string, bool) {
+	if !strings.HasPrefix(oid, bgpPeerTableOid+".") {
+		return 0, "", false
+	}
+	column, peer, found := strings.Cut(oid[len(bgpPeerTableOid)+1:], ".")
+	if !found {
+		return 0, "", false
+	}
+	n, err := strconv.Atoi(column)
+	if err != nil {
+		return 0, "", false
+	}
+	return n, peer, true
+}
+
+// toInt64 converts the values of the integer types of snmp, e.g. Counter32 and Gauge32
+func toInt64(v interface{}) int64 {
+	return gosnmp.ToBigInt(v).Int64()
+}
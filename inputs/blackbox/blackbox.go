@@ -0,0 +1,155 @@
+package blackbox
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/types"
+)
+
+var blackboxLog = logger.New("input.blackbox")
+
+const inputName = "blackbox"
+
+type prober func(ctx context.Context, target string, module *Module, r *probeResult) bool
+
+var probers = map[string]prober{
+	"http": probeHTTP,
+	"tcp":  probeTCP,
+	"icmp": probeICMP,
+	"dns":  probeDNS,
+}
+
+type Blackbox struct {
+	config.PluginConfig
+	// modules of blackbox exporter config file, overridden by modules with the same name below
+	ConfigFile string             `toml:"config_file"`
+	Modules    map[string]*Module `toml:"modules"`
+	Instances  []*Instance        `toml:"instances"`
+}
+
+func init() {
+	inputs.Add(inputName, func() inputs.Input {
+		return &Blackbox{}
+	})
+}
+
+func (b *Blackbox) Clone() inputs.Input {
+	return &Blackbox{}
+}
+
+func (b *Blackbox) Name() string {
+	return inputName
+}
+
+func (b *Blackbox) GetInstances() []inputs.Instance {
+	ret := make([]inputs.Instance, len(b.Instances))
+	for i := 0; i < len(b.Instances); i++ {
+		ret[i] = b.Instances[i]
+	}
+	return ret
+}
+
+func (b *Blackbox) Init() error {
+	modules := make(map[string]*Module)
+	if b.ConfigFile != "" {
+		fileModules, err := loadModules(b.ConfigFile)
+		if err != nil {
+			return err
+		}
+		for name, m := range fileModules {
+			modules[name] = m
+		}
+	}
+
+	for name, m := range b.Modules {
+		modules[name] = m
+	}
+
+	for name, m := range modules {
+		if err := m.init(); err != nil {
+			return fmt.Errorf("failed to init module %s: %v", name, err)
+		}
+	}
+
+	for _, ins := range b.Instances {
+		ins.modules = modules
+	}
+	return nil
+}
+
+type Instance struct {
+	config.InstanceConfig
+
+	Targets     []string `toml:"targets"`
+	Module      string   `toml:"module"`
+	Concurrency int      `toml:"concurrency"`
+
+	modules map[string]*Module
+	module  *Module
+}
+
+func (ins *Instance) Init() error {
+	if len(ins.Targets) == 0 {
+		return types.ErrInstancesEmpty
+	}
+
+	m, has := ins.modules[ins.Module]
+	if !has {
+		return fmt.Errorf("unknown module: %s", ins.Module)
+	}
+	ins.module = m
+
+	if ins.Concurrency <= 0 {
+		ins.Concurrency = 10
+	}
+	return nil
+}
+
+func (ins *Instance) Gather(slist *types.SampleList) {
+	wg := new(sync.WaitGroup)
+	ch := make(chan struct{}, ins.Concurrency)
+	for _, target := range ins.Targets {
+		ch <- struct{}{}
+		wg.Add(1)
+		go func(target string) {
+			defer func() {
+				<-ch
+				wg.Done()
+			}()
+			ins.probe(slist, target)
+		}(target)
+	}
+	wg.Wait()
+}
+
+func (ins *Instance) probe(slist *types.SampleList, target string) {
+	if config.Config.DebugMode {
+		blackboxLog.Debugf("probe %v with module %v", target, ins.Module)
+	}
+
+	r := &probeResult{
+		labels: map[string]string{"target": target, "module": ins.Module},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(ins.module.Timeout))
+	defer cancel()
+
+	start := time.Now()
+	success := probers[ins.module.Prober](ctx, target, ins.module, r)
+	r.add("probe_duration_seconds", time.Since(start).Seconds())
+	if success {
+		r.add("probe_success", 1)
+	} else {
+		r.add("probe_success", 0)
+	}
+
+	for _, s := range r.samples {
+		slist.PushFront(s)
+	}
+}
@@ -0,0 +1,98 @@
+package blackbox
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/miekg/dns"
+)
+
+func probeDNS(ctx context.Context, target string, module *Module, r *probeResult) bool {
+	dc := module.DNS
+
+	host, port, err := net.SplitHostPort(target)
+	if err != nil {
+		host, port = target, "53"
+		if dc.DNSOverTLS {
+			port = "853"
+		}
+	}
+
+	ip, err := chooseProtocol(ctx, dc.IPProtocol, fallback(dc.IPProtocolFallback), host, r)
+	if err != nil {
+		blackboxLog.Errorf("failed to resolve target: %v error: %v", target, err)
+		return false
+	}
+
+	transport := dc.TransportProtocol
+	if transport == "" {
+		transport = "udp"
+	}
+	client := &dns.Client{
+		Net: fmt.Sprintf("%s%d", transport, ipVersion(ip.IP)),
+	}
+	if dc.DNSOverTLS {
+		tlsConfig, err := dc.TLSConfig.build(host)
+		if err != nil {
+			blackboxLog.Errorf("failed to build tls config: %v", err)
+			return false
+		}
+		client.Net = fmt.Sprintf("tcp%d-tls", ipVersion(ip.IP))
+		client.TLSConfig = tlsConfig
+	}
+
+	msg := new(dns.Msg)
+	msg.Id = dns.Id()
+	msg.RecursionDesired = dc.Recursion == nil || *dc.Recursion
+	msg.Question = []dns.Question{{Name: dns.Fqdn(dc.QueryName), Qtype: dc.qtype, Qclass: dns.ClassINET}}
+
+	resp, rtt, err := client.ExchangeContext(ctx, msg, net.JoinHostPort(ip.String(), port))
+	if err != nil {
+		blackboxLog.Errorf("failed to query dns server: %v error: %v", target, err)
+		r.add("probe_dns_query_succeeded", 0)
+		return false
+	}
+	r.add("probe_dns_query_succeeded", 1)
+	r.add("probe_dns_duration_seconds", rtt.Seconds(), map[string]string{"phase": "request"})
+	r.add("probe_dns_answer_rrs", len(resp.Answer))
+	r.add("probe_dns_authority_rrs", len(resp.Ns))
+	r.add("probe_dns_additional_rrs", len(resp.Extra))
+
+	for _, rr := range resp.Answer {
+		if soa, ok := rr.(*dns.SOA); ok {
+			r.add("probe_dns_serial", soa.Serial)
+		}
+	}
+
+	rcodeValid := false
+	for _, rcode := range dc.ValidRcodes {
+		if dns.RcodeToString[resp.Rcode] == rcode {
+			rcodeValid = true
+			break
+		}
+	}
+	if !rcodeValid {
+		return false
+	}
+
+	return validRRs(&dc.ValidateAnswer, resp.Answer) &&
+		validRRs(&dc.ValidateAuthority, resp.Ns) &&
+		validRRs(&dc.ValidateAdditional, resp.Extra)
+}
+
+func validRRs(v *DNSRRValidator, rrs []dns.RR) bool {
+	for _, rr := range rrs {
+		for _, re := range v.matches {
+			if re.MatchString(rr.String()) {
+				return false
+			}
+		}
+		for _, re := range v.notMatches {
+			if !re.MatchString(rr.String()) {
+				return false
+			}
+		}
+	}
+	return true
+}
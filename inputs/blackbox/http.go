@@ -0,0 +1,240 @@
+package blackbox
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"strings"
+	"time"
+)
+
+func probeHTTP(ctx context.Context, target string, module *Module, r *probeResult) bool {
+	hc := module.HTTP
+
+	if !strings.Contains(target, "://") {
+		target = "http://" + target
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		blackboxLog.Errorf("failed to parse target url: %v error: %v", target, err)
+		return false
+	}
+	hostname := u.Hostname()
+
+	ip, err := chooseProtocol(ctx, hc.IPProtocol, fallback(hc.IPProtocolFallback), hostname, r)
+	if err != nil {
+		blackboxLog.Errorf("failed to resolve target: %v error: %v", target, err)
+		return false
+	}
+
+	tlsConfig, err := hc.TLSConfig.build(hostname)
+	if err != nil {
+		blackboxLog.Errorf("failed to build tls config: %v", err)
+		return false
+	}
+
+	dialer := &net.Dialer{}
+	transport := &http.Transport{
+		TLSClientConfig:   tlsConfig,
+		DisableKeepAlives: true,
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			// dial the resolved ip, hosts of redirects are resolved as usual
+			if host, port, err := net.SplitHostPort(addr); err == nil && host == hostname {
+				addr = net.JoinHostPort(ip.String(), port)
+			}
+			return dialer.DialContext(ctx, network, addr)
+		},
+	}
+	if hc.ProxyURL != "" {
+		proxy, err := url.Parse(hc.ProxyURL)
+		if err != nil {
+			blackboxLog.Errorf("failed to parse proxy_url: %v error: %v", hc.ProxyURL, err)
+			return false
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	followRedirects := !hc.NoFollowRedirects
+	if hc.FollowRedirects != nil {
+		followRedirects = *hc.FollowRedirects
+	}
+
+	redirects := 0
+	client := &http.Client{
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if !followRedirects {
+				return http.ErrUseLastResponse
+			}
+			redirects = len(via)
+			if redirects > 10 {
+				return fmt.Errorf("too many redirects")
+			}
+			return nil
+		},
+	}
+
+	var body io.Reader
+	if hc.Body != "" {
+		body = strings.NewReader(hc.Body)
+	}
+	req, err := http.NewRequestWithContext(ctx, hc.Method, u.String(), body)
+	if err != nil {
+		blackboxLog.Errorf("failed to create request: %v", err)
+		return false
+	}
+	for k, v := range hc.Headers {
+		if strings.EqualFold(k, "host") {
+			req.Host = v
+			continue
+		}
+		req.Header.Set(k, v)
+	}
+	if hc.BasicAuth != nil {
+		req.SetBasicAuth(hc.BasicAuth.Username, hc.BasicAuth.Password)
+	}
+	if hc.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+hc.BearerToken)
+	}
+
+	// only the timings of the last request are reported if redirected
+	var connectStart, connectDone, tlsStart, tlsDone, wroteRequest, firstByte time.Time
+	trace := &httptrace.ClientTrace{
+		ConnectStart:         func(_, _ string) { connectStart = time.Now() },
+		ConnectDone:          func(_, _ string, _ error) { connectDone = time.Now() },
+		TLSHandshakeStart:    func() { tlsStart = time.Now() },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { tlsDone = time.Now() },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { wroteRequest = time.Now() },
+		GotFirstResponseByte: func() { firstByte = time.Now() },
+	}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		blackboxLog.Errorf("failed to request: %v error: %v", target, err)
+		return false
+	}
+	defer resp.Body.Close()
+
+	bs, err := io.ReadAll(resp.Body)
+	if err != nil {
+		blackboxLog.Errorf("failed to read response body: %v error: %v", target, err)
+		return false
+	}
+	end := time.Now()
+
+	phase := func(name string, from, to time.Time) {
+		var d float64
+		if !from.IsZero() && !to.IsZero() {
+			d = to.Sub(from).Seconds()
+		}
+		r.add("probe_http_duration_seconds", d, map[string]string{"phase": name})
+	}
+	phase("connect", connectStart, connectDone)
+	phase("tls", tlsStart, tlsDone)
+	phase("processing", wroteRequest, firstByte)
+	phase("transfer", firstByte, end)
+
+	r.add("probe_http_status_code", resp.StatusCode)
+	r.add("probe_http_content_length", resp.ContentLength)
+	r.add("probe_http_uncompressed_body_length", len(bs))
+	r.add("probe_http_redirects", redirects)
+	r.add("probe_http_version", float64(resp.ProtoMajor)+float64(resp.ProtoMinor)/10)
+
+	success := validStatusCode(hc.ValidStatusCodes, resp.StatusCode)
+	if success && len(hc.ValidHTTPVersions) > 0 {
+		success = false
+		for _, v := range hc.ValidHTTPVersions {
+			if v == resp.Proto {
+				success = true
+				break
+			}
+		}
+	}
+
+	if resp.TLS != nil {
+		r.add("probe_http_ssl", 1)
+		r.addTLS(resp.TLS)
+		if hc.FailIfSSL {
+			success = false
+		}
+	} else {
+		r.add("probe_http_ssl", 0)
+		if hc.FailIfNotSSL {
+			success = false
+		}
+	}
+
+	regexFailed := !matchHTTPHeaders(&hc, resp.Header) || !matchHTTPBody(&hc, bs)
+	if regexFailed {
+		r.add("probe_failed_due_to_regex", 1)
+		success = false
+	} else {
+		r.add("probe_failed_due_to_regex", 0)
+	}
+
+	return success
+}
+
+// validStatusCode defaults to 2xx if no status codes configured
+func validStatusCode(codes []int, code int) bool {
+	if len(codes) == 0 {
+		return code >= 200 && code < 300
+	}
+	for _, c := range codes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+func matchHTTPBody(hc *HTTPProbe, body []byte) bool {
+	for _, re := range hc.bodyMatches {
+		if re.Match(body) {
+			return false
+		}
+	}
+	for _, re := range hc.bodyNotMatches {
+		if !re.Match(body) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchHTTPHeaders(hc *HTTPProbe, header http.Header) bool {
+	for _, hm := range hc.FailIfHeaderMatchesRegexp {
+		values := header.Values(hm.Header)
+		for _, v := range values {
+			if hm.re.MatchString(v) {
+				return false
+			}
+		}
+	}
+	for _, hm := range hc.FailIfHeaderNotMatchesRegexp {
+		values := header.Values(hm.Header)
+		if len(values) == 0 {
+			if !hm.AllowMissing {
+				return false
+			}
+			continue
+		}
+		matched := false
+		for _, v := range values {
+			if hm.re.MatchString(v) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,54 @@
+package blackbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	ping "github.com/prometheus-community/pro-bing"
+)
+
+func probeICMP(ctx context.Context, target string, module *Module, r *probeResult) bool {
+	ic := module.ICMP
+
+	ip, err := chooseProtocol(ctx, ic.IPProtocol, fallback(ic.IPProtocolFallback), target, r)
+	if err != nil {
+		blackboxLog.Errorf("failed to resolve target: %v error: %v", target, err)
+		return false
+	}
+
+	pinger := ping.New("")
+	pinger.SetNetwork(fmt.Sprintf("ip%d", ipVersion(ip.IP)))
+	pinger.SetIPAddr(ip)
+	pinger.SetPrivileged(true)
+	pinger.Count = 1
+	pinger.Timeout = time.Duration(module.Timeout)
+	if deadline, ok := ctx.Deadline(); ok {
+		pinger.Timeout = time.Until(deadline)
+	}
+	if ic.PayloadSize > 0 {
+		pinger.Size = ic.PayloadSize
+	}
+	if ic.TTL > 0 {
+		pinger.TTL = ic.TTL
+	}
+
+	var hopLimit int
+	pinger.OnRecv = func(pkt *ping.Packet) {
+		hopLimit = pkt.TTL
+	}
+
+	if err = pinger.Run(); err != nil {
+		blackboxLog.Errorf("failed to ping target: %v error: %v", target, err)
+		return false
+	}
+
+	stats := pinger.Statistics()
+	if stats.PacketsRecv == 0 {
+		return false
+	}
+
+	r.add("probe_icmp_duration_seconds", stats.AvgRtt.Seconds(), map[string]string{"phase": "rtt"})
+	r.add("probe_icmp_reply_hop_limit", hopLimit)
+	return true
+}
@@ -0,0 +1,268 @@
+package blackbox
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+	"gopkg.in/yaml.v3"
+
+	"flashcat.cloud/categraf/config"
+)
+
+const defaultTimeout = 5 * time.Second
+
+// Module is the probe definition, keeps the same layout as blackbox exporter,
+// so modules of blackbox.yml can be loaded directly
+type Module struct {
+	Prober  string          `toml:"prober" yaml:"prober"`
+	Timeout config.Duration `toml:"timeout" yaml:"timeout"`
+	HTTP    HTTPProbe       `toml:"http" yaml:"http"`
+	TCP     TCPProbe        `toml:"tcp" yaml:"tcp"`
+	ICMP    ICMPProbe       `toml:"icmp" yaml:"icmp"`
+	DNS     DNSProbe        `toml:"dns" yaml:"dns"`
+}
+
+type TLSConfig struct {
+	CAFile             string `toml:"ca_file" yaml:"ca_file"`
+	CertFile           string `toml:"cert_file" yaml:"cert_file"`
+	KeyFile            string `toml:"key_file" yaml:"key_file"`
+	ServerName         string `toml:"server_name" yaml:"server_name"`
+	InsecureSkipVerify bool   `toml:"insecure_skip_verify" yaml:"insecure_skip_verify"`
+}
+
+type BasicAuth struct {
+	Username string `toml:"username" yaml:"username"`
+	Password string `toml:"password" yaml:"password"`
+}
+
+type HeaderMatch struct {
+	Header       string `toml:"header" yaml:"header"`
+	Regexp       string `toml:"regexp" yaml:"regexp"`
+	AllowMissing bool   `toml:"allow_missing" yaml:"allow_missing"`
+
+	re *regexp.Regexp
+}
+
+type HTTPProbe struct {
+	ValidStatusCodes             []int             `toml:"valid_status_codes" yaml:"valid_status_codes"`
+	ValidHTTPVersions            []string          `toml:"valid_http_versions" yaml:"valid_http_versions"`
+	IPProtocol                   string            `toml:"preferred_ip_protocol" yaml:"preferred_ip_protocol"`
+	IPProtocolFallback           *bool             `toml:"ip_protocol_fallback" yaml:"ip_protocol_fallback"`
+	Method                       string            `toml:"method" yaml:"method"`
+	Headers                      map[string]string `toml:"headers" yaml:"headers"`
+	Body                         string            `toml:"body" yaml:"body"`
+	NoFollowRedirects            bool              `toml:"no_follow_redirects" yaml:"no_follow_redirects"`
+	FollowRedirects              *bool             `toml:"follow_redirects" yaml:"follow_redirects"`
+	FailIfSSL                    bool              `toml:"fail_if_ssl" yaml:"fail_if_ssl"`
+	FailIfNotSSL                 bool              `toml:"fail_if_not_ssl" yaml:"fail_if_not_ssl"`
+	FailIfBodyMatchesRegexp      []string          `toml:"fail_if_body_matches_regexp" yaml:"fail_if_body_matches_regexp"`
+	FailIfBodyNotMatchesRegexp   []string          `toml:"fail_if_body_not_matches_regexp" yaml:"fail_if_body_not_matches_regexp"`
+	FailIfHeaderMatchesRegexp    []*HeaderMatch    `toml:"fail_if_header_matches" yaml:"fail_if_header_matches"`
+	FailIfHeaderNotMatchesRegexp []*HeaderMatch    `toml:"fail_if_header_not_matches" yaml:"fail_if_header_not_matches"`
+	TLSConfig                    TLSConfig         `toml:"tls_config" yaml:"tls_config"`
+	BasicAuth                    *BasicAuth        `toml:"basic_auth" yaml:"basic_auth"`
+	BearerToken                  string            `toml:"bearer_token" yaml:"bearer_token"`
+	ProxyURL                     string            `toml:"proxy_url" yaml:"proxy_url"`
+
+	bodyMatches    []*regexp.Regexp
+	bodyNotMatches []*regexp.Regexp
+}
+
+type QueryResponse struct {
+	Expect   string `toml:"expect" yaml:"expect"`
+	Send     string `toml:"send" yaml:"send"`
+	StartTLS bool   `toml:"starttls" yaml:"starttls"`
+
+	expect *regexp.Regexp
+}
+
+type TCPProbe struct {
+	IPProtocol         string           `toml:"preferred_ip_protocol" yaml:"preferred_ip_protocol"`
+	IPProtocolFallback *bool            `toml:"ip_protocol_fallback" yaml:"ip_protocol_fallback"`
+	QueryResponse      []*QueryResponse `toml:"query_response" yaml:"query_response"`
+	TLS                bool             `toml:"tls" yaml:"tls"`
+	TLSConfig          TLSConfig        `toml:"tls_config" yaml:"tls_config"`
+}
+
+type ICMPProbe struct {
+	IPProtocol         string `toml:"preferred_ip_protocol" yaml:"preferred_ip_protocol"`
+	IPProtocolFallback *bool  `toml:"ip_protocol_fallback" yaml:"ip_protocol_fallback"`
+	PayloadSize        int    `toml:"payload_size" yaml:"payload_size"`
+	TTL                int    `toml:"ttl" yaml:"ttl"`
+}
+
+type DNSRRValidator struct {
+	FailIfMatchesRegexp    []string `toml:"fail_if_matches_regexp" yaml:"fail_if_matches_regexp"`
+	FailIfNotMatchesRegexp []string `toml:"fail_if_not_matches_regexp" yaml:"fail_if_not_matches_regexp"`
+
+	matches    []*regexp.Regexp
+	notMatches []*regexp.Regexp
+}
+
+type DNSProbe struct {
+	IPProtocol         string         `toml:"preferred_ip_protocol" yaml:"preferred_ip_protocol"`
+	IPProtocolFallback *bool          `toml:"ip_protocol_fallback" yaml:"ip_protocol_fallback"`
+	TransportProtocol  string         `toml:"transport_protocol" yaml:"transport_protocol"`
+	DNSOverTLS         bool           `toml:"dns_over_tls" yaml:"dns_over_tls"`
+	TLSConfig          TLSConfig      `toml:"tls_config" yaml:"tls_config"`
+	QueryName          string         `toml:"query_name" yaml:"query_name"`
+	QueryType          string         `toml:"query_type" yaml:"query_type"`
+	Recursion          *bool          `toml:"recursion_desired" yaml:"recursion_desired"`
+	ValidRcodes        []string       `toml:"valid_rcodes" yaml:"valid_rcodes"`
+	ValidateAnswer     DNSRRValidator `toml:"validate_answer_rrs" yaml:"validate_answer_rrs"`
+	ValidateAuthority  DNSRRValidator `toml:"validate_authority_rrs" yaml:"validate_authority_rrs"`
+	ValidateAdditional DNSRRValidator `toml:"validate_additional_rrs" yaml:"validate_additional_rrs"`
+
+	qtype uint16
+}
+
+// loadModules reads modules from a blackbox exporter config file
+func loadModules(path string) (map[string]*Module, error) {
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file struct {
+		Modules map[string]*Module `yaml:"modules"`
+	}
+	if err = yaml.Unmarshal(bs, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+	return file.Modules, nil
+}
+
+func (m *Module) init() error {
+	if m.Timeout <= 0 {
+		m.Timeout = config.Duration(defaultTimeout)
+	}
+
+	switch m.Prober {
+	case "http":
+		return m.HTTP.init()
+	case "tcp":
+		return m.TCP.init()
+	case "icmp":
+		return nil
+	case "dns":
+		return m.DNS.init()
+	}
+	return fmt.Errorf("unknown prober: %s", m.Prober)
+}
+
+func (h *HTTPProbe) init() error {
+	var err error
+	if h.bodyMatches, err = compileRegexps(h.FailIfBodyMatchesRegexp); err != nil {
+		return err
+	}
+	if h.bodyNotMatches, err = compileRegexps(h.FailIfBodyNotMatchesRegexp); err != nil {
+		return err
+	}
+	for _, hm := range append(h.FailIfHeaderMatchesRegexp, h.FailIfHeaderNotMatchesRegexp...) {
+		if hm.re, err = regexp.Compile(hm.Regexp); err != nil {
+			return fmt.Errorf("failed to compile header regexp %s: %v", hm.Regexp, err)
+		}
+	}
+	if h.Method == "" {
+		h.Method = "GET"
+	}
+	return nil
+}
+
+func (t *TCPProbe) init() error {
+	for _, qr := range t.QueryResponse {
+		if qr.Expect == "" {
+			continue
+		}
+		var err error
+		if qr.expect, err = regexp.Compile(qr.Expect); err != nil {
+			return fmt.Errorf("failed to compile expect regexp %s: %v", qr.Expect, err)
+		}
+	}
+	return nil
+}
+
+func (d *DNSProbe) init() error {
+	if d.QueryName == "" {
+		return fmt.Errorf("query_name of dns probe is required")
+	}
+
+	if d.QueryType == "" {
+		d.QueryType = "ANY"
+	}
+	qtype, has := dns.StringToType[strings.ToUpper(d.QueryType)]
+	if !has {
+		return fmt.Errorf("invalid query_type: %s", d.QueryType)
+	}
+	d.qtype = qtype
+
+	if len(d.ValidRcodes) == 0 {
+		d.ValidRcodes = []string{"NOERROR"}
+	}
+
+	for _, v := range []*DNSRRValidator{&d.ValidateAnswer, &d.ValidateAuthority, &d.ValidateAdditional} {
+		var err error
+		if v.matches, err = compileRegexps(v.FailIfMatchesRegexp); err != nil {
+			return err
+		}
+		if v.notMatches, err = compileRegexps(v.FailIfNotMatchesRegexp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func compileRegexps(exprs []string) ([]*regexp.Regexp, error) {
+	ret := make([]*regexp.Regexp, 0, len(exprs))
+	for _, expr := range exprs {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile regexp %s: %v", expr, err)
+		}
+		ret = append(ret, re)
+	}
+	return ret, nil
+}
+
+// fallback defaults to true, the same as blackbox exporter
+func fallback(b *bool) bool {
+	return b == nil || *b
+}
+
+func (c TLSConfig) build(serverName string) (*tls.Config, error) {
+	cfg := &tls.Config{
+		InsecureSkipVerify: c.InsecureSkipVerify,
+		ServerName:         serverName,
+	}
+	if c.ServerName != "" {
+		cfg.ServerName = c.ServerName
+	}
+
+	if c.CAFile != "" {
+		bs, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ca file %s: %v", c.CAFile, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(bs) {
+			return nil, fmt.Errorf("failed to parse ca file %s", c.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if c.CertFile != "" && c.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client cert: %v", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
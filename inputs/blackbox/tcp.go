@@ -0,0 +1,105 @@
+package blackbox
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+)
+
+func probeTCP(ctx context.Context, target string, module *Module, r *probeResult) bool {
+	tc := module.TCP
+
+	host, port, err := net.SplitHostPort(target)
+	if err != nil {
+		blackboxLog.Errorf("failed to split target host and port: %v error: %v", target, err)
+		return false
+	}
+
+	ip, err := chooseProtocol(ctx, tc.IPProtocol, fallback(tc.IPProtocolFallback), host, r)
+	if err != nil {
+		blackboxLog.Errorf("failed to resolve target: %v error: %v", target, err)
+		return false
+	}
+
+	network := fmt.Sprintf("tcp%d", ipVersion(ip.IP))
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+	if err != nil {
+		blackboxLog.Errorf("failed to dial target: %v error: %v", target, err)
+		return false
+	}
+	defer func() {
+		conn.Close()
+	}()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err = conn.SetDeadline(deadline); err != nil {
+			blackboxLog.Errorf("failed to set deadline: %v", err)
+			return false
+		}
+	}
+
+	upgrade := func() bool {
+		tlsConfig, err := tc.TLSConfig.build(host)
+		if err != nil {
+			blackboxLog.Errorf("failed to build tls config: %v", err)
+			return false
+		}
+		tlsConn := tls.Client(conn, tlsConfig)
+		if err = tlsConn.HandshakeContext(ctx); err != nil {
+			blackboxLog.Errorf("failed to handshake with target: %v error: %v", target, err)
+			return false
+		}
+		state := tlsConn.ConnectionState()
+		r.addTLS(&state)
+		conn = tlsConn
+		return true
+	}
+
+	if tc.TLS && !upgrade() {
+		return false
+	}
+
+	scanner := bufio.NewScanner(conn)
+	for i, qr := range tc.QueryResponse {
+		send := qr.Send
+		if qr.expect != nil {
+			var match []int
+			for scanner.Scan() {
+				if match = qr.expect.FindSubmatchIndex(scanner.Bytes()); match != nil {
+					send = string(qr.expect.Expand(nil, []byte(send), scanner.Bytes(), match))
+					break
+				}
+			}
+			if match == nil {
+				if err = scanner.Err(); err != nil {
+					blackboxLog.Errorf("failed to read from target: %v error: %v", target, err)
+				}
+				r.add("probe_failed_due_to_regex", 1)
+				return false
+			}
+		}
+
+		if send != "" {
+			if _, err = fmt.Fprintf(conn, "%s\n", send); err != nil {
+				blackboxLog.Errorf("failed to send query %v to target: %v error: %v", i, target, err)
+				return false
+			}
+		}
+
+		if qr.StartTLS {
+			if !upgrade() {
+				return false
+			}
+			scanner = bufio.NewScanner(conn)
+		}
+	}
+
+	if len(tc.QueryResponse) > 0 {
+		r.add("probe_failed_due_to_regex", 0)
+	}
+
+	return true
+}
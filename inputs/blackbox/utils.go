@@ -0,0 +1,93 @@
+package blackbox
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"time"
+
+	"flashcat.cloud/categraf/types"
+)
+
+// probeResult collects the samples of one probe
+type probeResult struct {
+	labels  map[string]string
+	samples []*types.Sample
+}
+
+func (r *probeResult) add(metric string, value interface{}, labels ...map[string]string) {
+	r.samples = append(r.samples, types.NewSample("", metric, value, append([]map[string]string{r.labels}, labels...)...))
+}
+
+// chooseProtocol resolves the target host with the preferred ip protocol (ip6 by default),
+// falls back to the other one if allowed
+func chooseProtocol(ctx context.Context, preferred string, fallback bool, host string, r *probeResult) (*net.IPAddr, error) {
+	if preferred != "ip4" {
+		preferred = "ip6"
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		r.add("probe_ip_protocol", ipVersion(ip))
+		return &net.IPAddr{IP: ip}, nil
+	}
+
+	start := time.Now()
+	defer func() {
+		r.add("probe_dns_lookup_time_seconds", time.Since(start).Seconds())
+	}()
+
+	ips, err := net.DefaultResolver.LookupIP(ctx, preferred, host)
+	if err != nil || len(ips) == 0 {
+		if !fallback {
+			return nil, fmt.Errorf("failed to resolve %s with %s: %v", host, preferred, err)
+		}
+		other := "ip4"
+		if preferred == "ip4" {
+			other = "ip6"
+		}
+		ips, err = net.DefaultResolver.LookupIP(ctx, other, host)
+		if err != nil || len(ips) == 0 {
+			return nil, fmt.Errorf("failed to resolve %s: %v", host, err)
+		}
+	}
+
+	r.add("probe_ip_protocol", ipVersion(ips[0]))
+	return &net.IPAddr{IP: ips[0]}, nil
+}
+
+func ipVersion(ip net.IP) int {
+	if ip.To4() != nil {
+		return 4
+	}
+	return 6
+}
+
+func getEarliestCertExpiry(state *tls.ConnectionState) time.Time {
+	earliest := time.Time{}
+	for _, cert := range state.PeerCertificates {
+		if (earliest.IsZero() || cert.NotAfter.Before(earliest)) && !cert.NotAfter.IsZero() {
+			earliest = cert.NotAfter
+		}
+	}
+	return earliest
+}
+
+func tlsVersion(state *tls.ConnectionState) string {
+	switch state.Version {
+	case tls.VersionTLS10:
+		return "TLS 1.0"
+	case tls.VersionTLS11:
+		return "TLS 1.1"
+	case tls.VersionTLS12:
+		return "TLS 1.2"
+	case tls.VersionTLS13:
+		return "TLS 1.3"
+	}
+	return "unknown"
+}
+
+func (r *probeResult) addTLS(state *tls.ConnectionState) {
+	r.add("probe_ssl_earliest_cert_expiry", getEarliestCertExpiry(state).Unix())
+	r.add("probe_tls_version_info", 1, map[string]string{"version": tlsVersion(state)})
+}
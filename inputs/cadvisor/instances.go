@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"io"
 	"io/ioutil"
-	"log"
 	"net/http"
 	"net/url"
 	"os"
@@ -17,11 +16,14 @@ import (
 	"flashcat.cloud/categraf/pkg/cache"
 	"flashcat.cloud/categraf/pkg/filter"
 	"flashcat.cloud/categraf/pkg/kubernetes"
+	"flashcat.cloud/categraf/pkg/logger"
 	util "flashcat.cloud/categraf/pkg/metrics"
 	"flashcat.cloud/categraf/pkg/tls"
 	"flashcat.cloud/categraf/types"
 )
 
+var cadvisorLog = logger.New("input.cadvisor")
+
 const (
 	acceptHeader = `application/vnd.google.protobuf;proto=io.prometheus.client.MetricFamily;encoding=delimited;q=0.7,text/plain;version=0.0.4;q=0.3,*/*;q=0.1`
 	cadvisorPath = "/metrics/cadvisor"
@@ -86,7 +88,7 @@ func (ins *Instance) Init() error {
 
 	u, err := url.Parse(ins.URL)
 	if err != nil {
-		log.Println("E! failed to scrape url:", ins.URL, "error:", err)
+		cadvisorLog.Errorf("failed to scrape url: %v error: %v", ins.URL, err)
 	}
 	ins.u = u
 	if ins.u.Path == "" {
@@ -144,7 +146,7 @@ func (ins *Instance) cache() {
 	podUrl.Path = "/pods"
 	req, err := http.NewRequest("GET", podUrl.String(), nil)
 	if err != nil {
-		log.Println("E! failed to new request for url:", podUrl.String(), "error:", err)
+		cadvisorLog.Errorf("failed to new request for url: %v error: %v", podUrl.String(), err)
 		return
 	}
 	ins.setHeaders(req)
@@ -155,19 +157,19 @@ func (ins *Instance) cache() {
 		case <-timer.C:
 			resp, err := ins.client.Do(req)
 			if err != nil {
-				log.Println("E! failed to request for url:", podUrl.String(), "error:", err)
+				cadvisorLog.Errorf("failed to request for url: %v error: %v", podUrl.String(), err)
 				continue
 			}
 			resp.Body.Close()
 			body, err := ioutil.ReadAll(resp.Body)
 			if err != nil {
-				log.Println("E! failed to read body for url:", podUrl.String(), "error:", err)
+				cadvisorLog.Errorf("failed to read body for url: %v error: %v", podUrl.String(), err)
 				continue
 			}
 			pods := kubernetes.PodList{}
 			err = json.Unmarshal(body, &pods)
 			if err != nil {
-				log.Println("E! unmarshal pods info", err)
+				cadvisorLog.Errorf("unmarshal pods info %v", err)
 				continue
 			}
 			for _, pod := range pods.Items {
@@ -181,7 +183,7 @@ func (ins *Instance) cache() {
 }
 
 func (ins *Instance) Done() chan struct{} {
-	log.Println("I! cadvisor instance stop")
+	cadvisorLog.Infof("cadvisor instance stop")
 	return ins.stop
 }
 
@@ -208,7 +210,7 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 
 	req, err := http.NewRequest("GET", ins.u.String(), nil)
 	if err != nil {
-		log.Println("E! failed to new request for url:", ins.u.String(), "error:", err)
+		cadvisorLog.Errorf("failed to new request for url: %v error: %v", ins.u.String(), err)
 		return
 	}
 
@@ -218,7 +220,7 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 
 	urlKey, urlVal, err := ins.GenerateLabel(ins.u)
 	if err != nil {
-		log.Println("E! failed to generate url label value:", err)
+		cadvisorLog.Errorf("failed to generate url label value: %v", err)
 		return
 	}
 
@@ -227,13 +229,13 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 	res, err := ins.client.Do(req)
 	if err != nil {
 		slist.PushFront(types.NewSample("", "up", 0, labels))
-		log.Println("E! failed to query url:", ins.u.String(), "error:", err)
+		cadvisorLog.Errorf("failed to query url: %v error: %v", ins.u.String(), err)
 		return
 	}
 
 	if res.StatusCode != http.StatusOK {
 		slist.PushFront(types.NewSample("", "up", 0, labels))
-		log.Println("E! failed to query url:", ins.u.String(), "status code:", res.StatusCode)
+		cadvisorLog.Errorf("failed to query url: %v status code: %v", ins.u.String(), res.StatusCode)
 		return
 	}
 
@@ -242,7 +244,7 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 	body, err := io.ReadAll(res.Body)
 	if err != nil {
 		slist.PushFront(types.NewSample("", "up", 0, labels))
-		log.Println("E! failed to read response body, url:", ins.u.String(), "error:", err)
+		cadvisorLog.Errorf("failed to read response body, url: %v error: %v", ins.u.String(), err)
 		return
 	}
 
@@ -255,7 +257,7 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 func (ins *Instance) gather(buf []byte, header http.Header, defaultLabels map[string]string, slist *types.SampleList) {
 	metricFamilies, err := util.Parse(buf, header)
 	if err != nil {
-		log.Println("E! failed to parse metrics, url:", ins.u.String(), "error:", err)
+		cadvisorLog.Errorf("failed to parse metrics, url: %v error: %v", ins.u.String(), err)
 		return
 	}
 
@@ -342,7 +344,7 @@ func (ins *Instance) setHeaders(req *http.Request) {
 	if ins.BearerTokeFile != "" {
 		content, err := os.ReadFile(ins.BearerTokeFile)
 		if err != nil {
-			log.Println("E! failed to read bearer token file:", ins.BearerTokeFile, "error:", err)
+			cadvisorLog.Errorf("failed to read bearer token file: %v error: %v", ins.BearerTokeFile, err)
 			return
 		}
 
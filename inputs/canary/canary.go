@@ -0,0 +1,260 @@
+package canary
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"regexp"
+	"time"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/pkg/tls"
+	"flashcat.cloud/categraf/types"
+)
+
+var canaryLog = logger.New("input.canary")
+
+const (
+	inputName = "canary"
+
+	// the timeout of cleanup statements, which run even if the flow timed out
+	cleanupTimeout = 5 * time.Second
+)
+
+// the variables in requests, statements and messages, e.g. ${token}
+var varPattern = regexp.MustCompile(`\$\{(\w+)\}`)
+
+type Canary struct {
+	config.PluginConfig
+	Instances []*Instance `toml:"instances"`
+}
+
+type Instance struct {
+	config.InstanceConfig
+
+	// the name of the flow, the label of all metrics
+	Flow string `toml:"flow"`
+	// the deadline of the whole flow
+	Timeout config.Duration `toml:"timeout"`
+
+	// the steps run in order: the http requests, the sql round trip and the message round trip,
+	// the flow stops at the first failed step
+	HTTP  []*HTTPStep `toml:"http"`
+	SQL   *SQLStep    `toml:"sql"`
+	Queue *QueueStep  `toml:"queue"`
+
+	// the options of http requests
+	FollowRedirects bool `toml:"follow_redirects"`
+	tls.ClientConfig
+
+	steps     []step
+	transport *http.Transport
+}
+
+// step is a part of a flow, the variables captured by a step are visible to the later steps
+type step interface {
+	name() string
+	maxDuration() time.Duration
+	run(ctx context.Context, r *run) error
+}
+
+// stepConfig is the options shared by all steps
+type stepConfig struct {
+	Name string `toml:"name"`
+	// the step fails if it takes longer, no limit if 0
+	MaxDuration config.Duration `toml:"max_duration"`
+}
+
+func (c *stepConfig) name() string {
+	return c.Name
+}
+
+func (c *stepConfig) maxDuration() time.Duration {
+	return time.Duration(c.MaxDuration)
+}
+
+// run is the state of a run of a flow
+type run struct {
+	vars   map[string]string
+	client *http.Client
+}
+
+// expand replaces the variables in s, the unknown ones are kept
+func (r *run) expand(s string) string {
+	return varPattern.ReplaceAllStringFunc(s, func(v string) string {
+		if value, ok := r.vars[v[2:len(v)-1]]; ok {
+			return value
+		}
+		return v
+	})
+}
+
+var _ inputs.SampleGatherer = new(Instance)
+var _ inputs.Input = new(Canary)
+var _ inputs.InstancesGetter = new(Canary)
+
+func init() {
+	inputs.Add(inputName, func() inputs.Input {
+		return &Canary{}
+	})
+}
+
+func (c *Canary) Clone() inputs.Input {
+	return &Canary{}
+}
+
+func (c *Canary) Name() string {
+	return inputName
+}
+
+func (c *Canary) GetInstances() []inputs.Instance {
+	ret := make([]inputs.Instance, len(c.Instances))
+	for i := 0; i < len(c.Instances); i++ {
+		ret[i] = c.Instances[i]
+	}
+	return ret
+}
+
+func (c *Canary) Drop() {
+	for i := 0; i < len(c.Instances); i++ {
+		c.Instances[i].close()
+	}
+}
+
+func (ins *Instance) Init() error {
+	if len(ins.HTTP) == 0 && ins.SQL == nil && ins.Queue == nil {
+		return types.ErrInstancesEmpty
+	}
+	if ins.Flow == "" {
+		return fmt.Errorf("flow is required")
+	}
+	if ins.Timeout <= 0 {
+		ins.Timeout = config.Duration(30 * time.Second)
+	}
+
+	tlsConfig, err := ins.ClientConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+	ins.transport = &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: tlsConfig,
+	}
+
+	ins.steps = ins.steps[:0]
+	for i, s := range ins.HTTP {
+		if s.Name == "" {
+			s.Name = fmt.Sprintf("http_%d", i+1)
+		}
+		if err := s.init(); err != nil {
+			return fmt.Errorf("failed to init http step %s: %v", s.Name, err)
+		}
+		ins.steps = append(ins.steps, s)
+	}
+	if ins.SQL != nil {
+		if ins.SQL.Name == "" {
+			ins.SQL.Name = "sql"
+		}
+		if err := ins.SQL.init(); err != nil {
+			return fmt.Errorf("failed to init sql step: %v", err)
+		}
+		ins.steps = append(ins.steps, ins.SQL)
+	}
+	if ins.Queue != nil {
+		if ins.Queue.Name == "" {
+			ins.Queue.Name = "queue"
+		}
+		if err := ins.Queue.init(time.Duration(ins.Timeout)); err != nil {
+			return fmt.Errorf("failed to init queue step: %v", err)
+		}
+		ins.steps = append(ins.steps, ins.Queue)
+	}
+
+	names := make(map[string]bool, len(ins.steps))
+	for _, s := range ins.steps {
+		if names[s.name()] {
+			return fmt.Errorf("duplicate step name %s", s.name())
+		}
+		names[s.name()] = true
+	}
+	return nil
+}
+
+func (ins *Instance) close() {
+	if ins.SQL != nil {
+		ins.SQL.close()
+	}
+	if ins.Queue != nil {
+		ins.Queue.close()
+	}
+}
+
+// Gather runs the flow once, the steps after the failed one are not run
+func (ins *Instance) Gather(slist *types.SampleList) {
+	tags := map[string]string{"flow": ins.Flow}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(ins.Timeout))
+	defer cancel()
+
+	r, err := ins.newRun()
+	if err != nil {
+		canaryLog.Errorf("failed to start canary flow %v error: %v", ins.Flow, err)
+		slist.PushSample(inputName, "success", 0, tags)
+		return
+	}
+
+	begun := time.Now()
+	success := true
+	for _, s := range ins.steps {
+		stepBegun := time.Now()
+		err := s.run(ctx, r)
+		took := time.Since(stepBegun)
+		if err == nil && s.maxDuration() > 0 && took > s.maxDuration() {
+			err = fmt.Errorf("took %s, longer than max_duration %s", took, s.maxDuration())
+		}
+
+		stepTags := map[string]string{"step": s.name()}
+		slist.PushSample(inputName, "step_duration_seconds", took.Seconds(), tags, stepTags)
+		slist.PushSample(inputName, "step_success", err == nil, tags, stepTags)
+		if err != nil {
+			canaryLog.Errorf("canary flow %v failed at step %v error: %v", ins.Flow, s.name(), err)
+			success = false
+			break
+		}
+	}
+
+	slist.PushSample(inputName, "duration_seconds", time.Since(begun).Seconds(), tags)
+	slist.PushSample(inputName, "success", success, tags)
+}
+
+// newRun returns the state of a run with a random ${token}, and a http client of its own cookies
+func (ins *Instance) newRun() (*run, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return nil, err
+	}
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{
+		Transport: ins.transport,
+		Jar:       jar,
+	}
+	if !ins.FollowRedirects {
+		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+
+	return &run{
+		vars:   map[string]string{"token": hex.EncodeToString(token)},
+		client: client,
+	}, nil
+}
@@ -0,0 +1,138 @@
+package canary
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// the body read for assertions and captures
+const maxBodySize = 1 << 20
+
+// HTTPStep sends a request and asserts the response
+type HTTPStep struct {
+	stepConfig
+
+	Method string `toml:"method"`
+	URL    string `toml:"url"`
+	// pairs of names and values, e.g. ["Content-Type", "application/json"]
+	Headers  []string `toml:"headers"`
+	Body     string   `toml:"body"`
+	Username string   `toml:"username"`
+	Password string   `toml:"password"`
+
+	// any 2xx if empty
+	ExpectStatus        []int  `toml:"expect_status"`
+	ExpectBodySubstring string `toml:"expect_body_substring"`
+	ExpectBodyRegex     string `toml:"expect_body_regex"`
+	// the variables captured by the first group of the regexes from the body, e.g. token = '"token":"([^"]+)"'
+	Extract map[string]string `toml:"extract"`
+
+	bodyRegex *regexp.Regexp
+	extract   map[string]*regexp.Regexp
+}
+
+func (s *HTTPStep) init() error {
+	if s.URL == "" {
+		return fmt.Errorf("url is required")
+	}
+	if s.Method == "" {
+		s.Method = http.MethodGet
+	}
+	if len(s.Headers)%2 != 0 {
+		return fmt.Errorf("headers must be pairs of names and values")
+	}
+
+	var err error
+	if s.ExpectBodyRegex != "" {
+		if s.bodyRegex, err = regexp.Compile(s.ExpectBodyRegex); err != nil {
+			return fmt.Errorf("invalid expect_body_regex: %v", err)
+		}
+	}
+	s.extract = make(map[string]*regexp.Regexp, len(s.Extract))
+	for name, pattern := range s.Extract {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid extract %s: %v", name, err)
+		}
+		if re.NumSubexp() < 1 {
+			return fmt.Errorf("invalid extract %s: a capturing group is required", name)
+		}
+		s.extract[name] = re
+	}
+	return nil
+}
+
+func (s *HTTPStep) run(ctx context.Context, r *run) error {
+	var body io.Reader
+	if s.Body != "" {
+		body = strings.NewReader(r.expand(s.Body))
+	}
+	req, err := http.NewRequestWithContext(ctx, s.Method, r.expand(s.URL), body)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(s.Headers); i += 2 {
+		value := r.expand(s.Headers[i+1])
+		req.Header.Add(s.Headers[i], value)
+		if s.Headers[i] == "Host" {
+			req.Host = value
+		}
+	}
+	if s.Username != "" || s.Password != "" {
+		req.SetBasicAuth(s.Username, s.Password)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	bs, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil {
+		return err
+	}
+
+	if !s.statusExpected(resp.StatusCode) {
+		return fmt.Errorf("unexpected HTTP status %s: %q", resp.Status, truncate(bs))
+	}
+	if s.ExpectBodySubstring != "" && !strings.Contains(string(bs), r.expand(s.ExpectBodySubstring)) {
+		return fmt.Errorf("body does not contain %q: %q", s.ExpectBodySubstring, truncate(bs))
+	}
+	if s.bodyRegex != nil && !s.bodyRegex.Match(bs) {
+		return fmt.Errorf("body does not match %q: %q", s.ExpectBodyRegex, truncate(bs))
+	}
+
+	for name, re := range s.extract {
+		m := re.FindSubmatch(bs)
+		if m == nil {
+			return fmt.Errorf("failed to extract %s, body does not match %q", name, s.Extract[name])
+		}
+		r.vars[name] = string(m[1])
+	}
+	return nil
+}
+
+func (s *HTTPStep) statusExpected(code int) bool {
+	if len(s.ExpectStatus) == 0 {
+		return code >= 200 && code < 300
+	}
+	for _, c := range s.ExpectStatus {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// truncate returns the head of the body in errors
+func truncate(bs []byte) []byte {
+	if len(bs) > 200 {
+		return bs[:200]
+	}
+	return bs
+}
@@ -0,0 +1,184 @@
+package canary
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/go-redis/redis/v8"
+
+	"flashcat.cloud/categraf/pkg/tls"
+)
+
+const defaultKafkaVersion = "2.0.0"
+
+// QueueStep publishes a message of the ${token} of the run, and waits until it's consumed
+type QueueStep struct {
+	stepConfig
+
+	// redis | kafka
+	Type string `toml:"type"`
+	// canary ${token} by default
+	Message string `toml:"message"`
+
+	// redis, the message is published to the channel by PUBLISH
+	Address  string `toml:"address"`
+	Username string `toml:"username"`
+	Password string `toml:"password"`
+	DB       int    `toml:"db"`
+	Channel  string `toml:"channel"`
+
+	// kafka, the message is consumed from the partition and offset it is produced to
+	Brokers      []string `toml:"brokers"`
+	Topic        string   `toml:"topic"`
+	KafkaVersion string   `toml:"kafka_version"`
+	SASLUsername string   `toml:"sasl_username"`
+	SASLPassword string   `toml:"sasl_password"`
+
+	tls.ClientConfig
+
+	redis  *redis.Client
+	sarama *sarama.Config
+}
+
+func (s *QueueStep) init(timeout time.Duration) error {
+	if s.Message == "" {
+		s.Message = "canary ${token}"
+	}
+
+	tlsConfig, err := s.ClientConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+
+	switch s.Type {
+	case "redis":
+		if s.Address == "" || s.Channel == "" {
+			return fmt.Errorf("address and channel are required")
+		}
+		s.redis = redis.NewClient(&redis.Options{
+			Addr:      s.Address,
+			Username:  s.Username,
+			Password:  s.Password,
+			DB:        s.DB,
+			TLSConfig: tlsConfig,
+		})
+	case "kafka":
+		if len(s.Brokers) == 0 || s.Topic == "" {
+			return fmt.Errorf("brokers and topic are required")
+		}
+		if s.KafkaVersion == "" {
+			s.KafkaVersion = defaultKafkaVersion
+		}
+
+		c := sarama.NewConfig()
+		c.ClientID = "categraf"
+		if c.Version, err = sarama.ParseKafkaVersion(s.KafkaVersion); err != nil {
+			return err
+		}
+		c.Net.DialTimeout = timeout
+		c.Producer.RequiredAcks = sarama.WaitForAll
+		c.Producer.Return.Successes = true
+		c.Consumer.Return.Errors = true
+		if s.SASLUsername != "" {
+			c.Net.SASL.Enable = true
+			c.Net.SASL.Mechanism = sarama.SASLTypePlaintext
+			c.Net.SASL.User = s.SASLUsername
+			c.Net.SASL.Password = s.SASLPassword
+		}
+		if tlsConfig != nil {
+			c.Net.TLS.Enable = true
+			c.Net.TLS.Config = tlsConfig
+		}
+		s.sarama = c
+	default:
+		return fmt.Errorf("invalid type %s, redis or kafka", s.Type)
+	}
+	return nil
+}
+
+func (s *QueueStep) close() {
+	if s.redis != nil {
+		s.redis.Close()
+	}
+}
+
+func (s *QueueStep) run(ctx context.Context, r *run) error {
+	message := r.expand(s.Message)
+	if s.redis != nil {
+		return s.runRedis(ctx, message)
+	}
+	return s.runKafka(ctx, message)
+}
+
+// runRedis subscribes the channel before publishing, the other messages of the channel are skipped
+func (s *QueueStep) runRedis(ctx context.Context, message string) error {
+	sub := s.redis.Subscribe(ctx, s.Channel)
+	defer sub.Close()
+	if _, err := sub.Receive(ctx); err != nil {
+		return fmt.Errorf("failed to subscribe: %v", err)
+	}
+
+	if err := s.redis.Publish(ctx, s.Channel, message).Err(); err != nil {
+		return fmt.Errorf("failed to publish: %v", err)
+	}
+
+	for {
+		m, err := sub.ReceiveMessage(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to consume: %v", err)
+		}
+		if m.Payload == message {
+			return nil
+		}
+	}
+}
+
+// runKafka connects to the brokers in each run, so that the flow fails if the brokers are not reachable
+func (s *QueueStep) runKafka(ctx context.Context, message string) error {
+	client, err := sarama.NewClient(s.Brokers, s.sarama)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	producer, err := sarama.NewSyncProducerFromClient(client)
+	if err != nil {
+		return err
+	}
+	defer producer.Close()
+
+	partition, offset, err := producer.SendMessage(&sarama.ProducerMessage{
+		Topic: s.Topic,
+		Value: sarama.StringEncoder(message),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to produce: %v", err)
+	}
+
+	consumer, err := sarama.NewConsumerFromClient(client)
+	if err != nil {
+		return err
+	}
+	defer consumer.Close()
+
+	pc, err := consumer.ConsumePartition(s.Topic, partition, offset)
+	if err != nil {
+		return fmt.Errorf("failed to consume: %v", err)
+	}
+	defer pc.Close()
+
+	for {
+		select {
+		case m := <-pc.Messages():
+			if string(m.Value) == message {
+				return nil
+			}
+		case err := <-pc.Errors():
+			return fmt.Errorf("failed to consume: %v", err)
+		case <-ctx.Done():
+			return fmt.Errorf("failed to consume: %v", ctx.Err())
+		}
+	}
+}
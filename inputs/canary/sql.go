@@ -0,0 +1,104 @@
+package canary
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	_ "github.com/denisenkom/go-mssqldb" // go-mssqldb initialization
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/jackc/pgx/v4/stdlib"
+)
+
+// the names of drivers registered to database/sql
+var drivers = map[string]string{
+	"mysql":     "mysql",
+	"postgres":  "pgx",
+	"sqlserver": "sqlserver",
+}
+
+// SQLStep writes the ${token} of the run and reads it back
+type SQLStep struct {
+	stepConfig
+
+	// mysql | postgres | sqlserver
+	Driver string `toml:"driver"`
+	DSN    string `toml:"dsn"`
+	// the statements run in order, e.g. INSERT INTO canary (token) VALUES ('${token}')
+	Write []string `toml:"write"`
+	// the first column of the first row is compared to expect, e.g. SELECT token FROM canary WHERE token = '${token}'
+	Read string `toml:"read"`
+	// ${token} by default
+	Expect string `toml:"expect"`
+	// the statements run after the step even if it failed, e.g. DELETE FROM canary WHERE token = '${token}'
+	Cleanup []string `toml:"cleanup"`
+
+	db *sql.DB
+}
+
+func (s *SQLStep) init() error {
+	driver, ok := drivers[s.Driver]
+	if !ok {
+		return fmt.Errorf("invalid driver %s, mysql, postgres or sqlserver", s.Driver)
+	}
+	if s.DSN == "" {
+		return fmt.Errorf("dsn is required")
+	}
+	if s.Read == "" {
+		return fmt.Errorf("read is required")
+	}
+	if s.Expect == "" {
+		s.Expect = "${token}"
+	}
+
+	// the connections are opened by the first run
+	db, err := sql.Open(driver, s.DSN)
+	if err != nil {
+		return err
+	}
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+	s.db = db
+	return nil
+}
+
+func (s *SQLStep) close() {
+	if s.db != nil {
+		s.db.Close()
+	}
+}
+
+func (s *SQLStep) run(ctx context.Context, r *run) error {
+	defer s.cleanup(r)
+
+	for _, stmt := range s.Write {
+		if _, err := s.db.ExecContext(ctx, r.expand(stmt)); err != nil {
+			return fmt.Errorf("failed to write: %v", err)
+		}
+	}
+
+	var value sql.NullString
+	if err := s.db.QueryRowContext(ctx, r.expand(s.Read)).Scan(&value); err != nil {
+		return fmt.Errorf("failed to read: %v", err)
+	}
+	if expect := r.expand(s.Expect); value.String != expect {
+		return fmt.Errorf("read %q, expected %q", value.String, expect)
+	}
+	return nil
+}
+
+// cleanup runs out of the deadline of the flow, so the rows are removed even if the flow timed out
+func (s *SQLStep) cleanup(r *run) {
+	if len(s.Cleanup) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+	defer cancel()
+	for _, stmt := range s.Cleanup {
+		if _, err := s.db.ExecContext(ctx, r.expand(stmt)); err != nil {
+			canaryLog.Warnf("failed to clean up canary step %v error: %v", s.Name, err)
+			return
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package cert_manager
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/pkg/metrics"
+	"flashcat.cloud/categraf/pkg/tls"
+	"flashcat.cloud/categraf/types"
+)
+
+var certManagerLog = logger.New("input.cert_manager")
+
+const (
+	inputName = "cert_manager"
+
+	metricPrefix = "certmanager_"
+
+	// the renewal time is moved forward once the certificate is renewed
+	renewalGrace = time.Hour
+)
+
+type CertManager struct {
+	config.PluginConfig
+	Instances []*Instance `toml:"instances"`
+}
+
+type Instance struct {
+	config.InstanceConfig
+
+	// the metrics of the controller, e.g. http://cert-manager.cert-manager:9402/metrics
+	URLs []string `toml:"urls"`
+	// cert_manager_healthy is 0 if a certificate expires in it
+	MinCertExpiry config.Duration `toml:"min_cert_expiry"`
+	Timeout       config.Duration `toml:"timeout"`
+	tls.ClientConfig
+
+	client *http.Client
+}
+
+type certificate struct {
+	ready     bool
+	expiry    float64
+	hasExpiry bool
+	renewal   float64
+}
+
+var _ inputs.SampleGatherer = new(Instance)
+var _ inputs.Input = new(CertManager)
+var _ inputs.InstancesGetter = new(CertManager)
+
+func init() {
+	inputs.Add(inputName, func() inputs.Input {
+		return &CertManager{}
+	})
+}
+
+func (c *CertManager) Clone() inputs.Input {
+	return &CertManager{}
+}
+
+func (c *CertManager) Name() string {
+	return inputName
+}
+
+func (c *CertManager) GetInstances() []inputs.Instance {
+	ret := make([]inputs.Instance, len(c.Instances))
+	for i := 0; i < len(c.Instances); i++ {
+		ret[i] = c.Instances[i]
+	}
+	return ret
+}
+
+func (ins *Instance) Init() error {
+	if len(ins.URLs) == 0 {
+		return types.ErrInstancesEmpty
+	}
+	if ins.Timeout <= 0 {
+		ins.Timeout = config.Duration(5 * time.Second)
+	}
+	if ins.MinCertExpiry <= 0 {
+		ins.MinCertExpiry = config.Duration(7 * 24 * time.Hour)
+	}
+
+	tlsConfig, err := ins.ClientConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+	ins.client = &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		},
+		Timeout: time.Duration(ins.Timeout),
+	}
+	return nil
+}
+
+func (ins *Instance) Gather(slist *types.SampleList) {
+	var wg sync.WaitGroup
+	for _, u := range ins.URLs {
+		wg.Add(1)
+		go func(u string) {
+			defer wg.Done()
+			ins.gatherURL(slist, u)
+		}(u)
+	}
+	wg.Wait()
+}
+
+func (ins *Instance) gatherURL(slist *types.SampleList, u string) {
+	tags := map[string]string{"url": u}
+
+	mfs, err := ins.scrape(u)
+	if err != nil {
+		certManagerLog.Errorf("failed to scrape cert-manager: %v error: %v", u, err)
+		slist.PushSample(inputName, "up", 0, tags)
+		slist.PushSample(inputName, "healthy", 0, tags)
+		return
+	}
+	slist.PushSample(inputName, "up", 1, tags)
+
+	healthy := ins.gatherCertificates(slist, mfs, tags)
+
+	for _, g := range metrics.SumBy(mfs[metricPrefix+"controller_sync_call_count"], "controller") {
+		slist.PushSample(inputName, "controller_syncs_total", g.Value, tags, g.Labels)
+	}
+	for _, g := range metrics.SumBy(mfs[metricPrefix+"controller_sync_error_count"], "controller") {
+		slist.PushSample(inputName, "controller_sync_errors_total", g.Value, tags, g.Labels)
+	}
+	// the requests by host and path are summed by status
+	for _, g := range metrics.SumBy(mfs[metricPrefix+"http_acme_client_request_count"], "status") {
+		slist.PushSample(inputName, "acme_requests_total", g.Value, tags, g.Labels)
+	}
+
+	slist.PushSample(inputName, "healthy", healthy, tags)
+}
+
+// gatherCertificates reports the readiness, expiry and renewal of each certificate, false if any
+// certificate is not ready, expires in min_cert_expiry, or is not renewed after the renewal time
+func (ins *Instance) gatherCertificates(slist *types.SampleList, mfs map[string]*dto.MetricFamily, tags map[string]string) bool {
+	certs := make(map[[2]string]*certificate)
+	get := func(labels map[string]string) *certificate {
+		key := [2]string{labels["namespace"], labels["name"]}
+		c, ok := certs[key]
+		if !ok {
+			c = &certificate{}
+			certs[key] = c
+		}
+		return c
+	}
+
+	// a series of each condition, the value of the current condition is 1
+	for _, g := range metrics.SumBy(mfs[metricPrefix+"certificate_ready_status"], "namespace", "name", "condition") {
+		c := get(g.Labels)
+		if g.Labels["condition"] == "True" && g.Value == 1 {
+			c.ready = true
+		}
+	}
+	for _, g := range metrics.SumBy(mfs[metricPrefix+"certificate_expiration_timestamp_seconds"], "namespace", "name") {
+		c := get(g.Labels)
+		c.expiry, c.hasExpiry = g.Value, true
+	}
+	for _, g := range metrics.SumBy(mfs[metricPrefix+"certificate_renewal_timestamp_seconds"], "namespace", "name") {
+		get(g.Labels).renewal = g.Value
+	}
+
+	now := time.Now()
+	var minExpiry float64
+	var hasExpiry bool
+	var ready, notReady float64
+	healthy := true
+	for key, c := range certs {
+		ctags := map[string]string{"namespace": key[0], "name": key[1]}
+		slist.PushSample(inputName, "certificate_ready", c.ready, tags, ctags)
+		if c.ready {
+			ready++
+		} else {
+			notReady++
+			healthy = false
+		}
+
+		if c.hasExpiry {
+			remaining := c.expiry - float64(now.Unix())
+			slist.PushSample(inputName, "certificate_expiry_seconds", remaining, tags, ctags)
+			if !hasExpiry || remaining < minExpiry {
+				minExpiry, hasExpiry = remaining, true
+			}
+		}
+
+		if c.renewal > 0 {
+			overdue := float64(now.Add(-renewalGrace).Unix()) > c.renewal
+			slist.PushSample(inputName, "certificate_renewal_overdue", overdue, tags, ctags)
+			if overdue {
+				healthy = false
+			}
+		}
+	}
+
+	slist.PushSample(inputName, "certificates", ready, tags, map[string]string{"state": "ready"})
+	slist.PushSample(inputName, "certificates", notReady, tags, map[string]string{"state": "not_ready"})
+	if hasExpiry {
+		slist.PushSample(inputName, "certificate_min_expiry_seconds", minExpiry, tags)
+		if minExpiry < time.Duration(ins.MinCertExpiry).Seconds() {
+			healthy = false
+		}
+	}
+	return healthy
+}
+
+func (ins *Instance) scrape(u string) (map[string]*dto.MetricFamily, error) {
+	resp, err := ins.client.Get(u)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		return nil, fmt.Errorf("returned HTTP status %s: %q", resp.Status, body)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return metrics.Parse(body, resp.Header)
+}
@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net"
 	"net/http"
 	"net/url"
@@ -15,11 +14,14 @@ import (
 
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/pkg/stringx"
 	"flashcat.cloud/categraf/pkg/tls"
 	"flashcat.cloud/categraf/types"
 )
 
+var clickhouseLog = logger.New("input.clickhouse")
+
 const inputName = "clickhouse"
 
 var defaultTimeout = 5 * time.Second
@@ -117,14 +119,14 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 	for _, server := range ins.Servers {
 		u, err := url.Parse(server)
 		if err != nil {
-			log.Println("E! failed to parse server url, error: ", err)
+			clickhouseLog.Errorf("failed to parse server url, error:  %v", err)
 			return
 		}
 		switch {
 		case ins.AutoDiscovery:
 			var conns []connect
 			if err := ins.execQuery(u, "SELECT cluster, shard_num, host_name FROM system.clusters "+ins.clusterIncludeExcludeFilter(), &conns); err != nil {
-				log.Println("E! failed to exec clickhouse query:", "SELECT cluster, shard_num, host_name FROM system.clusters "+ins.clusterIncludeExcludeFilter())
+				clickhouseLog.Errorf("failed to exec clickhouse query: %v", "SELECT cluster, shard_num, host_name FROM system.clusters "+ins.clusterIncludeExcludeFilter())
 				continue
 			}
 			for _, c := range conns {
@@ -159,13 +161,13 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 
 		for _, metricFunc := range metricsFuncs {
 			if err := metricFunc(slist, &connects[i]); err != nil {
-				log.Println("E! failed to exec  metrics Funcs error:", err)
+				clickhouseLog.Errorf("failed to exec  metrics Funcs error: %v", err)
 			}
 		}
 
 		for metric := range commonMetrics {
 			if err := ins.commonMetrics(slist, &connects[i], metric); err != nil {
-				log.Println("E! failed to exec query commonMetrics error:", err)
+				clickhouseLog.Errorf("failed to exec query commonMetrics error: %v", err)
 			}
 		}
 	}
@@ -0,0 +1,179 @@
+package clock
+
+import (
+	"math"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/toolkits/pkg/nux"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/types"
+)
+
+var clockLog = logger.New("input.clock")
+
+const inputName = "clock"
+
+const (
+	levelOK = iota
+	levelWarning
+	levelCritical
+)
+
+// Clock compares the local clock with ntp servers and the Date header of writers,
+// samples of a skewed host carry skewed timestamps, which corrupts every other metric
+type Clock struct {
+	config.PluginConfig
+	NTPServers     []string        `toml:"ntp_servers"`
+	CheckWriters   bool            `toml:"check_writers"`
+	Timeout        config.Duration `toml:"timeout"`
+	WarnOffset     config.Duration `toml:"warn_offset"`
+	CriticalOffset config.Duration `toml:"critical_offset"`
+
+	client *http.Client
+}
+
+func init() {
+	inputs.Add(inputName, func() inputs.Input {
+		return &Clock{}
+	})
+}
+
+func (c *Clock) Clone() inputs.Input {
+	return &Clock{}
+}
+
+func (c *Clock) Name() string {
+	return inputName
+}
+
+func (c *Clock) Init() error {
+	if len(c.NTPServers) == 0 && !c.CheckWriters {
+		return types.ErrInstancesEmpty
+	}
+
+	if c.Timeout == 0 {
+		c.Timeout = config.Duration(5 * time.Second)
+	}
+
+	if c.WarnOffset == 0 {
+		c.WarnOffset = config.Duration(time.Second)
+	}
+
+	if c.CriticalOffset == 0 {
+		c.CriticalOffset = config.Duration(10 * time.Second)
+	}
+
+	c.client = &http.Client{
+		Timeout: time.Duration(c.Timeout),
+		// the Date header of redirect response is good enough
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return nil
+}
+
+func (c *Clock) Gather(slist *types.SampleList) {
+	var (
+		maxOffset float64
+		checked   bool
+	)
+
+	for _, server := range c.NTPServers {
+		offset, rtt, err := ntpOffset(server)
+		tags := map[string]string{"server": server}
+		if err != nil {
+			clockLog.Errorf("failed to query ntp server: %v error: %v", server, err)
+			slist.PushSample(inputName, "ntp_up", 0, tags)
+			continue
+		}
+
+		slist.PushSample(inputName, "ntp_up", 1, tags)
+		slist.PushSample(inputName, "ntp_offset_seconds", offset.Seconds(), tags)
+		slist.PushSample(inputName, "ntp_rtt_seconds", rtt.Seconds(), tags)
+		maxOffset = math.Max(maxOffset, math.Abs(offset.Seconds()))
+		checked = true
+	}
+
+	if c.CheckWriters {
+		for _, w := range config.Config.Writers {
+			u, err := url.Parse(w.Url)
+			if err != nil {
+				continue
+			}
+
+			tags := map[string]string{"url": u.Redacted()}
+			offset, err := c.dateOffset(w.Url)
+			if err != nil {
+				clockLog.Errorf("failed to get Date header of writer: %v error: %v", u.Redacted(), err)
+				slist.PushSample(inputName, "writer_up", 0, tags)
+				continue
+			}
+
+			slist.PushSample(inputName, "writer_up", 1, tags)
+			slist.PushSample(inputName, "writer_offset_seconds", offset.Seconds(), tags)
+			maxOffset = math.Max(maxOffset, math.Abs(offset.Seconds()))
+			checked = true
+		}
+	}
+
+	if !checked {
+		return
+	}
+
+	level := levelOK
+	if maxOffset >= time.Duration(c.CriticalOffset).Seconds() {
+		level = levelCritical
+	} else if maxOffset >= time.Duration(c.WarnOffset).Seconds() {
+		level = levelWarning
+	}
+
+	slist.PushSample(inputName, "max_offset_seconds", maxOffset)
+	slist.PushSample(inputName, "skew_level", level)
+}
+
+// ntpOffset returns the offset of server clock to local clock and the round trip delay,
+// see https://en.wikipedia.org/wiki/Network_Time_Protocol
+func ntpOffset(server string) (time.Duration, time.Duration, error) {
+	org := time.Now()
+	receive, transmit, err := nux.NtpTwoTime(server)
+	if err != nil {
+		return 0, 0, err
+	}
+	dst := time.Now()
+
+	offset := (receive.Sub(org) + transmit.Sub(dst)) / 2
+	rtt := dst.Sub(org) - transmit.Sub(receive)
+	return offset, rtt, nil
+}
+
+// dateOffset returns the offset of the Date header of url to local clock,
+// Date is in seconds, so the server time is taken as the middle of the second
+func (c *Clock) dateOffset(target string) (time.Duration, error) {
+	req, err := http.NewRequest("HEAD", target, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	start := time.Now()
+	res, err := c.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	res.Body.Close()
+	end := time.Now()
+
+	// any status is fine, e.g. 405 of HEAD, the Date header is set anyway
+	date, err := http.ParseTime(res.Header.Get("Date"))
+	if err != nil {
+		return 0, err
+	}
+
+	local := start.Add(end.Sub(start) / 2)
+	return date.Add(500 * time.Millisecond).Sub(local), nil
+}
@@ -5,9 +5,9 @@ import (
 	"context"
 	_ "embed"
 	"fmt"
-	"log"
 	"net"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -22,12 +22,15 @@ import (
 	internalaws "flashcat.cloud/categraf/pkg/aws"
 	"flashcat.cloud/categraf/pkg/filter"
 	"flashcat.cloud/categraf/pkg/limiter"
+	"flashcat.cloud/categraf/pkg/logger"
 	internalProxy "flashcat.cloud/categraf/pkg/proxy"
 	"flashcat.cloud/categraf/pkg/stringx"
 	internalTypes "flashcat.cloud/categraf/types"
 	internalMetric "flashcat.cloud/categraf/types/metric"
 )
 
+var cloudwatchLog = logger.New("input.cloudwatch")
+
 //go:embed sample.conf
 var sampleConfig string
 
@@ -67,12 +70,22 @@ type (
 		RecentlyActive string          `toml:"recently_active"`
 		BatchSize      int             `toml:"batch_size"`
 
+		// DimensionMapping renames cloudwatch dimensions to tag keys, e.g. LoadBalancer -> lb
+		DimensionMapping map[string]string `toml:"dimension_mapping"`
+		// MaxQueriesPerGather caps the metric data queries of one gather, GetMetricData is billed per metric
+		MaxQueriesPerGather int `toml:"max_queries_per_gather"`
+
 		client          cloudwatchClient
 		statFilter      filter.Filter
 		metricCache     *metricCache
 		queryDimensions map[string]*map[string]string
 		windowStart     time.Time
 		windowEnd       time.Time
+		// the offset of the queries where the next gather starts if they exceed MaxQueriesPerGather
+		queryOffset int
+		// the end of the window last fetched of the queries by queryKey if they exceed MaxQueriesPerGather,
+		// the queries rotated out of the gathers are made from there
+		queryFetched map[string]time.Time
 
 		internalaws.CredentialConfig
 	}
@@ -111,7 +124,8 @@ func (*CloudWatch) SampleConfig() string {
 }
 
 func (ins *Instance) Init() error {
-	if ins.BatchSize == 0 {
+	// GetMetricData accepts at most 500 queries per request
+	if ins.BatchSize <= 0 || ins.BatchSize > 500 {
 		ins.BatchSize = 500
 	}
 	if ins.RateLimit == 0 {
@@ -157,7 +171,7 @@ func (cw *CloudWatch) GetInstances() []inputs.Instance {
 func (ins *Instance) Gather(slist *internalTypes.SampleList) {
 	filteredMetrics, err := getFilteredMetrics(ins)
 	if err != nil {
-		log.Println("E! filter metrics error,", err)
+		cloudwatchLog.Errorf("filter metrics error, %v", err)
 		return
 	}
 
@@ -166,7 +180,7 @@ func (ins *Instance) Gather(slist *internalTypes.SampleList) {
 	// Get all of the possible queries so we can send groups of 100.
 	queries := ins.getDataQueries(filteredMetrics)
 	if len(queries) == 0 {
-		log.Println("E! data queries length is 0")
+		cloudwatchLog.Errorf("data queries length is 0")
 		return
 	}
 
@@ -179,38 +193,56 @@ func (ins *Instance) Gather(slist *internalTypes.SampleList) {
 	rLock := sync.Mutex{}
 
 	results := map[string][]types.MetricDataResult{}
+	queries = ins.limitQueries(queries)
 
+	var requests, queried int64
 	for namespace, namespacedQueries := range queries {
-		var batches [][]types.MetricDataQuery
+		queried += int64(len(namespacedQueries))
 
-		for ins.BatchSize < len(namespacedQueries) {
-			namespacedQueries, batches = namespacedQueries[ins.BatchSize:], append(batches, namespacedQueries[0:ins.BatchSize:ins.BatchSize])
-		}
-		batches = append(batches, namespacedQueries)
-
-		for i := range batches {
-			wg.Add(1)
-			<-lmtr.C
-			go func(n string, inm []types.MetricDataQuery) {
-				defer wg.Done()
-				result, err := ins.gatherMetrics(ins.getDataInputs(inm))
-				if err != nil {
-					log.Println("E!", err)
-					return
-				}
+		for start, startQueries := range ins.groupByStart(namespace, namespacedQueries) {
+			var batches [][]types.MetricDataQuery
+
+			for ins.BatchSize < len(startQueries) {
+				startQueries, batches = startQueries[ins.BatchSize:], append(batches, startQueries[0:ins.BatchSize:ins.BatchSize])
+			}
+			batches = append(batches, startQueries)
+
+			for i := range batches {
+				requests++
+				wg.Add(1)
+				<-lmtr.C
+				go func(n string, start time.Time, inm []types.MetricDataQuery) {
+					defer wg.Done()
+					result, err := ins.gatherMetrics(ins.getDataInputs(start, inm))
+					if err != nil {
+						cloudwatchLog.Errorf("%v", err)
+						return
+					}
 
-				rLock.Lock()
-				results[n] = append(results[n], result...)
-				rLock.Unlock()
-			}(namespace, batches[i])
+					rLock.Lock()
+					results[n] = append(results[n], result...)
+					if ins.queryFetched != nil {
+						for _, q := range inm {
+							ins.queryFetched[queryKey(n, q)] = ins.windowEnd
+						}
+					}
+					rLock.Unlock()
+				}(namespace, start, batches[i])
+			}
 		}
 	}
 
 	wg.Wait()
 
+	// expose api usage, helps to estimate the cost of cloudwatch api
+	slist.PushSamples(inputName, map[string]interface{}{
+		"api_requests":    requests,
+		"queried_metrics": queried,
+	}, map[string]string{"region": ins.Region})
+
 	err = ins.aggregateMetrics(slist, results)
 	if err != nil {
-		log.Println("E! aggregate metrics error,", err)
+		cloudwatchLog.Errorf("aggregate metrics error, %v", err)
 	}
 }
 
@@ -362,7 +394,7 @@ func (ins *Instance) fetchNamespaceMetrics() []types.Metric {
 		for {
 			resp, err := ins.client.ListMetrics(context.Background(), params)
 			if err != nil {
-				log.Printf("E! failed to list metrics with namespace %s: %v", namespace, err)
+				cloudwatchLog.Errorf("failed to list metrics with namespace %s: %v", namespace, err)
 				// skip problem namespace on error and continue to next namespace
 				break
 			}
@@ -403,7 +435,7 @@ func (ins *Instance) getDataQueries(filteredMetrics []filteredMetric) map[string
 	for i, filtered := range filteredMetrics {
 		for j, metric := range filtered.metrics {
 			id := strconv.Itoa(j) + "_" + strconv.Itoa(i)
-			dimension := ctod(metric.Dimensions)
+			dimension := ins.ctod(metric.Dimensions)
 			if filtered.statFilter.Match("average") {
 				ins.queryDimensions["average_"+id] = dimension
 				dataQueries[*metric.Namespace] = append(dataQueries[*metric.Namespace], types.MetricDataQuery{
@@ -469,7 +501,7 @@ func (ins *Instance) getDataQueries(filteredMetrics []filteredMetric) map[string
 
 	if len(dataQueries) == 0 {
 		if config.Config.DebugMode {
-			log.Println("D! no metrics found to collect")
+			cloudwatchLog.Debugf("no metrics found to collect")
 		}
 		return nil
 	}
@@ -525,7 +557,9 @@ func (ins *Instance) aggregateMetrics(
 			tags := map[string]string{}
 
 			if dimensions, ok := ins.queryDimensions[*result.Id]; ok {
-				tags = *dimensions
+				for k, v := range *dimensions {
+					tags[k] = v
+				}
 			}
 			tags["region"] = ins.Region
 			tags["namespace"] = ns
@@ -574,17 +608,108 @@ func snakeCase(s string) string {
 }
 
 // ctod converts cloudwatch dimensions to regular dimensions.
-func ctod(cDimensions []types.Dimension) *map[string]string {
+func (ins *Instance) ctod(cDimensions []types.Dimension) *map[string]string {
 	dimensions := map[string]string{}
 	for i := range cDimensions {
-		dimensions[snakeCase(*cDimensions[i].Name)] = *cDimensions[i].Value
+		name := *cDimensions[i].Name
+		if key, has := ins.DimensionMapping[name]; has {
+			dimensions[key] = *cDimensions[i].Value
+			continue
+		}
+		dimensions[snakeCase(name)] = *cDimensions[i].Value
 	}
 	return &dimensions
 }
 
-func (ins *Instance) getDataInputs(dataQueries []types.MetricDataQuery) *cwClient.GetMetricDataInput {
+// limitQueries truncates queries to MaxQueriesPerGather, the queries of namespaces in sorted order are taken in turn
+// from where the last gather stopped, so every query is made once in a few gathers
+func (ins *Instance) limitQueries(queries map[string][]types.MetricDataQuery) map[string][]types.MetricDataQuery {
+	if ins.MaxQueriesPerGather <= 0 {
+		return queries
+	}
+
+	namespaces := make([]string, 0, len(queries))
+	total := 0
+	for ns := range queries {
+		namespaces = append(namespaces, ns)
+		total += len(queries[ns])
+	}
+
+	if total <= ins.MaxQueriesPerGather {
+		return queries
+	}
+
+	cloudwatchLog.Warnf("cloudwatch queries(%d) exceed max_queries_per_gather(%d), the rest are queried by the next gathers", total, ins.MaxQueriesPerGather)
+
+	sort.Strings(namespaces)
+	type query struct {
+		namespace string
+		query     types.MetricDataQuery
+	}
+	all := make([]query, 0, total)
+	// the queries gone from the metrics listed are forgotten
+	fetched := make(map[string]time.Time, total)
+	for _, ns := range namespaces {
+		for _, q := range queries[ns] {
+			all = append(all, query{namespace: ns, query: q})
+			key := queryKey(ns, q)
+			if t, has := ins.queryFetched[key]; has {
+				fetched[key] = t
+			}
+		}
+	}
+	ins.queryFetched = fetched
+
+	ret := make(map[string][]types.MetricDataQuery, len(queries))
+	start := ins.queryOffset % total
+	for i := 0; i < ins.MaxQueriesPerGather; i++ {
+		q := all[(start+i)%total]
+		ret[q.namespace] = append(ret[q.namespace], q.query)
+	}
+	ins.queryOffset = (start + ins.MaxQueriesPerGather) % total
+	return ret
+}
+
+// groupByStart groups the queries by the start of their windows, the queries rotated out of the last gathers
+// by MaxQueriesPerGather start where they were last fetched, so the data of the gathers skipped are not missed.
+// The queries never fetched start at the window of this gather, like the first gather
+func (ins *Instance) groupByStart(namespace string, queries []types.MetricDataQuery) map[time.Time][]types.MetricDataQuery {
+	if len(ins.queryFetched) == 0 {
+		return map[time.Time][]types.MetricDataQuery{ins.windowStart: queries}
+	}
+
+	ret := map[time.Time][]types.MetricDataQuery{}
+	for _, q := range queries {
+		start := ins.windowStart
+		if t, has := ins.queryFetched[queryKey(namespace, q)]; has && t.Before(start) {
+			start = t
+		}
+		ret[start] = append(ret[start], q)
+	}
+	return ret
+}
+
+// queryKey identifies the query across the rebuilds of the metric cache, ids of the queries are the indexes of
+// the metrics listed, which change when the metrics come and go
+func queryKey(namespace string, q types.MetricDataQuery) string {
+	var b strings.Builder
+	b.WriteString(namespace)
+	b.WriteByte('|')
+	b.WriteString(aws.ToString(q.MetricStat.Metric.MetricName))
+	b.WriteByte('|')
+	b.WriteString(aws.ToString(q.MetricStat.Stat))
+	for _, d := range q.MetricStat.Metric.Dimensions {
+		b.WriteByte('|')
+		b.WriteString(aws.ToString(d.Name))
+		b.WriteByte('=')
+		b.WriteString(aws.ToString(d.Value))
+	}
+	return b.String()
+}
+
+func (ins *Instance) getDataInputs(start time.Time, dataQueries []types.MetricDataQuery) *cwClient.GetMetricDataInput {
 	return &cwClient.GetMetricDataInput{
-		StartTime:         aws.Time(ins.windowStart),
+		StartTime:         aws.Time(start),
 		EndTime:           aws.Time(ins.windowEnd),
 		MetricDataQueries: dataQueries,
 	}
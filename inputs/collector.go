@@ -2,15 +2,17 @@ package inputs
 
 import (
 	"errors"
-	"log"
 
 	"github.com/prometheus/client_golang/prometheus"
 	dto "github.com/prometheus/client_model/go"
 
 	pp "flashcat.cloud/categraf/parser/prometheus"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/types"
 )
 
+var inputsLog = logger.New("inputs")
+
 const capMetricChan = 1000
 
 var parser = pp.EmptyParser()
@@ -33,14 +35,14 @@ func Collect(e prometheus.Collector, slist *types.SampleList, constLabels ...map
 
 		desc := metric.Desc()
 		if desc.Err() != nil {
-			log.Println("E! got invalid metric:", desc.Name(), desc.Err())
+			inputsLog.Errorf("got invalid metric: %v %v", desc.Name(), desc.Err())
 			continue
 		}
 
 		dtoMetric := &dto.Metric{}
 		err := metric.Write(dtoMetric)
 		if err != nil {
-			log.Println("E! failed to write metric:", desc.String())
+			inputsLog.Errorf("failed to write metric: %v", desc.String())
 			continue
 		}
 
@@ -4,7 +4,6 @@
 package conntrack
 
 import (
-	"log"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -12,9 +11,12 @@ import (
 
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/types"
 )
 
+var conntrackLog = logger.New("input.conntrack")
+
 const inputName = "conntrack"
 
 type Conntrack struct {
@@ -86,20 +88,20 @@ func (c *Conntrack) Gather(slist *types.SampleList) {
 
 			contents, err := os.ReadFile(fName)
 			if err != nil {
-				log.Println("E! failed to read file:", fName, "error:", err)
+				conntrackLog.Errorf("failed to read file: %v error: %v", fName, err)
 				continue
 			}
 
 			v := strings.TrimSpace(string(contents))
 			fields[metricKey], err = strconv.ParseFloat(v, 64)
 			if err != nil {
-				log.Println("E! failed to parse metric, expected number but found:", v, "error:", err)
+				conntrackLog.Errorf("failed to parse metric, expected number but found: %v error: %v", v, err)
 			}
 		}
 	}
 
 	if len(fields) == 0 && !c.Quiet {
-		log.Println("E! Conntrack input failed to collect metrics. Is the conntrack kernel module loaded?")
+		conntrackLog.Errorf("Conntrack input failed to collect metrics. Is the conntrack kernel module loaded?")
 	}
 
 	slist.PushSamples("conntrack", fields)
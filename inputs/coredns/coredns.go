@@ -0,0 +1,230 @@
+package coredns
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/pkg/metrics"
+	"flashcat.cloud/categraf/pkg/tls"
+	"flashcat.cloud/categraf/types"
+)
+
+var corednsLog = logger.New("input.coredns")
+
+const inputName = "coredns"
+
+type CoreDNS struct {
+	config.PluginConfig
+	Instances []*Instance `toml:"instances"`
+}
+
+type Instance struct {
+	config.InstanceConfig
+
+	// the prometheus plugin of coredns, e.g. http://coredns:9153/metrics
+	URLs []string `toml:"urls"`
+	// coredns_healthy is 0 if the ratio of servfail responses since the last gather is above it
+	MaxServfailRatio float64         `toml:"max_servfail_ratio"`
+	Timeout          config.Duration `toml:"timeout"`
+	tls.ClientConfig
+
+	client *http.Client
+	deltas *metrics.Deltas
+}
+
+var _ inputs.SampleGatherer = new(Instance)
+var _ inputs.Input = new(CoreDNS)
+var _ inputs.InstancesGetter = new(CoreDNS)
+
+func init() {
+	inputs.Add(inputName, func() inputs.Input {
+		return &CoreDNS{}
+	})
+}
+
+func (c *CoreDNS) Clone() inputs.Input {
+	return &CoreDNS{}
+}
+
+func (c *CoreDNS) Name() string {
+	return inputName
+}
+
+func (c *CoreDNS) GetInstances() []inputs.Instance {
+	ret := make([]inputs.Instance, len(c.Instances))
+	for i := 0; i < len(c.Instances); i++ {
+		ret[i] = c.Instances[i]
+	}
+	return ret
+}
+
+func (ins *Instance) Init() error {
+	if len(ins.URLs) == 0 {
+		return types.ErrInstancesEmpty
+	}
+	if ins.Timeout <= 0 {
+		ins.Timeout = config.Duration(5 * time.Second)
+	}
+	if ins.MaxServfailRatio <= 0 {
+		ins.MaxServfailRatio = 0.05
+	}
+	ins.deltas = metrics.NewDeltas()
+
+	tlsConfig, err := ins.ClientConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+	ins.client = &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		},
+		Timeout: time.Duration(ins.Timeout),
+	}
+	return nil
+}
+
+func (ins *Instance) Gather(slist *types.SampleList) {
+	var wg sync.WaitGroup
+	for _, u := range ins.URLs {
+		wg.Add(1)
+		go func(u string) {
+			defer wg.Done()
+			ins.gatherURL(slist, u)
+		}(u)
+	}
+	wg.Wait()
+}
+
+func (ins *Instance) gatherURL(slist *types.SampleList, u string) {
+	tags := map[string]string{"url": u}
+
+	mfs, err := ins.scrape(u)
+	if err != nil {
+		corednsLog.Errorf("failed to scrape coredns: %v error: %v", u, err)
+		slist.PushSample(inputName, "up", 0, tags)
+		slist.PushSample(inputName, "healthy", 0, tags)
+		return
+	}
+	slist.PushSample(inputName, "up", 1, tags)
+
+	for _, g := range metrics.SumBy(mfs["coredns_dns_requests_total"], "server", "zone") {
+		slist.PushSample(inputName, "requests_total", g.Value, tags, g.Labels)
+	}
+	for _, g := range metrics.SumBy(mfs["coredns_dns_responses_total"], "server", "zone", "rcode") {
+		slist.PushSample(inputName, "responses_total", g.Value, tags, g.Labels)
+	}
+	for _, g := range metrics.SumBy(mfs["coredns_dns_request_duration_seconds"], "server", "zone") {
+		slist.PushSample(inputName, "request_duration_seconds_count", g.Value, tags, g.Labels)
+		slist.PushSample(inputName, "request_duration_seconds_sum", g.Sum, tags, g.Labels)
+	}
+
+	for _, g := range metrics.SumBy(mfs["coredns_cache_entries"], "server", "type") {
+		slist.PushSample(inputName, "cache_entries", g.Value, tags, g.Labels)
+	}
+	for _, g := range metrics.SumBy(mfs["coredns_cache_hits_total"], "server") {
+		slist.PushSample(inputName, "cache_hits_total", g.Value, tags, g.Labels)
+	}
+	for _, g := range metrics.SumBy(mfs["coredns_cache_misses_total"], "server") {
+		slist.PushSample(inputName, "cache_misses_total", g.Value, tags, g.Labels)
+	}
+
+	ins.gatherForward(slist, mfs, tags)
+
+	panics := metrics.Sum(mfs["coredns_panics_total"])
+	slist.PushSample(inputName, "panics_total", panics, tags)
+	if mf, ok := mfs["coredns_reload_failed_total"]; ok {
+		slist.PushSample(inputName, "reload_failed_total", metrics.Sum(mf), tags)
+	}
+
+	ins.gatherSummary(slist, mfs, panics, tags)
+}
+
+// gatherForward reports the requests to upstreams of the forward plugin, which are the metrics
+// of the proxy plugin since coredns 1.11
+func (ins *Instance) gatherForward(slist *types.SampleList, mfs map[string]*dto.MetricFamily, tags map[string]string) {
+	if mf, ok := mfs["coredns_forward_requests_total"]; ok {
+		for _, g := range metrics.SumBy(mf, "to") {
+			slist.PushSample(inputName, "forward_requests_total", g.Value, tags, g.Labels)
+		}
+	}
+	for _, g := range metrics.SumBy(mfs["coredns_proxy_request_duration_seconds"], "proxy_name", "to") {
+		if g.Labels["proxy_name"] == "forward" {
+			slist.PushSample(inputName, "forward_requests_total", g.Value, tags, map[string]string{"to": g.Labels["to"]})
+		}
+	}
+
+	if mf, ok := mfs["coredns_forward_healthcheck_failures_total"]; ok {
+		for _, g := range metrics.SumBy(mf, "to") {
+			slist.PushSample(inputName, "forward_healthcheck_failures_total", g.Value, tags, g.Labels)
+		}
+	}
+	for _, g := range metrics.SumBy(mfs["coredns_proxy_healthcheck_failures_total"], "proxy_name", "to") {
+		if g.Labels["proxy_name"] == "forward" {
+			slist.PushSample(inputName, "forward_healthcheck_failures_total", g.Value, tags, map[string]string{"to": g.Labels["to"]})
+		}
+	}
+}
+
+// gatherSummary reports the ratios since the last gather, and coredns_healthy which is 0 if coredns
+// panicked or the ratio of servfail is above max_servfail_ratio
+func (ins *Instance) gatherSummary(slist *types.SampleList, mfs map[string]*dto.MetricFamily, panics float64, tags map[string]string) {
+	u := tags["url"]
+	healthy := true
+
+	if delta, ok := ins.deltas.Delta(u+"/panics", panics); ok && delta > 0 {
+		healthy = false
+	}
+
+	var responses, servfails float64
+	for _, g := range metrics.SumBy(mfs["coredns_dns_responses_total"], "rcode") {
+		responses += g.Value
+		if g.Labels["rcode"] == "SERVFAIL" {
+			servfails += g.Value
+		}
+	}
+	dResponses, ok1 := ins.deltas.Delta(u+"/responses", responses)
+	dServfails, ok2 := ins.deltas.Delta(u+"/servfails", servfails)
+	if ok1 && ok2 && dResponses > 0 {
+		ratio := dServfails / dResponses
+		slist.PushSample(inputName, "servfail_ratio", ratio, tags)
+		if ratio > ins.MaxServfailRatio {
+			healthy = false
+		}
+	}
+
+	dHits, ok1 := ins.deltas.Delta(u+"/hits", metrics.Sum(mfs["coredns_cache_hits_total"]))
+	dMisses, ok2 := ins.deltas.Delta(u+"/misses", metrics.Sum(mfs["coredns_cache_misses_total"]))
+	if ok1 && ok2 && dHits+dMisses > 0 {
+		slist.PushSample(inputName, "cache_hit_ratio", dHits/(dHits+dMisses), tags)
+	}
+
+	slist.PushSample(inputName, "healthy", healthy, tags)
+}
+
+func (ins *Instance) scrape(u string) (map[string]*dto.MetricFamily, error) {
+	resp, err := ins.client.Get(u)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		return nil, fmt.Errorf("returned HTTP status %s: %q", resp.Status, body)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return metrics.Parse(body, resp.Header)
+}
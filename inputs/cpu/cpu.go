@@ -1,17 +1,25 @@
 package cpu
 
 import (
-	"log"
+	"time"
 
 	cpuUtil "github.com/shirou/gopsutil/v3/cpu"
 
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
 	"flashcat.cloud/categraf/inputs/system"
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/pkg/state"
 	"flashcat.cloud/categraf/types"
 )
 
-const inputName = "cpu"
+var cpuLog = logger.New("input.cpu")
+
+const (
+	inputName   = "cpu"
+	stateKey    = "last_stats"
+	stateMaxAge = 5 * time.Minute
+)
 
 type CPUStats struct {
 	ps        system.PS
@@ -42,10 +50,16 @@ func (c *CPUStats) Name() string {
 func (c *CPUStats) Gather(slist *types.SampleList) {
 	times, err := c.ps.CPUTimes(c.CollectPerCPU, true)
 	if err != nil {
-		log.Println("E! failed to get cpu metrics:", err)
+		cpuLog.Errorf("failed to get cpu metrics: %v", err)
 		return
 	}
 
+	if c.lastStats == nil {
+		// restore the last stats persisted before restart, so usage can be calculated in the 1st gather
+		c.lastStats = make(map[string]cpuUtil.TimesStat)
+		state.Get(inputName, stateKey, &c.lastStats, stateMaxAge)
+	}
+
 	for _, cts := range times {
 		tags := map[string]string{
 			"cpu": cts.CPU,
@@ -70,7 +84,7 @@ func (c *CPUStats) Gather(slist *types.SampleList) {
 		totalDelta := total - lastTotal
 
 		if totalDelta < 0 {
-			log.Println("W! current total CPU time is less than previous total CPU time")
+			cpuLog.Warnf("current total CPU time is less than previous total CPU time")
 			break
 		}
 
@@ -99,6 +113,10 @@ func (c *CPUStats) Gather(slist *types.SampleList) {
 	for _, cts := range times {
 		c.lastStats[cts.CPU] = cts
 	}
+
+	if err = state.Put(inputName, stateKey, c.lastStats); err != nil {
+		cpuLog.Warnf("failed to save cpu stats: %v", err)
+	}
 }
 
 func totalCPUTime(t cpuUtil.TimesStat) float64 {
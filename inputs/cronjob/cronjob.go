@@ -0,0 +1,140 @@
+package cronjob
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/cronexpr"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/types"
+)
+
+const (
+	inputName = "cronjob"
+
+	defaultGrace = 5 * time.Minute
+)
+
+// Cronjob reports the runs of jobs, reported to /api/push/cronjob by the jobs
+// or by categraf -cronjob wrapping the jobs, the jobs configured are checked for missed runs
+type Cronjob struct {
+	config.PluginConfig
+	Jobs []*Job `toml:"jobs"`
+}
+
+type Job struct {
+	Name string `toml:"name"`
+	// cron expression, e.g. "0 3 * * *", or period of runs
+	Schedule string          `toml:"schedule"`
+	Period   config.Duration `toml:"period"`
+	// the run is missed if not started in grace after the time expected
+	Grace config.Duration `toml:"grace"`
+
+	expr *cronexpr.Expression
+}
+
+func init() {
+	inputs.Add(inputName, func() inputs.Input {
+		return &Cronjob{}
+	})
+}
+
+func (c *Cronjob) Clone() inputs.Input {
+	return &Cronjob{}
+}
+
+func (c *Cronjob) Name() string {
+	return inputName
+}
+
+func (c *Cronjob) Init() error {
+	for _, job := range c.Jobs {
+		if !jobNameRegexp.MatchString(job.Name) {
+			return fmt.Errorf("invalid job name: %q", job.Name)
+		}
+
+		if job.Schedule == "" && job.Period <= 0 {
+			return fmt.Errorf("schedule or period of job %s is required", job.Name)
+		}
+
+		if job.Schedule != "" {
+			expr, err := cronexpr.Parse(job.Schedule)
+			if err != nil {
+				return fmt.Errorf("failed to parse schedule of job %s: %v", job.Name, err)
+			}
+			job.expr = expr
+		}
+
+		if job.Grace <= 0 {
+			job.Grace = config.Duration(defaultGrace)
+		}
+	}
+	return nil
+}
+
+// expected returns the time the run after last is expected to start
+func (j *Job) expected(last time.Time) time.Time {
+	if j.expr != nil {
+		return j.expr.Next(last)
+	}
+	return last.Add(time.Duration(j.Period))
+}
+
+func (c *Cronjob) Gather(slist *types.SampleList) {
+	states, since := snapshot()
+	now := time.Now()
+
+	for _, job := range c.Jobs {
+		s := states[job.Name]
+		delete(states, job.Name)
+
+		last := s.lastStart
+		if last.Before(since) {
+			last = since
+		}
+
+		expected := job.expected(last)
+		missed := 0
+		if !s.running && now.After(expected.Add(time.Duration(job.Grace))) {
+			missed = 1
+		}
+
+		tags := map[string]string{"job": job.Name}
+		slist.PushSample(inputName, "missed", missed, tags)
+		slist.PushSample(inputName, "next_run_timestamp_seconds", expected.Unix(), tags)
+		gatherState(slist, &s, now, tags)
+	}
+
+	// the jobs reported but not configured
+	for name, s := range states {
+		gatherState(slist, &s, now, map[string]string{"job": name})
+	}
+}
+
+func gatherState(slist *types.SampleList, s *jobState, now time.Time, tags map[string]string) {
+	running := 0
+	if s.running {
+		running = 1
+		slist.PushSample(inputName, "running_seconds", now.Sub(s.lastStart).Seconds(), tags)
+	}
+	slist.PushSample(inputName, "running", running, tags)
+
+	slist.PushSample(inputName, "runs_total", s.successes, tags, map[string]string{"status": "success"})
+	slist.PushSample(inputName, "runs_total", s.failures, tags, map[string]string{"status": "failure"})
+
+	if !s.lastStart.IsZero() {
+		slist.PushSample(inputName, "last_start_timestamp_seconds", s.lastStart.Unix(), tags)
+	}
+
+	if !s.lastFinish.IsZero() {
+		slist.PushSample(inputName, "last_finish_timestamp_seconds", s.lastFinish.Unix(), tags)
+		slist.PushSample(inputName, "last_duration_seconds", s.lastDuration.Seconds(), tags)
+		slist.PushSample(inputName, "last_exit_code", s.lastExitCode, tags)
+	}
+
+	if !s.lastSuccess.IsZero() {
+		slist.PushSample(inputName, "last_success_timestamp_seconds", s.lastSuccess.Unix(), tags)
+	}
+}
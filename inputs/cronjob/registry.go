@@ -0,0 +1,109 @@
+package cronjob
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// the jobs are reported by api of any name, limit them in case of a typo in loop
+const maxJobs = 1000
+
+var jobNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9_.:-]{1,128}$`)
+
+type jobState struct {
+	running      bool
+	lastStart    time.Time
+	lastFinish   time.Time
+	lastSuccess  time.Time
+	lastDuration time.Duration
+	lastExitCode int
+	successes    float64
+	failures     float64
+}
+
+// the states outlive the input, which is recreated on reload
+var registry = struct {
+	sync.Mutex
+	jobs map[string]*jobState
+	// runs missed before categraf started are unknown
+	since time.Time
+}{jobs: make(map[string]*jobState), since: time.Now()}
+
+func getState(name string) (*jobState, error) {
+	if !jobNameRegexp.MatchString(name) {
+		return nil, fmt.Errorf("invalid job name: %q", name)
+	}
+
+	s, has := registry.jobs[name]
+	if !has {
+		if len(registry.jobs) >= maxJobs {
+			return nil, fmt.Errorf("too many jobs, max: %d", maxJobs)
+		}
+		s = &jobState{}
+		registry.jobs[name] = s
+	}
+	return s, nil
+}
+
+// Start records the start of a run of job name
+func Start(name string) error {
+	registry.Lock()
+	defer registry.Unlock()
+
+	s, err := getState(name)
+	if err != nil {
+		return err
+	}
+
+	s.running = true
+	s.lastStart = time.Now()
+	return nil
+}
+
+// Finish records the end of a run of job name, the duration is
+// measured since the start if reported, or duration if it is positive
+func Finish(name string, exitCode int, duration time.Duration) error {
+	registry.Lock()
+	defer registry.Unlock()
+
+	s, err := getState(name)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if duration <= 0 && s.running {
+		duration = now.Sub(s.lastStart)
+	}
+
+	// a finish without start counts as a run started duration ago
+	if !s.running {
+		s.lastStart = now.Add(-duration)
+	}
+
+	s.running = false
+	s.lastFinish = now
+	s.lastDuration = duration
+	s.lastExitCode = exitCode
+	if exitCode == 0 {
+		s.lastSuccess = now
+		s.successes++
+	} else {
+		s.failures++
+	}
+	return nil
+}
+
+// snapshot returns the copies of states, so that gather does not block reports
+func snapshot() (map[string]jobState, time.Time) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	states := make(map[string]jobState, len(registry.jobs))
+	for name, s := range registry.jobs {
+		states[name] = *s
+	}
+	return states, registry.since
+}
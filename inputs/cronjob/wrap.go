@@ -0,0 +1,87 @@
+package cronjob
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"flashcat.cloud/categraf/config"
+)
+
+// Wrap runs the command and reports the start and finish of job name to the http api of
+// the categraf running, returns the exit code of the command, e.g. in crontab:
+// 0 3 * * * /opt/categraf/categraf -cronjob backup -- /usr/local/bin/backup.sh
+func Wrap(name string, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "E! command of cronjob is required")
+		return 2
+	}
+
+	base, err := config.Config.HTTP.LocalURL()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "E! failed to report cronjob:", err)
+	}
+
+	// the job runs even if categraf is down, reporting failures are printed only
+	report := func(event string, query url.Values) {
+		if base == "" {
+			return
+		}
+		if err := post(base+"/api/push/cronjob/"+url.PathEscape(name)+"/"+event, query); err != nil {
+			fmt.Fprintln(os.Stderr, "E! failed to report cronjob:", err)
+		}
+	}
+
+	report("start", nil)
+
+	start := time.Now()
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	code := 0
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			code = exitErr.ExitCode()
+		} else {
+			fmt.Fprintln(os.Stderr, "E! failed to run cronjob:", err)
+			code = 127
+		}
+	}
+
+	report(strconv.Itoa(code), url.Values{"duration": []string{time.Since(start).String()}})
+	return code
+}
+
+func post(target string, query url.Values) error {
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	client := &http.Client{
+		Timeout: 5 * time.Second,
+		Transport: &http.Transport{
+			// the certificate of categraf is rarely issued for 127.0.0.1
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+
+	res, err := client.Post(target, "text/plain", nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode/100 != 2 {
+		return fmt.Errorf("status code: %d", res.StatusCode)
+	}
+	return nil
+}
@@ -1,16 +1,19 @@
 package disk
 
 import (
-	"log"
+	"runtime"
 	"strings"
 
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
 	"flashcat.cloud/categraf/inputs/system"
 	"flashcat.cloud/categraf/pkg/choice"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/types"
 )
 
+var diskLog = logger.New("input.disk")
+
 const inputName = "disk"
 
 type DiskStats struct {
@@ -43,11 +46,15 @@ func (s *DiskStats) Name() string {
 func (s *DiskStats) Gather(slist *types.SampleList) {
 	disks, partitions, err := s.ps.DiskUsage(s.MountPoints, s.IgnoreFS)
 	if err != nil {
-		log.Println("E! failed to get disk usage:", err)
+		diskLog.Errorf("failed to get disk usage: %v", err)
 		return
 	}
 
 	for i, du := range disks {
+		if isDarwinSystemVolume(partitions[i].Mountpoint, partitions[i].Opts) {
+			continue
+		}
+
 		if du.DeviceError == 1 {
 			tags := map[string]string{
 				"path":   du.Path,
@@ -79,7 +86,11 @@ func (s *DiskStats) Gather(slist *types.SampleList) {
 			"mode":   mountOpts.Mode(),
 		}
 		var usedPercent float64
-		if du.Used+du.Free > 0 {
+		if runtime.GOOS == "darwin" && du.Fstype == "apfs" {
+			// the volumes of an apfs container share the free space, so the usage of
+			// the container is what matters, instead of the little used by the volume
+			usedPercent = float64(du.Total-du.Free) / float64(du.Total) * 100
+		} else if du.Used+du.Free > 0 {
 			usedPercent = float64(du.Used) /
 				(float64(du.Used) + float64(du.Free)) * 100
 		}
@@ -99,6 +110,12 @@ func (s *DiskStats) Gather(slist *types.SampleList) {
 	}
 }
 
+// isDarwinSystemVolume reports the volumes of macOS hidden from the finder, e.g. /System/Volumes/VM
+// and Preboot, which are in the same apfs container with the root and data volumes
+func isDarwinSystemVolume(mountpoint string, opts []string) bool {
+	return runtime.GOOS == "darwin" && MountOptions(opts).exists("nobrowse") && mountpoint != "/System/Volumes/Data"
+}
+
 type MountOptions []string
 
 func (opts MountOptions) Mode() string {
@@ -2,15 +2,17 @@ package diskio
 
 import (
 	"fmt"
-	"log"
 
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
 	"flashcat.cloud/categraf/inputs/system"
 	"flashcat.cloud/categraf/pkg/filter"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/types"
 )
 
+var diskioLog = logger.New("input.diskio")
+
 const inputName = "diskio"
 
 type DiskIO struct {
@@ -61,7 +63,7 @@ func (d *DiskIO) Gather(slist *types.SampleList) {
 
 	diskio, err := d.ps.DiskIO(devices)
 	if err != nil {
-		log.Println("E! failed to get disk io:", err)
+		diskioLog.Errorf("failed to get disk io: %v", err)
 		return
 	}
 
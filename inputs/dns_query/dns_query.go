@@ -2,7 +2,6 @@ package dns_query
 
 import (
 	"fmt"
-	"log"
 	"net"
 	"os"
 	"strconv"
@@ -11,10 +10,13 @@ import (
 
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/types"
 	"github.com/miekg/dns"
 )
 
+var dnsQueryLog = logger.New("input.dns_query")
+
 const inputName = "dns_query"
 
 type ResultType uint64
@@ -84,7 +86,7 @@ func (ins *Instance) Init() error {
 
 		config, err := dns.ClientConfigFromFile(resolvPath)
 		if err != nil {
-			log.Println("E! failed to detect local dns server:", err)
+			dnsQueryLog.Errorf("failed to detect local dns server: %v", err)
 			return types.ErrInstancesEmpty
 		}
 
@@ -145,7 +147,7 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 					setResult(Timeout, fields)
 				} else if err != nil {
 					setResult(Error, fields)
-					log.Println("E!", err)
+					dnsQueryLog.Errorf("%v", err)
 				}
 
 				slist.PushSamples("dns_query", fields, tags)
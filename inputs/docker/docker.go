@@ -6,7 +6,6 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"strings"
 	"sync"
 	"time"
@@ -20,10 +19,13 @@ import (
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/swarm"
 
+	"flashcat.cloud/categraf/pkg/logger"
 	tlsx "flashcat.cloud/categraf/pkg/tls"
 	itypes "flashcat.cloud/categraf/types"
 )
 
+var dockerLog = logger.New("input.docker")
+
 const inputName = "docker"
 
 // KB, MB, GB, TB, PB...human friendly
@@ -140,7 +142,7 @@ func (ins *Instance) Gather(slist *itypes.SampleList) {
 		c, err := ins.getNewClient()
 		if err != nil {
 			slist.PushSample("docker", "up", 0)
-			log.Println("E! failed to new docker client:", err)
+			dockerLog.Errorf("failed to new docker client: %v", err)
 			return
 		}
 		ins.client = c
@@ -150,7 +152,7 @@ func (ins *Instance) Gather(slist *itypes.SampleList) {
 
 	if err := ins.gatherInfo(slist); err != nil {
 		slist.PushSample("docker", "up", 0)
-		log.Println("E! failed to gather docker info:", err)
+		dockerLog.Errorf("failed to gather docker info: %v", err)
 		return
 	}
 
@@ -181,11 +183,11 @@ func (ins *Instance) Gather(slist *itypes.SampleList) {
 
 	containers, err := ins.client.ContainerList(ctx, opts)
 	if err == context.DeadlineExceeded {
-		log.Println("E! failed to gather container list: timeout")
+		dockerLog.Errorf("failed to gather container list: timeout")
 		return
 	}
 	if err != nil {
-		log.Println("E! failed to gather container list:", err)
+		dockerLog.Errorf("failed to gather container list: %v", err)
 		return
 	}
 
@@ -240,11 +242,11 @@ func (ins *Instance) gatherContainer(container types.Container, slist *itypes.Sa
 
 	r, err := ins.client.ContainerStats(ctx, container.ID, false)
 	if err == context.DeadlineExceeded {
-		log.Println("E! failed to get container stats: timeout")
+		dockerLog.Errorf("failed to get container stats: timeout")
 		return
 	}
 	if err != nil {
-		log.Println("E! failed to get container stats:", err)
+		dockerLog.Errorf("failed to get container stats: %v", err)
 		return
 	}
 
@@ -255,7 +257,7 @@ func (ins *Instance) gatherContainer(container types.Container, slist *itypes.Sa
 	var v *types.StatsJSON
 	if err = dec.Decode(&v); err != nil {
 		if err != io.EOF {
-			log.Println("E! failed to decode output of container stats:", err)
+			dockerLog.Errorf("failed to decode output of container stats: %v", err)
 		}
 		return
 	}
@@ -269,7 +271,7 @@ func (ins *Instance) gatherContainer(container types.Container, slist *itypes.Sa
 
 	err = ins.gatherContainerInspect(container, slist, tags, r.OSType, v)
 	if err != nil {
-		log.Println("E! failed to gather container inspect:", err)
+		dockerLog.Errorf("failed to gather container inspect: %v", err)
 	}
 }
 
@@ -597,11 +599,11 @@ func (ins *Instance) gatherSwarmInfo(slist *itypes.SampleList) {
 
 	services, err := ins.client.ServiceList(ctx, types.ServiceListOptions{})
 	if err == context.DeadlineExceeded {
-		log.Println("E! failed to gather swarm info: timeout")
+		dockerLog.Errorf("failed to gather swarm info: timeout")
 		return
 	}
 	if err != nil {
-		log.Println("E! failed to gather swarm info:", err)
+		dockerLog.Errorf("failed to gather swarm info: %v", err)
 		return
 	}
 
@@ -611,13 +613,13 @@ func (ins *Instance) gatherSwarmInfo(slist *itypes.SampleList) {
 
 	tasks, err := ins.client.TaskList(ctx, types.TaskListOptions{})
 	if err != nil {
-		log.Println("E! failed to gather swarm info:", err)
+		dockerLog.Errorf("failed to gather swarm info: %v", err)
 		return
 	}
 
 	nodes, err := ins.client.NodeList(ctx, types.NodeListOptions{})
 	if err != nil {
-		log.Println("E! failed to gather swarm info:", err)
+		dockerLog.Errorf("failed to gather swarm info: %v", err)
 		return
 	}
 
@@ -654,7 +656,7 @@ func (ins *Instance) gatherSwarmInfo(slist *itypes.SampleList) {
 			fields["tasks_running"] = running[service.ID]
 			fields["tasks_desired"] = tasksNoShutdown[service.ID]
 		} else {
-			log.Println("E! Unknown replica mode")
+			dockerLog.Errorf("Unknown replica mode")
 		}
 
 		slist.PushSamples("docker_swarm", fields, tags)
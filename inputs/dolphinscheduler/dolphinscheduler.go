@@ -0,0 +1,293 @@
+package dolphinscheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/httpx"
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/pkg/tls"
+	"flashcat.cloud/categraf/types"
+)
+
+var dolphinschedulerLog = logger.New("input.dolphinscheduler")
+
+const (
+	inputName = "dolphinscheduler"
+
+	projectsPath          = "/projects"
+	processStateCountPath = "/projects/analysis/process-state-count"
+	taskStateCountPath    = "/projects/analysis/task-state-count"
+	mastersPath           = "/monitor/masters"
+	workersPath           = "/monitor/workers"
+
+	pageSize   = 100
+	timeLayout = "2006-01-02 15:04:05"
+)
+
+// the states of the tasks waiting for workers
+var queuedStates = []string{"SUBMITTED_SUCCESS", "DISPATCH"}
+
+type DolphinScheduler struct {
+	config.PluginConfig
+	Instances []*Instance `toml:"instances"`
+}
+
+type Instance struct {
+	config.InstanceConfig
+
+	// the api server, e.g. http://dolphinscheduler:12345/dolphinscheduler
+	URL string `toml:"url"`
+	// the token of security center
+	Token string `toml:"token"`
+	// the instances started in the window are counted by state
+	Window config.Duration `toml:"window"`
+	// the time zone of the api server, which formats the times without zone, e.g. Asia/Shanghai, local by default
+	Timezone string          `toml:"timezone"`
+	Timeout  config.Duration `toml:"timeout"`
+	tls.ClientConfig
+
+	client   *http.Client
+	location *time.Location
+}
+
+type project struct {
+	Code int64  `json:"code"`
+	Name string `json:"name"`
+}
+
+type server struct {
+	Host              string `json:"host"`
+	Port              int    `json:"port"`
+	LastHeartbeatTime string `json:"lastHeartbeatTime"`
+}
+
+var _ inputs.SampleGatherer = new(Instance)
+var _ inputs.Input = new(DolphinScheduler)
+var _ inputs.InstancesGetter = new(DolphinScheduler)
+
+func init() {
+	inputs.Add(inputName, func() inputs.Input {
+		return &DolphinScheduler{}
+	})
+}
+
+func (d *DolphinScheduler) Clone() inputs.Input {
+	return &DolphinScheduler{}
+}
+
+func (d *DolphinScheduler) Name() string {
+	return inputName
+}
+
+func (d *DolphinScheduler) GetInstances() []inputs.Instance {
+	ret := make([]inputs.Instance, len(d.Instances))
+	for i := 0; i < len(d.Instances); i++ {
+		ret[i] = d.Instances[i]
+	}
+	return ret
+}
+
+func (ins *Instance) Init() error {
+	if ins.URL == "" {
+		return types.ErrInstancesEmpty
+	}
+	if ins.Token == "" {
+		return fmt.Errorf("token of dolphinscheduler is required")
+	}
+	if ins.Timeout <= 0 {
+		ins.Timeout = config.Duration(5 * time.Second)
+	}
+	if ins.Window <= 0 {
+		ins.Window = config.Duration(time.Hour)
+	}
+	ins.URL = strings.TrimRight(ins.URL, "/")
+
+	ins.location = time.Local
+	if ins.Timezone != "" {
+		var err error
+		ins.location, err = time.LoadLocation(ins.Timezone)
+		if err != nil {
+			return fmt.Errorf("invalid timezone %s: %v", ins.Timezone, err)
+		}
+	}
+
+	tlsConfig, err := ins.ClientConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+	ins.client = &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		},
+		Timeout: time.Duration(ins.Timeout),
+	}
+	return nil
+}
+
+func (ins *Instance) Gather(slist *types.SampleList) {
+	projects, err := ins.listProjects()
+	if err != nil {
+		dolphinschedulerLog.Errorf("failed to list projects of dolphinscheduler: %v error: %v", ins.URL, err)
+		slist.PushSample(inputName, "up", 0)
+		return
+	}
+	slist.PushSample(inputName, "up", 1)
+
+	ins.gatherServers(slist, mastersPath, "master")
+	ins.gatherServers(slist, workersPath, "worker")
+
+	now := time.Now()
+	window := url.Values{
+		"startDate": {now.Add(-time.Duration(ins.Window)).In(ins.location).Format(timeLayout)},
+		"endDate":   {now.In(ins.location).Format(timeLayout)},
+	}
+	for _, p := range projects {
+		tags := map[string]string{"project": p.Name}
+		code := strconv.FormatInt(p.Code, 10)
+
+		ins.gatherStateCount(slist, processStateCountPath, "process_instances", code, window, tags)
+		ins.gatherStateCount(slist, taskStateCountPath, "task_instances", code, window, tags)
+		ins.gatherQueuedTasks(slist, code, tags)
+	}
+}
+
+// gatherServers reports the heartbeats of masters or workers registered in the registry
+func (ins *Instance) gatherServers(slist *types.SampleList, path, role string) {
+	var servers []server
+	if err := ins.get(path, nil, &servers); err != nil {
+		dolphinschedulerLog.Errorf("failed to list %v %v error: %v", role+"s of dolphinscheduler:", ins.URL, err)
+		return
+	}
+	slist.PushSample(inputName, role+"s", len(servers))
+
+	for _, s := range servers {
+		heartbeat, err := time.ParseInLocation(timeLayout, s.LastHeartbeatTime, ins.location)
+		if err != nil {
+			continue
+		}
+		tags := map[string]string{"host": s.Host + ":" + strconv.Itoa(s.Port)}
+		slist.PushSample(inputName, role+"_heartbeat_age_seconds", time.Since(heartbeat).Seconds(), tags)
+	}
+}
+
+// gatherStateCount reports the instances of the project started in the window by state, e.g. FAILURE
+func (ins *Instance) gatherStateCount(slist *types.SampleList, path, metric, code string, window url.Values, tags map[string]string) {
+	query := url.Values{"projectCode": {code}}
+	for k, v := range window {
+		query[k] = v
+	}
+
+	var count struct {
+		TaskCountDtos []struct {
+			Count         float64 `json:"count"`
+			TaskStateType string  `json:"taskStateType"`
+		} `json:"taskCountDtos"`
+	}
+	if err := ins.get(path, query, &count); err != nil {
+		dolphinschedulerLog.Errorf("failed to count %v of dolphinscheduler project: %v error: %v", metric, tags["project"], err)
+		return
+	}
+
+	for _, c := range count.TaskCountDtos {
+		slist.PushSample(inputName, metric, c.Count, tags, map[string]string{"state": strings.ToLower(c.TaskStateType)})
+	}
+}
+
+// gatherQueuedTasks reports the age of the oldest task waiting for workers, the tasks are stuck
+// if no worker of the group is alive or the workers are overloaded
+func (ins *Instance) gatherQueuedTasks(slist *types.SampleList, code string, tags map[string]string) {
+	var queued, oldest float64
+	for _, state := range queuedStates {
+		for pageNo := 1; ; pageNo++ {
+			var page struct {
+				TotalList []struct {
+					SubmitTime string `json:"submitTime"`
+				} `json:"totalList"`
+				Total float64 `json:"total"`
+			}
+			query := url.Values{"pageNo": {strconv.Itoa(pageNo)}, "pageSize": {strconv.Itoa(pageSize)}, "stateType": {state}}
+			if err := ins.get(projectsPath+"/"+code+"/task-instances", query, &page); err != nil {
+				dolphinschedulerLog.Errorf("failed to list queued tasks of dolphinscheduler project: %v error: %v", tags["project"], err)
+				return
+			}
+
+			if pageNo == 1 {
+				queued += page.Total
+			}
+			for _, t := range page.TotalList {
+				submitted, err := time.ParseInLocation(timeLayout, t.SubmitTime, ins.location)
+				if err != nil {
+					continue
+				}
+				if age := time.Since(submitted).Seconds(); age > oldest {
+					oldest = age
+				}
+			}
+
+			if len(page.TotalList) < pageSize {
+				break
+			}
+		}
+	}
+
+	slist.PushSample(inputName, "queued_tasks", queued, tags)
+	slist.PushSample(inputName, "queued_task_oldest_age_seconds", oldest, tags)
+}
+
+func (ins *Instance) listProjects() ([]project, error) {
+	var projects []project
+	for pageNo := 1; ; pageNo++ {
+		var page struct {
+			TotalList []project `json:"totalList"`
+		}
+		query := url.Values{"pageNo": {strconv.Itoa(pageNo)}, "pageSize": {strconv.Itoa(pageSize)}}
+		if err := ins.get(projectsPath, query, &page); err != nil {
+			return nil, err
+		}
+
+		projects = append(projects, page.TotalList...)
+		if len(page.TotalList) < pageSize {
+			return projects, nil
+		}
+	}
+}
+
+// get decodes the data of the result into v, the failures are returned with code not 0
+func (ins *Instance) get(path string, query url.Values, v interface{}) error {
+	u := ins.URL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("token", ins.Token)
+	req.Header.Set("Accept", "application/json")
+
+	var result struct {
+		Code int             `json:"code"`
+		Msg  string          `json:"msg"`
+		Data json.RawMessage `json:"data"`
+	}
+	if _, err := httpx.DoJSON(ins.client, req, path, &result); err != nil {
+		return err
+	}
+	if result.Code != 0 {
+		return fmt.Errorf("%s returned code %d: %s", path, result.Code, result.Msg)
+	}
+	if len(result.Data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(result.Data, v)
+}
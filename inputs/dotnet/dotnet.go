@@ -0,0 +1,268 @@
+package dotnet
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/cmdx"
+	"flashcat.cloud/categraf/pkg/filter"
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/types"
+)
+
+var dotnetLog = logger.New("input.dotnet")
+
+const inputName = "dotnet"
+
+type Dotnet struct {
+	config.PluginConfig
+	Instances []*Instance `toml:"instances"`
+}
+
+func init() {
+	inputs.Add(inputName, func() inputs.Input {
+		return &Dotnet{}
+	})
+}
+
+func (d *Dotnet) Clone() inputs.Input {
+	return &Dotnet{}
+}
+
+func (d *Dotnet) Name() string {
+	return inputName
+}
+
+func (d *Dotnet) GetInstances() []inputs.Instance {
+	ret := make([]inputs.Instance, len(d.Instances))
+	for i := 0; i < len(d.Instances); i++ {
+		ret[i] = d.Instances[i]
+	}
+	return ret
+}
+
+type Instance struct {
+	config.InstanceConfig
+
+	// glob patterns matching the process names listed by dotnet-counters ps
+	ProcessNames []string `toml:"process_names"`
+	// path of dotnet-counters, installed by dotnet tool install --global dotnet-counters
+	DotnetCounters string `toml:"dotnet_counters"`
+	// the providers of counters, e.g. System.Runtime and Microsoft.AspNetCore.Hosting
+	Counters []string `toml:"counters"`
+	// the counters are sampled for duration every gather
+	Duration config.Duration `toml:"duration"`
+	Timeout  config.Duration `toml:"timeout"`
+
+	filter filter.Filter
+}
+
+type process struct {
+	pid  string
+	name string
+}
+
+// the json exported by dotnet-counters collect --format json
+type counterReport struct {
+	Events []struct {
+		Provider    string   `json:"provider"`
+		Name        string   `json:"name"`
+		Tags        string   `json:"tags"`
+		CounterType string   `json:"counterType"`
+		Value       *float64 `json:"value"`
+	} `json:"Events"`
+}
+
+func (ins *Instance) Init() error {
+	if len(ins.ProcessNames) == 0 {
+		return types.ErrInstancesEmpty
+	}
+
+	f, err := filter.Compile(ins.ProcessNames)
+	if err != nil {
+		return err
+	}
+	ins.filter = f
+
+	if ins.DotnetCounters == "" {
+		ins.DotnetCounters = "dotnet-counters"
+	}
+
+	if len(ins.Counters) == 0 {
+		ins.Counters = []string{"System.Runtime"}
+	}
+
+	if ins.Duration < config.Duration(2*time.Second) {
+		ins.Duration = config.Duration(2 * time.Second)
+	}
+
+	if ins.Timeout == 0 {
+		ins.Timeout = config.Duration(10*time.Second) + ins.Duration
+	}
+	return nil
+}
+
+func (ins *Instance) Gather(slist *types.SampleList) {
+	procs, err := ins.find()
+	if err != nil {
+		dotnetLog.Errorf("failed to list dotnet processes: %v", err)
+		slist.PushSample(inputName, "up", 0)
+		return
+	}
+	slist.PushSample(inputName, "up", 1)
+	slist.PushSample(inputName, "processes", len(procs))
+
+	for _, p := range procs {
+		if err := ins.collect(slist, p); err != nil {
+			dotnetLog.Errorf("failed to collect counters of dotnet process: %v error: %v", p.pid, err)
+		}
+	}
+}
+
+// find parses the output of dotnet-counters ps, the lines are like
+// 12345 MyApp     /app/MyApp
+func (ins *Instance) find() ([]process, error) {
+	out, err := ins.run("ps")
+	if err != nil {
+		return nil, err
+	}
+
+	var procs []process
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		if _, err := strconv.Atoi(fields[0]); err != nil {
+			continue
+		}
+
+		if ins.filter.Match(fields[1]) {
+			procs = append(procs, process{pid: fields[0], name: fields[1]})
+		}
+	}
+	return procs, nil
+}
+
+// collect samples the counters of the process for duration with a refresh interval of 1s,
+// the last value of every counter is reported
+func (ins *Instance) collect(slist *types.SampleList, p process) error {
+	f, err := os.CreateTemp("", "categraf-dotnet-counters-*.json")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+
+	seconds := int(time.Duration(ins.Duration).Seconds())
+	_, err = ins.run("collect",
+		"--process-id", p.pid,
+		"--format", "json",
+		"--output", f.Name(),
+		"--refresh-interval", "1",
+		"--duration", fmt.Sprintf("00:00:%02d:%02d", seconds/60, seconds%60),
+		"--counters", strings.Join(ins.Counters, ","),
+	)
+	if err != nil {
+		return err
+	}
+
+	bs, err := os.ReadFile(f.Name())
+	if err != nil {
+		return err
+	}
+
+	// the file is not closed properly if dotnet-counters is stopped in the middle
+	bs = bytes.TrimSpace(bs)
+	if !bytes.HasSuffix(bs, []byte("}")) {
+		bs = append(bytes.TrimSuffix(bs, []byte(",")), []byte("]}")...)
+	}
+
+	var report counterReport
+	if err := json.Unmarshal(bs, &report); err != nil {
+		return fmt.Errorf("failed to parse output of dotnet-counters: %v", err)
+	}
+
+	type key struct{ provider, name, tags string }
+	last := make(map[key]float64)
+	for _, e := range report.Events {
+		if e.Value != nil {
+			last[key{e.Provider, e.Name, e.Tags}] = *e.Value
+		}
+	}
+
+	for k, v := range last {
+		metric, value := metricName(k.name, v)
+		tags := map[string]string{"pid": p.pid, "process": p.name, "provider": k.provider}
+		if k.tags != "" {
+			tags["tags"] = k.tags
+		}
+		slist.PushSample(inputName, metric, value, tags)
+	}
+	return nil
+}
+
+var (
+	unitRegexp      = regexp.MustCompile(`\s*\(([^)]*)\)\s*$`)
+	nonAlnumRegexp  = regexp.MustCompile(`[^a-z0-9]+`)
+	rateUnitRegexp  = regexp.MustCompile(`/ ?1 sec$`)
+	metricUnitNames = map[string]string{"%": "percent", "b": "bytes", "ms": "ms"}
+)
+
+// metricName converts the display names of counters, e.g.
+// "GC Heap Size (MB)" to gc_heap_size_bytes, "Gen 0 GC Count (Count / 1 sec)" to gen_0_gc_count_per_second,
+// and the names of meters of .NET 8+, e.g. dotnet.gc.collections to gc_collections
+func metricName(display string, value float64) (string, float64) {
+	name, unit := display, ""
+	if m := unitRegexp.FindStringSubmatch(display); m != nil {
+		name = display[:len(display)-len(m[0])]
+		unit = strings.ToLower(strings.TrimSpace(m[1]))
+	}
+
+	name = strings.TrimPrefix(strings.ToLower(name), "dotnet.")
+	name = strings.Trim(nonAlnumRegexp.ReplaceAllString(strings.ReplaceAll(name, "%", ""), "_"), "_")
+
+	switch {
+	// annotations of meters, e.g. {collection}
+	case unit == "", strings.HasPrefix(unit, "{"):
+	case unit == "mb":
+		name, value = name+"_bytes", value*1024*1024
+	case unit == "kb":
+		name, value = name+"_bytes", value*1024
+	case rateUnitRegexp.MatchString(unit):
+		// the refresh interval is 1s
+		name += "_per_second"
+	case metricUnitNames[unit] != "":
+		name += "_" + metricUnitNames[unit]
+	default:
+		name += "_" + strings.Trim(nonAlnumRegexp.ReplaceAllString(unit, "_"), "_")
+	}
+	return name, value
+}
+
+func (ins *Instance) run(args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(ins.DotnetCounters, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err, timeout := cmdx.RunTimeout(cmd, time.Duration(ins.Timeout))
+	if timeout {
+		return "", fmt.Errorf("dotnet-counters %s timeout after %s", args[0], time.Duration(ins.Timeout))
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"sort"
 	"strings"
@@ -15,10 +14,13 @@ import (
 	"flashcat.cloud/categraf/inputs"
 	"flashcat.cloud/categraf/pkg/filter"
 	"flashcat.cloud/categraf/pkg/jsonx"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/pkg/tls"
 	"flashcat.cloud/categraf/types"
 )
 
+var elasticsearchLog = logger.New("input.elasticsearch")
+
 const inputName = "elasticsearch"
 
 // Nodestats are always generated, so simply define a constant for these endpoints
@@ -201,7 +203,7 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 				// Gather node ID
 				if info.nodeID, err = ins.gatherNodeID(s + "/_nodes/_local/name"); err != nil {
 					slist.PushSample("elasticsearch", "up", 0, map[string]string{"address": s})
-					log.Println("E! failed to gather node id:", err)
+					elasticsearchLog.Errorf("failed to gather node id: %v", err)
 					return
 				}
 
@@ -209,7 +211,7 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 				// whether this node is the Master
 				if info.masterID, err = ins.getCatMaster(s + "/_cat/master"); err != nil {
 					slist.PushSample("elasticsearch", "up", 0, map[string]string{"address": s})
-					log.Println("E! failed to get cat master:", err)
+					elasticsearchLog.Errorf("failed to get cat master: %v", err)
 					return
 				}
 
@@ -232,7 +234,7 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 
 			// Always gather node stats
 			if err := ins.gatherNodeStats(url, s, slist); err != nil {
-				log.Println("E! failed to gather node stats:", err)
+				elasticsearchLog.Errorf("failed to gather node stats: %v", err)
 				return
 			}
 
@@ -242,14 +244,14 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 					url = url + "?level=" + ins.ClusterHealthLevel
 				}
 				if err := ins.gatherClusterHealth(url, s, slist); err != nil {
-					log.Println("E! failed to gather cluster health:", err)
+					elasticsearchLog.Errorf("failed to gather cluster health: %v", err)
 					return
 				}
 			}
 
 			if ins.ClusterStats && (ins.serverInfo[s].isMaster() || !ins.Local) {
 				if err := ins.gatherClusterStats(s+"/_cluster/stats", s, slist); err != nil {
-					log.Println("E! failed to gather cluster stats:", err)
+					elasticsearchLog.Errorf("failed to gather cluster stats: %v", err)
 					return
 				}
 			}
@@ -257,12 +259,12 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 			if len(ins.IndicesInclude) > 0 && (ins.serverInfo[s].isMaster() || !ins.Local) {
 				if ins.IndicesLevel != "shards" {
 					if err := ins.gatherIndicesStats(s+"/"+strings.Join(ins.IndicesInclude, ",")+"/_stats", s, slist); err != nil {
-						log.Println("E! failed to gather indices stats:", err)
+						elasticsearchLog.Errorf("failed to gather indices stats: %v", err)
 						return
 					}
 				} else {
 					if err := ins.gatherIndicesStats(s+"/"+strings.Join(ins.IndicesInclude, ",")+"/_stats?level=shards", s, slist); err != nil {
-						log.Println("E! failed to gather indices stats:", err)
+						elasticsearchLog.Errorf("failed to gather indices stats: %v", err)
 						return
 					}
 				}
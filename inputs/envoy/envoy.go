@@ -0,0 +1,249 @@
+package envoy
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/parser/prometheus"
+	"flashcat.cloud/categraf/pkg/filter"
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/pkg/tls"
+	"flashcat.cloud/categraf/types"
+)
+
+var envoyLog = logger.New("input.envoy")
+
+const (
+	inputName = "envoy"
+
+	statsPath = "/stats/prometheus"
+	// the push metrics of istiod
+	pilotPrefix = "pilot_"
+)
+
+// the stats of each category, matched by the filter of envoy against the names of stats, e.g.
+// cluster.outbound|9080||reviews.default.svc.cluster.local.upstream_rq_total, the counters by
+// code are left out in favor of the counters by class, e.g. upstream_rq_5xx
+var categories = map[string]string{
+	"cluster": `^cluster\..+\.(upstream_rq_total|upstream_rq_[1-5]xx|upstream_rq_active|upstream_rq_timeout|upstream_rq_retry|` +
+		`upstream_rq_pending_active|upstream_rq_pending_overflow|upstream_cx_active|upstream_cx_overflow|upstream_cx_pool_overflow|` +
+		`upstream_cx_connect_fail|membership_healthy|membership_total|outlier_detection\.ejections_active|upstream_rq_time)$`,
+	"listener": `^listener\..+\.(downstream_cx_total|downstream_cx_active|downstream_cx_destroy|downstream_cx_overflow|` +
+		`downstream_cx_overload_reject|downstream_pre_cx_timeout)$`,
+	"http": `^http\..+\.(downstream_rq_total|downstream_rq_[1-5]xx|downstream_rq_active|downstream_rq_timeout|` +
+		`downstream_cx_active|downstream_rq_time)$`,
+	"server": `^server\.(live|state|uptime|concurrency|total_connections|memory_allocated|memory_heap_size)$`,
+}
+
+// the tags extracted by envoy are renamed shorter
+var labelNames = map[string]string{
+	"envoy_cluster_name":             "cluster",
+	"envoy_listener_address":         "listener",
+	"envoy_http_conn_manager_prefix": "http_conn_manager",
+	"envoy_response_code_class":      "code_class",
+}
+
+type Envoy struct {
+	config.PluginConfig
+	Instances []*Instance `toml:"instances"`
+}
+
+type Instance struct {
+	config.InstanceConfig
+
+	// the admin interface of envoy, e.g. http://127.0.0.1:15000, or the merged stats port of istio sidecars,
+	// e.g. http://pod:15090
+	URLs []string `toml:"urls"`
+	// cluster | listener | http | server
+	Collect []string `toml:"collect"`
+	// the names of upstream clusters, globs are supported, e.g. outbound|*||*.svc.cluster.local
+	ClusterInclude []string `toml:"cluster_include"`
+	ClusterExclude []string `toml:"cluster_exclude"`
+	// report the buckets of histograms, e.g. upstream_rq_time, only _sum and _count are reported by default
+	Histograms bool `toml:"histograms"`
+
+	// the monitoring port of istiod, e.g. http://istiod.istio-system:15014/metrics, the metrics of pilot are reported
+	IstiodURLs []string `toml:"istiod_urls"`
+
+	Timeout config.Duration `toml:"timeout"`
+	tls.ClientConfig
+
+	client        *http.Client
+	statsFilter   string
+	clusterFilter filter.Filter
+}
+
+var _ inputs.SampleGatherer = new(Instance)
+var _ inputs.Input = new(Envoy)
+var _ inputs.InstancesGetter = new(Envoy)
+
+func init() {
+	inputs.Add(inputName, func() inputs.Input {
+		return &Envoy{}
+	})
+}
+
+func (e *Envoy) Clone() inputs.Input {
+	return &Envoy{}
+}
+
+func (e *Envoy) Name() string {
+	return inputName
+}
+
+func (e *Envoy) GetInstances() []inputs.Instance {
+	ret := make([]inputs.Instance, len(e.Instances))
+	for i := 0; i < len(e.Instances); i++ {
+		ret[i] = e.Instances[i]
+	}
+	return ret
+}
+
+func (ins *Instance) Init() error {
+	if len(ins.URLs) == 0 && len(ins.IstiodURLs) == 0 {
+		return types.ErrInstancesEmpty
+	}
+	if ins.Timeout <= 0 {
+		ins.Timeout = config.Duration(5 * time.Second)
+	}
+	if len(ins.Collect) == 0 {
+		ins.Collect = []string{"cluster", "listener", "http", "server"}
+	}
+
+	patterns := make([]string, 0, len(ins.Collect))
+	for _, c := range ins.Collect {
+		pattern, ok := categories[c]
+		if !ok {
+			return fmt.Errorf("invalid collect %s, cluster, listener, http or server", c)
+		}
+		patterns = append(patterns, "("+pattern+")")
+	}
+	ins.statsFilter = strings.Join(patterns, "|")
+
+	var err error
+	ins.clusterFilter, err = filter.NewIncludeExcludeFilter(ins.ClusterInclude, ins.ClusterExclude)
+	if err != nil {
+		return fmt.Errorf("failed to compile cluster filters: %v", err)
+	}
+
+	tlsConfig, err := ins.ClientConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+	ins.client = &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		},
+		Timeout: time.Duration(ins.Timeout),
+	}
+	return nil
+}
+
+func (ins *Instance) Gather(slist *types.SampleList) {
+	var wg sync.WaitGroup
+	for _, u := range ins.URLs {
+		wg.Add(1)
+		go func(u string) {
+			defer wg.Done()
+			ins.gatherStats(slist, strings.TrimRight(u, "/"))
+		}(u)
+	}
+	for _, u := range ins.IstiodURLs {
+		wg.Add(1)
+		go func(u string) {
+			defer wg.Done()
+			ins.gatherIstiod(slist, u)
+		}(u)
+	}
+	wg.Wait()
+}
+
+// gatherStats requests the stats matched by the filter only, the stats never updated are left out
+func (ins *Instance) gatherStats(slist *types.SampleList, u string) {
+	tags := map[string]string{"url": u}
+
+	query := url.Values{"usedonly": {""}, "filter": {ins.statsFilter}}
+	samples, err := ins.scrape(u+statsPath+"?"+query.Encode(), tags)
+	if err != nil {
+		envoyLog.Errorf("failed to get stats of envoy: %v error: %v", u, err)
+		slist.PushSample(inputName, "up", 0, tags)
+		return
+	}
+	slist.PushSample(inputName, "up", 1, tags)
+
+	kept := samples[:0]
+	for _, s := range samples {
+		if !ins.Histograms && strings.HasSuffix(s.Metric, "_bucket") {
+			continue
+		}
+		if cluster, ok := s.Labels["envoy_cluster_name"]; ok && !ins.clusterFilter.Match(cluster) {
+			continue
+		}
+		for from, to := range labelNames {
+			if v, ok := s.Labels[from]; ok {
+				delete(s.Labels, from)
+				s.Labels[to] = v
+			}
+		}
+		kept = append(kept, s)
+	}
+	slist.PushFrontN(kept)
+}
+
+// gatherIstiod reports the metrics of pilot, e.g. pilot_xds_pushes and pilot_proxy_convergence_time
+func (ins *Instance) gatherIstiod(slist *types.SampleList, u string) {
+	tags := map[string]string{"url": u}
+
+	samples, err := ins.scrape(u, tags)
+	if err != nil {
+		envoyLog.Errorf("failed to get metrics of istiod: %v error: %v", u, err)
+		slist.PushSample(inputName, "istiod_up", 0, tags)
+		return
+	}
+	slist.PushSample(inputName, "istiod_up", 1, tags)
+
+	kept := samples[:0]
+	for _, s := range samples {
+		if !strings.HasPrefix(s.Metric, pilotPrefix) {
+			continue
+		}
+		if !ins.Histograms && strings.HasSuffix(s.Metric, "_bucket") {
+			continue
+		}
+		kept = append(kept, s)
+	}
+	slist.PushFrontN(kept)
+}
+
+func (ins *Instance) scrape(u string, tags map[string]string) ([]*types.Sample, error) {
+	resp, err := ins.client.Get(u)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		return nil, fmt.Errorf("returned HTTP status %s: %q", resp.Status, body)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	scraped := types.NewSampleList()
+	parser := prometheus.NewParser("", tags, resp.Header, nil, nil)
+	if err := parser.Parse(body, scraped); err != nil {
+		return nil, err
+	}
+	return scraped.PopBackAll(), nil
+}
@@ -0,0 +1,169 @@
+//go:build windows
+// +build windows
+
+package exchange
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/yusufpapurcu/wmi"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/types"
+)
+
+var exchangeLog = logger.New("input.exchange")
+
+const (
+	inputName = "exchange"
+
+	transportService = "MSExchangeTransport"
+	defaultLookback  = 24 * time.Hour
+)
+
+// the states of back pressure, from the least to the most severe
+var pressureStates = []string{"normal", "medium", "high"}
+
+// e.g. The resource pressure increased from Normal to Medium.
+var pressureChangeRegexp = regexp.MustCompile(`(?i)\bfrom (normal|medium|high) to (normal|medium|high)\b`)
+
+type Exchange struct {
+	config.PluginConfig
+	// how far the application event log is searched back for the state of back pressure at the start
+	Lookback config.Duration `toml:"lookback"`
+
+	state      string
+	changed    time.Time
+	lastRecord uint32
+	// the events are searched since the last query
+	since time.Time
+}
+
+func init() {
+	inputs.Add(inputName, func() inputs.Input {
+		return &Exchange{}
+	})
+}
+
+func (e *Exchange) Clone() inputs.Input {
+	return &Exchange{}
+}
+
+func (e *Exchange) Name() string {
+	return inputName
+}
+
+func (e *Exchange) Init() error {
+	e.state = "normal"
+	lookback := time.Duration(e.Lookback)
+	if lookback <= 0 {
+		lookback = defaultLookback
+	}
+	e.since = time.Now().Add(-lookback)
+	return nil
+}
+
+// the events of back pressure logged by the transport service:
+// 15004 and 15005 for the increase and decrease, 15006 and 15007 for the disk space and memory critically low
+type Win32_NTLogEvent struct {
+	EventCode     uint16
+	RecordNumber  uint32
+	Message       string
+	TimeGenerated time.Time
+}
+
+type Win32_Service struct {
+	Name  string
+	State string
+}
+
+func (e *Exchange) Gather(slist *types.SampleList) {
+	var services []Win32_Service
+	err := wmi.Query(wmi.CreateQuery(&services, fmt.Sprintf("WHERE Name = '%s'", transportService)), &services)
+	if err != nil {
+		exchangeLog.Errorf("failed to query exchange transport service: %v", err)
+		slist.PushSample(inputName, "up", 0)
+		return
+	}
+
+	running := 0
+	if len(services) > 0 && services[0].State == "Running" {
+		running = 1
+	}
+	slist.PushSample(inputName, "transport_service_running", running)
+
+	if err := e.gatherBackPressure(); err != nil {
+		exchangeLog.Errorf("failed to query back pressure events of exchange: %v", err)
+		slist.PushSample(inputName, "up", 0)
+		return
+	}
+
+	for _, s := range pressureStates {
+		v := 0
+		if s == e.state {
+			v = 1
+		}
+		slist.PushSample(inputName, "backpressure_state", v, map[string]string{"state": s})
+	}
+
+	healthy := 0
+	if e.state == "normal" && running == 1 {
+		healthy = 1
+	}
+	slist.PushSample(inputName, "backpressure_healthy", healthy)
+	slist.PushSample(inputName, "up", 1)
+}
+
+// gatherBackPressure updates the state with the events logged since the last query
+func (e *Exchange) gatherBackPressure() error {
+	// overlaps the last query a little for the events written late, the ones seen are skipped by the time of change
+	now := time.Now().Add(-time.Minute)
+	var events []Win32_NTLogEvent
+	where := fmt.Sprintf("WHERE Logfile = 'Application' AND SourceName = '%s' AND EventCode >= 15004 AND EventCode <= 15007 AND TimeGenerated >= '%s'",
+		transportService, dmtfTime(e.since))
+	if err := wmi.Query(wmi.CreateQuery(&events, where), &events); err != nil {
+		return err
+	}
+	e.since = now
+
+	for _, ev := range events {
+		if ev.RecordNumber == e.lastRecord || ev.TimeGenerated.Before(e.changed) {
+			continue
+		}
+
+		state := ""
+		switch ev.EventCode {
+		case 15004, 15005:
+			if m := pressureChangeRegexp.FindStringSubmatch(ev.Message); m != nil {
+				state = strings.ToLower(m[2])
+			}
+		case 15006, 15007:
+			state = "high"
+		}
+		if state == "" {
+			continue
+		}
+
+		// the events are not sorted, the latest one wins
+		if !ev.TimeGenerated.After(e.changed) && ev.RecordNumber < e.lastRecord {
+			continue
+		}
+		if state != e.state {
+			exchangeLog.Infof("back pressure of exchange changed from %v to %v at %v", e.state, state, ev.TimeGenerated)
+		}
+		e.state = state
+		e.changed = ev.TimeGenerated
+		e.lastRecord = ev.RecordNumber
+	}
+	return nil
+}
+
+// dmtfTime formats t as the datetime of WMI, e.g. 20221014120000.000000+000
+func dmtfTime(t time.Time) string {
+	return t.UTC().Format("20060102150405.000000") + "+000"
+}
@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"fmt"
 	"io"
-	"log"
 	osExec "os/exec"
 	"path/filepath"
 	"runtime"
@@ -19,9 +18,12 @@ import (
 	"flashcat.cloud/categraf/parser/influx"
 	"flashcat.cloud/categraf/parser/prometheus"
 	"flashcat.cloud/categraf/pkg/cmdx"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/types"
 )
 
+var execLog = logger.New("input.exec")
+
 const inputName = "exec"
 
 const MaxStderrBytes int = 512
@@ -94,7 +96,7 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 
 		matches, err := filepath.Glob(cmdAndArgs[0])
 		if err != nil {
-			log.Println("E! failed to get filepath glob of commands:", err)
+			execLog.Errorf("failed to get filepath glob of commands: %v", err)
 			continue
 		}
 
@@ -117,7 +119,7 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 	}
 
 	if len(commands) == 0 {
-		log.Println("W! no commands after parse")
+		execLog.Warnf("no commands after parse")
 		return
 	}
 
@@ -135,13 +137,13 @@ func (ins *Instance) ProcessCommand(slist *types.SampleList, command string, wg
 
 	out, errbuf, runErr := commandRun(command, time.Duration(ins.Timeout))
 	if runErr != nil || len(errbuf) > 0 {
-		log.Println("E! exec_command:", command, "error:", runErr, "stderr:", string(errbuf))
+		execLog.Errorf("exec_command: %v error: %v stderr: %v", command, runErr, string(errbuf))
 		return
 	}
 
 	err := ins.parser.Parse(out, slist)
 	if err != nil {
-		log.Println("E! failed to parse command stdout:", err)
+		execLog.Errorf("failed to parse command stdout: %v", err)
 	}
 }
 
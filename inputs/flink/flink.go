@@ -0,0 +1,332 @@
+package flink
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/httpx"
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/pkg/tls"
+	"flashcat.cloud/categraf/types"
+)
+
+var flinkLog = logger.New("input.flink")
+
+const (
+	inputName = "flink"
+
+	overviewPath     = "/overview"
+	jobsOverviewPath = "/jobs/overview"
+	jobsPath         = "/jobs/"
+)
+
+type Flink struct {
+	config.PluginConfig
+	Instances []*Instance `toml:"instances"`
+}
+
+type Instance struct {
+	config.InstanceConfig
+
+	// the rest api of the jobmanager, e.g. http://jobmanager:8081
+	URL      string          `toml:"url"`
+	Username string          `toml:"username"`
+	Password string          `toml:"password"`
+	Timeout  config.Duration `toml:"timeout"`
+	tls.ClientConfig
+
+	// checkpoints | restarts | backpressure
+	Collect []string `toml:"collect"`
+
+	client  *http.Client
+	collect map[string]bool
+}
+
+type job struct {
+	ID       string  `json:"jid"`
+	Name     string  `json:"name"`
+	State    string  `json:"state"`
+	Duration float64 `json:"duration"`
+}
+
+type checkpoints struct {
+	Counts struct {
+		InProgress float64 `json:"in_progress"`
+		Completed  float64 `json:"completed"`
+		Failed     float64 `json:"failed"`
+	} `json:"counts"`
+	Latest struct {
+		Completed *struct {
+			EndToEndDuration   float64 `json:"end_to_end_duration"`
+			StateSize          float64 `json:"state_size"`
+			LatestAckTimestamp float64 `json:"latest_ack_timestamp"`
+		} `json:"completed"`
+		Failed *struct {
+			FailureTimestamp float64 `json:"failure_timestamp"`
+		} `json:"failed"`
+	} `json:"latest"`
+}
+
+type backpressure struct {
+	Status string `json:"status"`
+	Level  string `json:"backpressureLevel"`
+	// before flink 1.13
+	DeprecatedLevel string `json:"backpressure-level"`
+	Subtasks        []struct {
+		Ratio     float64 `json:"ratio"`
+		BusyRatio float64 `json:"busyRatio"`
+	} `json:"subtasks"`
+}
+
+var _ inputs.SampleGatherer = new(Instance)
+var _ inputs.Input = new(Flink)
+var _ inputs.InstancesGetter = new(Flink)
+
+func init() {
+	inputs.Add(inputName, func() inputs.Input {
+		return &Flink{}
+	})
+}
+
+func (f *Flink) Clone() inputs.Input {
+	return &Flink{}
+}
+
+func (f *Flink) Name() string {
+	return inputName
+}
+
+func (f *Flink) GetInstances() []inputs.Instance {
+	ret := make([]inputs.Instance, len(f.Instances))
+	for i := 0; i < len(f.Instances); i++ {
+		ret[i] = f.Instances[i]
+	}
+	return ret
+}
+
+func (ins *Instance) Init() error {
+	if ins.URL == "" {
+		return types.ErrInstancesEmpty
+	}
+	if ins.Timeout <= 0 {
+		ins.Timeout = config.Duration(5 * time.Second)
+	}
+	if len(ins.Collect) == 0 {
+		ins.Collect = []string{"checkpoints", "restarts", "backpressure"}
+	}
+	ins.URL = strings.TrimRight(ins.URL, "/")
+
+	ins.collect = make(map[string]bool, len(ins.Collect))
+	for _, c := range ins.Collect {
+		switch c {
+		case "checkpoints", "restarts", "backpressure":
+			ins.collect[c] = true
+		default:
+			return fmt.Errorf("invalid collect %s, checkpoints, restarts or backpressure", c)
+		}
+	}
+
+	tlsConfig, err := ins.ClientConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+	ins.client = &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		},
+		Timeout: time.Duration(ins.Timeout),
+	}
+	return nil
+}
+
+func (ins *Instance) Gather(slist *types.SampleList) {
+	var overview struct {
+		TaskManagers   float64 `json:"taskmanagers"`
+		SlotsTotal     float64 `json:"slots-total"`
+		SlotsAvailable float64 `json:"slots-available"`
+		JobsRunning    float64 `json:"jobs-running"`
+		JobsFinished   float64 `json:"jobs-finished"`
+		JobsCancelled  float64 `json:"jobs-cancelled"`
+		JobsFailed     float64 `json:"jobs-failed"`
+	}
+	if err := ins.get(overviewPath, nil, &overview); err != nil {
+		flinkLog.Errorf("failed to get overview of flink: %v error: %v", ins.URL, err)
+		slist.PushSample(inputName, "up", 0)
+		return
+	}
+	slist.PushSample(inputName, "up", 1)
+	slist.PushSamples(inputName, map[string]interface{}{
+		"taskmanagers":    overview.TaskManagers,
+		"slots_total":     overview.SlotsTotal,
+		"slots_available": overview.SlotsAvailable,
+	})
+	slist.PushSample(inputName, "jobs", overview.JobsRunning, map[string]string{"state": "running"})
+	slist.PushSample(inputName, "jobs", overview.JobsFinished, map[string]string{"state": "finished"})
+	slist.PushSample(inputName, "jobs", overview.JobsCancelled, map[string]string{"state": "cancelled"})
+	slist.PushSample(inputName, "jobs", overview.JobsFailed, map[string]string{"state": "failed"})
+
+	var jobs struct {
+		Jobs []job `json:"jobs"`
+	}
+	if err := ins.get(jobsOverviewPath, nil, &jobs); err != nil {
+		flinkLog.Errorf("failed to list jobs of flink: %v error: %v", ins.URL, err)
+		return
+	}
+
+	for _, j := range jobs.Jobs {
+		// the jobs in terminal states are kept in the history until expired
+		switch j.State {
+		case "FINISHED", "CANCELED", "FAILED":
+			continue
+		}
+		ins.gatherJob(slist, j)
+	}
+}
+
+func (ins *Instance) gatherJob(slist *types.SampleList, j job) {
+	tags := map[string]string{"job_id": j.ID, "job_name": j.Name}
+	base := jobsPath + url.PathEscape(j.ID)
+
+	// restarting or failing jobs are not running
+	slist.PushSample(inputName+"_job", "running", j.State == "RUNNING", tags)
+	slist.PushSample(inputName+"_job", "uptime_seconds", j.Duration/1000, tags)
+
+	if ins.collect["restarts"] {
+		ins.gatherRestarts(slist, base, tags)
+	}
+	if ins.collect["checkpoints"] {
+		ins.gatherCheckpoints(slist, base, tags)
+	}
+	if ins.collect["backpressure"] {
+		ins.gatherBackpressure(slist, base, tags)
+	}
+}
+
+// gatherRestarts reports numRestarts of the job, which is fullRestarts before flink 1.10
+func (ins *Instance) gatherRestarts(slist *types.SampleList, base string, tags map[string]string) {
+	var metrics []struct {
+		ID    string `json:"id"`
+		Value string `json:"value"`
+	}
+	if err := ins.get(base+"/metrics", url.Values{"get": {"numRestarts,fullRestarts"}}, &metrics); err != nil {
+		flinkLog.Errorf("failed to get restarts of flink job: %v error: %v", tags["job_id"], err)
+		return
+	}
+
+	values := make(map[string]string, len(metrics))
+	for _, m := range metrics {
+		values[m.ID] = m.Value
+	}
+	value, ok := values["numRestarts"]
+	if !ok {
+		value, ok = values["fullRestarts"]
+	}
+	if !ok {
+		return
+	}
+	if restarts, err := strconv.ParseFloat(value, 64); err == nil {
+		slist.PushSample(inputName+"_job", "restarts_total", restarts, tags)
+	}
+}
+
+func (ins *Instance) gatherCheckpoints(slist *types.SampleList, base string, tags map[string]string) {
+	var cp checkpoints
+	if err := ins.get(base+"/checkpoints", nil, &cp); err != nil {
+		flinkLog.Errorf("failed to get checkpoints of flink job: %v error: %v", tags["job_id"], err)
+		return
+	}
+
+	fields := map[string]interface{}{
+		"checkpoints_completed_total": cp.Counts.Completed,
+		"checkpoints_failed_total":    cp.Counts.Failed,
+		"checkpoints_in_progress":     cp.Counts.InProgress,
+	}
+	if c := cp.Latest.Completed; c != nil {
+		fields["last_checkpoint_duration_seconds"] = c.EndToEndDuration / 1000
+		fields["last_checkpoint_size_bytes"] = c.StateSize
+		fields["last_checkpoint_timestamp"] = c.LatestAckTimestamp / 1000
+	}
+	if f := cp.Latest.Failed; f != nil {
+		fields["last_checkpoint_failure_timestamp"] = f.FailureTimestamp / 1000
+	}
+	slist.PushSamples(inputName+"_job", fields, tags)
+}
+
+// gatherBackpressure reports the max ratio of the subtasks of each vertex, the ratio is sampled by
+// the jobmanager on request, the first request of a vertex may return nothing before flink 1.13
+func (ins *Instance) gatherBackpressure(slist *types.SampleList, base string, tags map[string]string) {
+	var detail struct {
+		Vertices []struct {
+			ID     string `json:"id"`
+			Name   string `json:"name"`
+			Status string `json:"status"`
+		} `json:"vertices"`
+	}
+	if err := ins.get(base, nil, &detail); err != nil {
+		flinkLog.Errorf("failed to get vertices of flink job: %v error: %v", tags["job_id"], err)
+		return
+	}
+
+	for _, v := range detail.Vertices {
+		if v.Status != "RUNNING" {
+			continue
+		}
+
+		var bp backpressure
+		if err := ins.get(base+"/vertices/"+url.PathEscape(v.ID)+"/backpressure", nil, &bp); err != nil {
+			flinkLog.Errorf("failed to get backpressure of flink vertex: %v error: %v", v.Name, err)
+			continue
+		}
+		if bp.Status != "ok" {
+			continue
+		}
+
+		level := bp.Level
+		if level == "" {
+			level = bp.DeprecatedLevel
+		}
+
+		var ratio, busyRatio float64
+		for _, s := range bp.Subtasks {
+			if s.Ratio > ratio {
+				ratio = s.Ratio
+			}
+			if s.BusyRatio > busyRatio {
+				busyRatio = s.BusyRatio
+			}
+		}
+
+		vtags := map[string]string{"vertex": v.Name}
+		slist.PushSamples(inputName+"_vertex", map[string]interface{}{
+			"backpressure_ratio": ratio,
+			"busy_ratio":         busyRatio,
+			"backpressured":      level == "high",
+		}, tags, vtags)
+	}
+}
+
+func (ins *Instance) get(path string, query url.Values, v interface{}) error {
+	u := ins.URL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	if ins.Username != "" || ins.Password != "" {
+		req.SetBasicAuth(ins.Username, ins.Password)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	_, err = httpx.DoJSON(ins.client, req, path, v)
+	return err
+}
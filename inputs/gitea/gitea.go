@@ -0,0 +1,133 @@
+package gitea
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/httpx"
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/pkg/tls"
+	"flashcat.cloud/categraf/types"
+)
+
+var giteaLog = logger.New("input.gitea")
+
+const (
+	inputName = "gitea"
+
+	healthPath = "/api/healthz"
+	statusPass = "pass"
+)
+
+type Gitea struct {
+	config.PluginConfig
+	Instances []*Instance `toml:"instances"`
+}
+
+type Instance struct {
+	config.InstanceConfig
+
+	URL     string          `toml:"url"`
+	Timeout config.Duration `toml:"timeout"`
+	tls.ClientConfig
+
+	client *http.Client
+}
+
+// health is the response of /api/healthz, the status is pass, warn or fail,
+// checks are keyed by component:measurement, e.g. database:ping
+type health struct {
+	Status      string `json:"status"`
+	Description string `json:"description"`
+	Checks      map[string][]struct {
+		Status string `json:"status"`
+		Output string `json:"output"`
+	} `json:"checks"`
+}
+
+var _ inputs.SampleGatherer = new(Instance)
+var _ inputs.Input = new(Gitea)
+var _ inputs.InstancesGetter = new(Gitea)
+
+func init() {
+	inputs.Add(inputName, func() inputs.Input {
+		return &Gitea{}
+	})
+}
+
+func (g *Gitea) Clone() inputs.Input {
+	return &Gitea{}
+}
+
+func (g *Gitea) Name() string {
+	return inputName
+}
+
+func (g *Gitea) GetInstances() []inputs.Instance {
+	ret := make([]inputs.Instance, len(g.Instances))
+	for i := 0; i < len(g.Instances); i++ {
+		ret[i] = g.Instances[i]
+	}
+	return ret
+}
+
+func (ins *Instance) Init() error {
+	if ins.URL == "" {
+		return types.ErrInstancesEmpty
+	}
+	if ins.Timeout <= 0 {
+		ins.Timeout = config.Duration(5 * time.Second)
+	}
+	ins.URL = strings.TrimRight(ins.URL, "/")
+
+	tlsConfig, err := ins.ClientConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+	ins.client = &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		},
+		Timeout: time.Duration(ins.Timeout),
+	}
+	return nil
+}
+
+func (ins *Instance) Gather(slist *types.SampleList) {
+	begin := time.Now()
+	h, err := ins.health()
+	if err != nil {
+		giteaLog.Errorf("failed to check health of gitea: %v error: %v", ins.URL, err)
+		slist.PushSample(inputName, "up", 0)
+		return
+	}
+	slist.PushSample(inputName, "up", 1)
+	slist.PushSample(inputName, "health_response_time_seconds", time.Since(begin).Seconds())
+	slist.PushSample(inputName, "healthy", h.Status == statusPass)
+
+	for name, items := range h.Checks {
+		pass := len(items) > 0
+		for _, item := range items {
+			if item.Status != statusPass {
+				pass = false
+				if config.Config.DebugMode {
+					giteaLog.Debugf("gitea health check %v %v %v", name, item.Status+":", item.Output)
+				}
+			}
+		}
+		slist.PushSample(inputName, "health_check", pass, map[string]string{"check": name})
+	}
+}
+
+// health requests /api/healthz, which returns 503 if any check fails
+func (ins *Instance) health() (*health, error) {
+	h := new(health)
+	if _, err := httpx.GetJSON(ins.client, ins.URL+healthPath, healthPath, h, http.StatusServiceUnavailable); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
@@ -0,0 +1,166 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/httpx"
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/pkg/tls"
+	"flashcat.cloud/categraf/types"
+)
+
+var gitlabLog = logger.New("input.gitlab")
+
+const (
+	inputName = "gitlab"
+
+	livenessPath  = "/-/liveness"
+	readinessPath = "/-/readiness"
+	statusOK      = "ok"
+)
+
+type Gitlab struct {
+	config.PluginConfig
+	Instances []*Instance `toml:"instances"`
+}
+
+type Instance struct {
+	config.InstanceConfig
+
+	URL string `toml:"url"`
+	// the health check endpoints are accessible from the ip allowlist of gitlab, or by the token
+	// of Admin Area > Monitoring > Health check for the gitlab versions before 15.0
+	HealthToken string          `toml:"health_token"`
+	Timeout     config.Duration `toml:"timeout"`
+	tls.ClientConfig
+
+	client *http.Client
+}
+
+// check is the status of a dependency in the readiness response, labeled by the shard for gitaly
+type check struct {
+	Status  string            `json:"status"`
+	Message string            `json:"message"`
+	Labels  map[string]string `json:"labels"`
+}
+
+var _ inputs.SampleGatherer = new(Instance)
+var _ inputs.Input = new(Gitlab)
+var _ inputs.InstancesGetter = new(Gitlab)
+
+func init() {
+	inputs.Add(inputName, func() inputs.Input {
+		return &Gitlab{}
+	})
+}
+
+func (g *Gitlab) Clone() inputs.Input {
+	return &Gitlab{}
+}
+
+func (g *Gitlab) Name() string {
+	return inputName
+}
+
+func (g *Gitlab) GetInstances() []inputs.Instance {
+	ret := make([]inputs.Instance, len(g.Instances))
+	for i := 0; i < len(g.Instances); i++ {
+		ret[i] = g.Instances[i]
+	}
+	return ret
+}
+
+func (ins *Instance) Init() error {
+	if ins.URL == "" {
+		return types.ErrInstancesEmpty
+	}
+	if ins.Timeout <= 0 {
+		ins.Timeout = config.Duration(5 * time.Second)
+	}
+	ins.URL = strings.TrimRight(ins.URL, "/")
+
+	tlsConfig, err := ins.ClientConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+	ins.client = &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		},
+		Timeout: time.Duration(ins.Timeout),
+	}
+	return nil
+}
+
+func (ins *Instance) Gather(slist *types.SampleList) {
+	var live map[string]interface{}
+	if _, err := ins.get(livenessPath, nil, &live); err != nil {
+		gitlabLog.Errorf("failed to check liveness of gitlab: %v error: %v", ins.URL, err)
+		slist.PushSample(inputName, "liveness", 0)
+	} else {
+		slist.PushSample(inputName, "liveness", live["status"] == statusOK)
+	}
+
+	// the dependencies are checked by all=1, not only the web process
+	var ready map[string]json.RawMessage
+	code, err := ins.get(readinessPath, url.Values{"all": {"1"}}, &ready)
+	if err != nil {
+		gitlabLog.Errorf("failed to check readiness of gitlab: %v error: %v", ins.URL, err)
+		slist.PushSample(inputName, "readiness", 0)
+		return
+	}
+
+	var status string
+	json.Unmarshal(ready["status"], &status)
+	slist.PushSample(inputName, "readiness", code == http.StatusOK && status == statusOK)
+
+	for name, raw := range ready {
+		if !strings.HasSuffix(name, "_check") {
+			continue
+		}
+
+		var checks []check
+		if err := json.Unmarshal(raw, &checks); err != nil {
+			continue
+		}
+
+		for _, c := range checks {
+			tags := map[string]string{"check": name}
+			for k, v := range c.Labels {
+				tags[k] = v
+			}
+			if c.Status != statusOK && config.Config.DebugMode {
+				gitlabLog.Debugf("gitlab readiness check %v failed: %v", name, c.Message)
+			}
+			slist.PushSample(inputName, "readiness_check", c.Status == statusOK, tags)
+		}
+	}
+}
+
+// get decodes the json response of path, 503 returned by the health checks of failures is not an error
+func (ins *Instance) get(path string, query url.Values, v interface{}) (int, error) {
+	if ins.HealthToken != "" {
+		if query == nil {
+			query = url.Values{}
+		}
+		query.Set("token", ins.HealthToken)
+	}
+
+	u := ins.URL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	resp, err := httpx.GetJSON(ins.client, u, path, v, http.StatusServiceUnavailable)
+	if resp == nil {
+		return 0, err
+	}
+	return resp.StatusCode, err
+}
@@ -0,0 +1,285 @@
+package gitlab_ci
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/httpx"
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/pkg/tls"
+	"flashcat.cloud/categraf/types"
+)
+
+var gitlabCiLog = logger.New("input.gitlab_ci")
+
+const (
+	inputName = "gitlab_ci"
+
+	runnersOwned = "owned"
+	runnersAll   = "all"
+
+	runnersPerPage = 100
+)
+
+type GitlabCI struct {
+	config.PluginConfig
+	Instances []*Instance `toml:"instances"`
+}
+
+type Instance struct {
+	config.InstanceConfig
+
+	URL          string          `toml:"url"`
+	PrivateToken string          `toml:"private_token"`
+	Timeout      config.Duration `toml:"timeout"`
+	tls.ClientConfig
+
+	// ids or full paths of projects, e.g. 42 or group/project, the pending and running jobs are counted
+	Projects []string `toml:"projects"`
+	// owned: the runners available to the token, all: all runners of the instance, admin is required,
+	// runners are not gathered if empty
+	Runners string `toml:"runners"`
+	// count the running jobs of each runner, one request per runner
+	RunnerJobs bool `toml:"runner_jobs"`
+	// the prometheus endpoints of gitlab-runner processes, listen_address in config.toml of runners,
+	// the saturation is the running jobs over concurrent
+	RunnerMetricsURLs []string `toml:"runner_metrics_urls"`
+
+	client *http.Client
+}
+
+// runner is the item of GET /runners
+type runner struct {
+	ID          int64  `json:"id"`
+	Description string `json:"description"`
+	Active      bool   `json:"active"`
+	Paused      bool   `json:"paused"`
+	RunnerType  string `json:"runner_type"`
+	Online      bool   `json:"online"`
+}
+
+var _ inputs.SampleGatherer = new(Instance)
+var _ inputs.Input = new(GitlabCI)
+var _ inputs.InstancesGetter = new(GitlabCI)
+
+func init() {
+	inputs.Add(inputName, func() inputs.Input {
+		return &GitlabCI{}
+	})
+}
+
+func (g *GitlabCI) Clone() inputs.Input {
+	return &GitlabCI{}
+}
+
+func (g *GitlabCI) Name() string {
+	return inputName
+}
+
+func (g *GitlabCI) GetInstances() []inputs.Instance {
+	ret := make([]inputs.Instance, len(g.Instances))
+	for i := 0; i < len(g.Instances); i++ {
+		ret[i] = g.Instances[i]
+	}
+	return ret
+}
+
+func (ins *Instance) Init() error {
+	if ins.URL == "" && len(ins.RunnerMetricsURLs) == 0 {
+		return types.ErrInstancesEmpty
+	}
+	if ins.Runners != "" && ins.Runners != runnersOwned && ins.Runners != runnersAll {
+		return fmt.Errorf("invalid runners %s, owned or all", ins.Runners)
+	}
+	if ins.Timeout <= 0 {
+		ins.Timeout = config.Duration(5 * time.Second)
+	}
+	ins.URL = strings.TrimRight(ins.URL, "/")
+
+	tlsConfig, err := ins.ClientConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+	ins.client = &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		},
+		Timeout: time.Duration(ins.Timeout),
+	}
+	return nil
+}
+
+func (ins *Instance) Gather(slist *types.SampleList) {
+	if ins.URL != "" {
+		ins.gatherAPI(slist)
+	}
+
+	for _, u := range ins.RunnerMetricsURLs {
+		ins.gatherRunnerProcess(slist, u)
+	}
+}
+
+func (ins *Instance) gatherAPI(slist *types.SampleList) {
+	up := 1
+	defer func() {
+		slist.PushSample(inputName, "up", up)
+	}()
+
+	if _, err := ins.get("/version", nil, nil); err != nil {
+		gitlabCiLog.Errorf("failed to request gitlab: %v error: %v", ins.URL, err)
+		up = 0
+		return
+	}
+
+	for _, project := range ins.Projects {
+		for _, status := range []string{"pending", "running"} {
+			total, err := ins.total("/projects/"+url.PathEscape(project)+"/jobs", url.Values{"scope[]": {status}})
+			if err != nil {
+				gitlabCiLog.Errorf("failed to count %v jobs of gitlab project: %v error: %v", status, project, err)
+				continue
+			}
+			slist.PushSample(inputName, "project_jobs", total, map[string]string{"project": project, "status": status})
+		}
+	}
+
+	if ins.Runners != "" {
+		ins.gatherRunners(slist)
+	}
+}
+
+func (ins *Instance) gatherRunners(slist *types.SampleList) {
+	path := "/runners"
+	if ins.Runners == runnersAll {
+		path = "/runners/all"
+	}
+
+	var runners []runner
+	for page := "1"; page != ""; {
+		var items []runner
+		header, err := ins.get(path, url.Values{"per_page": {strconv.Itoa(runnersPerPage)}, "page": {page}}, &items)
+		if err != nil {
+			gitlabCiLog.Errorf("failed to list gitlab runners: %v", err)
+			return
+		}
+		runners = append(runners, items...)
+		page = header.Get("X-Next-Page")
+	}
+
+	for _, r := range runners {
+		tags := map[string]string{
+			"runner_id":   strconv.FormatInt(r.ID, 10),
+			"runner":      r.Description,
+			"runner_type": r.RunnerType,
+		}
+
+		fields := map[string]interface{}{
+			"runner_online": r.Online,
+			// active is deprecated by paused since gitlab 14.8
+			"runner_paused": r.Paused || !r.Active,
+		}
+
+		if ins.RunnerJobs {
+			total, err := ins.total("/runners/"+tags["runner_id"]+"/jobs", url.Values{"status": {"running"}})
+			if err != nil {
+				gitlabCiLog.Errorf("failed to count running jobs of gitlab runner: %v error: %v", r.ID, err)
+			} else {
+				fields["runner_running_jobs"] = total
+			}
+		}
+		slist.PushSamples(inputName, fields, tags)
+	}
+}
+
+// gatherRunnerProcess reads the jobs and concurrent of the gitlab-runner process
+func (ins *Instance) gatherRunnerProcess(slist *types.SampleList, u string) {
+	tags := map[string]string{"runner_url": u}
+
+	families, err := ins.scrape(u)
+	if err != nil {
+		gitlabCiLog.Errorf("failed to scrape gitlab runner: %v error: %v", u, err)
+		slist.PushSample(inputName, "runner_process_up", 0, tags)
+		return
+	}
+	slist.PushSample(inputName, "runner_process_up", 1, tags)
+
+	var jobs, concurrent float64
+	if mf, has := families["gitlab_runner_jobs"]; has {
+		for _, m := range mf.Metric {
+			jobs += m.GetGauge().GetValue()
+		}
+	}
+	if mf, has := families["gitlab_runner_concurrent"]; has && len(mf.Metric) > 0 {
+		concurrent = mf.Metric[0].GetGauge().GetValue()
+	}
+
+	fields := map[string]interface{}{
+		"runner_process_jobs":       jobs,
+		"runner_process_concurrent": concurrent,
+	}
+	if concurrent > 0 {
+		fields["runner_process_saturation_percent"] = jobs / concurrent * 100
+	}
+	slist.PushSamples(inputName, fields, tags)
+}
+
+// get requests the api of path, and decodes the response into v if it's not nil
+func (ins *Instance) get(path string, query url.Values, v interface{}) (http.Header, error) {
+	u := ins.URL + "/api/v4" + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if ins.PrivateToken != "" {
+		req.Header.Set("PRIVATE-TOKEN", ins.PrivateToken)
+	}
+
+	resp, err := httpx.DoJSON(ins.client, req, path, v)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Header, nil
+}
+
+// total returns X-Total of the list api, which is omitted by gitlab if there are more than 10,000 items
+func (ins *Instance) total(path string, query url.Values) (int64, error) {
+	query.Set("per_page", "1")
+	header, err := ins.get(path, query, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	total := header.Get("X-Total")
+	if total == "" {
+		return 0, fmt.Errorf("X-Total of %s is not returned", path)
+	}
+	return strconv.ParseInt(total, 10, 64)
+}
+
+func (ins *Instance) scrape(u string) (map[string]*dto.MetricFamily, error) {
+	resp, err := ins.client.Get(u)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("returned HTTP status %s", resp.Status)
+	}
+
+	var parser expfmt.TextParser
+	return parser.TextToMetricFamilies(resp.Body)
+}
@@ -0,0 +1,358 @@
+package gnmi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/jsonx"
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/pkg/protox"
+	"flashcat.cloud/categraf/pkg/tls"
+	"flashcat.cloud/categraf/types"
+)
+
+var gnmiLog = logger.New("input.gnmi")
+
+const (
+	inputName = "gnmi"
+
+	subscribeMethod = "/gnmi.gNMI/Subscribe"
+)
+
+var metricReplacer = strings.NewReplacer("/", "_", "-", "_", ":", "_")
+
+type (
+	GNMI struct {
+		config.PluginConfig
+		Instances []*Instance `toml:"instances"`
+	}
+
+	Instance struct {
+		config.InstanceConfig
+
+		Addresses     []string        `toml:"addresses"`
+		Username      string          `toml:"username"`
+		Password      string          `toml:"password"`
+		Encoding      string          `toml:"encoding"`
+		Origin        string          `toml:"origin"`
+		Prefix        string          `toml:"prefix"`
+		Target        string          `toml:"target"`
+		UpdatesOnly   bool            `toml:"updates_only"`
+		Redial        config.Duration `toml:"redial"`
+		Subscriptions []*Subscription `toml:"subscriptions"`
+		tls.ClientConfig
+
+		encoding uint64
+		prefix   path
+		subs     []subscription
+		buffer   *types.SampleList
+		cancel   context.CancelFunc
+		wg       sync.WaitGroup
+	}
+
+	Subscription struct {
+		Name              string          `toml:"name"`
+		Origin            string          `toml:"origin"`
+		Path              string          `toml:"path"`
+		SubscriptionMode  string          `toml:"subscription_mode"`
+		SampleInterval    config.Duration `toml:"sample_interval"`
+		SuppressRedundant bool            `toml:"suppress_redundant"`
+		HeartbeatInterval config.Duration `toml:"heartbeat_interval"`
+
+		names []string
+	}
+)
+
+var _ inputs.SampleGatherer = new(Instance)
+var _ inputs.Input = new(GNMI)
+var _ inputs.InstancesGetter = new(GNMI)
+
+func init() {
+	inputs.Add(inputName, func() inputs.Input {
+		return &GNMI{}
+	})
+}
+
+func (g *GNMI) Clone() inputs.Input {
+	return &GNMI{}
+}
+
+func (g *GNMI) Name() string {
+	return inputName
+}
+
+func (g *GNMI) GetInstances() []inputs.Instance {
+	ret := make([]inputs.Instance, len(g.Instances))
+	for i := 0; i < len(g.Instances); i++ {
+		ret[i] = g.Instances[i]
+	}
+	return ret
+}
+
+func (g *GNMI) Drop() {
+	for i := 0; i < len(g.Instances); i++ {
+		g.Instances[i].stop()
+	}
+}
+
+func (ins *Instance) Init() error {
+	if len(ins.Addresses) == 0 || len(ins.Subscriptions) == 0 {
+		return types.ErrInstancesEmpty
+	}
+
+	switch strings.ToLower(ins.Encoding) {
+	case "", "proto":
+		ins.encoding = encodingProto
+	case "json":
+		ins.encoding = encodingJSON
+	case "json_ietf":
+		ins.encoding = encodingJSONIETF
+	case "bytes":
+		ins.encoding = encodingBytes
+	case "ascii":
+		ins.encoding = encodingASCII
+	default:
+		return fmt.Errorf("unsupported encoding: %s", ins.Encoding)
+	}
+
+	if ins.Redial == 0 {
+		ins.Redial = config.Duration(10 * time.Second)
+	}
+
+	var err error
+	ins.prefix, err = parsePath(ins.Origin, ins.Prefix, ins.Target)
+	if err != nil {
+		return fmt.Errorf("invalid prefix %s: %v", ins.Prefix, err)
+	}
+
+	ins.subs = ins.subs[:0]
+	for _, s := range ins.Subscriptions {
+		p, err := parsePath(s.Origin, s.Path, "")
+		if err != nil {
+			return fmt.Errorf("invalid subscription path %s: %v", s.Path, err)
+		}
+		s.names = p.names()
+
+		sub := subscription{
+			Path:              p,
+			SampleInterval:    uint64(time.Duration(s.SampleInterval).Nanoseconds()),
+			SuppressRedundant: s.SuppressRedundant,
+			HeartbeatInterval: uint64(time.Duration(s.HeartbeatInterval).Nanoseconds()),
+		}
+		switch strings.ToLower(s.SubscriptionMode) {
+		case "", "target_defined":
+			sub.Mode = subscriptionModeTargetDefined
+		case "on_change":
+			sub.Mode = subscriptionModeOnChange
+		case "sample":
+			sub.Mode = subscriptionModeSample
+		default:
+			return fmt.Errorf("unsupported subscription mode: %s", s.SubscriptionMode)
+		}
+		ins.subs = append(ins.subs, sub)
+	}
+
+	opts := []grpc.DialOption{grpc.WithDefaultCallOptions(grpc.ForceCodec(protox.RawCodec{}))}
+	tlsCfg, err := ins.ClientConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+	if tlsCfg != nil {
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(tlsCfg)))
+	} else {
+		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	}
+
+	ins.buffer = types.NewSampleList()
+	ctx, cancel := context.WithCancel(context.Background())
+	ins.cancel = cancel
+	for _, addr := range ins.Addresses {
+		ins.wg.Add(1)
+		go ins.subscribeLoop(ctx, addr, opts)
+	}
+	return nil
+}
+
+func (ins *Instance) stop() {
+	if ins.cancel != nil {
+		ins.cancel()
+		ins.wg.Wait()
+	}
+}
+
+// Gather drains the samples received from the subscriptions since last gather
+func (ins *Instance) Gather(slist *types.SampleList) {
+	slist.PushFrontN(ins.buffer.PopBackAll())
+}
+
+func (ins *Instance) subscribeLoop(ctx context.Context, addr string, opts []grpc.DialOption) {
+	defer ins.wg.Done()
+	for {
+		err := ins.subscribe(ctx, addr, opts)
+		if ctx.Err() != nil {
+			return
+		}
+		gnmiLog.Errorf("gnmi subscription to %v stopped: %v redial after %v", addr, err, time.Duration(ins.Redial))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Duration(ins.Redial)):
+		}
+	}
+}
+
+func (ins *Instance) subscribe(ctx context.Context, addr string, opts []grpc.DialOption) error {
+	conn, err := grpc.DialContext(ctx, addr, opts...)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if ins.Username != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "username", ins.Username, "password", ins.Password)
+	}
+
+	stream, err := conn.NewStream(ctx, &grpc.StreamDesc{
+		StreamName:    "Subscribe",
+		ServerStreams: true,
+		ClientStreams: true,
+	}, subscribeMethod)
+	if err != nil {
+		return err
+	}
+
+	req := encodeSubscribeRequest(ins.prefix, ins.subs, ins.encoding, ins.UpdatesOnly)
+	if err := stream.SendMsg(req); err != nil {
+		return err
+	}
+
+	if config.Config.DebugMode {
+		gnmiLog.Debugf("gnmi subscribed to %v", addr)
+	}
+
+	for {
+		var resp []byte
+		if err := stream.RecvMsg(&resp); err != nil {
+			return err
+		}
+
+		n, err := decodeSubscribeResponse(resp)
+		if err != nil {
+			return err
+		}
+		if n == nil {
+			continue
+		}
+		ins.handleNotification(addr, n)
+	}
+}
+
+func (ins *Instance) handleNotification(addr string, n *notification) {
+	ts := time.Unix(0, n.Timestamp)
+	if n.Timestamp == 0 {
+		ts = time.Now()
+	}
+
+	for _, u := range n.Updates {
+		labels := map[string]string{"source": addr}
+		if n.Prefix.Target != "" {
+			labels["target"] = n.Prefix.Target
+		}
+
+		elems := make([]pathElem, 0, len(n.Prefix.Elems)+len(u.Path.Elems))
+		elems = append(elems, n.Prefix.Elems...)
+		elems = append(elems, u.Path.Elems...)
+
+		names := make([]string, 0, len(elems))
+		for _, e := range elems {
+			names = append(names, e.Name)
+			for k, v := range e.Keys {
+				key := metricReplacer.Replace(k)
+				// the same key on different levels, e.g. interface name and subinterface index
+				if _, has := labels[key]; has {
+					key = metricReplacer.Replace(e.Name) + "_" + key
+				}
+				labels[key] = v
+			}
+		}
+
+		name := ins.metricName(names)
+
+		switch v := u.Value.(type) {
+		case nil:
+			continue
+		case jsonValue:
+			var obj interface{}
+			if err := json.Unmarshal(v, &obj); err != nil {
+				gnmiLog.Errorf("failed to decode json value of %v error: %v", strings.Join(names, "/"), err)
+				continue
+			}
+			if f, ok := obj.(float64); ok {
+				ins.buffer.PushFront(types.NewSample(inputName, name, f, labels).SetTime(ts))
+				continue
+			}
+			flattener := jsonx.JSONFlattener{}
+			if err := flattener.FullFlattenJSON("", obj, false, true); err != nil {
+				gnmiLog.Errorf("failed to flatten json value of %v error: %v", strings.Join(names, "/"), err)
+				continue
+			}
+			for field, fv := range flattener.Fields {
+				ins.buffer.PushFront(types.NewSample(inputName, name+"_"+metricReplacer.Replace(field), fv, labels).SetTime(ts))
+			}
+		case string:
+			// string leaves are mostly states or descriptions, non-numeric values are dropped by writer
+			ins.buffer.PushFront(types.NewSample(inputName, name, v, labels).SetTime(ts))
+		default:
+			ins.buffer.PushFront(types.NewSample(inputName, name, v, labels).SetTime(ts))
+		}
+	}
+}
+
+// metricName uses the name of the longest matched subscription as the prefix of metric name
+func (ins *Instance) metricName(names []string) string {
+	var (
+		matched *Subscription
+		size    int
+	)
+	for _, s := range ins.Subscriptions {
+		if s.Name == "" || len(s.names) > len(names) || len(s.names) < size {
+			continue
+		}
+		ok := true
+		for i := range s.names {
+			if s.names[i] != names[i] {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			matched = s
+			size = len(s.names)
+		}
+	}
+
+	if matched == nil {
+		return metricReplacer.Replace(strings.Join(names, "_"))
+	}
+
+	rest := names[size:]
+	if len(rest) == 0 {
+		return matched.Name
+	}
+	return matched.Name + "_" + metricReplacer.Replace(strings.Join(rest, "_"))
+}
@@ -0,0 +1,367 @@
+package gnmi
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"flashcat.cloud/categraf/pkg/protox"
+)
+
+// messages of https://github.com/openconfig/gnmi/blob/master/proto/gnmi/gnmi.proto,
+// only the fields used by the subscription are encoded or decoded
+
+type pathElem struct {
+	Name string
+	Keys map[string]string
+}
+
+type path struct {
+	Origin string
+	Target string
+	Elems  []pathElem
+}
+
+type notification struct {
+	Timestamp int64
+	Prefix    path
+	Updates   []update
+}
+
+type update struct {
+	Path  path
+	Value interface{}
+}
+
+const (
+	encodingJSON     = 0
+	encodingBytes    = 1
+	encodingProto    = 2
+	encodingASCII    = 3
+	encodingJSONIETF = 4
+)
+
+const (
+	subscriptionModeTargetDefined = 0
+	subscriptionModeOnChange      = 1
+	subscriptionModeSample        = 2
+)
+
+// parsePath parses xpath like /interfaces/interface[name=Ethernet1]/state/counters
+func parsePath(origin, s, target string) (path, error) {
+	p := path{Origin: origin, Target: target}
+	s = strings.TrimSpace(s)
+	if s == "" || s == "/" {
+		return p, nil
+	}
+	if strings.Contains(s, ":") && origin == "" {
+		// origin:/path
+		if idx := strings.Index(s, ":/"); idx > 0 {
+			p.Origin = s[:idx]
+			s = s[idx+1:]
+		}
+	}
+
+	for _, seg := range splitPath(strings.Trim(s, "/")) {
+		elem := pathElem{}
+		idx := strings.Index(seg, "[")
+		if idx < 0 {
+			elem.Name = seg
+			p.Elems = append(p.Elems, elem)
+			continue
+		}
+
+		elem.Name = seg[:idx]
+		elem.Keys = make(map[string]string)
+		rest := seg[idx:]
+		for len(rest) > 0 {
+			if rest[0] != '[' {
+				return p, fmt.Errorf("invalid path segment: %s", seg)
+			}
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return p, fmt.Errorf("invalid path segment: %s", seg)
+			}
+			kv := strings.SplitN(rest[1:end], "=", 2)
+			if len(kv) != 2 {
+				return p, fmt.Errorf("invalid path key: %s", rest[1:end])
+			}
+			elem.Keys[kv[0]] = kv[1]
+			rest = rest[end+1:]
+		}
+		p.Elems = append(p.Elems, elem)
+	}
+	return p, nil
+}
+
+// splitPath splits path by '/', ignoring the separators inside keys
+func splitPath(s string) []string {
+	var (
+		ret   []string
+		depth int
+		start int
+	)
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case '/':
+			if depth == 0 {
+				ret = append(ret, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(ret, s[start:])
+}
+
+func (p path) encode() []byte {
+	var b []byte
+	b = protox.AppendString(b, 2, p.Origin)
+	for _, e := range p.Elems {
+		var eb []byte
+		eb = protox.AppendString(eb, 1, e.Name)
+		keys := make([]string, 0, len(e.Keys))
+		for k := range e.Keys {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			var kb []byte
+			kb = protox.AppendString(kb, 1, k)
+			kb = protox.AppendString(kb, 2, e.Keys[k])
+			eb = protox.AppendMessage(eb, 2, kb)
+		}
+		b = protox.AppendMessage(b, 3, eb)
+	}
+	b = protox.AppendString(b, 4, p.Target)
+	return b
+}
+
+// names returns the element names of the path, without keys
+func (p path) names() []string {
+	ret := make([]string, 0, len(p.Elems))
+	for _, e := range p.Elems {
+		ret = append(ret, e.Name)
+	}
+	return ret
+}
+
+func decodePath(b []byte) (path, error) {
+	p := path{}
+	err := protox.Range(b, func(f protox.Field) error {
+		switch f.Num {
+		case 1:
+			// deprecated element
+			p.Elems = append(p.Elems, pathElem{Name: f.String()})
+		case 2:
+			p.Origin = f.String()
+		case 3:
+			e, err := decodePathElem(f.Bytes)
+			if err != nil {
+				return err
+			}
+			p.Elems = append(p.Elems, e)
+		case 4:
+			p.Target = f.String()
+		}
+		return nil
+	})
+	return p, err
+}
+
+func decodePathElem(b []byte) (pathElem, error) {
+	e := pathElem{}
+	err := protox.Range(b, func(f protox.Field) error {
+		switch f.Num {
+		case 1:
+			e.Name = f.String()
+		case 2:
+			var k, v string
+			err := protox.Range(f.Bytes, func(kf protox.Field) error {
+				switch kf.Num {
+				case 1:
+					k = kf.String()
+				case 2:
+					v = kf.String()
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			if e.Keys == nil {
+				e.Keys = make(map[string]string)
+			}
+			e.Keys[k] = v
+		}
+		return nil
+	})
+	return e, err
+}
+
+type subscription struct {
+	Path              path
+	Mode              uint64
+	SampleInterval    uint64
+	SuppressRedundant bool
+	HeartbeatInterval uint64
+}
+
+// encodeSubscribeRequest encodes SubscribeRequest with a STREAM SubscriptionList
+func encodeSubscribeRequest(prefix path, subs []subscription, encoding uint64, updatesOnly bool) []byte {
+	var list []byte
+	if pb := prefix.encode(); len(pb) > 0 {
+		list = protox.AppendMessage(list, 1, pb)
+	}
+	for _, s := range subs {
+		var sb []byte
+		sb = protox.AppendMessage(sb, 1, s.Path.encode())
+		sb = protox.AppendVarint(sb, 2, s.Mode)
+		sb = protox.AppendVarint(sb, 3, s.SampleInterval)
+		sb = protox.AppendBool(sb, 4, s.SuppressRedundant)
+		sb = protox.AppendVarint(sb, 5, s.HeartbeatInterval)
+		list = protox.AppendMessage(list, 2, sb)
+	}
+	// mode STREAM is 0
+	list = protox.AppendVarint(list, 8, encoding)
+	list = protox.AppendBool(list, 9, updatesOnly)
+
+	return protox.AppendMessage(nil, 1, list)
+}
+
+// decodeSubscribeResponse returns the notification, or nil if it is a sync response
+func decodeSubscribeResponse(b []byte) (*notification, error) {
+	var (
+		n   *notification
+		err error
+	)
+	rerr := protox.Range(b, func(f protox.Field) error {
+		switch f.Num {
+		case 1:
+			n, err = decodeNotification(f.Bytes)
+			return err
+		case 4:
+			return fmt.Errorf("subscribe response error: %s", f.String())
+		}
+		return nil
+	})
+	if rerr != nil {
+		return nil, rerr
+	}
+	return n, nil
+}
+
+func decodeNotification(b []byte) (*notification, error) {
+	n := &notification{}
+	err := protox.Range(b, func(f protox.Field) error {
+		switch f.Num {
+		case 1:
+			n.Timestamp = f.Int64()
+		case 2:
+			p, err := decodePath(f.Bytes)
+			if err != nil {
+				return err
+			}
+			n.Prefix = p
+		case 4:
+			u, err := decodeUpdate(f.Bytes)
+			if err != nil {
+				return err
+			}
+			n.Updates = append(n.Updates, u)
+		}
+		return nil
+	})
+	return n, err
+}
+
+func decodeUpdate(b []byte) (update, error) {
+	u := update{}
+	err := protox.Range(b, func(f protox.Field) error {
+		switch f.Num {
+		case 1:
+			p, err := decodePath(f.Bytes)
+			if err != nil {
+				return err
+			}
+			u.Path = p
+		case 3:
+			v, err := decodeTypedValue(f.Bytes)
+			if err != nil {
+				return err
+			}
+			u.Value = v
+		}
+		return nil
+	})
+	return u, err
+}
+
+// jsonValue marks the value which should be decoded as json
+type jsonValue []byte
+
+func decodeTypedValue(b []byte) (interface{}, error) {
+	var v interface{}
+	err := protox.Range(b, func(f protox.Field) error {
+		switch f.Num {
+		case 1, 12:
+			v = f.String()
+		case 2:
+			v = f.Int64()
+		case 3:
+			v = f.Varint
+		case 4:
+			v = f.Bool()
+		case 6:
+			if f.Type == protowire.Fixed32Type {
+				v = float64(f.Float32())
+			}
+		case 7:
+			var digits int64
+			var precision uint64
+			err := protox.Range(f.Bytes, func(df protox.Field) error {
+				switch df.Num {
+				case 1:
+					digits = df.Int64()
+				case 2:
+					precision = df.Varint
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			v, err = strconv.ParseFloat(decimalString(digits, precision), 64)
+			return err
+		case 10, 11:
+			v = jsonValue(append([]byte(nil), f.Bytes...))
+		case 14:
+			v = f.Float64()
+		}
+		return nil
+	})
+	return v, err
+}
+
+func decimalString(digits int64, precision uint64) string {
+	s := strconv.FormatInt(digits, 10)
+	if precision == 0 {
+		return s
+	}
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+	for uint64(len(s)) <= precision {
+		s = "0" + s
+	}
+	s = s[:uint64(len(s))-precision] + "." + s[uint64(len(s))-precision:]
+	if neg {
+		s = "-" + s
+	}
+	return s
+}
@@ -1,15 +1,17 @@
 package greenplum
 
 import (
-	"log"
 	"os/exec"
 	"strings"
 
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/types"
 )
 
+var greenplumLog = logger.New("input.greenplum")
+
 const inputName = "greenplum"
 
 type Greenplum struct {
@@ -48,7 +50,7 @@ func (ins *Greenplum) Gather(slist *types.SampleList) {
 	stateValue = strings.TrimSpace(stateValue)
 	gpstate := strings.Fields(stateValue)
 	if len(gpstate)%7 != 0 {
-		log.Println("E! failed to parse gpstate -m output: %v", gpstate)
+		greenplumLog.Errorf("failed to parse gpstate -m output: %v", gpstate)
 		return
 	}
 	line := len(gpstate) / 7
@@ -0,0 +1,198 @@
+package hadoop_hdfs
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/filter"
+	"flashcat.cloud/categraf/pkg/httpx"
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/pkg/stringx"
+	"flashcat.cloud/categraf/pkg/tls"
+	"flashcat.cloud/categraf/types"
+)
+
+var hadoopHdfsLog = logger.New("input.hadoop_hdfs")
+
+const (
+	inputName = "hadoop_hdfs"
+
+	jmxPath    = "/jmx"
+	beanDomain = "Hadoop:"
+)
+
+// the port of rpc beans is a label, e.g. RpcActivityForPort8020
+var portSuffix = regexp.MustCompile(`ForPort(\d+)$`)
+
+type HadoopHDFS struct {
+	config.PluginConfig
+	Instances []*Instance `toml:"instances"`
+}
+
+type Instance struct {
+	config.InstanceConfig
+
+	// the http addresses of namenodes, datanodes or journalnodes, e.g. http://namenode:9870
+	URLs []string `toml:"urls"`
+	// the names of beans, e.g. Hadoop:service=NameNode,name=FSNamesystem, globs are supported
+	BeanInclude []string        `toml:"bean_include"`
+	BeanExclude []string        `toml:"bean_exclude"`
+	Timeout     config.Duration `toml:"timeout"`
+	tls.ClientConfig
+
+	client     *http.Client
+	beanFilter filter.Filter
+}
+
+type bean map[string]interface{}
+
+var _ inputs.SampleGatherer = new(Instance)
+var _ inputs.Input = new(HadoopHDFS)
+var _ inputs.InstancesGetter = new(HadoopHDFS)
+
+func init() {
+	inputs.Add(inputName, func() inputs.Input {
+		return &HadoopHDFS{}
+	})
+}
+
+func (h *HadoopHDFS) Clone() inputs.Input {
+	return &HadoopHDFS{}
+}
+
+func (h *HadoopHDFS) Name() string {
+	return inputName
+}
+
+func (h *HadoopHDFS) GetInstances() []inputs.Instance {
+	ret := make([]inputs.Instance, len(h.Instances))
+	for i := 0; i < len(h.Instances); i++ {
+		ret[i] = h.Instances[i]
+	}
+	return ret
+}
+
+func (ins *Instance) Init() error {
+	if len(ins.URLs) == 0 {
+		return types.ErrInstancesEmpty
+	}
+	if ins.Timeout <= 0 {
+		ins.Timeout = config.Duration(5 * time.Second)
+	}
+	if len(ins.BeanInclude) == 0 {
+		ins.BeanInclude = []string{beanDomain + "*"}
+	}
+
+	var err error
+	ins.beanFilter, err = filter.NewIncludeExcludeFilter(ins.BeanInclude, ins.BeanExclude)
+	if err != nil {
+		return fmt.Errorf("failed to compile bean filters: %v", err)
+	}
+
+	tlsConfig, err := ins.ClientConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+	ins.client = &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		},
+		Timeout: time.Duration(ins.Timeout),
+	}
+	return nil
+}
+
+func (ins *Instance) Gather(slist *types.SampleList) {
+	var wg sync.WaitGroup
+	for _, u := range ins.URLs {
+		wg.Add(1)
+		go func(u string) {
+			defer wg.Done()
+			ins.gatherURL(slist, strings.TrimRight(u, "/"))
+		}(u)
+	}
+	wg.Wait()
+}
+
+func (ins *Instance) gatherURL(slist *types.SampleList, u string) {
+	tags := map[string]string{"url": u}
+
+	beans, err := ins.getBeans(u)
+	if err != nil {
+		hadoopHdfsLog.Errorf("failed to get jmx of hadoop: %v error: %v", u, err)
+		slist.PushSample(inputName, "up", 0, tags)
+		return
+	}
+	slist.PushSample(inputName, "up", 1, tags)
+
+	for _, b := range beans {
+		name, _ := b["name"].(string)
+		if !strings.HasPrefix(name, beanDomain) || !ins.beanFilter.Match(name) {
+			continue
+		}
+		ins.gatherBean(slist, name, b, tags)
+	}
+}
+
+// gatherBean reports the numbers and booleans of the bean, e.g. CapacityUsed of
+// Hadoop:service=NameNode,name=FSNamesystem is hadoop_hdfs_fs_namesystem_capacity_used{role="namenode"}
+func (ins *Instance) gatherBean(slist *types.SampleList, name string, b bean, tags map[string]string) {
+	props := make(map[string]string)
+	for _, kv := range strings.Split(strings.TrimPrefix(name, beanDomain), ",") {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			props[k] = v
+		}
+	}
+
+	service, beanName := props["service"], props["name"]
+	if service == "" || beanName == "" {
+		return
+	}
+
+	labels := map[string]string{"role": strings.ToLower(service)}
+	for k, v := range tags {
+		labels[k] = v
+	}
+
+	// e.g. DataNodeActivity-datanode01-9866
+	if i := strings.Index(beanName, "-"); i > 0 {
+		beanName = beanName[:i]
+	}
+	if m := portSuffix.FindStringSubmatch(beanName); m != nil {
+		labels["port"] = m[1]
+		beanName = strings.TrimSuffix(beanName, m[0])
+	}
+	if sub := props["sub"]; sub != "" {
+		beanName += "_" + sub
+	}
+
+	prefix := inputName + "_" + stringx.SnakeCase(beanName)
+	fields := make(map[string]interface{})
+	for attr, value := range b {
+		switch v := value.(type) {
+		case float64, bool:
+			fields[stringx.SnakeCase(attr)] = v
+		case string:
+			// active or standby of the namenode in HA
+			if attr == "State" && service == "NameNode" {
+				fields["active"] = v == "active"
+			}
+		}
+	}
+	slist.PushSamples(prefix, fields, labels)
+}
+
+func (ins *Instance) getBeans(u string) ([]bean, error) {
+	var jmx struct {
+		Beans []bean `json:"beans"`
+	}
+	_, err := httpx.GetJSON(ins.client, u+jmxPath, jmxPath, &jmx)
+	return jmx.Beans, err
+}
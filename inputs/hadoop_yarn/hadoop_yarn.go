@@ -0,0 +1,271 @@
+package hadoop_yarn
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/httpx"
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/pkg/stringx"
+	"flashcat.cloud/categraf/pkg/tls"
+	"flashcat.cloud/categraf/types"
+)
+
+var hadoopYarnLog = logger.New("input.hadoop_yarn")
+
+const (
+	inputName = "hadoop_yarn"
+
+	clusterInfoPath    = "/ws/v1/cluster/info"
+	clusterMetricsPath = "/ws/v1/cluster/metrics"
+	schedulerPath      = "/ws/v1/cluster/scheduler"
+	nodesPath          = "/ws/v1/cluster/nodes"
+)
+
+type HadoopYarn struct {
+	config.PluginConfig
+	Instances []*Instance `toml:"instances"`
+}
+
+type Instance struct {
+	config.InstanceConfig
+
+	// the resourcemanager, e.g. http://resourcemanager:8088, the standby redirects to the active one
+	URL     string          `toml:"url"`
+	Timeout config.Duration `toml:"timeout"`
+	tls.ClientConfig
+
+	// cluster | queues | nodes
+	Collect []string `toml:"collect"`
+
+	client  *http.Client
+	collect map[string]bool
+}
+
+type node struct {
+	ID                    string  `json:"id"`
+	Rack                  string  `json:"rack"`
+	State                 string  `json:"state"`
+	NumContainers         float64 `json:"numContainers"`
+	UsedMemoryMB          float64 `json:"usedMemoryMB"`
+	AvailMemoryMB         float64 `json:"availMemoryMB"`
+	UsedVirtualCores      float64 `json:"usedVirtualCores"`
+	AvailableVirtualCores float64 `json:"availableVirtualCores"`
+	LastHealthUpdate      float64 `json:"lastHealthUpdate"`
+}
+
+var _ inputs.SampleGatherer = new(Instance)
+var _ inputs.Input = new(HadoopYarn)
+var _ inputs.InstancesGetter = new(HadoopYarn)
+
+func init() {
+	inputs.Add(inputName, func() inputs.Input {
+		return &HadoopYarn{}
+	})
+}
+
+func (h *HadoopYarn) Clone() inputs.Input {
+	return &HadoopYarn{}
+}
+
+func (h *HadoopYarn) Name() string {
+	return inputName
+}
+
+func (h *HadoopYarn) GetInstances() []inputs.Instance {
+	ret := make([]inputs.Instance, len(h.Instances))
+	for i := 0; i < len(h.Instances); i++ {
+		ret[i] = h.Instances[i]
+	}
+	return ret
+}
+
+func (ins *Instance) Init() error {
+	if ins.URL == "" {
+		return types.ErrInstancesEmpty
+	}
+	if ins.Timeout <= 0 {
+		ins.Timeout = config.Duration(5 * time.Second)
+	}
+	if len(ins.Collect) == 0 {
+		ins.Collect = []string{"cluster", "queues"}
+	}
+	ins.URL = strings.TrimRight(ins.URL, "/")
+
+	ins.collect = make(map[string]bool, len(ins.Collect))
+	for _, c := range ins.Collect {
+		switch c {
+		case "cluster", "queues", "nodes":
+			ins.collect[c] = true
+		default:
+			return fmt.Errorf("invalid collect %s, cluster, queues or nodes", c)
+		}
+	}
+
+	tlsConfig, err := ins.ClientConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+	ins.client = &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		},
+		Timeout: time.Duration(ins.Timeout),
+	}
+	return nil
+}
+
+func (ins *Instance) Gather(slist *types.SampleList) {
+	var info struct {
+		ClusterInfo struct {
+			State   string `json:"state"`
+			HAState string `json:"haState"`
+		} `json:"clusterInfo"`
+	}
+	if err := ins.get(clusterInfoPath, &info); err != nil {
+		hadoopYarnLog.Errorf("failed to get cluster info of yarn: %v error: %v", ins.URL, err)
+		slist.PushSample(inputName, "up", 0)
+		return
+	}
+	slist.PushSample(inputName, "up", 1)
+	slist.PushSample(inputName, "started", info.ClusterInfo.State == "STARTED")
+	if info.ClusterInfo.HAState != "" {
+		slist.PushSample(inputName, "ha_active", info.ClusterInfo.HAState == "ACTIVE")
+	}
+
+	if ins.collect["cluster"] {
+		ins.gatherClusterMetrics(slist)
+	}
+	if ins.collect["queues"] {
+		ins.gatherQueues(slist)
+	}
+	if ins.collect["nodes"] {
+		ins.gatherNodes(slist)
+	}
+}
+
+// gatherClusterMetrics reports the applications by state, the memory, vcores and containers
+// allocated, pending and reserved, and the nodes by state
+func (ins *Instance) gatherClusterMetrics(slist *types.SampleList) {
+	var metrics struct {
+		ClusterMetrics map[string]interface{} `json:"clusterMetrics"`
+	}
+	if err := ins.get(clusterMetricsPath, &metrics); err != nil {
+		hadoopYarnLog.Errorf("failed to get cluster metrics of yarn: %v error: %v", ins.URL, err)
+		return
+	}
+
+	fields := make(map[string]interface{})
+	for k, v := range metrics.ClusterMetrics {
+		if f, ok := v.(float64); ok {
+			fields[stringx.SnakeCase(k)] = f
+		}
+	}
+	slist.PushSamples(inputName+"_cluster", fields)
+}
+
+// gatherQueues reports the queues of the capacity or fair scheduler, the numbers of each queue
+// are reported as is, e.g. absoluteUsedCapacity of capacity scheduler, and numPendingApps of fair scheduler
+func (ins *Instance) gatherQueues(slist *types.SampleList) {
+	var scheduler struct {
+		Scheduler struct {
+			SchedulerInfo map[string]interface{} `json:"schedulerInfo"`
+		} `json:"scheduler"`
+	}
+	if err := ins.get(schedulerPath, &scheduler); err != nil {
+		hadoopYarnLog.Errorf("failed to get scheduler of yarn: %v error: %v", ins.URL, err)
+		return
+	}
+
+	info := scheduler.Scheduler.SchedulerInfo
+	switch {
+	case info["rootQueue"] != nil:
+		// fair scheduler
+		walkQueue(slist, info["rootQueue"])
+	case info["queues"] != nil:
+		// capacity scheduler, the root queue is the scheduler info itself
+		walkQueue(slist, info)
+	}
+}
+
+func walkQueue(slist *types.SampleList, v interface{}) {
+	queue, ok := v.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	if name, ok := queue["queueName"].(string); ok {
+		fields := make(map[string]interface{})
+		for k, v := range queue {
+			if f, ok := v.(float64); ok {
+				fields[stringx.SnakeCase(k)] = f
+			}
+		}
+		for _, k := range []string{"resourcesUsed", "usedResources"} {
+			res, ok := queue[k].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if f, ok := res["memory"].(float64); ok {
+				fields["used_memory_mb"] = f
+			}
+			if f, ok := res["vCores"].(float64); ok {
+				fields["used_vcores"] = f
+			}
+		}
+		slist.PushSamples(inputName+"_queue", fields, map[string]string{"queue": name})
+	}
+
+	// queues.queue of capacity scheduler, childQueues.queue of fair scheduler
+	for _, k := range []string{"queues", "childQueues"} {
+		children, ok := queue[k].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		items, _ := children["queue"].([]interface{})
+		for _, item := range items {
+			walkQueue(slist, item)
+		}
+	}
+}
+
+func (ins *Instance) gatherNodes(slist *types.SampleList) {
+	var nodes struct {
+		Nodes struct {
+			Node []node `json:"node"`
+		} `json:"nodes"`
+	}
+	if err := ins.get(nodesPath, &nodes); err != nil {
+		hadoopYarnLog.Errorf("failed to get nodes of yarn: %v error: %v", ins.URL, err)
+		return
+	}
+
+	for _, n := range nodes.Nodes.Node {
+		tags := map[string]string{"node": n.ID, "rack": n.Rack}
+		slist.PushSamples(inputName+"_node", map[string]interface{}{
+			"running":                      n.State == "RUNNING",
+			"containers":                   n.NumContainers,
+			"used_memory_mb":               n.UsedMemoryMB,
+			"avail_memory_mb":              n.AvailMemoryMB,
+			"used_virtual_cores":           n.UsedVirtualCores,
+			"available_virtual_cores":      n.AvailableVirtualCores,
+			"last_health_update_timestamp": n.LastHealthUpdate / 1000,
+		}, tags)
+	}
+}
+
+func (ins *Instance) get(path string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, ins.URL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	_, err = httpx.DoJSON(ins.client, req, path, v)
+	return err
+}
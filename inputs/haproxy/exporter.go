@@ -20,7 +20,6 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"net"
 	"net/http"
 	_ "net/http/pprof"
@@ -375,14 +374,14 @@ func (e *Exporter) scrape(ch chan<- prometheus.Metric) (up float64) {
 	if e.fetchInfo != nil {
 		infoReader, err := e.fetchInfo()
 		if err != nil {
-			log.Println("E! failed to fetch haproxy info:", err)
+			haproxyLog.Errorf("failed to fetch haproxy info: %v", err)
 			return 0
 		}
 		defer infoReader.Close()
 
 		info, err := e.parseInfo(infoReader)
 		if err != nil {
-			log.Println("E! failed to parse haproxy info:", err)
+			haproxyLog.Errorf("failed to parse haproxy info: %v", err)
 		} else {
 			ch <- prometheus.MustNewConstMetric(haproxyInfo, prometheus.GaugeValue, 1, info.ReleaseDate, info.Version)
 		}
@@ -390,7 +389,7 @@ func (e *Exporter) scrape(ch chan<- prometheus.Metric) (up float64) {
 
 	body, err := e.fetchStat()
 	if err != nil {
-		log.Println("E! failed to fetch haproxy stat:", err)
+		haproxyLog.Errorf("failed to fetch haproxy stat: %v", err)
 		return 0
 	}
 	defer body.Close()
@@ -408,11 +407,11 @@ loop:
 			break loop
 		default:
 			if _, ok := err.(*csv.ParseError); ok {
-				log.Println("E! failed to parse csv:", err)
+				haproxyLog.Errorf("failed to parse csv: %v", err)
 				e.csvParseFailures.Inc()
 				continue loop
 			}
-			log.Println("E! failed to read csv:", err)
+			haproxyLog.Errorf("failed to read csv: %v", err)
 			return 0
 		}
 		e.parseRow(row, ch)
@@ -447,7 +446,7 @@ func (e *Exporter) parseInfo(i io.Reader) (versionInfo, error) {
 
 func (e *Exporter) parseRow(csvRow []string, ch chan<- prometheus.Metric) {
 	if len(csvRow) < minimumCsvFieldCount {
-		log.Println("E! Parser received unexpected number of CSV fields", "min", minimumCsvFieldCount, "received", len(csvRow))
+		haproxyLog.Errorf("Parser received unexpected number of CSV fields min %v received %v", minimumCsvFieldCount, len(csvRow))
 		e.csvParseFailures.Inc()
 		return
 	}
@@ -511,7 +510,7 @@ func (e *Exporter) exportCsvFields(metrics map[int]metricInfo, csvRow []string,
 			value = float64(valueInt)
 		}
 		if err != nil {
-			log.Println("E! Can't parse CSV field value", "value", valueStr, "err", err)
+			haproxyLog.Errorf("Can't parse CSV field value value %v err %v", valueStr, err)
 			e.csvParseFailures.Inc()
 			continue
 		}
@@ -2,14 +2,16 @@ package haproxy
 
 import (
 	"fmt"
-	"log"
 	"time"
 
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/types"
 )
 
+var haproxyLog = logger.New("input.haproxy")
+
 const inputName = "haproxy"
 
 type HAProxy struct {
@@ -109,6 +111,6 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 
 	err := inputs.Collect(ins.e, slist)
 	if err != nil {
-		log.Println("E! failed to collect metrics:", err)
+		haproxyLog.Errorf("failed to collect metrics: %v", err)
 	}
 }
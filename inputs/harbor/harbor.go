@@ -0,0 +1,316 @@
+package harbor
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/httpx"
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/pkg/tls"
+	"flashcat.cloud/categraf/types"
+)
+
+var harborLog = logger.New("input.harbor")
+
+const (
+	inputName = "harbor"
+
+	apiPrefix      = "/api/v2.0"
+	healthPath     = "/health"
+	statisticsPath = "/statistics"
+	quotasPath     = "/quotas"
+	gcPath         = "/system/gc"
+	auditLogsPath  = "/audit-logs"
+
+	statusHealthy = "healthy"
+	pageSize      = 100
+
+	// the time of the q param of audit logs
+	opTimeLayout = "2006-01-02 15:04:05"
+)
+
+type Harbor struct {
+	config.PluginConfig
+	Instances []*Instance `toml:"instances"`
+}
+
+type Instance struct {
+	config.InstanceConfig
+
+	URL string `toml:"url"`
+	// the statistics, quotas, gc and audit logs require the system admin, only health is gathered without credentials
+	Username string          `toml:"username"`
+	Password string          `toml:"password"`
+	Timeout  config.Duration `toml:"timeout"`
+	tls.ClientConfig
+
+	// health | statistics | quotas | gc | audit_logs, all but audit_logs by default
+	Collect []string `toml:"collect"`
+
+	client  *http.Client
+	collect map[string]bool
+
+	// the pulls and pushes counted from audit logs, since the first gather
+	lastAudit time.Time
+	pulls     float64
+	pushes    float64
+}
+
+type quota struct {
+	Ref struct {
+		Name string `json:"name"`
+	} `json:"ref"`
+	Hard struct {
+		Storage float64 `json:"storage"`
+	} `json:"hard"`
+	Used struct {
+		Storage float64 `json:"storage"`
+	} `json:"used"`
+}
+
+type gcJob struct {
+	JobStatus    string    `json:"job_status"`
+	CreationTime time.Time `json:"creation_time"`
+	UpdateTime   time.Time `json:"update_time"`
+}
+
+var _ inputs.SampleGatherer = new(Instance)
+var _ inputs.Input = new(Harbor)
+var _ inputs.InstancesGetter = new(Harbor)
+
+func init() {
+	inputs.Add(inputName, func() inputs.Input {
+		return &Harbor{}
+	})
+}
+
+func (h *Harbor) Clone() inputs.Input {
+	return &Harbor{}
+}
+
+func (h *Harbor) Name() string {
+	return inputName
+}
+
+func (h *Harbor) GetInstances() []inputs.Instance {
+	ret := make([]inputs.Instance, len(h.Instances))
+	for i := 0; i < len(h.Instances); i++ {
+		ret[i] = h.Instances[i]
+	}
+	return ret
+}
+
+func (ins *Instance) Init() error {
+	if ins.URL == "" {
+		return types.ErrInstancesEmpty
+	}
+	if ins.Timeout <= 0 {
+		ins.Timeout = config.Duration(5 * time.Second)
+	}
+	if len(ins.Collect) == 0 {
+		ins.Collect = []string{"health", "statistics", "quotas", "gc"}
+	}
+	ins.URL = strings.TrimRight(ins.URL, "/")
+
+	ins.collect = make(map[string]bool, len(ins.Collect))
+	for _, c := range ins.Collect {
+		switch c {
+		case "health", "statistics", "quotas", "gc", "audit_logs":
+			ins.collect[c] = true
+		default:
+			return fmt.Errorf("invalid collect %s, health, statistics, quotas, gc or audit_logs", c)
+		}
+	}
+
+	tlsConfig, err := ins.ClientConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+	ins.client = &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		},
+		Timeout: time.Duration(ins.Timeout),
+	}
+	return nil
+}
+
+func (ins *Instance) Gather(slist *types.SampleList) {
+	var health struct {
+		Status     string `json:"status"`
+		Components []struct {
+			Name   string `json:"name"`
+			Status string `json:"status"`
+			Error  string `json:"error"`
+		} `json:"components"`
+	}
+	if _, err := ins.get(healthPath, nil, &health); err != nil {
+		harborLog.Errorf("failed to request harbor: %v error: %v", ins.URL, err)
+		slist.PushSample(inputName, "up", 0)
+		return
+	}
+	slist.PushSample(inputName, "up", 1)
+
+	if ins.collect["health"] {
+		slist.PushSample(inputName, "healthy", health.Status == statusHealthy)
+		for _, c := range health.Components {
+			if c.Status != statusHealthy && config.Config.DebugMode {
+				harborLog.Debugf("harbor component %v is %v %v", c.Name, c.Status+":", c.Error)
+			}
+			slist.PushSample(inputName, "component_healthy", c.Status == statusHealthy, map[string]string{"component": c.Name})
+		}
+	}
+
+	if ins.collect["statistics"] {
+		ins.gatherStatistics(slist)
+	}
+	if ins.collect["quotas"] {
+		ins.gatherQuotas(slist)
+	}
+	if ins.collect["gc"] {
+		ins.gatherGC(slist)
+	}
+	if ins.collect["audit_logs"] {
+		ins.gatherAuditLogs(slist)
+	}
+}
+
+func (ins *Instance) gatherStatistics(slist *types.SampleList) {
+	var stats struct {
+		PrivateProjectCount     float64 `json:"private_project_count"`
+		PublicProjectCount      float64 `json:"public_project_count"`
+		PrivateRepoCount        float64 `json:"private_repo_count"`
+		PublicRepoCount         float64 `json:"public_repo_count"`
+		TotalStorageConsumption float64 `json:"total_storage_consumption"`
+	}
+	if _, err := ins.get(statisticsPath, nil, &stats); err != nil {
+		harborLog.Errorf("failed to get statistics of harbor: %v error: %v", ins.URL, err)
+		return
+	}
+
+	slist.PushSample(inputName, "projects", stats.PrivateProjectCount, map[string]string{"public": "false"})
+	slist.PushSample(inputName, "projects", stats.PublicProjectCount, map[string]string{"public": "true"})
+	slist.PushSample(inputName, "repositories", stats.PrivateRepoCount, map[string]string{"public": "false"})
+	slist.PushSample(inputName, "repositories", stats.PublicRepoCount, map[string]string{"public": "true"})
+	slist.PushSample(inputName, "storage_consumption_bytes", stats.TotalStorageConsumption)
+}
+
+// gatherQuotas reports the storage used by projects
+func (ins *Instance) gatherQuotas(slist *types.SampleList) {
+	for page := 1; ; page++ {
+		var quotas []quota
+		query := url.Values{"page": {strconv.Itoa(page)}, "page_size": {strconv.Itoa(pageSize)}, "reference": {"project"}}
+		if _, err := ins.get(quotasPath, query, &quotas); err != nil {
+			harborLog.Errorf("failed to list quotas of harbor: %v error: %v", ins.URL, err)
+			return
+		}
+
+		for _, q := range quotas {
+			tags := map[string]string{"project": q.Ref.Name}
+			fields := map[string]interface{}{
+				"project_storage_used_bytes": q.Used.Storage,
+			}
+			// -1 is unlimited
+			if q.Hard.Storage >= 0 {
+				fields["project_storage_quota_bytes"] = q.Hard.Storage
+			}
+			slist.PushSamples(inputName, fields, tags)
+		}
+
+		if len(quotas) < pageSize {
+			return
+		}
+	}
+}
+
+// gatherGC reports the status of the latest garbage collection
+func (ins *Instance) gatherGC(slist *types.SampleList) {
+	var jobs []gcJob
+	query := url.Values{"page": {"1"}, "page_size": {"1"}, "sort": {"-creation_time"}}
+	if _, err := ins.get(gcPath, query, &jobs); err != nil {
+		harborLog.Errorf("failed to get gc history of harbor: %v error: %v", ins.URL, err)
+		return
+	}
+	if len(jobs) == 0 {
+		return
+	}
+
+	job := jobs[0]
+	status := strings.ToLower(job.JobStatus)
+	slist.PushSample(inputName, "gc_last_success", status == "success")
+	slist.PushSample(inputName, "gc_running", status == "running" || status == "pending")
+	if !job.UpdateTime.IsZero() {
+		slist.PushSample(inputName, "gc_last_timestamp", job.UpdateTime.Unix())
+	}
+}
+
+// gatherAuditLogs counts the pulls and pushes of artifacts by the audit logs since the last gather,
+// the counters start at the first gather
+func (ins *Instance) gatherAuditLogs(slist *types.SampleList) {
+	// the logs are searched by seconds, the window ends at the last whole second
+	now := time.Now().UTC().Truncate(time.Second).Add(-time.Second)
+	if ins.lastAudit.IsZero() {
+		ins.lastAudit = now
+	}
+
+	if now.After(ins.lastAudit) {
+		from := ins.lastAudit.Add(time.Second).Format(opTimeLayout)
+		to := now.Format(opTimeLayout)
+
+		pulls, err := ins.countAuditLogs("operation=pull,op_time=[" + from + "~" + to + "]")
+		if err != nil {
+			harborLog.Errorf("failed to count pulls of harbor: %v error: %v", ins.URL, err)
+			return
+		}
+		pushes, err := ins.countAuditLogs("operation=create,resource_type=artifact,op_time=[" + from + "~" + to + "]")
+		if err != nil {
+			harborLog.Errorf("failed to count pushes of harbor: %v error: %v", ins.URL, err)
+			return
+		}
+
+		ins.pulls += pulls
+		ins.pushes += pushes
+		ins.lastAudit = now
+	}
+
+	slist.PushSample(inputName, "artifact_pulls_total", ins.pulls)
+	slist.PushSample(inputName, "artifact_pushes_total", ins.pushes)
+}
+
+func (ins *Instance) countAuditLogs(q string) (float64, error) {
+	header, err := ins.get(auditLogsPath, url.Values{"q": {q}, "page": {"1"}, "page_size": {"1"}}, nil)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(header.Get("X-Total-Count"), 64)
+}
+
+// get decodes the json response of path into v if it's not nil
+func (ins *Instance) get(path string, query url.Values, v interface{}) (http.Header, error) {
+	u := ins.URL + apiPrefix + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if ins.Username != "" || ins.Password != "" {
+		req.SetBasicAuth(ins.Username, ins.Password)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpx.DoJSON(ins.client, req, path, v)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Header, nil
+}
@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"strings"
 	"sync"
@@ -163,7 +162,7 @@ func (hrp *HTTPProvider) check() error {
 func (hrp *HTTPProvider) doReq() (*httpProviderResponse, error) {
 	req, err := http.NewRequest("GET", hrp.RemoteUrl, nil)
 	if err != nil {
-		log.Println("E! http provider: build reload config request error:", err)
+		inputsLog.Errorf("http provider: build reload config request error: %v", err)
 		return nil, err
 	}
 
@@ -190,20 +189,20 @@ func (hrp *HTTPProvider) doReq() (*httpProviderResponse, error) {
 
 	resp, err := hrp.client.Do(req)
 	if err != nil {
-		log.Println("E! http provider: request reload config error:", err)
+		inputsLog.Errorf("http provider: request reload config error: %v", err)
 		return nil, err
 	}
 	defer resp.Body.Close()
 	respData, err := io.ReadAll(resp.Body)
 	if err != nil {
-		log.Println("E! http provider: request reload config error:", err)
+		inputsLog.Errorf("http provider: request reload config error: %v", err)
 		return nil, err
 	}
 
 	confResp := &httpProviderResponse{}
 	err = json.Unmarshal(respData, confResp)
 	if err != nil {
-		log.Println("E! http provider: unmarshal result error:", err)
+		inputsLog.Errorf("http provider: unmarshal result error: %v", err)
 		return nil, err
 	}
 
@@ -218,11 +217,11 @@ func (hrp *HTTPProvider) doReq() (*httpProviderResponse, error) {
 }
 
 func (hrp *HTTPProvider) LoadConfig() (bool, error) {
-	log.Println("I! http provider: start reload config from remote:", hrp.RemoteUrl)
+	inputsLog.Infof("http provider: start reload config from remote: %v", hrp.RemoteUrl)
 
 	confResp, err := hrp.doReq()
 	if err != nil {
-		log.Printf("W! http provider: request remote err: [%+v]", err)
+		inputsLog.Warnf("http provider: request remote err: [%+v]", err)
 		return false, err
 	}
 
@@ -230,7 +229,7 @@ func (hrp *HTTPProvider) LoadConfig() (bool, error) {
 	if confResp.Version == hrp.version {
 		return false, nil
 	}
-	log.Printf("I! remote version:%s, current version:%s", confResp.Version, hrp.version)
+	inputsLog.Infof("remote version:%s, current version:%s", confResp.Version, hrp.version)
 
 	// delete empty entries
 	for k, v := range confResp.Configs {
@@ -262,7 +261,7 @@ func (hrp *HTTPProvider) StartReloader() {
 				}
 				if changed {
 					if hrp.add.len() > 0 {
-						log.Println("I! http provider: new or updated inputs:", hrp.add)
+						inputsLog.Infof("http provider: new or updated inputs: %v", hrp.add)
 						for inputKey, cm := range hrp.add.iter() {
 							for _, conf := range cm {
 								hrp.op.RegisterInput(FormatInputName(hrp.Name(), inputKey), []cfg.ConfigWithFormat{conf})
@@ -271,7 +270,7 @@ func (hrp *HTTPProvider) StartReloader() {
 					}
 
 					if hrp.del.len() > 0 {
-						log.Println("I! http provider: deleted inputs:", hrp.del)
+						inputsLog.Infof("http provider: deleted inputs: %v", hrp.del)
 						for inputKey, cm := range hrp.del.iter() {
 							for sum := range cm {
 								hrp.op.DeregisterInput(FormatInputName(hrp.Name(), inputKey), sum)
@@ -325,7 +324,7 @@ func (hrp *HTTPProvider) caculateDiff(newConfigs map[string]map[string]*cfg.Conf
 	for inputKey, configs := range newConfigs {
 		for _, inputConfig := range configs {
 			if config.Config.DebugMode {
-				log.Println("D!: inputKey:", inputKey, "config sum:", inputConfig.CheckSum())
+				inputsLog.Debugf("inputKey: %v config sum: %v", inputKey, inputConfig.CheckSum())
 			}
 			cache.put(inputKey, *inputConfig)
 		}
@@ -337,20 +336,20 @@ func (hrp *HTTPProvider) caculateDiff(newConfigs map[string]map[string]*cfg.Conf
 			add, del := new.Diff(NewSet().Load(oldConfigMap))
 			for sum := range add {
 				if config.Config.DebugMode {
-					log.Println("D!: add config:", inputKey, "config sum:", sum)
+					inputsLog.Debugf("add config: %v config sum: %v", inputKey, sum)
 				}
 				hrp.add.put(inputKey, configMap[sum])
 			}
 			for sum := range del {
 				if config.Config.DebugMode {
-					log.Println("D!: delete config:", inputKey, "config sum:", sum)
+					inputsLog.Debugf("delete config: %v config sum: %v", inputKey, sum)
 				}
 				hrp.del.put(inputKey, oldConfigMap[sum])
 			}
 		} else {
 			for _, inputConfig := range configMap {
 				if config.Config.DebugMode {
-					log.Println("D!: add config:", inputKey, "config sum:", inputConfig.CheckSum())
+					inputsLog.Debugf("add config: %v config sum: %v", inputKey, inputConfig.CheckSum())
 				}
 				hrp.add.put(inputKey, inputConfig)
 			}
@@ -361,7 +360,7 @@ func (hrp *HTTPProvider) caculateDiff(newConfigs map[string]map[string]*cfg.Conf
 		if _, has := cache.get(inputKey); !has {
 			for _, inputConfig := range configMap {
 				if config.Config.DebugMode {
-					log.Println("D!: delete config:", inputKey, "config sum:", inputConfig.CheckSum())
+					inputsLog.Debugf("delete config: %v config sum: %v", inputKey, inputConfig.CheckSum())
 				}
 				hrp.del.put(inputKey, inputConfig)
 			}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
-	"log"
 	"net"
 	"net/http"
 	"net/url"
@@ -14,11 +13,14 @@ import (
 
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/pkg/netx"
 	"flashcat.cloud/categraf/pkg/tls"
 	"flashcat.cloud/categraf/types"
 )
 
+var httpResponseLog = logger.New("input.http_response")
+
 const (
 	inputName = "http_response"
 
@@ -179,7 +181,7 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 
 func (ins *Instance) gather(slist *types.SampleList, target string) {
 	if config.Config.DebugMode {
-		log.Println("D! http_response... target:", target)
+		httpResponseLog.Debugf("http_response... target: %v", target)
 	}
 
 	labels := map[string]string{"target": target}
@@ -194,7 +196,7 @@ func (ins *Instance) gather(slist *types.SampleList, target string) {
 
 	returnTags, fields, err = ins.httpGather(target)
 	if err != nil {
-		log.Println("E! failed to gather http target:", target, "error:", err)
+		httpResponseLog.Errorf("failed to gather http target: %v error: %v", target, err)
 	}
 
 	for k, v := range returnTags {
@@ -238,7 +240,7 @@ func (ins *Instance) httpGather(target string) (map[string]string, map[string]in
 	// If an error in returned, it means we are dealing with a network error, as
 	// HTTP error codes do not generate errors in the net/http library
 	if err != nil {
-		log.Println("E! network error while polling:", target, "error:", err)
+		httpResponseLog.Errorf("network error while polling: %v error: %v", target, err)
 
 		// metric: result_code
 		fields["result_code"] = ConnectionFailed
@@ -280,20 +282,20 @@ func (ins *Instance) httpGather(target string) (map[string]string, map[string]in
 
 	bs, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		log.Println("E! failed to read response body:", err)
+		httpResponseLog.Errorf("failed to read response body: %v", err)
 		return tags, fields, nil
 	}
 
 	if len(ins.ExpectResponseSubstring) > 0 {
 		if !strings.Contains(string(bs), ins.ExpectResponseSubstring) {
-			log.Println("E! body mismatch, response body:", string(bs))
+			httpResponseLog.Errorf("body mismatch, response body: %v", string(bs))
 			fields["result_code"] = BodyMismatch
 		}
 	}
 
 	if ins.ExpectResponseStatusCode != nil {
 		if *ins.ExpectResponseStatusCode != resp.StatusCode {
-			log.Println("E! status code mismatch, response stats code:", resp.StatusCode)
+			httpResponseLog.Errorf("status code mismatch, response stats code: %v", resp.StatusCode)
 			fields["result_code"] = CodeMismatch
 		}
 	}
@@ -0,0 +1,227 @@
+//go:build windows
+// +build windows
+
+package iis
+
+import (
+	"github.com/yusufpapurcu/wmi"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/filter"
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/types"
+)
+
+var iisLog = logger.New("input.iis")
+
+const inputName = "iis"
+
+// the states of CurrentApplicationPoolState
+var appPoolStates = []string{"uninitialized", "initialized", "running", "disabling", "disabled", "shutdown_pending", "delete_pending"}
+
+type IIS struct {
+	config.PluginConfig
+	// support glob, all if empty
+	SitesInclude    []string `toml:"sites_include"`
+	SitesExclude    []string `toml:"sites_exclude"`
+	AppPoolsInclude []string `toml:"app_pools_include"`
+	AppPoolsExclude []string `toml:"app_pools_exclude"`
+
+	siteFilter    filter.Filter
+	appPoolFilter filter.Filter
+}
+
+func init() {
+	inputs.Add(inputName, func() inputs.Input {
+		return &IIS{}
+	})
+}
+
+func (i *IIS) Clone() inputs.Input {
+	return &IIS{}
+}
+
+func (i *IIS) Name() string {
+	return inputName
+}
+
+func (i *IIS) Init() error {
+	var err error
+	if i.siteFilter, err = filter.NewIncludeExcludeFilter(i.SitesInclude, i.SitesExclude); err != nil {
+		return err
+	}
+	i.appPoolFilter, err = filter.NewIncludeExcludeFilter(i.AppPoolsInclude, i.AppPoolsExclude)
+	return err
+}
+
+type Win32_PerfRawData_W3SVC_WebService struct {
+	Name string
+
+	CurrentConnections                  uint32
+	TotalConnectionAttemptsallinstances uint32
+	TotalGetRequests                    uint32
+	TotalPostRequests                   uint32
+	TotalPutRequests                    uint32
+	TotalDeleteRequests                 uint32
+	TotalHeadRequests                   uint32
+	TotalOptionsRequests                uint32
+	TotalOtherRequestMethods            uint32
+	TotalNotFoundErrors                 uint32
+	TotalLockedErrors                   uint32
+	TotalRejectedAsyncIORequests        uint32
+	TotalBytesReceived                  uint64
+	TotalBytesSent                      uint64
+	TotalAnonymousUsers                 uint32
+	TotalNonAnonymousUsers              uint32
+}
+
+type Win32_PerfRawData_HTTP_HTTPServiceRequestQueues struct {
+	Name string
+
+	CurrentQueueSize uint32
+	MaxQueueItemAge  uint64
+	RejectedRequests uint64
+	CacheHitRate     uint32
+}
+
+type Win32_PerfRawData_APPPOOLCountersProvider_APPPOOLWAS struct {
+	Name string
+
+	CurrentApplicationPoolState        uint32
+	CurrentApplicationPoolUptime       uint64
+	CurrentWorkerProcesses             uint32
+	MaximumWorkerProcesses             uint32
+	RecentWorkerProcessFailures        uint32
+	TotalApplicationPoolRecycles       uint32
+	TotalWorkerProcessesCreated        uint32
+	TotalWorkerProcessFailures         uint32
+	TotalWorkerProcessPingFailures     uint32
+	TotalWorkerProcessShutdownFailures uint32
+	TotalWorkerProcessStartupFailures  uint32
+
+	Frequency_Object uint64
+	Timestamp_Object uint64
+}
+
+func (i *IIS) Gather(slist *types.SampleList) {
+	up := 1
+	if err := i.gatherSites(slist); err != nil {
+		iisLog.Errorf("failed to gather iis sites: %v", err)
+		up = 0
+	}
+
+	if err := i.gatherRequestQueues(slist); err != nil {
+		iisLog.Errorf("failed to gather iis request queues: %v", err)
+		up = 0
+	}
+
+	if err := i.gatherAppPools(slist); err != nil {
+		iisLog.Errorf("failed to gather iis app pools: %v", err)
+		up = 0
+	}
+
+	slist.PushSample(inputName, "up", up)
+}
+
+func (i *IIS) gatherSites(slist *types.SampleList) error {
+	var dst []Win32_PerfRawData_W3SVC_WebService
+	if err := wmi.Query(wmi.CreateQuery(&dst, ""), &dst); err != nil {
+		return err
+	}
+
+	for _, s := range dst {
+		// _Total is the sum of sites
+		if s.Name == "_Total" || !i.siteFilter.Match(s.Name) {
+			continue
+		}
+
+		tags := map[string]string{"site": s.Name}
+		slist.PushSamples(inputName, map[string]interface{}{
+			"current_connections":              s.CurrentConnections,
+			"connection_attempts_total":        s.TotalConnectionAttemptsallinstances,
+			"not_found_errors_total":           s.TotalNotFoundErrors,
+			"locked_errors_total":              s.TotalLockedErrors,
+			"rejected_async_io_requests_total": s.TotalRejectedAsyncIORequests,
+			"received_bytes_total":             s.TotalBytesReceived,
+			"sent_bytes_total":                 s.TotalBytesSent,
+			"anonymous_users_total":            s.TotalAnonymousUsers,
+			"non_anonymous_users_total":        s.TotalNonAnonymousUsers,
+		}, tags)
+
+		for method, n := range map[string]uint32{
+			"GET":     s.TotalGetRequests,
+			"POST":    s.TotalPostRequests,
+			"PUT":     s.TotalPutRequests,
+			"DELETE":  s.TotalDeleteRequests,
+			"HEAD":    s.TotalHeadRequests,
+			"OPTIONS": s.TotalOptionsRequests,
+			"other":   s.TotalOtherRequestMethods,
+		} {
+			slist.PushSample(inputName, "requests_total", n, tags, map[string]string{"method": method})
+		}
+	}
+	return nil
+}
+
+func (i *IIS) gatherRequestQueues(slist *types.SampleList) error {
+	var dst []Win32_PerfRawData_HTTP_HTTPServiceRequestQueues
+	if err := wmi.Query(wmi.CreateQuery(&dst, ""), &dst); err != nil {
+		return err
+	}
+
+	for _, q := range dst {
+		if !i.appPoolFilter.Match(q.Name) {
+			continue
+		}
+
+		slist.PushSamples(inputName, map[string]interface{}{
+			"request_queue_length":         q.CurrentQueueSize,
+			"request_queue_max_age_ms":     q.MaxQueueItemAge,
+			"request_queue_rejected_total": q.RejectedRequests,
+		}, map[string]string{"app_pool": q.Name})
+	}
+	return nil
+}
+
+func (i *IIS) gatherAppPools(slist *types.SampleList) error {
+	var dst []Win32_PerfRawData_APPPOOLCountersProvider_APPPOOLWAS
+	if err := wmi.Query(wmi.CreateQuery(&dst, ""), &dst); err != nil {
+		return err
+	}
+
+	for _, p := range dst {
+		if p.Name == "_Total" || !i.appPoolFilter.Match(p.Name) {
+			continue
+		}
+
+		tags := map[string]string{"app_pool": p.Name}
+		fields := map[string]interface{}{
+			"app_pool_worker_processes":                       p.CurrentWorkerProcesses,
+			"app_pool_max_worker_processes":                   p.MaximumWorkerProcesses,
+			"app_pool_recent_worker_process_failures":         p.RecentWorkerProcessFailures,
+			"app_pool_recycles_total":                         p.TotalApplicationPoolRecycles,
+			"app_pool_worker_processes_created_total":         p.TotalWorkerProcessesCreated,
+			"app_pool_worker_process_failures_total":          p.TotalWorkerProcessFailures,
+			"app_pool_worker_process_ping_failures_total":     p.TotalWorkerProcessPingFailures,
+			"app_pool_worker_process_shutdown_failures_total": p.TotalWorkerProcessShutdownFailures,
+			"app_pool_worker_process_startup_failures_total":  p.TotalWorkerProcessStartupFailures,
+		}
+
+		// the uptime is the elapsed time since the counter, in ticks of frequency
+		if p.Frequency_Object > 0 && p.Timestamp_Object >= p.CurrentApplicationPoolUptime {
+			fields["app_pool_uptime_seconds"] = float64(p.Timestamp_Object-p.CurrentApplicationPoolUptime) / float64(p.Frequency_Object)
+		}
+		slist.PushSamples(inputName, fields, tags)
+
+		// 1 for the current state, 0 for the others
+		for idx, state := range appPoolStates {
+			v := 0
+			if uint32(idx+1) == p.CurrentApplicationPoolState {
+				v = 1
+			}
+			slist.PushSample(inputName, "app_pool_state", v, tags, map[string]string{"state": state})
+		}
+	}
+	return nil
+}
@@ -0,0 +1,228 @@
+package ingress_nginx
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/pkg/metrics"
+	"flashcat.cloud/categraf/pkg/tls"
+	"flashcat.cloud/categraf/types"
+)
+
+var ingressNginxLog = logger.New("input.ingress_nginx")
+
+const (
+	inputName = "ingress_nginx"
+
+	metricPrefix = "nginx_ingress_controller_"
+)
+
+type IngressNginx struct {
+	config.PluginConfig
+	Instances []*Instance `toml:"instances"`
+}
+
+type Instance struct {
+	config.InstanceConfig
+
+	// the metrics of controller pods, e.g. http://ingress-nginx-controller:10254/metrics
+	URLs []string `toml:"urls"`
+	// ingress_nginx_healthy is 0 if the ratio of 5xx responses since the last gather is above it
+	Max5xxRatio float64 `toml:"max_5xx_ratio"`
+	// and if a certificate expires in it
+	MinCertExpiry config.Duration `toml:"min_cert_expiry"`
+	Timeout       config.Duration `toml:"timeout"`
+	tls.ClientConfig
+
+	client *http.Client
+	deltas *metrics.Deltas
+}
+
+var _ inputs.SampleGatherer = new(Instance)
+var _ inputs.Input = new(IngressNginx)
+var _ inputs.InstancesGetter = new(IngressNginx)
+
+func init() {
+	inputs.Add(inputName, func() inputs.Input {
+		return &IngressNginx{}
+	})
+}
+
+func (n *IngressNginx) Clone() inputs.Input {
+	return &IngressNginx{}
+}
+
+func (n *IngressNginx) Name() string {
+	return inputName
+}
+
+func (n *IngressNginx) GetInstances() []inputs.Instance {
+	ret := make([]inputs.Instance, len(n.Instances))
+	for i := 0; i < len(n.Instances); i++ {
+		ret[i] = n.Instances[i]
+	}
+	return ret
+}
+
+func (ins *Instance) Init() error {
+	if len(ins.URLs) == 0 {
+		return types.ErrInstancesEmpty
+	}
+	if ins.Timeout <= 0 {
+		ins.Timeout = config.Duration(5 * time.Second)
+	}
+	if ins.Max5xxRatio <= 0 {
+		ins.Max5xxRatio = 0.05
+	}
+	if ins.MinCertExpiry <= 0 {
+		ins.MinCertExpiry = config.Duration(7 * 24 * time.Hour)
+	}
+	ins.deltas = metrics.NewDeltas()
+
+	tlsConfig, err := ins.ClientConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+	ins.client = &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		},
+		Timeout: time.Duration(ins.Timeout),
+	}
+	return nil
+}
+
+func (ins *Instance) Gather(slist *types.SampleList) {
+	var wg sync.WaitGroup
+	for _, u := range ins.URLs {
+		wg.Add(1)
+		go func(u string) {
+			defer wg.Done()
+			ins.gatherURL(slist, u)
+		}(u)
+	}
+	wg.Wait()
+}
+
+func (ins *Instance) gatherURL(slist *types.SampleList, u string) {
+	tags := map[string]string{"url": u}
+
+	mfs, err := ins.scrape(u)
+	if err != nil {
+		ingressNginxLog.Errorf("failed to scrape ingress-nginx controller: %v error: %v", u, err)
+		slist.PushSample(inputName, "up", 0, tags)
+		slist.PushSample(inputName, "healthy", 0, tags)
+		return
+	}
+	slist.PushSample(inputName, "up", 1, tags)
+
+	// the requests by path, method and service are summed by ingress and the class of status
+	requests := make(map[[3]string]float64)
+	for _, g := range metrics.SumBy(mfs[metricPrefix+"requests"], "namespace", "ingress", "status") {
+		requests[[3]string{g.Labels["namespace"], g.Labels["ingress"], statusClass(g.Labels["status"])}] += g.Value
+	}
+	for key, n := range requests {
+		slist.PushSample(inputName, "requests_total", n, tags, map[string]string{"namespace": key[0], "ingress": key[1], "status_class": key[2]})
+	}
+	for _, g := range metrics.SumBy(mfs[metricPrefix+"request_duration_seconds"], "namespace", "ingress") {
+		slist.PushSample(inputName, "request_duration_seconds_count", g.Value, tags, g.Labels)
+		slist.PushSample(inputName, "request_duration_seconds_sum", g.Sum, tags, g.Labels)
+	}
+
+	for _, g := range metrics.SumBy(mfs[metricPrefix+"nginx_process_connections"], "state") {
+		slist.PushSample(inputName, "connections", g.Value, tags, g.Labels)
+	}
+
+	reloadOK := true
+	if mf, ok := mfs[metricPrefix+"config_last_reload_successful"]; ok {
+		reloadOK = metrics.Sum(mf) == 1
+		slist.PushSample(inputName, "config_last_reload_successful", reloadOK, tags)
+	}
+	slist.PushSample(inputName, "config_reloads_total", metrics.Sum(mfs[metricPrefix+"success"]), tags)
+	slist.PushSample(inputName, "config_reload_errors_total", metrics.Sum(mfs[metricPrefix+"errors"]), tags)
+
+	certOK := ins.gatherCerts(slist, mfs, tags)
+
+	healthy := reloadOK && certOK
+	if ratio, ok := ins.ratio5xx(u, requests); ok {
+		slist.PushSample(inputName, "5xx_ratio", ratio, tags)
+		if ratio > ins.Max5xxRatio {
+			healthy = false
+		}
+	}
+	slist.PushSample(inputName, "healthy", healthy, tags)
+}
+
+// gatherCerts reports the seconds before the certificates of hosts expire, false if any expires in min_cert_expiry
+func (ins *Instance) gatherCerts(slist *types.SampleList, mfs map[string]*dto.MetricFamily, tags map[string]string) bool {
+	groups := metrics.SumBy(mfs[metricPrefix+"ssl_expire_time_seconds"], "namespace", "secret_name", "host")
+	if len(groups) == 0 {
+		return true
+	}
+
+	now := float64(time.Now().Unix())
+	min := groups[0].Value - now
+	for _, g := range groups {
+		remaining := g.Value - now
+		slist.PushSample(inputName, "cert_expiry_seconds", remaining, tags, g.Labels)
+		if remaining < min {
+			min = remaining
+		}
+	}
+	slist.PushSample(inputName, "cert_min_expiry_seconds", min, tags)
+	return min >= time.Duration(ins.MinCertExpiry).Seconds()
+}
+
+// ratio5xx returns the ratio of 5xx responses of all ingresses since the last gather
+func (ins *Instance) ratio5xx(u string, requests map[[3]string]float64) (float64, bool) {
+	var total, errors float64
+	for key, n := range requests {
+		total += n
+		if key[2] == "5xx" {
+			errors += n
+		}
+	}
+
+	dTotal, ok1 := ins.deltas.Delta(u+"/requests", total)
+	dErrors, ok2 := ins.deltas.Delta(u+"/errors", errors)
+	if !ok1 || !ok2 || dTotal == 0 {
+		return 0, false
+	}
+	return dErrors / dTotal, true
+}
+
+// statusClass returns the class of the status code, e.g. 5xx of 503
+func statusClass(status string) string {
+	if len(status) != 3 {
+		return "unknown"
+	}
+	return status[:1] + "xx"
+}
+
+func (ins *Instance) scrape(u string) (map[string]*dto.MetricFamily, error) {
+	resp, err := ins.client.Get(u)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		return nil, fmt.Errorf("returned HTTP status %s: %q", resp.Status, body)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return metrics.Parse(body, resp.Header)
+}
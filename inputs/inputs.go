@@ -1,6 +1,8 @@
 package inputs
 
 import (
+	"time"
+
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/types"
 )
@@ -57,6 +59,7 @@ type (
 		Name() string
 		GetLabels() map[string]string
 		GetInterval() config.Duration
+		GetPriority() config.Priority
 		InitInternalConfig() error
 		Process(*types.SampleList) *types.SampleList
 	}
@@ -76,6 +79,8 @@ type Instance interface {
 
 	GetLabels() map[string]string
 	GetIntervalTimes() int64
+	GetCircuitBreaker() (int, time.Duration)
+	InMaintenance(time.Time) (bool, bool)
 	InitInternalConfig() error
 	Process(*types.SampleList) *types.SampleList
 }
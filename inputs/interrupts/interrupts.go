@@ -0,0 +1,48 @@
+package interrupts
+
+import (
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/filter"
+	"flashcat.cloud/categraf/pkg/logger"
+)
+
+var interruptsLog = logger.New("input.interrupts")
+
+const inputName = "interrupts"
+
+// Interrupts gathers the per cpu counters of /proc/interrupts and /proc/softirqs,
+// so the imbalance of interrupts, e.g. all queues of a NIC on cpu0, is visible
+type Interrupts struct {
+	config.PluginConfig
+
+	// glob patterns matching the device (e.g. eth0-TxRx-0, nvme0q1) or the irq (e.g. 36, LOC), all if empty
+	DeviceInclude  []string `toml:"device_include"`
+	DeviceExclude  []string `toml:"device_exclude"`
+	GatherSoftirqs bool     `toml:"gather_softirqs"`
+
+	filter filter.Filter
+}
+
+func init() {
+	inputs.Add(inputName, func() inputs.Input {
+		return &Interrupts{}
+	})
+}
+
+func (i *Interrupts) Clone() inputs.Input {
+	return &Interrupts{}
+}
+
+func (i *Interrupts) Name() string {
+	return inputName
+}
+
+func (i *Interrupts) Init() error {
+	f, err := filter.NewIncludeExcludeFilter(i.DeviceInclude, i.DeviceExclude)
+	if err != nil {
+		return err
+	}
+	i.filter = f
+	return nil
+}
@@ -0,0 +1,126 @@
+//go:build linux
+// +build linux
+
+package interrupts
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"flashcat.cloud/categraf/pkg/osx"
+	"flashcat.cloud/categraf/types"
+)
+
+type irq struct {
+	id     string
+	chip   string
+	device string
+	counts []uint64
+}
+
+func (i *Interrupts) Gather(slist *types.SampleList) {
+	irqs, cpus, err := parseInterrupts(filepath.Join(osx.GetHostProc(), "interrupts"))
+	if err != nil {
+		interruptsLog.Errorf("failed to parse interrupts: %v", err)
+	}
+
+	for _, irq := range irqs {
+		if !i.filter.Match(irq.device) && !i.filter.Match(irq.id) {
+			continue
+		}
+
+		for n, count := range irq.counts {
+			slist.PushSample(inputName, "total", count, map[string]string{
+				"irq":    irq.id,
+				"chip":   irq.chip,
+				"device": irq.device,
+				"cpu":    cpus[n],
+			})
+		}
+	}
+
+	if !i.GatherSoftirqs {
+		return
+	}
+
+	softirqs, cpus, err := parseInterrupts(filepath.Join(osx.GetHostProc(), "softirqs"))
+	if err != nil {
+		interruptsLog.Errorf("failed to parse softirqs: %v", err)
+	}
+
+	for _, irq := range softirqs {
+		for n, count := range irq.counts {
+			slist.PushSample(inputName, "softirqs_total", count, map[string]string{
+				"type": irq.id,
+				"cpu":  cpus[n],
+			})
+		}
+	}
+}
+
+// parseInterrupts parses the table of cpus, the rows are like
+//
+//	36:     433485     2341  PCI-MSIX-0000:00:02.0   1-edge      virtio1-req.0
+//	LOC:   3565644    23412   Local timer interrupts
+//	ERR:         0
+//
+// some rows, e.g. ERR and MIS, have a single count instead of the count of every cpu
+func parseInterrupts(file string) ([]*irq, []string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	if !scanner.Scan() {
+		return nil, nil, fmt.Errorf("%s is empty", file)
+	}
+
+	cpus := strings.Fields(scanner.Text())
+	for n := range cpus {
+		cpus[n] = strings.TrimPrefix(strings.ToLower(cpus[n]), "cpu")
+	}
+
+	var irqs []*irq
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || !strings.HasSuffix(fields[0], ":") {
+			continue
+		}
+
+		r := &irq{id: strings.TrimSuffix(fields[0], ":")}
+		for _, f := range fields[1:] {
+			if len(r.counts) == len(cpus) {
+				break
+			}
+			v, err := strconv.ParseUint(f, 10, 64)
+			if err != nil {
+				break
+			}
+			r.counts = append(r.counts, v)
+		}
+
+		if len(r.counts) != len(cpus) {
+			continue
+		}
+
+		desc := fields[1+len(cpus):]
+		if _, err := strconv.Atoi(r.id); err == nil && len(desc) > 0 {
+			// chip, hwirq with the trigger, e.g. 1-edge, then the devices
+			r.chip = desc[0]
+			desc = desc[1:]
+			if len(desc) > 1 {
+				desc = desc[1:]
+			}
+		}
+		r.device = strings.Join(desc, " ")
+		irqs = append(irqs, r)
+	}
+
+	return irqs, cpus, scanner.Err()
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+// +build !linux
+
+package interrupts
+
+import (
+	"flashcat.cloud/categraf/types"
+)
+
+func (i *Interrupts) Gather(slist *types.SampleList) {
+}
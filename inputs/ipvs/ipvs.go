@@ -1 +1,5 @@
 package ipvs
+
+import "flashcat.cloud/categraf/pkg/logger"
+
+var ipvsLog = logger.New("input.ipvs")
@@ -3,7 +3,6 @@ package ipvs
 import (
 	_ "embed"
 	"fmt"
-	"log"
 	"math/bits"
 	"strconv"
 	"syscall"
@@ -68,7 +67,7 @@ func (i *IPVS) Gather(slist *types.SampleList) error {
 
 		destinations, err := i.handle.GetDestinations(s)
 		if err != nil {
-			log.Printf("E! Failed to list destinations for a virtual server: %v\n", err)
+			ipvsLog.Errorf("Failed to list destinations for a virtual server: %v", err)
 			continue // move on to the next virtual server
 		}
 
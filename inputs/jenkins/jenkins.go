@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -15,10 +14,13 @@ import (
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
 	"flashcat.cloud/categraf/pkg/filter"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/pkg/tls"
 	"flashcat.cloud/categraf/types"
 )
 
+var jenkinsLog = logger.New("input.jenkins")
+
 const inputName = "jenkins"
 
 type Jenkins struct {
@@ -88,17 +90,18 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 	if ins.client == nil {
 		client, err := ins.newHTTPClient()
 		if err != nil {
-			log.Println("E! failed to new HTTPClient:", err)
+			jenkinsLog.Errorf("failed to new HTTPClient: %v", err)
 			return
 		}
 
 		if err = ins.initialize(client); err != nil {
-			log.Println("E! failed to initialize:", err)
+			jenkinsLog.Errorf("failed to initialize: %v", err)
 			return
 		}
 	}
 
 	ins.gatherNodesData(slist)
+	ins.gatherQueue(slist)
 	ins.gatherJobs(slist)
 }
 
@@ -159,6 +162,11 @@ func (ins *Instance) initialize(client *http.Client) error {
 		ins.MaxConnections = 5
 	}
 
+	// the builds older than now are all skipped without max_build_age
+	if ins.MaxBuildAge <= 0 {
+		ins.MaxBuildAge = config.Duration(time.Hour)
+	}
+
 	// default sub jobs can be acquired
 	if ins.MaxSubJobPerLayer <= 0 {
 		ins.MaxSubJobPerLayer = 10
@@ -224,7 +232,7 @@ func (ins *Instance) gatherNodeData(n node, slist *types.SampleList) error {
 func (ins *Instance) gatherNodesData(slist *types.SampleList) {
 	nodeResp, err := ins.client.getAllNodes(context.Background())
 	if err != nil {
-		log.Println("E! gatherNodesData", err)
+		jenkinsLog.Errorf("gatherNodesData %v", err)
 		return
 	}
 
@@ -233,6 +241,9 @@ func (ins *Instance) gatherNodesData(slist *types.SampleList) {
 	fields := make(map[string]interface{})
 	fields["busy_executors"] = nodeResp.BusyExecutors
 	fields["total_executors"] = nodeResp.TotalExecutors
+	if nodeResp.TotalExecutors > 0 {
+		fields["busy_executors_percent"] = float64(nodeResp.BusyExecutors) / float64(nodeResp.TotalExecutors) * 100
+	}
 	slist.PushSamples(inputName, fields, tags)
 	// get node data
 	for _, node := range nodeResp.Computers {
@@ -243,10 +254,50 @@ func (ins *Instance) gatherNodesData(slist *types.SampleList) {
 	}
 }
 
+// gatherQueue counts the items waiting in the build queue, and how long the oldest one has waited
+func (ins *Instance) gatherQueue(slist *types.SampleList) {
+	queueResp, err := ins.client.getQueue(context.Background())
+	if err != nil {
+		jenkinsLog.Errorf("gatherQueue %v", err)
+		return
+	}
+
+	var blocked, buildable, stuck int
+	var oldest int64
+	for _, item := range queueResp.Items {
+		if item.Blocked {
+			blocked++
+		}
+		if item.Buildable {
+			buildable++
+		}
+		if item.Stuck {
+			stuck++
+		}
+		if item.InQueueSince > 0 && (oldest == 0 || item.InQueueSince < oldest) {
+			oldest = item.InQueueSince
+		}
+	}
+
+	tags := map[string]string{"source": ins.Source, "port": ins.Port}
+	fields := map[string]interface{}{
+		"queue_size":      len(queueResp.Items),
+		"queue_blocked":   blocked,
+		"queue_buildable": buildable,
+		"queue_stuck":     stuck,
+	}
+	if oldest > 0 {
+		fields["queue_oldest_seconds"] = time.Since(time.UnixMilli(oldest)).Seconds()
+	} else {
+		fields["queue_oldest_seconds"] = 0
+	}
+	slist.PushSamples(inputName, fields, tags)
+}
+
 func (ins *Instance) gatherJobs(slist *types.SampleList) {
 	js, err := ins.client.getJobs(context.Background(), nil)
 	if err != nil {
-		log.Println("E! gatherJobs", err)
+		jenkinsLog.Errorf("gatherJobs %v", err)
 		return
 	}
 	var wg sync.WaitGroup
@@ -259,7 +310,7 @@ func (ins *Instance) gatherJobs(slist *types.SampleList) {
 				parents: []string{},
 				layer:   0,
 			}, slist); err != nil {
-				log.Println("E! getJobDetail", err)
+				jenkinsLog.Errorf("getJobDetail %v", err)
 			}
 		}(job.Name, &wg, slist)
 	}
@@ -293,7 +344,7 @@ func (ins *Instance) getJobDetail(jr jobRequest, slist *types.SampleList) error
 				parents: jr.combined(),
 				layer:   jr.layer + 1,
 			}, slist); err != nil {
-				log.Println("E! getJobDetail", err)
+				jenkinsLog.Errorf("getJobDetail %v", err)
 			}
 		}(ij, jr, slist)
 	}
@@ -312,7 +363,7 @@ func (ins *Instance) getJobDetail(jr jobRequest, slist *types.SampleList) error
 
 	if build.Building {
 		if config.Config.DebugMode {
-			log.Println("Ignore running build on ", jr.name, "build", number)
+			jenkinsLog.Infof("Ignore running build on  %v build %v", jr.name, number)
 		}
 		return nil
 	}
@@ -367,6 +418,18 @@ type swapSpaceMonitor struct {
 	MemoryTotal     float64 `json:"totalPhysicalMemory"`
 }
 
+type queueResponse struct {
+	Items []queueItem `json:"items"`
+}
+
+type queueItem struct {
+	Blocked   bool `json:"blocked"`
+	Buildable bool `json:"buildable"`
+	Stuck     bool `json:"stuck"`
+	// unix milliseconds
+	InQueueSince int64 `json:"inQueueSince"`
+}
+
 type jobResponse struct {
 	LastBuild jobBuild   `json:"lastBuild"`
 	Jobs      []innerJob `json:"jobs"`
@@ -397,8 +460,9 @@ func (b *buildResponse) GetTimestamp() time.Time {
 }
 
 const (
-	nodePath = "/computer/api/json"
-	jobPath  = "/api/json"
+	nodePath  = "/computer/api/json"
+	queuePath = "/queue/api/json"
+	jobPath   = "/api/json"
 )
 
 type jobRequest struct {
@@ -604,6 +668,12 @@ func (c *client) getBuild(ctx context.Context, jr jobRequest, number int64) (b *
 	return b, err
 }
 
+func (c *client) getQueue(ctx context.Context) (queueResp *queueResponse, err error) {
+	queueResp = new(queueResponse)
+	err = c.doGet(ctx, queuePath, queueResp)
+	return queueResp, err
+}
+
 func (c *client) getAllNodes(ctx context.Context) (nodeResp *nodeResponse, err error) {
 	nodeResp = new(nodeResponse)
 	err = c.doGet(ctx, nodePath, nodeResp)
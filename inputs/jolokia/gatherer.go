@@ -2,13 +2,15 @@ package jolokia
 
 import (
 	"fmt"
-	"log"
 	"sort"
 	"strings"
 
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/types"
 )
 
+var jolokiaLog = logger.New("input.jolokia")
+
 const defaultFieldName = "value"
 
 type Gatherer struct {
@@ -60,7 +62,7 @@ func (g *Gatherer) gatherResponses(responses []ReadResponse, tags map[string]str
 		responsePoints, responseErrors := g.generatePoints(metric, responses)
 		points = append(points, responsePoints...)
 		for _, err := range responseErrors {
-			log.Println("E!", err)
+			jolokiaLog.Errorf("%v", err)
 		}
 
 		series[metric.Name] = points
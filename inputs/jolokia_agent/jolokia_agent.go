@@ -2,17 +2,19 @@ package jolokia_agent
 
 import (
 	"fmt"
-	"log"
 	"sync"
 	"time"
 
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
 	"flashcat.cloud/categraf/inputs/jolokia"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/pkg/tls"
 	"flashcat.cloud/categraf/types"
 )
 
+var jolokiaAgentLog = logger.New("input.jolokia_agent")
+
 const inputName = "jolokia_agent"
 
 type JolokiaAgent struct {
@@ -82,7 +84,7 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 		for _, url := range ins.URLs {
 			client, err := ins.createClient(url)
 			if err != nil {
-				log.Println("E! failed to create client:", err)
+				jolokiaAgentLog.Errorf("failed to create client: %v", err)
 				continue
 			}
 			ins.clients = append(ins.clients, client)
@@ -98,7 +100,7 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 
 			err := ins.gatherer.Gather(client, slist)
 			if err != nil {
-				log.Println("E!", fmt.Errorf("unable to gather metrics for %s: %v", client.URL, err))
+				jolokiaAgentLog.Errorf("%v", fmt.Errorf("unable to gather metrics for %s: %v", client.URL, err))
 			}
 		}(client)
 	}
@@ -2,16 +2,18 @@ package jolokia_proxy
 
 import (
 	"fmt"
-	"log"
 	"time"
 
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
 	"flashcat.cloud/categraf/inputs/jolokia"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/pkg/tls"
 	"flashcat.cloud/categraf/types"
 )
 
+var jolokiaProxyLog = logger.New("input.jolokia_proxy")
+
 const inputName = "jolokia_proxy"
 
 type JolokiaProxy struct {
@@ -89,7 +91,7 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 	if ins.client == nil {
 		client, err := ins.createClient(ins.URL)
 		if err != nil {
-			log.Println("E! failed to create client:", err)
+			jolokiaProxyLog.Errorf("failed to create client: %v", err)
 			return
 		}
 		ins.client = client
@@ -97,7 +99,7 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 
 	err := ins.gatherer.Gather(ins.client, slist)
 	if err != nil {
-		log.Println("E!", fmt.Errorf("unable to gather metrics for %s: %v", ins.client.URL, err))
+		jolokiaProxyLog.Errorf("%v", fmt.Errorf("unable to gather metrics for %s: %v", ins.client.URL, err))
 	}
 }
 
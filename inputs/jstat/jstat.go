@@ -0,0 +1,236 @@
+package jstat
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/cmdx"
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/types"
+)
+
+var jstatLog = logger.New("input.jstat")
+
+const inputName = "jstat"
+
+// columns of jstat -gc, the capacities and usages are in KB, the times in seconds
+var gcColumns = map[string]string{
+	"S0C":  "survivor0_capacity_bytes",
+	"S1C":  "survivor1_capacity_bytes",
+	"S0U":  "survivor0_used_bytes",
+	"S1U":  "survivor1_used_bytes",
+	"EC":   "eden_capacity_bytes",
+	"EU":   "eden_used_bytes",
+	"OC":   "old_capacity_bytes",
+	"OU":   "old_used_bytes",
+	"MC":   "metaspace_capacity_bytes",
+	"MU":   "metaspace_used_bytes",
+	"CCSC": "compressed_class_capacity_bytes",
+	"CCSU": "compressed_class_used_bytes",
+	"YGC":  "young_gc_total",
+	"YGCT": "young_gc_seconds_total",
+	"FGC":  "full_gc_total",
+	"FGCT": "full_gc_seconds_total",
+	"CGC":  "concurrent_gc_total",
+	"CGCT": "concurrent_gc_seconds_total",
+	"GCT":  "gc_seconds_total",
+}
+
+type Jstat struct {
+	config.PluginConfig
+	Instances []*Instance `toml:"instances"`
+}
+
+func init() {
+	inputs.Add(inputName, func() inputs.Input {
+		return &Jstat{}
+	})
+}
+
+func (j *Jstat) Clone() inputs.Input {
+	return &Jstat{}
+}
+
+func (j *Jstat) Name() string {
+	return inputName
+}
+
+func (j *Jstat) GetInstances() []inputs.Instance {
+	ret := make([]inputs.Instance, len(j.Instances))
+	for i := 0; i < len(j.Instances); i++ {
+		ret[i] = j.Instances[i]
+	}
+	return ret
+}
+
+type Instance struct {
+	config.InstanceConfig
+
+	// regular expression matching the main class (or jar) and arguments listed by jcmd -l
+	SearchPattern string `toml:"search_pattern"`
+	// the bin of JAVA_HOME is used for jcmd and jstat if set, otherwise they are looked up in PATH
+	JavaHome string          `toml:"java_home"`
+	Timeout  config.Duration `toml:"timeout"`
+
+	pattern *regexp.Regexp
+	jcmd    string
+	jstat   string
+}
+
+type jvm struct {
+	pid       string
+	mainClass string
+}
+
+func (ins *Instance) Init() error {
+	if ins.SearchPattern == "" {
+		return types.ErrInstancesEmpty
+	}
+
+	pattern, err := regexp.Compile(ins.SearchPattern)
+	if err != nil {
+		return fmt.Errorf("failed to compile search_pattern: %v", err)
+	}
+	ins.pattern = pattern
+
+	if ins.Timeout == 0 {
+		ins.Timeout = config.Duration(10 * time.Second)
+	}
+
+	ins.jcmd, ins.jstat = "jcmd", "jstat"
+	if ins.JavaHome != "" {
+		ins.jcmd = filepath.Join(ins.JavaHome, "bin", "jcmd")
+		ins.jstat = filepath.Join(ins.JavaHome, "bin", "jstat")
+	}
+	return nil
+}
+
+func (ins *Instance) Gather(slist *types.SampleList) {
+	jvms, err := ins.find()
+	if err != nil {
+		jstatLog.Errorf("failed to list jvms by jcmd -l: %v", err)
+		slist.PushSample(inputName, "up", 0)
+		return
+	}
+	slist.PushSample(inputName, "up", 1)
+	slist.PushSample(inputName, "processes", len(jvms))
+
+	// jstat is a jvm, they run one by one to limit the memory used
+	for _, j := range jvms {
+		tags := map[string]string{"pid": j.pid, "main_class": j.mainClass}
+		fields, err := ins.gc(j.pid)
+		if err != nil {
+			jstatLog.Errorf("failed to run jstat -gc of pid: %v error: %v", j.pid, err)
+			continue
+		}
+		slist.PushSamples(inputName, fields, tags)
+	}
+}
+
+// find lists the jvms which are attachable by the user of categraf, the lines are like
+// 12345 org.apache.catalina.startup.Bootstrap start
+func (ins *Instance) find() ([]jvm, error) {
+	out, err := ins.run(ins.jcmd, "-l")
+	if err != nil {
+		return nil, err
+	}
+
+	var jvms []jvm
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		if _, err := strconv.Atoi(fields[0]); err != nil {
+			continue
+		}
+
+		// jcmd lists itself
+		if fields[1] == "jdk.jcmd/sun.tools.jcmd.JCmd" || fields[1] == "sun.tools.jcmd.JCmd" {
+			continue
+		}
+
+		if !ins.pattern.MatchString(strings.Join(fields[1:], " ")) {
+			continue
+		}
+
+		jvms = append(jvms, jvm{pid: fields[0], mainClass: filepath.Base(fields[1])})
+	}
+	return jvms, nil
+}
+
+// gc parses the output of jstat -gc, the columns vary by versions of jdk,
+// e.g. CGC and CGCT are added in jdk 9, the unavailable are printed as -
+func (ins *Instance) gc(pid string) (map[string]interface{}, error) {
+	out, err := ins.run(ins.jstat, "-gc", pid)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) < 2 {
+		return nil, fmt.Errorf("unexpected output: %s", out)
+	}
+
+	header := strings.Fields(lines[0])
+	values := strings.Fields(lines[len(lines)-1])
+	if len(header) != len(values) {
+		return nil, fmt.Errorf("columns mismatch: %s", out)
+	}
+
+	fields := make(map[string]interface{}, len(header)+2)
+	var heapUsed, heapCapacity float64
+	for i, column := range header {
+		name, has := gcColumns[column]
+		if !has {
+			continue
+		}
+
+		v, err := strconv.ParseFloat(values[i], 64)
+		if err != nil {
+			continue
+		}
+
+		if strings.HasSuffix(name, "_bytes") {
+			v *= 1024
+		}
+		fields[name] = v
+
+		switch column {
+		case "S0U", "S1U", "EU", "OU":
+			heapUsed += v
+		case "S0C", "S1C", "EC", "OC":
+			heapCapacity += v
+		}
+	}
+
+	fields["heap_used_bytes"] = heapUsed
+	fields["heap_capacity_bytes"] = heapCapacity
+	return fields, nil
+}
+
+func (ins *Instance) run(name string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(name, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err, timeout := cmdx.RunTimeout(cmd, time.Duration(ins.Timeout))
+	if timeout {
+		return "", fmt.Errorf("%s timeout after %s", name, time.Duration(ins.Timeout))
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
@@ -2,7 +2,6 @@ package kafka
 
 import (
 	"fmt"
-	"log"
 	"os"
 	"strings"
 	"time"
@@ -10,6 +9,7 @@ import (
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
 	"flashcat.cloud/categraf/inputs/kafka/exporter"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/types"
 	"github.com/Shopify/sarama"
 	"github.com/go-kit/log/level"
@@ -17,6 +17,8 @@ import (
 	klog "github.com/go-kit/log"
 )
 
+var kafkaLog = logger.New("input.kafka")
+
 const inputName = "kafka"
 
 type Kafka struct {
@@ -219,7 +221,7 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 
 	err := inputs.Collect(ins.e, slist)
 	if err != nil {
-		log.Println("E! failed to collect metrics:", err)
+		kafkaLog.Errorf("failed to collect metrics: %v", err)
 	}
 }
 
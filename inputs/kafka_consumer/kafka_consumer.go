@@ -0,0 +1,275 @@
+package kafka_consumer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Shopify/sarama"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/inputs/kafka/exporter"
+	"flashcat.cloud/categraf/parser"
+	"flashcat.cloud/categraf/pkg/consumer"
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/pkg/tls"
+	"flashcat.cloud/categraf/types"
+)
+
+var kafkaConsumerLog = logger.New("input.kafka_consumer")
+
+const (
+	inputName = "kafka_consumer"
+
+	defaultConsumerGroup      = "categraf"
+	defaultKafkaVersion       = "2.0.0"
+	defaultMaxBufferedSamples = 100000
+	retryInterval             = 5 * time.Second
+)
+
+type KafkaConsumer struct {
+	config.PluginConfig
+	Instances []*Instance `toml:"instances"`
+}
+
+type Instance struct {
+	config.InstanceConfig
+
+	Brokers       []string `toml:"brokers"`
+	Topics        []string `toml:"topics"`
+	ConsumerGroup string   `toml:"consumer_group"`
+	ClientID      string   `toml:"client_id"`
+	KafkaVersion  string   `toml:"kafka_version"`
+	// oldest | newest, where to start if the consumer group has no committed offsets
+	OffsetReset string `toml:"offset_reset"`
+	// the samples buffered between gathers, the new ones are dropped once it's full
+	MaxBufferedSamples int `toml:"max_buffered_samples"`
+	// the label of the topic of messages, not labeled if empty
+	TopicLabel string `toml:"topic_label"`
+
+	UseSASL       bool   `toml:"use_sasl"`
+	SASLUsername  string `toml:"sasl_username"`
+	SASLPassword  string `toml:"sasl_password"`
+	SASLMechanism string `toml:"sasl_mechanism"`
+	tls.ClientConfig
+
+	parser.Format
+
+	sarama *sarama.Config
+	// up if a session of the consumer group is running
+	buffer *consumer.Buffer
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+var _ inputs.SampleGatherer = new(Instance)
+var _ inputs.Input = new(KafkaConsumer)
+var _ inputs.InstancesGetter = new(KafkaConsumer)
+
+func init() {
+	inputs.Add(inputName, func() inputs.Input {
+		return &KafkaConsumer{}
+	})
+}
+
+func (k *KafkaConsumer) Clone() inputs.Input {
+	return &KafkaConsumer{}
+}
+
+func (k *KafkaConsumer) Name() string {
+	return inputName
+}
+
+func (k *KafkaConsumer) GetInstances() []inputs.Instance {
+	ret := make([]inputs.Instance, len(k.Instances))
+	for i := 0; i < len(k.Instances); i++ {
+		ret[i] = k.Instances[i]
+	}
+	return ret
+}
+
+func (k *KafkaConsumer) Drop() {
+	for i := 0; i < len(k.Instances); i++ {
+		k.Instances[i].stop()
+	}
+}
+
+func (ins *Instance) Init() error {
+	if len(ins.Brokers) == 0 {
+		return types.ErrInstancesEmpty
+	}
+	if len(ins.Topics) == 0 {
+		return fmt.Errorf("topics is required")
+	}
+	if ins.ConsumerGroup == "" {
+		ins.ConsumerGroup = defaultConsumerGroup
+	}
+	if ins.KafkaVersion == "" {
+		ins.KafkaVersion = defaultKafkaVersion
+	}
+	if ins.MaxBufferedSamples <= 0 {
+		ins.MaxBufferedSamples = defaultMaxBufferedSamples
+	}
+
+	p, err := ins.Format.NewParser()
+	if err != nil {
+		return err
+	}
+
+	if ins.sarama, err = ins.saramaConfig(); err != nil {
+		return err
+	}
+
+	ins.buffer = consumer.NewBuffer(inputName, p, ins.MaxBufferedSamples, ins.TopicLabel)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ins.cancel = cancel
+	ins.wg.Add(1)
+	go ins.consume(ctx)
+	return nil
+}
+
+func (ins *Instance) saramaConfig() (*sarama.Config, error) {
+	c := sarama.NewConfig()
+	c.ClientID = ins.ClientID
+	if c.ClientID == "" {
+		c.ClientID = "categraf"
+	}
+
+	version, err := sarama.ParseKafkaVersion(ins.KafkaVersion)
+	if err != nil {
+		return nil, err
+	}
+	c.Version = version
+
+	switch ins.OffsetReset {
+	case "", "newest":
+		c.Consumer.Offsets.Initial = sarama.OffsetNewest
+	case "oldest":
+		c.Consumer.Offsets.Initial = sarama.OffsetOldest
+	default:
+		return nil, fmt.Errorf("invalid offset_reset %s, oldest or newest", ins.OffsetReset)
+	}
+
+	if ins.UseSASL {
+		if ins.SASLUsername == "" || ins.SASLPassword == "" {
+			return nil, fmt.Errorf("SASL is enabled but username or password was not provided")
+		}
+
+		switch strings.ToLower(ins.SASLMechanism) {
+		case "", "plain":
+			c.Net.SASL.Mechanism = sarama.SASLTypePlaintext
+		case "scram-sha256":
+			c.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient { return &exporter.XDGSCRAMClient{HashGeneratorFcn: exporter.SHA256} }
+			c.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA256
+		case "scram-sha512":
+			c.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient { return &exporter.XDGSCRAMClient{HashGeneratorFcn: exporter.SHA512} }
+			c.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA512
+		default:
+			return nil, fmt.Errorf("invalid sasl_mechanism %s, plain, scram-sha256 or scram-sha512", ins.SASLMechanism)
+		}
+		c.Net.SASL.Enable = true
+		c.Net.SASL.User = ins.SASLUsername
+		c.Net.SASL.Password = ins.SASLPassword
+	}
+
+	tlsConfig, err := ins.ClientConfig.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		c.Net.TLS.Enable = true
+		c.Net.TLS.Config = tlsConfig
+	}
+
+	return c, nil
+}
+
+// consume joins the consumer group until stopped, and retries if the brokers are not available
+func (ins *Instance) consume(ctx context.Context) {
+	defer ins.wg.Done()
+
+	var (
+		group sarama.ConsumerGroup
+		err   error
+	)
+	for {
+		if group == nil {
+			group, err = sarama.NewConsumerGroup(ins.Brokers, ins.ConsumerGroup, ins.sarama)
+			if err != nil {
+				kafkaConsumerLog.Errorf("failed to create kafka consumer group: %v brokers: %v error: %v", ins.ConsumerGroup, ins.Brokers, err)
+			}
+		}
+
+		if group != nil {
+			// returns when the session ends, e.g. on rebalance, and it's joined again at once
+			if err = group.Consume(ctx, ins.Topics, &consumerHandler{ins: ins}); err != nil {
+				kafkaConsumerLog.Errorf("kafka consumer group %v stopped consuming: %v", ins.ConsumerGroup, err)
+				if err == sarama.ErrClosedConsumerGroup {
+					group = nil
+				}
+			}
+		}
+
+		if ctx.Err() != nil {
+			if group != nil {
+				group.Close()
+			}
+			return
+		}
+
+		if err == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(retryInterval):
+		}
+	}
+}
+
+func (ins *Instance) stop() {
+	if ins.cancel == nil {
+		return
+	}
+	ins.cancel()
+	ins.wg.Wait()
+}
+
+type consumerHandler struct {
+	ins *Instance
+}
+
+func (h *consumerHandler) Setup(sarama.ConsumerGroupSession) error {
+	h.ins.buffer.SetUp(true)
+	return nil
+}
+
+func (h *consumerHandler) Cleanup(sarama.ConsumerGroupSession) error {
+	h.ins.buffer.SetUp(false)
+	return nil
+}
+
+func (h *consumerHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for msg := range claim.Messages() {
+		h.ins.handleMessage(msg)
+		session.MarkMessage(msg, "")
+	}
+	return nil
+}
+
+// handleMessage parses the message into the buffer, the messages failed to parse are counted and skipped
+func (ins *Instance) handleMessage(msg *sarama.ConsumerMessage) {
+	if err := ins.buffer.Add(msg.Topic, msg.Value); err != nil && config.Config.DebugMode {
+		kafkaConsumerLog.Debugf("failed to parse kafka message of topic: %v partition: %v offset: %v error: %v", msg.Topic, msg.Partition, msg.Offset, err)
+	}
+}
+
+// Gather drains the samples consumed since last gather
+func (ins *Instance) Gather(slist *types.SampleList) {
+	ins.buffer.Gather(slist, map[string]string{"consumer_group": ins.ConsumerGroup})
+}
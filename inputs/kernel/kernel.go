@@ -6,16 +6,18 @@ package kernel
 import (
 	"bytes"
 	"fmt"
-	"log"
 	"os"
 	"strconv"
 	"strings"
 
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/types"
 )
 
+var kernelLog = logger.New("input.kernel")
+
 const inputName = "kernel"
 
 // /proc/stat file line prefixes to gather stats on:
@@ -56,20 +58,20 @@ func (s *KernelStats) Name() string {
 func (s *KernelStats) Gather(slist *types.SampleList) {
 	data, err := s.getProcStat()
 	if err != nil {
-		log.Println("E! failed to read:", s.statFile, "error:", err)
+		kernelLog.Errorf("failed to read: %v error: %v", s.statFile, err)
 		return
 	}
 
 	entropyData, err := os.ReadFile(s.entropyStatFile)
 	if err != nil {
-		log.Println("E! failed to read:", s.entropyStatFile, "error:", err)
+		kernelLog.Errorf("failed to read: %v error: %v", s.entropyStatFile, err)
 		return
 	}
 
 	entropyString := string(entropyData)
 	entropyValue, err := strconv.ParseInt(strings.TrimSpace(entropyString), 10, 64)
 	if err != nil {
-		log.Println("E! failed to parse:", s.entropyStatFile, "error:", err)
+		kernelLog.Errorf("failed to parse: %v error: %v", s.entropyStatFile, err)
 		return
 	}
 
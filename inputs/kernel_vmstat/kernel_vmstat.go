@@ -6,15 +6,17 @@ package kernel_vmstat
 import (
 	"bytes"
 	"fmt"
-	"log"
 	"os"
 	"strconv"
 
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/types"
 )
 
+var kernelVmstatLog = logger.New("input.kernel_vmstat")
+
 const inputName = "kernel_vmstat"
 
 type KernelVmstat struct {
@@ -45,7 +47,7 @@ func (s *KernelVmstat) Name() string {
 func (s *KernelVmstat) Gather(slist *types.SampleList) {
 	data, err := s.getProcVmstat()
 	if err != nil {
-		log.Println("E! failed to gather vmstat:", err)
+		kernelVmstatLog.Errorf("failed to gather vmstat: %v", err)
 		return
 	}
 
@@ -61,7 +63,7 @@ func (s *KernelVmstat) Gather(slist *types.SampleList) {
 			m, err := strconv.ParseInt(string(dataFields[i+1]), 10, 64)
 			if err != nil {
 				if config.Config.DebugMode {
-					log.Println("D! failed to parse vmstat field:", string(dataFields[i]))
+					kernelVmstatLog.Debugf("failed to parse vmstat field: %v", string(dataFields[i]))
 				}
 				continue
 			}
@@ -0,0 +1,324 @@
+package keycloak
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/httpx"
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/pkg/tls"
+	"flashcat.cloud/categraf/types"
+)
+
+var keycloakLog = logger.New("input.keycloak")
+
+const (
+	inputName = "keycloak"
+
+	pageSize = 100
+	// the date of the events api
+	dateLayout = "2006-01-02"
+)
+
+type Keycloak struct {
+	config.PluginConfig
+	Instances []*Instance `toml:"instances"`
+}
+
+type Instance struct {
+	config.InstanceConfig
+
+	// the base url, e.g. http://keycloak:8080, http://keycloak:8080/auth before keycloak 17
+	URL string `toml:"url"`
+	// the realm the client or user authenticates in
+	AuthRealm string `toml:"auth_realm"`
+	// the client_credentials grant with a service account of view-realm and view-events roles,
+	// or the password grant of admin-cli with username and password
+	ClientID     string          `toml:"client_id"`
+	ClientSecret string          `toml:"client_secret"`
+	Username     string          `toml:"username"`
+	Password     string          `toml:"password"`
+	Realms       []string        `toml:"realms"`
+	Timeout      config.Duration `toml:"timeout"`
+	tls.ClientConfig
+
+	// count the logins by the saved events, which are required to be enabled of the realms
+	GatherEvents bool `toml:"gather_events"`
+
+	client *http.Client
+
+	token       string
+	tokenExpiry time.Time
+
+	// the logins counted by events, since the first gather
+	lastEvent map[string]int64
+	logins    map[loginKey]float64
+}
+
+type loginKey struct {
+	realm  string
+	client string
+	error  string
+}
+
+type event struct {
+	Time     int64  `json:"time"`
+	Type     string `json:"type"`
+	ClientID string `json:"clientId"`
+	Error    string `json:"error"`
+}
+
+var _ inputs.SampleGatherer = new(Instance)
+var _ inputs.Input = new(Keycloak)
+var _ inputs.InstancesGetter = new(Keycloak)
+
+func init() {
+	inputs.Add(inputName, func() inputs.Input {
+		return &Keycloak{}
+	})
+}
+
+func (k *Keycloak) Clone() inputs.Input {
+	return &Keycloak{}
+}
+
+func (k *Keycloak) Name() string {
+	return inputName
+}
+
+func (k *Keycloak) GetInstances() []inputs.Instance {
+	ret := make([]inputs.Instance, len(k.Instances))
+	for i := 0; i < len(k.Instances); i++ {
+		ret[i] = k.Instances[i]
+	}
+	return ret
+}
+
+func (ins *Instance) Init() error {
+	if ins.URL == "" {
+		return types.ErrInstancesEmpty
+	}
+	if ins.Timeout <= 0 {
+		ins.Timeout = config.Duration(5 * time.Second)
+	}
+	if ins.AuthRealm == "" {
+		ins.AuthRealm = "master"
+	}
+	if ins.ClientID == "" {
+		ins.ClientID = "admin-cli"
+	}
+	if ins.ClientSecret == "" && ins.Username == "" {
+		return fmt.Errorf("client_secret or username of keycloak is required")
+	}
+	ins.URL = strings.TrimRight(ins.URL, "/")
+	ins.lastEvent = make(map[string]int64)
+	ins.logins = make(map[loginKey]float64)
+
+	tlsConfig, err := ins.ClientConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+	ins.client = &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		},
+		Timeout: time.Duration(ins.Timeout),
+	}
+	return nil
+}
+
+func (ins *Instance) Gather(slist *types.SampleList) {
+	if _, err := ins.accessToken(); err != nil {
+		keycloakLog.Errorf("failed to get access token of keycloak: %v error: %v", ins.URL, err)
+		slist.PushSample(inputName, "up", 0)
+		return
+	}
+	slist.PushSample(inputName, "up", 1)
+
+	realms := ins.Realms
+	if len(realms) == 0 {
+		var list []struct {
+			Realm   string `json:"realm"`
+			Enabled bool   `json:"enabled"`
+		}
+		if err := ins.get("/admin/realms", nil, &list); err != nil {
+			keycloakLog.Errorf("failed to list realms of keycloak: %v error: %v", ins.URL, err)
+			return
+		}
+		for _, r := range list {
+			if r.Enabled {
+				realms = append(realms, r.Realm)
+			}
+		}
+	}
+
+	for _, realm := range realms {
+		ins.gatherSessions(slist, realm)
+		if ins.GatherEvents {
+			ins.gatherEvents(slist, realm)
+		}
+	}
+}
+
+// gatherSessions reports the active and offline sessions by client
+func (ins *Instance) gatherSessions(slist *types.SampleList, realm string) {
+	var stats []struct {
+		ClientID string `json:"clientId"`
+		Active   string `json:"active"`
+		Offline  string `json:"offline"`
+	}
+	if err := ins.get("/admin/realms/"+url.PathEscape(realm)+"/client-session-stats", nil, &stats); err != nil {
+		keycloakLog.Errorf("failed to get sessions of keycloak realm: %v error: %v", realm, err)
+		return
+	}
+
+	for _, s := range stats {
+		tags := map[string]string{"realm": realm, "client": s.ClientID}
+		fields := make(map[string]interface{})
+		if active, err := strconv.ParseFloat(s.Active, 64); err == nil {
+			fields["sessions"] = active
+		}
+		if offline, err := strconv.ParseFloat(s.Offline, 64); err == nil {
+			fields["offline_sessions"] = offline
+		}
+		slist.PushSamples(inputName, fields, tags)
+	}
+}
+
+// gatherEvents counts the logins and login errors since the last gather, the events are listed
+// from the newest, the counters start at the first gather
+func (ins *Instance) gatherEvents(slist *types.SampleList, realm string) {
+	last, ok := ins.lastEvent[realm]
+	if !ok {
+		// the events before the first gather are not counted
+		last = time.Now().UnixMilli()
+	}
+	newest := last
+	counts := make(map[loginKey]float64)
+
+	// the events api filters by date only
+	query := url.Values{
+		"type":     {"LOGIN", "LOGIN_ERROR"},
+		"dateFrom": {time.Now().Add(-24 * time.Hour).Format(dateLayout)},
+		"max":      {strconv.Itoa(pageSize)},
+	}
+
+pages:
+	for first := 0; ; first += pageSize {
+		query.Set("first", strconv.Itoa(first))
+
+		var events []event
+		if err := ins.get("/admin/realms/"+url.PathEscape(realm)+"/events", query, &events); err != nil {
+			keycloakLog.Errorf("failed to list events of keycloak realm: %v error: %v", realm, err)
+			return
+		}
+
+		for _, e := range events {
+			if e.Time > newest {
+				newest = e.Time
+			}
+			if e.Time <= last {
+				break pages
+			}
+
+			key := loginKey{realm: realm, client: e.ClientID}
+			if e.Type == "LOGIN_ERROR" {
+				key.error = e.Error
+			}
+			counts[key]++
+		}
+
+		if len(events) < pageSize {
+			break
+		}
+	}
+
+	ins.lastEvent[realm] = newest
+	for key, n := range counts {
+		ins.logins[key] += n
+	}
+
+	for key, n := range ins.logins {
+		if key.realm != realm {
+			continue
+		}
+		tags := map[string]string{"realm": key.realm, "client": key.client}
+		if key.error == "" {
+			slist.PushSample(inputName, "logins_total", n, tags)
+		} else {
+			slist.PushSample(inputName, "login_failures_total", n, tags, map[string]string{"error": key.error})
+		}
+	}
+}
+
+// accessToken returns the cached token, or requests a new one if it's about to expire
+func (ins *Instance) accessToken() (string, error) {
+	if ins.token != "" && time.Now().Before(ins.tokenExpiry) {
+		return ins.token, nil
+	}
+
+	form := url.Values{"client_id": {ins.ClientID}}
+	if ins.Username != "" {
+		form.Set("grant_type", "password")
+		form.Set("username", ins.Username)
+		form.Set("password", ins.Password)
+	} else {
+		form.Set("grant_type", "client_credentials")
+	}
+	if ins.ClientSecret != "" {
+		form.Set("client_secret", ins.ClientSecret)
+	}
+
+	path := "/realms/" + url.PathEscape(ins.AuthRealm) + "/protocol/openid-connect/token"
+	req, err := http.NewRequest(http.MethodPost, ins.URL+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var token struct {
+		AccessToken string  `json:"access_token"`
+		ExpiresIn   float64 `json:"expires_in"`
+	}
+	if _, err := httpx.DoJSON(ins.client, req, path, &token); err != nil {
+		return "", err
+	}
+
+	ins.token = token.AccessToken
+	// refresh the token before it expires in the middle of a gather
+	ins.tokenExpiry = time.Now().Add(time.Duration(token.ExpiresIn)*time.Second - time.Duration(ins.Timeout)*2)
+	return ins.token, nil
+}
+
+func (ins *Instance) get(path string, query url.Values, v interface{}) error {
+	token, err := ins.accessToken()
+	if err != nil {
+		return err
+	}
+
+	u := ins.URL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpx.DoJSON(ins.client, req, path, v)
+	if resp != nil && resp.StatusCode == http.StatusUnauthorized {
+		// the session of the token is revoked, request a new one next time
+		ins.token = ""
+	}
+	return err
+}
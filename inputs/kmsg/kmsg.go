@@ -0,0 +1,148 @@
+package kmsg
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"sync"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/types"
+)
+
+var kmsgLog = logger.New("input.kmsg")
+
+const inputName = "kmsg"
+
+type pattern struct {
+	event string
+	re    *regexp.Regexp
+}
+
+// events matched against the kernel messages in order, the OOM kill is reported
+// once by the "Killed process" line, both of global and memory cgroup OOM
+var defaultPatterns = []pattern{
+	{"oom_kill", regexp.MustCompile(`Killed process \d+`)},
+	{"hung_task", regexp.MustCompile(`blocked for more than \d+ seconds`)},
+	{"soft_lockup", regexp.MustCompile(`soft lockup - CPU#\d+ stuck`)},
+	{"ext4_error", regexp.MustCompile(`EXT4-fs (error|warning|\(.*\): .*error)`)},
+	{"xfs_error", regexp.MustCompile(`XFS \(.*\): (Corruption|metadata I/O error|.*[Ss]hut(ting)? ?down)`)},
+	{"io_error", regexp.MustCompile(`(Buffer I/O error|blk_update_request: .*error|I/O error, dev)`)},
+	{"mce", regexp.MustCompile(`(\[Hardware Error\]|Machine check events logged|mce: .*error)`)},
+	{"segfault", regexp.MustCompile(`segfault at [0-9a-f]+`)},
+	{"nf_conntrack", regexp.MustCompile(`nf_conntrack: .*table full, dropping packet`)},
+}
+
+var levels = []string{"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"}
+
+type Kmsg struct {
+	config.PluginConfig
+	Enable bool   `toml:"enable"`
+	Path   string `toml:"path"`
+
+	// more events to count, name to regular expression of the message
+	Events map[string]string `toml:"events"`
+
+	// forward the lines to the logs agent, logs.enable must be true,
+	// only the lines of events are forwarded unless forward_all is true
+	ForwardLogs bool   `toml:"forward_logs"`
+	ForwardAll  bool   `toml:"forward_all"`
+	LogsService string `toml:"logs_service"`
+	LogsSource  string `toml:"logs_source"`
+
+	patterns []pattern
+	logs     *config.LogChannel
+	file     *os.File
+	stop     chan struct{}
+
+	sync.Mutex
+	events   map[string]float64
+	messages map[string]float64
+	dropped  float64
+}
+
+func init() {
+	inputs.Add(inputName, func() inputs.Input {
+		return &Kmsg{}
+	})
+}
+
+func (k *Kmsg) Clone() inputs.Input {
+	return &Kmsg{}
+}
+
+func (k *Kmsg) Name() string {
+	return inputName
+}
+
+func (k *Kmsg) compile() error {
+	// the configured events take precedence over the default ones
+	names := make([]string, 0, len(k.Events))
+	for name := range k.Events {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		re, err := regexp.Compile(k.Events[name])
+		if err != nil {
+			return fmt.Errorf("failed to compile regexp of event %s: %v", name, err)
+		}
+		k.patterns = append(k.patterns, pattern{name, re})
+	}
+
+	for _, p := range defaultPatterns {
+		if _, has := k.Events[p.event]; !has {
+			k.patterns = append(k.patterns, p)
+		}
+	}
+	return nil
+}
+
+// handle accounts a kernel message by the first event matched
+func (k *Kmsg) handle(priority int, message string) {
+	event := ""
+	for _, p := range k.patterns {
+		if p.re.MatchString(message) {
+			event = p.event
+			break
+		}
+	}
+
+	k.Lock()
+	defer k.Unlock()
+
+	k.messages[levels[priority&7]]++
+	if event != "" {
+		k.events[event]++
+	}
+
+	if k.logs == nil || (event == "" && !k.ForwardAll) {
+		return
+	}
+
+	if !k.logs.Send([]byte(message)) {
+		k.dropped++
+	}
+}
+
+func (k *Kmsg) Gather(slist *types.SampleList) {
+	k.Lock()
+	defer k.Unlock()
+
+	// the events never happened are reported as 0, so that increase() works from the first one
+	for _, p := range k.patterns {
+		slist.PushSample(inputName, "events_total", k.events[p.event], map[string]string{"event": p.event})
+	}
+
+	for level, value := range k.messages {
+		slist.PushSample(inputName, "messages_total", value, map[string]string{"level": level})
+	}
+
+	if k.logs != nil {
+		slist.PushSample(inputName, "logs_dropped_total", k.dropped)
+	}
+}
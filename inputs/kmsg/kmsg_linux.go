@@ -0,0 +1,121 @@
+//go:build linux
+// +build linux
+
+package kmsg
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/types"
+)
+
+// a read returns a record, which fails with EINVAL if the buffer is smaller than the record
+const recordSize = 8192
+
+func (k *Kmsg) Init() error {
+	if !k.Enable {
+		return types.ErrInstancesEmpty
+	}
+
+	if k.Path == "" {
+		k.Path = "/dev/kmsg"
+	}
+
+	if k.LogsService == "" {
+		k.LogsService = "kernel"
+	}
+
+	if k.LogsSource == "" {
+		k.LogsSource = inputName
+	}
+
+	if err := k.compile(); err != nil {
+		return err
+	}
+
+	f, err := os.Open(k.Path)
+	if err != nil {
+		return err
+	}
+
+	// the messages in ring buffer are history, e.g. of the boot
+	if _, err := f.Seek(0, io.SeekEnd); err != nil {
+		f.Close()
+		return err
+	}
+
+	k.events = make(map[string]float64)
+	k.messages = make(map[string]float64)
+	if k.ForwardLogs {
+		k.logs = config.NewLogChannel(inputName+"/"+k.Path, k.LogsService, k.LogsSource, "")
+	}
+
+	k.file = f
+	k.stop = make(chan struct{})
+	go k.read()
+	return nil
+}
+
+func (k *Kmsg) Drop() {
+	if k.stop != nil {
+		close(k.stop)
+		// /dev/kmsg is pollable, closing interrupts the blocking read
+		k.file.Close()
+	}
+}
+
+// read reads a record per read, the record is formatted as
+// "priority,sequence,timestamp,flags;message\n" followed by the continuation lines
+func (k *Kmsg) read() {
+	buf := make([]byte, recordSize)
+	for {
+		n, err := k.file.Read(buf)
+
+		select {
+		case <-k.stop:
+			return
+		default:
+		}
+
+		if err != nil {
+			// the records are overwritten before read, the next read gets the oldest available one
+			if errors.Is(err, syscall.EPIPE) {
+				continue
+			}
+			kmsgLog.Errorf("failed to read %v error: %v", k.Path, err)
+			return
+		}
+
+		priority, message, ok := parseRecord(string(buf[:n]))
+		if ok {
+			k.handle(priority, message)
+		}
+	}
+}
+
+func parseRecord(record string) (int, string, bool) {
+	semicolon := strings.IndexByte(record, ';')
+	if semicolon < 0 {
+		return 0, "", false
+	}
+
+	header := strings.SplitN(record[:semicolon], ",", 2)
+	prefix, err := strconv.Atoi(header[0])
+	if err != nil {
+		return 0, "", false
+	}
+
+	message := record[semicolon+1:]
+	if i := strings.IndexByte(message, '\n'); i >= 0 {
+		message = message[:i]
+	}
+
+	// the prefix is facility << 3 | level
+	return prefix & 7, message, true
+}
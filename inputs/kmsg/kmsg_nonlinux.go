@@ -0,0 +1,8 @@
+//go:build !linux
+// +build !linux
+
+package kmsg
+
+func (k *Kmsg) Init() error {
+	return nil
+}
@@ -0,0 +1,376 @@
+package kube_events
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/leader"
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/types"
+)
+
+var kubeEventsLog = logger.New("input.kube_events")
+
+const (
+	inputName = "kube_events"
+
+	defaultDedupWindow = 5 * time.Minute
+)
+
+type KubeEvents struct {
+	config.PluginConfig
+	Instances []*Instance `toml:"instances"`
+}
+
+func init() {
+	inputs.Add(inputName, func() inputs.Input {
+		return &KubeEvents{}
+	})
+}
+
+func (k *KubeEvents) Clone() inputs.Input {
+	return &KubeEvents{}
+}
+
+func (k *KubeEvents) Name() string {
+	return inputName
+}
+
+func (k *KubeEvents) GetInstances() []inputs.Instance {
+	ret := make([]inputs.Instance, len(k.Instances))
+	for i := 0; i < len(k.Instances); i++ {
+		ret[i] = k.Instances[i]
+	}
+	return ret
+}
+
+func (k *KubeEvents) Drop() {
+	for i := 0; i < len(k.Instances); i++ {
+		k.Instances[i].Drop()
+	}
+}
+
+type Instance struct {
+	config.InstanceConfig
+
+	Enable bool `toml:"enable"`
+	// in cluster config of the service account if empty
+	Kubeconfig string `toml:"kubeconfig"`
+
+	// all namespaces if empty
+	Namespaces []string `toml:"namespaces"`
+	// Normal or Warning, all types if empty
+	Types          []string `toml:"types"`
+	Reasons        []string `toml:"reasons"`
+	ExcludeReasons []string `toml:"exclude_reasons"`
+
+	// the same event (object, reason and message) is forwarded once in dedup_window
+	DedupWindow config.Duration `toml:"dedup_window"`
+
+	// forward events to the logs agent, logs.enable must be true
+	ForwardLogs bool   `toml:"forward_logs"`
+	LogsService string `toml:"logs_service"`
+	LogsSource  string `toml:"logs_source"`
+
+	client     kubernetes.Interface
+	namespaces []string
+	unwatch    func()
+	logs       *config.LogChannel
+	types      map[string]struct{}
+	reasons    map[string]struct{}
+	excludes   map[string]struct{}
+
+	sync.Mutex
+	// the informers run only if the input is allowed on this replica, see leader.Watch,
+	// stop is nil if they are not running
+	started   time.Time
+	stop      chan struct{}
+	counters  map[eventKey]float64
+	forwarded map[string]time.Time
+	dropped   float64
+}
+
+type eventKey struct {
+	namespace string
+	kind      string
+	reason    string
+	typ       string
+}
+
+// eventLog is the log message forwarded for an event
+type eventLog struct {
+	Time      time.Time `json:"time"`
+	Namespace string    `json:"namespace"`
+	Kind      string    `json:"kind"`
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+	Reason    string    `json:"reason"`
+	Message   string    `json:"message"`
+	Count     int32     `json:"count"`
+	Component string    `json:"component,omitempty"`
+	Host      string    `json:"host,omitempty"`
+}
+
+func toSet(items []string) map[string]struct{} {
+	if len(items) == 0 {
+		return nil
+	}
+	set := make(map[string]struct{}, len(items))
+	for _, item := range items {
+		set[item] = struct{}{}
+	}
+	return set
+}
+
+func (ins *Instance) Init() error {
+	if !ins.Enable {
+		return types.ErrInstancesEmpty
+	}
+
+	if ins.DedupWindow <= 0 {
+		ins.DedupWindow = config.Duration(defaultDedupWindow)
+	}
+
+	if ins.LogsService == "" {
+		ins.LogsService = "kubernetes"
+	}
+
+	if ins.LogsSource == "" {
+		ins.LogsSource = inputName
+	}
+
+	var (
+		restConfig *rest.Config
+		err        error
+	)
+	if ins.Kubeconfig == "" {
+		restConfig, err = rest.InClusterConfig()
+	} else {
+		restConfig, err = clientcmd.BuildConfigFromFlags("", ins.Kubeconfig)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load kubernetes config: %v", err)
+	}
+
+	client, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create kubernetes client: %v", err)
+	}
+
+	ins.types = toSet(ins.Types)
+	ins.reasons = toSet(ins.Reasons)
+	ins.excludes = toSet(ins.ExcludeReasons)
+	ins.counters = make(map[eventKey]float64)
+	ins.forwarded = make(map[string]time.Time)
+	if ins.ForwardLogs {
+		ins.logs = config.NewLogChannel(inputName+"/"+ins.Kubeconfig+"/"+strings.Join(ins.Namespaces, ","), ins.LogsService, ins.LogsSource, "")
+	}
+
+	ins.client = client
+	ins.namespaces = ins.Namespaces
+	if len(ins.namespaces) == 0 {
+		ins.namespaces = []string{metav1.NamespaceAll}
+	}
+
+	// the events are watched by the leader only if the input is cluster scoped
+	ins.unwatch = leader.Watch(inputName, ins.watch)
+	return nil
+}
+
+// watch starts the informers of events if allowed, or stops them
+func (ins *Instance) watch(allowed bool) {
+	ins.Lock()
+	defer ins.Unlock()
+
+	if !allowed {
+		if ins.stop != nil {
+			close(ins.stop)
+			ins.stop = nil
+			// the dedup of the next leadership starts over
+			ins.forwarded = make(map[string]time.Time)
+		}
+		return
+	}
+	if ins.stop != nil {
+		return
+	}
+
+	// events listed at start are history, only the events happened later are reported
+	ins.started = time.Now()
+	stop := make(chan struct{})
+	ins.stop = stop
+
+	for _, ns := range ins.namespaces {
+		// no resync, the watch delivers every change of events
+		factory := informers.NewSharedInformerFactoryWithOptions(ins.client, 0, informers.WithNamespace(ns))
+		informer := factory.Core().V1().Events().Informer()
+		informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				if e, ok := obj.(*corev1.Event); ok {
+					ins.handle(stop, e, 0)
+				}
+			},
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				old, ok1 := oldObj.(*corev1.Event)
+				e, ok2 := newObj.(*corev1.Event)
+				if ok1 && ok2 {
+					ins.handle(stop, e, eventCount(old))
+				}
+			},
+		})
+		factory.Start(stop)
+	}
+}
+
+func (ins *Instance) Drop() {
+	if ins.unwatch != nil {
+		ins.unwatch()
+	}
+	ins.watch(false)
+}
+
+// eventCount returns the number of occurrences of the event,
+// the series is used by events created through events.k8s.io
+func eventCount(e *corev1.Event) int32 {
+	if e.Series != nil && e.Series.Count > 0 {
+		return e.Series.Count
+	}
+	if e.Count > 0 {
+		return e.Count
+	}
+	return 1
+}
+
+func eventTime(e *corev1.Event) time.Time {
+	switch {
+	case e.Series != nil && !e.Series.LastObservedTime.IsZero():
+		return e.Series.LastObservedTime.Time
+	case !e.LastTimestamp.IsZero():
+		return e.LastTimestamp.Time
+	case !e.EventTime.IsZero():
+		return e.EventTime.Time
+	default:
+		return e.CreationTimestamp.Time
+	}
+}
+
+func (ins *Instance) match(e *corev1.Event) bool {
+	if _, has := ins.excludes[e.Reason]; has {
+		return false
+	}
+	if ins.types != nil {
+		if _, has := ins.types[e.Type]; !has {
+			return false
+		}
+	}
+	if ins.reasons != nil {
+		if _, has := ins.reasons[e.Reason]; !has {
+			return false
+		}
+	}
+	return true
+}
+
+// handle accounts the occurrences of the event since oldCount, the events delivered by the informers
+// stopped already are skipped
+func (ins *Instance) handle(stop chan struct{}, e *corev1.Event, oldCount int32) {
+	if !ins.match(e) {
+		return
+	}
+
+	count := eventCount(e)
+	delta := count - oldCount
+	if delta <= 0 {
+		return
+	}
+
+	key := eventKey{
+		namespace: e.InvolvedObject.Namespace,
+		kind:      e.InvolvedObject.Kind,
+		reason:    e.Reason,
+		typ:       e.Type,
+	}
+	if key.namespace == "" {
+		key.namespace = e.Namespace
+	}
+
+	ins.Lock()
+	defer ins.Unlock()
+
+	ts := eventTime(e)
+	if ins.stop != stop || ts.Before(ins.started) {
+		return
+	}
+
+	ins.counters[key] += float64(delta)
+
+	if ins.logs == nil {
+		return
+	}
+
+	dedup := strings.Join([]string{key.namespace, key.kind, e.InvolvedObject.Name, e.Reason, e.Message}, "/")
+	if last, has := ins.forwarded[dedup]; has && ts.Sub(last) < time.Duration(ins.DedupWindow) {
+		return
+	}
+	ins.forwarded[dedup] = ts
+
+	bs, err := json.Marshal(eventLog{
+		Time:      ts,
+		Namespace: key.namespace,
+		Kind:      key.kind,
+		Name:      e.InvolvedObject.Name,
+		Type:      e.Type,
+		Reason:    e.Reason,
+		Message:   e.Message,
+		Count:     count,
+		Component: e.Source.Component,
+		Host:      e.Source.Host,
+	})
+	if err != nil {
+		kubeEventsLog.Errorf("failed to marshal kubernetes event: %v", err)
+		return
+	}
+
+	if !ins.logs.Send(bs) {
+		ins.dropped++
+	}
+}
+
+func (ins *Instance) Gather(slist *types.SampleList) {
+	ins.Lock()
+	defer ins.Unlock()
+
+	for key, value := range ins.counters {
+		slist.PushSample(inputName, "total", value, map[string]string{
+			"namespace": key.namespace,
+			"kind":      key.kind,
+			"reason":    key.reason,
+			"type":      key.typ,
+		})
+	}
+
+	if ins.logs != nil {
+		slist.PushSample(inputName, "logs_dropped_total", ins.dropped)
+	}
+
+	// entries out of the window no longer dedup anything
+	now := time.Now()
+	for k, last := range ins.forwarded {
+		if now.Sub(last) >= time.Duration(ins.DedupWindow) {
+			delete(ins.forwarded, k)
+		}
+	}
+}
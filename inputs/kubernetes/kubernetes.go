@@ -3,7 +3,6 @@ package kubernetes
 import (
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
 	"os"
 	"strings"
@@ -12,10 +11,13 @@ import (
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
 	"flashcat.cloud/categraf/pkg/filter"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/pkg/tls"
 	"flashcat.cloud/categraf/types"
 )
 
+var kubernetesLog = logger.New("input.kubernetes")
+
 const (
 	inputName                 = "kubernetes"
 	defaultServiceAccountPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
@@ -110,7 +112,7 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 	urlpath := fmt.Sprintf("%s/stats/summary", ins.URL)
 	err := ins.LoadJSON(urlpath, summaryMetrics)
 	if err != nil {
-		log.Println("E! failed to load", urlpath, "error:", err)
+		kubernetesLog.Errorf("failed to load %v error: %v", urlpath, err)
 		slist.PushSample(inputName, "kubelet_up", 0)
 		return
 	}
@@ -119,7 +121,7 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 
 	podInfos, err := ins.gatherPodInfo(ins.URL)
 	if err != nil {
-		log.Println("E! failed to gather pod info, error:", err)
+		kubernetesLog.Errorf("failed to gather pod info, error: %v", err)
 		return
 	}
 
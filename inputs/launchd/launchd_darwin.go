@@ -0,0 +1,124 @@
+//go:build darwin
+// +build darwin
+
+package launchd
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/cmdx"
+	"flashcat.cloud/categraf/pkg/filter"
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/types"
+)
+
+var launchdLog = logger.New("input.launchd")
+
+const inputName = "launchd"
+
+type Launchd struct {
+	config.PluginConfig
+	// support glob, all services if empty
+	LabelsInclude  []string        `toml:"labels_include"`
+	LabelsExclude  []string        `toml:"labels_exclude"`
+	CommandTimeout config.Duration `toml:"command_timeout"`
+
+	labelFilter filter.Filter
+}
+
+func init() {
+	inputs.Add(inputName, func() inputs.Input {
+		return &Launchd{}
+	})
+}
+
+func (l *Launchd) Clone() inputs.Input {
+	return &Launchd{}
+}
+
+func (l *Launchd) Name() string {
+	return inputName
+}
+
+func (l *Launchd) Init() error {
+	if l.CommandTimeout <= 0 {
+		l.CommandTimeout = config.Duration(5 * time.Second)
+	}
+
+	var err error
+	l.labelFilter, err = filter.NewIncludeExcludeFilter(l.LabelsInclude, l.LabelsExclude)
+	return err
+}
+
+// service is a line of launchctl list, e.g. "123	0	com.example.agent",
+// pid is "-" if it's not running, and the status is the last exit status, negative if killed by signal
+type service struct {
+	label   string
+	running bool
+	status  int
+}
+
+func (l *Launchd) Gather(slist *types.SampleList) {
+	services, err := l.list()
+	if err != nil {
+		launchdLog.Errorf("failed to list launchd services: %v", err)
+		slist.PushSample(inputName, "up", 0)
+		return
+	}
+
+	for _, s := range services {
+		if !l.labelFilter.Match(s.label) {
+			continue
+		}
+
+		running := 0
+		if s.running {
+			running = 1
+		}
+		tags := map[string]string{"label": s.label}
+		slist.PushSample(inputName, "service_running", running, tags)
+		slist.PushSample(inputName, "service_last_exit_status", s.status, tags)
+	}
+
+	slist.PushSample(inputName, "up", 1)
+}
+
+// list returns the services of the domain of the user running categraf, the system domain for root
+func (l *Launchd) list() ([]service, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("launchctl", "list")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err, timeout := cmdx.RunTimeout(cmd, time.Duration(l.CommandTimeout))
+	if timeout {
+		return nil, fmt.Errorf("launchctl list timeout after %s", time.Duration(l.CommandTimeout))
+	}
+
+	if err != nil {
+		if stderr.Len() > 0 {
+			return nil, fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		return nil, err
+	}
+
+	var services []service
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 || fields[0] == "PID" {
+			continue
+		}
+
+		// the status is "-" if the service has never exited
+		status, _ := strconv.Atoi(fields[1])
+		services = append(services, service{label: fields[2], running: fields[0] != "-", status: status})
+	}
+	return services, nil
+}
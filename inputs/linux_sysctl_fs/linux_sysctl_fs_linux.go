@@ -6,17 +6,19 @@ package linux_sysctl_fs
 import (
 	"bytes"
 	"errors"
-	"log"
 	"os"
 	"path"
 	"strconv"
 
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/pkg/osx"
 	"flashcat.cloud/categraf/types"
 )
 
+var linuxSysctlFsLog = logger.New("input.linux_sysctl_fs")
+
 const inputName = "linux_sysctl_fs"
 
 type SysctlFS struct {
@@ -48,23 +50,23 @@ func (s *SysctlFS) Gather(slist *types.SampleList) {
 
 	for _, n := range []string{"aio-nr", "aio-max-nr", "dquot-nr", "dquot-max", "super-nr", "super-max"} {
 		if err := s.gatherOne(n, fields); err != nil {
-			log.Println("E! failed to gather sysctl fs:", err)
+			linuxSysctlFsLog.Errorf("failed to gather sysctl fs: %v", err)
 		}
 	}
 
 	err := s.gatherList("inode-state", fields, "inode-nr", "inode-free-nr", "inode-preshrink-nr")
 	if err != nil {
-		log.Println("E! failed to gather inode-state:", err)
+		linuxSysctlFsLog.Errorf("failed to gather inode-state: %v", err)
 	}
 
 	err = s.gatherList("dentry-state", fields, "dentry-nr", "dentry-unused-nr", "dentry-age-limit", "dentry-want-pages")
 	if err != nil {
-		log.Println("E! failed to gather dentry-state:", err)
+		linuxSysctlFsLog.Errorf("failed to gather dentry-state: %v", err)
 	}
 
 	err = s.gatherList("file-nr", fields, "file-nr", "", "file-max")
 	if err != nil {
-		log.Println("E! failed to gather file-nr:", err)
+		linuxSysctlFsLog.Errorf("failed to gather file-nr: %v", err)
 	}
 
 	slist.PushSamples(inputName, fields)
@@ -36,12 +36,13 @@ func (lp *LocalProvider) StartReloader() {}
 func (lp *LocalProvider) StopReloader() {}
 
 func (lp *LocalProvider) LoadConfig() (bool, error) {
-	dirs, err := file.DirsUnder(lp.configDir)
+	// the inputs of the overlays are enabled as well
+	dirs, err := config.DirsUnderLayers(lp.configDir)
 	if err != nil {
-		return false, fmt.Errorf("failed to get dirs under %s : %v", config.Config.ConfigDir, err)
+		return false, err
 	}
 
-	names := make([]string, 0, len(dirs))
+	var names []string
 	for _, dir := range dirs {
 		if strings.HasPrefix(dir, inputFilePrefix) {
 			names = append(names, dir[len(inputFilePrefix):])
@@ -73,6 +74,10 @@ func (lp *LocalProvider) GetInputConfig(inputKey string) ([]cfg.ConfigWithFormat
 	}
 	lp.RUnlock()
 
+	if len(config.Overlays) > 0 {
+		return cfg.ReadLayers(config.Layers(lp.configDir, inputFilePrefix+inputKey))
+	}
+
 	files, err := file.FilesUnder(path.Join(lp.configDir, inputFilePrefix+inputKey))
 	if err != nil {
 		return nil, fmt.Errorf("failed to list files under: %s : %v", lp.configDir, err)
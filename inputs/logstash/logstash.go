@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"net/url"
 	"strings"
@@ -14,10 +13,13 @@ import (
 	"flashcat.cloud/categraf/inputs"
 	"flashcat.cloud/categraf/pkg/choice"
 	"flashcat.cloud/categraf/pkg/jsonx"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/pkg/tls"
 	"flashcat.cloud/categraf/types"
 )
 
+var logstashLog = logger.New("input.logstash")
+
 const inputName = "logstash"
 
 type Logstash struct {
@@ -157,11 +159,11 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 	if choice.Contains("jvm", ins.Collect) {
 		jvmURL, err := url.Parse(ins.URL + jvmStats)
 		if err != nil {
-			log.Println("E! failed to parse url:", ins.URL+jvmStats)
+			logstashLog.Errorf("failed to parse url: %v", ins.URL+jvmStats)
 			return
 		}
 		if err := ins.gatherJVMStats(jvmURL.String(), slist); err != nil {
-			log.Println("E! failed to gather jvm stats:", err)
+			logstashLog.Errorf("failed to gather jvm stats: %v", err)
 			return
 		}
 	}
@@ -169,11 +171,11 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 	if choice.Contains("process", ins.Collect) {
 		processURL, err := url.Parse(ins.URL + processStats)
 		if err != nil {
-			log.Println("E! failed to parse url:", ins.URL+processStats)
+			logstashLog.Errorf("failed to parse url: %v", ins.URL+processStats)
 			return
 		}
 		if err := ins.gatherProcessStats(processURL.String(), slist); err != nil {
-			log.Println("E! failed to gather process stats:", err)
+			logstashLog.Errorf("failed to gather process stats: %v", err)
 			return
 		}
 	}
@@ -182,21 +184,21 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 		if ins.SinglePipeline {
 			pipelineURL, err := url.Parse(ins.URL + pipelineStats)
 			if err != nil {
-				log.Println("E! failed to parse url:", ins.URL+pipelineStats)
+				logstashLog.Errorf("failed to parse url: %v", ins.URL+pipelineStats)
 				return
 			}
 			if err := ins.gatherPipelineStats(pipelineURL.String(), slist); err != nil {
-				log.Println("E! failed to gather pipeline stats:", err)
+				logstashLog.Errorf("failed to gather pipeline stats: %v", err)
 				return
 			}
 		} else {
 			pipelinesURL, err := url.Parse(ins.URL + pipelinesStats)
 			if err != nil {
-				log.Println("E! failed to parse url:", ins.URL+pipelinesStats)
+				logstashLog.Errorf("failed to parse url: %v", ins.URL+pipelinesStats)
 				return
 			}
 			if err := ins.gatherPipelinesStats(pipelinesURL.String(), slist); err != nil {
-				log.Println("E! failed to gather pipelines stats:", err)
+				logstashLog.Errorf("failed to gather pipelines stats: %v", err)
 				return
 			}
 		}
@@ -1,15 +1,17 @@
 package mem
 
 import (
-	"log"
 	"runtime"
 
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
 	"flashcat.cloud/categraf/inputs/system"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/types"
 )
 
+var memLog = logger.New("input.mem")
+
 const inputName = "mem"
 
 type MemStats struct {
@@ -17,6 +19,9 @@ type MemStats struct {
 
 	config.PluginConfig
 	CollectPlatformFields bool `toml:"collect_platform_fields"`
+	// linux only, per node memory and allocation stats, and hugepage pools
+	CollectNuma      bool `toml:"collect_numa"`
+	CollectHugepages bool `toml:"collect_hugepages"`
 }
 
 func init() {
@@ -41,7 +46,7 @@ func (s *MemStats) Name() string {
 func (s *MemStats) Gather(slist *types.SampleList) {
 	vm, err := s.ps.VMStat()
 	if err != nil {
-		log.Println("E! failed to get vmstat:", err)
+		memLog.Errorf("failed to get vmstat: %v", err)
 		return
 	}
 
@@ -60,6 +65,7 @@ func (s *MemStats) Gather(slist *types.SampleList) {
 			fields["free"] = vm.Free
 			fields["inactive"] = vm.Inactive
 			fields["wired"] = vm.Wired
+			gatherDarwinVMStat(fields)
 		case "openbsd":
 			fields["active"] = vm.Active
 			fields["cached"] = vm.Cached
@@ -74,7 +80,7 @@ func (s *MemStats) Gather(slist *types.SampleList) {
 			fields["inactive"] = vm.Inactive
 			fields["laundry"] = vm.Laundry
 			fields["wired"] = vm.Wired
-		case "linux":
+		case "linux", "android":
 			fields["active"] = vm.Active
 			fields["buffered"] = vm.Buffers
 			fields["cached"] = vm.Cached
@@ -108,4 +114,12 @@ func (s *MemStats) Gather(slist *types.SampleList) {
 	}
 
 	slist.PushSamples(inputName, fields)
+
+	if s.CollectNuma {
+		s.gatherNuma(slist)
+	}
+
+	if s.CollectHugepages {
+		s.gatherHugepages(slist)
+	}
 }
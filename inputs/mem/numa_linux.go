@@ -0,0 +1,175 @@
+//go:build linux
+// +build linux
+
+package mem
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"flashcat.cloud/categraf/pkg/osx"
+	"flashcat.cloud/categraf/types"
+)
+
+// fields of /sys/devices/system/node/node(N)/meminfo, converted from kB to bytes
+var numaMeminfoFields = map[string]string{
+	"MemTotal":  "total",
+	"MemFree":   "free",
+	"MemUsed":   "used",
+	"FilePages": "file_pages",
+	"AnonPages": "anon_pages",
+	"Shmem":     "shmem",
+	"Slab":      "slab",
+}
+
+// fields of /sys/devices/system/node/node(N)/numastat, counters of pages allocated
+var numastatFields = map[string]string{
+	"numa_hit":       "hit",
+	"numa_miss":      "miss",
+	"numa_foreign":   "foreign",
+	"interleave_hit": "interleave_hit",
+	"local_node":     "local_node",
+	"other_node":     "other_node",
+}
+
+func (s *MemStats) gatherNuma(slist *types.SampleList) {
+	nodes, err := filepath.Glob(filepath.Join(osx.GetHostSys(), "devices/system/node/node[0-9]*"))
+	if err != nil {
+		memLog.Errorf("failed to list numa nodes: %v", err)
+		return
+	}
+
+	for _, dir := range nodes {
+		tags := map[string]string{"node": strings.TrimPrefix(filepath.Base(dir), "node")}
+		fields := make(map[string]interface{})
+
+		if err := readNodeMeminfo(filepath.Join(dir, "meminfo"), fields); err != nil {
+			memLog.Errorf("failed to read meminfo of numa node: %v error: %v", dir, err)
+		}
+
+		if total, ok := fields["total"].(uint64); ok && total > 0 {
+			if used, ok := fields["used"].(uint64); ok {
+				fields["used_percent"] = 100 * float64(used) / float64(total)
+			}
+		}
+
+		if err := readKeyValues(filepath.Join(dir, "numastat"), numastatFields, fields); err != nil {
+			memLog.Errorf("failed to read numastat of numa node: %v error: %v", dir, err)
+		}
+
+		slist.PushSamples(inputName+"_numa", fields, tags)
+
+		gatherHugepages(slist, filepath.Join(dir, "hugepages"), inputName+"_numa_hugepages", tags)
+	}
+}
+
+func (s *MemStats) gatherHugepages(slist *types.SampleList) {
+	gatherHugepages(slist, filepath.Join(osx.GetHostSys(), "kernel/mm/hugepages"), inputName+"_hugepages", nil)
+}
+
+// gatherHugepages gathers the pools of every page size under dir, e.g. hugepages-2048kB,
+// the nodes have no resv_hugepages, which are accounted system wide
+func gatherHugepages(slist *types.SampleList, dir, prefix string, tags map[string]string) {
+	pools, err := filepath.Glob(filepath.Join(dir, "hugepages-*"))
+	if err != nil {
+		memLog.Errorf("failed to list hugepages: %v", err)
+		return
+	}
+
+	for _, pool := range pools {
+		size := strings.TrimPrefix(filepath.Base(pool), "hugepages-")
+		fields := make(map[string]interface{})
+		for file, name := range map[string]string{
+			"nr_hugepages":      "total",
+			"free_hugepages":    "free",
+			"resv_hugepages":    "reserved",
+			"surplus_hugepages": "surplus",
+		} {
+			if v, err := readUint(filepath.Join(pool, file)); err == nil {
+				fields[name] = v
+			}
+		}
+
+		total, ok1 := fields["total"].(uint64)
+		free, ok2 := fields["free"].(uint64)
+		if ok1 && ok2 && total >= free {
+			fields["used"] = total - free
+			if total > 0 {
+				fields["used_percent"] = 100 * float64(total-free) / float64(total)
+			}
+		}
+
+		slist.PushSamples(prefix, fields, tags, map[string]string{"size": size})
+	}
+}
+
+// readNodeMeminfo parses the lines like "Node 0 MemTotal:        6147400 kB"
+func readNodeMeminfo(file string, fields map[string]interface{}) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		parts := strings.Fields(scanner.Text())
+		if len(parts) < 4 {
+			continue
+		}
+
+		name, has := numaMeminfoFields[strings.TrimSuffix(parts[2], ":")]
+		if !has {
+			continue
+		}
+
+		v, err := strconv.ParseUint(parts[3], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		if len(parts) > 4 && parts[4] == "kB" {
+			v *= 1024
+		}
+		fields[name] = v
+	}
+	return scanner.Err()
+}
+
+// readKeyValues parses the lines like "numa_hit 106201369"
+func readKeyValues(file string, names map[string]string, fields map[string]interface{}) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		parts := strings.Fields(line)
+		if len(parts) != 2 {
+			continue
+		}
+
+		name, has := names[parts[0]]
+		if !has {
+			continue
+		}
+
+		v, err := strconv.ParseUint(parts[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		fields[name] = v
+	}
+	return nil
+}
+
+func readUint(file string) (uint64, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+// +build !linux
+
+package mem
+
+import (
+	"flashcat.cloud/categraf/types"
+)
+
+func (s *MemStats) gatherNuma(slist *types.SampleList) {
+}
+
+func (s *MemStats) gatherHugepages(slist *types.SampleList) {
+}
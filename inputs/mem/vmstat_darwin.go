@@ -0,0 +1,80 @@
+//go:build darwin
+// +build darwin
+
+package mem
+
+import (
+	"bufio"
+	"bytes"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// e.g. Mach Virtual Memory Statistics: (page size of 16384 bytes)
+var pageSizeRegexp = regexp.MustCompile(`page size of (\d+) bytes`)
+
+// the vm_statistics64 printed by vm_stat, the pages are converted to bytes, the others are counters
+var vmStatFields = map[string]string{
+	"Pages speculative":            "speculative",
+	"Pages purgeable":              "purgeable",
+	"File-backed pages":            "file_backed",
+	"Anonymous pages":              "anonymous",
+	"Pages occupied by compressor": "compressed",
+	"Pages stored in compressor":   "compressor_stored",
+	"Pageins":                      "pageins",
+	"Pageouts":                     "pageouts",
+	"Swapins":                      "swapins",
+	"Swapouts":                     "swapouts",
+	"Compressions":                 "compressions",
+	"Decompressions":               "decompressions",
+}
+
+// gatherDarwinVMStat adds the memory of macOS not in gopsutil, e.g. the compressed
+// and the app memory shown by the activity monitor, which is anonymous - purgeable
+func gatherDarwinVMStat(fields map[string]interface{}) {
+	out, err := exec.Command("vm_stat").Output()
+	if err != nil {
+		memLog.Errorf("failed to run vm_stat: %v", err)
+		return
+	}
+
+	pageSize := uint64(4096)
+	values := make(map[string]uint64)
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if m := pageSizeRegexp.FindStringSubmatch(line); m != nil {
+			pageSize, _ = strconv.ParseUint(m[1], 10, 64)
+			continue
+		}
+
+		i := strings.LastIndex(line, ":")
+		if i < 0 {
+			continue
+		}
+		name, ok := vmStatFields[strings.Trim(line[:i], `" `)]
+		if !ok {
+			continue
+		}
+		v, err := strconv.ParseUint(strings.Trim(line[i+1:], " ."), 10, 64)
+		if err != nil {
+			continue
+		}
+		values[name] = v
+	}
+
+	for name, v := range values {
+		switch name {
+		case "pageins", "pageouts", "swapins", "swapouts", "compressions", "decompressions":
+			fields[name] = v
+		default:
+			fields[name] = v * pageSize
+		}
+	}
+
+	if anonymous, ok := values["anonymous"]; ok && anonymous >= values["purgeable"] {
+		fields["app"] = (anonymous - values["purgeable"]) * pageSize
+	}
+}
@@ -0,0 +1,7 @@
+//go:build !darwin
+// +build !darwin
+
+package mem
+
+func gatherDarwinVMStat(fields map[string]interface{}) {
+}
@@ -2,16 +2,18 @@ package mongodb
 
 import (
 	"fmt"
-	"log"
 	"time"
 
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
 	"flashcat.cloud/categraf/inputs/mongodb/exporter"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/types"
 	"github.com/sirupsen/logrus"
 )
 
+var mongodbLog = logger.New("input.mongodb")
+
 const inputName = "mongodb"
 
 type MongoDB struct {
@@ -131,6 +133,6 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 
 	err := inputs.Collect(ins.e, slist)
 	if err != nil {
-		log.Println("E! failed to collect metrics:", err)
+		mongodbLog.Errorf("failed to collect metrics: %v", err)
 	}
 }
@@ -0,0 +1,110 @@
+//go:build windows
+// +build windows
+
+package msmq
+
+import (
+	"strings"
+
+	"github.com/yusufpapurcu/wmi"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/filter"
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/types"
+)
+
+var msmqLog = logger.New("input.msmq")
+
+const inputName = "msmq"
+
+type MSMQ struct {
+	config.PluginConfig
+	// support glob, e.g. "*\\private$\\orders*", all if empty
+	QueuesInclude []string `toml:"queues_include"`
+	QueuesExclude []string `toml:"queues_exclude"`
+
+	queueFilter filter.Filter
+}
+
+func init() {
+	inputs.Add(inputName, func() inputs.Input {
+		return &MSMQ{}
+	})
+}
+
+func (m *MSMQ) Clone() inputs.Input {
+	return &MSMQ{}
+}
+
+func (m *MSMQ) Name() string {
+	return inputName
+}
+
+func (m *MSMQ) Init() error {
+	var err error
+	m.queueFilter, err = filter.NewIncludeExcludeFilter(m.QueuesInclude, m.QueuesExclude)
+	return err
+}
+
+type Win32_PerfRawData_MSMQ_MSMQQueue struct {
+	Name string
+
+	BytesinJournalQueue    uint64
+	BytesinQueue           uint64
+	MessagesinJournalQueue uint64
+	MessagesinQueue        uint64
+}
+
+type Win32_PerfRawData_MSMQ_MSMQService struct {
+	IncomingMessagestotal    uint64
+	OutgoingMessagestotal    uint64
+	MSMQIncomingMessages     uint64
+	MSMQOutgoingMessages     uint64
+	TotalbytesinallQueues    uint64
+	TotalmessagesinallQueues uint64
+	Sessions                 uint64
+}
+
+func (m *MSMQ) Gather(slist *types.SampleList) {
+	var queues []Win32_PerfRawData_MSMQ_MSMQQueue
+	if err := wmi.Query(wmi.CreateQuery(&queues, ""), &queues); err != nil {
+		msmqLog.Errorf("failed to gather msmq queues: %v", err)
+		slist.PushSample(inputName, "up", 0)
+		return
+	}
+
+	for _, q := range queues {
+		// e.g. Computer Queues, the sum of queues
+		if !strings.Contains(q.Name, "\\") || !m.queueFilter.Match(strings.ToLower(q.Name)) {
+			continue
+		}
+
+		slist.PushSamples(inputName, map[string]interface{}{
+			"queue_messages":         q.MessagesinQueue,
+			"queue_bytes":            q.BytesinQueue,
+			"journal_queue_messages": q.MessagesinJournalQueue,
+			"journal_queue_bytes":    q.BytesinJournalQueue,
+		}, map[string]string{"queue": strings.ToLower(q.Name)})
+	}
+
+	var service []Win32_PerfRawData_MSMQ_MSMQService
+	if err := wmi.Query(wmi.CreateQuery(&service, ""), &service); err != nil {
+		msmqLog.Errorf("failed to gather msmq service: %v", err)
+		slist.PushSample(inputName, "up", 0)
+		return
+	}
+
+	for _, s := range service {
+		slist.PushSamples(inputName, map[string]interface{}{
+			"incoming_messages_total": s.IncomingMessagestotal,
+			"outgoing_messages_total": s.OutgoingMessagestotal,
+			"messages":                s.TotalmessagesinallQueues,
+			"bytes":                   s.TotalbytesinallQueues,
+			"sessions":                s.Sessions,
+		})
+	}
+
+	slist.PushSample(inputName, "up", 1)
+}
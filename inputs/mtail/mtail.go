@@ -2,7 +2,6 @@ package mtail
 
 import (
 	"fmt"
-	"log"
 	"os"
 	"time"
 
@@ -15,10 +14,13 @@ import (
 	"flashcat.cloud/categraf/inputs/mtail/internal/metrics"
 	"flashcat.cloud/categraf/inputs/mtail/internal/mtail"
 	"flashcat.cloud/categraf/inputs/mtail/internal/waker"
+	"flashcat.cloud/categraf/pkg/logger"
 	util "flashcat.cloud/categraf/pkg/metrics"
 	"flashcat.cloud/categraf/types"
 )
 
+var mtailLog = logger.New("input.mtail")
+
 const inputName = `mtail`
 const description = ` extract internal monitoring data from application logs`
 
@@ -131,7 +133,7 @@ func (ins *Instance) Init() error {
 
 	m, err := mtail.New(ins.ctx, store, opts...)
 	if err != nil {
-		log.Println(err)
+		mtailLog.Infof("%v", err)
 		ins.cancel()
 		return err
 	}
@@ -179,7 +181,7 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 	reg := ins.m.GetRegistry()
 	mfs, done, err := prometheus.ToTransactionalGatherer(reg).Gather()
 	if err != nil {
-		log.Println(err)
+		mtailLog.Infof("%v", err)
 		return
 	}
 	defer done()
@@ -2,7 +2,6 @@ package mysql
 
 import (
 	"database/sql"
-	"log"
 	"strconv"
 	"strings"
 
@@ -14,7 +13,7 @@ func (ins *Instance) gatherBinlog(slist *types.SampleList, db *sql.DB, globalTag
 	var logBin uint8
 	err := db.QueryRow(`SELECT @@log_bin`).Scan(&logBin)
 	if err != nil {
-		log.Println("E! failed to query SELECT @@log_bin:", err)
+		mysqlLog.Errorf("failed to query SELECT @@log_bin: %v", err)
 		return
 	}
 
@@ -25,7 +24,7 @@ func (ins *Instance) gatherBinlog(slist *types.SampleList, db *sql.DB, globalTag
 
 	rows, err := db.Query(`SHOW BINARY LOGS`)
 	if err != nil {
-		log.Println("E! failed to query SHOW BINARY LOGS:", err)
+		mysqlLog.Errorf("failed to query SHOW BINARY LOGS: %v", err)
 		return
 	}
 
@@ -33,7 +32,7 @@ func (ins *Instance) gatherBinlog(slist *types.SampleList, db *sql.DB, globalTag
 
 	columns, err := rows.Columns()
 	if err != nil {
-		log.Println("E! failed to get columns:", err)
+		mysqlLog.Errorf("failed to get columns: %v", err)
 		return
 	}
 
@@ -57,7 +56,7 @@ func (ins *Instance) gatherBinlog(slist *types.SampleList, db *sql.DB, globalTag
 				return
 			}
 		default:
-			log.Println("E! invalid number of columns:", columnCount)
+			mysqlLog.Errorf("invalid number of columns: %v", columnCount)
 		}
 
 		size += filesize
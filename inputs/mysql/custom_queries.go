@@ -3,7 +3,6 @@ package mysql
 import (
 	"context"
 	"database/sql"
-	"log"
 	"strings"
 	"sync"
 	"time"
@@ -37,12 +36,12 @@ func (ins *Instance) gatherOneQuery(slist *types.SampleList, db *sql.DB, globalT
 
 	rows, err := db.QueryContext(ctx, query.Request)
 	if ctx.Err() == context.DeadlineExceeded {
-		log.Println("E! query timeout, request:", query.Request)
+		mysqlLog.Errorf("query timeout, request: %v", query.Request)
 		return
 	}
 
 	if err != nil {
-		log.Println("E! failed to query:", err)
+		mysqlLog.Errorf("failed to query: %v", err)
 		return
 	}
 
@@ -50,7 +49,7 @@ func (ins *Instance) gatherOneQuery(slist *types.SampleList, db *sql.DB, globalT
 
 	cols, err := rows.Columns()
 	if err != nil {
-		log.Println("E! failed to get columns:", err)
+		mysqlLog.Errorf("failed to get columns: %v", err)
 		return
 	}
 
@@ -63,7 +62,7 @@ func (ins *Instance) gatherOneQuery(slist *types.SampleList, db *sql.DB, globalT
 
 		// Scan the result into the column pointers...
 		if err := rows.Scan(columnPointers...); err != nil {
-			log.Println("E! failed to scan:", err)
+			mysqlLog.Errorf("failed to scan: %v", err)
 			return
 		}
 
@@ -74,7 +73,7 @@ func (ins *Instance) gatherOneQuery(slist *types.SampleList, db *sql.DB, globalT
 		}
 
 		if err = ins.parseRow(row, query, slist, globalTags); err != nil {
-			log.Println("E! failed to parse row:", err, "sql:", query.Request)
+			mysqlLog.Errorf("failed to parse row: %v sql: %v", err, query.Request)
 		}
 	}
 }
@@ -92,7 +91,7 @@ func (ins *Instance) parseRow(row map[string]string, query QueryConfig, slist *t
 	for _, column := range query.MetricFields {
 		value, err := conv.ToFloat64(row[column])
 		if err != nil {
-			log.Println("E! failed to convert field:", column, "value:", value, "error:", err)
+			mysqlLog.Errorf("failed to convert field: %v value: %v error: %v", column, value, err)
 			return err
 		}
 
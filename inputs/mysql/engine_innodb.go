@@ -2,7 +2,6 @@ package mysql
 
 import (
 	"database/sql"
-	"log"
 	"regexp"
 	"strconv"
 	"strings"
@@ -14,7 +13,7 @@ import (
 func (ins *Instance) gatherEngineInnodbStatus(slist *types.SampleList, db *sql.DB, globalTags map[string]string, cache map[string]float64) {
 	rows, err := db.Query(SQL_ENGINE_INNODB_STATUS)
 	if err != nil {
-		log.Println("E! failed to query engine innodb status:", err)
+		mysqlLog.Errorf("failed to query engine innodb status: %v", err)
 		return
 	}
 
@@ -24,7 +23,7 @@ func (ins *Instance) gatherEngineInnodbStatus(slist *types.SampleList, db *sql.D
 	// First row should contain the necessary info. If many rows returned then it's unknown case.
 	if rows.Next() {
 		if err := rows.Scan(&typeCol, &nameCol, &statusCol); err != nil {
-			log.Println("E! failed to scan result, sql:", SQL_ENGINE_INNODB_STATUS, "error:", err)
+			mysqlLog.Errorf("failed to scan result, sql: %v error: %v", SQL_ENGINE_INNODB_STATUS, err)
 			return
 		}
 	}
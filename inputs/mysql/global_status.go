@@ -2,7 +2,6 @@ package mysql
 
 import (
 	"database/sql"
-	"log"
 	"regexp"
 	"strconv"
 	"strings"
@@ -18,7 +17,7 @@ var globalStatusRE = regexp.MustCompile(`^(com|handler|connection_errors|innodb_
 func (ins *Instance) gatherGlobalStatus(slist *types.SampleList, db *sql.DB, globalTags map[string]string, cache map[string]float64) {
 	rows, err := db.Query(SQL_GLOBAL_STATUS)
 	if err != nil {
-		log.Println("E! failed to query global status:", err)
+		mysqlLog.Errorf("failed to query global status: %v", err)
 		return
 	}
 
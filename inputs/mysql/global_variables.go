@@ -2,7 +2,6 @@ package mysql
 
 import (
 	"database/sql"
-	"log"
 	"regexp"
 	"strconv"
 	"strings"
@@ -14,7 +13,7 @@ import (
 func (ins *Instance) gatherGlobalVariables(slist *types.SampleList, db *sql.DB, globalTags map[string]string, cache map[string]float64) {
 	rows, err := db.Query(SQL_GLOBAL_VARIABLES)
 	if err != nil {
-		log.Println("E! failed to query global variables:", err)
+		mysqlLog.Errorf("failed to query global variables: %v", err)
 		return
 	}
 
@@ -0,0 +1,101 @@
+package mysql
+
+import (
+	"database/sql"
+	"strings"
+
+	"flashcat.cloud/categraf/pkg/tagx"
+	"flashcat.cloud/categraf/types"
+)
+
+var groupReplicationMemberStats = []string{
+	"transactions_count",
+	"transactions_check",
+	"conflict_detected",
+	"transactions_row_validating",
+	"transactions_remote_applier_queue",
+	"transactions_remote_applied",
+	"transactions_local_proposed",
+	"transactions_local_rollback",
+}
+
+func (ins *Instance) gatherGroupReplication(slist *types.SampleList, db *sql.DB, globalTags map[string]string) {
+	if !ins.GatherGroupReplication {
+		return
+	}
+
+	var status string
+	if err := db.QueryRow(SQL_GROUP_REPLICATION_PLUGIN_STATUS).Scan(&status); err != nil || !strings.EqualFold(status, "active") {
+		// group replication is not enabled
+		return
+	}
+
+	ins.gatherGroupReplicationMembers(slist, db, globalTags)
+	ins.gatherGroupReplicationMemberStats(slist, db, globalTags)
+}
+
+func (ins *Instance) gatherGroupReplicationMembers(slist *types.SampleList, db *sql.DB, globalTags map[string]string) {
+	rows, err := db.Query(SQL_GROUP_REPLICATION_MEMBERS)
+	if err != nil {
+		mysqlLog.Errorf("failed to query group replication members: %v", err)
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, host, port, state, role string
+		if err := rows.Scan(&id, &host, &port, &state, &role); err != nil {
+			mysqlLog.Errorf("failed to scan group replication members: %v", err)
+			return
+		}
+
+		tags := tagx.Copy(globalTags)
+		tags["member_id"] = id
+		tags["member_host"] = host
+		tags["member_port"] = port
+		tags["member_role"] = role
+		tags["member_state"] = state
+
+		online := 0
+		if state == "ONLINE" {
+			online = 1
+		}
+
+		slist.PushFront(types.NewSample(inputName, "group_replication_member_online", online, tags))
+	}
+}
+
+func (ins *Instance) gatherGroupReplicationMemberStats(slist *types.SampleList, db *sql.DB, globalTags map[string]string) {
+	rows, err := db.Query(SQL_GROUP_REPLICATION_METRICS)
+	if err != nil {
+		mysqlLog.Errorf("failed to query group replication member stats: %v", err)
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var channel string
+		values := make([]sql.NullFloat64, len(groupReplicationMemberStats))
+		scanArgs := []interface{}{&channel}
+		for i := range values {
+			scanArgs = append(scanArgs, &values[i])
+		}
+
+		if err := rows.Scan(scanArgs...); err != nil {
+			mysqlLog.Errorf("failed to scan group replication member stats: %v", err)
+			return
+		}
+
+		tags := tagx.Copy(globalTags)
+		tags["channel_name"] = channel
+
+		for i, name := range groupReplicationMemberStats {
+			if !values[i].Valid {
+				continue
+			}
+			slist.PushFront(types.NewSample(inputName, "group_replication_"+name, values[i].Float64, tags))
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package mysql
+
+import (
+	"database/sql"
+	"fmt"
+
+	"flashcat.cloud/categraf/pkg/tagx"
+	"flashcat.cloud/categraf/types"
+)
+
+// gatherHeartbeat computes the replication lag from the heartbeat table maintained by pt-heartbeat,
+// which is more accurate than Seconds_Behind_Master
+func (ins *Instance) gatherHeartbeat(slist *types.SampleList, db *sql.DB, globalTags map[string]string) {
+	if ins.HeartbeatDatabase == "" {
+		return
+	}
+
+	table := ins.HeartbeatTable
+	if table == "" {
+		table = "heartbeat"
+	}
+
+	now := "NOW(6)"
+	if ins.HeartbeatUTC {
+		now = "UTC_TIMESTAMP(6)"
+	}
+
+	rows, err := db.Query(fmt.Sprintf(SQL_HEARTBEAT, now, ins.HeartbeatDatabase, table))
+	if err != nil {
+		mysqlLog.Errorf("failed to query heartbeat table: %v", err)
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var ts, now float64
+		var serverID string
+
+		if err := rows.Scan(&ts, &now, &serverID); err != nil {
+			mysqlLog.Errorf("failed to scan heartbeat rows: %v", err)
+			return
+		}
+
+		tags := tagx.Copy(globalTags)
+		tags["server_id"] = serverID
+
+		slist.PushFront(types.NewSample(inputName, "heartbeat_stored_timestamp_seconds", ts, tags))
+		slist.PushFront(types.NewSample(inputName, "heartbeat_now_timestamp_seconds", now, tags))
+		slist.PushFront(types.NewSample(inputName, "heartbeat_lag_seconds", now-ts, tags))
+	}
+}
@@ -0,0 +1,49 @@
+package mysql
+
+import (
+	"database/sql"
+
+	"flashcat.cloud/categraf/pkg/tagx"
+	"flashcat.cloud/categraf/types"
+)
+
+// gatherInnodbMetrics collects the enabled counters of information_schema.innodb_metrics,
+// e.g. subsystem buffer for buffer pool and log for redo log
+func (ins *Instance) gatherInnodbMetrics(slist *types.SampleList, db *sql.DB, globalTags map[string]string) {
+	if !ins.GatherInnodbMetrics {
+		return
+	}
+
+	subsystems := make(map[string]struct{}, len(ins.InnodbMetricsSubsystems))
+	for _, s := range ins.InnodbMetricsSubsystems {
+		subsystems[s] = struct{}{}
+	}
+
+	rows, err := db.Query(SQL_INNODB_METRICS)
+	if err != nil {
+		mysqlLog.Errorf("failed to query innodb metrics: %v", err)
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var name, subsystem string
+		var count float64
+
+		if err := rows.Scan(&name, &subsystem, &count); err != nil {
+			mysqlLog.Errorf("failed to scan innodb metrics: %v", err)
+			return
+		}
+
+		if len(subsystems) > 0 {
+			if _, has := subsystems[subsystem]; !has {
+				continue
+			}
+		}
+
+		tags := tagx.Copy(globalTags)
+		tags["subsystem"] = subsystem
+		slist.PushFront(types.NewSample(inputName, "innodb_metrics_"+name, count, tags))
+	}
+}
@@ -209,6 +209,14 @@ var OPTIONAL_INNODB_VARS = map[string]struct{}{
 	"innodb_x_lock_os_waits":                {},
 	"innodb_x_lock_spin_rounds":             {},
 	"innodb_x_lock_spin_waits":              {},
+	// redo log status vars added in MySQL 8.0.30
+	"innodb_redo_log_capacity_resized":    {},
+	"innodb_redo_log_checkpoint_lsn":      {},
+	"innodb_redo_log_current_lsn":         {},
+	"innodb_redo_log_flushed_to_disk_lsn": {},
+	"innodb_redo_log_logical_size":        {},
+	"innodb_redo_log_physical_size":       {},
+	"innodb_redo_log_enabled":             {},
 }
 
 var GALERA_VARS = map[string]struct{}{
@@ -3,17 +3,19 @@ package mysql
 import (
 	"database/sql"
 	"fmt"
-	"log"
 	"strings"
 	"time"
 
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/pkg/tls"
 	"flashcat.cloud/categraf/types"
 	"github.com/go-sql-driver/mysql"
 )
 
+var mysqlLog = logger.New("input.mysql")
+
 const inputName = "mysql"
 
 type QueryConfig struct {
@@ -45,10 +47,32 @@ type Instance struct {
 	GatherTableSize                 bool `toml:"gather_table_size"`
 	GatherSystemTableSize           bool `toml:"gather_system_table_size"`
 	GatherSlaveStatus               bool `toml:"gather_slave_status"`
+	GatherGroupReplication          bool `toml:"gather_group_replication"`
+	GatherInnodbMetrics             bool `toml:"gather_innodb_metrics"`
+
+	// subsystems of information_schema.innodb_metrics to collect, all if empty
+	InnodbMetricsSubsystems []string `toml:"innodb_metrics_subsystems"`
+
+	// replication lag from the heartbeat table of pt-heartbeat
+	HeartbeatDatabase string `toml:"heartbeat_database"`
+	HeartbeatTable    string `toml:"heartbeat_table"`
+	HeartbeatUTC      bool   `toml:"heartbeat_utc"`
+
+	// schema and table sizes are gathered every size_gather_interval, the last values are reported in between
+	SizeGatherInterval config.Duration `toml:"size_gather_interval"`
 
 	validMetrics map[string]struct{}
 	dsn          string
 	tls.ClientConfig
+
+	sizeGathered time.Time
+	sizeSamples  []sizeSample
+}
+
+type sizeSample struct {
+	metric string
+	value  interface{}
+	labels map[string]string
 }
 
 func (ins *Instance) Init() error {
@@ -193,7 +217,7 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 	db, err := sql.Open("mysql", ins.dsn)
 	if err != nil {
 		slist.PushSample(inputName, "up", 0, tags)
-		log.Println("E! failed to open mysql:", err)
+		mysqlLog.Errorf("failed to open mysql: %v", err)
 		return
 	}
 
@@ -205,7 +229,7 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 
 	if err = db.Ping(); err != nil {
 		slist.PushSample(inputName, "up", 0, tags)
-		log.Println("E! failed to ping mysql:", err)
+		mysqlLog.Errorf("failed to ping mysql: %v", err)
 		return
 	}
 
@@ -220,9 +244,38 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 	ins.gatherBinlog(slist, db, tags)
 	ins.gatherProcesslistByState(slist, db, tags)
 	ins.gatherProcesslistByUser(slist, db, tags)
-	ins.gatherSchemaSize(slist, db, tags)
-	ins.gatherTableSize(slist, db, tags, false)
-	ins.gatherTableSize(slist, db, tags, true)
+	ins.gatherSizes(slist, db, tags)
 	ins.gatherSlaveStatus(slist, db, tags)
+	ins.gatherHeartbeat(slist, db, tags)
+	ins.gatherGroupReplication(slist, db, tags)
+	ins.gatherInnodbMetrics(slist, db, tags)
 	ins.gatherCustomQueries(slist, db, tags)
 }
+
+// gatherSizes gathers the schema and table sizes, which are expensive on instances with many tables
+func (ins *Instance) gatherSizes(slist *types.SampleList, db *sql.DB, tags map[string]string) {
+	if ins.SizeGatherInterval <= 0 {
+		ins.gatherSchemaSize(slist, db, tags)
+		ins.gatherTableSize(slist, db, tags, false)
+		ins.gatherTableSize(slist, db, tags, true)
+		return
+	}
+
+	if time.Since(ins.sizeGathered) >= time.Duration(ins.SizeGatherInterval) {
+		sizes := types.NewSampleList()
+		ins.gatherSchemaSize(sizes, db, tags)
+		ins.gatherTableSize(sizes, db, tags, false)
+		ins.gatherTableSize(sizes, db, tags, true)
+
+		ins.sizeSamples = ins.sizeSamples[:0]
+		sizes.Range(func(s *types.Sample) bool {
+			ins.sizeSamples = append(ins.sizeSamples, sizeSample{metric: s.Metric, value: s.Value, labels: s.Labels})
+			return true
+		})
+		ins.sizeGathered = time.Now()
+	}
+
+	for _, s := range ins.sizeSamples {
+		slist.PushFront(types.NewSample("", s.metric, s.value, s.labels))
+	}
+}
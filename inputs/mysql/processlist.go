@@ -2,7 +2,6 @@ package mysql
 
 import (
 	"database/sql"
-	"log"
 	"strings"
 
 	"flashcat.cloud/categraf/pkg/tagx"
@@ -96,7 +95,7 @@ func (ins *Instance) gatherProcesslistByState(slist *types.SampleList, db *sql.D
 
 	rows, err := db.Query(SQL_INFO_SCHEMA_PROCESSLIST)
 	if err != nil {
-		log.Println("E! failed to get processlist:", err)
+		mysqlLog.Errorf("failed to get processlist: %v", err)
 		return
 	}
 
@@ -120,7 +119,7 @@ func (ins *Instance) gatherProcesslistByState(slist *types.SampleList, db *sql.D
 
 		err = rows.Scan(&command, &state, &count)
 		if err != nil {
-			log.Println("W! failed to scan rows:", err)
+			mysqlLog.Warnf("failed to scan rows: %v", err)
 			return
 		}
 		// each state has its mapping
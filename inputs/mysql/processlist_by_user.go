@@ -2,7 +2,6 @@ package mysql
 
 import (
 	"database/sql"
-	"log"
 
 	"flashcat.cloud/categraf/pkg/tagx"
 	"flashcat.cloud/categraf/types"
@@ -15,7 +14,7 @@ func (ins *Instance) gatherProcesslistByUser(slist *types.SampleList, db *sql.DB
 
 	rows, err := db.Query(SQL_INFO_SCHEMA_PROCESSLIST_BY_USER)
 	if err != nil {
-		log.Println("E! failed to get processlist:", err)
+		mysqlLog.Errorf("failed to get processlist: %v", err)
 		return
 	}
 
@@ -29,7 +28,7 @@ func (ins *Instance) gatherProcesslistByUser(slist *types.SampleList, db *sql.DB
 
 		err = rows.Scan(&user, &connections)
 		if err != nil {
-			log.Println("E! failed to scan rows:", err)
+			mysqlLog.Errorf("failed to scan rows: %v", err)
 			return
 		}
 
@@ -87,4 +87,16 @@ WHERE channel_name IN ('group_replication_applier', 'group_replication_recovery'
 	SQL_GROUP_REPLICATION_PLUGIN_STATUS = `
 SELECT plugin_status
 FROM information_schema.plugins WHERE plugin_name='group_replication'`
+
+	SQL_GROUP_REPLICATION_MEMBERS = `
+SELECT member_id, member_host, member_port, member_state, IFNULL(member_role, '') AS member_role
+FROM performance_schema.replication_group_members`
+
+	SQL_INNODB_METRICS = `
+SELECT name, subsystem, count
+FROM information_schema.innodb_metrics
+WHERE status = 'enabled'`
+
+	// the heartbeat table of pt-heartbeat
+	SQL_HEARTBEAT = "SELECT UNIX_TIMESTAMP(ts), UNIX_TIMESTAMP(%s), server_id FROM `%s`.`%s`"
 )
@@ -2,7 +2,6 @@ package mysql
 
 import (
 	"database/sql"
-	"log"
 
 	"flashcat.cloud/categraf/pkg/tagx"
 	"flashcat.cloud/categraf/types"
@@ -15,7 +14,7 @@ func (ins *Instance) gatherSchemaSize(slist *types.SampleList, db *sql.DB, globa
 
 	rows, err := db.Query(SQL_QUERY_SCHEMA_SIZE)
 	if err != nil {
-		log.Println("E! failed to get schema size:", err)
+		mysqlLog.Errorf("failed to get schema size: %v", err)
 		return
 	}
 
@@ -29,7 +28,7 @@ func (ins *Instance) gatherSchemaSize(slist *types.SampleList, db *sql.DB, globa
 
 		err = rows.Scan(&schema, &size)
 		if err != nil {
-			log.Println("E! failed to scan rows:", err)
+			mysqlLog.Errorf("failed to scan rows: %v", err)
 			return
 		}
 
@@ -3,13 +3,17 @@ package mysql
 import (
 	"database/sql"
 	"fmt"
-	"log"
 	"strings"
 
 	"flashcat.cloud/categraf/types"
 )
 
-var slaveStatusQueries = [2]string{"SHOW ALL SLAVES STATUS", "SHOW SLAVE STATUS"}
+// SHOW REPLICA STATUS is available since MySQL 8.0.22, SHOW SLAVE STATUS is deprecated then
+var slaveStatusQueries = [3]string{"SHOW ALL SLAVES STATUS", "SHOW REPLICA STATUS", "SHOW SLAVE STATUS"}
+
+// replicaColumnReplacer renames the columns of SHOW REPLICA STATUS to the legacy names,
+// so the metrics keep the same names across versions, e.g. Seconds_Behind_Source -> Seconds_Behind_Master
+var replicaColumnReplacer = strings.NewReplacer("Source", "Master", "Replica", "Slave")
 var slaveStatusQuerySuffixes = [3]string{" NONBLOCKING", " NOLOCK", ""}
 
 func querySlaveStatus(db *sql.DB) (rows *sql.Rows, err error) {
@@ -37,12 +41,12 @@ func (ins *Instance) gatherSlaveStatus(slist *types.SampleList, db *sql.DB, glob
 
 	rows, err := querySlaveStatus(db)
 	if err != nil {
-		log.Println("E! failed to query slave status:", err)
+		mysqlLog.Errorf("failed to query slave status: %v", err)
 		return
 	}
 
 	if rows == nil {
-		log.Println("E! failed to query slave status: rows is nil")
+		mysqlLog.Errorf("failed to query slave status: rows is nil")
 		return
 	}
 
@@ -50,10 +54,14 @@ func (ins *Instance) gatherSlaveStatus(slist *types.SampleList, db *sql.DB, glob
 
 	slaveCols, err := rows.Columns()
 	if err != nil {
-		log.Println("E! failed to get columns of slave rows:", err)
+		mysqlLog.Errorf("failed to get columns of slave rows: %v", err)
 		return
 	}
 
+	for i := range slaveCols {
+		slaveCols[i] = replicaColumnReplacer.Replace(slaveCols[i])
+	}
+
 	for rows.Next() {
 		// As the number of columns varies with mysqld versions,
 		// and sql.Scan requires []interface{}, we need to create a
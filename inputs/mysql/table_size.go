@@ -2,7 +2,6 @@ package mysql
 
 import (
 	"database/sql"
-	"log"
 
 	"flashcat.cloud/categraf/pkg/tagx"
 	"flashcat.cloud/categraf/types"
@@ -23,7 +22,7 @@ func (ins *Instance) gatherTableSize(slist *types.SampleList, db *sql.DB, global
 
 	rows, err := db.Query(query)
 	if err != nil {
-		log.Println("E! failed to get table size:", err)
+		mysqlLog.Errorf("failed to get table size: %v", err)
 		return
 	}
 
@@ -37,7 +36,7 @@ func (ins *Instance) gatherTableSize(slist *types.SampleList, db *sql.DB, global
 
 		err = rows.Scan(&schema, &table, &indexSize, &dataSize)
 		if err != nil {
-			log.Println("E! failed to scan rows:", err)
+			mysqlLog.Errorf("failed to scan rows: %v", err)
 			return
 		}
 
@@ -0,0 +1,55 @@
+package mysql_slowlog
+
+import (
+	"hash/fnv"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	// the strings and the comments in one pass, so "#" in a string is not a comment and quotes in a comment are not a string
+	reStringOrComment = regexp.MustCompile(`'(?:[^'\\]|\\.|'')*'|"(?:[^"\\]|\\.|"")*"|(?s:/\*.*?\*/)|(?:--|#)[^\n]*`)
+	reHex             = regexp.MustCompile(`\b0x[0-9a-fA-F]+\b`)
+	reNumber          = regexp.MustCompile(`\b-?\d+(?:\.\d+)?(?:[eE][+-]?\d+)?\b`)
+	reInList          = regexp.MustCompile(`(?i)\bin\s*\(\s*\?(?:\s*,\s*\?)*\s*\)`)
+	reValues          = regexp.MustCompile(`(?i)\bvalues\s*\(.*\)`)
+	reWhitespace      = regexp.MustCompile(`\s+`)
+)
+
+// normalize replaces literals of the statement with ?, so statements differ only in
+// literals share the same digest, e.g. "SELECT * FROM t WHERE id IN (1, 2)" -> "select * from t where id in (?+)"
+func normalize(statement string) string {
+	s := reStringOrComment.ReplaceAllStringFunc(statement, func(m string) string {
+		if m[0] == '\'' || m[0] == '"' {
+			return "?"
+		}
+		return " "
+	})
+	s = reHex.ReplaceAllString(s, "?")
+	s = reNumber.ReplaceAllString(s, "?")
+	s = reInList.ReplaceAllString(s, "in (?+)")
+	s = reValues.ReplaceAllString(s, "values (?+)")
+	s = reWhitespace.ReplaceAllString(s, " ")
+	s = strings.TrimSpace(s)
+	s = strings.TrimRight(s, "; ")
+	return strings.ToLower(s)
+}
+
+func digest(normalized string) string {
+	h := fnv.New64a()
+	h.Write([]byte(normalized))
+	return strconv.FormatUint(h.Sum64(), 16)
+}
+
+// truncate keeps the label value short, digest is the identity of the statement
+func truncate(s string, max int) string {
+	if max <= 0 || len(s) <= max {
+		return s
+	}
+	for max > 0 && !utf8.RuneStart(s[max]) {
+		max--
+	}
+	return s[:max] + "..."
+}
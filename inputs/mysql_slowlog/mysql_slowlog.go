@@ -0,0 +1,321 @@
+package mysql_slowlog
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/pkg/tls"
+	"flashcat.cloud/categraf/types"
+	"github.com/go-sql-driver/mysql"
+)
+
+var mysqlSlowlogLog = logger.New("input.mysql_slowlog")
+
+const (
+	inputName = "mysql_slowlog"
+
+	sourceFile              = "file"
+	sourcePerformanceSchema = "performance_schema"
+
+	defaultTopN           = 100
+	defaultMaxDigests     = 1000
+	defaultDigestTextSize = 200
+
+	// digests beyond max_digests are accounted to this digest
+	otherDigest = "other"
+)
+
+const sqlDigestSummary = `
+SELECT IFNULL(SCHEMA_NAME, ''), DIGEST, IFNULL(DIGEST_TEXT, ''),
+	COUNT_STAR, SUM_TIMER_WAIT, MAX_TIMER_WAIT, SUM_LOCK_TIME, SUM_ERRORS, SUM_ROWS_SENT, SUM_ROWS_EXAMINED
+FROM performance_schema.events_statements_summary_by_digest
+WHERE DIGEST IS NOT NULL
+ORDER BY SUM_TIMER_WAIT DESC
+LIMIT ?`
+
+// timers of performance_schema are in picoseconds
+const picoseconds = 1e12
+
+type Instance struct {
+	config.InstanceConfig
+
+	// file: tail the slow query log, performance_schema: query events_statements_summary_by_digest
+	Source      string `toml:"source"`
+	SlowLogPath string `toml:"slow_log_path"`
+
+	Address        string `toml:"address"`
+	Username       string `toml:"username"`
+	Password       string `toml:"password"`
+	Parameters     string `toml:"parameters"`
+	TimeoutSeconds int64  `toml:"timeout_seconds"`
+	tls.ClientConfig
+
+	// digests with the most total query time to report
+	TopN int `toml:"top_n"`
+	// digests tracked from the slow query log, the others are accounted to digest "other"
+	MaxDigests     int `toml:"max_digests"`
+	DigestTextSize int `toml:"digest_text_size"`
+
+	dsn     string
+	reader  *slowLogReader
+	digests map[string]*digestStats
+}
+
+type digestStats struct {
+	schema       string
+	digest       string
+	text         string
+	calls        float64
+	queryTime    float64
+	lockTime     float64
+	rowsSent     float64
+	rowsExamined float64
+	errors       float64
+	// max query time since the last gather
+	maxQueryTime float64
+}
+
+func (ins *Instance) Init() error {
+	if ins.Source == "" {
+		ins.Source = sourceFile
+	}
+
+	if ins.TopN <= 0 {
+		ins.TopN = defaultTopN
+	}
+
+	if ins.MaxDigests <= 0 {
+		ins.MaxDigests = defaultMaxDigests
+	}
+
+	if ins.DigestTextSize <= 0 {
+		ins.DigestTextSize = defaultDigestTextSize
+	}
+
+	switch ins.Source {
+	case sourceFile:
+		if ins.SlowLogPath == "" {
+			return types.ErrInstancesEmpty
+		}
+		ins.reader = newSlowLogReader(ins.SlowLogPath)
+		ins.digests = make(map[string]*digestStats)
+		return nil
+	case sourcePerformanceSchema:
+		if ins.Address == "" {
+			return types.ErrInstancesEmpty
+		}
+		return ins.initDSN()
+	default:
+		return fmt.Errorf("unknown source: %s, must be file or performance_schema", ins.Source)
+	}
+}
+
+func (ins *Instance) initDSN() error {
+	if ins.UseTLS {
+		tlsConfig, err := ins.ClientConfig.TLSConfig()
+		if err != nil {
+			return fmt.Errorf("failed to register tls config: %v", err)
+		}
+
+		err = mysql.RegisterTLSConfig("custom", tlsConfig)
+		if err != nil {
+			return fmt.Errorf("failed to register tls config: %v", err)
+		}
+	}
+
+	net := "tcp"
+	if strings.HasSuffix(ins.Address, ".sock") {
+		net = "unix"
+	}
+
+	conf, err := mysql.ParseDSN(fmt.Sprintf("%s:%s@%s(%s)/?%s", ins.Username, ins.Password, net, ins.Address, ins.Parameters))
+	if err != nil {
+		return err
+	}
+	if conf.Timeout == 0 {
+		if ins.TimeoutSeconds == 0 {
+			ins.TimeoutSeconds = 3
+		}
+		conf.Timeout = time.Second * time.Duration(ins.TimeoutSeconds)
+	}
+
+	ins.dsn = conf.FormatDSN()
+	return nil
+}
+
+type MySQLSlowLog struct {
+	config.PluginConfig
+	Instances []*Instance `toml:"instances"`
+}
+
+func init() {
+	inputs.Add(inputName, func() inputs.Input {
+		return &MySQLSlowLog{}
+	})
+}
+
+func (m *MySQLSlowLog) Clone() inputs.Input {
+	return &MySQLSlowLog{}
+}
+
+func (m *MySQLSlowLog) Name() string {
+	return inputName
+}
+
+func (m *MySQLSlowLog) GetInstances() []inputs.Instance {
+	ret := make([]inputs.Instance, len(m.Instances))
+	for i := 0; i < len(m.Instances); i++ {
+		ret[i] = m.Instances[i]
+	}
+	return ret
+}
+
+func (ins *Instance) Gather(slist *types.SampleList) {
+	if ins.Source == sourcePerformanceSchema {
+		ins.gatherPerformanceSchema(slist)
+		return
+	}
+	ins.gatherSlowLog(slist)
+}
+
+func (ins *Instance) gatherSlowLog(slist *types.SampleList) {
+	tags := map[string]string{"path": ins.SlowLogPath}
+
+	err := ins.reader.read(ins.observe)
+	if err != nil {
+		slist.PushSample(inputName, "up", 0, tags)
+		mysqlSlowlogLog.Errorf("failed to read mysql slow query log: %v", err)
+		return
+	}
+	slist.PushSample(inputName, "up", 1, tags)
+
+	stats := make([]*digestStats, 0, len(ins.digests))
+	for _, s := range ins.digests {
+		stats = append(stats, s)
+	}
+	ins.pushDigests(slist, tags, stats, false)
+
+	for _, s := range ins.digests {
+		s.maxQueryTime = 0
+	}
+}
+
+func (ins *Instance) observe(e *entry) {
+	text := normalize(e.statement)
+	if text == "" {
+		return
+	}
+
+	d := digest(text)
+	key := e.schema + "\x00" + d
+	s, has := ins.digests[key]
+	if !has {
+		if len(ins.digests) >= ins.MaxDigests {
+			key = otherDigest
+			d, text = otherDigest, otherDigest
+			s, has = ins.digests[key]
+		}
+		if !has {
+			s = &digestStats{schema: e.schema, digest: d, text: truncate(text, ins.DigestTextSize)}
+			if key == otherDigest {
+				s.schema = ""
+			}
+			ins.digests[key] = s
+		}
+	}
+
+	s.calls++
+	s.queryTime += e.queryTime
+	s.lockTime += e.lockTime
+	s.rowsSent += e.rowsSent
+	s.rowsExamined += e.rowsExamined
+	if e.queryTime > s.maxQueryTime {
+		s.maxQueryTime = e.queryTime
+	}
+}
+
+func (ins *Instance) gatherPerformanceSchema(slist *types.SampleList) {
+	tags := map[string]string{"address": ins.Address}
+
+	db, err := sql.Open("mysql", ins.dsn)
+	if err != nil {
+		slist.PushSample(inputName, "up", 0, tags)
+		mysqlSlowlogLog.Errorf("failed to open mysql: %v", err)
+		return
+	}
+
+	defer db.Close()
+
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+	db.SetConnMaxLifetime(time.Minute)
+
+	rows, err := db.Query(sqlDigestSummary, ins.TopN)
+	if err != nil {
+		slist.PushSample(inputName, "up", 0, tags)
+		mysqlSlowlogLog.Errorf("failed to query events_statements_summary_by_digest: %v", err)
+		return
+	}
+
+	defer rows.Close()
+
+	var stats []*digestStats
+	for rows.Next() {
+		var s digestStats
+		var sumTimer, maxTimer, lockTime float64
+		if err := rows.Scan(&s.schema, &s.digest, &s.text, &s.calls, &sumTimer, &maxTimer, &lockTime, &s.errors, &s.rowsSent, &s.rowsExamined); err != nil {
+			mysqlSlowlogLog.Errorf("failed to scan events_statements_summary_by_digest: %v", err)
+			continue
+		}
+		s.queryTime = sumTimer / picoseconds
+		s.maxQueryTime = maxTimer / picoseconds
+		s.lockTime = lockTime / picoseconds
+		s.text = truncate(s.text, ins.DigestTextSize)
+		stats = append(stats, &s)
+	}
+
+	if err := rows.Err(); err != nil {
+		slist.PushSample(inputName, "up", 0, tags)
+		mysqlSlowlogLog.Errorf("failed to read events_statements_summary_by_digest: %v", err)
+		return
+	}
+
+	slist.PushSample(inputName, "up", 1, tags)
+	ins.pushDigests(slist, tags, stats, true)
+}
+
+func (ins *Instance) pushDigests(slist *types.SampleList, tags map[string]string, stats []*digestStats, withErrors bool) {
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].queryTime > stats[j].queryTime
+	})
+	if len(stats) > ins.TopN {
+		stats = stats[:ins.TopN]
+	}
+
+	for _, s := range stats {
+		labels := map[string]string{
+			"schema":      s.schema,
+			"digest":      s.digest,
+			"digest_text": s.text,
+		}
+
+		fields := map[string]interface{}{
+			"calls_total":              s.calls,
+			"query_time_seconds_total": s.queryTime,
+			"query_time_seconds_max":   s.maxQueryTime,
+			"lock_time_seconds_total":  s.lockTime,
+			"rows_sent_total":          s.rowsSent,
+			"rows_examined_total":      s.rowsExamined,
+		}
+		if withErrors {
+			fields["errors_total"] = s.errors
+		}
+		slist.PushSamples(inputName, fields, tags, labels)
+	}
+}
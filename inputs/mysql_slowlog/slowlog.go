@@ -0,0 +1,153 @@
+package mysql_slowlog
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// entry is one statement of the slow query log
+type entry struct {
+	schema       string
+	statement    string
+	queryTime    float64
+	lockTime     float64
+	rowsSent     float64
+	rowsExamined float64
+}
+
+// slowLogReader reads the entries appended to the slow query log since the last read,
+// the log is read from the end when opened the first time, and from the beginning after rotation or truncation
+type slowLogReader struct {
+	path   string
+	info   os.FileInfo
+	offset int64
+
+	// parser state, entries may be split across reads
+	schema    string
+	inEntry   bool
+	current   entry
+	statement []string
+}
+
+func newSlowLogReader(path string) *slowLogReader {
+	return &slowLogReader{path: path}
+}
+
+func (r *slowLogReader) read(fn func(e *entry)) error {
+	f, err := os.Open(r.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case r.info == nil:
+		r.offset = info.Size()
+	case !os.SameFile(r.info, info) || info.Size() < r.offset:
+		r.offset = 0
+		r.reset()
+	}
+	r.info = info
+
+	if _, err = f.Seek(r.offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	reader := bufio.NewReaderSize(f, 64*1024)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			// the last line is incomplete, read it again next time
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		r.offset += int64(len(line))
+		r.parseLine(strings.TrimRight(line, "\r\n"), fn)
+	}
+}
+
+func (r *slowLogReader) reset() {
+	r.inEntry = false
+	r.current = entry{}
+	r.statement = r.statement[:0]
+}
+
+func (r *slowLogReader) parseLine(line string, fn func(e *entry)) {
+	if strings.HasPrefix(line, "# ") {
+		// a new entry starts with header lines, flush the statement not terminated by ;
+		if r.inEntry && len(r.statement) > 0 {
+			r.flush(fn)
+		}
+		if strings.HasPrefix(line, "# Query_time:") {
+			r.current = parseStats(line)
+			r.inEntry = true
+		}
+		return
+	}
+
+	if !r.inEntry {
+		// server startup lines, e.g. "Tcp port: 3306  Unix socket: ..."
+		return
+	}
+
+	trimmed := strings.TrimSpace(line)
+	if len(r.statement) == 0 {
+		lower := strings.ToLower(trimmed)
+		if strings.HasPrefix(lower, "use ") && strings.HasSuffix(lower, ";") {
+			r.schema = strings.Trim(strings.TrimSpace(trimmed[4:len(trimmed)-1]), "`")
+			return
+		}
+		if strings.HasPrefix(lower, "set timestamp=") {
+			return
+		}
+		if trimmed == "" {
+			return
+		}
+	}
+
+	r.statement = append(r.statement, line)
+	if strings.HasSuffix(trimmed, ";") {
+		r.flush(fn)
+	}
+}
+
+func (r *slowLogReader) flush(fn func(e *entry)) {
+	r.current.schema = r.schema
+	r.current.statement = strings.Join(r.statement, "\n")
+	fn(&r.current)
+	r.reset()
+}
+
+// parseStats parses the line like:
+// # Query_time: 2.000205  Lock_time: 0.000000 Rows_sent: 1  Rows_examined: 0
+func parseStats(line string) entry {
+	var e entry
+	fields := strings.Fields(strings.TrimPrefix(line, "#"))
+	for i := 0; i+1 < len(fields); i += 2 {
+		v, err := strconv.ParseFloat(fields[i+1], 64)
+		if err != nil {
+			continue
+		}
+		switch fields[i] {
+		case "Query_time:":
+			e.queryTime = v
+		case "Lock_time:":
+			e.lockTime = v
+		case "Rows_sent:":
+			e.rowsSent = v
+		case "Rows_examined:":
+			e.rowsExamined = v
+		}
+	}
+	return e
+}
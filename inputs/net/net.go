@@ -2,16 +2,18 @@ package net
 
 import (
 	"fmt"
-	"log"
 	"net"
 
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
 	"flashcat.cloud/categraf/inputs/system"
 	"flashcat.cloud/categraf/pkg/filter"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/types"
 )
 
+var netLog = logger.New("input.net")
+
 const inputName = "net"
 
 type NetIOStats struct {
@@ -59,13 +61,13 @@ func (s *NetIOStats) Init() error {
 func (s *NetIOStats) Gather(slist *types.SampleList) {
 	netio, err := s.ps.NetIO()
 	if err != nil {
-		log.Println("E! failed to get net io metrics:", err)
+		netLog.Errorf("failed to get net io metrics: %v", err)
 		return
 	}
 
 	interfaces, err := net.Interfaces()
 	if err != nil {
-		log.Println("E! failed to list interfaces:", err)
+		netLog.Errorf("failed to list interfaces: %v", err)
 		return
 	}
 
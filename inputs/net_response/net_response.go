@@ -4,7 +4,6 @@ import (
 	"bufio"
 	"errors"
 	"fmt"
-	"log"
 	"net"
 	"net/textproto"
 	"strings"
@@ -13,9 +12,12 @@ import (
 
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/types"
 )
 
+var netResponseLog = logger.New("input.net_response")
+
 const (
 	inputName = "net_response"
 
@@ -127,7 +129,7 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 
 func (ins *Instance) gather(slist *types.SampleList, target string) {
 	if config.Config.DebugMode {
-		log.Println("D! net_response... target:", target)
+		netResponseLog.Debugf("net_response... target: %v", target)
 	}
 
 	labels := map[string]string{"target": target}
@@ -146,19 +148,19 @@ func (ins *Instance) gather(slist *types.SampleList, target string) {
 	case "tcp":
 		returnTags, fields, err = ins.TCPGather(target)
 		if err != nil {
-			log.Println("E! failed to gather:", target, "error:", err)
+			netResponseLog.Errorf("failed to gather: %v error: %v", target, err)
 			return
 		}
 		labels["protocol"] = "tcp"
 	case "udp":
 		returnTags, fields, err = ins.UDPGather(target)
 		if err != nil {
-			log.Println("E! failed to gather:", target, "error:", err)
+			netResponseLog.Errorf("failed to gather: %v error: %v", target, err)
 			return
 		}
 		labels["protocol"] = "udp"
 	default:
-		log.Println("E! bad protocol, target:", target)
+		netResponseLog.Errorf("bad protocol, target: %v", target)
 	}
 
 	for k, v := range returnTags {
@@ -212,7 +214,7 @@ func (ins *Instance) TCPGather(address string) (map[string]string, map[string]in
 		responseTime = time.Since(start).Seconds()
 		// Handle error
 		if err != nil {
-			log.Printf("E! read tcp failed, address: %s, error: %s", address, err)
+			netResponseLog.Errorf("read tcp failed, address: %s, error: %s", address, err)
 			fields["result_code"] = ReadFailed
 		} else {
 			if strings.Contains(data, ins.Expect) {
@@ -273,7 +275,7 @@ func (ins *Instance) UDPGather(address string) (map[string]string, map[string]in
 	responseTime := time.Since(start).Seconds()
 	// Handle error
 	if err != nil {
-		log.Printf("E! read udp failed, address: %s, error: %s", address, err)
+		netResponseLog.Errorf("read udp failed, address: %s, error: %s", address, err)
 		fields["result_code"] = ReadFailed
 		// Error encoded in result
 		//nolint:nilerr
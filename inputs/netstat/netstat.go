@@ -5,7 +5,6 @@ import (
 	"bytes"
 	"io"
 	"io/ioutil"
-	"log"
 	"os"
 	"path"
 	"runtime"
@@ -18,9 +17,12 @@ import (
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
 	"flashcat.cloud/categraf/inputs/system"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/types"
 )
 
+var netstatLog = logger.New("input.netstat")
+
 const inputName = "netstat"
 
 type NetStats struct {
@@ -53,8 +55,8 @@ func (s *NetStats) Name() string {
 }
 
 func (s *NetStats) gatherSummary(slist *types.SampleList) {
-	if runtime.GOOS != "linux" {
-		log.Println("W! netstat_summary is only supported on linux")
+	if runtime.GOOS != "linux" && runtime.GOOS != "android" {
+		netstatLog.Warnf("netstat_summary is only supported on linux")
 		return
 	}
 	if s.DisableSummaryStats {
@@ -68,7 +70,7 @@ func (s *NetStats) gatherSummary(slist *types.SampleList) {
 	}
 	bs, err := ioutil.ReadFile(f)
 	if err != nil {
-		log.Println("E! failed to read sockstat", f, err)
+		netstatLog.Errorf("failed to read sockstat %v %v", f, err)
 		return
 	}
 	reader := bufio.NewReader(bytes.NewBuffer(bs))
@@ -92,13 +94,21 @@ func (s *NetStats) gatherSummary(slist *types.SampleList) {
 		for i := 0; i < len(kvs); i += 2 {
 			val, err := strconv.ParseUint(kvs[i+1], 10, 64)
 			if err != nil {
-				log.Println("W! parse:", f, "line:", line, "field:", kvs[i+1], "failed:", err)
+				netstatLog.Warnf("parse: %v line: %v field: %v failed: %v", f, line, kvs[i+1], err)
 			}
 			slist.PushSample(inputName+"_"+metric, strings.ToLower(kvs[i]), val, tags)
 		}
 	}
 }
 
+// the tcp states of bsd and windows, which are named differently from those of linux
+var bsdTCPStates = map[string]string{
+	"SYN_RCVD":   "SYN_RECV",
+	"FIN_WAIT_1": "FIN_WAIT1",
+	"FIN_WAIT_2": "FIN_WAIT2",
+	"CLOSED":     "CLOSE",
+}
+
 func (s *NetStats) Gather(slist *types.SampleList) {
 	s.gatherExt(slist)
 
@@ -109,7 +119,7 @@ func (s *NetStats) Gather(slist *types.SampleList) {
 	}
 	netconns, err := s.ps.NetConnections()
 	if err != nil {
-		log.Println("E! failed to get net connections:", err)
+		netstatLog.Errorf("failed to get net connections: %v", err)
 		return
 	}
 
@@ -123,11 +133,11 @@ func (s *NetStats) Gather(slist *types.SampleList) {
 			counts["UDP"]++
 			continue // UDP has no status
 		}
-		c, ok := counts[netcon.Status]
-		if !ok {
-			counts[netcon.Status] = 0
+		status := netcon.Status
+		if name, ok := bsdTCPStates[status]; ok {
+			status = name
 		}
-		counts[netcon.Status] = c + 1
+		counts[status]++
 	}
 
 	fields := map[string]interface{}{
@@ -160,7 +170,7 @@ func (s *NetStats) gatherExt(slist *types.SampleList) {
 		return
 	}
 	if err != nil {
-		log.Println("E! failed to get ext metrics:", err)
+		netstatLog.Errorf("failed to get ext metrics: %v", err)
 		return
 	}
 
@@ -2,15 +2,17 @@ package netstat
 
 import (
 	"fmt"
-	"log"
 	"syscall"
 
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
 	"flashcat.cloud/categraf/inputs/system"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/types"
 )
 
+var netstatLog = logger.New("input.netstat_filter")
+
 const inputName = "netstat_filter"
 
 type NetStatFilter struct {
@@ -63,7 +65,7 @@ func (ins *Instance) Init() error {
 func (ins *Instance) Gather(slist *types.SampleList) {
 	netconns, err := ins.ps.NetConnections()
 	if err != nil {
-		log.Println("E! failed to get net connections:", err)
+		netstatLog.Errorf("failed to get net connections: %v", err)
 		return
 	}
 
@@ -0,0 +1,305 @@
+package nexus
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/httpx"
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/pkg/tls"
+	"flashcat.cloud/categraf/types"
+)
+
+var nexusLog = logger.New("input.nexus")
+
+const (
+	inputName = "nexus"
+
+	statusPath         = "/service/rest/v1/status"
+	statusWritablePath = "/service/rest/v1/status/writable"
+	statusCheckPath    = "/service/rest/v1/status/check"
+	blobStoresPath     = "/service/rest/v1/blobstores"
+	tasksPath          = "/service/rest/v1/tasks"
+	metricsDataPath    = "/service/metrics/data"
+
+	// the requests of jetty are timed by method, e.g. org.eclipse.jetty.webapp.WebAppContext.get-requests
+	jettyPrefix   = "org.eclipse.jetty.webapp.WebAppContext."
+	requestSuffix = "-requests"
+)
+
+type Nexus struct {
+	config.PluginConfig
+	Instances []*Instance `toml:"instances"`
+}
+
+type Instance struct {
+	config.InstanceConfig
+
+	URL string `toml:"url"`
+	// the status checks, blob stores, tasks and request metrics require nx-metrics-all and nx-blobstores-read
+	// or nx-tasks-read privileges, only available and writable are gathered without credentials
+	Username string          `toml:"username"`
+	Password string          `toml:"password"`
+	Timeout  config.Duration `toml:"timeout"`
+	tls.ClientConfig
+
+	// status | blobstores | tasks | requests
+	Collect []string `toml:"collect"`
+
+	client  *http.Client
+	collect map[string]bool
+}
+
+type blobStore struct {
+	Name                  string  `json:"name"`
+	Type                  string  `json:"type"`
+	BlobCount             float64 `json:"blobCount"`
+	TotalSizeInBytes      float64 `json:"totalSizeInBytes"`
+	AvailableSpaceInBytes float64 `json:"availableSpaceInBytes"`
+}
+
+type task struct {
+	Name          string `json:"name"`
+	Type          string `json:"type"`
+	CurrentState  string `json:"currentState"`
+	LastRunResult string `json:"lastRunResult"`
+	LastRun       string `json:"lastRun"`
+}
+
+var _ inputs.SampleGatherer = new(Instance)
+var _ inputs.Input = new(Nexus)
+var _ inputs.InstancesGetter = new(Nexus)
+
+func init() {
+	inputs.Add(inputName, func() inputs.Input {
+		return &Nexus{}
+	})
+}
+
+func (n *Nexus) Clone() inputs.Input {
+	return &Nexus{}
+}
+
+func (n *Nexus) Name() string {
+	return inputName
+}
+
+func (n *Nexus) GetInstances() []inputs.Instance {
+	ret := make([]inputs.Instance, len(n.Instances))
+	for i := 0; i < len(n.Instances); i++ {
+		ret[i] = n.Instances[i]
+	}
+	return ret
+}
+
+func (ins *Instance) Init() error {
+	if ins.URL == "" {
+		return types.ErrInstancesEmpty
+	}
+	if ins.Timeout <= 0 {
+		ins.Timeout = config.Duration(5 * time.Second)
+	}
+	if len(ins.Collect) == 0 {
+		ins.Collect = []string{"status", "blobstores", "tasks", "requests"}
+	}
+	ins.URL = strings.TrimRight(ins.URL, "/")
+
+	ins.collect = make(map[string]bool, len(ins.Collect))
+	for _, c := range ins.Collect {
+		switch c {
+		case "status", "blobstores", "tasks", "requests":
+			ins.collect[c] = true
+		default:
+			return fmt.Errorf("invalid collect %s, status, blobstores, tasks or requests", c)
+		}
+	}
+
+	tlsConfig, err := ins.ClientConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+	ins.client = &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		},
+		Timeout: time.Duration(ins.Timeout),
+	}
+	return nil
+}
+
+func (ins *Instance) Gather(slist *types.SampleList) {
+	// 200 if the node can serve read requests, 503 otherwise
+	code, err := ins.get(statusPath, nil, nil)
+	if err != nil {
+		nexusLog.Errorf("failed to request nexus: %v error: %v", ins.URL, err)
+		slist.PushSample(inputName, "up", 0)
+		return
+	}
+	slist.PushSample(inputName, "up", 1)
+	slist.PushSample(inputName, "available", code == http.StatusOK)
+
+	if code, err = ins.get(statusWritablePath, nil, nil); err == nil {
+		slist.PushSample(inputName, "writable", code == http.StatusOK)
+	}
+
+	if ins.collect["status"] {
+		ins.gatherStatusChecks(slist)
+	}
+	if ins.collect["blobstores"] {
+		ins.gatherBlobStores(slist)
+	}
+	if ins.collect["tasks"] {
+		ins.gatherTasks(slist)
+	}
+	if ins.collect["requests"] {
+		ins.gatherRequests(slist)
+	}
+}
+
+func (ins *Instance) gatherStatusChecks(slist *types.SampleList) {
+	var checks map[string]struct {
+		Healthy bool   `json:"healthy"`
+		Message string `json:"message"`
+	}
+	if _, err := ins.get(statusCheckPath, nil, &checks); err != nil {
+		nexusLog.Errorf("failed to get status checks of nexus: %v error: %v", ins.URL, err)
+		return
+	}
+
+	for name, c := range checks {
+		slist.PushSample(inputName, "status_check", c.Healthy, map[string]string{"check": name})
+	}
+}
+
+func (ins *Instance) gatherBlobStores(slist *types.SampleList) {
+	var stores []blobStore
+	if _, err := ins.get(blobStoresPath, nil, &stores); err != nil {
+		nexusLog.Errorf("failed to list blob stores of nexus: %v error: %v", ins.URL, err)
+		return
+	}
+
+	for _, s := range stores {
+		tags := map[string]string{"blobstore": s.Name, "type": s.Type}
+		fields := map[string]interface{}{
+			"blobstore_blobs":      s.BlobCount,
+			"blobstore_size_bytes": s.TotalSizeInBytes,
+		}
+		// the available space of s3 blob stores is unlimited, and reported as a huge number
+		if strings.EqualFold(s.Type, "file") {
+			fields["blobstore_available_bytes"] = s.AvailableSpaceInBytes
+		}
+		slist.PushSamples(inputName, fields, tags)
+	}
+}
+
+// gatherTasks reports the result of the last runs of tasks, e.g. the compaction of blob stores,
+// which is the garbage collection of deleted blobs
+func (ins *Instance) gatherTasks(slist *types.SampleList) {
+	var tasks []task
+	for token := ""; ; {
+		var page struct {
+			Items             []task `json:"items"`
+			ContinuationToken string `json:"continuationToken"`
+		}
+
+		var query url.Values
+		if token != "" {
+			query = url.Values{"continuationToken": {token}}
+		}
+		if _, err := ins.get(tasksPath, query, &page); err != nil {
+			nexusLog.Errorf("failed to list tasks of nexus: %v error: %v", ins.URL, err)
+			return
+		}
+
+		tasks = append(tasks, page.Items...)
+		if page.ContinuationToken == "" || page.ContinuationToken == token {
+			break
+		}
+		token = page.ContinuationToken
+	}
+
+	for _, t := range tasks {
+		tags := map[string]string{"task": t.Name, "type": t.Type}
+		fields := map[string]interface{}{
+			"task_running": t.CurrentState == "RUNNING",
+		}
+
+		if t.LastRunResult != "" {
+			fields["task_last_run_ok"] = t.LastRunResult == "OK"
+		}
+		if lastRun, err := time.Parse(time.RFC3339, t.LastRun); err == nil {
+			fields["task_last_run_timestamp"] = lastRun.Unix()
+		}
+		slist.PushSamples(inputName, fields, tags)
+	}
+}
+
+// gatherRequests reports the requests served by method, the rate of get and put requests
+// is the rate of pulls and pushes of the repositories
+func (ins *Instance) gatherRequests(slist *types.SampleList) {
+	var data struct {
+		Timers map[string]struct {
+			Count float64 `json:"count"`
+		} `json:"timers"`
+		Meters map[string]struct {
+			Count float64 `json:"count"`
+		} `json:"meters"`
+	}
+	if _, err := ins.get(metricsDataPath, nil, &data); err != nil {
+		nexusLog.Errorf("failed to get metrics data of nexus: %v error: %v", ins.URL, err)
+		return
+	}
+
+	for name, t := range data.Timers {
+		if !strings.HasPrefix(name, jettyPrefix) || !strings.HasSuffix(name, requestSuffix) {
+			continue
+		}
+		method := strings.TrimSuffix(strings.TrimPrefix(name, jettyPrefix), requestSuffix)
+		// requests of all methods
+		if method == "" || strings.Contains(method, ".") {
+			continue
+		}
+		slist.PushSample(inputName, "requests_total", t.Count, map[string]string{"method": method})
+	}
+
+	for name, m := range data.Meters {
+		if !strings.HasPrefix(name, jettyPrefix) || !strings.HasSuffix(name, "-responses") {
+			continue
+		}
+		code := strings.TrimSuffix(strings.TrimPrefix(name, jettyPrefix), "-responses")
+		slist.PushSample(inputName, "responses_total", m.Count, map[string]string{"code": code})
+	}
+}
+
+// get decodes the json response of path into v if it's not nil, 503 of the status apis is not an error
+func (ins *Instance) get(path string, query url.Values, v interface{}) (int, error) {
+	u := ins.URL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return 0, err
+	}
+	if ins.Username != "" || ins.Password != "" {
+		req.SetBasicAuth(ins.Username, ins.Password)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	var accepted []int
+	if v == nil {
+		accepted = append(accepted, http.StatusServiceUnavailable)
+	}
+	resp, err := httpx.DoJSON(ins.client, req, path, v, accepted...)
+	if resp == nil {
+		return 0, err
+	}
+	return resp.StatusCode, err
+}
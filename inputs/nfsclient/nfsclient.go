@@ -3,7 +3,6 @@ package nfsclient
 import (
 	"bufio"
 	"fmt"
-	"log"
 	"os"
 	"regexp"
 	"strconv"
@@ -11,9 +10,12 @@ import (
 
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/types"
 )
 
+var nfsclientLog = logger.New("input.nfsclient")
+
 const inputName = "nfsclient"
 
 type NfsClient struct {
@@ -178,41 +180,41 @@ func (s *NfsClient) Init() error {
 
 	if len(s.IncludeMounts) > 0 {
 		if config.Config.DebugMode {
-			log.Println("D! Including these mount patterns:", s.IncludeMounts)
+			nfsclientLog.Debugf("Including these mount patterns: %v", s.IncludeMounts)
 		}
 	} else {
 		if config.Config.DebugMode {
-			log.Println("D! Including all mounts.")
+			nfsclientLog.Debugf("Including all mounts.")
 		}
 	}
 
 	if len(s.ExcludeMounts) > 0 {
 		if config.Config.DebugMode {
-			log.Println("D! Excluding these mount patterns:", s.ExcludeMounts)
+			nfsclientLog.Debugf("Excluding these mount patterns: %v", s.ExcludeMounts)
 		}
 	} else {
 		if config.Config.DebugMode {
-			log.Println("D! Not excluding any mounts.")
+			nfsclientLog.Debugf("Not excluding any mounts.")
 		}
 	}
 
 	if len(s.IncludeOperations) > 0 {
 		if config.Config.DebugMode {
-			log.Println("D! Including these operations:", s.IncludeOperations)
+			nfsclientLog.Debugf("Including these operations: %v", s.IncludeOperations)
 		}
 	} else {
 		if config.Config.DebugMode {
-			log.Println("D! Including all operations.")
+			nfsclientLog.Debugf("Including all operations.")
 		}
 	}
 
 	if len(s.ExcludeOperations) > 0 {
 		if config.Config.DebugMode {
-			log.Println("D! Excluding these mount patterns:", s.ExcludeOperations)
+			nfsclientLog.Debugf("Excluding these mount patterns: %v", s.ExcludeOperations)
 		}
 	} else {
 		if config.Config.DebugMode {
-			log.Println("D! Not excluding any operations.")
+			nfsclientLog.Debugf("Not excluding any operations.")
 		}
 	}
 
@@ -223,7 +225,7 @@ func (s *NfsClient) Gather(slist *types.SampleList) {
 	file, err := os.Open(s.mountstatsPath)
 	if err != nil {
 		if config.Config.DebugMode {
-			log.Println("D! Failed opening the", file, "file:", err)
+			nfsclientLog.Debugf("Failed opening the %v file: %v", file, err)
 		}
 		return
 	}
@@ -235,7 +237,7 @@ func (s *NfsClient) Gather(slist *types.SampleList) {
 	}
 
 	if err := scanner.Err(); err != nil {
-		log.Println("E!", err)
+		nfsclientLog.Errorf("%v", err)
 	}
 }
 
@@ -279,7 +281,7 @@ func (s *NfsClient) parseStat(mountpoint string, export string, version string,
 	}
 
 	if len(nline) == 0 {
-		log.Println("W! Parsing Stat line with one field:", line)
+		nfsclientLog.Warnf("Parsing Stat line with one field: %v", line)
 		return nil
 	}
 
@@ -495,7 +497,7 @@ func (s *NfsClient) getMountStatsPath() string {
 		path = os.Getenv("MOUNT_PROC")
 	}
 	if config.Config.DebugMode {
-		log.Println("D! using [", path, "] for mountstats")
+		nfsclientLog.Debugf("using [ %v ] for mountstats", path)
 	}
 	return path
 }
@@ -4,7 +4,6 @@ import (
 	"bufio"
 	"fmt"
 	"io"
-	"log"
 	"net"
 	"net/http"
 	"net/url"
@@ -15,10 +14,13 @@ import (
 
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/pkg/tls"
 	"flashcat.cloud/categraf/types"
 )
 
+var nginxLog = logger.New("input.nginx")
+
 const inputName = "nginx"
 
 type Nginx struct {
@@ -103,14 +105,14 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 	for _, u := range ins.Urls {
 		addr, err := url.Parse(u)
 		if err != nil {
-			log.Println("E! failed to parse the url:", u, "error:", err)
+			nginxLog.Errorf("failed to parse the url: %v error: %v", u, err)
 			continue
 		}
 		wg.Add(1)
 		go func(addr *url.URL) {
 			defer wg.Done()
 			if err := ins.gather(addr, slist); err != nil {
-				log.Println("E!", err)
+				nginxLog.Errorf("%v", err)
 			}
 		}(addr)
 	}
@@ -147,7 +149,7 @@ func (ins *Instance) createHTTPClient() (*http.Client, error) {
 
 func (ins *Instance) gather(addr *url.URL, slist *types.SampleList) error {
 	if config.Config.DebugMode {
-		log.Println("D! nginx... url:", addr)
+		nginxLog.Debugf("nginx... url: %v", addr)
 	}
 
 	var body io.Reader
@@ -175,7 +177,7 @@ func (ins *Instance) gather(addr *url.URL, slist *types.SampleList) error {
 	defer func(Body io.ReadCloser) {
 		err := Body.Close()
 		if err != nil {
-			log.Println("E! failed to close the body of client:", err)
+			nginxLog.Errorf("failed to close the body of client: %v", err)
 		}
 	}(resp.Body)
 
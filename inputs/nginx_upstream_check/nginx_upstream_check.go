@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net"
 	"net/http"
 	"net/url"
@@ -14,11 +13,14 @@ import (
 
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/pkg/netx"
 	"flashcat.cloud/categraf/pkg/tls"
 	"flashcat.cloud/categraf/types"
 )
 
+var nginxUpstreamCheckLog = logger.New("input.nginx_upstream_check")
+
 const inputName = "nginx_upstream_check"
 
 type NginxUpstreamCheck struct {
@@ -184,7 +186,7 @@ type NginxUpstreamCheckServer struct {
 
 func (ins *Instance) gather(slist *types.SampleList, target string) {
 	if config.Config.DebugMode {
-		log.Println("D! nginx_upstream_check... target:", target)
+		nginxUpstreamCheckLog.Debugf("nginx_upstream_check... target: %v", target)
 	}
 
 	labels := map[string]string{"target": target}
@@ -193,7 +195,7 @@ func (ins *Instance) gather(slist *types.SampleList, target string) {
 
 	err := ins.gatherJSONData(target, checkData)
 	if err != nil {
-		log.Println("E! failed to gather json data:", err)
+		nginxUpstreamCheckLog.Errorf("failed to gather json data: %v", err)
 		return
 	}
 
@@ -0,0 +1,343 @@
+package nodejs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/types"
+)
+
+var nodejsLog = logger.New("input.nodejs")
+
+const inputName = "nodejs"
+
+// the kinds of gc reported by PerformanceObserver, see perf_hooks.constants
+var gcKinds = map[string]string{
+	"1": "minor",
+	"2": "major",
+	"4": "incremental",
+	"8": "weakcb",
+}
+
+// fields of v8.getHeapStatistics()
+var heapStats = map[string]string{
+	"total_heap_size":      "heap_total_bytes",
+	"total_physical_size":  "heap_physical_bytes",
+	"total_available_size": "heap_available_bytes",
+	"used_heap_size":       "heap_used_bytes",
+	"heap_size_limit":      "heap_limit_bytes",
+	"malloced_memory":      "heap_malloced_bytes",
+	"external_memory":      "heap_external_bytes",
+}
+
+// expression evaluated in the node process by the inspector, the observers of event loop delay
+// and gc are installed on the first evaluation and kept in globalThis for the later ones
+const expression = `(() => {
+  if (!globalThis.__categraf) {
+    const { monitorEventLoopDelay, PerformanceObserver } = require('perf_hooks');
+    const s = { eld: monitorEventLoopDelay({ resolution: 10 }), gc: {} };
+    s.eld.enable();
+    new PerformanceObserver((list) => {
+      for (const e of list.getEntries()) {
+        const kind = String(e.detail ? e.detail.kind : e.kind);
+        const c = s.gc[kind] || (s.gc[kind] = { count: 0, seconds: 0 });
+        c.count++;
+        c.seconds += e.duration / 1000;
+      }
+    }).observe({ entryTypes: ['gc'] });
+    globalThis.__categraf = s;
+  }
+  const s = globalThis.__categraf;
+  const v8 = require('v8');
+  const r = {
+    memory: process.memoryUsage(),
+    heap: v8.getHeapStatistics(),
+    spaces: v8.getHeapSpaceStatistics(),
+    eld: s.eld.count === 0 ? null : {
+      min: s.eld.min, max: s.eld.max, mean: s.eld.mean,
+      p50: s.eld.percentile(50), p90: s.eld.percentile(90), p99: s.eld.percentile(99),
+    },
+    gc: s.gc,
+    handles: process._getActiveHandles ? process._getActiveHandles().length : null,
+    requests: process._getActiveRequests ? process._getActiveRequests().length : null,
+    uptime: process.uptime(),
+    version: process.version,
+  };
+  s.eld.reset();
+  return JSON.stringify(r);
+})()`
+
+type NodeJS struct {
+	config.PluginConfig
+	Instances []*Instance `toml:"instances"`
+}
+
+func init() {
+	inputs.Add(inputName, func() inputs.Input {
+		return &NodeJS{}
+	})
+}
+
+func (n *NodeJS) Clone() inputs.Input {
+	return &NodeJS{}
+}
+
+func (n *NodeJS) Name() string {
+	return inputName
+}
+
+func (n *NodeJS) GetInstances() []inputs.Instance {
+	ret := make([]inputs.Instance, len(n.Instances))
+	for i := 0; i < len(n.Instances); i++ {
+		ret[i] = n.Instances[i]
+	}
+	return ret
+}
+
+type Instance struct {
+	config.InstanceConfig
+
+	// address of the inspector, the node process is started with --inspect=127.0.0.1:9229
+	// or receives SIGUSR1 to listen on it
+	InspectorURLs []string        `toml:"inspector_urls"`
+	Timeout       config.Duration `toml:"timeout"`
+
+	client *http.Client
+}
+
+type target struct {
+	Type                 string `json:"type"`
+	Title                string `json:"title"`
+	WebSocketDebuggerURL string `json:"webSocketDebuggerUrl"`
+}
+
+type runtimeStats struct {
+	Memory map[string]float64 `json:"memory"`
+	Heap   map[string]float64 `json:"heap"`
+	Spaces []struct {
+		SpaceName          string  `json:"space_name"`
+		SpaceSize          float64 `json:"space_size"`
+		SpaceUsedSize      float64 `json:"space_used_size"`
+		SpaceAvailableSize float64 `json:"space_available_size"`
+	} `json:"spaces"`
+	EventLoopDelay *struct {
+		Min  float64 `json:"min"`
+		Max  float64 `json:"max"`
+		Mean float64 `json:"mean"`
+		P50  float64 `json:"p50"`
+		P90  float64 `json:"p90"`
+		P99  float64 `json:"p99"`
+	} `json:"eld"`
+	GC map[string]struct {
+		Count   float64 `json:"count"`
+		Seconds float64 `json:"seconds"`
+	} `json:"gc"`
+	Handles  *float64 `json:"handles"`
+	Requests *float64 `json:"requests"`
+	Uptime   float64  `json:"uptime"`
+	Version  string   `json:"version"`
+}
+
+func (ins *Instance) Init() error {
+	if len(ins.InspectorURLs) == 0 {
+		return types.ErrInstancesEmpty
+	}
+
+	if ins.Timeout == 0 {
+		ins.Timeout = config.Duration(5 * time.Second)
+	}
+
+	ins.client = &http.Client{Timeout: time.Duration(ins.Timeout)}
+	return nil
+}
+
+func (ins *Instance) Gather(slist *types.SampleList) {
+	for _, u := range ins.InspectorURLs {
+		u = strings.TrimSuffix(u, "/")
+		tags := map[string]string{"inspector_url": u}
+
+		stats, err := ins.gather(u)
+		if err != nil {
+			nodejsLog.Errorf("failed to gather nodejs runtime stats from: %v error: %v", u, err)
+			slist.PushSample(inputName, "up", 0, tags)
+			continue
+		}
+		slist.PushSample(inputName, "up", 1, tags)
+		stats.push(slist, tags)
+	}
+}
+
+func (s *runtimeStats) push(slist *types.SampleList, tags map[string]string) {
+	slist.PushSample(inputName, "info", 1, tags, map[string]string{"version": s.Version})
+	slist.PushSample(inputName, "uptime_seconds", s.Uptime, tags)
+
+	// rss, heapTotal, heapUsed, external and arrayBuffers
+	for k, v := range s.Memory {
+		slist.PushSample(inputName, "memory_"+snakeCase(k)+"_bytes", v, tags)
+	}
+
+	for k, name := range heapStats {
+		if v, has := s.Heap[k]; has {
+			slist.PushSample(inputName, name, v, tags)
+		}
+	}
+
+	for _, space := range s.Spaces {
+		spaceTags := map[string]string{"space": strings.TrimSuffix(space.SpaceName, "_space")}
+		slist.PushSample(inputName, "heap_space_size_bytes", space.SpaceSize, tags, spaceTags)
+		slist.PushSample(inputName, "heap_space_used_bytes", space.SpaceUsedSize, tags, spaceTags)
+		slist.PushSample(inputName, "heap_space_available_bytes", space.SpaceAvailableSize, tags, spaceTags)
+	}
+
+	// the delays are in nanoseconds, and measured since the last gather
+	if eld := s.EventLoopDelay; eld != nil {
+		slist.PushSample(inputName, "eventloop_lag_min_seconds", eld.Min/1e9, tags)
+		slist.PushSample(inputName, "eventloop_lag_max_seconds", eld.Max/1e9, tags)
+		slist.PushSample(inputName, "eventloop_lag_mean_seconds", eld.Mean/1e9, tags)
+		slist.PushSample(inputName, "eventloop_lag_seconds", eld.P50/1e9, tags, map[string]string{"quantile": "0.5"})
+		slist.PushSample(inputName, "eventloop_lag_seconds", eld.P90/1e9, tags, map[string]string{"quantile": "0.9"})
+		slist.PushSample(inputName, "eventloop_lag_seconds", eld.P99/1e9, tags, map[string]string{"quantile": "0.99"})
+	}
+
+	// the gcs are counted since the first gather
+	for kind, gc := range s.GC {
+		if name, has := gcKinds[kind]; has {
+			kind = name
+		}
+		gcTags := map[string]string{"kind": kind}
+		slist.PushSample(inputName, "gc_total", gc.Count, tags, gcTags)
+		slist.PushSample(inputName, "gc_seconds_total", gc.Seconds, tags, gcTags)
+	}
+
+	if s.Handles != nil {
+		slist.PushSample(inputName, "active_handles", *s.Handles, tags)
+	}
+	if s.Requests != nil {
+		slist.PushSample(inputName, "active_requests", *s.Requests, tags)
+	}
+}
+
+func (ins *Instance) gather(u string) (*runtimeStats, error) {
+	wsURL, err := ins.debuggerURL(u)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := websocket.Dialer{HandshakeTimeout: time.Duration(ins.Timeout)}
+	conn, _, err := dialer.Dial(wsURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %v", wsURL, err)
+	}
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(time.Duration(ins.Timeout)))
+	conn.SetWriteDeadline(time.Now().Add(time.Duration(ins.Timeout)))
+
+	req := map[string]interface{}{
+		"id":     1,
+		"method": "Runtime.evaluate",
+		"params": map[string]interface{}{
+			"expression": expression,
+			// require is not defined in the global scope of es modules
+			"includeCommandLineAPI": true,
+			"returnByValue":         true,
+			"silent":                true,
+		},
+	}
+	if err := conn.WriteJSON(req); err != nil {
+		return nil, err
+	}
+
+	for {
+		var res struct {
+			ID     int `json:"id"`
+			Result struct {
+				Result struct {
+					Type  string `json:"type"`
+					Value string `json:"value"`
+				} `json:"result"`
+				ExceptionDetails *struct {
+					Text      string `json:"text"`
+					Exception struct {
+						Description string `json:"description"`
+					} `json:"exception"`
+				} `json:"exceptionDetails"`
+			} `json:"result"`
+			Error *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+
+		if err := conn.ReadJSON(&res); err != nil {
+			return nil, err
+		}
+
+		// events of the inspector
+		if res.ID != 1 {
+			continue
+		}
+
+		if res.Error != nil {
+			return nil, errors.New(res.Error.Message)
+		}
+
+		if e := res.Result.ExceptionDetails; e != nil {
+			return nil, fmt.Errorf("failed to evaluate: %s %s", e.Text, e.Exception.Description)
+		}
+
+		var stats runtimeStats
+		if err := json.Unmarshal([]byte(res.Result.Result.Value), &stats); err != nil {
+			return nil, fmt.Errorf("failed to parse result of evaluation: %v", err)
+		}
+		return &stats, nil
+	}
+}
+
+// debuggerURL returns the url of websocket of the first node target listed by the inspector
+func (ins *Instance) debuggerURL(u string) (string, error) {
+	res, err := ins.client.Get(u + "/json/list")
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s/json/list returned status code: %d", u, res.StatusCode)
+	}
+
+	var targets []target
+	if err := json.NewDecoder(res.Body).Decode(&targets); err != nil {
+		return "", fmt.Errorf("failed to decode %s/json/list: %v", u, err)
+	}
+
+	for _, t := range targets {
+		if t.Type == "node" && t.WebSocketDebuggerURL != "" {
+			return t.WebSocketDebuggerURL, nil
+		}
+	}
+	return "", fmt.Errorf("no node target listed by %s/json/list", u)
+}
+
+// snakeCase converts heapUsed to heap_used
+func snakeCase(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if r >= 'A' && r <= 'Z' {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -1,15 +1,17 @@
 package ntp
 
 import (
-	"log"
 	"time"
 
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/types"
 	"github.com/toolkits/pkg/nux"
 )
 
+var ntpLog = logger.New("input.ntp")
+
 const inputName = "ntp"
 
 type NTPStat struct {
@@ -48,7 +50,7 @@ func (n *NTPStat) Gather(slist *types.SampleList) {
 		orgTime := time.Now()
 		serverReciveTime, serverTransmitTime, err := nux.NtpTwoTime(n.server)
 		if err != nil {
-			log.Println("E! failed to connect ntp server:", n.server, "error:", err)
+			ntpLog.Errorf("failed to connect ntp server: %v error: %v", n.server, err)
 			n.server = ""
 			continue
 		}
@@ -2,7 +2,6 @@ package nvidia_smi
 
 import (
 	"fmt"
-	"log"
 	"strings"
 
 	"flashcat.cloud/categraf/pkg/stringx"
@@ -55,7 +54,7 @@ func buildQFieldToRFieldMap(qFieldsRaw string, nvidiaSmiCommand string) ([]qFiel
 	if len(qFieldsSeparated) == 1 && qFieldsSeparated[0] == qFieldsAuto {
 		parsed, err := parseAutoQFields(nvidiaSmiCommand)
 		if err != nil {
-			log.Println("W! failed to auto-determine query field names, falling back to the built-in list. error:", err)
+			nvidiaSmiLog.Warnf("failed to auto-determine query field names, falling back to the built-in list. error: %v", err)
 			return getKeys(fallbackQFieldToRFieldMap), fallbackQFieldToRFieldMap, nil
 		}
 
@@ -67,7 +66,7 @@ func buildQFieldToRFieldMap(qFieldsRaw string, nvidiaSmiCommand string) ([]qFiel
 	var rFields []rField
 
 	if err != nil {
-		log.Println("W! failed to run an initial scrape, using the built-in list for field mapping")
+		nvidiaSmiLog.Warnf("failed to run an initial scrape, using the built-in list for field mapping")
 
 		rFields, err = getFallbackValues(qFields)
 		if err != nil {
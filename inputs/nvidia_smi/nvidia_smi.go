@@ -3,15 +3,17 @@ package nvidia_smi
 // This is a fork of https://github.com/utkuozdemir/nvidia_gpu_exporter
 
 import (
-	"log"
 	"strings"
 	"time"
 
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/types"
 )
 
+var nvidiaSmiLog = logger.New("input.nvidia_smi")
+
 const inputName = "nvidia_smi"
 
 type GPUStats struct {
@@ -98,7 +100,7 @@ func (s *GPUStats) Gather(slist *types.SampleList) {
 			num, err := transformRawValue(currentCell.rawValue, metricInfo.valueMultiplier)
 			if err != nil {
 				if config.Config.DebugMode {
-					log.Println("D! failed to transform gpu field:", currentCell.qField, "raw value:", currentCell.rawValue, "error:", err)
+					nvidiaSmiLog.Debugf("failed to transform gpu field: %v raw value: %v error: %v", currentCell.qField, currentCell.rawValue, err)
 				}
 				continue
 			}
@@ -1 +1,5 @@
 package oracle
+
+import "flashcat.cloud/categraf/pkg/logger"
+
+var oracleLog = logger.New("input.oracle")
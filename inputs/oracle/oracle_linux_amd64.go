@@ -6,7 +6,6 @@ package oracle
 import (
 	"context"
 	"fmt"
-	"log"
 	"strings"
 	"sync"
 	"time"
@@ -108,18 +107,18 @@ func (ins *Instance) Init() error {
 
 func (ins *Instance) Drop() error {
 	if config.Config.DebugMode {
-		log.Println("D! dropping oracle connection:", ins.Address)
+		oracleLog.Debugf("dropping oracle connection: %v", ins.Address)
 	}
 
 	if len(ins.Address) == 0 || ins.client == nil {
 		if config.Config.DebugMode {
-			log.Println("D! oracle address is empty or client is nil, so there is no need to close")
+			oracleLog.Debugf("oracle address is empty or client is nil, so there is no need to close")
 		}
 		return nil
 	}
 
 	if err := ins.client.Close(); err != nil {
-		log.Println("E! failed to close oracle connection:", ins.Address, "error:", err)
+		oracleLog.Errorf("failed to close oracle connection: %v error: %v", ins.Address, err)
 	}
 
 	return nil
@@ -128,7 +127,7 @@ func (ins *Instance) Drop() error {
 func (ins *Instance) Gather(slist *types.SampleList) {
 	if len(ins.Address) == 0 {
 		if config.Config.DebugMode {
-			log.Println("D! oracle address is empty")
+			oracleLog.Debugf("oracle address is empty")
 		}
 		return
 	}
@@ -141,7 +140,7 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 
 	if err := ins.client.Ping(); err != nil {
 		slist.PushFront(types.NewSample(inputName, "up", 0, tags))
-		log.Println("E! failed to ping oracle:", ins.Address, "error:", err)
+		oracleLog.Errorf("failed to ping oracle: %v error: %v", ins.Address, err)
 		return
 	} else {
 		slist.PushFront(types.NewSample(inputName, "up", 1, tags))
@@ -174,12 +173,12 @@ func (ins *Instance) scrapeMetric(waitMetrics *sync.WaitGroup, slist *types.Samp
 	rows, err := ins.client.QueryContext(ctx, metricConf.Request)
 
 	if ctx.Err() == context.DeadlineExceeded {
-		log.Println("E! oracle query timeout, request:", metricConf.Request)
+		oracleLog.Errorf("oracle query timeout, request: %v", metricConf.Request)
 		return
 	}
 
 	if err != nil {
-		log.Println("E! failed to query:", err)
+		oracleLog.Errorf("failed to query: %v", err)
 		return
 	}
 
@@ -187,12 +186,12 @@ func (ins *Instance) scrapeMetric(waitMetrics *sync.WaitGroup, slist *types.Samp
 
 	cols, err := rows.Columns()
 	if err != nil {
-		log.Println("E! failed to get columns:", err)
+		oracleLog.Errorf("failed to get columns: %v", err)
 		return
 	}
 
 	if config.Config.DebugMode {
-		log.Println("D! columns:", cols)
+		oracleLog.Debugf("columns: %v", cols)
 	}
 
 	for rows.Next() {
@@ -204,7 +203,7 @@ func (ins *Instance) scrapeMetric(waitMetrics *sync.WaitGroup, slist *types.Samp
 
 		// Scan the result into the column pointers...
 		if err := rows.Scan(columnPointers...); err != nil {
-			log.Println("E! failed to scan:", err)
+			oracleLog.Errorf("failed to scan: %v", err)
 			return
 		}
 
@@ -218,14 +217,14 @@ func (ins *Instance) scrapeMetric(waitMetrics *sync.WaitGroup, slist *types.Samp
 
 		count := 0
 		if err = ins.parseRow(m, metricConf, slist, tags); err != nil {
-			log.Println("E! failed to parse row:", err)
+			oracleLog.Errorf("failed to parse row: %v", err)
 			continue
 		} else {
 			count++
 		}
 
 		if !metricConf.IgnoreZeroResult && count == 0 {
-			log.Println("E! no metrics found while parsing")
+			oracleLog.Errorf("no metrics found while parsing")
 		}
 	}
 }
@@ -246,7 +245,7 @@ func (ins *Instance) parseRow(row map[string]string, metricConf MetricConfig, sl
 	for _, column := range metricConf.MetricFields {
 		value, err := conv.ToFloat64(row[column])
 		if err != nil {
-			log.Println("E! failed to convert field:", column, "value:", value, "error:", err)
+			oracleLog.Errorf("failed to convert field: %v value: %v error: %v", column, value, err)
 			return err
 		}
 
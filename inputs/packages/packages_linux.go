@@ -0,0 +1,303 @@
+//go:build linux
+// +build linux
+
+package packages
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/pkg/cmdx"
+	"flashcat.cloud/categraf/pkg/osx"
+	"flashcat.cloud/categraf/types"
+)
+
+const (
+	managerDpkg = "dpkg"
+	managerRpm  = "rpm"
+	managerApk  = "apk"
+)
+
+var rpmDBPaths = []string{"/var/lib/rpm", "/usr/lib/sysimage/rpm"}
+
+func (p *Packages) Init() error {
+	if !p.Enable {
+		return types.ErrInstancesEmpty
+	}
+
+	if p.RootPath == "" {
+		p.RootPath = "/"
+	}
+
+	if p.CommandTimeout == 0 {
+		p.CommandTimeout = config.Duration(30 * time.Second)
+	}
+
+	for _, m := range p.Managers {
+		if m != managerDpkg && m != managerRpm && m != managerApk {
+			return fmt.Errorf("unsupported package manager: %s", m)
+		}
+	}
+
+	if len(p.Managers) == 0 {
+		p.Managers = p.detectManagers()
+	}
+
+	return p.initReport()
+}
+
+func (p *Packages) Gather(slist *types.SampleList) {
+	release := readProcFile("sys/kernel/osrelease")
+	version := readProcFile("sys/kernel/version")
+	slist.PushSample(inputName, "kernel_info", 1, map[string]string{
+		"kernel_release": release,
+		"kernel_version": version,
+	})
+
+	report := &Report{
+		Hostname:      config.Config.GetHostname(),
+		KernelRelease: release,
+		KernelVersion: version,
+		Unixtime:      time.Now().Unix(),
+	}
+
+	for _, m := range p.Managers {
+		tags := map[string]string{"manager": m}
+
+		pkgs, err := p.list(m)
+		if err != nil {
+			packagesLog.Errorf("failed to list packages of %v error: %v", m, err)
+			slist.PushSample(inputName, "up", 0, tags)
+			continue
+		}
+
+		kernels := 0
+		for _, pkg := range pkgs {
+			if isKernelPackage(pkg.Name) {
+				kernels++
+			}
+			if p.GatherPackageInfo {
+				slist.PushSample(inputName, "info", 1, tags, map[string]string{
+					"name":    pkg.Name,
+					"version": pkg.Version,
+					"arch":    pkg.Arch,
+				})
+			}
+		}
+
+		slist.PushSample(inputName, "up", 1, tags)
+		slist.PushSample(inputName, "installed", len(pkgs), tags)
+		slist.PushSample(inputName, "kernel_installed", kernels, tags)
+		report.Packages = append(report.Packages, pkgs...)
+	}
+
+	if err := p.report(report); err != nil {
+		packagesLog.Errorf("failed to report packages to %v error: %v", p.ReportUrl, err)
+	}
+}
+
+func (p *Packages) detectManagers() []string {
+	var managers []string
+	if fileExists(p.path("/var/lib/dpkg/status")) {
+		managers = append(managers, managerDpkg)
+	}
+
+	if fileExists(p.path("/lib/apk/db/installed")) {
+		managers = append(managers, managerApk)
+	}
+
+	if _, err := exec.LookPath("rpm"); err == nil {
+		for _, db := range rpmDBPaths {
+			if fileExists(p.path(db)) {
+				managers = append(managers, managerRpm)
+				break
+			}
+		}
+	}
+	return managers
+}
+
+func (p *Packages) list(manager string) ([]*Package, error) {
+	switch manager {
+	case managerDpkg:
+		return p.listDpkg()
+	case managerApk:
+		return p.listApk()
+	default:
+		return p.listRpm()
+	}
+}
+
+// listDpkg parses the status database of dpkg, the stanzas are separated by blank lines
+func (p *Packages) listDpkg() ([]*Package, error) {
+	f, err := os.Open(p.path("/var/lib/dpkg/status"))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var (
+		pkgs      []*Package
+		pkg       = &Package{Manager: managerDpkg}
+		installed bool
+	)
+
+	flush := func() {
+		if installed && pkg.Name != "" {
+			pkgs = append(pkgs, pkg)
+		}
+		pkg = &Package{Manager: managerDpkg}
+		installed = false
+	}
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			flush()
+			continue
+		}
+
+		key, value, found := strings.Cut(line, ": ")
+		if !found {
+			continue
+		}
+
+		switch key {
+		case "Package":
+			pkg.Name = value
+		case "Version":
+			pkg.Version = value
+		case "Architecture":
+			pkg.Arch = value
+		case "Status":
+			// e.g. install ok installed, the packages removed with config left are config-files
+			installed = strings.HasSuffix(value, " installed")
+		}
+	}
+	flush()
+
+	return pkgs, scanner.Err()
+}
+
+// listApk parses the installed database of apk, the fields are single letter prefixed
+func (p *Packages) listApk() ([]*Package, error) {
+	f, err := os.Open(p.path("/lib/apk/db/installed"))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var (
+		pkgs []*Package
+		pkg  = &Package{Manager: managerApk}
+	)
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if pkg.Name != "" {
+				pkgs = append(pkgs, pkg)
+			}
+			pkg = &Package{Manager: managerApk}
+			continue
+		}
+
+		if len(line) < 2 || line[1] != ':' {
+			continue
+		}
+
+		switch line[0] {
+		case 'P':
+			pkg.Name = line[2:]
+		case 'V':
+			pkg.Version = line[2:]
+		case 'A':
+			pkg.Arch = line[2:]
+		}
+	}
+
+	if pkg.Name != "" {
+		pkgs = append(pkgs, pkg)
+	}
+
+	return pkgs, scanner.Err()
+}
+
+func (p *Packages) listRpm() ([]*Package, error) {
+	args := []string{"-qa", "--queryformat", "%{NAME}\t%{EPOCH}:%{VERSION}-%{RELEASE}\t%{ARCH}\n"}
+	if p.RootPath != "/" {
+		args = append(args, "--root", p.RootPath)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("rpm", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err, timeout := cmdx.RunTimeout(cmd, time.Duration(p.CommandTimeout))
+	if timeout {
+		return nil, fmt.Errorf("rpm -qa timeout after %s", time.Duration(p.CommandTimeout))
+	}
+
+	if err != nil {
+		if stderr.Len() > 0 {
+			return nil, fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		return nil, err
+	}
+
+	var pkgs []*Package
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 3 {
+			continue
+		}
+
+		// epoch is (none) for most packages
+		version := strings.TrimPrefix(fields[1], "(none):")
+		pkgs = append(pkgs, &Package{Manager: managerRpm, Name: fields[0], Version: version, Arch: fields[2]})
+	}
+	return pkgs, nil
+}
+
+func (p *Packages) path(name string) string {
+	return filepath.Join(p.RootPath, name)
+}
+
+// isKernelPackage reports whether the package is an installed kernel image,
+// e.g. kernel-core of rpm, linux-image-5.10.0-21-amd64 of dpkg and linux-lts of apk
+func isKernelPackage(name string) bool {
+	switch name {
+	case "kernel", "kernel-core", "kernel-default", "kernel-uek", "linux-lts", "linux-virt", "linux-edge":
+		return true
+	}
+
+	if strings.HasPrefix(name, "linux-image-") {
+		rest := strings.TrimPrefix(name, "linux-image-")
+		return rest != "" && rest[0] >= '0' && rest[0] <= '9'
+	}
+	return false
+}
+
+func readProcFile(name string) string {
+	bs, err := os.ReadFile(filepath.Join(osx.GetHostProc(), name))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(bs))
+}
+
+func fileExists(name string) bool {
+	_, err := os.Stat(name)
+	return err == nil
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+// +build !linux
+
+package packages
+
+import (
+	"flashcat.cloud/categraf/types"
+)
+
+func (p *Packages) Init() error {
+	return nil
+}
+
+func (p *Packages) Gather(slist *types.SampleList) {
+}
@@ -4,7 +4,6 @@ import (
 	"bufio"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"net/url"
 	"os"
@@ -16,11 +15,14 @@ import (
 
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/pkg/tls"
 	"flashcat.cloud/categraf/types"
 	fcgiclient "github.com/tomasen/fcgi_client"
 )
 
+var phpfpmLog = logger.New("input.phpfpm")
+
 const (
 	inputName = "phpfpm"
 
@@ -104,7 +106,7 @@ func (ins *Instance) Gather(sList *types.SampleList) {
 
 	urls, err := expandUrls(ins.Urls)
 	if err != nil {
-		log.Println("E! failed to parse urls:", err)
+		phpfpmLog.Errorf("failed to parse urls: %v", err)
 		return
 	}
 
@@ -113,7 +115,7 @@ func (ins *Instance) Gather(sList *types.SampleList) {
 		go func(url string) {
 			defer wg.Done()
 			if err := ins.gather(url, sList); err != nil {
-				log.Println("E!", err)
+				phpfpmLog.Errorf("%v", err)
 			}
 		}(u)
 	}
@@ -123,7 +125,7 @@ func (ins *Instance) Gather(sList *types.SampleList) {
 
 func (ins *Instance) gather(addr string, sList *types.SampleList) error {
 	if config.Config.DebugMode {
-		log.Println("D! php-fpm... url:", addr)
+		phpfpmLog.Debugf("php-fpm... url: %v", addr)
 	}
 
 	var resp *http.Response
@@ -144,7 +146,7 @@ func (ins *Instance) gather(addr string, sList *types.SampleList) error {
 	defer func(Body io.ReadCloser) {
 		err := Body.Close()
 		if err != nil {
-			log.Println("E! failed to close the body of client:", err)
+			phpfpmLog.Errorf("failed to close the body of client: %v", err)
 		}
 	}(resp.Body)
 
@@ -229,7 +231,7 @@ func (ins *Instance) initHTTPClient() {
 	if ins.client == nil {
 		client, err := ins.createHTTPClient()
 		if err != nil {
-			log.Printf("failed to create http client: %v", err)
+			phpfpmLog.Warnf("failed to create http client: %v", err)
 		}
 		ins.client = client
 	}
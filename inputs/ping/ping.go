@@ -2,7 +2,6 @@ package ping
 
 import (
 	"fmt"
-	"log"
 	"net"
 	"runtime"
 	"strings"
@@ -11,10 +10,13 @@ import (
 
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/types"
 	ping "github.com/prometheus-community/pro-bing"
 )
 
+var pingLog = logger.New("input.ping")
+
 const (
 	inputName                = "ping"
 	defaultPingDataBytesSize = 56
@@ -131,7 +133,7 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 
 func (ins *Instance) gather(slist *types.SampleList, target string) {
 	if config.Config.DebugMode {
-		log.Println("D! ping...", target)
+		pingLog.Debugf("ping... %v", target)
 	}
 
 	labels := map[string]string{"target": target}
@@ -146,7 +148,7 @@ func (ins *Instance) gather(slist *types.SampleList, target string) {
 
 	stats, err := ins.ping(target)
 	if err != nil {
-		log.Println("E! failed to ping:", target, "error:", err)
+		pingLog.Errorf("failed to ping: %v error: %v", target, err)
 		if strings.Contains(err.Error(), "unknown") {
 			fields["result_code"] = 1
 		} else {
@@ -159,7 +161,7 @@ func (ins *Instance) gather(slist *types.SampleList, target string) {
 
 	if stats.PacketsSent == 0 {
 		if config.Config.DebugMode {
-			log.Println("D! no packets sent, target:", target)
+			pingLog.Debugf("no packets sent, target: %v", target)
 		}
 		fields["result_code"] = 2
 		return
@@ -167,7 +169,7 @@ func (ins *Instance) gather(slist *types.SampleList, target string) {
 
 	if stats.PacketsRecv == 0 {
 		if config.Config.DebugMode {
-			log.Println("D! no packets received, target:", target)
+			pingLog.Debugf("no packets received, target: %v", target)
 		}
 		fields["result_code"] = 1
 		fields["minimum_response_ms"] = float64(-1)
@@ -5,7 +5,6 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"log"
 	"net"
 	"net/url"
 	"regexp"
@@ -18,11 +17,14 @@ import (
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
 	"flashcat.cloud/categraf/pkg/conv"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/types"
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/stdlib"
 )
 
+var postgresqlLog = logger.New("input.postgresql")
+
 const (
 	inputName = "postgresql"
 )
@@ -81,6 +83,11 @@ type Instance struct {
 	PreparedStatements bool            `toml:"prepared_statements"`
 	Metrics            []MetricConfig  `toml:"metrics"`
 
+	// per query metrics of pg_stat_statements, limited to the statements with the most total time
+	GatherStatements    bool `toml:"gather_statements"`
+	StatementsTopN      int  `toml:"statements_top_n"`
+	StatementsQuerySize int  `toml:"statements_query_size"`
+
 	MaxIdle int
 	MaxOpen int
 	DB      *sql.DB
@@ -98,6 +105,12 @@ func (ins *Instance) Init() error {
 	if ins.Address == "" {
 		return types.ErrInstancesEmpty
 	}
+	if ins.StatementsTopN <= 0 {
+		ins.StatementsTopN = defaultStatementsTopN
+	}
+	if ins.StatementsQuerySize <= 0 {
+		ins.StatementsQuerySize = defaultStatementsQuerySize
+	}
 	ins.MaxIdle = 1
 	ins.MaxOpen = 1
 	// ins.MaxLifetime = config.Duration(0)
@@ -115,7 +128,7 @@ func (ins *Instance) Init() error {
 
 	connConfig, err := pgx.ParseConfig(ins.Address)
 	if err != nil {
-		log.Println("E! can't parse address :", err)
+		postgresqlLog.Errorf("can't parse address : %v", err)
 		return err
 	}
 
@@ -132,7 +145,7 @@ func (ins *Instance) Init() error {
 
 	connectionString := stdlib.RegisterConnConfig(connConfig)
 	if ins.DB, err = sql.Open("pgx", connectionString); err != nil {
-		log.Println("E! can't open db :", err)
+		postgresqlLog.Errorf("can't open db : %v", err)
 		return err
 	}
 
@@ -168,7 +181,7 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 
 	rows, err := ins.DB.Query(query)
 	if err != nil {
-		log.Println("E! failed to execute Query :", err)
+		postgresqlLog.Errorf("failed to execute Query : %v", err)
 		return
 	}
 
@@ -176,14 +189,14 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 
 	// grab the column information from the result
 	if columns, err = rows.Columns(); err != nil {
-		log.Println("E! failed to grab column info:", err)
+		postgresqlLog.Errorf("failed to grab column info: %v", err)
 		return
 	}
 
 	for rows.Next() {
 		err = ins.accRow(rows, slist, columns)
 		if err != nil {
-			log.Println("E! failed to get row data:", err)
+			postgresqlLog.Errorf("failed to get row data: %v", err)
 			return
 		}
 	}
@@ -192,7 +205,7 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 
 	bgWriterRow, err := ins.DB.Query(query)
 	if err != nil {
-		log.Println("E! failed to execute Query:", err)
+		postgresqlLog.Errorf("failed to execute Query: %v", err)
 		return
 	}
 
@@ -200,18 +213,20 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 
 	// grab the column information from the result
 	if columns, err = bgWriterRow.Columns(); err != nil {
-		log.Println("E! failed to grab column info:", err)
+		postgresqlLog.Errorf("failed to grab column info: %v", err)
 		return
 	}
 
 	for bgWriterRow.Next() {
 		err = ins.accRow(bgWriterRow, slist, columns)
 		if err != nil {
-			log.Println("E! failed to get row data:", err)
+			postgresqlLog.Errorf("failed to get row data: %v", err)
 			return
 		}
 	}
 
+	ins.gatherStatements(slist)
+
 	waitMetrics := new(sync.WaitGroup)
 
 	for i := 0; i < len(ins.Metrics); i++ {
@@ -234,12 +249,12 @@ func (ins *Instance) scrapeMetric(waitMetrics *sync.WaitGroup, slist *types.Samp
 	rows, err := ins.DB.QueryContext(ctx, metricConf.Request)
 
 	if ctx.Err() == context.DeadlineExceeded {
-		log.Println("E! postgresql query timeout, request:", metricConf.Request)
+		postgresqlLog.Errorf("postgresql query timeout, request: %v", metricConf.Request)
 		return
 	}
 
 	if err != nil {
-		log.Println("E! failed to query:", err)
+		postgresqlLog.Errorf("failed to query: %v", err)
 		return
 	}
 
@@ -247,7 +262,7 @@ func (ins *Instance) scrapeMetric(waitMetrics *sync.WaitGroup, slist *types.Samp
 
 	cols, err := rows.Columns()
 	if err != nil {
-		log.Println("E! failed to get columns:", err)
+		postgresqlLog.Errorf("failed to get columns: %v", err)
 		return
 	}
 
@@ -260,7 +275,7 @@ func (ins *Instance) scrapeMetric(waitMetrics *sync.WaitGroup, slist *types.Samp
 
 		// Scan the result into the column pointers...
 		if err := rows.Scan(columnPointers...); err != nil {
-			log.Println("E! failed to scan:", err)
+			postgresqlLog.Errorf("failed to scan: %v", err)
 			return
 		}
 
@@ -274,14 +289,14 @@ func (ins *Instance) scrapeMetric(waitMetrics *sync.WaitGroup, slist *types.Samp
 
 		count := 0
 		if err = ins.parseRow(m, metricConf, slist, tags); err != nil {
-			log.Println("E! failed to parse row:", err)
+			postgresqlLog.Errorf("failed to parse row: %v", err)
 			continue
 		} else {
 			count++
 		}
 
 		if !metricConf.IgnoreZeroResult && count == 0 {
-			log.Println("E! no metrics found while parsing")
+			postgresqlLog.Errorf("no metrics found while parsing")
 		}
 	}
 }
@@ -302,7 +317,7 @@ func (ins *Instance) parseRow(row map[string]string, metricConf MetricConfig, sl
 	for _, column := range metricConf.MetricFields {
 		value, err := conv.ToFloat64(row[column])
 		if err != nil {
-			log.Println("E! failed to convert field:", column, "value:", value, "error:", err)
+			postgresqlLog.Errorf("failed to convert field: %v value: %v error: %v", column, value, err)
 			return err
 		}
 
@@ -357,19 +372,19 @@ func (ins *Instance) accRow(row scanner, slist *types.SampleList, columns []stri
 		// extract the database name from the column map
 		if dbNameStr, ok := (*columnMap["datname"]).(string); ok {
 			if _, err := dbname.WriteString(dbNameStr); err != nil {
-				log.Println("E! failed to WriteString:", dbNameStr, err)
+				postgresqlLog.Errorf("failed to WriteString: %v %v", dbNameStr, err)
 				return err
 			}
 		} else {
 			// PG 12 adds tracking of global objects to pg_stat_database
 			if _, err := dbname.WriteString("postgres_global"); err != nil {
-				log.Println("E! failed to WriteString: postgres_global", err)
+				postgresqlLog.Errorf("failed to WriteString: postgres_global %v", err)
 				return err
 			}
 		}
 	} else {
 		if _, err := dbname.WriteString("postgres"); err != nil {
-			log.Println("E! failed to WriteString: postgres", err)
+			postgresqlLog.Errorf("failed to WriteString: postgres %v", err)
 			return err
 		}
 	}
@@ -377,7 +392,7 @@ func (ins *Instance) accRow(row scanner, slist *types.SampleList, columns []stri
 	var tagAddress string
 	tagAddress, err = ins.SanitizedAddress()
 	if err != nil {
-		log.Println("E! failed to SanitizedAddress", err)
+		postgresqlLog.Errorf("failed to SanitizedAddress %v", err)
 		return err
 	}
 
@@ -0,0 +1,138 @@
+package postgresql
+
+import (
+	"fmt"
+	"strconv"
+
+	"flashcat.cloud/categraf/types"
+)
+
+const (
+	defaultStatementsTopN      = 100
+	defaultStatementsQuerySize = 200
+)
+
+// pg_stat_statements renamed total_time to total_exec_time in 13
+const sqlStatements = `
+SELECT d.datname, r.rolname, s.queryid, left(s.query, $2), s.calls, s.%[1]s, s.rows
+FROM pg_stat_statements s
+JOIN pg_database d ON d.oid = s.dbid
+JOIN pg_roles r ON r.oid = s.userid
+WHERE s.queryid IS NOT NULL
+ORDER BY s.%[1]s DESC
+LIMIT $1`
+
+type statementStats struct {
+	db        string
+	user      string
+	queryid   string
+	query     string
+	calls     float64
+	totalTime float64
+	rows      float64
+}
+
+// gatherStatements reports the statements of pg_stat_statements with the most total time,
+// the extension must be created in the database of the connection
+func (ins *Instance) gatherStatements(slist *types.SampleList) {
+	if !ins.GatherStatements {
+		return
+	}
+
+	var version int
+	if err := ins.DB.QueryRow("SHOW server_version_num").Scan(&version); err != nil {
+		postgresqlLog.Errorf("failed to query server_version_num: %v", err)
+		return
+	}
+
+	column := "total_exec_time"
+	if version < 130000 {
+		column = "total_time"
+	}
+
+	rows, err := ins.DB.Query(fmt.Sprintf(sqlStatements, column), ins.StatementsTopN, ins.StatementsQuerySize)
+	if err != nil {
+		postgresqlLog.Errorf("failed to query pg_stat_statements: %v", err)
+		return
+	}
+
+	defer rows.Close()
+
+	// statements are also split by toplevel since 14
+	var ordered []*statementStats
+	stats := make(map[string]*statementStats)
+	for rows.Next() {
+		var s statementStats
+		var queryid int64
+		if err := rows.Scan(&s.db, &s.user, &queryid, &s.query, &s.calls, &s.totalTime, &s.rows); err != nil {
+			postgresqlLog.Errorf("failed to scan pg_stat_statements: %v", err)
+			return
+		}
+
+		if !ins.statementsOfDB(s.db) {
+			continue
+		}
+
+		s.queryid = strconv.FormatInt(queryid, 10)
+		key := s.db + "\x00" + s.user + "\x00" + s.queryid
+		if old, has := stats[key]; has {
+			old.calls += s.calls
+			old.totalTime += s.totalTime
+			old.rows += s.rows
+			continue
+		}
+		stats[key] = &s
+		ordered = append(ordered, &s)
+	}
+
+	if err := rows.Err(); err != nil {
+		postgresqlLog.Errorf("failed to read pg_stat_statements: %v", err)
+		return
+	}
+
+	server, err := ins.SanitizedAddress()
+	if err != nil {
+		postgresqlLog.Errorf("failed to SanitizedAddress %v", err)
+		return
+	}
+
+	for _, s := range ordered {
+		tags := map[string]string{
+			"server":  server,
+			"db":      s.db,
+			"user":    s.user,
+			"queryid": s.queryid,
+			"query":   s.query,
+		}
+
+		// times of pg_stat_statements are in milliseconds
+		fields := map[string]interface{}{
+			"statements_calls_total":        s.calls,
+			"statements_total_time_seconds": s.totalTime / 1000,
+			"statements_rows_total":         s.rows,
+		}
+		if s.calls > 0 {
+			fields["statements_mean_time_seconds"] = s.totalTime / s.calls / 1000
+		}
+		slist.PushSamples(inputName, fields, tags)
+	}
+}
+
+func (ins *Instance) statementsOfDB(db string) bool {
+	for _, ignored := range ins.IgnoredDatabases {
+		if db == ignored {
+			return false
+		}
+	}
+
+	if len(ins.Databases) == 0 {
+		return true
+	}
+
+	for _, name := range ins.Databases {
+		if db == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+//go:build linux
+// +build linux
+
+package processes
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"flashcat.cloud/categraf/pkg/osx"
+	"flashcat.cloud/categraf/types"
+)
+
+const defaultFdTopN = 10
+
+type processFd struct {
+	pid       string
+	comm      string
+	fds       uint64
+	softLimit uint64
+	hardLimit uint64
+}
+
+func (f *processFd) usage() float64 {
+	if f.softLimit == 0 {
+		return 0
+	}
+	return float64(f.fds) / float64(f.softLimit) * 100
+}
+
+// gatherFd gathers the file handles of system from file-nr, and the top processes
+// ordered by fd usage vs soft limit of open files, which run out first
+func (p *Processes) gatherFd(slist *types.SampleList) {
+	proc := osx.GetHostProc()
+
+	data, err := os.ReadFile(filepath.Join(proc, "sys/fs/file-nr"))
+	if err != nil {
+		processesLog.Errorf("failed to read file-nr: %v", err)
+	} else if fields := strings.Fields(string(data)); len(fields) == 3 {
+		allocated, _ := strconv.ParseUint(fields[0], 10, 64)
+		fileMax, _ := strconv.ParseUint(fields[2], 10, 64)
+		slist.PushSample(inputName, "fd_allocated", allocated)
+		slist.PushSample(inputName, "fd_max", fileMax)
+		if fileMax > 0 {
+			slist.PushSample(inputName, "fd_used_percent", float64(allocated)/float64(fileMax)*100)
+		}
+	}
+
+	dirs, err := filepath.Glob(proc + "/[0-9]*")
+	if err != nil {
+		processesLog.Errorf("failed to list processes: %v", err)
+		return
+	}
+
+	procs := make([]*processFd, 0, len(dirs))
+	for _, dir := range dirs {
+		// fd of processes of other users are not readable without root or CAP_SYS_PTRACE
+		entries, err := os.ReadDir(filepath.Join(dir, "fd"))
+		if err != nil {
+			continue
+		}
+
+		f := &processFd{pid: filepath.Base(dir), fds: uint64(len(entries))}
+		f.softLimit, f.hardLimit = readOpenFilesLimit(filepath.Join(dir, "limits"))
+		if comm, err := readProcFile(filepath.Join(dir, "comm")); err == nil {
+			f.comm = string(bytes.TrimSpace(comm))
+		}
+		procs = append(procs, f)
+	}
+
+	sort.Slice(procs, func(i, j int) bool {
+		if procs[i].usage() != procs[j].usage() {
+			return procs[i].usage() > procs[j].usage()
+		}
+		return procs[i].fds > procs[j].fds
+	})
+
+	var total uint64
+	for _, f := range procs {
+		total += f.fds
+	}
+	slist.PushSample(inputName, "fd_processes_total", total)
+	if len(procs) > 0 {
+		slist.PushSample(inputName, "fd_max_usage_percent", procs[0].usage())
+	}
+
+	topN := p.FdTopN
+	if topN <= 0 {
+		topN = defaultFdTopN
+	}
+
+	for i := 0; i < len(procs) && i < topN; i++ {
+		f := procs[i]
+		tags := map[string]string{"pid": f.pid, "comm": f.comm}
+		slist.PushSample(inputName, "fd_num_fds", f.fds, tags)
+		slist.PushSample(inputName, "fd_rlimit_soft", f.softLimit, tags)
+		slist.PushSample(inputName, "fd_rlimit_hard", f.hardLimit, tags)
+		slist.PushSample(inputName, "fd_usage_percent", f.usage(), tags)
+	}
+}
+
+// readOpenFilesLimit parses the line of /proc/(pid)/limits, e.g.
+// Max open files            1024                 524288               files
+func readOpenFilesLimit(filename string) (uint64, uint64) {
+	data, err := readProcFile(filename)
+	if err != nil || data == nil {
+		return 0, 0
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "Max open files") {
+			continue
+		}
+
+		fields := strings.Fields(strings.TrimPrefix(line, "Max open files"))
+		if len(fields) < 2 {
+			return 0, 0
+		}
+		return parseLimit(fields[0]), parseLimit(fields[1])
+	}
+	return 0, 0
+}
+
+// parseLimit returns 0 for unlimited, so that the usage is 0
+func parseLimit(s string) uint64 {
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
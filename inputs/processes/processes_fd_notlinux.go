@@ -0,0 +1,11 @@
+//go:build !linux && !windows
+// +build !linux,!windows
+
+package processes
+
+import (
+	"flashcat.cloud/categraf/types"
+)
+
+func (p *Processes) gatherFd(slist *types.SampleList) {
+}
@@ -6,7 +6,6 @@ package processes
 import (
 	"bytes"
 	"fmt"
-	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -16,16 +15,22 @@ import (
 
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/pkg/osx"
 	"flashcat.cloud/categraf/types"
 )
 
+var processesLog = logger.New("input.processes")
+
 const inputName = "processes"
 
 type Processes struct {
 	config.PluginConfig
 	ForcePS   bool `toml:"force_ps"`
 	ForceProc bool `toml:"force_proc"`
+	// gather fd usage of system and the top processes by fd usage vs rlimit, linux only
+	GatherFd bool `toml:"gather_fd"`
+	FdTopN   int  `toml:"fd_top_n"`
 }
 
 func init() {
@@ -48,7 +53,7 @@ func (p *Processes) Gather(slist *types.SampleList) {
 
 	// Decide if we will use 'ps' to get stats (use procfs otherwise)
 	usePS := true
-	if runtime.GOOS == "linux" {
+	if runtime.GOOS == "linux" || runtime.GOOS == "android" {
 		usePS = false
 	}
 	if p.ForcePS {
@@ -60,15 +65,19 @@ func (p *Processes) Gather(slist *types.SampleList) {
 	// Gather stats from 'ps' or procfs
 	if usePS {
 		if err := p.gatherFromPS(fields); err != nil {
-			log.Println("E! failed to gather from ps:", err)
+			processesLog.Errorf("failed to gather from ps: %v", err)
 		}
 	} else {
 		if err := p.gatherFromProc(fields); err != nil {
-			log.Println("E! failed to gather from proc:", err)
+			processesLog.Errorf("failed to gather from proc: %v", err)
 		}
 	}
 
 	slist.PushSamples(inputName, fields)
+
+	if p.GatherFd {
+		p.gatherFd(slist)
+	}
 }
 
 // Gets empty fields of metrics based on the OS
@@ -90,7 +99,7 @@ func getEmptyFields() map[string]interface{} {
 		fields["idle"] = int64(0)
 	case "openbsd":
 		fields["idle"] = int64(0)
-	case "linux":
+	case "linux", "android":
 		fields["dead"] = int64(0)
 		fields["paging"] = int64(0)
 		fields["total_threads"] = int64(0)
@@ -132,7 +141,7 @@ func (p *Processes) gatherFromPS(fields map[string]interface{}) error {
 		case '?':
 			fields["unknown"] = fields["unknown"].(int64) + int64(1)
 		default:
-			log.Println("W! unknown state:", string(status[0]), "from ps")
+			processesLog.Warnf("unknown state: %v from ps", string(status[0]))
 		}
 		fields["total"] = fields["total"].(int64) + int64(1)
 	}
@@ -189,13 +198,13 @@ func (p *Processes) gatherFromProc(fields map[string]interface{}) error {
 			}
 			fields["parked"] = int64(1)
 		default:
-			log.Println("W! Unknown state:", string(stats[0][0]), "in file:", filename)
+			processesLog.Warnf("Unknown state: %v in file: %v", string(stats[0][0]), filename)
 		}
 		fields["total"] = fields["total"].(int64) + int64(1)
 
 		threads, err := strconv.Atoi(string(stats[17]))
 		if err != nil {
-			log.Println("W! Error parsing thread count:", err)
+			processesLog.Warnf("Error parsing thread count: %v", err)
 			continue
 		}
 		fields["total_threads"] = fields["total_threads"].(int64) + int64(threads)
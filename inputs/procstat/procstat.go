@@ -3,7 +3,6 @@ package procstat
 import (
 	"errors"
 	"fmt"
-	"log"
 	"os/exec"
 	"runtime"
 	"strings"
@@ -13,9 +12,12 @@ import (
 
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/types"
 )
 
+var procstatLog = logger.New("input.procstat")
+
 const inputName = "procstat"
 
 type PID int32
@@ -48,13 +50,13 @@ func (ins *Instance) Init() error {
 
 	if ins.SearchExecSubstring != "" {
 		ins.searchString = ins.SearchExecSubstring
-		log.Println("I! procstat: search_exec_substring:", ins.SearchExecSubstring)
+		procstatLog.Infof("procstat: search_exec_substring: %v", ins.SearchExecSubstring)
 	} else if ins.SearchCmdlineSubstring != "" {
 		ins.searchString = ins.SearchCmdlineSubstring
-		log.Println("I! procstat: search_cmdline_substring:", ins.SearchCmdlineSubstring)
+		procstatLog.Infof("procstat: search_cmdline_substring: %v", ins.SearchCmdlineSubstring)
 	} else if ins.SearchWinService != "" {
 		ins.searchString = ins.SearchWinService
-		log.Println("I! procstat: search_win_service:", ins.SearchWinService)
+		procstatLog.Infof("procstat: search_win_service: %v", ins.SearchWinService)
 	} else {
 		return errors.New("the fields should not be all blank: search_exec_substring, search_cmdline_substring, search_win_service")
 	}
@@ -120,12 +122,12 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 	} else if ins.SearchWinService != "" {
 		pids, err = ins.winServicePIDs()
 	} else {
-		log.Println("E! Oops... search string not found")
+		procstatLog.Errorf("Oops... search string not found")
 		return
 	}
 
 	if err != nil {
-		log.Println("E! procstat: failed to lookup pids, search string:", ins.searchString, "error:", err)
+		procstatLog.Errorf("procstat: failed to lookup pids, search string: %v error: %v", ins.searchString, err)
 		slist.PushFront(types.NewSample(inputName, "lookup_count", 0, tags))
 		return
 	}
@@ -160,7 +162,7 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 		case "jvm":
 			ins.gatherJvm(slist, ins.procs, tags)
 		default:
-			log.Println("E! unknown choice in gather_more_metrics:", field)
+			procstatLog.Errorf("unknown choice in gather_more_metrics: %v", field)
 		}
 	}
 }
@@ -392,7 +394,7 @@ func (ins *Instance) gatherJvm(slist *types.SampleList, procs map[PID]Process, t
 	for pid := range procs {
 		jvmStat, err := execJstat(pid)
 		if err != nil {
-			log.Println("E! failed to exec jstat:", err)
+			procstatLog.Errorf("failed to exec jstat: %v", err)
 			continue
 		}
 
@@ -0,0 +1,185 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/pkg/filter"
+	"flashcat.cloud/categraf/pkg/tls"
+)
+
+// HTTPAuth is the authentication and tls of the scrape requests
+type HTTPAuth struct {
+	BearerTokenString string       `toml:"bearer_token_string"`
+	BearerTokeFile    string       `toml:"bearer_token_file"`
+	Username          string       `toml:"username"`
+	Password          string       `toml:"password"`
+	Headers           []string     `toml:"headers"`
+	OAuth2            OAuth2Config `toml:"oauth2"`
+	tls.ClientConfig
+
+	tokenFile *tokenFile
+	client    *http.Client
+}
+
+// OAuth2Config is the client credentials flow, the token is fetched from token_url and refreshed before expiry
+type OAuth2Config struct {
+	Enabled          bool              `toml:"enabled"`
+	ClientID         string            `toml:"client_id"`
+	ClientSecret     string            `toml:"client_secret"`
+	ClientSecretFile string            `toml:"client_secret_file"`
+	TokenURL         string            `toml:"token_url"`
+	Scopes           []string          `toml:"scopes"`
+	EndpointParams   map[string]string `toml:"endpoint_params"`
+}
+
+// TargetAuth overrides the authentication of the instance for the urls matched
+type TargetAuth struct {
+	// support glob, e.g. https://secure-*.example.com/*
+	URLs []string `toml:"urls"`
+	HTTPAuth
+
+	urlFilter filter.Filter
+}
+
+func (a *HTTPAuth) init(timeout time.Duration, transport *config.TransportOption) error {
+	if len(a.Headers)%2 != 0 {
+		return fmt.Errorf("headers should be pairs of key and value: %v", a.Headers)
+	}
+
+	if a.BearerTokeFile != "" {
+		a.tokenFile = &tokenFile{path: a.BearerTokeFile}
+	}
+
+	trans := &http.Transport{}
+	if a.UseTLS {
+		tlsConfig, err := a.ClientConfig.TLSConfig()
+		if err != nil {
+			return err
+		}
+
+		// the client certificates of mtls are rotated frequently
+		if a.TLSCert != "" && a.TLSKey != "" {
+			if err := tls.ReloadCertificate(tlsConfig, a.TLSCert, a.TLSKey); err != nil {
+				return err
+			}
+		}
+		trans.TLSClientConfig = tlsConfig
+	}
+
+	rt := transport.RoundTripper(trans)
+	a.client = &http.Client{
+		Transport: rt,
+		Timeout:   timeout,
+	}
+
+	if !a.OAuth2.Enabled {
+		return nil
+	}
+
+	source, err := a.OAuth2.tokenSource(&http.Client{Transport: rt, Timeout: timeout})
+	if err != nil {
+		return err
+	}
+
+	a.client.Transport = &oauth2.Transport{Source: source, Base: rt}
+	return nil
+}
+
+func (c *OAuth2Config) tokenSource(client *http.Client) (oauth2.TokenSource, error) {
+	if c.TokenURL == "" {
+		return nil, fmt.Errorf("oauth2 token_url is required")
+	}
+
+	secret := c.ClientSecret
+	if c.ClientSecretFile != "" {
+		bs, err := os.ReadFile(c.ClientSecretFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read oauth2 client_secret_file: %v", err)
+		}
+		secret = strings.TrimSpace(string(bs))
+	}
+
+	params := url.Values{}
+	for k, v := range c.EndpointParams {
+		params.Set(k, v)
+	}
+
+	conf := &clientcredentials.Config{
+		ClientID:       c.ClientID,
+		ClientSecret:   secret,
+		TokenURL:       c.TokenURL,
+		Scopes:         c.Scopes,
+		EndpointParams: params,
+	}
+
+	// the token source is reused by all requests and fetches the token with client
+	return conf.TokenSource(context.WithValue(context.Background(), oauth2.HTTPClient, client)), nil
+}
+
+func (a *HTTPAuth) setHeaders(req *http.Request) {
+	if a.Username != "" && a.Password != "" {
+		req.SetBasicAuth(a.Username, a.Password)
+	}
+
+	token := a.BearerTokenString
+	if a.tokenFile != nil {
+		t, err := a.tokenFile.get()
+		if err != nil {
+			prometheusLog.Errorf("failed to read bearer token file: %v error: %v", a.BearerTokeFile, err)
+		} else {
+			token = t
+		}
+	}
+
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	req.Header.Set("Accept", acceptHeader)
+
+	for i := 0; i+1 < len(a.Headers); i += 2 {
+		req.Header.Set(a.Headers[i], a.Headers[i+1])
+	}
+}
+
+// tokenFile rereads the token once the file is modified, e.g. the projected service account tokens of kubernetes
+type tokenFile struct {
+	sync.Mutex
+	path    string
+	modTime time.Time
+	token   string
+}
+
+func (f *tokenFile) get() (string, error) {
+	st, err := os.Stat(f.path)
+	if err != nil {
+		return "", err
+	}
+
+	f.Lock()
+	defer f.Unlock()
+
+	if f.token != "" && !st.ModTime().After(f.modTime) {
+		return f.token, nil
+	}
+
+	content, err := os.ReadFile(f.path)
+	if err != nil {
+		return "", err
+	}
+
+	f.token = strings.TrimSpace(string(content))
+	f.modTime = st.ModTime()
+	return f.token, nil
+}
@@ -3,7 +3,6 @@ package prometheus
 import (
 	"bytes"
 	"fmt"
-	"log"
 	"net/url"
 	"strings"
 	"text/template"
@@ -92,7 +91,7 @@ func (ins *Instance) UrlsFromConsul() ([]ScrapeUrl, error) {
 	}
 
 	if config.Config.DebugMode {
-		log.Println("D! get urls from consul:", ins.ConsulConfig.Agent)
+		prometheusLog.Debugf("get urls from consul: %v", ins.ConsulConfig.Agent)
 	}
 
 	urlset := map[string]struct{}{}
@@ -112,13 +111,13 @@ func (ins *Instance) UrlsFromConsul() ([]ScrapeUrl, error) {
 
 		if len(consulServices) == 0 {
 			if config.Config.DebugMode {
-				log.Println("D! query consul did not find any instances, service:", q.ServiceName, " tag:", q.ServiceTag)
+				prometheusLog.Debugf("query consul did not find any instances, service: %v  tag: %v", q.ServiceName, q.ServiceTag)
 			}
 			continue
 		}
 
 		if config.Config.DebugMode {
-			log.Println("D! query consul found", len(consulServices), "instances, service:", q.ServiceName, " tag:", q.ServiceTag)
+			prometheusLog.Debugf("query consul found %v instances, service: %v  tag: %v", len(consulServices), q.ServiceName, q.ServiceTag)
 		}
 
 		for _, consulService := range consulServices {
@@ -162,7 +161,7 @@ func (ins *Instance) getConsulServiceURL(q *ConsulQuery, s *api.CatalogService)
 	}
 
 	if config.Config.DebugMode {
-		log.Println("D! found consul service:", serviceURL.String())
+		prometheusLog.Debugf("found consul service: %v", serviceURL.String())
 	}
 
 	return &ScrapeUrl{
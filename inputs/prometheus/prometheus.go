@@ -1,11 +1,13 @@
 package prometheus
 
 import (
+	"fmt"
+	"hash/fnv"
 	"io"
-	"log"
 	"net/http"
 	"net/url"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -14,34 +16,51 @@ import (
 	"flashcat.cloud/categraf/inputs"
 	"flashcat.cloud/categraf/parser/prometheus"
 	"flashcat.cloud/categraf/pkg/filter"
-	"flashcat.cloud/categraf/pkg/tls"
+	"flashcat.cloud/categraf/pkg/httpx"
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/pkg/relabel"
 	"flashcat.cloud/categraf/types"
 )
 
+var prometheusLog = logger.New("input.prometheus")
+
 const inputName = "prometheus"
 const acceptHeader = `application/vnd.google.protobuf;proto=io.prometheus.client.MetricFamily;encoding=delimited;q=0.7,text/plain;version=0.0.4;q=0.3,*/*;q=0.1`
 
 type Instance struct {
 	config.InstanceConfig
 
-	URLs              []string        `toml:"urls"`
-	ConsulConfig      ConsulConfig    `toml:"consul"`
-	NamePrefix        string          `toml:"name_prefix"`
-	BearerTokenString string          `toml:"bearer_token_string"`
-	BearerTokeFile    string          `toml:"bearer_token_file"`
-	Username          string          `toml:"username"`
-	Password          string          `toml:"password"`
-	Timeout           config.Duration `toml:"timeout"`
-	IgnoreMetrics     []string        `toml:"ignore_metrics"`
-	IgnoreLabelKeys   []string        `toml:"ignore_label_keys"`
-	Headers           []string        `toml:"headers"`
+	URLs            []string        `toml:"urls"`
+	ConsulConfig    ConsulConfig    `toml:"consul"`
+	NamePrefix      string          `toml:"name_prefix"`
+	Timeout         config.Duration `toml:"timeout"`
+	IgnoreMetrics   []string        `toml:"ignore_metrics"`
+	IgnoreLabelKeys []string        `toml:"ignore_label_keys"`
+	// spread the scrapes of urls across the interval deterministically, instead of all at once
+	ScrapeSpread bool `toml:"scrape_spread"`
+	// keep the timestamps of the exposition, e.g. of the exporters backfilling or delayed
+	HonorTimestamps bool `toml:"honor_timestamps"`
+	// drop or forward the stale markers of the exposition
+	StaleMarkers string `toml:"stale_markers"`
+	// gather the exemplars of the counters and the buckets, e.g. the trace ids, for the remote write writers
+	Exemplars bool `toml:"exemplars"`
+	// relabel the samples scraped in order, e.g. rename or drop the series of high cardinality
+	MetricRelabelConfigs []*relabel.Config `toml:"metric_relabel_configs"`
+	HTTPAuth
+	config.TransportOption
+	config.ResponseCacheOption
+	prometheus.NativeHistogramOption
+	// the first one matched overrides the authentication above
+	Auths []*TargetAuth `toml:"auths"`
 
 	config.UrlLabel
 
 	ignoreMetricsFilter   filter.Filter
 	ignoreLabelKeysFilter filter.Filter
-	tls.ClientConfig
-	client *http.Client
+	responseCache         *httpx.ResponseCache
+	// the interval of the input, and the window in which the scrapes are spread
+	interval     time.Duration
+	spreadWindow time.Duration
 }
 
 func (ins *Instance) Empty() bool {
@@ -77,13 +96,38 @@ func (ins *Instance) Init() error {
 		ins.Timeout = config.Duration(time.Second * 3)
 	}
 
-	client, err := ins.createHTTPClient()
-	if err != nil {
+	if ins.ScrapeSpread {
+		interval := ins.interval
+		if interval <= 0 {
+			interval = config.GetInterval()
+		}
+		if ins.IntervalTimes > 0 {
+			interval *= time.Duration(ins.IntervalTimes)
+		}
+		// the scrapes are finished before the next round
+		ins.spreadWindow = interval - time.Duration(ins.Timeout)
+	}
+
+	if err := ins.HTTPAuth.init(time.Duration(ins.Timeout), &ins.TransportOption); err != nil {
 		return err
-	} else {
-		ins.client = client
 	}
 
+	for i, a := range ins.Auths {
+		if len(a.URLs) == 0 {
+			return fmt.Errorf("urls of auths[%d] is required", i)
+		}
+
+		var err error
+		if a.urlFilter, err = filter.Compile(a.URLs); err != nil {
+			return fmt.Errorf("invalid urls of auths[%d]: %v", i, err)
+		}
+
+		if err := a.init(time.Duration(ins.Timeout), &ins.TransportOption); err != nil {
+			return fmt.Errorf("auths[%d]: %v", i, err)
+		}
+	}
+
+	var err error
 	if len(ins.IgnoreMetrics) > 0 {
 		ins.ignoreMetricsFilter, err = filter.Compile(ins.IgnoreMetrics)
 		if err != nil {
@@ -102,26 +146,44 @@ func (ins *Instance) Init() error {
 		return err
 	}
 
-	return nil
-}
+	if err := ins.NativeHistogramOption.Validate(); err != nil {
+		return err
+	}
 
-func (ins *Instance) createHTTPClient() (*http.Client, error) {
-	trans := &http.Transport{}
+	if err := prometheus.ValidateStaleMarkers(ins.StaleMarkers); err != nil {
+		return err
+	}
 
-	if ins.UseTLS {
-		tlsConfig, err := ins.ClientConfig.TLSConfig()
-		if err != nil {
-			return nil, err
+	for _, c := range ins.MetricRelabelConfigs {
+		if err := c.Validate(); err != nil {
+			return err
 		}
-		trans.TLSClientConfig = tlsConfig
 	}
 
-	client := &http.Client{
-		Transport: trans,
-		Timeout:   time.Duration(ins.Timeout),
+	ins.responseCache = httpx.NewResponseCache(time.Duration(ins.ResponseCacheTTL), ins.ConditionalRequests)
+
+	return nil
+}
+
+// spreadDelay returns the offset of u in the spread window, which is the same in every round
+func (ins *Instance) spreadDelay(u *url.URL) time.Duration {
+	if ins.spreadWindow <= 0 {
+		return 0
 	}
 
-	return client, nil
+	h := fnv.New64a()
+	h.Write([]byte(u.String()))
+	return time.Duration(h.Sum64() % uint64(ins.spreadWindow))
+}
+
+// auth returns the authentication of the scrape url
+func (ins *Instance) auth(u *url.URL) *HTTPAuth {
+	for _, a := range ins.Auths {
+		if a.urlFilter.Match(u.String()) {
+			return &a.HTTPAuth
+		}
+	}
+	return &ins.HTTPAuth
 }
 
 type Prometheus struct {
@@ -143,6 +205,13 @@ func (p *Prometheus) Name() string {
 	return inputName
 }
 
+func (p *Prometheus) Init() error {
+	for _, ins := range p.Instances {
+		ins.interval = time.Duration(p.Interval)
+	}
+	return nil
+}
+
 func (p *Prometheus) GetInstances() []inputs.Instance {
 	ret := make([]inputs.Instance, len(p.Instances))
 	for i := 0; i < len(p.Instances); i++ {
@@ -158,7 +227,7 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 	for i := 0; i < len(ins.URLs); i++ {
 		u, err := url.Parse(ins.URLs[i])
 		if err != nil {
-			log.Println("E! failed to parse prometheus scrape url:", ins.URLs[i], "error:", err)
+			prometheusLog.Errorf("failed to parse prometheus scrape url: %v error: %v", ins.URLs[i], err)
 			continue
 		}
 
@@ -169,7 +238,7 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 
 	urls, err := ins.UrlsFromConsul()
 	if err != nil {
-		log.Println("E! failed to query urls from consul:", err)
+		prometheusLog.Errorf("failed to query urls from consul: %v", err)
 		return
 	}
 
@@ -184,23 +253,31 @@ func (ins *Instance) gatherUrl(urlwg *sync.WaitGroup, slist *types.SampleList, u
 
 	u := uri.URL
 
+	if delay := ins.spreadDelay(u); delay > 0 {
+		time.Sleep(delay)
+	}
+
 	if u.Path == "" {
 		u.Path = "/metrics"
 	}
 
 	req, err := http.NewRequest("GET", u.String(), nil)
 	if err != nil {
-		log.Println("E! failed to new request for url:", u.String(), "error:", err)
+		prometheusLog.Errorf("failed to new request for url: %v error: %v", u.String(), err)
 		return
 	}
 
-	ins.setHeaders(req)
+	auth := ins.auth(u)
+	auth.setHeaders(req)
+
+	cacheKey := responseCacheKey(u, uri.Tags)
+	ins.responseCache.SetConditionalHeaders(cacheKey, req)
 
 	labels := map[string]string{}
 
 	urlKey, urlVal, err := ins.GenerateLabel(u)
 	if err != nil {
-		log.Println("E! failed to generate url label value:", err)
+		prometheusLog.Errorf("failed to generate url label value: %v", err)
 		return
 	}
 
@@ -210,16 +287,31 @@ func (ins *Instance) gatherUrl(urlwg *sync.WaitGroup, slist *types.SampleList, u
 		labels[key] = val
 	}
 
-	res, err := ins.client.Do(req)
+	start := time.Now()
+	defer func() {
+		slist.PushFront(types.NewSample("", "scrape_duration_seconds", time.Since(start).Seconds(), labels))
+	}()
+
+	res, err := auth.client.Do(req)
 	if err != nil {
 		slist.PushFront(types.NewSample("", "up", 0, labels))
-		log.Println("E! failed to query url:", u.String(), "error:", err)
+		prometheusLog.Errorf("failed to query url: %v error: %v", u.String(), err)
 		return
 	}
 
+	if res.StatusCode == http.StatusNotModified {
+		res.Body.Close()
+		if samples, ok := ins.responseCache.Lookup(cacheKey, res, nil); ok {
+			slist.PushFront(types.NewSample("", "up", 1, labels))
+			slist.PushFrontN(samples)
+			slist.PushFront(types.NewSample("", "scrape_samples_scraped", len(samples), labels))
+			return
+		}
+	}
+
 	if res.StatusCode != http.StatusOK {
 		slist.PushFront(types.NewSample("", "up", 0, labels))
-		log.Println("E! failed to query url:", u.String(), "status code:", res.StatusCode)
+		prometheusLog.Errorf("failed to query url: %v status code: %v", u.String(), res.StatusCode)
 		return
 	}
 
@@ -228,40 +320,51 @@ func (ins *Instance) gatherUrl(urlwg *sync.WaitGroup, slist *types.SampleList, u
 	body, err := io.ReadAll(res.Body)
 	if err != nil {
 		slist.PushFront(types.NewSample("", "up", 0, labels))
-		log.Println("E! failed to read response body, url:", u.String(), "error:", err)
+		prometheusLog.Errorf("failed to read response body, url: %v error: %v", u.String(), err)
 		return
 	}
 
 	slist.PushFront(types.NewSample("", "up", 1, labels))
 
-	parser := prometheus.NewParser(ins.NamePrefix, labels, res.Header, ins.ignoreMetricsFilter, ins.ignoreLabelKeysFilter)
-	if err = parser.Parse(body, slist); err != nil {
-		log.Println("E! failed to parse response body, url:", u.String(), "error:", err)
-	}
-}
-
-func (ins *Instance) setHeaders(req *http.Request) {
-	if ins.Username != "" && ins.Password != "" {
-		req.SetBasicAuth(ins.Username, ins.Password)
-	}
-
-	if ins.BearerTokeFile != "" {
-		content, err := os.ReadFile(ins.BearerTokeFile)
+	samples, cached := ins.responseCache.Lookup(cacheKey, res, body)
+	if !cached {
+		scraped := types.NewSampleList()
+		parser := prometheus.NewParser(ins.NamePrefix, labels, res.Header, ins.ignoreMetricsFilter, ins.ignoreLabelKeysFilter)
+		parser.NativeHistogram = ins.NativeHistogramOption
+		parser.HonorTimestamps = ins.HonorTimestamps
+		parser.StaleMarkers = ins.StaleMarkers
+		parser.Exemplars = ins.Exemplars
+		parser.MetricRelabelConfigs = ins.MetricRelabelConfigs
+		err = parser.Parse(body, scraped)
 		if err != nil {
-			log.Println("E! failed to read bearer token file:", ins.BearerTokeFile, "error:", err)
-			return
+			prometheusLog.Errorf("failed to parse response body, url: %v error: %v", u.String(), err)
+		}
+		samples = scraped.PopBackAll()
+		// the samples of a broken response are not reused
+		if err == nil {
+			ins.responseCache.Store(cacheKey, res, body, samples)
 		}
-
-		ins.BearerTokenString = strings.TrimSpace(string(content))
 	}
+	slist.PushFrontN(samples)
+	slist.PushFront(types.NewSample("", "scrape_samples_scraped", len(samples), labels))
+}
 
-	if ins.BearerTokenString != "" {
-		req.Header.Set("Authorization", "Bearer "+ins.BearerTokenString)
+// responseCacheKey tells the responses of the same url discovered with different tags apart,
+// since the tags are labels of the samples cached
+func responseCacheKey(u *url.URL, tags map[string]string) string {
+	if len(tags) == 0 {
+		return u.String()
 	}
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
 
-	req.Header.Set("Accept", acceptHeader)
-
-	for i := 0; i < len(ins.Headers); i += 2 {
-		req.Header.Set(ins.Headers[i], ins.Headers[i+1])
+	var b strings.Builder
+	b.WriteString(u.String())
+	for _, k := range keys {
+		b.WriteString("|" + k + "=" + tags[k])
 	}
+	return b.String()
 }
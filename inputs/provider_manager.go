@@ -2,7 +2,6 @@ package inputs
 
 import (
 	"fmt"
-	"log"
 	"strings"
 
 	"flashcat.cloud/categraf/config"
@@ -58,7 +57,7 @@ type Provider interface {
 }
 
 func NewProvider(c *config.ConfigType, op InputOperation) (Provider, error) {
-	log.Println("I! use input provider:", c.Global.Providers)
+	inputsLog.Infof("use input provider: %v", c.Global.Providers)
 	// 不添加provider配置 则默认使用local
 	// 兼容老版本
 	if len(c.Global.Providers) == 0 {
@@ -114,7 +113,7 @@ func (pm *ProviderManager) LoadConfig() (bool, error) {
 	for _, p := range pm.providers {
 		ok, err := p.LoadConfig()
 		if err != nil {
-			log.Printf("E! provider manager, LoadConfig of %s err: %s", p.Name(), err)
+			inputsLog.Errorf("provider manager, LoadConfig of %s err: %s", p.Name(), err)
 		} else {
 			changed = changed || ok
 		}
@@ -128,7 +127,7 @@ func (pm *ProviderManager) GetInputs() ([]string, error) {
 	for _, p := range pm.providers {
 		pInputs, err := p.GetInputs()
 		if err != nil {
-			log.Printf("E! provider manager, GetInputs of %s error: %v, skip", p.Name(), err)
+			inputsLog.Errorf("provider manager, GetInputs of %s error: %v, skip", p.Name(), err)
 			continue
 		}
 		for _, inputKey := range pInputs {
@@ -151,7 +150,7 @@ func (pm *ProviderManager) GetInputConfig(inputName string) ([]cfg.ConfigWithFor
 
 		pcwf, err := p.GetInputConfig(inputKey)
 		if err != nil {
-			log.Printf("E! provider manager, failed to get config of %s from %s, error: %s", inputName, p.Name(), err)
+			inputsLog.Errorf("provider manager, failed to get config of %s from %s, error: %s", inputName, p.Name(), err)
 			continue
 		}
 
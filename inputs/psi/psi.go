@@ -0,0 +1,36 @@
+package psi
+
+import (
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/logger"
+)
+
+var psiLog = logger.New("input.psi")
+
+const inputName = "psi"
+
+// PSI gathers the pressure stall information of linux 4.20+, the share of time
+// some or all tasks stalled on cpu, memory, io and irq, a better saturation signal than load
+type PSI struct {
+	config.PluginConfig
+
+	// gather the pressure of cgroups matching the patterns relative to cgroup_root,
+	// e.g. "system.slice/*", cgroup v2 only
+	Cgroups    []string `toml:"cgroups"`
+	CgroupRoot string   `toml:"cgroup_root"`
+}
+
+func init() {
+	inputs.Add(inputName, func() inputs.Input {
+		return &PSI{}
+	})
+}
+
+func (p *PSI) Clone() inputs.Input {
+	return &PSI{}
+}
+
+func (p *PSI) Name() string {
+	return inputName
+}
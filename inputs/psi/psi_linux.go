@@ -0,0 +1,110 @@
+//go:build linux
+// +build linux
+
+package psi
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"flashcat.cloud/categraf/pkg/osx"
+	"flashcat.cloud/categraf/types"
+)
+
+var resources = []string{"cpu", "memory", "io", "irq"}
+
+func (p *PSI) Init() error {
+	if _, err := os.Stat(filepath.Join(osx.GetHostProc(), "pressure")); err != nil {
+		return fmt.Errorf("pressure stall information is not available, linux 4.20+ with psi enabled is required: %v", err)
+	}
+
+	if p.CgroupRoot == "" {
+		p.CgroupRoot = filepath.Join(osx.GetHostSys(), "fs/cgroup")
+	}
+	return nil
+}
+
+func (p *PSI) Gather(slist *types.SampleList) {
+	dir := filepath.Join(osx.GetHostProc(), "pressure")
+	for _, resource := range resources {
+		err := gatherFile(slist, filepath.Join(dir, resource), map[string]string{"resource": resource})
+		// irq is added in linux 6.1
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			psiLog.Errorf("failed to gather pressure of %v error: %v", resource, err)
+		}
+	}
+
+	for _, pattern := range p.Cgroups {
+		dirs, err := filepath.Glob(filepath.Join(p.CgroupRoot, pattern))
+		if err != nil {
+			psiLog.Errorf("invalid cgroup pattern: %v error: %v", pattern, err)
+			continue
+		}
+
+		for _, dir := range dirs {
+			cgroup, err := filepath.Rel(p.CgroupRoot, dir)
+			if err != nil {
+				continue
+			}
+
+			for _, resource := range resources {
+				err := gatherFile(slist, filepath.Join(dir, resource+".pressure"), map[string]string{
+					"resource": resource,
+					"cgroup":   cgroup,
+				})
+				// not a cgroup directory, or the controller is not enabled
+				if err != nil && !errors.Is(err, os.ErrNotExist) {
+					psiLog.Errorf("failed to gather pressure of %v of cgroup %v error: %v", resource, cgroup, err)
+				}
+			}
+		}
+	}
+}
+
+// gatherFile parses the lines like
+// some avg10=0.00 avg60=0.00 avg300=0.00 total=0
+// full avg10=0.00 avg60=0.00 avg300=0.00 total=0
+// the avgs are percentages, total is the stall time in microseconds
+func gatherFile(slist *types.SampleList, file string, tags map[string]string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		parts := strings.Fields(scanner.Text())
+		if len(parts) == 0 || (parts[0] != "some" && parts[0] != "full") {
+			continue
+		}
+
+		fields := make(map[string]interface{}, 4)
+		for _, kv := range parts[1:] {
+			key, value, found := strings.Cut(kv, "=")
+			if !found {
+				continue
+			}
+
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fmt.Errorf("failed to parse %s of %s: %v", key, file, err)
+			}
+
+			switch key {
+			case "avg10", "avg60", "avg300":
+				fields[key] = v
+			case "total":
+				fields["stall_seconds_total"] = v / 1e6
+			}
+		}
+
+		slist.PushSamples(inputName, fields, tags, map[string]string{"type": parts[0]})
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+// +build !linux
+
+package psi
+
+import (
+	"flashcat.cloud/categraf/types"
+)
+
+func (p *PSI) Init() error {
+	return types.ErrInstancesEmpty
+}
+
+func (p *PSI) Gather(slist *types.SampleList) {
+}
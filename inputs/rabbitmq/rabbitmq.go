@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"sync"
 	"time"
@@ -12,10 +11,13 @@ import (
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
 	"flashcat.cloud/categraf/pkg/filter"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/pkg/tls"
 	"flashcat.cloud/categraf/types"
 )
 
+var rabbitmqLog = logger.New("input.rabbitmq")
+
 const inputName = "rabbitmq"
 
 type RabbitMQ struct {
@@ -379,7 +381,7 @@ func (ins *Instance) requestEndpoint(u string) ([]byte, error) {
 	endpoint := ins.URL + u
 
 	if config.Config.DebugMode {
-		log.Println("D! requesting:", endpoint)
+		rabbitmqLog.Debugf("requesting: %v", endpoint)
 	}
 
 	req, err := http.NewRequest("GET", endpoint, nil)
@@ -429,12 +431,12 @@ func gatherOverview(ins *Instance, slist *types.SampleList) {
 
 	err := ins.requestJSON("/api/overview", &overview)
 	if err != nil {
-		log.Println("E! failed to query rabbitmq /api/overview:", err)
+		rabbitmqLog.Errorf("failed to query rabbitmq /api/overview: %v", err)
 		return
 	}
 
 	if overview.QueueTotals == nil || overview.ObjectTotals == nil || overview.MessageStats == nil || overview.Listeners == nil {
-		log.Println("E! wrong answer from rabbitmq. probably auth issue")
+		rabbitmqLog.Errorf("wrong answer from rabbitmq. probably auth issue")
 		return
 	}
 
@@ -480,7 +482,7 @@ func gatherExchanges(ins *Instance, slist *types.SampleList) {
 	exchanges := make([]Exchange, 0)
 	err := ins.requestJSON("/api/exchanges", &exchanges)
 	if err != nil {
-		log.Println("E! failed to query rabbitmq /api/exchanges:", err)
+		rabbitmqLog.Errorf("failed to query rabbitmq /api/exchanges: %v", err)
 		return
 	}
 
@@ -528,7 +530,7 @@ func gatherFederationLinks(ins *Instance, slist *types.SampleList) {
 	federationLinks := make([]FederationLink, 0)
 	err := ins.requestJSON("/api/federation-links", &federationLinks)
 	if err != nil {
-		log.Println("E! failed to query rabbitmq /api/federation-links:", err)
+		rabbitmqLog.Errorf("failed to query rabbitmq /api/federation-links: %v", err)
 		return
 	}
 
@@ -587,7 +589,7 @@ func gatherNodes(ins *Instance, slist *types.SampleList) {
 
 	err := ins.requestJSON("/api/nodes", &allNodes)
 	if err != nil {
-		log.Println("E! failed to query rabbitmq /api/nodes:", err)
+		rabbitmqLog.Errorf("failed to query rabbitmq /api/nodes: %v", err)
 		return
 	}
 
@@ -644,7 +646,7 @@ func gatherNodes(ins *Instance, slist *types.SampleList) {
 			var memory MemoryResponse
 			err = ins.requestJSON("/api/nodes/"+node.Name+"/memory", &memory)
 			if err != nil {
-				log.Println("E! failed to query rabbitmq /api/nodes/"+node.Name+"/memory:", err)
+				rabbitmqLog.Infof("%v %v", "E! failed to query rabbitmq /api/nodes/"+node.Name+"/memory:", err)
 				return
 			}
 
@@ -682,14 +684,14 @@ func gatherNodes(ins *Instance, slist *types.SampleList) {
 							}
 
 							msg := fmt.Sprintf("unknown type %T for %q total memory", x, estimator)
-							log.Println("E!", msg)
+							rabbitmqLog.Errorf("%v", msg)
 						}
 					}
 					if !foundEstimator {
-						log.Println("E! no known memory estimation in", v)
+						rabbitmqLog.Errorf("no known memory estimation in %v", v)
 					}
 				default:
-					log.Println("E! unknown type", memory.Memory.Total, "for total memory")
+					rabbitmqLog.Errorf("unknown type %v for total memory", memory.Memory.Total)
 				}
 			}
 
@@ -730,7 +732,7 @@ func gatherQueues(ins *Instance, slist *types.SampleList) {
 	queues := make([]Queue, 0)
 	err := ins.requestJSON("/api/queues", &queues)
 	if err != nil {
-		log.Println("E! failed to query rabbitmq /api/queues:", err)
+		rabbitmqLog.Errorf("failed to query rabbitmq /api/queues: %v", err)
 		return
 	}
 
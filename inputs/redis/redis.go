@@ -4,7 +4,6 @@ import (
 	"bufio"
 	"context"
 	"fmt"
-	"log"
 	"regexp"
 	"strconv"
 	"strings"
@@ -13,11 +12,14 @@ import (
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
 	"flashcat.cloud/categraf/pkg/conv"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/pkg/tls"
 	"flashcat.cloud/categraf/types"
 	"github.com/go-redis/redis/v8"
 )
 
+var redisLog = logger.New("input.redis")
+
 const inputName = "redis"
 
 var replicationSlaveMetricPrefix = regexp.MustCompile(`^slave\d+`)
@@ -114,7 +116,7 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 	slist.PushFront(types.NewSample(inputName, "ping_use_seconds", time.Since(begun).Seconds(), tags))
 	if err != nil {
 		slist.PushFront(types.NewSample(inputName, "up", 0, tags))
-		log.Println("E! failed to ping redis:", ins.Address, "error:", err)
+		redisLog.Errorf("failed to ping redis: %v error: %v", ins.Address, err)
 		return
 	} else {
 		slist.PushFront(types.NewSample(inputName, "up", 1, tags))
@@ -129,13 +131,13 @@ func (ins *Instance) gatherCommandValues(slist *types.SampleList, tags map[strin
 	for _, cmd := range ins.Commands {
 		val, err := ins.client.Do(context.Background(), cmd.Command...).Result()
 		if err != nil {
-			log.Println("E! failed to exec redis command:", cmd.Command)
+			redisLog.Errorf("failed to exec redis command: %v", cmd.Command)
 			continue
 		}
 
 		fval, err := conv.ToFloat64(val)
 		if err != nil {
-			log.Println("E! failed to convert result of command:", cmd.Command, "error:", err)
+			redisLog.Errorf("failed to convert result of command: %v error: %v", cmd.Command, err)
 			continue
 		}
 
@@ -154,7 +156,7 @@ func (ins *Instance) gatherInfoAll(slist *types.SampleList, tags map[string]stri
 	}
 
 	if err != nil {
-		log.Println("E! failed to call redis `info all`:", err)
+		redisLog.Errorf("failed to call redis `info all`: %v", err)
 		return
 	}
 
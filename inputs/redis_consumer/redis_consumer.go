@@ -0,0 +1,365 @@
+package redis_consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/parser"
+	"flashcat.cloud/categraf/pkg/consumer"
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/pkg/tls"
+	"flashcat.cloud/categraf/types"
+)
+
+var redisConsumerLog = logger.New("input.redis_consumer")
+
+const (
+	inputName = "redis_consumer"
+
+	modeStream = "stream"
+	modePubSub = "pubsub"
+
+	defaultConsumerGroup      = "categraf"
+	defaultPayloadField       = "data"
+	defaultReadCount          = 100
+	defaultBlock              = 5 * time.Second
+	defaultMaxBufferedSamples = 100000
+	retryInterval             = 5 * time.Second
+)
+
+type RedisConsumer struct {
+	config.PluginConfig
+	Instances []*Instance `toml:"instances"`
+}
+
+type Instance struct {
+	config.InstanceConfig
+
+	Address  string `toml:"address"`
+	Username string `toml:"username"`
+	Password string `toml:"password"`
+	DB       int    `toml:"db"`
+	tls.ClientConfig
+
+	// stream | pubsub
+	Mode string `toml:"mode"`
+
+	// stream mode, the messages are acked once parsed
+	Streams       []string `toml:"streams"`
+	ConsumerGroup string   `toml:"consumer_group"`
+	// unique in the consumer group, the hostname by default
+	ConsumerName string `toml:"consumer_name"`
+	// oldest | newest, where to start if the consumer group is created
+	OffsetReset string `toml:"offset_reset"`
+	// the field of the stream entries holding the message
+	PayloadField string          `toml:"payload_field"`
+	ReadCount    int64           `toml:"read_count"`
+	Block        config.Duration `toml:"block"`
+
+	// pubsub mode, patterns are subscribed by PSUBSCRIBE, e.g. metrics.*
+	Channels []string `toml:"channels"`
+	Patterns []string `toml:"patterns"`
+
+	// the samples buffered between gathers, the new ones are dropped once it's full
+	MaxBufferedSamples int `toml:"max_buffered_samples"`
+	// the label of the stream or channel of messages, not labeled if empty
+	SourceLabel string `toml:"source_label"`
+
+	parser.Format
+
+	client *redis.Client
+	// up if the last read of streams succeeded, or the channels are subscribed
+	buffer *consumer.Buffer
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+var _ inputs.SampleGatherer = new(Instance)
+var _ inputs.Input = new(RedisConsumer)
+var _ inputs.InstancesGetter = new(RedisConsumer)
+
+func init() {
+	inputs.Add(inputName, func() inputs.Input {
+		return &RedisConsumer{}
+	})
+}
+
+func (r *RedisConsumer) Clone() inputs.Input {
+	return &RedisConsumer{}
+}
+
+func (r *RedisConsumer) Name() string {
+	return inputName
+}
+
+func (r *RedisConsumer) GetInstances() []inputs.Instance {
+	ret := make([]inputs.Instance, len(r.Instances))
+	for i := 0; i < len(r.Instances); i++ {
+		ret[i] = r.Instances[i]
+	}
+	return ret
+}
+
+func (r *RedisConsumer) Drop() {
+	for i := 0; i < len(r.Instances); i++ {
+		r.Instances[i].stop()
+	}
+}
+
+func (ins *Instance) Init() error {
+	if ins.Address == "" {
+		return types.ErrInstancesEmpty
+	}
+
+	if ins.Mode == "" {
+		ins.Mode = modeStream
+	}
+	switch ins.Mode {
+	case modeStream:
+		if len(ins.Streams) == 0 {
+			return fmt.Errorf("streams is required in stream mode")
+		}
+		if ins.OffsetReset != "" && ins.OffsetReset != "oldest" && ins.OffsetReset != "newest" {
+			return fmt.Errorf("invalid offset_reset %s, oldest or newest", ins.OffsetReset)
+		}
+	case modePubSub:
+		if len(ins.Channels) == 0 && len(ins.Patterns) == 0 {
+			return fmt.Errorf("channels or patterns is required in pubsub mode")
+		}
+	default:
+		return fmt.Errorf("invalid mode %s, stream or pubsub", ins.Mode)
+	}
+
+	if ins.ConsumerGroup == "" {
+		ins.ConsumerGroup = defaultConsumerGroup
+	}
+	if ins.ConsumerName == "" {
+		ins.ConsumerName = config.Config.GetHostname()
+	}
+	if ins.PayloadField == "" {
+		ins.PayloadField = defaultPayloadField
+	}
+	if ins.ReadCount <= 0 {
+		ins.ReadCount = defaultReadCount
+	}
+	if ins.Block <= 0 {
+		ins.Block = config.Duration(defaultBlock)
+	}
+	if ins.MaxBufferedSamples <= 0 {
+		ins.MaxBufferedSamples = defaultMaxBufferedSamples
+	}
+
+	p, err := ins.Format.NewParser()
+	if err != nil {
+		return err
+	}
+
+	redisOptions := &redis.Options{
+		Addr:     ins.Address,
+		Username: ins.Username,
+		Password: ins.Password,
+		DB:       ins.DB,
+		// the blocking reads take longer than the default read timeout
+		ReadTimeout: time.Duration(ins.Block) + 3*time.Second,
+	}
+
+	if ins.UseTLS {
+		tlsConfig, err := ins.TLSConfig()
+		if err != nil {
+			return fmt.Errorf("failed to init tls config: %v", err)
+		}
+		redisOptions.TLSConfig = tlsConfig
+	}
+
+	ins.client = redis.NewClient(redisOptions)
+	ins.buffer = consumer.NewBuffer(inputName, p, ins.MaxBufferedSamples, ins.SourceLabel)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ins.cancel = cancel
+	ins.wg.Add(1)
+	if ins.Mode == modeStream {
+		go ins.consumeStreams(ctx)
+	} else {
+		go ins.consumePubSub(ctx)
+	}
+	return nil
+}
+
+func (ins *Instance) stop() {
+	if ins.cancel == nil {
+		return
+	}
+	ins.cancel()
+	ins.wg.Wait()
+	ins.client.Close()
+}
+
+func (ins *Instance) wait(ctx context.Context) {
+	select {
+	case <-ctx.Done():
+	case <-time.After(retryInterval):
+	}
+}
+
+// createGroups creates the consumer group of streams, and the streams if they don't exist
+func (ins *Instance) createGroups(ctx context.Context) error {
+	start := "$"
+	if ins.OffsetReset == "oldest" {
+		start = "0"
+	}
+
+	for _, stream := range ins.Streams {
+		err := ins.client.XGroupCreateMkStream(ctx, stream, ins.ConsumerGroup, start).Err()
+		if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+			return fmt.Errorf("failed to create consumer group %s of stream %s: %v", ins.ConsumerGroup, stream, err)
+		}
+	}
+	return nil
+}
+
+// consumeStreams reads the pending messages of the consumer first, i.e. read but not acked before the restart,
+// then the new messages
+func (ins *Instance) consumeStreams(ctx context.Context) {
+	defer ins.wg.Done()
+
+	created := false
+	pending := true
+	for ctx.Err() == nil {
+		if !created {
+			if err := ins.createGroups(ctx); err != nil {
+				redisConsumerLog.Errorf("%v", err)
+				ins.buffer.SetUp(false)
+				ins.wait(ctx)
+				continue
+			}
+			created = true
+		}
+
+		id := ">"
+		if pending {
+			id = "0"
+		}
+
+		streams := make([]string, 0, len(ins.Streams)*2)
+		streams = append(streams, ins.Streams...)
+		for range ins.Streams {
+			streams = append(streams, id)
+		}
+
+		result, err := ins.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    ins.ConsumerGroup,
+			Consumer: ins.ConsumerName,
+			Streams:  streams,
+			Count:    ins.ReadCount,
+			Block:    time.Duration(ins.Block),
+		}).Result()
+
+		if err != nil && !errors.Is(err, redis.Nil) {
+			if ctx.Err() != nil {
+				return
+			}
+			redisConsumerLog.Errorf("failed to read streams: %v error: %v", ins.Streams, err)
+			ins.buffer.SetUp(false)
+			// e.g. NOGROUP if the streams are deleted
+			created = false
+			ins.wait(ctx)
+			continue
+		}
+		ins.buffer.SetUp(true)
+
+		total := 0
+		for _, stream := range result {
+			ids := make([]string, 0, len(stream.Messages))
+			for _, msg := range stream.Messages {
+				ids = append(ids, msg.ID)
+				payload, ok := msg.Values[ins.PayloadField].(string)
+				if !ok {
+					ins.buffer.Invalid()
+					continue
+				}
+				ins.handleMessage(stream.Stream, []byte(payload))
+			}
+			total += len(ids)
+
+			if len(ids) > 0 {
+				if err := ins.client.XAck(ctx, stream.Stream, ins.ConsumerGroup, ids...).Err(); err != nil {
+					redisConsumerLog.Errorf("failed to ack messages of stream: %v error: %v", stream.Stream, err)
+				}
+			}
+		}
+
+		// all pending messages are read
+		if pending && total == 0 {
+			pending = false
+		}
+	}
+}
+
+func (ins *Instance) consumePubSub(ctx context.Context) {
+	defer ins.wg.Done()
+
+	for ctx.Err() == nil {
+		var ps *redis.PubSub
+		if len(ins.Patterns) > 0 {
+			ps = ins.client.PSubscribe(ctx, ins.Patterns...)
+			if len(ins.Channels) > 0 {
+				if err := ps.Subscribe(ctx, ins.Channels...); err != nil {
+					redisConsumerLog.Errorf("failed to subscribe channels: %v error: %v", ins.Channels, err)
+				}
+			}
+		} else {
+			ps = ins.client.Subscribe(ctx, ins.Channels...)
+		}
+
+		// waits for the confirmation of subscription
+		if _, err := ps.Receive(ctx); err != nil {
+			ps.Close()
+			if ctx.Err() != nil {
+				return
+			}
+			redisConsumerLog.Errorf("failed to subscribe channels: %v patterns: %v error: %v", ins.Channels, ins.Patterns, err)
+			ins.buffer.SetUp(false)
+			ins.wait(ctx)
+			continue
+		}
+		ins.buffer.SetUp(true)
+
+		// the channel is reconnected by go-redis, and closed once ps is closed
+		ch := ps.Channel()
+	LOOP:
+		for {
+			select {
+			case <-ctx.Done():
+				break LOOP
+			case msg, ok := <-ch:
+				if !ok {
+					break LOOP
+				}
+				ins.handleMessage(msg.Channel, []byte(msg.Payload))
+			}
+		}
+
+		ps.Close()
+		ins.buffer.SetUp(false)
+	}
+}
+
+// handleMessage parses the message into the buffer, the messages failed to parse are counted and skipped
+func (ins *Instance) handleMessage(source string, payload []byte) {
+	if err := ins.buffer.Add(source, payload); err != nil && config.Config.DebugMode {
+		redisConsumerLog.Debugf("failed to parse redis message of: %v error: %v", source, err)
+	}
+}
+
+// Gather drains the samples consumed since last gather
+func (ins *Instance) Gather(slist *types.SampleList) {
+	ins.buffer.Gather(slist, map[string]string{"mode": ins.Mode})
+}
@@ -5,7 +5,6 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"log"
 	"net/url"
 	"strconv"
 	"strings"
@@ -13,11 +12,14 @@ import (
 
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/pkg/tls"
 	"flashcat.cloud/categraf/types"
 	"github.com/go-redis/redis/v8"
 )
 
+var redisSentinelLog = logger.New("input.redis_sentinel")
+
 const inputName = "redis_sentinel"
 const measurementMasters = "redis_sentinel_masters"
 const measurementSentinel = "redis_sentinel"
@@ -131,20 +133,20 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 
 			masters, err := client.gatherMasterStats(slist)
 			if err != nil {
-				log.Println("E! failed to gather master stats:", err)
+				redisSentinelLog.Errorf("failed to gather master stats: %v", err)
 			}
 
 			for _, master := range masters {
 				if err := client.gatherReplicaStats(slist, master); err != nil {
-					log.Println("E! failed to gather replica stats:", err)
+					redisSentinelLog.Errorf("failed to gather replica stats: %v", err)
 				}
 				if err := client.gatherSentinelStats(slist, master); err != nil {
-					log.Println("E! failed to gather sentinel stats:", err)
+					redisSentinelLog.Errorf("failed to gather sentinel stats: %v", err)
 				}
 			}
 
 			if err := client.gatherInfoStats(slist); err != nil {
-				log.Println("E! failed to gather info stats:", err)
+				redisSentinelLog.Errorf("failed to gather info stats: %v", err)
 			}
 		}(slist, client)
 	}
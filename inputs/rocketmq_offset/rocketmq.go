@@ -4,15 +4,17 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
-	"log"
 	"net/http"
 	"strings"
 
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/types"
 )
 
+var rocketmqOffsetLog = logger.New("input.rocketmq_offset")
+
 const inputName = "rocketmq_offset"
 
 const consoleSchema string = "http://"
@@ -63,7 +65,7 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 	// 获取rocketmq集群中的topicNameList
 	topicNameArray := GetTopicNameList(ins.RocketMQConsoleIPAndPort)
 	if topicNameArray == nil {
-		log.Println("E! fail to get topic,please check config!")
+		rocketmqOffsetLog.Errorf("fail to get topic,please check config!")
 		return
 	}
 
@@ -205,8 +207,8 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 				}
 
 				// 按照queueId进行msgDiff聚合
-				queuestr := brokerName + ":" + string(queueId)
-				if _, ok := diff_Queue_Map[string(queueId)]; ok {
+				queuestr := brokerName + ":" + string(rune(queueId))
+				if _, ok := diff_Queue_Map[string(rune(queueId))]; ok {
 					diff_Queue_Map[queuestr].Diff = diff_Queue_Map[queuestr].Diff + diff
 				} else {
 					var diff_Queue *MsgDiffQueue = new(MsgDiffQueue)
@@ -287,7 +289,7 @@ func GetTopicNameList(rocketmqConsoleIPAndPort string) []string {
 	err := json.Unmarshal([]byte(content), &jsonData)
 
 	if err != nil {
-		log.Println("E! unable to decode topic name list", err)
+		rocketmqOffsetLog.Errorf("unable to decode topic name list %v", err)
 		return nil
 	}
 
@@ -322,7 +324,7 @@ func doRequest(url string) []byte {
 	body, err := ioutil.ReadAll(res.Body)
 
 	if err != nil {
-		log.Println("E! fail to read request data", err)
+		rocketmqOffsetLog.Errorf("fail to read request data %v", err)
 		return nil
 	} else {
 		return body
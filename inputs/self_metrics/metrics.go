@@ -1,17 +1,18 @@
 package categraf
 
 import (
-	"log"
-
 	"github.com/prometheus/client_golang/prometheus"
 	dto "github.com/prometheus/client_model/go"
 
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/pkg/metrics"
 	"flashcat.cloud/categraf/types"
 )
 
+var categrafLog = logger.New("input.self_metrics")
+
 const (
 	inputName     = `self_metrics`
 	description   = `categraf self monitoring data`
@@ -39,7 +40,7 @@ func (pt *Categraf) Name() string {
 func (ins *Categraf) Gather(slist *types.SampleList) {
 	mfs, err := prometheus.DefaultGatherer.Gather()
 	if err != nil {
-		log.Println(err)
+		categrafLog.Infof("%v", err)
 		return
 	}
 	for _, mf := range mfs {
@@ -0,0 +1,249 @@
+package snmp
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+
+	"flashcat.cloud/categraf/pkg/state"
+	"flashcat.cloud/categraf/types"
+)
+
+// the columns of IF-MIB, the rows of ifTable and ifXTable are indexed by ifIndex
+const (
+	ifTableOid      = ".1.3.6.1.2.1.2.2.1"
+	ifXTableOid     = ".1.3.6.1.2.1.31.1.1.1"
+	ifDescrOid      = ifTableOid + ".2"
+	ifOperStatusOid = ifTableOid + ".8"
+	ifNameOid       = ifXTableOid + ".1"
+	ifAliasOid      = ifXTableOid + ".18"
+)
+
+// the interface tags and their columns, the agents without ifXTable have ifDescr only
+var interfaceTagOids = []struct {
+	tag string
+	oid string
+}{
+	{"ifName", ifNameOid},
+	{"ifAlias", ifAliasOid},
+	{"ifDescr", ifDescrOid},
+}
+
+// ifOperStatus of IF-MIB
+var operStatusNames = map[int64]string{
+	1: "up",
+	2: "down",
+	3: "testing",
+	4: "unknown",
+	5: "dormant",
+	6: "notPresent",
+	7: "lowerLayerDown",
+}
+
+// interfaceState is the interfaces of an agent, used by the goroutine of the agent only
+type interfaceState struct {
+	refreshed time.Time
+	// ifIndex -> ifName, ifAlias and ifDescr
+	tags map[string]map[string]string
+	// ifIndex -> ifOperStatus of the last gather
+	operStatus map[string]int64
+	// ifIndex -> count of the changes of ifOperStatus since categraf started
+	changes map[string]uint64
+}
+
+// savedInterfaces is the interfaceState of an agent persisted across restarts, so the interface tags are not
+// walked again by every restart, and the changes of ifOperStatus while categraf is down are still emitted
+type savedInterfaces struct {
+	Refreshed  time.Time                    `json:"refreshed"`
+	Tags       map[string]map[string]string `json:"tags"`
+	OperStatus map[string]int64             `json:"oper_status"`
+}
+
+func newInterfaceState(agent string) *interfaceState {
+	s := &interfaceState{
+		tags:       map[string]map[string]string{},
+		operStatus: map[string]int64{},
+		changes:    map[string]uint64{},
+	}
+
+	var saved savedInterfaces
+	if state.Get(inputName, interfacesStateKey(agent), &saved, 0) {
+		s.refreshed = saved.Refreshed
+		if saved.Tags != nil {
+			s.tags = saved.Tags
+		}
+		if saved.OperStatus != nil {
+			s.operStatus = saved.OperStatus
+		}
+	}
+	return s
+}
+
+func interfacesStateKey(agent string) string {
+	return "interfaces_" + agent
+}
+
+func (s *interfaceState) save(agent string) {
+	err := state.Put(inputName, interfacesStateKey(agent), savedInterfaces{
+		Refreshed:  s.refreshed,
+		Tags:       s.tags,
+		OperStatus: s.operStatus,
+	})
+	if err != nil {
+		snmpLog.Warnf("failed to save interfaces of agent %v error: %v", agent, err)
+	}
+}
+
+// isInterfaceTable tells if the rows of t are indexed by ifIndex, i.e. all the fields are columns of ifTable
+// and ifXTable, or t is one of the interface_tables
+func (ins *Instance) isInterfaceTable(t Table) bool {
+	for _, name := range ins.InterfaceTables {
+		if t.Name == name {
+			return true
+		}
+	}
+	if len(t.Fields) == 0 {
+		return false
+	}
+	for _, f := range t.Fields {
+		if f.OidIndexSuffix != "" || f.OidIndexLength != 0 || f.SecondaryIndexTable || f.SecondaryIndexUse {
+			return false
+		}
+		oid := "." + strings.TrimPrefix(f.Oid, ".")
+		if !strings.HasPrefix(oid, ifTableOid+".") && !strings.HasPrefix(oid, ifXTableOid+".") {
+			return false
+		}
+	}
+	return true
+}
+
+// refresh walks the interface tags once in interface_cache_ttl, as the names of interfaces rarely change
+func (s *interfaceState) refresh(gs snmpConnection, ttl time.Duration) {
+	if !s.refreshed.IsZero() && time.Since(s.refreshed) < ttl {
+		return
+	}
+
+	tags := map[string]map[string]string{}
+	walked := false
+	for _, c := range interfaceTagOids {
+		values, err := walkColumn(gs, c.oid)
+		if err != nil {
+			snmpLog.Warnf("failed to walk %v of agent %v error: %v", c.tag, gs.Host(), err)
+			continue
+		}
+		walked = true
+		for idx, v := range values {
+			name, ok := v.(string)
+			if !ok || name == "" {
+				continue
+			}
+			if tags[idx] == nil {
+				tags[idx] = map[string]string{}
+			}
+			tags[idx][c.tag] = name
+		}
+	}
+
+	// the cache of the last refresh is kept if the agent is unreachable, and the walks are retried next gather
+	if !walked {
+		return
+	}
+	s.tags = tags
+	s.refreshed = time.Now()
+}
+
+// tag adds the interface tags of the row of ifIndex idx, the tags of the fields of the table are kept
+func (s *interfaceState) tag(idx string, tags map[string]string) {
+	for k, v := range s.tags[idx] {
+		if _, has := tags[k]; !has {
+			tags[k] = v
+		}
+	}
+}
+
+// gatherOperStatus walks ifOperStatus, and emits a sample of every change since the last gather, so flaps are
+// alertable without comparing the samples of the gathers
+func (ins *Instance) gatherOperStatus(slist *types.SampleList, gs snmpConnection, s *interfaceState) {
+	values, err := walkColumn(gs, ifOperStatusOid)
+	if err != nil {
+		snmpLog.Errorf("failed to walk ifOperStatus of agent %v error: %v", gs.Host(), err)
+		return
+	}
+
+	current := make(map[string]int64, len(values))
+	for idx, v := range values {
+		status, ok := toInt64(v)
+		if !ok {
+			continue
+		}
+		current[idx] = status
+
+		tags := map[string]string{"ifIndex": idx, ins.AgentHostTag: gs.Host()}
+		s.tag(idx, tags)
+		slist.PushSample(inputName, "if_oper_status", status, tags)
+
+		if last, has := s.operStatus[idx]; has && last != status {
+			s.changes[idx]++
+			slist.PushSample(inputName, "if_oper_status_change", status, tags, map[string]string{
+				"previous": operStatusName(last),
+				"current":  operStatusName(status),
+			})
+		}
+		slist.PushSample(inputName, "if_oper_status_changes_total", s.changes[idx], tags)
+	}
+
+	// the interfaces removed are forgotten
+	for idx := range s.changes {
+		if _, has := current[idx]; !has {
+			delete(s.changes, idx)
+		}
+	}
+	s.operStatus = current
+}
+
+func operStatusName(status int64) string {
+	if name, has := operStatusNames[status]; has {
+		return name
+	}
+	return fmt.Sprint(status)
+}
+
+// walkColumn returns the values of a column by index, the leading dot of indexes is trimmed, e.g. 5 of ifIndex 5
+func walkColumn(gs snmpConnection, oid string) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	err := gs.Walk(oid, func(ent gosnmp.SnmpPDU) error {
+		if len(ent.Name) <= len(oid) || ent.Name[:len(oid)+1] != oid+"." {
+			return &walkError{} // break the walk
+		}
+		v, err := fieldConvert("", ent.Value)
+		if err != nil {
+			return nil
+		}
+		values[ent.Name[len(oid)+1:]] = v
+		return nil
+	})
+	if _, ok := err.(*walkError); err != nil && !ok {
+		return nil, err
+	}
+	return values, nil
+}
+
+func toInt64(v interface{}) (int64, bool) {
+	switch vt := v.(type) {
+	case int:
+		return int64(vt), true
+	case int32:
+		return int64(vt), true
+	case int64:
+		return vt, true
+	case uint:
+		return int64(vt), true
+	case uint32:
+		return int64(vt), true
+	case uint64:
+		return int64(vt), true
+	}
+	return 0, false
+}
@@ -4,7 +4,7 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
-	"log" //nolint:revive
+	//nolint:revive
 	"os/exec"
 	"strings"
 	"sync"
@@ -40,7 +40,7 @@ func execCmd(arg0 string, args ...string) ([]byte, error) {
 		for _, arg := range args {
 			quoted = append(quoted, fmt.Sprintf("%q", arg))
 		}
-		log.Printf("D! [inputs.snmp] executing %q %s", arg0, strings.Join(quoted, " "))
+		snmpLog.Debugf("[inputs.snmp] executing %q %s", arg0, strings.Join(quoted, " "))
 	}
 
 	out, err := execCommand(arg0, args...).Output()
@@ -58,6 +58,7 @@ var snmpTableCachesLock sync.Mutex
 
 // snmpTable resolves the given OID as a table, providing information about the
 // table and fields within.
+//
 //nolint:revive
 func (n *netsnmpTranslator) SnmpTable(oid string) (
 	mibName string, oidNum string, oidText string,
@@ -154,6 +155,7 @@ var snmpTranslateCachesLock sync.Mutex
 var snmpTranslateCaches map[string]snmpTranslateCache
 
 // snmpTranslate resolves the given OID.
+//
 //nolint:revive
 func (n *netsnmpTranslator) SnmpTranslate(oid string) (
 	mibName string, oidNum string, oidText string,
@@ -4,7 +4,6 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
-	"log"
 	"math"
 	"net"
 	"strconv"
@@ -16,9 +15,12 @@ import (
 
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/types"
 )
 
+var snmpLog = logger.New("input.snmp")
+
 const inputName = `snmp`
 const description = `Retrieves SNMP values from remote agents`
 const sampleConfig = `
@@ -149,7 +151,18 @@ type Instance struct {
 	Name   string  `toml:"name"`
 	Fields []Field `toml:"field"`
 
+	// tag the rows of the interface tables, i.e. of ifTable, ifXTable and interface_tables, by ifName,
+	// ifAlias and ifDescr, which are walked once in interface_cache_ttl
+	InterfaceTags bool `toml:"interface_tags"`
+	// the tables indexed by ifIndex besides ifTable and ifXTable, e.g. of the vendor MIBs
+	InterfaceTables   []string        `toml:"interface_tables"`
+	InterfaceCacheTTL config.Duration `toml:"interface_cache_ttl"`
+	// walk ifOperStatus in every gather, and emit the samples of its changes
+	InterfaceStatusEvents bool `toml:"interface_status_events"`
+
 	connectionCache []snmpConnection
+	// the interfaces of the agents, by the index of agents like connectionCache
+	interfaces []*interfaceState
 
 	translator Translator
 }
@@ -168,11 +181,19 @@ func (ins *Instance) Init() error {
 	}
 
 	ins.connectionCache = make([]snmpConnection, len(ins.Agents))
+	ins.interfaces = make([]*interfaceState, len(ins.Agents))
+	for i := range ins.interfaces {
+		ins.interfaces[i] = newInterfaceState(ins.Agents[i])
+	}
+	if ins.InterfaceCacheTTL <= 0 {
+		ins.InterfaceCacheTTL = config.Duration(time.Hour)
+	}
 
 	for i := range ins.Tables {
 		if err := ins.Tables[i].Init(ins.translator); err != nil {
 			return fmt.Errorf("initializing table %s ins: %s", ins.Tables[i].Name, err)
 		}
+		ins.Tables[i].interfaceIndexed = ins.isInterfaceTable(ins.Tables[i])
 	}
 
 	for i := range ins.Fields {
@@ -208,6 +229,8 @@ type Table struct {
 	Oid string `toml:"oid"`
 
 	initialized bool `toml:"initialized"`
+	// the rows are indexed by ifIndex, and tagged by the interface tags if interface_tags
+	interfaceIndexed bool
 }
 
 // Init builds & initializes the nested fields.
@@ -362,6 +385,8 @@ type RTable struct {
 // RTableRow is the resulting row containing all the OID values which shared
 // the same index.
 type RTableRow struct {
+	// Index is the table OID index of the row without the leading dot, empty for the top-level fields.
+	Index string `toml:"index"`
 	// Tags are all the Field values which had IsTag=true.
 	Tags map[string]string `toml:"tags"`
 	// Fields are all the Field values which had IsTag=false.
@@ -424,7 +449,7 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 			defer wg.Done()
 			gs, err := ins.getConnection(i)
 			if err != nil {
-				log.Printf("agent %s ins: %s", agent, err)
+				snmpLog.Infof("agent %s ins: %s", agent, err)
 				return
 			}
 
@@ -434,22 +459,35 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 				Fields: ins.Fields,
 			}
 			topTags := map[string]string{}
-			if err := ins.gatherTable(slist, gs, t, topTags, false); err != nil {
-				log.Printf("agent %s ins: %s", agent, err)
+			if err := ins.gatherTable(slist, gs, t, topTags, false, nil); err != nil {
+				snmpLog.Infof("agent %s ins: %s", agent, err)
+			}
+
+			var ifs *interfaceState
+			if ins.InterfaceTags || ins.InterfaceStatusEvents {
+				ifs = ins.interfaces[i]
+				ifs.refresh(gs, time.Duration(ins.InterfaceCacheTTL))
 			}
 
 			// Now is the real tables.
 			for _, t := range ins.Tables {
-				if err := ins.gatherTable(slist, gs, t, topTags, true); err != nil {
-					log.Printf("agent %s ins: gathering table %s error: %s", agent, t.Name, err)
+				if err := ins.gatherTable(slist, gs, t, topTags, true, ifs); err != nil {
+					snmpLog.Infof("agent %s ins: gathering table %s error: %s", agent, t.Name, err)
 				}
 			}
+
+			if ins.InterfaceStatusEvents {
+				ins.gatherOperStatus(slist, gs, ifs)
+			}
+			if ifs != nil {
+				ifs.save(agent)
+			}
 		}(i, agent)
 	}
 	wg.Wait()
 }
 
-func (ins *Instance) gatherTable(slist *types.SampleList, gs snmpConnection, t Table, topTags map[string]string, walk bool) error {
+func (ins *Instance) gatherTable(slist *types.SampleList, gs snmpConnection, t Table, topTags map[string]string, walk bool, ifs *interfaceState) error {
 	rt, err := t.Build(gs, walk, ins.translator)
 	if err != nil {
 		return err
@@ -476,6 +514,9 @@ func (ins *Instance) gatherTable(slist *types.SampleList, gs snmpConnection, t T
 		if _, ok := tr.Tags[ins.AgentHostTag]; !ok {
 			tr.Tags[ins.AgentHostTag] = gs.Host()
 		}
+		if ins.InterfaceTags && t.interfaceIndexed && ifs != nil {
+			ifs.tag(tr.Index, tr.Tags)
+		}
 		slist.PushSamples(prefix, tr.Fields, tr.Tags)
 	}
 
@@ -548,7 +589,7 @@ func (t Table) Build(gs snmpConnection, walk bool, tr Translator) (*RTable, erro
 				}
 				ifv[""] = fv
 			} else {
-				log.Println("W! no info for oid", oid)
+				snmpLog.Warnf("no info for oid %v", oid)
 			}
 		} else {
 			err := gs.Walk(oid, func(ent gosnmp.SnmpPDU) error {
@@ -622,6 +663,7 @@ func (t Table) Build(gs snmpConnection, walk bool, tr Translator) (*RTable, erro
 			rtr, ok := rows[idx]
 			if !ok {
 				rtr = RTableRow{}
+				rtr.Index = strings.TrimPrefix(idx, ".")
 				rtr.Tags = map[string]string{}
 				rtr.Fields = map[string]interface{}{}
 				rows[idx] = rtr
@@ -2,7 +2,6 @@ package sockstat
 
 import (
 	"errors"
-	"log"
 	"os"
 	"strings"
 
@@ -10,9 +9,12 @@ import (
 
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/types"
 )
 
+var sockstatLog = logger.New("input.sockstat")
+
 const inputName = "sockstat"
 
 func init() {
@@ -59,7 +61,7 @@ type NetSockstatProtocol struct {
 func (ss *SockStat) Gather(slist *types.SampleList) {
 	ns, err := ParseNetSockstat()
 	if err != nil {
-		log.Println("E! failed to get net sockstat: ", err)
+		sockstatLog.Errorf("failed to get net sockstat:  %v", err)
 		return
 	}
 	ss.parse(ns, slist)
@@ -67,11 +69,11 @@ func (ss *SockStat) Gather(slist *types.SampleList) {
 	ns6, err := ParseNetSockstat6()
 	if err != nil {
 		if config.Config.DebugMode {
-			log.Println("D! failed to get net sockstat6: ", err)
+			sockstatLog.Debugf("failed to get net sockstat6:  %v", err)
 			return
 		}
 		if !errors.Is(err, os.ErrNotExist) {
-			log.Println("E! failed to get net sockstat6: ", err)
+			sockstatLog.Errorf("failed to get net sockstat6:  %v", err)
 			return
 		}
 	}
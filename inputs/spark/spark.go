@@ -0,0 +1,258 @@
+package spark
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/httpx"
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/pkg/tls"
+	"flashcat.cloud/categraf/types"
+)
+
+var sparkLog = logger.New("input.spark")
+
+const (
+	inputName = "spark"
+
+	apiPrefix = "/api/v1/applications"
+	yarnApps  = "/ws/v1/cluster/apps"
+)
+
+type Spark struct {
+	config.PluginConfig
+	Instances []*Instance `toml:"instances"`
+}
+
+type Instance struct {
+	config.InstanceConfig
+
+	// the web ui of drivers, e.g. http://driver:4040, or the history server, e.g. http://history:18080,
+	// the running applications are gathered
+	URLs []string `toml:"urls"`
+	// the resourcemanager the running spark applications are discovered from, e.g. http://resourcemanager:8088,
+	// the applications are requested through the proxy of resourcemanager
+	YarnURL string          `toml:"yarn_url"`
+	Timeout config.Duration `toml:"timeout"`
+	tls.ClientConfig
+
+	client *http.Client
+}
+
+type application struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type executor struct {
+	ID                string  `json:"id"`
+	IsActive          bool    `json:"isActive"`
+	ActiveTasks       float64 `json:"activeTasks"`
+	FailedTasks       float64 `json:"failedTasks"`
+	CompletedTasks    float64 `json:"completedTasks"`
+	TotalDuration     float64 `json:"totalDuration"`
+	TotalGCTime       float64 `json:"totalGCTime"`
+	MemoryUsed        float64 `json:"memoryUsed"`
+	MaxMemory         float64 `json:"maxMemory"`
+	DiskUsed          float64 `json:"diskUsed"`
+	TotalInputBytes   float64 `json:"totalInputBytes"`
+	TotalShuffleRead  float64 `json:"totalShuffleRead"`
+	TotalShuffleWrite float64 `json:"totalShuffleWrite"`
+}
+
+var _ inputs.SampleGatherer = new(Instance)
+var _ inputs.Input = new(Spark)
+var _ inputs.InstancesGetter = new(Spark)
+
+func init() {
+	inputs.Add(inputName, func() inputs.Input {
+		return &Spark{}
+	})
+}
+
+func (s *Spark) Clone() inputs.Input {
+	return &Spark{}
+}
+
+func (s *Spark) Name() string {
+	return inputName
+}
+
+func (s *Spark) GetInstances() []inputs.Instance {
+	ret := make([]inputs.Instance, len(s.Instances))
+	for i := 0; i < len(s.Instances); i++ {
+		ret[i] = s.Instances[i]
+	}
+	return ret
+}
+
+func (ins *Instance) Init() error {
+	if len(ins.URLs) == 0 && ins.YarnURL == "" {
+		return types.ErrInstancesEmpty
+	}
+	if ins.Timeout <= 0 {
+		ins.Timeout = config.Duration(5 * time.Second)
+	}
+	ins.YarnURL = strings.TrimRight(ins.YarnURL, "/")
+
+	tlsConfig, err := ins.ClientConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+	ins.client = &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		},
+		Timeout: time.Duration(ins.Timeout),
+	}
+	return nil
+}
+
+func (ins *Instance) Gather(slist *types.SampleList) {
+	var wg sync.WaitGroup
+	for _, u := range ins.URLs {
+		wg.Add(1)
+		go func(u string) {
+			defer wg.Done()
+			ins.gatherURL(slist, strings.TrimRight(u, "/"))
+		}(u)
+	}
+
+	if ins.YarnURL != "" {
+		for _, u := range ins.discoverYarn(slist) {
+			wg.Add(1)
+			go func(u string) {
+				defer wg.Done()
+				ins.gatherURL(slist, u)
+			}(u)
+		}
+	}
+	wg.Wait()
+}
+
+// discoverYarn returns the proxy urls of the running spark applications of yarn
+func (ins *Instance) discoverYarn(slist *types.SampleList) []string {
+	var apps struct {
+		Apps struct {
+			App []struct {
+				ID          string `json:"id"`
+				TrackingURL string `json:"trackingUrl"`
+			} `json:"app"`
+		} `json:"apps"`
+	}
+
+	query := url.Values{"states": {"RUNNING"}, "applicationTypes": {"SPARK"}}
+	if err := ins.get(ins.YarnURL+yarnApps+"?"+query.Encode(), &apps); err != nil {
+		sparkLog.Errorf("failed to list spark applications of yarn: %v error: %v", ins.YarnURL, err)
+		slist.PushSample(inputName, "yarn_up", 0, map[string]string{"url": ins.YarnURL})
+		return nil
+	}
+	slist.PushSample(inputName, "yarn_up", 1, map[string]string{"url": ins.YarnURL})
+
+	urls := make([]string, 0, len(apps.Apps.App))
+	for _, app := range apps.Apps.App {
+		if app.TrackingURL == "" {
+			urls = append(urls, ins.YarnURL+"/proxy/"+app.ID)
+		} else {
+			urls = append(urls, strings.TrimRight(app.TrackingURL, "/"))
+		}
+	}
+	return urls
+}
+
+func (ins *Instance) gatherURL(slist *types.SampleList, u string) {
+	tags := map[string]string{"url": u}
+
+	var apps []application
+	if err := ins.get(u+apiPrefix+"?status=running", &apps); err != nil {
+		sparkLog.Errorf("failed to list spark applications: %v error: %v", u, err)
+		slist.PushSample(inputName, "up", 0, tags)
+		return
+	}
+	slist.PushSample(inputName, "up", 1, tags)
+	slist.PushSample(inputName, "running_applications", len(apps), tags)
+
+	for _, app := range apps {
+		ins.gatherApplication(slist, u, app)
+	}
+}
+
+func (ins *Instance) gatherApplication(slist *types.SampleList, u string, app application) {
+	tags := map[string]string{"url": u, "app_id": app.ID, "app_name": app.Name}
+	base := u + apiPrefix + "/" + url.PathEscape(app.ID)
+
+	var executors []executor
+	if err := ins.get(base+"/executors", &executors); err != nil {
+		sparkLog.Errorf("failed to get executors of spark application: %v error: %v", app.ID, err)
+		return
+	}
+
+	var active, activeTasks, failedTasks, completedTasks, duration, gcTime float64
+	var memoryUsed, maxMemory, diskUsed, inputBytes, shuffleRead, shuffleWrite float64
+	for _, e := range executors {
+		// the driver is listed as an executor
+		if e.IsActive && e.ID != "driver" {
+			active++
+		}
+		activeTasks += e.ActiveTasks
+		failedTasks += e.FailedTasks
+		completedTasks += e.CompletedTasks
+		duration += e.TotalDuration
+		gcTime += e.TotalGCTime
+		memoryUsed += e.MemoryUsed
+		maxMemory += e.MaxMemory
+		diskUsed += e.DiskUsed
+		inputBytes += e.TotalInputBytes
+		shuffleRead += e.TotalShuffleRead
+		shuffleWrite += e.TotalShuffleWrite
+	}
+
+	slist.PushSamples(inputName+"_app", map[string]interface{}{
+		"active_executors":            active,
+		"active_tasks":                activeTasks,
+		"failed_tasks_total":          failedTasks,
+		"completed_tasks_total":       completedTasks,
+		"task_duration_seconds_total": duration / 1000,
+		"gc_time_seconds_total":       gcTime / 1000,
+		"memory_used_bytes":           memoryUsed,
+		"max_memory_bytes":            maxMemory,
+		"disk_used_bytes":             diskUsed,
+		"input_bytes_total":           inputBytes,
+		"shuffle_read_bytes_total":    shuffleRead,
+		"shuffle_write_bytes_total":   shuffleWrite,
+	}, tags)
+
+	for _, status := range []string{"running", "failed"} {
+		var jobs []json.RawMessage
+		if err := ins.get(base+"/jobs?status="+status, &jobs); err != nil {
+			sparkLog.Errorf("failed to get jobs of spark application: %v error: %v", app.ID, err)
+			continue
+		}
+		slist.PushSample(inputName+"_app", "jobs", len(jobs), tags, map[string]string{"status": status})
+	}
+
+	var stages []json.RawMessage
+	if err := ins.get(base+"/stages?status=active", &stages); err != nil {
+		sparkLog.Errorf("failed to get stages of spark application: %v error: %v", app.ID, err)
+		return
+	}
+	slist.PushSample(inputName+"_app", "active_stages", len(stages), tags)
+}
+
+func (ins *Instance) get(u string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	_, err = httpx.DoJSON(ins.client, req, u, v)
+	return err
+}
@@ -0,0 +1,258 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/conv"
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/pkg/tagx"
+	"flashcat.cloud/categraf/types"
+)
+
+var sqliteLog = logger.New("input.sqlite")
+
+const inputName = "sqlite"
+
+type QueryConfig struct {
+	Mesurement    string          `toml:"mesurement"`
+	LabelFields   []string        `toml:"label_fields"`
+	MetricFields  []string        `toml:"metric_fields"`
+	FieldToAppend string          `toml:"field_to_append"`
+	Timeout       config.Duration `toml:"timeout"`
+	Request       string          `toml:"request"`
+}
+
+type Instance struct {
+	config.InstanceConfig
+
+	// the sqlite database file, opened read-only
+	Path string `toml:"path"`
+	// wait for the locks of the writers of the app, instead of failing with SQLITE_BUSY
+	BusyTimeout config.Duration `toml:"busy_timeout"`
+	Queries     []QueryConfig   `toml:"queries"`
+
+	dsn string
+}
+
+type SQLite struct {
+	config.PluginConfig
+	Instances []*Instance `toml:"instances"`
+}
+
+func init() {
+	inputs.Add(inputName, func() inputs.Input {
+		return &SQLite{}
+	})
+}
+
+func (s *SQLite) Clone() inputs.Input {
+	return &SQLite{}
+}
+
+func (s *SQLite) Name() string {
+	return inputName
+}
+
+func (s *SQLite) GetInstances() []inputs.Instance {
+	ret := make([]inputs.Instance, len(s.Instances))
+	for i := 0; i < len(s.Instances); i++ {
+		ret[i] = s.Instances[i]
+	}
+	return ret
+}
+
+func (ins *Instance) Init() error {
+	if ins.Path == "" || len(ins.Queries) == 0 {
+		return types.ErrInstancesEmpty
+	}
+
+	if ins.BusyTimeout <= 0 {
+		ins.BusyTimeout = config.Duration(5 * time.Second)
+	}
+	for i := range ins.Queries {
+		if ins.Queries[i].Timeout <= 0 {
+			ins.Queries[i].Timeout = config.Duration(5 * time.Second)
+		}
+		for j := range ins.Queries[i].LabelFields {
+			ins.Queries[i].LabelFields[j] = strings.ToLower(ins.Queries[i].LabelFields[j])
+		}
+		for j := range ins.Queries[i].MetricFields {
+			ins.Queries[i].MetricFields[j] = strings.ToLower(ins.Queries[i].MetricFields[j])
+		}
+	}
+
+	// mode=ro never creates the file nor writes it, and _query_only rejects the writes of the queries,
+	// e.g. of a mistaken delete, the database in WAL mode is read without blocking the writers of the app
+	params := url.Values{}
+	params.Set("mode", "ro")
+	params.Set("_query_only", "1")
+	params.Set("_busy_timeout", fmt.Sprint(time.Duration(ins.BusyTimeout).Milliseconds()))
+	ins.dsn = "file:" + ins.Path + "?" + params.Encode()
+	return nil
+}
+
+func (ins *Instance) Gather(slist *types.SampleList) {
+	tags := map[string]string{"path": ins.Path}
+
+	begun := time.Now()
+	defer func() {
+		slist.PushSample(inputName, "scrape_use_seconds", time.Since(begun).Seconds(), tags)
+	}()
+
+	// the database is opened in every gather, so a file replaced by the app is read too,
+	// and no read transaction is kept open to hold back the checkpoints of the WAL
+	db, err := ins.open()
+	if err != nil {
+		slist.PushSample(inputName, "up", 0, tags)
+		sqliteLog.Errorf("failed to open sqlite database: %v error: %v", ins.Path, err)
+		return
+	}
+	defer db.Close()
+
+	var journalMode string
+	if err := db.QueryRow("PRAGMA journal_mode").Scan(&journalMode); err != nil {
+		slist.PushSample(inputName, "up", 0, tags)
+		sqliteLog.Errorf("failed to read sqlite database: %v error: %v", ins.Path, err)
+		return
+	}
+	slist.PushSample(inputName, "up", 1, tags, map[string]string{"journal_mode": strings.ToLower(journalMode)})
+
+	if info, err := os.Stat(ins.Path); err == nil {
+		slist.PushSample(inputName, "size_bytes", info.Size(), tags)
+	}
+	if strings.EqualFold(journalMode, "wal") {
+		// a WAL growing without bound tells the checkpoints are starved, e.g. by long readers
+		var walSize int64
+		if info, err := os.Stat(ins.Path + "-wal"); err == nil {
+			walSize = info.Size()
+		}
+		slist.PushSample(inputName, "wal_size_bytes", walSize, tags)
+	}
+
+	wg := new(sync.WaitGroup)
+	for i := 0; i < len(ins.Queries); i++ {
+		wg.Add(1)
+		go ins.gatherOneQuery(slist, db, tags, wg, ins.Queries[i])
+	}
+	wg.Wait()
+}
+
+func (ins *Instance) open() (*sql.DB, error) {
+	if _, err := os.Stat(ins.Path); err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("sqlite3", ins.dsn)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(len(ins.Queries))
+	return db, nil
+}
+
+func (ins *Instance) gatherOneQuery(slist *types.SampleList, db *sql.DB, globalTags map[string]string, wg *sync.WaitGroup, query QueryConfig) {
+	defer wg.Done()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(query.Timeout))
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, query.Request)
+	if ctx.Err() == context.DeadlineExceeded {
+		sqliteLog.Errorf("query timeout, request: %v", query.Request)
+		return
+	}
+
+	if err != nil {
+		sqliteLog.Errorf("failed to query: %v sql: %v", err, query.Request)
+		return
+	}
+
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		sqliteLog.Errorf("failed to get columns: %v", err)
+		return
+	}
+
+	for rows.Next() {
+		columns := make([]sql.NullString, len(cols))
+		columnPointers := make([]interface{}, len(cols))
+		for i := range columns {
+			columnPointers[i] = &columns[i]
+		}
+
+		if err := rows.Scan(columnPointers...); err != nil {
+			sqliteLog.Errorf("failed to scan: %v", err)
+			return
+		}
+
+		// the NULL columns are missing in row
+		row := make(map[string]string)
+		for i, colName := range cols {
+			if columns[i].Valid {
+				row[strings.ToLower(colName)] = columns[i].String
+			}
+		}
+
+		if err = parseRow(row, query, slist, globalTags); err != nil {
+			sqliteLog.Errorf("failed to parse row: %v sql: %v", err, query.Request)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		sqliteLog.Errorf("failed to read rows: %v sql: %v", err, query.Request)
+	}
+}
+
+func parseRow(row map[string]string, query QueryConfig, slist *types.SampleList, globalTags map[string]string) error {
+	labels := tagx.Copy(globalTags)
+
+	for _, label := range query.LabelFields {
+		labelValue, has := row[label]
+		if has {
+			labels[label] = strings.Replace(labelValue, " ", "_", -1)
+		}
+	}
+
+	for _, column := range query.MetricFields {
+		raw, has := row[column]
+		if !has {
+			continue
+		}
+		value, err := conv.ToFloat64(raw)
+		if err != nil {
+			return fmt.Errorf("failed to convert field %s, value: %s, error: %v", column, raw, err)
+		}
+
+		if query.FieldToAppend == "" {
+			slist.PushFront(types.NewSample(inputName, query.Mesurement+"_"+column, value, labels))
+		} else {
+			suffix := cleanName(row[query.FieldToAppend])
+			slist.PushFront(types.NewSample(inputName, query.Mesurement+"_"+suffix+"_"+column, value, labels))
+		}
+	}
+
+	return nil
+}
+
+func cleanName(s string) string {
+	s = strings.Replace(s, " ", "_", -1) // Remove spaces
+	s = strings.Replace(s, "(", "", -1)  // Remove open parenthesis
+	s = strings.Replace(s, ")", "", -1)  // Remove close parenthesis
+	s = strings.Replace(s, "/", "", -1)  // Remove forward slashes
+	s = strings.Replace(s, "*", "", -1)  // Remove asterisks
+	s = strings.Replace(s, "%", "percent", -1)
+	s = strings.ToLower(s)
+	return s
+}
@@ -6,18 +6,20 @@ import (
 	"time"
 
 	"fmt"
-	"log"
 	"strings"
 	"sync"
 
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
 	"flashcat.cloud/categraf/pkg/filter"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/types"
 
 	mssql "github.com/denisenkom/go-mssqldb"
 )
 
+var sqlserverLog = logger.New("input.sqlserver")
+
 const inputName = "sqlserver"
 
 // DO NOT REMOVE THE NEXT TWO LINES! This is required to embed the sampleConfig data.
@@ -111,7 +113,7 @@ func (s *Instance) Init() error {
 	}
 
 	if err := s.initQueries(); err != nil {
-		log.Println("E! initQueries err:", err)
+		sqlserverLog.Errorf("initQueries err: %v", err)
 		return err
 	}
 
@@ -126,7 +128,7 @@ func (s *Instance) Init() error {
 			var err error
 			pool, err = sql.Open("mssql", serv)
 			if err != nil {
-				log.Println("E! open mssql error:", err)
+				sqlserverLog.Errorf("open mssql error: %v", err)
 				continue
 			}
 		default:
@@ -141,7 +143,7 @@ func (s *Instance) Init() error {
 func (s *Instance) initQueries() error {
 	s.queries = make(MapQuery)
 	queries := s.queries
-	log.Println("Config: database_type: ", s.DatabaseType, " query_version: ", s.QueryVersion)
+	sqlserverLog.Infof("Config: database_type:  %v  query_version:  %v", s.DatabaseType, s.QueryVersion)
 
 	// To prevent query definition conflicts
 	// Constant definitions for type "SQLServer" start with sqlServer
@@ -157,6 +159,8 @@ func (s *Instance) initQueries() error {
 		queries["SQLServerCpu"] = Query{ScriptName: "SQLServerCpu", Script: sqlServerRingBufferCPU, ResultByRow: false}
 		queries["SQLServerAvailabilityReplicaStates"] = Query{ScriptName: "SQLServerAvailabilityReplicaStates", Script: sqlServerAvailabilityReplicaStates, ResultByRow: false}
 		queries["SQLServerDatabaseReplicaStates"] = Query{ScriptName: "SQLServerDatabaseReplicaStates", Script: sqlServerDatabaseReplicaStates, ResultByRow: false}
+		queries["SQLServerAvailabilityGroupHealth"] = Query{ScriptName: "SQLServerAvailabilityGroupHealth", Script: sqlServerAvailabilityGroupHealth, ResultByRow: false}
+		queries["SQLServerAvailabilityDatabaseHealth"] = Query{ScriptName: "SQLServerAvailabilityDatabaseHealth", Script: sqlServerAvailabilityDatabaseHealth, ResultByRow: false}
 		queries["SQLServerRecentBackups"] = Query{ScriptName: "SQLServerRecentBackups", Script: sqlServerRecentBackups, ResultByRow: false}
 	} else {
 		// Decide if we want to run version 1 or version 2 queries
@@ -199,7 +203,7 @@ func (s *Instance) initQueries() error {
 	for query := range queries {
 		querylist = append(querylist, query)
 	}
-	log.Println("Config: Effective Queries: ", querylist)
+	sqlserverLog.Infof("Config: Effective Queries:  %v", querylist)
 
 	return nil
 }
@@ -251,7 +255,7 @@ func (s *Instance) Gather(slist *types.SampleList) {
 				queryError := s.gatherServer(pool, query, slist, connectionString)
 
 				if queryError != nil {
-					log.Println("E! queryError is ", queryError)
+					sqlserverLog.Errorf("queryError is  %v", queryError)
 				}
 				if s.HealthMetric {
 					mutex.Lock()
@@ -1310,6 +1310,68 @@ END'
 EXEC sp_executesql @SqlStatement
 `
 
+// sqlServerAvailabilityGroupHealth returns 0/1 of the availability groups and replicas, for alerting the failover
+// readiness, each row is a replica seen by the server, all replicas are seen on the primary
+const sqlServerAvailabilityGroupHealth string = `
+SET DEADLOCK_PRIORITY -10;
+IF SERVERPROPERTY('EngineEdition') NOT IN (2,3,4) BEGIN /*NOT IN Standard,Enterpris,Express*/
+	DECLARE @ErrorMessage AS nvarchar(500) = 'categraf - Connection string Server:'+ @@ServerName + ',Database:' + DB_NAME() +' is not a SQL Server Standard,Enterprise or Express. Check the database_type parameter in the telegraf configuration.';
+	RAISERROR (@ErrorMessage,11,1)
+	RETURN
+END
+IF SERVERPROPERTY('IsHadrEnabled') = 1 BEGIN
+	SELECT
+		'sqlserver_hadr_health' AS [measurement]
+		,REPLACE(@@SERVERNAME, '\', ':') AS [sql_instance]
+		,ag.name AS group_name
+		,ar.replica_server_name
+		,ar.availability_mode_desc
+		,ar.failover_mode_desc
+		,CASE WHEN hars.is_local = 1 THEN 'true' ELSE 'false' END AS is_local
+		,CASE WHEN hags.synchronization_health = 2 THEN 1 ELSE 0 END AS group_healthy
+		,CASE WHEN hars.synchronization_health = 2 THEN 1 ELSE 0 END AS replica_healthy
+		,CASE WHEN hars.connected_state = 1 THEN 1 ELSE 0 END AS replica_connected
+		,CASE WHEN hars.role = 1 THEN 1 ELSE 0 END AS replica_is_primary
+		/*a replica is ready for failover without data loss if it's synchronous and all of its databases are ready*/
+		,CASE WHEN ar.availability_mode = 1 AND NOT EXISTS (
+			SELECT 1 FROM sys.dm_hadr_database_replica_cluster_states AS drcs
+			WHERE drcs.replica_id = ar.replica_id AND drcs.is_failover_ready = 0
+		) THEN 1 ELSE 0 END AS replica_failover_ready
+	FROM sys.availability_replicas AS ar
+	INNER JOIN sys.availability_groups AS ag ON ar.group_id = ag.group_id
+	INNER JOIN sys.dm_hadr_availability_group_states AS hags ON hags.group_id = ag.group_id
+	LEFT JOIN sys.dm_hadr_availability_replica_states AS hars ON hars.replica_id = ar.replica_id
+END
+`
+
+// sqlServerAvailabilityDatabaseHealth returns 0/1 of the databases in the availability groups, the asynchronous
+// replicas are never SYNCHRONIZED, so SYNCHRONIZING is taken as synchronized for them
+const sqlServerAvailabilityDatabaseHealth string = `
+SET DEADLOCK_PRIORITY -10;
+IF SERVERPROPERTY('EngineEdition') NOT IN (2,3,4) BEGIN /*NOT IN Standard,Enterpris,Express*/
+	DECLARE @ErrorMessage AS nvarchar(500) = 'categraf - Connection string Server:'+ @@ServerName + ',Database:' + DB_NAME() +' is not a SQL Server Standard,Enterprise or Express. Check the database_type parameter in the telegraf configuration.';
+	RAISERROR (@ErrorMessage,11,1)
+	RETURN
+END
+IF SERVERPROPERTY('IsHadrEnabled') = 1 BEGIN
+	SELECT
+		'sqlserver_hadr_database_health' AS [measurement]
+		,REPLACE(@@SERVERNAME, '\', ':') AS [sql_instance]
+		,ag.name AS group_name
+		,ar.replica_server_name
+		,db_name(drs.database_id) AS database_name
+		,CASE WHEN drs.synchronization_health = 2 THEN 1 ELSE 0 END AS healthy
+		,CASE WHEN drs.synchronization_state = 2 OR (ar.availability_mode = 0 AND drs.synchronization_state = 1) THEN 1 ELSE 0 END AS synchronized
+		,CASE WHEN drs.is_suspended = 1 THEN 1 ELSE 0 END AS suspended
+		,CASE WHEN drcs.is_failover_ready = 1 THEN 1 ELSE 0 END AS failover_ready
+	FROM sys.dm_hadr_database_replica_states AS drs
+	INNER JOIN sys.availability_replicas AS ar ON drs.replica_id = ar.replica_id
+	INNER JOIN sys.availability_groups AS ag ON ar.group_id = ag.group_id
+	LEFT JOIN sys.dm_hadr_database_replica_cluster_states AS drcs
+		ON drcs.replica_id = drs.replica_id AND drcs.group_database_id = drs.group_database_id
+END
+`
+
 const sqlServerRecentBackups string = `
 SET DEADLOCK_PRIORITY -10;
 IF SERVERPROPERTY('EngineEdition') NOT IN (2,3,4) BEGIN /*NOT IN Standard,Enterpris,Express*/
@@ -0,0 +1,386 @@
+package ssh
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	xssh "golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/parser"
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/types"
+)
+
+var sshLog = logger.New("input.ssh")
+
+const inputName = "ssh"
+
+// the stdout beyond is dropped, a runaway command does not exhaust the memory
+const maxStdoutBytes = 4 * 1024 * 1024
+
+const maxStderrBytes = 512
+
+type Instance struct {
+	config.InstanceConfig
+
+	// host:port, the port is 22 if omitted
+	Targets  []string `toml:"targets"`
+	Commands []string `toml:"commands"`
+
+	Username string `toml:"username"`
+	Password string `toml:"password"`
+	// the private key in PEM, e.g. ~/.ssh/id_ed25519
+	PrivateKeyFile       string `toml:"private_key_file"`
+	PrivateKeyPassphrase string `toml:"private_key_passphrase"`
+	// authenticate with the keys of the ssh-agent of SSH_AUTH_SOCK
+	UseAgent bool `toml:"use_agent"`
+
+	// the host keys are pinned by their SHA256 fingerprints, e.g. SHA256:nThbg6kXUpJWGl7E1IGOCspRomTxdCARLviKw6E5SY8,
+	// or checked against known_hosts files, one of them is required unless insecure_ignore_host_key
+	HostKeyFingerprints   []string `toml:"host_key_fingerprints"`
+	KnownHosts            []string `toml:"known_hosts"`
+	InsecureIgnoreHostKey bool     `toml:"insecure_ignore_host_key"`
+
+	// the algorithms of the legacy servers, the defaults of golang.org/x/crypto/ssh if empty
+	Ciphers           []string `toml:"ciphers"`
+	KeyExchanges      []string `toml:"key_exchanges"`
+	HostKeyAlgorithms []string `toml:"host_key_algorithms"`
+
+	// timeout of connecting and authenticating
+	DialTimeout config.Duration `toml:"dial_timeout"`
+	// timeout of each command
+	Timeout config.Duration `toml:"timeout"`
+	// the targets gathered at the same time
+	MaxConcurrency int `toml:"max_concurrency"`
+
+	// how the stdout of the commands is parsed
+	parser.Format
+
+	parser parser.Parser
+	signer xssh.Signer
+	// the auth methods and host key callback, the agent is connected in every gather
+	clientConfig *xssh.ClientConfig
+}
+
+type SSH struct {
+	config.PluginConfig
+	Instances []*Instance `toml:"instances"`
+}
+
+func init() {
+	inputs.Add(inputName, func() inputs.Input {
+		return &SSH{}
+	})
+}
+
+func (s *SSH) Clone() inputs.Input {
+	return &SSH{}
+}
+
+func (s *SSH) Name() string {
+	return inputName
+}
+
+func (s *SSH) GetInstances() []inputs.Instance {
+	ret := make([]inputs.Instance, len(s.Instances))
+	for i := 0; i < len(s.Instances); i++ {
+		ret[i] = s.Instances[i]
+	}
+	return ret
+}
+
+func (ins *Instance) Init() error {
+	if len(ins.Targets) == 0 || len(ins.Commands) == 0 {
+		return types.ErrInstancesEmpty
+	}
+
+	if ins.Username == "" {
+		return errors.New("username is required")
+	}
+	if ins.Password == "" && ins.PrivateKeyFile == "" && !ins.UseAgent {
+		return errors.New("one of password, private_key_file and use_agent is required")
+	}
+
+	for i, target := range ins.Targets {
+		if _, _, err := net.SplitHostPort(target); err != nil {
+			ins.Targets[i] = net.JoinHostPort(target, "22")
+		}
+	}
+
+	if ins.DialTimeout <= 0 {
+		ins.DialTimeout = config.Duration(5 * time.Second)
+	}
+	if ins.Timeout <= 0 {
+		ins.Timeout = config.Duration(10 * time.Second)
+	}
+	if ins.MaxConcurrency <= 0 {
+		ins.MaxConcurrency = 10
+	}
+
+	var err error
+	if ins.parser, err = ins.Format.NewParser(); err != nil {
+		return err
+	}
+
+	if ins.PrivateKeyFile != "" {
+		if ins.signer, err = readPrivateKey(ins.PrivateKeyFile, ins.PrivateKeyPassphrase); err != nil {
+			return err
+		}
+	}
+
+	hostKeyCallback, err := ins.hostKeyCallback()
+	if err != nil {
+		return err
+	}
+
+	ins.clientConfig = &xssh.ClientConfig{
+		User:              ins.Username,
+		HostKeyCallback:   hostKeyCallback,
+		HostKeyAlgorithms: ins.HostKeyAlgorithms,
+		Timeout:           time.Duration(ins.DialTimeout),
+		Config: xssh.Config{
+			Ciphers:      ins.Ciphers,
+			KeyExchanges: ins.KeyExchanges,
+		},
+	}
+	return nil
+}
+
+func readPrivateKey(file, passphrase string) (xssh.Signer, error) {
+	pem, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private_key_file: %v", err)
+	}
+	var signer xssh.Signer
+	if passphrase != "" {
+		signer, err = xssh.ParsePrivateKeyWithPassphrase(pem, []byte(passphrase))
+	} else {
+		signer, err = xssh.ParsePrivateKey(pem)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private_key_file %s: %v", file, err)
+	}
+	return signer, nil
+}
+
+func (ins *Instance) hostKeyCallback() (xssh.HostKeyCallback, error) {
+	if len(ins.HostKeyFingerprints) > 0 {
+		pinned := make(map[string]struct{}, len(ins.HostKeyFingerprints))
+		for _, fp := range ins.HostKeyFingerprints {
+			if !strings.HasPrefix(fp, "SHA256:") {
+				return nil, fmt.Errorf("host key fingerprint %q is not of SHA256, e.g. the output of ssh-keygen -lf", fp)
+			}
+			pinned[fp] = struct{}{}
+		}
+		return func(hostname string, remote net.Addr, key xssh.PublicKey) error {
+			fp := xssh.FingerprintSHA256(key)
+			if _, has := pinned[fp]; !has {
+				return fmt.Errorf("host key %s of %s is not pinned", fp, hostname)
+			}
+			return nil
+		}, nil
+	}
+
+	if len(ins.KnownHosts) > 0 {
+		callback, err := knownhosts.New(ins.KnownHosts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read known_hosts: %v", err)
+		}
+		return callback, nil
+	}
+
+	if ins.InsecureIgnoreHostKey {
+		return xssh.InsecureIgnoreHostKey(), nil
+	}
+	return nil, errors.New("one of host_key_fingerprints and known_hosts is required, or set insecure_ignore_host_key")
+}
+
+func (ins *Instance) Gather(slist *types.SampleList) {
+	auths, closeAgent := ins.authMethods()
+	defer closeAgent()
+
+	clientConfig := *ins.clientConfig
+	clientConfig.Auth = auths
+
+	wg := new(sync.WaitGroup)
+	ch := make(chan struct{}, ins.MaxConcurrency)
+	for _, target := range ins.Targets {
+		ch <- struct{}{}
+		wg.Add(1)
+		go func(target string) {
+			defer func() {
+				<-ch
+				wg.Done()
+			}()
+			ins.gatherTarget(slist, &clientConfig, target)
+		}(target)
+	}
+	wg.Wait()
+}
+
+// authMethods returns the auth methods tried in order, the password is the last one, as the servers
+// may lock the accounts of the failed passwords
+func (ins *Instance) authMethods() ([]xssh.AuthMethod, func()) {
+	var (
+		auths   []xssh.AuthMethod
+		signers []xssh.Signer
+	)
+	if ins.signer != nil {
+		signers = append(signers, ins.signer)
+	}
+
+	closeAgent := func() {}
+	if ins.UseAgent {
+		if conn, err := net.Dial("unix", os.Getenv("SSH_AUTH_SOCK")); err != nil {
+			sshLog.Errorf("failed to connect ssh-agent of SSH_AUTH_SOCK: %v", err)
+		} else {
+			closeAgent = func() { conn.Close() }
+			if agentSigners, err := agent.NewClient(conn).Signers(); err != nil {
+				sshLog.Errorf("failed to list keys of ssh-agent: %v", err)
+			} else {
+				signers = append(signers, agentSigners...)
+			}
+		}
+	}
+
+	if len(signers) > 0 {
+		auths = append(auths, xssh.PublicKeys(signers...))
+	}
+	if ins.Password != "" {
+		auths = append(auths, xssh.Password(ins.Password))
+		// the legacy servers ask the password in keyboard-interactive only
+		auths = append(auths, xssh.KeyboardInteractive(func(user, instruction string, questions []string, echos []bool) ([]string, error) {
+			answers := make([]string, len(questions))
+			for i := range answers {
+				answers[i] = ins.Password
+			}
+			return answers, nil
+		}))
+	}
+	return auths, closeAgent
+}
+
+func (ins *Instance) gatherTarget(slist *types.SampleList, clientConfig *xssh.ClientConfig, target string) {
+	tags := map[string]string{"target": target}
+
+	begun := time.Now()
+	client, err := dial(target, clientConfig)
+	if err != nil {
+		slist.PushSample(inputName, "up", 0, tags)
+		sshLog.Errorf("failed to connect ssh target: %v error: %v", target, err)
+		return
+	}
+	defer client.Close()
+	slist.PushSample(inputName, "up", 1, tags)
+	slist.PushSample(inputName, "connect_seconds", time.Since(begun).Seconds(), tags)
+
+	// the commands are run one by one, the appliances usually allow one session per connection at a time
+	for _, command := range ins.Commands {
+		ins.runCommand(slist, client, tags, command)
+	}
+}
+
+// dial connects and authenticates in DialTimeout, Timeout of ClientConfig covers the tcp connection only
+func dial(target string, clientConfig *xssh.ClientConfig) (*xssh.Client, error) {
+	conn, err := net.DialTimeout("tcp", target, clientConfig.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetDeadline(time.Now().Add(clientConfig.Timeout))
+
+	c, chans, reqs, err := xssh.NewClientConn(conn, target, clientConfig)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	conn.SetDeadline(time.Time{})
+	return xssh.NewClient(c, chans, reqs), nil
+}
+
+func (ins *Instance) runCommand(slist *types.SampleList, client *xssh.Client, tags map[string]string, command string) {
+	session, err := client.NewSession()
+	if err != nil {
+		sshLog.Errorf("failed to open ssh session of target: %v error: %v", tags["target"], err)
+		return
+	}
+	defer session.Close()
+
+	stdout := &limitedBuffer{limit: maxStdoutBytes}
+	stderr := &limitedBuffer{limit: maxStderrBytes}
+	session.Stdout = stdout
+	session.Stderr = stderr
+
+	done := make(chan error, 1)
+	go func() {
+		done <- session.Run(command)
+	}()
+
+	select {
+	case err = <-done:
+	case <-time.After(time.Duration(ins.Timeout)):
+		// the remote command may be left running, closing the session is all a client can do
+		session.Close()
+		sshLog.Errorf("ssh command timeout, target: %v command: %v", tags["target"], command)
+		return
+	}
+
+	exitStatus := 0
+	if err != nil {
+		var exitErr *xssh.ExitError
+		if !errors.As(err, &exitErr) {
+			sshLog.Errorf("failed to run ssh command, target: %v command: %v error: %v", tags["target"], command, err)
+			return
+		}
+		exitStatus = exitErr.ExitStatus()
+	}
+	slist.PushSample(inputName, "command_exit_status", exitStatus, tags, map[string]string{"command": command})
+
+	if exitStatus != 0 {
+		sshLog.Errorf("ssh command: %v target: %v exit status: %v stderr: %v", command, tags["target"], exitStatus, stderr.String())
+		return
+	}
+
+	// a command may print nothing if there is nothing to report
+	if len(bytes.TrimSpace(stdout.Bytes())) == 0 {
+		return
+	}
+
+	// the target label tells the samples of the same command run on the targets apart
+	samples := types.NewSampleList()
+	if err := ins.parser.Parse(stdout.Bytes(), samples); err != nil {
+		sshLog.Errorf("failed to parse stdout of ssh command: %v target: %v error: %v", command, tags["target"], err)
+	}
+	items := samples.PopBackAll()
+	for _, s := range items {
+		if _, has := s.Labels["target"]; !has {
+			s.Labels["target"] = tags["target"]
+		}
+	}
+	slist.PushFrontN(items)
+}
+
+// limitedBuffer keeps the first limit bytes written, the others are discarded
+type limitedBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.Len(); room < len(p) {
+		if room > 0 {
+			b.Buffer.Write(p[:room])
+		}
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
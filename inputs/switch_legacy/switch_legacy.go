@@ -3,13 +3,13 @@ package switch_legacy
 import (
 	"errors"
 	"fmt"
-	"log"
 	"sync"
 	"time"
 
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
 	"flashcat.cloud/categraf/pkg/conv"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/pkg/runtimex"
 	"flashcat.cloud/categraf/types"
 	"github.com/gaochao1/sw"
@@ -18,6 +18,8 @@ import (
 	go_snmp "github.com/ulricqin/gosnmp"
 )
 
+var switchLegacyLog = logger.New("input.switch_legacy")
+
 const inputName = "switch_legacy"
 
 type Switch struct {
@@ -149,10 +151,10 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 
 	start := time.Now()
 	defer func() {
-		log.Println("I! switch gather use:", time.Since(start))
+		switchLegacyLog.Infof("switch gather use: %v", time.Since(start))
 	}()
 
-	log.Println("I! switch total ip count:", len(ips))
+	switchLegacyLog.Infof("switch total ip count: %v", len(ips))
 
 	if ins.PingEnable {
 		ips = ins.gatherPing(ips, slist)
@@ -194,7 +196,7 @@ func (ins *Instance) custstat(wg *sync.WaitGroup, ip string, slist *types.Sample
 
 	defer func() {
 		if r := recover(); r != nil {
-			log.Println("E! recovered in custstat, ip:", ip, "oid:", cust.OID, "error:", r, "stack:", runtimex.Stack(3))
+			switchLegacyLog.Errorf("recovered in custstat, ip: %v oid: %v error: %v stack: %v", ip, cust.OID, r, runtimex.Stack(3))
 		}
 	}()
 
@@ -208,7 +210,7 @@ func (ins *Instance) custstat(wg *sync.WaitGroup, ip string, slist *types.Sample
 			if err == nil {
 				slist.PushFront(types.NewSample(inputName, cust.Metric, value, cust.Tags, map[string]string{ins.parent.SwitchIdLabel: ins.parent.MappingIP(ip)}))
 			} else {
-				log.Println("E! failed to convert to float64, ip:", ip, "oid:", cust.OID, "value:", snmpPDUs[0].Value)
+				switchLegacyLog.Errorf("failed to convert to float64, ip: %v oid: %v value: %v", ip, cust.OID, snmpPDUs[0].Value)
 			}
 			break
 		}
@@ -253,7 +255,7 @@ func (ins *Instance) memstat(wg *sync.WaitGroup, sema *semaphore.Semaphore, ip s
 
 	utilPercent, err := sw.MemUtilization(ip, ins.Community, int(ins.SnmpTimeoutMs), ins.SnmpRetries)
 	if err != nil {
-		log.Println("E! failed to gather mem, ip:", ip, "error:", err)
+		switchLegacyLog.Errorf("failed to gather mem, ip: %v error: %v", ip, err)
 		return
 	}
 
@@ -298,7 +300,7 @@ func (ins *Instance) cpustat(wg *sync.WaitGroup, sema *semaphore.Semaphore, ip s
 
 	utilPercent, err := sw.CpuUtilization(ip, ins.Community, int(ins.SnmpTimeoutMs), ins.SnmpRetries)
 	if err != nil {
-		log.Println("E! failed to gather cpu, ip:", ip, "error:", err)
+		switchLegacyLog.Errorf("failed to gather cpu, ip: %v error: %v", ip, err)
 		return
 	}
 
@@ -389,7 +391,7 @@ func (ins *Instance) gatherFlowMetrics(ips []string, slist *types.SampleList) {
 								slist.PushFront(types.NewSample(inputName, "if_in_speed_percent", 100*IfHCInOctets/float64(ifStat.IfSpeed), tags))
 							}
 						} else {
-							log.Println("W! if_in out of range, current:", ifStat.IfHCInOctets, "lasttime:", lastifStat.IfHCInOctets, "tags:", tags)
+							switchLegacyLog.Warnf("if_in out of range, current: %v lasttime: %v tags: %v", ifStat.IfHCInOctets, lastifStat.IfHCInOctets, tags)
 						}
 
 						if limitCheck(IfHCOutOctets, speedlimit) {
@@ -398,7 +400,7 @@ func (ins *Instance) gatherFlowMetrics(ips []string, slist *types.SampleList) {
 								slist.PushFront(types.NewSample(inputName, "if_out_speed_percent", 100*IfHCOutOctets/float64(ifStat.IfSpeed), tags))
 							}
 						} else {
-							log.Println("W! if_out out of range, current:", ifStat.IfHCOutOctets, "lasttime:", lastifStat.IfHCOutOctets, "tags:", tags)
+							switchLegacyLog.Warnf("if_out out of range, current: %v lasttime: %v tags: %v", ifStat.IfHCOutOctets, lastifStat.IfHCOutOctets, tags)
 						}
 					}
 				}
@@ -416,13 +418,13 @@ func (ins *Instance) gatherFlowMetrics(ips []string, slist *types.SampleList) {
 							if limitCheck(IfHCInBroadcastPkts, ins.BroadcastPktLimit) {
 								slist.PushFront(types.NewSample(inputName, "if_in_broadcast_pkt", IfHCInBroadcastPkts, tags))
 							} else {
-								log.Println("W! if_in_broadcast_pkt out of range, current:", ifStat.IfHCInBroadcastPkts, "lasttime:", lastifStat.IfHCInBroadcastPkts, "tags:", tags)
+								switchLegacyLog.Warnf("if_in_broadcast_pkt out of range, current: %v lasttime: %v tags: %v", ifStat.IfHCInBroadcastPkts, lastifStat.IfHCInBroadcastPkts, tags)
 							}
 
 							if limitCheck(IfHCOutBroadcastPkts, ins.BroadcastPktLimit) {
 								slist.PushFront(types.NewSample(inputName, "if_out_broadcast_pkt", IfHCOutBroadcastPkts, tags))
 							} else {
-								log.Println("W! if_out_broadcast_pkt out of range, current:", ifStat.IfHCOutBroadcastPkts, "lasttime:", lastifStat.IfHCOutBroadcastPkts, "tags:", tags)
+								switchLegacyLog.Warnf("if_out_broadcast_pkt out of range, current: %v lasttime: %v tags: %v", ifStat.IfHCOutBroadcastPkts, lastifStat.IfHCOutBroadcastPkts, tags)
 							}
 						}
 					}
@@ -441,13 +443,13 @@ func (ins *Instance) gatherFlowMetrics(ips []string, slist *types.SampleList) {
 							if limitCheck(IfHCInMulticastPkts, ins.MulticastPktLimit) {
 								slist.PushFront(types.NewSample(inputName, "if_in_multicast_pkt", IfHCInMulticastPkts, tags))
 							} else {
-								log.Println("W! if_in_multicast_pkt out of range, current:", ifStat.IfHCInMulticastPkts, "lasttime:", lastifStat.IfHCInMulticastPkts, "tags:", tags)
+								switchLegacyLog.Warnf("if_in_multicast_pkt out of range, current: %v lasttime: %v tags: %v", ifStat.IfHCInMulticastPkts, lastifStat.IfHCInMulticastPkts, tags)
 							}
 
 							if limitCheck(IfHCOutMulticastPkts, ins.MulticastPktLimit) {
 								slist.PushFront(types.NewSample(inputName, "if_out_multicast_pkt", IfHCOutMulticastPkts, tags))
 							} else {
-								log.Println("W! if_out_multicast_pkt out of range, current:", ifStat.IfHCOutMulticastPkts, "lasttime:", lastifStat.IfHCOutMulticastPkts, "tags:", tags)
+								switchLegacyLog.Warnf("if_out_multicast_pkt out of range, current: %v lasttime: %v tags: %v", ifStat.IfHCOutMulticastPkts, lastifStat.IfHCOutMulticastPkts, tags)
 							}
 						}
 					}
@@ -466,13 +468,13 @@ func (ins *Instance) gatherFlowMetrics(ips []string, slist *types.SampleList) {
 							if limitCheck(IfInDiscards, ins.DiscardsPktLimit) {
 								slist.PushFront(types.NewSample(inputName, "if_in_discards", IfInDiscards, tags))
 							} else {
-								log.Println("W! if_in_discards out of range, current:", ifStat.IfInDiscards, "lasttime:", lastifStat.IfInDiscards, "tags:", tags)
+								switchLegacyLog.Warnf("if_in_discards out of range, current: %v lasttime: %v tags: %v", ifStat.IfInDiscards, lastifStat.IfInDiscards, tags)
 							}
 
 							if limitCheck(IfOutDiscards, ins.DiscardsPktLimit) {
 								slist.PushFront(types.NewSample(inputName, "if_out_discards", IfOutDiscards, tags))
 							} else {
-								log.Println("W! if_out_discards out of range, current:", ifStat.IfOutDiscards, "lasttime:", lastifStat.IfOutDiscards, "tags:", tags)
+								switchLegacyLog.Warnf("if_out_discards out of range, current: %v lasttime: %v tags: %v", ifStat.IfOutDiscards, lastifStat.IfOutDiscards, tags)
 							}
 						}
 					}
@@ -491,13 +493,13 @@ func (ins *Instance) gatherFlowMetrics(ips []string, slist *types.SampleList) {
 							if limitCheck(IfInErrors, ins.ErrorsPktLimit) {
 								slist.PushFront(types.NewSample(inputName, "if_in_errors", IfInErrors, tags))
 							} else {
-								log.Println("W! if_in_errors out of range, current:", ifStat.IfInErrors, "lasttime:", lastifStat.IfInErrors, "tags:", tags)
+								switchLegacyLog.Warnf("if_in_errors out of range, current: %v lasttime: %v tags: %v", ifStat.IfInErrors, lastifStat.IfInErrors, tags)
 							}
 
 							if limitCheck(IfOutErrors, ins.ErrorsPktLimit) {
 								slist.PushFront(types.NewSample(inputName, "if_out_errors", IfOutErrors, tags))
 							} else {
-								log.Println("W! if_out_errors out of range, current:", ifStat.IfOutErrors, "lasttime:", lastifStat.IfOutErrors, "tags:", tags)
+								switchLegacyLog.Warnf("if_out_errors out of range, current: %v lasttime: %v tags: %v", ifStat.IfOutErrors, lastifStat.IfOutErrors, tags)
 							}
 						}
 					}
@@ -513,7 +515,7 @@ func (ins *Instance) gatherFlowMetrics(ips []string, slist *types.SampleList) {
 							if limitCheck(IfInUnknownProtos, ins.UnknownProtosPktLimit) {
 								slist.PushFront(types.NewSample(inputName, "if_in_unknown_protos", IfInUnknownProtos, tags))
 							} else {
-								log.Println("W! if_in_unknown_protos out of range, current:", ifStat.IfInUnknownProtos, "lasttime:", lastifStat.IfInUnknownProtos, "tags:", tags)
+								switchLegacyLog.Warnf("if_in_unknown_protos out of range, current: %v lasttime: %v tags: %v", ifStat.IfInUnknownProtos, lastifStat.IfInUnknownProtos, tags)
 							}
 						}
 					}
@@ -529,7 +531,7 @@ func (ins *Instance) gatherFlowMetrics(ips []string, slist *types.SampleList) {
 							if limitCheck(IfOutQLen, ins.OutQlenPktLimit) {
 								slist.PushFront(types.NewSample(inputName, "if_out_qlen", IfOutQLen, tags))
 							} else {
-								log.Println("W! if_out_qlen out of range, current:", ifStat.IfOutQLen, "lasttime:", lastifStat.IfOutQLen, "tags:", tags)
+								switchLegacyLog.Warnf("if_out_qlen out of range, current: %v lasttime: %v tags: %v", ifStat.IfOutQLen, lastifStat.IfOutQLen, tags)
 							}
 						}
 					}
@@ -548,13 +550,13 @@ func (ins *Instance) gatherFlowMetrics(ips []string, slist *types.SampleList) {
 							if limitCheck(IfHCInUcastPkts, ins.PktLimit) {
 								slist.PushFront(types.NewSample(inputName, "if_in_pkts", IfHCInUcastPkts, tags))
 							} else {
-								log.Println("W! if_in_pkts out of range, current:", ifStat.IfHCInUcastPkts, "lasttime:", lastifStat.IfHCInUcastPkts, "tags:", tags)
+								switchLegacyLog.Warnf("if_in_pkts out of range, current: %v lasttime: %v tags: %v", ifStat.IfHCInUcastPkts, lastifStat.IfHCInUcastPkts, tags)
 							}
 
 							if limitCheck(IfHCOutUcastPkts, ins.PktLimit) {
 								slist.PushFront(types.NewSample(inputName, "if_out_pkts", IfHCOutUcastPkts, tags))
 							} else {
-								log.Println("W! if_out_pkts out of range, current:", ifStat.IfHCOutUcastPkts, "lasttime:", lastifStat.IfHCOutUcastPkts, "tags:", tags)
+								switchLegacyLog.Warnf("if_out_pkts out of range, current: %v lasttime: %v tags: %v", ifStat.IfHCOutUcastPkts, lastifStat.IfHCOutUcastPkts, tags)
 							}
 						}
 					}
@@ -584,11 +586,11 @@ func (ins *Instance) ifstat(wg *sync.WaitGroup, sema *semaphore.Semaphore, ip st
 	}
 
 	if config.Config.DebugMode {
-		log.Println("D! switch gather ifstat, ip:", ip, "use:", time.Since(start))
+		switchLegacyLog.Debugf("switch gather ifstat, ip: %v use: %v", ip, time.Since(start))
 	}
 
 	if err != nil {
-		log.Println("E! failed to gather ifstat, ip:", ip, "error:", err)
+		switchLegacyLog.Errorf("failed to gather ifstat, ip: %v error: %v", ip, err)
 		return
 	}
 
@@ -632,7 +634,7 @@ func (ins *Instance) gatherPing(ips []string, slist *types.SampleList) []string
 		}
 	}
 
-	log.Println("I! switch alive ip count:", len(ips))
+	switchLegacyLog.Infof("switch alive ip count: %v", len(ips))
 	return ips
 }
 
@@ -1,10 +1,13 @@
 package system
 
 import (
-	"log"
+	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"syscall"
 
 	"github.com/shirou/gopsutil/v3/cpu"
 	"github.com/shirou/gopsutil/v3/disk"
@@ -52,20 +55,29 @@ func (s *SystemPS) CPUTimes(perCPU, totalCPU bool) ([]cpu.TimesStat, error) {
 	if perCPU {
 		perCPUTimes, err := cpu.Times(true)
 		if err != nil {
-			return nil, err
+			return nil, cgoRequired(err)
 		}
 		cpuTimes = append(cpuTimes, perCPUTimes...)
 	}
 	if totalCPU {
 		totalCPUTimes, err := cpu.Times(false)
 		if err != nil {
-			return nil, err
+			return nil, cgoRequired(err)
 		}
 		cpuTimes = append(cpuTimes, totalCPUTimes...)
 	}
 	return cpuTimes, nil
 }
 
+// cgoRequired explains the error of darwin, whose cpu times and disk io are read
+// by host_processor_info and IOKit, which are only available with cgo
+func cgoRequired(err error) error {
+	if runtime.GOOS == "darwin" && strings.Contains(err.Error(), "not implemented") {
+		return fmt.Errorf("%v, categraf should be built with CGO_ENABLED=1 on darwin", err)
+	}
+	return err
+}
+
 type set struct {
 	m map[string]struct{}
 }
@@ -155,7 +167,7 @@ func (s *SystemPS) DiskUsage(
 		}
 		du, err := s.PSDiskUsage(mountpoint)
 		if err != nil {
-			log.Println("E! failed to get disk usage, mountpoint:", mountpoint, "error:", err)
+			systemLog.Errorf("failed to get disk usage, mountpoint: %v error: %v", mountpoint, err)
 			dun.DeviceError = 1
 			du = &disk.UsageStat{}
 		}
@@ -179,12 +191,46 @@ func (s *SystemPS) NetIO() ([]net.IOCountersStat, error) {
 }
 
 func (s *SystemPS) NetConnections() ([]net.ConnectionStat, error) {
+	if runtime.GOOS == "freebsd" {
+		// gopsutil lists the connections of bsd by lsof, which is not in the base system of freebsd
+		if _, err := exec.LookPath("lsof"); err != nil {
+			return netstatConnections()
+		}
+	}
 	return net.Connections("all")
 }
 
+// netstatConnections parses the tcp and udp sockets of netstat -an, e.g.
+// tcp4       0      0 10.0.0.2.22            10.0.0.1.53012         ESTABLISHED
+func netstatConnections() ([]net.ConnectionStat, error) {
+	out, err := exec.Command("netstat", "-an").Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var conns []net.ConnectionStat
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 5 {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(fields[0], "tcp") && len(fields) >= 6:
+			conns = append(conns, net.ConnectionStat{Type: syscall.SOCK_STREAM, Status: fields[len(fields)-1]})
+		case strings.HasPrefix(fields[0], "udp"):
+			conns = append(conns, net.ConnectionStat{Type: syscall.SOCK_DGRAM})
+		}
+	}
+	return conns, nil
+}
+
 func (s *SystemPS) DiskIO(names []string) (map[string]disk.IOCountersStat, error) {
 	m, err := disk.IOCounters(names...)
 	if err != nil && strings.Contains(err.Error(), "not implemented") {
+		if runtime.GOOS == "darwin" {
+			return nil, cgoRequired(err)
+		}
 		return nil, nil
 	}
 
@@ -1,18 +1,20 @@
 package system
 
 import (
-	"log"
 	"os"
 	"strings"
 
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/types"
 	"github.com/shirou/gopsutil/v3/cpu"
 	"github.com/shirou/gopsutil/v3/host"
 	"github.com/shirou/gopsutil/v3/load"
 )
 
+var systemLog = logger.New("input.system")
+
 const inputName = "system"
 
 type SystemStats struct {
@@ -37,13 +39,13 @@ func (s *SystemStats) Name() string {
 func (s *SystemStats) Gather(slist *types.SampleList) {
 	loadavg, err := load.Avg()
 	if err != nil && !strings.Contains(err.Error(), "not implemented") {
-		log.Println("E! failed to gather system load:", err)
+		systemLog.Errorf("failed to gather system load: %v", err)
 		return
 	}
 
 	numCPUs, err := cpu.Counts(true)
 	if err != nil {
-		log.Println("E! failed to gather cpu number:", err)
+		systemLog.Errorf("failed to gather cpu number: %v", err)
 		return
 	}
 
@@ -59,7 +61,7 @@ func (s *SystemStats) Gather(slist *types.SampleList) {
 
 	uptime, err := host.Uptime()
 	if err != nil {
-		log.Println("E! failed to get host uptime:", err)
+		systemLog.Errorf("failed to get host uptime: %v", err)
 	} else {
 		fields["uptime"] = uptime
 	}
@@ -69,9 +71,9 @@ func (s *SystemStats) Gather(slist *types.SampleList) {
 		if err == nil {
 			fields["n_users"] = len(users)
 		} else if os.IsNotExist(err) {
-			log.Println("W! reading os users:", err)
+			systemLog.Warnf("reading os users: %v", err)
 		} else if os.IsPermission(err) {
-			log.Println("W! reading os users:", err)
+			systemLog.Warnf("reading os users: %v", err)
 		}
 	}
 
@@ -5,9 +5,11 @@ import (
 
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
-	"github.com/coreos/go-systemd/v22/dbus"
+	"flashcat.cloud/categraf/pkg/logger"
 )
 
+var systemdLog = logger.New("input.systemd")
+
 const (
 	// minSystemdVersionSystemState is the minimum SystemD version for availability of
 	// the 'SystemState' manager property and the timer property 'LastTriggerUSec'
@@ -32,10 +34,7 @@ type (
 		EnableRestartMetrics   bool `toml:"enable_restarts_metrics"`
 		EnableStartTimeMetrics bool `toml:"enable_start_time_metrics"`
 
-		conn *dbus.Conn
-	}
-	unit struct {
-		dbus.UnitStatus
+		conn *dbusConn
 	}
 )
 
@@ -19,7 +19,6 @@ package systemd
 import (
 	"context"
 	"fmt"
-	"log"
 	"math"
 	"regexp"
 	"strconv"
@@ -33,6 +32,13 @@ import (
 	"flashcat.cloud/categraf/types"
 )
 
+// dbus of godbus is not built on the platforms without systemd, e.g. freebsd
+type dbusConn = dbus.Conn
+
+type unit struct {
+	dbus.UnitStatus
+}
+
 // Init returns a new Collector exposing systemd statistics.
 func (s *Systemd) Init() error {
 	if !s.Enable {
@@ -69,13 +75,13 @@ func (s *Systemd) Gather(slist *types.SampleList) {
 
 	systemdVersion, systemdVersionFull := s.getSystemdVersion()
 	if systemdVersion < minSystemdVersionSystemState {
-		log.Println("msg", "Detected systemd version is lower than minimum, some systemd state and timer metrics will not be available", "current", systemdVersion, "minimum", minSystemdVersionSystemState)
+		systemdLog.Infof("msg Detected systemd version is lower than minimum, some systemd state and timer metrics will not be available current %v minimum %v", systemdVersion, minSystemdVersionSystemState)
 	}
 	slist.PushSample(inputName, "version", systemdVersion, map[string]string{"version": systemdVersionFull})
 
 	allUnits, err := s.getAllUnits()
 	if err != nil {
-		log.Println("E! couldn't get units: %w", err)
+		systemdLog.Errorf("couldn't get units: %v", err)
 		return
 	}
 
@@ -83,13 +89,13 @@ func (s *Systemd) Gather(slist *types.SampleList) {
 	summary := summarizeUnits(allUnits)
 	s.collectSummaryMetrics(slist, summary)
 	if config.Config.DebugMode {
-		log.Println("D!", "collectSummaryMetrics took", "duration_seconds", time.Since(begin).Seconds())
+		systemdLog.Debugf("collectSummaryMetrics took duration_seconds %v", time.Since(begin).Seconds())
 	}
 
 	begin = time.Now()
 	units := filterUnits(allUnits, s.unitIncludePattern, s.unitExcludePattern)
 	if config.Config.DebugMode {
-		log.Println("D!", "filterUnits took", "duration_seconds", time.Since(begin).Seconds())
+		systemdLog.Debugf("filterUnits took duration_seconds %v", time.Since(begin).Seconds())
 	}
 
 	var wg sync.WaitGroup
@@ -141,7 +147,7 @@ func (s *Systemd) Gather(slist *types.SampleList) {
 		err = s.collectSystemState(slist)
 	}
 	if err != nil {
-		log.Println("E! collect systemd state:", err)
+		systemdLog.Errorf("collect systemd state: %v", err)
 	}
 }
 
@@ -151,14 +157,14 @@ func (s *Systemd) collectUnitStatusMetrics(slist *types.SampleList, units []unit
 		if strings.HasSuffix(unit.Name, ".service") {
 			serviceTypeProperty, err := s.conn.GetUnitTypePropertyContext(context.TODO(), unit.Name, "Service", "Type")
 			if err != nil {
-				log.Println("E!", "couldn't get unit type", "unit", unit.Name, "err", err)
+				systemdLog.Errorf("couldn't get unit type unit %v err %v", unit.Name, err)
 			} else {
 				serviceType = serviceTypeProperty.Value.Value().(string)
 			}
 		} else if strings.HasSuffix(unit.Name, ".mount") {
 			serviceTypeProperty, err := s.conn.GetUnitTypePropertyContext(context.TODO(), unit.Name, "Mount", "Type")
 			if err != nil {
-				log.Println("E!", "couldn't get unit type", "unit", unit.Name, "err", err)
+				systemdLog.Errorf("couldn't get unit type unit %v err %v", unit.Name, err)
 			} else {
 				serviceType = serviceTypeProperty.Value.Value().(string)
 			}
@@ -175,7 +181,7 @@ func (s *Systemd) collectUnitStatusMetrics(slist *types.SampleList, units []unit
 			// NRestarts wasn't added until systemd 235.
 			restartsCount, err := s.conn.GetUnitTypePropertyContext(context.TODO(), unit.Name, "Service", "NRestarts")
 			if err != nil {
-				log.Println("E!", "couldn't get unit NRestarts", "unit", unit.Name, "err", err)
+				systemdLog.Errorf("couldn't get unit NRestarts unit %v err %v", unit.Name, err)
 			} else {
 				slist.PushSample(inputName, "service_restart_total", restartsCount.Value.Value().(uint32),
 					map[string]string{"name": unit.Name})
@@ -193,7 +199,7 @@ func (s *Systemd) collectSockets(slist *types.SampleList, units []unit) {
 
 		acceptedConnectionCount, err := s.conn.GetUnitTypePropertyContext(context.TODO(), unit.Name, "Socket", "NAccepted")
 		if err != nil {
-			log.Println("W!", "couldn't get unit NAccepted", "unit", unit.Name, "err", err)
+			systemdLog.Warnf("couldn't get unit NAccepted unit %v err %v", unit.Name, err)
 			continue
 		}
 		tag["name"] = unit.Name
@@ -202,7 +208,7 @@ func (s *Systemd) collectSockets(slist *types.SampleList, units []unit) {
 
 		currentConnectionCount, err := s.conn.GetUnitTypePropertyContext(context.TODO(), unit.Name, "Socket", "NConnections")
 		if err != nil {
-			log.Println("W!", "couldn't get unit NConnections", "unit", unit.Name, "err", err)
+			systemdLog.Warnf("couldn't get unit NConnections unit %v err %v", unit.Name, err)
 			continue
 		}
 		slist.PushSample(inputName, "socket_current_connections",
@@ -211,7 +217,7 @@ func (s *Systemd) collectSockets(slist *types.SampleList, units []unit) {
 		// NRefused wasn't added until systemd 239.
 		refusedConnectionCount, err := s.conn.GetUnitTypePropertyContext(context.TODO(), unit.Name, "Socket", "NRefused")
 		if err != nil {
-			log.Printf("couldn't get unit '%s' NRefused: %s", unit.Name, err)
+			systemdLog.Warnf("couldn't get unit '%s' NRefused: %s", unit.Name, err)
 		} else {
 			slist.PushSample(inputName, "socket_refused_connections_total",
 				refusedConnectionCount.Value.Value().(uint32), tag)
@@ -229,7 +235,7 @@ func (s *Systemd) collectUnitStartTimeMetrics(slist *types.SampleList, units []u
 		} else {
 			timestampValue, err := s.conn.GetUnitPropertyContext(context.TODO(), unit.Name, "ActiveEnterTimestamp")
 			if err != nil {
-				log.Println("W!", "couldn't get unit StartTimeUsec", "unit", unit.Name, "err", err)
+				systemdLog.Warnf("couldn't get unit StartTimeUsec unit %v err %v", unit.Name, err)
 				continue
 			}
 			startTimeUsec = timestampValue.Value.Value().(uint64)
@@ -250,7 +256,7 @@ func (s *Systemd) collectUnitTasksMetrics(slist *types.SampleList, units []unit)
 		if strings.HasSuffix(unit.Name, ".service") {
 			tasksCurrentCount, err := s.conn.GetUnitTypePropertyContext(context.TODO(), unit.Name, "Service", "TasksCurrent")
 			if err != nil {
-				log.Println("E!", "couldn't get unit TasksCurrent", "unit", unit.Name, "err", err)
+				systemdLog.Errorf("couldn't get unit TasksCurrent unit %v err %v", unit.Name, err)
 			} else {
 				val = tasksCurrentCount.Value.Value().(uint64)
 				// Don't set if tasksCurrent if dbus reports MaxUint64.
@@ -260,7 +266,7 @@ func (s *Systemd) collectUnitTasksMetrics(slist *types.SampleList, units []unit)
 			}
 			tasksMaxCount, err := s.conn.GetUnitTypePropertyContext(context.TODO(), unit.Name, "Service", "TasksMax")
 			if err != nil {
-				log.Println("E!", "couldn't get unit TasksMax", "unit", unit.Name, "err", err)
+				systemdLog.Errorf("couldn't get unit TasksMax unit %v err %v", unit.Name, err)
 			} else {
 				val = tasksMaxCount.Value.Value().(uint64)
 				// Don't set if tasksMax if dbus reports MaxUint64.
@@ -282,7 +288,7 @@ func (s *Systemd) collectTimers(slist *types.SampleList, units []unit) {
 
 		lastTriggerValue, err := s.conn.GetUnitTypePropertyContext(context.TODO(), unit.Name, "Timer", "LastTriggerUSec")
 		if err != nil {
-			log.Println("W!", "couldn't get unit LastTriggerUSec", "unit", unit.Name, "err", err)
+			systemdLog.Warnf("couldn't get unit LastTriggerUSec unit %v err %v", unit.Name, err)
 			continue
 		}
 
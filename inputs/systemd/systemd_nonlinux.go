@@ -7,6 +7,8 @@ import (
 	"flashcat.cloud/categraf/types"
 )
 
+type dbusConn struct{}
+
 func (s *Systemd) Init() error {
 	return nil
 }
@@ -0,0 +1,212 @@
+package telemetry_dialout
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"flashcat.cloud/categraf/pkg/protox"
+)
+
+// telemetry is the common form of cisco and huawei telemetry messages
+type telemetry struct {
+	NodeID       string
+	Subscription string
+	Path         string
+	Timestamp    uint64 // ms
+	Rows         []row
+}
+
+type row struct {
+	Timestamp uint64 // ms
+	Keys      map[string]string
+	Fields    map[string]interface{}
+}
+
+// decodeCiscoGPB decodes cisco telemetry.proto with GPB-KV (self-describing) encoding,
+// see https://github.com/cisco/bigmuddy-network-telemetry-proto/blob/master/staging/telemetry.proto
+func decodeCiscoGPB(b []byte) (*telemetry, error) {
+	t := &telemetry{}
+	err := protox.Range(b, func(f protox.Field) error {
+		switch f.Num {
+		case 1:
+			t.NodeID = f.String()
+		case 3:
+			t.Subscription = f.String()
+		case 6:
+			t.Path = f.String()
+		case 10:
+			t.Timestamp = f.Varint
+		case 11:
+			r, err := decodeCiscoRow(f.Bytes)
+			if err != nil {
+				return err
+			}
+			t.Rows = append(t.Rows, r)
+		case 12:
+			return fmt.Errorf("compact gpb encoding is not supported, use self-describing-gpb")
+		}
+		return nil
+	})
+	return t, err
+}
+
+// decodeCiscoRow decodes a top level TelemetryField, which contains "keys" and "content"
+func decodeCiscoRow(b []byte) (row, error) {
+	r := row{Keys: map[string]string{}, Fields: map[string]interface{}{}}
+	err := protox.Range(b, func(f protox.Field) error {
+		switch f.Num {
+		case 1:
+			r.Timestamp = f.Varint
+		case 15:
+			name, _, children, err := decodeCiscoField(f.Bytes)
+			if err != nil {
+				return err
+			}
+			flat := map[string]interface{}{}
+			for _, c := range children {
+				flattenCiscoField(c, "", flat)
+			}
+			switch name {
+			case "keys":
+				for k, v := range flat {
+					r.Keys[k] = fmt.Sprint(v)
+				}
+			case "content":
+				for k, v := range flat {
+					r.Fields[k] = v
+				}
+			}
+		}
+		return nil
+	})
+	return r, err
+}
+
+type ciscoField struct {
+	name     string
+	value    interface{}
+	children []ciscoField
+}
+
+func decodeCiscoField(b []byte) (string, interface{}, []ciscoField, error) {
+	var (
+		name     string
+		value    interface{}
+		children []ciscoField
+	)
+	err := protox.Range(b, func(f protox.Field) error {
+		switch f.Num {
+		case 2:
+			name = f.String()
+		case 4:
+			value = f.String()
+		case 5:
+			value = f.String()
+		case 6:
+			value = f.Bool()
+		case 7, 8:
+			value = f.Varint
+		case 9, 10:
+			value = f.Sint64()
+		case 11:
+			value = f.Float64()
+		case 12:
+			value = float64(f.Float32())
+		case 15:
+			n, v, c, err := decodeCiscoField(f.Bytes)
+			if err != nil {
+				return err
+			}
+			children = append(children, ciscoField{name: n, value: v, children: c})
+		}
+		return nil
+	})
+	return name, value, children, err
+}
+
+func flattenCiscoField(f ciscoField, prefix string, out map[string]interface{}) {
+	name := f.name
+	if prefix != "" {
+		name = prefix + "/" + f.name
+	}
+	if len(f.children) == 0 {
+		if f.value != nil {
+			out[name] = f.value
+		}
+		return
+	}
+	for _, c := range f.children {
+		flattenCiscoField(c, name, out)
+	}
+}
+
+// decodeCiscoJSON decodes cisco json encoding:
+// {"node_id_str":"r1","subscription_id_str":"s1","encoding_path":"...","msg_timestamp":1,"data_json":[{"timestamp":1,"keys":{},"content":{}}]}
+func decodeCiscoJSON(b []byte) (*telemetry, error) {
+	var msg struct {
+		NodeID       string      `json:"node_id_str"`
+		Subscription string      `json:"subscription_id_str"`
+		Path         string      `json:"encoding_path"`
+		Timestamp    json.Number `json:"msg_timestamp"`
+		Data         []struct {
+			Timestamp json.Number            `json:"timestamp"`
+			Keys      map[string]interface{} `json:"keys"`
+			Content   map[string]interface{} `json:"content"`
+		} `json:"data_json"`
+	}
+	if err := json.Unmarshal(b, &msg); err != nil {
+		return nil, err
+	}
+
+	t := &telemetry{
+		NodeID:       msg.NodeID,
+		Subscription: msg.Subscription,
+		Path:         msg.Path,
+		Timestamp:    parseUint(msg.Timestamp),
+	}
+	for _, d := range msg.Data {
+		r := row{Timestamp: parseUint(d.Timestamp), Keys: map[string]string{}, Fields: map[string]interface{}{}}
+		flat := map[string]interface{}{}
+		flattenJSON(d.Keys, "", flat)
+		for k, v := range flat {
+			r.Keys[k] = fmt.Sprint(v)
+		}
+		flattenJSON(d.Content, "", r.Fields)
+		t.Rows = append(t.Rows, r)
+	}
+	return t, nil
+}
+
+func parseUint(n json.Number) uint64 {
+	v, _ := strconv.ParseUint(n.String(), 10, 64)
+	return v
+}
+
+func flattenJSON(v interface{}, prefix string, out map[string]interface{}) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, c := range t {
+			name := k
+			if prefix != "" {
+				name = prefix + "/" + k
+			}
+			flattenJSON(c, name, out)
+		}
+	case []interface{}:
+		// lists are skipped, they are usually multiple rows with keys
+	case nil:
+	default:
+		if prefix != "" {
+			out[prefix] = t
+		}
+	}
+}
+
+func msToTime(ms uint64) time.Time {
+	if ms == 0 {
+		return time.Now()
+	}
+	return time.UnixMilli(int64(ms))
+}
@@ -0,0 +1,146 @@
+package telemetry_dialout
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"flashcat.cloud/categraf/pkg/protox"
+)
+
+const huaweiEncodingJSON = 1
+
+// decodeHuaweiGPB decodes huawei telemetry.proto.
+// The row content of GPB encoding is defined by the proto of each sensor path, which is unknown here,
+// so only json encoding (data_str or json content) is supported
+func decodeHuaweiGPB(b []byte) (*telemetry, error) {
+	var (
+		t        = &telemetry{}
+		contents [][]byte
+		stamps   []uint64
+		dataStr  string
+		encoding uint64
+	)
+	err := protox.Range(b, func(f protox.Field) error {
+		switch f.Num {
+		case 1:
+			t.NodeID = f.String()
+		case 2:
+			t.Subscription = f.String()
+		case 3:
+			t.Path = f.String()
+		case 6:
+			t.Timestamp = f.Varint
+		case 7:
+			return protox.Range(f.Bytes, func(rf protox.Field) error {
+				if rf.Num != 1 {
+					return nil
+				}
+				var ts uint64
+				var content []byte
+				err := protox.Range(rf.Bytes, func(cf protox.Field) error {
+					switch cf.Num {
+					case 1:
+						ts = cf.Varint
+					case 11:
+						content = cf.Bytes
+					}
+					return nil
+				})
+				contents = append(contents, content)
+				stamps = append(stamps, ts)
+				return err
+			})
+		case 12:
+			encoding = f.Varint
+		case 14:
+			dataStr = f.String()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if dataStr != "" {
+		var obj interface{}
+		if err := json.Unmarshal([]byte(dataStr), &obj); err != nil {
+			return nil, fmt.Errorf("failed to decode data_str: %v", err)
+		}
+		t.Rows = append(t.Rows, huaweiRow(t.Timestamp, obj))
+		return t, nil
+	}
+
+	for i := range contents {
+		var obj interface{}
+		if err := json.Unmarshal(contents[i], &obj); err != nil {
+			if encoding != huaweiEncodingJSON {
+				return nil, fmt.Errorf("gpb content of %s is not supported, configure the sensor with json encoding", t.Path)
+			}
+			return nil, err
+		}
+		t.Rows = append(t.Rows, huaweiRow(stamps[i], obj))
+	}
+	return t, nil
+}
+
+// decodeHuaweiJSON decodes the data_json of serviceArgs
+func decodeHuaweiJSON(b []byte) (*telemetry, error) {
+	var msg struct {
+		NodeID       string      `json:"node_id_str"`
+		Subscription string      `json:"subscription_id_str"`
+		Path         string      `json:"sensor_path"`
+		Timestamp    json.Number `json:"msg_timestamp"`
+		DataStr      string      `json:"data_str"`
+		DataGPB      struct {
+			Row []struct {
+				Timestamp json.Number `json:"timestamp"`
+				Content   interface{} `json:"content"`
+			} `json:"row"`
+		} `json:"data_gpb"`
+	}
+	if err := json.Unmarshal(b, &msg); err != nil {
+		return nil, err
+	}
+
+	t := &telemetry{
+		NodeID:       msg.NodeID,
+		Subscription: msg.Subscription,
+		Path:         msg.Path,
+		Timestamp:    parseUint(msg.Timestamp),
+	}
+
+	if msg.DataStr != "" {
+		var obj interface{}
+		if err := json.Unmarshal([]byte(msg.DataStr), &obj); err != nil {
+			return nil, fmt.Errorf("failed to decode data_str: %v", err)
+		}
+		t.Rows = append(t.Rows, huaweiRow(t.Timestamp, obj))
+	}
+
+	for _, r := range msg.DataGPB.Row {
+		content := r.Content
+		if s, ok := content.(string); ok {
+			if err := json.Unmarshal([]byte(s), &content); err != nil {
+				return nil, fmt.Errorf("failed to decode row content: %v", err)
+			}
+		}
+		t.Rows = append(t.Rows, huaweiRow(parseUint(r.Timestamp), content))
+	}
+	return t, nil
+}
+
+// huaweiRow flattens the content, keys of huawei sensors are not distinguishable from values,
+// so string leaves are used as keys
+func huaweiRow(ts uint64, content interface{}) row {
+	r := row{Timestamp: ts, Keys: map[string]string{}, Fields: map[string]interface{}{}}
+	flat := map[string]interface{}{}
+	flattenJSON(content, "", flat)
+	for k, v := range flat {
+		if s, ok := v.(string); ok {
+			r.Keys[k] = s
+			continue
+		}
+		r.Fields[k] = v
+	}
+	return r
+}
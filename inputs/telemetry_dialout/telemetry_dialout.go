@@ -0,0 +1,354 @@
+package telemetry_dialout
+
+import (
+	"bufio"
+	"context"
+	cryptotls "crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/pkg/protox"
+	"flashcat.cloud/categraf/pkg/tls"
+	"flashcat.cloud/categraf/types"
+)
+
+var telemetryDialoutLog = logger.New("input.telemetry_dialout")
+
+const (
+	inputName = "telemetry_dialout"
+
+	ciscoMethod  = "/mdt_dialout.gRPCMdtDialout/MdtDialout"
+	huaweiMethod = "/huawei_dialout.gRPCDataservice/dataPublish"
+
+	// cisco tcp dial-out header, see https://github.com/cisco/bigmuddy-network-telemetry-pipeline
+	tcpHeaderSize   = 12
+	tcpEncapGPB     = 1
+	tcpEncapJSON    = 2
+	tcpMaxMsgSizeMB = 4
+)
+
+var metricReplacer = strings.NewReplacer("/", "_", "-", "_", ":", "_", ".", "_")
+
+type (
+	TelemetryDialout struct {
+		config.PluginConfig
+		Instances []*Instance `toml:"instances"`
+	}
+
+	Instance struct {
+		config.InstanceConfig
+
+		ServiceAddress string `toml:"service_address"`
+		// grpc: cisco and huawei grpc dial-out; tcp: cisco tcp dial-out
+		Transport  string            `toml:"transport"`
+		MaxMsgSize int               `toml:"max_msg_size"`
+		Aliases    map[string]string `toml:"aliases"`
+		tls.ServerConfig
+
+		listener net.Listener
+		grpcSrv  *grpc.Server
+		buffer   *types.SampleList
+		wg       sync.WaitGroup
+		cancel   context.CancelFunc
+	}
+)
+
+var _ inputs.SampleGatherer = new(Instance)
+var _ inputs.Input = new(TelemetryDialout)
+var _ inputs.InstancesGetter = new(TelemetryDialout)
+
+func init() {
+	inputs.Add(inputName, func() inputs.Input {
+		return &TelemetryDialout{}
+	})
+}
+
+func (t *TelemetryDialout) Clone() inputs.Input {
+	return &TelemetryDialout{}
+}
+
+func (t *TelemetryDialout) Name() string {
+	return inputName
+}
+
+func (t *TelemetryDialout) GetInstances() []inputs.Instance {
+	ret := make([]inputs.Instance, len(t.Instances))
+	for i := 0; i < len(t.Instances); i++ {
+		ret[i] = t.Instances[i]
+	}
+	return ret
+}
+
+func (t *TelemetryDialout) Drop() {
+	for i := 0; i < len(t.Instances); i++ {
+		t.Instances[i].stop()
+	}
+}
+
+func (ins *Instance) Init() error {
+	if ins.ServiceAddress == "" {
+		return types.ErrInstancesEmpty
+	}
+	if ins.Transport == "" {
+		ins.Transport = "grpc"
+	}
+	if ins.MaxMsgSize <= 0 {
+		ins.MaxMsgSize = tcpMaxMsgSizeMB * 1024 * 1024
+	}
+
+	tlsCfg, err := ins.ServerConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+
+	ins.listener, err = net.Listen("tcp", ins.ServiceAddress)
+	if err != nil {
+		return err
+	}
+
+	ins.buffer = types.NewSampleList()
+	ctx, cancel := context.WithCancel(context.Background())
+	ins.cancel = cancel
+
+	switch ins.Transport {
+	case "grpc":
+		opts := []grpc.ServerOption{
+			grpc.ForceServerCodec(protox.RawCodec{}),
+			grpc.MaxRecvMsgSize(ins.MaxMsgSize),
+			grpc.UnknownServiceHandler(ins.handleStream),
+		}
+		if tlsCfg != nil {
+			opts = append(opts, grpc.Creds(credentials.NewTLS(tlsCfg)))
+		}
+		ins.grpcSrv = grpc.NewServer(opts...)
+		ins.wg.Add(1)
+		go func() {
+			defer ins.wg.Done()
+			if err := ins.grpcSrv.Serve(ins.listener); err != nil {
+				telemetryDialoutLog.Errorf("telemetry dial-out grpc server stopped: %v", err)
+			}
+		}()
+	case "tcp":
+		if tlsCfg != nil {
+			ins.listener = cryptotls.NewListener(ins.listener, tlsCfg)
+		}
+		ins.wg.Add(1)
+		go ins.acceptTCP(ctx)
+	default:
+		ins.listener.Close()
+		return fmt.Errorf("unsupported transport: %s", ins.Transport)
+	}
+
+	telemetryDialoutLog.Infof("telemetry dial-out listening on %v transport: %v", ins.listener.Addr(), ins.Transport)
+	return nil
+}
+
+func (ins *Instance) stop() {
+	if ins.cancel == nil {
+		return
+	}
+	ins.cancel()
+	if ins.grpcSrv != nil {
+		ins.grpcSrv.Stop()
+	} else {
+		ins.listener.Close()
+	}
+	ins.wg.Wait()
+}
+
+// Gather drains the samples received since last gather
+func (ins *Instance) Gather(slist *types.SampleList) {
+	slist.PushFrontN(ins.buffer.PopBackAll())
+}
+
+// handleStream serves both cisco MdtDialout and huawei dataPublish, their stream messages are:
+// MdtDialoutArgs { int64 ReqId = 1; bytes data = 2; string errors = 3; }
+// serviceArgs { int64 ReqId = 1; bytes data = 2; string errors = 3; string data_json = 4; }
+func (ins *Instance) handleStream(srv interface{}, stream grpc.ServerStream) error {
+	method, _ := grpc.MethodFromServerStream(stream)
+	if method != ciscoMethod && method != huaweiMethod {
+		return fmt.Errorf("unknown method: %s", method)
+	}
+
+	source := ""
+	if p, ok := peer.FromContext(stream.Context()); ok {
+		source, _, _ = net.SplitHostPort(p.Addr.String())
+	}
+
+	for {
+		var msg []byte
+		if err := stream.RecvMsg(&msg); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		var data, dataJSON []byte
+		err := protox.Range(msg, func(f protox.Field) error {
+			switch f.Num {
+			case 2:
+				data = f.Bytes
+			case 3:
+				if len(f.Bytes) > 0 {
+					telemetryDialoutLog.Errorf("telemetry dial-out %v reports error: %v", source, f.String())
+				}
+			case 4:
+				dataJSON = f.Bytes
+			}
+			return nil
+		})
+		if err != nil {
+			telemetryDialoutLog.Errorf("failed to decode dial-out message from %v error: %v", source, err)
+			continue
+		}
+
+		var t *telemetry
+		switch {
+		case method == ciscoMethod && len(data) > 0:
+			t, err = decodeCisco(data)
+		case method == huaweiMethod && len(dataJSON) > 0:
+			t, err = decodeHuaweiJSON(dataJSON)
+		case method == huaweiMethod && len(data) > 0:
+			t, err = decodeHuaweiGPB(data)
+		default:
+			continue
+		}
+		if err != nil {
+			telemetryDialoutLog.Errorf("failed to decode telemetry from %v error: %v", source, err)
+			continue
+		}
+		ins.push(source, t)
+	}
+}
+
+func decodeCisco(data []byte) (*telemetry, error) {
+	if len(data) > 0 && data[0] == '{' {
+		return decodeCiscoJSON(data)
+	}
+	return decodeCiscoGPB(data)
+}
+
+func (ins *Instance) acceptTCP(ctx context.Context) {
+	defer ins.wg.Done()
+	for {
+		conn, err := ins.listener.Accept()
+		if err != nil {
+			if ctx.Err() == nil {
+				telemetryDialoutLog.Errorf("telemetry dial-out tcp accept error: %v", err)
+			}
+			return
+		}
+
+		ins.wg.Add(1)
+		go ins.handleTCP(ctx, conn)
+	}
+}
+
+func (ins *Instance) handleTCP(ctx context.Context, conn net.Conn) {
+	defer ins.wg.Done()
+
+	// the blocked reads return once conn is closed on shutdown
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		conn.Close()
+	}()
+
+	source, _, _ := net.SplitHostPort(conn.RemoteAddr().String())
+	r := bufio.NewReader(conn)
+	header := make([]byte, tcpHeaderSize)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			if err != io.EOF {
+				telemetryDialoutLog.Errorf("failed to read dial-out header from %v error: %v", source, err)
+			}
+			return
+		}
+
+		encap := binary.BigEndian.Uint16(header[2:4])
+		size := binary.BigEndian.Uint32(header[8:12])
+		if int(size) > ins.MaxMsgSize {
+			telemetryDialoutLog.Errorf("dial-out message from %v too large: %v", source, size)
+			return
+		}
+
+		payload := make([]byte, size)
+		if _, err := io.ReadFull(r, payload); err != nil {
+			telemetryDialoutLog.Errorf("failed to read dial-out payload from %v error: %v", source, err)
+			return
+		}
+
+		var (
+			t   *telemetry
+			err error
+		)
+		switch encap {
+		case tcpEncapGPB:
+			t, err = decodeCiscoGPB(payload)
+		case tcpEncapJSON:
+			t, err = decodeCiscoJSON(payload)
+		default:
+			err = fmt.Errorf("unknown encap: %d", encap)
+		}
+		if err != nil {
+			telemetryDialoutLog.Errorf("failed to decode telemetry from %v error: %v", source, err)
+			continue
+		}
+		ins.push(source, t)
+	}
+}
+
+func (ins *Instance) push(source string, t *telemetry) {
+	prefix := ins.measurement(t.Path)
+	for _, r := range t.Rows {
+		labels := map[string]string{
+			"source":       source,
+			"node_id":      t.NodeID,
+			"subscription": t.Subscription,
+			"path":         t.Path,
+		}
+		for k, v := range r.Keys {
+			labels[metricReplacer.Replace(k)] = v
+		}
+
+		ts := r.Timestamp
+		if ts == 0 {
+			ts = t.Timestamp
+		}
+		tm := msToTime(ts)
+
+		for field, value := range r.Fields {
+			name := prefix + "_" + metricReplacer.Replace(field)
+			ins.buffer.PushFront(types.NewSample(inputName, name, value, labels).SetTime(tm))
+		}
+	}
+}
+
+// measurement uses the alias of the path, or the path without yang module prefix
+func (ins *Instance) measurement(path string) string {
+	for alias, p := range ins.Aliases {
+		if p == path {
+			return alias
+		}
+	}
+	if idx := strings.Index(path, ":"); idx >= 0 {
+		path = path[idx+1:]
+	}
+	return metricReplacer.Replace(path)
+}
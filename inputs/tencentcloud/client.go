@@ -0,0 +1,201 @@
+package tencentcloud
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	monitorService = "monitor"
+	monitorVersion = "2018-07-24"
+	signAlgorithm  = "TC3-HMAC-SHA256"
+	camEndpoint    = "http://metadata.tencentyun.com/latest/meta-data/cam/security-credentials/"
+)
+
+type credential struct {
+	secretID  string
+	secretKey string
+	token     string
+	expiredAt time.Time
+}
+
+// client is a minimal tencent cloud api 3.0 client, only the requests of cloud monitor are needed
+type client struct {
+	endpoint string
+	region   string
+	camRole  string
+	http     *http.Client
+
+	sync.Mutex
+	cred credential
+}
+
+func newClient(endpoint, region, secretID, secretKey, token, camRole string, timeout time.Duration) *client {
+	return &client{
+		endpoint: endpoint,
+		region:   region,
+		camRole:  camRole,
+		http:     &http.Client{Timeout: timeout},
+		cred: credential{
+			secretID:  secretID,
+			secretKey: secretKey,
+			token:     token,
+		},
+	}
+}
+
+// credential returns the static secret, or the temporary secret of the cam role bound to the cvm
+func (c *client) credential(ctx context.Context) (credential, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.camRole == "" {
+		return c.cred, nil
+	}
+
+	// refresh 5 minutes before expiration
+	if c.cred.secretID != "" && time.Until(c.cred.expiredAt) > 5*time.Minute {
+		return c.cred, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, camEndpoint+c.camRole, nil)
+	if err != nil {
+		return credential{}, err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return credential{}, fmt.Errorf("failed to get credentials of cam role %s: %v", c.camRole, err)
+	}
+	defer resp.Body.Close()
+
+	var ret struct {
+		TmpSecretID  string `json:"TmpSecretId"`
+		TmpSecretKey string `json:"TmpSecretKey"`
+		Token        string `json:"Token"`
+		ExpiredTime  int64  `json:"ExpiredTime"`
+		Code         string `json:"Code"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&ret); err != nil {
+		return credential{}, fmt.Errorf("failed to decode credentials of cam role %s: %v", c.camRole, err)
+	}
+	if ret.Code != "Success" {
+		return credential{}, fmt.Errorf("failed to get credentials of cam role %s, code: %s", c.camRole, ret.Code)
+	}
+
+	c.cred = credential{
+		secretID:  ret.TmpSecretID,
+		secretKey: ret.TmpSecretKey,
+		token:     ret.Token,
+		expiredAt: time.Unix(ret.ExpiredTime, 0),
+	}
+	return c.cred, nil
+}
+
+type apiError struct {
+	Code    string `json:"Code"`
+	Message string `json:"Message"`
+}
+
+// call sends a signed request, see https://cloud.tencent.com/document/api/248/30353
+func (c *client) call(ctx context.Context, action string, request, response interface{}) error {
+	cred, err := c.credential(ctx)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://"+c.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Host", c.endpoint)
+	req.Header.Set("X-TC-Action", action)
+	req.Header.Set("X-TC-Version", monitorVersion)
+	req.Header.Set("X-TC-Region", c.region)
+	req.Header.Set("X-TC-Timestamp", strconv.FormatInt(now.Unix(), 10))
+	if cred.token != "" {
+		req.Header.Set("X-TC-Token", cred.token)
+	}
+	req.Header.Set("Authorization", sign(cred, c.endpoint, payload, now))
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	var wrapper struct {
+		Response json.RawMessage `json:"Response"`
+	}
+	if err := json.Unmarshal(body, &wrapper); err != nil {
+		return fmt.Errorf("failed to decode response of %s: %v, status code: %d", action, err, resp.StatusCode)
+	}
+
+	var e struct {
+		Error     *apiError `json:"Error"`
+		RequestID string    `json:"RequestId"`
+	}
+	if err := json.Unmarshal(wrapper.Response, &e); err != nil {
+		return err
+	}
+	if e.Error != nil {
+		return fmt.Errorf("%s failed, code: %s, message: %s, request id: %s", action, e.Error.Code, e.Error.Message, e.RequestID)
+	}
+
+	return json.Unmarshal(wrapper.Response, response)
+}
+
+func sign(cred credential, host string, payload []byte, now time.Time) string {
+	date := now.Format("2006-01-02")
+	canonicalRequest := "POST\n/\n\n" +
+		"content-type:application/json; charset=utf-8\n" +
+		"host:" + host + "\n\n" +
+		"content-type;host\n" +
+		sha256hex(payload)
+
+	scope := date + "/" + monitorService + "/tc3_request"
+	stringToSign := signAlgorithm + "\n" +
+		strconv.FormatInt(now.Unix(), 10) + "\n" +
+		scope + "\n" +
+		sha256hex([]byte(canonicalRequest))
+
+	secretDate := hmacsha256([]byte("TC3"+cred.secretKey), date)
+	secretService := hmacsha256(secretDate, monitorService)
+	secretSigning := hmacsha256(secretService, "tc3_request")
+	signature := hex.EncodeToString(hmacsha256(secretSigning, stringToSign))
+
+	return fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=content-type;host, Signature=%s",
+		signAlgorithm, cred.secretID, scope, signature)
+}
+
+func sha256hex(b []byte) string {
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+func hmacsha256(key []byte, msg string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(msg))
+	return h.Sum(nil)
+}
@@ -0,0 +1,235 @@
+package tencentcloud
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/limiter"
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/pkg/stringx"
+	"flashcat.cloud/categraf/types"
+)
+
+var tencentcloudLog = logger.New("input.tencentcloud")
+
+const (
+	inputName = "tencentcloud"
+
+	// GetMonitorData accepts at most 10 instances per request
+	maxInstancesPerRequest = 10
+)
+
+type (
+	TencentCloud struct {
+		config.PluginConfig
+		Instances []*Instance `toml:"instances"`
+	}
+
+	Instance struct {
+		config.InstanceConfig
+
+		Region    string `toml:"region"`
+		Endpoint  string `toml:"endpoint"`
+		SecretID  string `toml:"secret_id"`
+		SecretKey string `toml:"secret_key"`
+		Token     string `toml:"token"`
+		// CamRole fetches temporary credentials of the cam role bound to the cvm
+		CamRole string `toml:"cam_role"`
+
+		Namespace string          `toml:"namespace"`
+		Metrics   []string        `toml:"metrics"`
+		Targets   []*Target       `toml:"targets"`
+		Period    config.Duration `toml:"period"`
+		Delay     config.Duration `toml:"delay"`
+		RateLimit int             `toml:"ratelimit"`
+		Timeout   config.Duration `toml:"timeout"`
+
+		client      *client
+		windowStart time.Time
+		windowEnd   time.Time
+	}
+
+	Target struct {
+		Dimensions map[string]string `toml:"dimensions"`
+	}
+)
+
+type (
+	dimension struct {
+		Name  string `json:"Name"`
+		Value string `json:"Value"`
+	}
+
+	monitorInstance struct {
+		Dimensions []dimension `json:"Dimensions"`
+	}
+
+	getMonitorDataRequest struct {
+		Namespace  string            `json:"Namespace"`
+		MetricName string            `json:"MetricName"`
+		Period     uint64            `json:"Period"`
+		StartTime  string            `json:"StartTime"`
+		EndTime    string            `json:"EndTime"`
+		Instances  []monitorInstance `json:"Instances"`
+	}
+
+	getMonitorDataResponse struct {
+		DataPoints []struct {
+			Dimensions []dimension `json:"Dimensions"`
+			Timestamps []float64   `json:"Timestamps"`
+			Values     []float64   `json:"Values"`
+		} `json:"DataPoints"`
+	}
+)
+
+var _ inputs.SampleGatherer = new(Instance)
+var _ inputs.Input = new(TencentCloud)
+var _ inputs.InstancesGetter = new(TencentCloud)
+
+func init() {
+	inputs.Add(inputName, func() inputs.Input {
+		return &TencentCloud{}
+	})
+}
+
+func (t *TencentCloud) Clone() inputs.Input {
+	return &TencentCloud{}
+}
+
+func (t *TencentCloud) Name() string {
+	return inputName
+}
+
+func (t *TencentCloud) GetInstances() []inputs.Instance {
+	ret := make([]inputs.Instance, len(t.Instances))
+	for i := 0; i < len(t.Instances); i++ {
+		ret[i] = t.Instances[i]
+	}
+	return ret
+}
+
+func (ins *Instance) Init() error {
+	if ins.Region == "" || ins.Namespace == "" || len(ins.Metrics) == 0 || len(ins.Targets) == 0 {
+		return types.ErrInstancesEmpty
+	}
+
+	if ins.CamRole == "" && (ins.SecretID == "" || ins.SecretKey == "") {
+		return fmt.Errorf("secret_id and secret_key are required if cam_role is empty")
+	}
+
+	if ins.Endpoint == "" {
+		ins.Endpoint = "monitor.tencentcloudapi.com"
+	}
+	if ins.Period == 0 {
+		ins.Period = config.Duration(time.Minute)
+	}
+	if ins.Delay == 0 {
+		ins.Delay = config.Duration(2 * time.Minute)
+	}
+	if ins.RateLimit <= 0 {
+		ins.RateLimit = 20
+	}
+	if ins.Timeout == 0 {
+		ins.Timeout = config.Duration(5 * time.Second)
+	}
+
+	ins.client = newClient(ins.Endpoint, ins.Region, ins.SecretID, ins.SecretKey, ins.Token, ins.CamRole, time.Duration(ins.Timeout))
+	return nil
+}
+
+func (ins *Instance) Gather(slist *types.SampleList) {
+	ins.updateWindow(time.Now())
+
+	lmtr := limiter.NewRateLimiter(ins.RateLimit, time.Second)
+	defer lmtr.Stop()
+
+	wg := new(sync.WaitGroup)
+	for _, metric := range ins.Metrics {
+		for start := 0; start < len(ins.Targets); start += maxInstancesPerRequest {
+			end := start + maxInstancesPerRequest
+			if end > len(ins.Targets) {
+				end = len(ins.Targets)
+			}
+
+			<-lmtr.C
+			wg.Add(1)
+			go func(metric string, targets []*Target) {
+				defer wg.Done()
+				ins.gatherMetric(slist, metric, targets)
+			}(metric, ins.Targets[start:end])
+		}
+	}
+	wg.Wait()
+}
+
+func (ins *Instance) gatherMetric(slist *types.SampleList, metric string, targets []*Target) {
+	req := getMonitorDataRequest{
+		Namespace:  ins.Namespace,
+		MetricName: metric,
+		Period:     uint64(time.Duration(ins.Period).Seconds()),
+		StartTime:  ins.windowStart.Format(time.RFC3339),
+		EndTime:    ins.windowEnd.Format(time.RFC3339),
+		Instances:  make([]monitorInstance, 0, len(targets)),
+	}
+	for _, t := range targets {
+		mi := monitorInstance{}
+		for k, v := range t.Dimensions {
+			mi.Dimensions = append(mi.Dimensions, dimension{Name: k, Value: v})
+		}
+		req.Instances = append(req.Instances, mi)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(ins.Timeout))
+	defer cancel()
+
+	var resp getMonitorDataResponse
+	if err := ins.client.call(ctx, "GetMonitorData", req, &resp); err != nil {
+		tencentcloudLog.Errorf("failed to get monitor data, namespace: %v metric: %v error: %v", ins.Namespace, metric, err)
+		return
+	}
+
+	name := snakeCase(strings.ReplaceAll(ins.Namespace, "/", "_")) + "_" + snakeCase(metric)
+	for _, dp := range resp.DataPoints {
+		labels := map[string]string{
+			"region":    ins.Region,
+			"namespace": ins.Namespace,
+		}
+		for _, d := range dp.Dimensions {
+			labels[snakeCase(d.Name)] = d.Value
+		}
+
+		for i := range dp.Values {
+			if i >= len(dp.Timestamps) {
+				break
+			}
+			ts := time.Unix(int64(dp.Timestamps[i]), 0)
+			slist.PushFront(types.NewSample(inputName, name, dp.Values[i], labels).SetTime(ts))
+		}
+	}
+}
+
+func (ins *Instance) updateWindow(relativeTo time.Time) {
+	windowEnd := relativeTo.Add(-time.Duration(ins.Delay))
+
+	if ins.windowEnd.IsZero() {
+		// this is the first run, no window info, so just get a single period
+		ins.windowStart = windowEnd.Add(-time.Duration(ins.Period))
+	} else {
+		// subsequent window, start where last window left off
+		ins.windowStart = ins.windowEnd
+	}
+
+	ins.windowEnd = windowEnd
+}
+
+func snakeCase(s string) string {
+	s = stringx.SnakeCase(s)
+	s = strings.ReplaceAll(s, " ", "_")
+	s = strings.ReplaceAll(s, "__", "_")
+	return s
+}
@@ -2,7 +2,6 @@ package tomcat
 
 import (
 	"encoding/xml"
-	"log"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -10,10 +9,13 @@ import (
 
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/pkg/tls"
 	"flashcat.cloud/categraf/types"
 )
 
+var tomcatLog = logger.New("input.tomcat")
+
 const inputName = "tomcat"
 
 type TomcatStatus struct {
@@ -169,13 +171,13 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 	resp, err := ins.client.Do(ins.request)
 	if err != nil {
 		slist.PushFront(types.NewSample(inputName, "up", 0, tags))
-		log.Println("E! failed to query tomcat url:", err)
+		tomcatLog.Errorf("failed to query tomcat url: %v", err)
 		return
 	}
 
 	if resp.StatusCode != http.StatusOK {
 		slist.PushFront(types.NewSample(inputName, "up", 0, tags))
-		log.Println("E! received HTTP status code:", resp.StatusCode, "expected: 200")
+		tomcatLog.Errorf("received HTTP status code: %v expected: 200", resp.StatusCode)
 		return
 	}
 
@@ -184,7 +186,7 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 	var status TomcatStatus
 	if err := xml.NewDecoder(resp.Body).Decode(&status); err != nil {
 		slist.PushFront(types.NewSample(inputName, "up", 0, tags))
-		log.Println("E! failed to decode response body:", err)
+		tomcatLog.Errorf("failed to decode response body: %v", err)
 		return
 	}
 
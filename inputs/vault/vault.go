@@ -0,0 +1,244 @@
+package vault
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/parser/prometheus"
+	"flashcat.cloud/categraf/pkg/httpx"
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/pkg/tls"
+	"flashcat.cloud/categraf/types"
+)
+
+var vaultLog = logger.New("input.vault")
+
+const (
+	inputName = "vault"
+
+	// standbys, sealed and uninitialized nodes return 200 as well, the state is read from the body
+	healthPath      = "/v1/sys/health?standbyok=true&perfstandbyok=true&sealedcode=200&uninitcode=200&drsecondarycode=200&performancestandbycode=200"
+	sealStatusPath  = "/v1/sys/seal-status"
+	leaderPath      = "/v1/sys/leader"
+	lookupSelfPath  = "/v1/auth/token/lookup-self"
+	metricsPath     = "/v1/sys/metrics?format=prometheus"
+	tokenHeader     = "X-Vault-Token"
+	namespaceHeader = "X-Vault-Namespace"
+)
+
+type Vault struct {
+	config.PluginConfig
+	Instances []*Instance `toml:"instances"`
+}
+
+type Instance struct {
+	config.InstanceConfig
+
+	URL string `toml:"url"`
+	// the token with read capability of sys/metrics, which is not required if unauthenticated_metrics_access is enabled,
+	// the ttl of the token is reported as well, without it only health, seal status and leader are gathered
+	Token     string          `toml:"token"`
+	Namespace string          `toml:"namespace"`
+	Timeout   config.Duration `toml:"timeout"`
+	tls.ClientConfig
+
+	// gather the telemetry of sys/metrics, e.g. vault_token_count and vault_expire_num_leases
+	GatherMetrics bool `toml:"gather_metrics"`
+
+	client *http.Client
+}
+
+var _ inputs.SampleGatherer = new(Instance)
+var _ inputs.Input = new(Vault)
+var _ inputs.InstancesGetter = new(Vault)
+
+func init() {
+	inputs.Add(inputName, func() inputs.Input {
+		return &Vault{}
+	})
+}
+
+func (v *Vault) Clone() inputs.Input {
+	return &Vault{}
+}
+
+func (v *Vault) Name() string {
+	return inputName
+}
+
+func (v *Vault) GetInstances() []inputs.Instance {
+	ret := make([]inputs.Instance, len(v.Instances))
+	for i := 0; i < len(v.Instances); i++ {
+		ret[i] = v.Instances[i]
+	}
+	return ret
+}
+
+func (ins *Instance) Init() error {
+	if ins.URL == "" {
+		return types.ErrInstancesEmpty
+	}
+	if ins.Timeout <= 0 {
+		ins.Timeout = config.Duration(5 * time.Second)
+	}
+	ins.URL = strings.TrimRight(ins.URL, "/")
+
+	tlsConfig, err := ins.ClientConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+	ins.client = &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		},
+		Timeout: time.Duration(ins.Timeout),
+	}
+	return nil
+}
+
+func (ins *Instance) Gather(slist *types.SampleList) {
+	var health struct {
+		Initialized        bool `json:"initialized"`
+		Sealed             bool `json:"sealed"`
+		Standby            bool `json:"standby"`
+		PerformanceStandby bool `json:"performance_standby"`
+	}
+	if err := ins.get(healthPath, &health); err != nil {
+		vaultLog.Errorf("failed to get health of vault: %v error: %v", ins.URL, err)
+		slist.PushSample(inputName, "up", 0)
+		return
+	}
+	slist.PushSample(inputName, "up", 1)
+	slist.PushSamples(inputName, map[string]interface{}{
+		"initialized":         health.Initialized,
+		"sealed":              health.Sealed,
+		"standby":             health.Standby,
+		"performance_standby": health.PerformanceStandby,
+	})
+
+	var seal struct {
+		Threshold float64 `json:"t"`
+		Shares    float64 `json:"n"`
+		Progress  float64 `json:"progress"`
+	}
+	if err := ins.get(sealStatusPath, &seal); err != nil {
+		vaultLog.Errorf("failed to get seal status of vault: %v error: %v", ins.URL, err)
+	} else {
+		slist.PushSamples(inputName, map[string]interface{}{
+			"seal_threshold":       seal.Threshold,
+			"seal_shares":          seal.Shares,
+			"seal_unseal_progress": seal.Progress,
+		})
+	}
+
+	// the leader is unavailable while sealed
+	if !health.Sealed {
+		var leader struct {
+			HAEnabled bool `json:"ha_enabled"`
+			IsSelf    bool `json:"is_self"`
+		}
+		if err := ins.get(leaderPath, &leader); err != nil {
+			vaultLog.Errorf("failed to get leader of vault: %v error: %v", ins.URL, err)
+		} else if leader.HAEnabled {
+			slist.PushSample(inputName, "ha_active", leader.IsSelf)
+		}
+	}
+
+	if ins.Token != "" {
+		ins.gatherToken(slist)
+	}
+	if ins.GatherMetrics && !health.Sealed {
+		ins.gatherMetrics(slist)
+	}
+}
+
+// gatherToken reports the ttl of the token, the metrics are lost after the token expires
+func (ins *Instance) gatherToken(slist *types.SampleList) {
+	var self struct {
+		Data struct {
+			TTL float64 `json:"ttl"`
+		} `json:"data"`
+	}
+	if err := ins.get(lookupSelfPath, &self); err != nil {
+		vaultLog.Errorf("failed to lookup token of vault: %v error: %v", ins.URL, err)
+		return
+	}
+	// 0 is a root token without ttl
+	if self.Data.TTL > 0 {
+		slist.PushSample(inputName, "token_ttl_seconds", self.Data.TTL)
+	}
+}
+
+// gatherMetrics reports the telemetry of vault as is, the names are prefixed with vault_ already
+func (ins *Instance) gatherMetrics(slist *types.SampleList) {
+	resp, err := ins.do(metricsPath)
+	if err != nil {
+		vaultLog.Errorf("failed to get metrics of vault: %v error: %v", ins.URL, err)
+		return
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		vaultLog.Errorf("failed to read metrics of vault: %v error: %v", ins.URL, err)
+		return
+	}
+	parser := prometheus.NewParser("", nil, resp.Header, nil, nil)
+	if err := parser.Parse(body, slist); err != nil {
+		vaultLog.Errorf("failed to parse metrics of vault: %v error: %v", ins.URL, err)
+	}
+}
+
+func (ins *Instance) get(path string, v interface{}) error {
+	req, err := ins.newRequest(path)
+	if err != nil {
+		return err
+	}
+	_, err = httpx.DoJSON(ins.client, req, apiName(path), v)
+	return err
+}
+
+// do returns the response of path if the status is 200, the body must be closed by the caller
+func (ins *Instance) do(path string) (*http.Response, error) {
+	req, err := ins.newRequest(path)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := ins.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		return nil, fmt.Errorf("%s returned HTTP status %s: %q", apiName(path), resp.Status, body)
+	}
+	return resp, nil
+}
+
+func (ins *Instance) newRequest(path string) (*http.Request, error) {
+	req, err := http.NewRequest(http.MethodGet, ins.URL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if ins.Token != "" {
+		req.Header.Set(tokenHeader, ins.Token)
+	}
+	if ins.Namespace != "" {
+		req.Header.Set(namespaceHeader, ins.Namespace)
+	}
+	return req, nil
+}
+
+// apiName is path without the query in the errors
+func apiName(path string) string {
+	return strings.SplitN(path, "?", 2)[0]
+}
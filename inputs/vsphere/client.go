@@ -4,7 +4,6 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
-	"log"
 	"net/url"
 	"strconv"
 	"strings"
@@ -96,7 +95,7 @@ func (cf *ClientFactory) testClient(ctx context.Context) error {
 	ctx1, cancel1 := context.WithTimeout(ctx, time.Duration(cf.parent.Timeout))
 	defer cancel1()
 	if _, err := methods.GetCurrentTime(ctx1, cf.client.Client); err != nil {
-		log.Println("I! Client session seems to have time out. Reauthenticating!")
+		vsphereLog.Infof("Client session seems to have time out. Reauthenticating!")
 		ctx2, cancel2 := context.WithTimeout(ctx, time.Duration(cf.parent.Timeout))
 		defer cancel2()
 		if err := cf.client.Client.SessionManager.Login(ctx2, url.UserPassword(cf.parent.Username, cf.parent.Password)); err != nil {
@@ -124,7 +123,7 @@ func NewClient(ctx context.Context, vSphereURL *url.URL, vs *Instance) (*Client,
 		vSphereURL.User = url.UserPassword(vs.Username, vs.Password)
 	}
 	if config.Config.DebugMode {
-		log.Println("D! Creating client: ", vSphereURL.Host)
+		vsphereLog.Debugf("Creating client:  %v", vSphereURL.Host)
 	}
 	soapClient := soap.NewClient(vSphereURL, tlsCfg.InsecureSkipVerify)
 
@@ -197,10 +196,10 @@ func NewClient(ctx context.Context, vSphereURL *url.URL, vs *Instance) (*Client,
 		return nil, err
 	}
 	if config.Config.DebugMode {
-		log.Println("D! vCenter says max_query_metrics should be ", n)
+		vsphereLog.Debugf("vCenter says max_query_metrics should be  %v", n)
 	}
 	if n < vs.MaxQueryMetrics {
-		log.Println("W! Configured max_query_metrics is %d, but server limits it to %d. Reducing.", vs.MaxQueryMetrics, n)
+		vsphereLog.Warnf("Configured max_query_metrics is %d, but server limits it to %d. Reducing.", vs.MaxQueryMetrics, n)
 		vs.MaxQueryMetrics = n
 	}
 	return client, nil
@@ -223,7 +222,7 @@ func (c *Client) close() {
 		defer cancel()
 		if c.Client != nil {
 			if err := c.Client.Logout(ctx); err != nil {
-				log.Println("E! Logout: ", err.Error())
+				vsphereLog.Errorf("Logout:  %v", err.Error())
 			}
 		}
 	})
@@ -253,7 +252,7 @@ func (c *Client) GetMaxQueryMetrics(ctx context.Context) (int, error) {
 				v, err := strconv.Atoi(s)
 				if err == nil {
 					if config.Config.DebugMode {
-						log.Println("D! vCenter maxQueryMetrics is defined: %d", v)
+						vsphereLog.Debugf("vCenter maxQueryMetrics is defined: %d", v)
 					}
 					if v == -1 {
 						// Whatever the server says, we never ask for more metrics than this.
@@ -265,17 +264,17 @@ func (c *Client) GetMaxQueryMetrics(ctx context.Context) (int, error) {
 			// Fall through version-based inference if value isn't usable
 		}
 	} else {
-		log.Println("W! Option query for maxQueryMetrics failed. Using default")
+		vsphereLog.Warnf("Option query for maxQueryMetrics failed. Using default")
 	}
 
 	// No usable maxQueryMetrics setting. Infer based on version
 	ver := c.Client.Client.ServiceContent.About.Version
 	parts := strings.Split(ver, ".")
 	if len(parts) < 2 {
-		log.Println("W! vCenter returned an invalid version string: %s. Using default query size=64", ver)
+		vsphereLog.Warnf("vCenter returned an invalid version string: %s. Using default query size=64", ver)
 		return 64, nil
 	}
-	log.Println("I! vCenter version is: ", ver)
+	vsphereLog.Infof("vCenter version is:  %v", ver)
 	major, err := strconv.Atoi(parts[0])
 	if err != nil {
 		return 0, err
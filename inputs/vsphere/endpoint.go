@@ -3,7 +3,6 @@ package vsphere
 import (
 	"context"
 	"fmt"
-	"log"
 	"math"
 	"math/rand"
 	"net/url"
@@ -256,7 +255,7 @@ func anythingEnabled(ex []string) bool {
 func newFilterOrPanic(include []string, exclude []string) filter.Filter {
 	f, err := filter.NewIncludeExcludeFilter(include, exclude)
 	if err != nil {
-		log.Printf("E! Include/exclude filters are invalid: ", err)
+		vsphereLog.Errorf("Include/exclude filters are invalid: %v", err)
 		return nil
 	}
 	return f
@@ -282,10 +281,10 @@ func (e *Endpoint) startDiscovery(ctx context.Context) {
 			case <-e.discoveryTicker.C:
 				err := e.discover(ctx)
 				if err != nil && err != context.Canceled {
-					log.Println(fmt.Sprintf("E! Discovery for %s: %s", e.URL.Host, err.Error()))
+					vsphereLog.Infof("%v", fmt.Sprintf("E! Discovery for %s: %s", e.URL.Host, err.Error()))
 				}
 			case <-ctx.Done():
-				log.Println("D! Exiting discovery goroutine for ", e.URL.Host)
+				vsphereLog.Debugf("Exiting discovery goroutine for  %v", e.URL.Host)
 				e.discoveryTicker.Stop()
 				return
 			}
@@ -296,7 +295,7 @@ func (e *Endpoint) startDiscovery(ctx context.Context) {
 func (e *Endpoint) initalDiscovery(ctx context.Context) {
 	err := e.discover(ctx)
 	if err != nil && err != context.Canceled {
-		log.Println(fmt.Sprintf("E! Discovery for %s: %s", e.URL.Host, err.Error()))
+		vsphereLog.Infof("%v", fmt.Sprintf("E! Discovery for %s: %s", e.URL.Host, err.Error()))
 	}
 	e.startDiscovery(ctx)
 }
@@ -311,14 +310,14 @@ func (e *Endpoint) init(ctx context.Context) error {
 	if e.customAttrEnabled {
 		fields, err := client.GetCustomFields(ctx)
 		if err != nil {
-			log.Println("W! Could not load custom field metadata")
+			vsphereLog.Warnf("Could not load custom field metadata")
 		} else {
 			e.customFields = fields
 		}
 	}
 
 	if time.Duration(e.Parent.ObjectDiscoveryInterval) > 0 {
-		log.Println("D! Running initial discovery")
+		vsphereLog.Debugf("Running initial discovery")
 		e.initalDiscovery(ctx)
 	}
 	e.initialized = true
@@ -388,7 +387,7 @@ func (e *Endpoint) getAncestorName(ctx context.Context, client *Client, resource
 			defer cancel1()
 			err := o.Properties(ctx1, here, []string{"parent", "name"}, &result)
 			if err != nil {
-				log.Printf("W! Error while resolving parent. Assuming no parent exists. Error: %s", err.Error())
+				vsphereLog.Warnf("Error while resolving parent. Assuming no parent exists. Error: %s", err.Error())
 				return true
 			}
 			if result.Reference().Type == resourceType {
@@ -398,7 +397,7 @@ func (e *Endpoint) getAncestorName(ctx context.Context, client *Client, resource
 			}
 			if result.Parent == nil {
 				if config.Config.DebugMode {
-					log.Printf("D! No parent found for %s (ascending from %s)", here.Reference(), r.Reference())
+					vsphereLog.Debugf("No parent found for %s (ascending from %s)", here.Reference(), r.Reference())
 				}
 
 				return true
@@ -430,7 +429,7 @@ func (e *Endpoint) discover(ctx context.Context) error {
 		return err
 	}
 	if config.Config.DebugMode {
-		log.Printf("D! Discover new objects for %s", e.URL.Host)
+		vsphereLog.Debugf("Discover new objects for %s", e.URL.Host)
 	}
 	dcNameCache := make(map[string]string)
 
@@ -440,7 +439,7 @@ func (e *Endpoint) discover(ctx context.Context) error {
 	newObjects := make(map[string]objectMap)
 	for k, res := range e.resourceKinds {
 		if config.Config.DebugMode {
-			log.Printf("D! Discovering resources for %s", res.name)
+			vsphereLog.Debugf("Discovering resources for %s", res.name)
 		}
 		// Need to do this for all resource types even if they are not enabled
 		if res.enabled || k != "vm" {
@@ -477,7 +476,7 @@ func (e *Endpoint) discover(ctx context.Context) error {
 			}
 			newObjects[k] = objects
 			if config.Config.DebugMode {
-				log.Printf("D! discovered_objects  type is : %s   and number is : %d ", res.name, int64(len(objects)))
+				vsphereLog.Debugf("discovered_objects  type is : %s   and number is : %d ", res.name, int64(len(objects)))
 			}
 			numRes += int64(len(objects))
 		}
@@ -499,7 +498,7 @@ func (e *Endpoint) discover(ctx context.Context) error {
 	if e.customAttrEnabled {
 		fields, err = client.GetCustomFields(ctx)
 		if err != nil {
-			log.Printf("W! Could not load custom field metadata ,Error:  %s", err.Error())
+			vsphereLog.Warnf("Could not load custom field metadata ,Error:  %s", err.Error())
 			fields = nil
 		}
 	}
@@ -517,18 +516,18 @@ func (e *Endpoint) discover(ctx context.Context) error {
 		e.customFields = fields
 	}
 	if config.Config.DebugMode {
-		log.Printf("D! discovered_objects  type is : %s   and number is : %d ", "instance-total", numRes)
+		vsphereLog.Debugf("discovered_objects  type is : %s   and number is : %d ", "instance-total", numRes)
 	}
 	return nil
 }
 
 func (e *Endpoint) simpleMetadataSelect(ctx context.Context, client *Client, res *resourceKind) {
 	if config.Config.DebugMode {
-		log.Printf("D! Using fast metric metadata selection for %s", res.name)
+		vsphereLog.Debugf("Using fast metric metadata selection for %s", res.name)
 	}
 	m, err := client.CounterInfoByName(ctx)
 	if err != nil {
-		log.Printf("E! Getting metric metadata. Discovery will be incomplete. Error: %s", err.Error())
+		vsphereLog.Errorf("Getting metric metadata. Discovery will be incomplete. Error: %s", err.Error())
 		return
 	}
 	res.metrics = make(performance.MetricList, 0, len(res.include))
@@ -544,7 +543,7 @@ func (e *Endpoint) simpleMetadataSelect(ctx context.Context, client *Client, res
 			}
 			res.metrics = append(res.metrics, cnt)
 		} else {
-			log.Printf("W! Metric name %s is unknown. Will not be collected", s)
+			vsphereLog.Warnf("Metric name %s is unknown. Will not be collected", s)
 		}
 	}
 }
@@ -571,9 +570,9 @@ func (e *Endpoint) complexMetadataSelect(ctx context.Context, res *resourceKind,
 	}
 
 	instInfoMux := sync.Mutex{}
-	te,err := NewThrottledExecutor(e.Parent.DiscoverConcurrency)
-	if err !=nil {
-		log.Println("E! NewThrottledExecutor",err.Error())
+	te, err := NewThrottledExecutor(e.Parent.DiscoverConcurrency)
+	if err != nil {
+		vsphereLog.Errorf("NewThrottledExecutor %v", err.Error())
 		return
 	}
 	for _, obj := range sampledObjects {
@@ -581,7 +580,7 @@ func (e *Endpoint) complexMetadataSelect(ctx context.Context, res *resourceKind,
 			te.Run(ctx, func() {
 				metrics, err := e.getMetadata(ctx, obj, res.sampling)
 				if err != nil {
-					log.Printf("E! Getting metric metadata. Discovery will be incomplete. Error: %s", err.Error())
+					vsphereLog.Errorf("Getting metric metadata. Discovery will be incomplete. Error: %s", err.Error())
 				}
 				mMap := make(map[string]gtypes.PerfMetricId)
 				for _, m := range metrics {
@@ -595,7 +594,7 @@ func (e *Endpoint) complexMetadataSelect(ctx context.Context, res *resourceKind,
 					}
 				}
 				if config.Config.DebugMode {
-					log.Printf("D!Found %d metrics for %s", len(mMap), obj.name)
+					vsphereLog.Debugf("Found %d metrics for %s", len(mMap), obj.name)
 				}
 				instInfoMux.Lock()
 				defer instInfoMux.Unlock()
@@ -619,7 +618,7 @@ func getDatacenters(ctx context.Context, e *Endpoint, resourceFilter *ResourceFi
 	defer cancel1()
 	err := resourceFilter.FindAll(ctx1, &resources)
 	if err != nil {
-		log.Println("E! getDatacenters resourceFilter.FindAll Error:", err.Error())
+		vsphereLog.Errorf("getDatacenters resourceFilter.FindAll Error: %v", err.Error())
 		return nil, err
 	}
 	m := make(objectMap, len(resources))
@@ -641,7 +640,7 @@ func getClusters(ctx context.Context, e *Endpoint, resourceFilter *ResourceFilte
 	defer cancel1()
 	err := resourceFilter.FindAll(ctx1, &resources)
 	if err != nil {
-		log.Println("E! getClusters resourceFilter.FindAll Error:", err.Error())
+		vsphereLog.Errorf("getClusters resourceFilter.FindAll Error: %v", err.Error())
 		return nil, err
 	}
 	cache := make(map[string]*gtypes.ManagedObjectReference)
@@ -664,7 +663,7 @@ func getClusters(ctx context.Context, e *Endpoint, resourceFilter *ResourceFilte
 				defer cancel3()
 				err = o.Properties(ctx3, *r.Parent, []string{"parent"}, &folder)
 				if err != nil {
-					log.Printf("W! Error while getting folder parent: %s", err.Error())
+					vsphereLog.Warnf("Error while getting folder parent: %s", err.Error())
 					p = nil
 				} else {
 					pp := folder.Parent.Reference()
@@ -692,7 +691,7 @@ func getResourcePools(ctx context.Context, e *Endpoint, resourceFilter *Resource
 	var resources []mo.ResourcePool
 	err := resourceFilter.FindAll(ctx, &resources)
 	if err != nil {
-		log.Println("E! getResourcePools resourceFilter.FindAll Error:", err.Error())
+		vsphereLog.Errorf("getResourcePools resourceFilter.FindAll Error: %v", err.Error())
 		return nil, err
 	}
 	m := make(objectMap)
@@ -722,7 +721,7 @@ func getHosts(ctx context.Context, e *Endpoint, resourceFilter *ResourceFilter)
 	var resources []mo.HostSystem
 	err := resourceFilter.FindAll(ctx, &resources)
 	if err != nil {
-		log.Println("E! getHosts resourceFilter.FindAll Error:", err.Error())
+		vsphereLog.Errorf("getHosts resourceFilter.FindAll Error: %v", err.Error())
 		return nil, err
 	}
 	m := make(objectMap)
@@ -743,7 +742,7 @@ func getVMs(ctx context.Context, e *Endpoint, resourceFilter *ResourceFilter) (o
 	defer cancel1()
 	err := resourceFilter.FindAll(ctx1, &resources)
 	if err != nil {
-		log.Println("E! getVMs resourceFilter.FindAll Error:", err.Error())
+		vsphereLog.Errorf("getVMs resourceFilter.FindAll Error: %v", err.Error())
 		return nil, err
 	}
 	m := make(objectMap)
@@ -823,7 +822,7 @@ func getVMs(ctx context.Context, e *Endpoint, resourceFilter *ResourceFilter) (o
 				}
 				key, ok := e.customFields[val.Key]
 				if !ok {
-					log.Printf("W! Metadata for custom field %d not found. Skipping", val.Key)
+					vsphereLog.Warnf("Metadata for custom field %d not found. Skipping", val.Key)
 					continue
 				}
 				if e.customAttrFilter.Match(key) {
@@ -851,7 +850,7 @@ func getDatastores(ctx context.Context, e *Endpoint, resourceFilter *ResourceFil
 	defer cancel1()
 	err := resourceFilter.FindAll(ctx1, &resources)
 	if err != nil {
-		log.Println("E! getDatastores resourceFilter.FindAll Error:", err.Error())
+		vsphereLog.Errorf("getDatastores resourceFilter.FindAll Error: %v", err.Error())
 		return nil, err
 	}
 	m := make(objectMap)
@@ -882,12 +881,12 @@ func (e *Endpoint) loadCustomAttributes(entity *mo.ManagedEntity) map[string]str
 	for _, v := range entity.CustomValue {
 		cv, ok := v.(*gtypes.CustomFieldStringValue)
 		if !ok {
-			log.Printf("W! Metadata for custom field %d not of string type. Skipping", cv.Key)
+			vsphereLog.Warnf("Metadata for custom field %d not of string type. Skipping", cv.Key)
 			continue
 		}
 		key, ok := e.customFields[cv.Key]
 		if !ok {
-			log.Printf("W! Metadata for custom field %d not found. Skipping", cv.Key)
+			vsphereLog.Warnf("Metadata for custom field %d not found. Skipping", cv.Key)
 			continue
 		}
 		if e.customAttrFilter.Match(key) {
@@ -953,9 +952,9 @@ func submitChunkJob(ctx context.Context, te *ThrottledExecutor, job queryJob, pq
 }
 
 func (e *Endpoint) chunkify(ctx context.Context, res *resourceKind, now time.Time, latest time.Time, job queryJob) {
-	te, err:= NewThrottledExecutor(e.Parent.CollectConcurrency)
-	if err!= nil {
-		log.Println("E! NewThrottledExecutor",err.Error())
+	te, err := NewThrottledExecutor(e.Parent.CollectConcurrency)
+	if err != nil {
+		vsphereLog.Errorf("NewThrottledExecutor %v", err.Error())
 		return
 	}
 	maxMetrics := e.Parent.MaxQueryMetrics
@@ -980,7 +979,7 @@ func (e *Endpoint) chunkify(ctx context.Context, res *resourceKind, now time.Tim
 			metricName := e.getMetricNameForID(metric.CounterId)
 			if metricName == "" {
 				if config.Config.DebugMode {
-					log.Printf("D! Unable to find metric name for id %d. Skipping!", metric.CounterId)
+					vsphereLog.Debugf("Unable to find metric name for id %d. Skipping!", metric.CounterId)
 				}
 				continue
 			}
@@ -1012,8 +1011,7 @@ func (e *Endpoint) chunkify(ctx context.Context, res *resourceKind, now time.Tim
 			// OR if we're past the absolute maximum limit
 			if (!res.realTime && len(bucket.MetricId) >= maxMetrics) || len(bucket.MetricId) > maxRealtimeMetrics {
 				if config.Config.DebugMode {
-					log.Printf("D! Submitting partial query: %d metrics (%d remaining) of type %s for %s. Total objects %d",
-						len(bucket.MetricId), len(res.metrics)-metricIdx, res.name, e.URL.Host, len(res.objects))
+					vsphereLog.Debugf("Submitting partial query: %d metrics (%d remaining) of type %s for %s. Total objects %d", len(bucket.MetricId), len(res.metrics)-metricIdx, res.name, e.URL.Host, len(res.objects))
 				}
 
 				// Don't send work items if the context has been cancelled.
@@ -1032,7 +1030,7 @@ func (e *Endpoint) chunkify(ctx context.Context, res *resourceKind, now time.Tim
 			numQs += len(bucket.MetricId)
 			if (!res.realTime && numQs > e.Parent.MaxQueryObjects) || numQs > maxRealtimeMetrics {
 				if config.Config.DebugMode {
-					log.Printf("D! Submitting final bucket job for %s: %d metrics", res.name, numQs)
+					vsphereLog.Debugf("Submitting final bucket job for %s: %d metrics", res.name, numQs)
 				}
 				submitChunkJob(ctx, te, job, pqs)
 				pqs = make(queryChunk, 0, e.Parent.MaxQueryObjects)
@@ -1043,7 +1041,7 @@ func (e *Endpoint) chunkify(ctx context.Context, res *resourceKind, now time.Tim
 	// Submit any jobs left in the queue
 	if len(pqs) > 0 {
 		if config.Config.DebugMode {
-			log.Printf("D! Submitting job for %s: %d objects, %d metrics", res.name, len(pqs), numQs)
+			vsphereLog.Debugf("Submitting job for %s: %d objects, %d metrics", res.name, len(pqs), numQs)
 		}
 		submitChunkJob(ctx, te, job, pqs)
 	}
@@ -1056,15 +1054,15 @@ func (e *Endpoint) collectResource(ctx context.Context, resourceType string, sli
 	res := e.resourceKinds[resourceType]
 	client, err := e.clientFactory.GetClient(ctx)
 	if config.Config.DebugMode {
-		log.Printf("D! collectResource  %s ", resourceType)
+		vsphereLog.Debugf("collectResource  %s ", resourceType)
 	}
 	if err != nil {
-		log.Println("E! collectResource  Error: ", err.Error())
+		vsphereLog.Errorf("collectResource  Error:  %v", err.Error())
 		return err
 	}
 	now, err := client.GetServerTime(ctx)
 	if err != nil {
-		log.Println("E! GetServerTime  Error : ", err.Error())
+		vsphereLog.Errorf("GetServerTime  Error :  %v", err.Error())
 		return err
 	}
 
@@ -1081,11 +1079,11 @@ func (e *Endpoint) collectResource(ctx context.Context, resourceType string, sli
 			estInterval = s
 		}
 		if config.Config.DebugMode {
-			log.Printf("D! resourceType %s Raw interval %s, padded: %s, estimated: %s", resourceType, rawInterval, paddedInterval, estInterval)
+			vsphereLog.Debugf("resourceType %s Raw interval %s, padded: %s, estimated: %s", resourceType, rawInterval, paddedInterval, estInterval)
 		}
 	}
 	if config.Config.DebugMode {
-		log.Printf("D! resourceType %s Interval estimated to %s", resourceType, estInterval)
+		vsphereLog.Debugf("resourceType %s Interval estimated to %s", resourceType, estInterval)
 	}
 	res.lastColl = localNow
 
@@ -1093,13 +1091,12 @@ func (e *Endpoint) collectResource(ctx context.Context, resourceType string, sli
 	if !latest.IsZero() {
 		elapsed := now.Sub(latest).Seconds() + 5.0 // Allow 5 second jitter.
 		if config.Config.DebugMode {
-			log.Printf("D! Latest: %s, elapsed: %f, resource: %s", latest, elapsed, resourceType)
+			vsphereLog.Debugf("Latest: %s, elapsed: %f, resource: %s", latest, elapsed, resourceType)
 		}
 		if !res.realTime && elapsed < float64(res.sampling) {
 			// No new data would be available. We're outta here!
 			if config.Config.DebugMode {
-				log.Printf("D! Sampling period for %s of %d has not elapsed on %s",
-					resourceType, res.sampling, e.URL.Host)
+				vsphereLog.Debugf("Sampling period for %s of %d has not elapsed on %s", resourceType, res.sampling, e.URL.Host)
 			}
 			return nil
 		}
@@ -1107,8 +1104,7 @@ func (e *Endpoint) collectResource(ctx context.Context, resourceType string, sli
 		latest = now.Add(time.Duration(-res.sampling) * time.Second)
 	}
 	if config.Config.DebugMode {
-		log.Printf("D! Collecting metrics for %d objects of type %s for %s",
-			len(res.objects), resourceType, e.URL.Host)
+		vsphereLog.Debugf("Collecting metrics for %d objects of type %s for %s", len(res.objects), resourceType, e.URL.Host)
 	}
 
 	count := int64(0)
@@ -1122,12 +1118,12 @@ func (e *Endpoint) collectResource(ctx context.Context, resourceType string, sli
 			n, localLatest, err := e.collectChunk(ctx, chunk, res, slist, estInterval)
 			if err != nil {
 				if config.Config.DebugMode {
-					log.Printf("D! CollectChunk for %s returned %d metrics   err: %s ", resourceType, n, err)
+					vsphereLog.Debugf("CollectChunk for %s returned %d metrics   err: %s ", resourceType, n, err)
 				}
 				return
 			}
 			if config.Config.DebugMode {
-				log.Printf("D! CollectChunk for %s returned %d metrics", resourceType, n)
+				vsphereLog.Debugf("CollectChunk for %s returned %d metrics", resourceType, n)
 			}
 			atomic.AddInt64(&count, int64(n))
 			tsMux.Lock()
@@ -1137,13 +1133,13 @@ func (e *Endpoint) collectResource(ctx context.Context, resourceType string, sli
 			}
 		})
 	if config.Config.DebugMode {
-		log.Printf("D! Latest sample for %s set to %s", resourceType, latestSample)
+		vsphereLog.Debugf("Latest sample for %s set to %s", resourceType, latestSample)
 	}
 	if !latestSample.IsZero() {
 		res.latestSample = latestSample
 	}
 	if config.Config.DebugMode {
-		log.Printf("discovered_objects  type is : %s   and number is : %d ", resourceType, count)
+		vsphereLog.Infof("discovered_objects  type is : %s   and number is : %d ", resourceType, count)
 	}
 
 	return nil
@@ -1159,7 +1155,7 @@ func (e *Endpoint) alignSamples(info []gtypes.PerfSampleInfo, values []int64, in
 		// data coming back with missing values. Take care of that gracefully!
 		if idx >= len(values) {
 			if config.Config.DebugMode {
-				log.Printf("D! len(SampleInfo)>len(Value) %d > %d during alignment", len(info), len(values))
+				vsphereLog.Debugf("len(SampleInfo)>len(Value) %d > %d during alignment", len(info), len(values))
 			}
 			break
 		}
@@ -1191,14 +1187,14 @@ func (e *Endpoint) alignSamples(info []gtypes.PerfSampleInfo, values []int64, in
 
 func (e *Endpoint) collectChunk(ctx context.Context, pqs queryChunk, res *resourceKind, slist *types.SampleList, interval time.Duration) (int, time.Time, error) {
 	if config.Config.DebugMode {
-		log.Printf("D! Query for %s has %d QuerySpecs", res.name, len(pqs))
+		vsphereLog.Debugf("Query for %s has %d QuerySpecs", res.name, len(pqs))
 	}
 	latestSample := time.Time{}
 	count := 0
 	resourceType := res.name
 	prefix := resourceType
 	if config.Config.DebugMode {
-		log.Printf("D! collectChunk for %s", resourceType)
+		vsphereLog.Debugf("collectChunk for %s", resourceType)
 	}
 	client, err := e.clientFactory.GetClient(ctx)
 	if err != nil {
@@ -1212,11 +1208,11 @@ func (e *Endpoint) collectChunk(ctx context.Context, pqs queryChunk, res *resour
 
 	ems, err := client.QueryMetrics(ctx, pqs)
 	if err != nil {
-		log.Printf("W! client.QueryMetrics for %s  error is %s", resourceType, err.Error())
+		vsphereLog.Warnf("client.QueryMetrics for %s  error is %s", resourceType, err.Error())
 		return count, latestSample, err
 	}
 	if config.Config.DebugMode {
-		log.Printf("D! Query for %s returned metrics for %d objects\r\n", resourceType, len(ems))
+		vsphereLog.Debugf("Query for %s returned metrics for %d objects\r", resourceType, len(ems))
 	}
 
 	// Iterate through results
@@ -1224,12 +1220,12 @@ func (e *Endpoint) collectChunk(ctx context.Context, pqs queryChunk, res *resour
 		moid := em.Entity.Reference().Value
 		instInfo, found := res.objects[moid]
 		if !found {
-			log.Printf("E! MOID %s not found in cache. Skipping! (This should not happen!)\r\n", moid)
+			vsphereLog.Errorf("MOID %s not found in cache. Skipping! (This should not happen!)\r", moid)
 			continue
 		}
 		buckets := make(map[string]metricEntry)
 		if config.Config.DebugMode {
-			log.Printf("D! Query for %s  em.Value len is %d \r\n", resourceType, len(em.Value))
+			vsphereLog.Debugf("Query for %s  em.Value len is %d \r", resourceType, len(em.Value))
 		}
 		for _, v := range em.Value {
 			name := v.Name
@@ -1242,7 +1238,7 @@ func (e *Endpoint) collectChunk(ctx context.Context, pqs queryChunk, res *resour
 			// Populate tags
 			objectRef, ok := res.objects[moid]
 			if !ok {
-				log.Printf("E! MOID %s not found in cache. Skipping", moid)
+				vsphereLog.Errorf("MOID %s not found in cache. Skipping", moid)
 				continue
 			}
 			e.populateTags(objectRef, resourceType, res, t, &v)
@@ -1250,14 +1246,14 @@ func (e *Endpoint) collectChunk(ctx context.Context, pqs queryChunk, res *resour
 			nValues := 0
 			alignedInfo, alignedValues := e.alignSamples(em.SampleInfo, v.Value, interval)
 			if config.Config.DebugMode {
-				log.Printf("D! Query for %s  alignedInfo len is %d \r\n", resourceType, len(alignedInfo))
+				vsphereLog.Debugf("Query for %s  alignedInfo len is %d \r", resourceType, len(alignedInfo))
 			}
 			for idx, sample := range alignedInfo {
 				// According to the docs, SampleInfo and Value should have the same length, but we've seen corrupted
 				// data coming back with missing values. Take care of that gracefully!
 				if idx >= len(alignedValues) {
 					if config.Config.DebugMode {
-						log.Printf("D! Len(SampleInfo)>len(Value) %d > %d\r\n", len(alignedInfo), len(alignedValues))
+						vsphereLog.Debugf("Len(SampleInfo)>len(Value) %d > %d\r", len(alignedInfo), len(alignedValues))
 					}
 					break
 				}
@@ -1270,7 +1266,7 @@ func (e *Endpoint) collectChunk(ctx context.Context, pqs queryChunk, res *resour
 				// Organize the metrics into a bucket per measurement.
 				mn, fn := e.makeMetricIdentifier(prefix, name)
 				if config.Config.DebugMode {
-					log.Printf("D! makeMetricIdentifier: %s   %s\r\n", prefix, name)
+					vsphereLog.Debugf("makeMetricIdentifier: %s   %s\r", prefix, name)
 				}
 				bKey := mn + " " + v.Instance + " " + strconv.FormatInt(ts.UnixNano(), 10)
 				bucket, found := buckets[bKey]
@@ -1282,7 +1278,7 @@ func (e *Endpoint) collectChunk(ctx context.Context, pqs queryChunk, res *resour
 				// Percentage values must be scaled down by 100.
 				info, ok := metricInfo[name]
 				if !ok {
-					log.Printf("E! Could not determine unit for %s. Skipping\r\n", name)
+					vsphereLog.Errorf("Could not determine unit for %s. Skipping\r", name)
 				}
 				v := alignedValues[idx]
 				if info.UnitInfo.GetElementDescription().Key == "percent" {
@@ -1301,7 +1297,7 @@ func (e *Endpoint) collectChunk(ctx context.Context, pqs queryChunk, res *resour
 			}
 			if nValues == 0 {
 				if config.Config.DebugMode {
-					log.Printf("D! Missing value for: %s, %s", name, objectRef.name)
+					vsphereLog.Debugf("Missing value for: %s, %s", name, objectRef.name)
 				}
 				continue
 			}
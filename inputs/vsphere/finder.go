@@ -3,7 +3,6 @@ package vsphere
 import (
 	"context"
 	"fmt"
-	"log"
 	"reflect"
 	"strings"
 
@@ -75,7 +74,7 @@ func (f *Finder) findResources(ctx context.Context, resType, path string, objs m
 	if err != nil {
 		return err
 	}
-	log.Printf("I! Find(%s, %s) returned %d objects\r\n", resType, path, len(objs))
+	vsphereLog.Infof("Find(%s, %s) returned %d objects\r", resType, path, len(objs))
 	return nil
 }
 
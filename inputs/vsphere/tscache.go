@@ -2,7 +2,6 @@ package vsphere
 
 import (
 	"fmt"
-	"log"
 	"sync"
 	"time"
 
@@ -36,7 +35,7 @@ func (t *TSCache) Purge() {
 		}
 	}
 	if config.Config.DebugMode {
-		log.Println(fmt.Sprintf("D! purged timestamp cache. %d deleted with %d remaining", n, len(t.table)))
+		vsphereLog.Infof("%v", fmt.Sprintf("D! purged timestamp cache. %d deleted with %d remaining", n, len(t.table)))
 	}
 }
 
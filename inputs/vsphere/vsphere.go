@@ -2,17 +2,19 @@ package vsphere
 
 import (
 	"context"
-	"log"
 	"time"
 
 	"github.com/vmware/govmomi/vim25/soap"
 
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/pkg/tls"
 	"flashcat.cloud/categraf/types"
 )
 
+var vsphereLog = logger.New("input.vsphere")
+
 const inputName = "vsphere"
 
 // VSphere is the top level type for the vSphere input plugin. It contains all the configuration
@@ -173,12 +175,12 @@ func (ins *Instance) Init() error {
 	// Create endpoints, one for each vCenter we're monitoring
 	u, err := soap.ParseURL(ins.Vcenter)
 	if err != nil {
-		log.Println("E! soap.ParseURL", err)
+		vsphereLog.Errorf("soap.ParseURL %v", err)
 		return err
 	}
 	ep, err := NewEndpoint(ctx, ins, u)
 	if err != nil {
-		log.Println("E! NewEndpoint", err)
+		vsphereLog.Errorf("NewEndpoint %v", err)
 		return err
 	}
 	ins.endpoints = ep
@@ -186,7 +188,7 @@ func (ins *Instance) Init() error {
 }
 
 func (v *Instance) Drop() {
-	log.Printf("I! Stopping plugin")
+	vsphereLog.Infof("Stopping plugin")
 	v.cancel()
 
 	// Wait for all endpoints to finish. No need to wait for
@@ -195,7 +197,7 @@ func (v *Instance) Drop() {
 	// wait for any discovery to complete by trying to grab the
 	// "busy" mutex.
 	if config.Config.DebugMode {
-		log.Printf("D! Waiting for endpoint %q to finish", v.endpoints.URL.Host)
+		vsphereLog.Debugf("Waiting for endpoint %q to finish", v.endpoints.URL.Host)
 	}
 	func() {
 		v.endpoints.busy.Lock() // Wait until discovery is finished
@@ -214,7 +216,7 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 	}
 	if err != nil {
 		// acc.AddError(err)
-		log.Printf("E! fail to gather\n", err)
+		vsphereLog.Errorf("fail to gather: %v", err)
 	}
 
 }
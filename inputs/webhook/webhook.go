@@ -0,0 +1,413 @@
+package webhook
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tidwall/gjson"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/pkg/tls"
+	"flashcat.cloud/categraf/types"
+)
+
+var webhookLog = logger.New("input.webhook")
+
+const (
+	inputName = "webhook"
+
+	defaultMaxBodySize = 4 * 1024 * 1024
+	defaultMaxSeries   = 10000
+
+	headerPrefix = "header:"
+	rootPrefix   = "$."
+)
+
+type Webhook struct {
+	config.PluginConfig
+	Instances []*Instance `toml:"instances"`
+}
+
+type Instance struct {
+	config.InstanceConfig
+
+	ServiceAddress string `toml:"service_address"`
+	// the requests must carry Authorization: Bearer <token>, or ?token=<token>, not checked if empty
+	Token       string `toml:"token"`
+	MaxBodySize int    `toml:"max_body_size"`
+	// the label sets counted, the events of new label sets are dropped once it's full
+	MaxSeries int `toml:"max_series"`
+	tls.ServerConfig
+
+	Rules []*Rule `toml:"rules"`
+
+	server   *http.Server
+	listener net.Listener
+	rules    map[string][]*Rule
+	hasLogs  bool
+	wg       sync.WaitGroup
+
+	sync.Mutex
+	events   map[string]*series
+	requests map[requestKey]float64
+	// the events not counted for max_series, and the log messages not forwarded
+	droppedEvents float64
+	droppedLogs   float64
+}
+
+// Rule maps the events posted to path into the counter webhook_events_total, and log messages optionally.
+// the fields are gjson paths of the event, $.path of the whole body, or header:Name of the request headers
+type Rule struct {
+	Name string `toml:"name"`
+	Path string `toml:"path"`
+	// the path of the array of events in the body, e.g. alerts of alertmanager, the body is one event if empty
+	Items string `toml:"items"`
+	// the events are mapped only if all the fields equal the values
+	Match map[string]string `toml:"match"`
+	// label name to field
+	Labels map[string]string `toml:"labels"`
+
+	// forward events to the logs agent, logs.enable must be true
+	ForwardLogs bool   `toml:"forward_logs"`
+	LogsService string `toml:"logs_service"`
+	LogsSource  string `toml:"logs_source"`
+
+	labelNames []string
+	logs       *config.LogChannel
+}
+
+type series struct {
+	labels map[string]string
+	value  float64
+}
+
+type requestKey struct {
+	path   string
+	status string
+}
+
+// eventLog is the log message forwarded for an event
+type eventLog struct {
+	Time   time.Time         `json:"time"`
+	Rule   string            `json:"rule"`
+	Labels map[string]string `json:"labels"`
+	Event  json.RawMessage   `json:"event"`
+}
+
+var _ inputs.SampleGatherer = new(Instance)
+var _ inputs.Input = new(Webhook)
+var _ inputs.InstancesGetter = new(Webhook)
+
+func init() {
+	inputs.Add(inputName, func() inputs.Input {
+		return &Webhook{}
+	})
+}
+
+func (w *Webhook) Clone() inputs.Input {
+	return &Webhook{}
+}
+
+func (w *Webhook) Name() string {
+	return inputName
+}
+
+func (w *Webhook) GetInstances() []inputs.Instance {
+	ret := make([]inputs.Instance, len(w.Instances))
+	for i := 0; i < len(w.Instances); i++ {
+		ret[i] = w.Instances[i]
+	}
+	return ret
+}
+
+func (w *Webhook) Drop() {
+	for i := 0; i < len(w.Instances); i++ {
+		w.Instances[i].stop()
+	}
+}
+
+func (ins *Instance) Init() error {
+	if ins.ServiceAddress == "" {
+		return types.ErrInstancesEmpty
+	}
+	if len(ins.Rules) == 0 {
+		return fmt.Errorf("rules is required")
+	}
+	if ins.MaxBodySize <= 0 {
+		ins.MaxBodySize = defaultMaxBodySize
+	}
+	if ins.MaxSeries <= 0 {
+		ins.MaxSeries = defaultMaxSeries
+	}
+
+	ins.rules = make(map[string][]*Rule)
+	for i, rule := range ins.Rules {
+		if err := rule.init(); err != nil {
+			return fmt.Errorf("rule %d: %v", i, err)
+		}
+		ins.rules[rule.Path] = append(ins.rules[rule.Path], rule)
+		ins.hasLogs = ins.hasLogs || rule.ForwardLogs
+	}
+
+	tlsCfg, err := ins.ServerConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+
+	ins.listener, err = net.Listen("tcp", ins.ServiceAddress)
+	if err != nil {
+		return err
+	}
+
+	ins.events = make(map[string]*series)
+	ins.requests = make(map[requestKey]float64)
+	ins.server = &http.Server{
+		Handler:      ins,
+		TLSConfig:    tlsCfg,
+		ReadTimeout:  30 * time.Second,
+		WriteTimeout: 30 * time.Second,
+	}
+
+	ins.wg.Add(1)
+	go func() {
+		defer ins.wg.Done()
+		var err error
+		if tlsCfg != nil {
+			err = ins.server.ServeTLS(ins.listener, "", "")
+		} else {
+			err = ins.server.Serve(ins.listener)
+		}
+		if err != nil && err != http.ErrServerClosed {
+			webhookLog.Errorf("webhook server stopped: %v", err)
+		}
+	}()
+
+	webhookLog.Infof("webhook listening on %v", ins.listener.Addr())
+	return nil
+}
+
+func (r *Rule) init() error {
+	if r.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if r.Path == "" {
+		r.Path = "/" + r.Name
+	}
+	if !strings.HasPrefix(r.Path, "/") {
+		r.Path = "/" + r.Path
+	}
+
+	r.labelNames = make([]string, 0, len(r.Labels))
+	for name := range r.Labels {
+		r.labelNames = append(r.labelNames, name)
+	}
+	sort.Strings(r.labelNames)
+
+	if r.ForwardLogs {
+		if r.LogsService == "" {
+			r.LogsService = r.Name
+		}
+		if r.LogsSource == "" {
+			r.LogsSource = inputName
+		}
+		r.logs = config.NewLogChannel(inputName+"/"+r.Name, r.LogsService, r.LogsSource, "")
+	}
+	return nil
+}
+
+func (ins *Instance) stop() {
+	if ins.server == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ins.server.Shutdown(ctx)
+	ins.wg.Wait()
+}
+
+func (ins *Instance) authorized(r *http.Request) bool {
+	if ins.Token == "" {
+		return true
+	}
+	token := r.URL.Query().Get("token")
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		token = strings.TrimPrefix(auth, "Bearer ")
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(ins.Token)) == 1
+}
+
+func (ins *Instance) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rules, has := ins.rules[r.URL.Path]
+	if !has {
+		// the paths of requests are not labeled as is, they are not bounded
+		ins.countRequest("", "not_found")
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.Method != http.MethodPost && r.Method != http.MethodPut {
+		ins.countRequest(r.URL.Path, "method_not_allowed")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !ins.authorized(r) {
+		ins.countRequest(r.URL.Path, "unauthorized")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, int64(ins.MaxBodySize)+1))
+	if err != nil {
+		ins.countRequest(r.URL.Path, "invalid")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(body) > ins.MaxBodySize {
+		ins.countRequest(r.URL.Path, "too_large")
+		http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if !gjson.ValidBytes(body) {
+		ins.countRequest(r.URL.Path, "invalid")
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+
+	root := gjson.ParseBytes(body)
+	now := time.Now()
+	for _, rule := range rules {
+		ins.apply(rule, now, r.Header, root)
+	}
+
+	ins.countRequest(r.URL.Path, "ok")
+	w.Write([]byte("ok"))
+}
+
+// apply maps the events of the body by the rule
+func (ins *Instance) apply(rule *Rule, now time.Time, header http.Header, root gjson.Result) {
+	items := []gjson.Result{root}
+	if rule.Items != "" {
+		items = root.Get(rule.Items).Array()
+	}
+
+	for _, item := range items {
+		if !rule.match(header, root, item) {
+			continue
+		}
+
+		labels := make(map[string]string, len(rule.labelNames)+1)
+		for _, name := range rule.labelNames {
+			labels[name] = lookup(rule.Labels[name], header, root, item)
+		}
+		ins.countEvent(rule, labels)
+
+		if rule.logs == nil {
+			continue
+		}
+
+		bs, err := json.Marshal(eventLog{
+			Time:   now,
+			Rule:   rule.Name,
+			Labels: labels,
+			Event:  json.RawMessage(item.Raw),
+		})
+		if err != nil {
+			webhookLog.Errorf("failed to marshal webhook event of rule: %v error: %v", rule.Name, err)
+			continue
+		}
+
+		if !rule.logs.Send(bs) {
+			ins.Lock()
+			ins.droppedLogs++
+			ins.Unlock()
+		}
+	}
+}
+
+func (r *Rule) match(header http.Header, root, item gjson.Result) bool {
+	for field, value := range r.Match {
+		if lookup(field, header, root, item) != value {
+			return false
+		}
+	}
+	return true
+}
+
+// lookup returns the value of the field, empty if not found
+func lookup(field string, header http.Header, root, item gjson.Result) string {
+	switch {
+	case strings.HasPrefix(field, headerPrefix):
+		return header.Get(strings.TrimPrefix(field, headerPrefix))
+	case strings.HasPrefix(field, rootPrefix):
+		return root.Get(strings.TrimPrefix(field, rootPrefix)).String()
+	default:
+		return item.Get(field).String()
+	}
+}
+
+func (ins *Instance) countEvent(rule *Rule, labels map[string]string) {
+	var key strings.Builder
+	key.WriteString(rule.Name)
+	for _, name := range rule.labelNames {
+		key.WriteByte(0xff)
+		key.WriteString(labels[name])
+	}
+
+	ins.Lock()
+	defer ins.Unlock()
+
+	s, has := ins.events[key.String()]
+	if !has {
+		if len(ins.events) >= ins.MaxSeries {
+			ins.droppedEvents++
+			return
+		}
+		s = &series{labels: make(map[string]string, len(labels)+1)}
+		for k, v := range labels {
+			s.labels[k] = v
+		}
+		s.labels["rule"] = rule.Name
+		ins.events[key.String()] = s
+	}
+	s.value++
+}
+
+func (ins *Instance) countRequest(path, status string) {
+	ins.Lock()
+	ins.requests[requestKey{path: path, status: status}]++
+	ins.Unlock()
+}
+
+func (ins *Instance) Gather(slist *types.SampleList) {
+	ins.Lock()
+	defer ins.Unlock()
+
+	for _, s := range ins.events {
+		slist.PushSample(inputName, "events_total", s.value, s.labels)
+	}
+
+	for key, value := range ins.requests {
+		slist.PushSample(inputName, "requests_total", value, map[string]string{
+			"path":   key.path,
+			"status": key.status,
+		})
+	}
+
+	slist.PushSample(inputName, "events_dropped_total", ins.droppedEvents)
+	if ins.hasLogs {
+		slist.PushSample(inputName, "logs_dropped_total", ins.droppedLogs)
+	}
+}
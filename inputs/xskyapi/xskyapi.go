@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net"
 	"net/http"
 	"net/url"
@@ -16,10 +15,13 @@ import (
 
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/pkg/tls"
 	"flashcat.cloud/categraf/types"
 )
 
+var xskyapiLog = logger.New("input.xskyapi")
+
 const inputName = "xskyapi"
 
 var (
@@ -153,7 +155,7 @@ func (ins *Instance) Gather(slist *types.SampleList) {
 
 func (ins *Instance) gather(slist *types.SampleList, server string, token string) {
 	if config.Config.DebugMode {
-		log.Println("D! xskyapi... server:", server)
+		xskyapiLog.Debugf("xskyapi... server: %v", server)
 	}
 
 	// acquire quota data of 3 mainstream distributed storage service provided by Xsky
@@ -166,14 +168,14 @@ func (ins *Instance) gather(slist *types.SampleList, server string, token string
 
 		resp, _, err := ins.sendRequest(urlUsers, token)
 		if err != nil {
-			log.Println("E! failed to send request to xskyapi url:", urlUsers, "error:", err)
+			xskyapiLog.Errorf("failed to send request to xskyapi url: %v error: %v", urlUsers, err)
 		}
 
 		osUsers := OsUsers{}
 
 		er := json.Unmarshal(resp, &osUsers)
 		if er != nil {
-			log.Printf("Parsing JSON string exception：%s\n", err)
+			xskyapiLog.Infof("Parsing JSON string exception：%s", err)
 		}
 
 		labels := map[string]string{"server": server}
@@ -193,13 +195,13 @@ func (ins *Instance) gather(slist *types.SampleList, server string, token string
 
 		resp, _, err = ins.sendRequest(urlBuckets, token)
 		if err != nil {
-			log.Println("E! failed to send request to xskyapi url:", urlBuckets, "error:", err)
+			xskyapiLog.Errorf("failed to send request to xskyapi url: %v error: %v", urlBuckets, err)
 		}
 		osBuckets := OsBuckets{}
 
 		err = json.Unmarshal(resp, &osBuckets)
 		if err != nil {
-			log.Printf("Parsing JSON string exception：%s\n", err)
+			xskyapiLog.Infof("Parsing JSON string exception：%s", err)
 		}
 
 		labels = map[string]string{"server": server}
@@ -223,14 +225,14 @@ func (ins *Instance) gather(slist *types.SampleList, server string, token string
 
 		resp, _, err := ins.sendRequest(urlDfs, token)
 		if err != nil {
-			log.Println("E! failed to send request to xskyapi url:", urlDfs, "error:", err)
+			xskyapiLog.Errorf("failed to send request to xskyapi url: %v error: %v", urlDfs, err)
 		}
 
 		dfsQuotas := DfsQuotas{}
 
 		er := json.Unmarshal(resp, &dfsQuotas)
 		if er != nil {
-			log.Printf("Parsing JSON string exception：%s\n", err)
+			xskyapiLog.Infof("Parsing JSON string exception：%s", err)
 		}
 
 		labels := map[string]string{"server": server}
@@ -249,14 +251,14 @@ func (ins *Instance) gather(slist *types.SampleList, server string, token string
 
 		resp, _, err = ins.sendRequest(urlBV, token)
 		if err != nil {
-			log.Println("E! failed to send request to xskyapi url:", urlDfs, "error:", err)
+			xskyapiLog.Errorf("failed to send request to xskyapi url: %v error: %v", urlDfs, err)
 		}
 
 		blockVolumes := BlockVolumes{}
 
 		err = json.Unmarshal(resp, &blockVolumes)
 		if err != nil {
-			log.Printf("Parsing JSON string exception：%s\n", err)
+			xskyapiLog.Infof("Parsing JSON string exception：%s", err)
 		}
 
 		labels = map[string]string{"server": server}
@@ -278,14 +280,14 @@ func (ins *Instance) gather(slist *types.SampleList, server string, token string
 
 		resp, _, err := ins.sendRequest(urlDfs, token)
 		if err != nil {
-			log.Println("E! failed to send request to xskyapi url:", urlDfs, "error:", err)
+			xskyapiLog.Errorf("failed to send request to xskyapi url: %v error: %v", urlDfs, err)
 		}
 
 		fsFolders := FsFolders{}
 
 		er := json.Unmarshal(resp, &fsFolders)
 		if er != nil {
-			log.Printf("Parsing JSON string exception：%s\n", err)
+			xskyapiLog.Infof("Parsing JSON string exception：%s", err)
 		}
 
 		labels := map[string]string{"server": server}
@@ -304,14 +306,14 @@ func (ins *Instance) gather(slist *types.SampleList, server string, token string
 
 		resp, _, err = ins.sendRequest(urlBV, token)
 		if err != nil {
-			log.Println("E! failed to send request to xskyapi url:", urlDfs, "error:", err)
+			xskyapiLog.Errorf("failed to send request to xskyapi url: %v error: %v", urlDfs, err)
 		}
 
 		blockVolumes := BlockVolumes{}
 
 		err = json.Unmarshal(resp, &blockVolumes)
 		if err != nil {
-			log.Printf("Parsing JSON string exception：%s\n", err)
+			xskyapiLog.Infof("Parsing JSON string exception：%s", err)
 		}
 
 		labels = map[string]string{"server": server}
@@ -326,14 +328,14 @@ func (ins *Instance) gather(slist *types.SampleList, server string, token string
 			slist.PushSamples(inputName, fields, labels)
 		}
 	default:
-		log.Printf("E! dss_type %s not suppported, expected oss, gfs or eus", ins.DssType)
+		xskyapiLog.Errorf("dss_type %s not suppported, expected oss, gfs or eus", ins.DssType)
 	}
 }
 
 func (ins *Instance) sendRequest(serverURL string, token string) ([]byte, float64, error) {
 	// Prepare URL
 	requestURL, _ := url.Parse(serverURL)
-	log.Println("D! now parseurl:", requestURL)
+	xskyapiLog.Debugf("now parseurl: %v", requestURL)
 
 	// Prepare request query and body
 	data := url.Values{}
@@ -14,10 +14,13 @@ import (
 
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/pkg/tls"
 	"flashcat.cloud/categraf/types"
 )
 
+var zookeeperLog = logger.New("input.zookeeper")
+
 const (
 	inputName                 = "zookeeper"
 	commandNotAllowedTmpl     = "E!: %q command isn't allowed at %q, see '4lw.commands.whitelist' ZK config parameter\n"
@@ -124,7 +127,7 @@ func (ins *Instance) gatherOneHost(wg *sync.WaitGroup, slist *types.SampleList,
 	mntrConn, err := ins.ZkConnect(zkHost)
 	if err != nil {
 		slist.PushFront(types.NewSample("", "zk_up", 0, tags))
-		log.Println("E! failed to connect zookeeper:", zkHost, "error:", err)
+		zookeeperLog.Errorf("failed to connect zookeeper: %v error: %v", zkHost, err)
 		return
 	}
 
@@ -135,7 +138,7 @@ func (ins *Instance) gatherOneHost(wg *sync.WaitGroup, slist *types.SampleList,
 	ruokConn, err := ins.ZkConnect(zkHost)
 	if err != nil {
 		slist.PushFront(types.NewSample("", "zk_ruok", 0, tags))
-		log.Println("E! failed to connect zookeeper:", zkHost, "error:", err)
+		zookeeperLog.Errorf("failed to connect zookeeper: %v error: %v", zkHost, err)
 		return
 	}
 
@@ -197,7 +200,7 @@ func (ins *Instance) gatherMntrResult(conn net.Conn, slist *types.SampleList, gl
 			var k string
 
 			if !isDigit(value) {
-				log.Printf("warning: skipping metric %q which holds not-digit value: %q", key, value)
+				zookeeperLog.Infof("warning: skipping metric %q which holds not-digit value: %q", key, value)
 				continue
 			}
 			k = metricNameReplacer.Replace(key)
@@ -226,12 +229,12 @@ func (ins *Instance) gatherRuokResult(conn net.Conn, slist *types.SampleList, gl
 func sendZookeeperCmd(conn net.Conn, cmd string) string {
 	_, err := conn.Write([]byte(cmd))
 	if err != nil {
-		log.Println("E! failed to exec Zookeeper command:", cmd)
+		zookeeperLog.Errorf("failed to exec Zookeeper command: %v", cmd)
 	}
 
 	res, err := ioutil.ReadAll(conn)
 	if err != nil {
-		log.Printf("E! failed read Zookeeper command: '%s' response from '%s': %s", cmd, conn.RemoteAddr().String(), err)
+		zookeeperLog.Errorf("failed read Zookeeper command: '%s' response from '%s': %s", cmd, conn.RemoteAddr().String(), err)
 	}
 	return string(res)
 }
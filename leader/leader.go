@@ -0,0 +1,223 @@
+//go:build !minimal
+
+// Package leader elects one of the categraf replicas by a kubernetes lease, so the cluster scoped inputs,
+// e.g. kube_state_metrics and kube_events of a daemonset, run on exactly one replica and the series are not duplicated.
+package leader
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/pkg/filter"
+	"flashcat.cloud/categraf/pkg/k8smeta"
+	"flashcat.cloud/categraf/pkg/logger"
+)
+
+const (
+	defaultLeaseName     = "categraf-leader"
+	defaultLeaseDuration = 15 * time.Second
+	defaultRenewDeadline = 10 * time.Second
+	defaultRetryPeriod   = 2 * time.Second
+)
+
+var leaderLog = logger.New("leader")
+
+var isLeader = prometheus.NewGauge(prometheus.GaugeOpts{
+	Name: "leader_election_is_leader",
+	Help: "Whether the replica is the leader running the cluster scoped inputs.",
+})
+
+func init() {
+	prometheus.MustRegister(isLeader)
+}
+
+type elector struct {
+	inputs filter.Filter
+	// 1 if leading
+	leading int32
+
+	// the watchers are notified of the changes of leadership in order under mu
+	mu       sync.Mutex
+	watchers map[*watcher]struct{}
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+var e *elector
+
+// Init starts the election of config.Config.LeaderElection, all inputs run if it's disabled
+func Init() error {
+	conf := config.Config.LeaderElection
+	if conf == nil || !conf.Enable {
+		return nil
+	}
+
+	inputs, err := filter.Compile(conf.Inputs)
+	if err != nil {
+		return fmt.Errorf("leader_election: failed to compile inputs: %v", err)
+	}
+	if inputs == nil {
+		return fmt.Errorf("leader_election: inputs is required")
+	}
+
+	if conf.LeaseName == "" {
+		conf.LeaseName = defaultLeaseName
+	}
+	if conf.LeaseNamespace == "" {
+		conf.LeaseNamespace = k8smeta.Namespace()
+		if conf.LeaseNamespace == "" {
+			return fmt.Errorf("leader_election: the namespace of pod is unknown, set lease_namespace or env POD_NAMESPACE")
+		}
+	}
+	if conf.Identity == "" {
+		conf.Identity = os.Getenv("POD_NAME")
+		if conf.Identity == "" {
+			conf.Identity = config.Config.GetHostname()
+		}
+	}
+	if conf.LeaseDuration <= 0 {
+		conf.LeaseDuration = config.Duration(defaultLeaseDuration)
+	}
+	if conf.RenewDeadline <= 0 {
+		conf.RenewDeadline = config.Duration(defaultRenewDeadline)
+	}
+	if conf.RetryPeriod <= 0 {
+		conf.RetryPeriod = config.Duration(defaultRetryPeriod)
+	}
+
+	client, err := k8smeta.NewClient(conf.Kubeconfig, time.Duration(conf.RenewDeadline))
+	if err != nil {
+		return fmt.Errorf("leader_election: %v", err)
+	}
+
+	le := &elector{inputs: inputs, watchers: make(map[*watcher]struct{}), done: make(chan struct{})}
+	leaderElector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock: &resourcelock.LeaseLock{
+			LeaseMeta:  metav1.ObjectMeta{Name: conf.LeaseName, Namespace: conf.LeaseNamespace},
+			Client:     client.CoordinationV1(),
+			LockConfig: resourcelock.ResourceLockConfig{Identity: conf.Identity},
+		},
+		LeaseDuration: time.Duration(conf.LeaseDuration),
+		RenewDeadline: time.Duration(conf.RenewDeadline),
+		RetryPeriod:   time.Duration(conf.RetryPeriod),
+		// the next leader takes over at once on shutdown, instead of waiting for the lease to expire
+		ReleaseOnCancel: true,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(context.Context) {
+				le.set(true)
+				leaderLog.Infof("started leading by %s", conf.Identity)
+			},
+			OnStoppedLeading: func() {
+				le.set(false)
+				leaderLog.Infof("stopped leading by %s", conf.Identity)
+			},
+			OnNewLeader: func(identity string) {
+				if identity != conf.Identity {
+					leaderLog.Infof("the leader is %s", identity)
+				}
+			},
+		},
+		Name: conf.LeaseName,
+	})
+	if err != nil {
+		return fmt.Errorf("leader_election: %v", err)
+	}
+
+	var ctx context.Context
+	ctx, le.cancel = context.WithCancel(context.Background())
+	go le.run(ctx, leaderElector)
+
+	e = le
+	leaderLog.Infof("leader election started, lease: %s/%s, identity: %s", conf.LeaseNamespace, conf.LeaseName, conf.Identity)
+	return nil
+}
+
+// run campaigns again once the leadership is lost, e.g. the apiserver is not reachable in the renew deadline
+func (le *elector) run(ctx context.Context, leaderElector *leaderelection.LeaderElector) {
+	defer close(le.done)
+	for {
+		leaderElector.Run(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+	}
+}
+
+func (le *elector) set(leading bool) {
+	le.mu.Lock()
+	defer le.mu.Unlock()
+
+	if leading {
+		atomic.StoreInt32(&le.leading, 1)
+		isLeader.Set(1)
+	} else {
+		atomic.StoreInt32(&le.leading, 0)
+		isLeader.Set(0)
+	}
+	for w := range le.watchers {
+		w.fn(leading)
+	}
+}
+
+// Allowed tells if the input of the name, without the prefix of the provider, runs on this replica
+func Allowed(input string) bool {
+	if e == nil || !e.inputs.Match(input) {
+		return true
+	}
+	return atomic.LoadInt32(&e.leading) == 1
+}
+
+type watcher struct {
+	fn func(allowed bool)
+}
+
+// Watch calls fn with whether the input of the name runs on this replica, at once and on every change of leadership,
+// so the cluster scoped inputs which keep watches on the apiserver, e.g. kube_events, start and stop them by the leadership.
+// fn must not block, and the returned func stops watching.
+func Watch(input string, fn func(allowed bool)) (cancel func()) {
+	if e == nil || !e.inputs.Match(input) {
+		fn(true)
+		return func() {}
+	}
+
+	w := &watcher{fn: fn}
+	e.mu.Lock()
+	fn(atomic.LoadInt32(&e.leading) == 1)
+	e.watchers[w] = struct{}{}
+	e.mu.Unlock()
+
+	return func() {
+		e.mu.Lock()
+		delete(e.watchers, w)
+		e.mu.Unlock()
+	}
+}
+
+var stopOnce sync.Once
+
+// Stop releases the lease if leading
+func Stop() {
+	if e == nil {
+		return
+	}
+	stopOnce.Do(func() {
+		e.cancel()
+		select {
+		case <-e.done:
+		case <-time.After(5 * time.Second):
+		}
+	})
+}
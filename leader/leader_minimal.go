@@ -0,0 +1,33 @@
+//go:build minimal
+
+package leader
+
+import (
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/pkg/logger"
+)
+
+var leaderLog = logger.New("leader")
+
+// Init does nothing, the kubernetes client is not built into the minimal build to save the size,
+// all inputs run on every replica
+func Init() error {
+	if conf := config.Config.LeaderElection; conf != nil && conf.Enable {
+		leaderLog.Warnf("leader election is not supported by the minimal build")
+	}
+	return nil
+}
+
+// Allowed tells if the input of the name runs on this replica, always true in the minimal build
+func Allowed(input string) bool {
+	return true
+}
+
+// Watch calls fn at once, the inputs always run in the minimal build
+func Watch(input string, fn func(allowed bool)) (cancel func()) {
+	fn(true)
+	return func() {}
+}
+
+// Stop does nothing
+func Stop() {}
@@ -10,7 +10,6 @@ package auditor
 import (
 	"encoding/json"
 	"io/ioutil"
-	"log"
 	"os"
 	"path/filepath"
 	"sync"
@@ -18,8 +17,11 @@ import (
 
 	config "flashcat.cloud/categraf/config/logs"
 	"flashcat.cloud/categraf/logs/message"
+	"flashcat.cloud/categraf/pkg/logger"
 )
 
+var auditorLog = logger.New("logs.auditor")
+
 // DefaultRegistryFilename is the default registry filename
 const DefaultRegistryFilename = "registry.json"
 
@@ -89,7 +91,7 @@ func (a *RegistryAuditor) Stop() {
 	a.closeChannels()
 	a.cleanupRegistry()
 	if err := a.flushRegistry(); err != nil {
-		log.Println("W!", err)
+		auditorLog.Warnf("%v", err)
 	}
 }
 
@@ -172,10 +174,10 @@ func (a *RegistryAuditor) run() {
 			if err != nil {
 				if os.IsPermission(err) || os.IsNotExist(err) {
 					fileError.Do(func() {
-						log.Println("W!", err)
+						auditorLog.Warnf("%v", err)
 					})
 				} else {
-					log.Println("E!", err)
+					auditorLog.Errorf("%v", err)
 				}
 			}
 		}
@@ -187,15 +189,15 @@ func (a *RegistryAuditor) recoverRegistry() map[string]*RegistryEntry {
 	mr, err := ioutil.ReadFile(a.registryPath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			log.Println("I! Could not find state file at %q, will start with default offsets", a.registryPath)
+			auditorLog.Infof("Could not find state file at %q, will start with default offsets", a.registryPath)
 		} else {
-			log.Println("E!", err)
+			auditorLog.Errorf("%v", err)
 		}
 		return make(map[string]*RegistryEntry)
 	}
 	r, err := a.unmarshalRegistry(mr)
 	if err != nil {
-		log.Println("E!", err)
+		auditorLog.Errorf("%v", err)
 		return make(map[string]*RegistryEntry)
 	}
 	return r
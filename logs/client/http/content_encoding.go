@@ -10,6 +10,8 @@ package http
 import (
 	"bytes"
 	"compress/gzip"
+
+	"github.com/klauspost/compress/zstd"
 )
 
 // ContentEncoding encodes the payload
@@ -36,9 +38,11 @@ type GzipContentEncoding struct {
 	level int
 }
 
-// NewGzipContentEncoding creates a new Gzip content type
+// NewGzipContentEncoding creates a new Gzip content type, of the default level if level is 0
 func NewGzipContentEncoding(level int) *GzipContentEncoding {
-	if level < gzip.NoCompression {
+	if level == 0 {
+		level = gzip.DefaultCompression
+	} else if level < gzip.NoCompression {
 		level = gzip.NoCompression
 	} else if level > gzip.BestCompression {
 		level = gzip.BestCompression
@@ -73,3 +77,30 @@ func (c *GzipContentEncoding) encode(payload []byte) ([]byte, error) {
 	}
 	return compressedPayload.Bytes(), nil
 }
+
+// ZstdContentEncoding encodes the payload using zstd algorithm
+type ZstdContentEncoding struct {
+	encoder *zstd.Encoder
+}
+
+// NewZstdContentEncoding creates a new Zstd content type, the level is of zstd, i.e. 1 (fastest) to 22 (best),
+// which is mapped to the nearest level of the encoder, the default level if 0
+func NewZstdContentEncoding(level int) *ZstdContentEncoding {
+	var options []zstd.EOption
+	if level > 0 {
+		options = append(options, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+	}
+	// the options are valid, and the encoder is used by EncodeAll only, which is safe for concurrent sends
+	encoder, _ := zstd.NewWriter(nil, options...)
+	return &ZstdContentEncoding{
+		encoder,
+	}
+}
+
+func (c *ZstdContentEncoding) name() string {
+	return "zstd"
+}
+
+func (c *ZstdContentEncoding) encode(payload []byte) ([]byte, error) {
+	return c.encoder.EncodeAll(payload, make([]byte, 0, len(payload)/2)), nil
+}
@@ -8,7 +8,6 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
-	"log"
 	"net/http"
 	"net/url"
 	"sync"
@@ -18,8 +17,12 @@ import (
 	"flashcat.cloud/categraf/logs/client"
 	"flashcat.cloud/categraf/pkg/backoff"
 	httputils "flashcat.cloud/categraf/pkg/httpx"
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/signing"
 )
 
+var httpLog = logger.New("logs.client.http")
+
 // ContentType options,
 const (
 	TextContentType = "text/plain"
@@ -52,6 +55,7 @@ type Destination struct {
 	blockedUntil        time.Time
 	protocol            logsconfig.IntakeProtocol
 	origin              logsconfig.IntakeOrigin
+	format              string
 }
 
 // NewDestination returns a new Destination.
@@ -87,6 +91,7 @@ func newDestination(endpoint logsconfig.Endpoint, contentType string, destinatio
 		backoff:             policy,
 		protocol:            endpoint.Protocol,
 		origin:              endpoint.Origin,
+		format:              endpoint.Format,
 	}
 }
 
@@ -137,6 +142,10 @@ func (d *Destination) unconditionalSend(payload []byte) (err error) {
 	}
 	req.Header.Set("User-Agent", "categraf")
 	req.Header.Set("CATEGRAF-API-KEY", d.apiKey)
+	if d.format == logsconfig.DatadogFormat {
+		// the logs intake of datadog, e.g. https://http-intake.logs.datadoghq.com/api/v2/logs
+		req.Header.Set("DD-API-KEY", d.apiKey)
+	}
 	req.Header.Set("Content-Type", d.contentType)
 	req.Header.Set("Content-Encoding", d.contentEncoding.name())
 	if d.protocol != "" {
@@ -147,6 +156,7 @@ func (d *Destination) unconditionalSend(payload []byte) (err error) {
 		// TODO agentversion
 		req.Header.Set("CATEGRAF-ORIGIN-VERSION", "0.0.1")
 	}
+	signing.Sign(req, encodedPayload)
 	req = req.WithContext(ctx)
 
 	resp, err := d.client.Do(req)
@@ -167,7 +177,7 @@ func (d *Destination) unconditionalSend(payload []byte) (err error) {
 		return err
 	}
 	if resp.StatusCode >= 400 {
-		log.Printf("W! failed to post http payload. code=%d host=%s response=%s\n", resp.StatusCode, d.host, string(response))
+		httpLog.Warnf("failed to post http payload. code=%d host=%s response=%s", resp.StatusCode, d.host, string(response))
 	}
 	if resp.StatusCode == 429 || resp.StatusCode >= 500 {
 		// the server could not serve the request, most likely because of an
@@ -253,7 +263,10 @@ func buildURL(endpoint logsconfig.Endpoint) string {
 }
 
 func buildContentEncoding(endpoint logsconfig.Endpoint) ContentEncoding {
-	if endpoint.UseCompression {
+	switch {
+	case endpoint.Compression == logsconfig.ZstdCompression:
+		return NewZstdContentEncoding(endpoint.CompressionLevel)
+	case endpoint.UseCompression || endpoint.Compression == logsconfig.GzipCompression:
 		return NewGzipContentEncoding(endpoint.CompressionLevel)
 	}
 	return IdentityContentType
@@ -261,18 +274,18 @@ func buildContentEncoding(endpoint logsconfig.Endpoint) ContentEncoding {
 
 // CheckConnectivity check if sending logs through HTTP works
 func CheckConnectivity(endpoint logsconfig.Endpoint) logsconfig.HTTPConnectivity {
-	log.Println("I! Checking HTTP connectivity...")
+	httpLog.Infof("Checking HTTP connectivity...")
 	ctx := client.NewDestinationsContext()
 	ctx.Start()
 	defer ctx.Stop()
 	// Lower the timeout to 5s because HTTP connectivity test is done synchronously during the agent bootstrap sequence
 	destination := newDestination(endpoint, JSONContentType, ctx, time.Second*5, 0)
-	log.Println("I! Sending HTTP connectivity request to", destination.url)
+	httpLog.Infof("Sending HTTP connectivity request to %v", destination.url)
 	err := destination.unconditionalSend(emptyPayload)
 	if err != nil {
-		log.Println("E! HTTP connectivity failure:", err)
+		httpLog.Errorf("HTTP connectivity failure: %v", err)
 	} else {
-		log.Println("HTTP connectivity successful")
+		httpLog.Infof("HTTP connectivity successful")
 	}
 	return err == nil
 }
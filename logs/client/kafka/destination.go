@@ -3,22 +3,25 @@
 package kafka
 
 import (
+	"bytes"
 	"context"
 	"errors"
-	"log"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/Shopify/sarama"
-	json "github.com/mailru/easyjson"
+	"github.com/mailru/easyjson/jlexer"
 
 	coreconfig "flashcat.cloud/categraf/config"
 	logsconfig "flashcat.cloud/categraf/config/logs"
 	"flashcat.cloud/categraf/logs/client"
 	"flashcat.cloud/categraf/pkg/backoff"
+	"flashcat.cloud/categraf/pkg/logger"
 )
 
+var kafkaLog = logger.New("logs.client.kafka")
+
 // ContentType options,
 const (
 	TextContentType = "text/plain"
@@ -52,6 +55,7 @@ type Destination struct {
 	blockedUntil        time.Time
 	protocol            logsconfig.IntakeProtocol
 	origin              logsconfig.IntakeOrigin
+	maxMessageBytes     int
 }
 
 // NewDestination returns a new Destination.
@@ -98,9 +102,19 @@ func newDestination(endpoint logsconfig.Endpoint, contentType string, destinatio
 		backoff:             policy,
 		protocol:            endpoint.Protocol,
 		origin:              endpoint.Origin,
+		maxMessageBytes:     coreconfig.Config.Logs.Producer.MaxMessageBytes,
 	}
 }
 
+// recordOverhead is reserved in the messages for the headers and the fields of kafka records besides the key and value
+const recordOverhead = 256
+
+// MaxPayloadSize returns the max size of the payloads accepted by the producer, i.e. Producer.MaxMessageBytes
+// less the key and the overhead of records
+func (d *Destination) MaxPayloadSize() int {
+	return d.maxMessageBytes - len(d.apiKey) - recordOverhead
+}
+
 func errorToTag(err error) string {
 	if err == nil {
 		return "none"
@@ -140,25 +154,48 @@ func (d *Destination) unconditionalSend(payload []byte) (err error) {
 		return err
 	}
 	topic := d.topic
-	data := &Data{}
-	err = json.Unmarshal(payload, data)
-	if err != nil {
-		log.Println("E! get topic from payload, ", err)
+	// the topic of a log source is in the json envelope, the payloads of the other formats go to the topic of endpoint
+	if t := payloadTopic(payload); t != "" {
+		topic = t
 	}
-	if data.Topic != "" {
-		topic = data.Topic
-	}
-	err = NewBuilder().WithMessage(d.apiKey, encodedPayload).WithTopic(topic).Send(d.client)
+	err = NewBuilder().WithMessage(d.apiKey, encodedPayload).WithTopic(topic).WithContentEncoding(d.contentEncoding.name()).Send(d.client)
 	if err != nil {
 		if ctx.Err() == context.Canceled {
 			return ctx.Err()
 		}
+		if errors.Is(err, sarama.ErrMessageSizeTooLarge) {
+			// the payload is rejected however many times it is sent
+			return err
+		}
 		// most likely a network or a connect error, the callee should retry.
 		return client.NewRetryableError(err)
 	}
 	return nil
 }
 
+// payloadTopic returns the topic of the json envelope of payload, either in a json array or one per line,
+// the batches are split by topic by the sender so the first envelope tells the topic of a batch
+func payloadTopic(payload []byte) string {
+	if len(payload) == 0 || (payload[0] != '{' && payload[0] != '[') {
+		return ""
+	}
+	if i := bytes.IndexByte(payload, '\n'); i > 0 && payload[0] == '{' {
+		// ndjson
+		payload = payload[:i]
+	}
+	l := &jlexer.Lexer{Data: payload}
+	if payload[0] == '[' {
+		l.Delim('[')
+	}
+	data := &Data{}
+	data.UnmarshalEasyJSON(l)
+	if err := l.Error(); err != nil {
+		kafkaLog.Errorf("get topic from payload,  %v", err)
+		return ""
+	}
+	return data.Topic
+}
+
 // SendAsync sends a payload in background.
 func (d *Destination) SendAsync(payload []byte) {
 	d.once.Do(func() {
@@ -194,7 +231,10 @@ func (d *Destination) sendInBackground(payloadChan chan []byte) {
 }
 
 func buildContentEncoding(endpoint logsconfig.Endpoint) ContentEncoding {
-	if endpoint.UseCompression {
+	switch {
+	case endpoint.Compression == logsconfig.ZstdCompression:
+		return NewZstdContentEncoding(endpoint.CompressionLevel)
+	case endpoint.UseCompression || endpoint.Compression == logsconfig.GzipCompression:
 		return NewGzipContentEncoding(endpoint.CompressionLevel)
 	}
 	return IdentityContentType
@@ -27,6 +27,15 @@ func (m *MessageBuilder) WithTopic(topic string) *MessageBuilder {
 	return m
 }
 
+// WithContentEncoding sets the content-encoding header of the compressed messages,
+// the headers are sent to kafka 0.11 and above
+func (m *MessageBuilder) WithContentEncoding(encoding string) *MessageBuilder {
+	if encoding != "" && encoding != IdentityContentType.name() {
+		m.Headers = append(m.Headers, sarama.RecordHeader{Key: []byte("content-encoding"), Value: []byte(encoding)})
+	}
+	return m
+}
+
 func (s *MessageBuilder) build() (*sarama.ProducerMessage, error) {
 	switch {
 	case len(s.Topic) == 0:
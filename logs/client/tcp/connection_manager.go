@@ -12,20 +12,23 @@ import (
 	"crypto/tls"
 	"fmt"
 	"io"
-	"log"
 	"math/rand"
 	"net"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/net/proxy"
 
 	logsconfig "flashcat.cloud/categraf/config/logs"
 	"flashcat.cloud/categraf/logs/status"
+	"flashcat.cloud/categraf/pkg/logger"
 )
 
+var tcpLog = logger.New("logs.client.tcp")
+
 const (
 	maxExpBackoffCount    = 7
 	connectionTimeout     = 20 * time.Second
@@ -37,12 +40,17 @@ type ConnectionManager struct {
 	endpoint  logsconfig.Endpoint
 	mutex     sync.Mutex
 	firstConn sync.Once
+	// the tls sessions are resumed by the reconnections, which saves the full handshakes
+	sessionCache tls.ClientSessionCache
+	// the connections broken since the last successful write, the reconnections back off by it
+	brokenConns uint32
 }
 
 // NewConnectionManager returns an initialized ConnectionManager
 func NewConnectionManager(endpoint logsconfig.Endpoint) *ConnectionManager {
 	return &ConnectionManager{
-		endpoint: endpoint,
+		endpoint:     endpoint,
+		sessionCache: tls.NewLRUClientSessionCache(0),
 	}
 }
 
@@ -60,13 +68,14 @@ func (cm *ConnectionManager) NewConnection(ctx context.Context) (net.Conn, error
 
 	cm.firstConn.Do(func() {
 		if cm.endpoint.ProxyAddress != "" {
-			log.Printf("Connecting to the backend: %v, via socks5: %v, with SSL: %v\n", cm.address(), cm.endpoint.ProxyAddress, cm.endpoint.UseSSL)
+			tcpLog.Infof("Connecting to the backend: %v, via socks5: %v, with SSL: %v", cm.address(), cm.endpoint.ProxyAddress, cm.endpoint.UseSSL)
 		} else {
-			log.Printf("Connecting to the backend: %v, with SSL: %v\n", cm.address(), cm.endpoint.UseSSL)
+			tcpLog.Infof("Connecting to the backend: %v, with SSL: %v", cm.address(), cm.endpoint.UseSSL)
 		}
 	})
 
-	var retries uint
+	// the connections broken right after they are established back off too, e.g. reset by a middlebox
+	retries := uint(atomic.LoadUint32(&cm.brokenConns))
 	var err error
 	for {
 		if err != nil {
@@ -87,37 +96,48 @@ func (cm *ConnectionManager) NewConnection(ctx context.Context) (net.Conn, error
 		}
 
 		var conn net.Conn
+		dialer := &net.Dialer{
+			Timeout: connectionTimeout,
+			// the keepalive probes detect the peers gone silently, and keep the idle connections
+			// alive in the conntrack tables of middleboxes
+			KeepAlive: cm.endpoint.KeepAlive,
+		}
 		if cm.endpoint.ProxyAddress != "" {
-			var dialer proxy.Dialer
-			dialer, err = proxy.SOCKS5("tcp", cm.endpoint.ProxyAddress, nil, proxy.Direct)
+			var socks proxy.Dialer
+			socks, err = proxy.SOCKS5("tcp", cm.endpoint.ProxyAddress, nil, dialer)
 			if err != nil {
-				log.Println("E!", err)
+				tcpLog.Errorf("%v", err)
 				continue
 			}
 			// TODO: handle timeouts with ctx.
-			conn, err = dialer.Dial("tcp", cm.address())
+			conn, err = socks.Dial("tcp", cm.address())
 		} else {
-			var dialer net.Dialer
 			dctx, cancel := context.WithTimeout(ctx, connectionTimeout)
-			defer cancel()
 			conn, err = dialer.DialContext(dctx, "tcp", cm.address())
+			cancel()
 		}
 		if err != nil {
-			log.Println("W!", err)
+			tcpLog.Warnf("%v", err)
 			continue
 		}
-		log.Println("I! connected to", cm.address())
+		tcpLog.Infof("connected to %v", cm.address())
 
 		if cm.endpoint.UseSSL {
 			sslConn := tls.Client(conn, &tls.Config{
-				ServerName: cm.endpoint.Host,
+				ServerName:         cm.endpoint.Host,
+				ClientSessionCache: cm.sessionCache,
 			})
 			err = cm.handshakeWithTimeout(sslConn, connectionTimeout)
 			if err != nil {
-				log.Println("E!", err)
+				tcpLog.Errorf("%v", err)
+				conn.Close()
 				continue
 			}
-			log.Println("SSL handshake successful")
+			if sslConn.ConnectionState().DidResume {
+				tcpLog.Infof("SSL session resumed")
+			} else {
+				tcpLog.Infof("SSL handshake successful")
+			}
 			conn = sslConn
 		}
 
@@ -149,10 +169,24 @@ func (cm *ConnectionManager) ShouldReset(connCreationTime time.Time) bool {
 	return cm.endpoint.ConnectionResetInterval != 0 && time.Since(connCreationTime) > cm.endpoint.ConnectionResetInterval
 }
 
+// Broken tells a connection is broken, the next connections back off until a write succeeds
+func (cm *ConnectionManager) Broken() {
+	if atomic.LoadUint32(&cm.brokenConns) < maxExpBackoffCount {
+		atomic.AddUint32(&cm.brokenConns, 1)
+	}
+}
+
+// Healthy tells a write succeeded, the next connection is established immediately
+func (cm *ConnectionManager) Healthy() {
+	if atomic.LoadUint32(&cm.brokenConns) != 0 {
+		atomic.StoreUint32(&cm.brokenConns, 0)
+	}
+}
+
 // CloseConnection closes a connection on the client side
 func (cm *ConnectionManager) CloseConnection(conn net.Conn) {
 	conn.Close()
-	log.Println("Connection closed")
+	tcpLog.Infof("Connection closed")
 }
 
 // handleServerClose lets the connection manager detect when a connection
@@ -173,7 +207,7 @@ func (cm *ConnectionManager) handleServerClose(conn net.Conn) {
 			cm.CloseConnection(conn)
 			return
 		default:
-			log.Println("E!", err)
+			tcpLog.Errorf("%v", err)
 			return
 		}
 	}
@@ -181,7 +215,8 @@ func (cm *ConnectionManager) handleServerClose(conn net.Conn) {
 
 // backoff implements a randomized exponential backoff in case of connection failure
 // each invocation will trigger a sleep between [2^(retries-1), 2^retries) second
-// the exponent is capped at 7, which translates to max sleep between ~1min and ~2min
+// the exponent is capped at 7, which translates to max sleep between ~1min and ~2min,
+// and the sleep is capped at the reconnect_backoff_max of the endpoint
 func (cm *ConnectionManager) backoff(ctx context.Context, retries uint) {
 	if retries > maxExpBackoffCount {
 		retries = maxExpBackoffCount
@@ -190,6 +225,10 @@ func (cm *ConnectionManager) backoff(ctx context.Context, retries uint) {
 	backoffMax := 1 << retries
 	backoffMin := 1 << (retries - 1)
 	backoffDuration := time.Duration(backoffMin+rand.Intn(backoffMax-backoffMin)) * time.Second
+	if limit := cm.endpoint.ReconnectBackoffMax; limit > 0 && backoffDuration > limit {
+		// keep the jitter below the limit, so the agents don't reconnect in lockstep
+		backoffDuration = limit/2 + time.Duration(rand.Int63n(int64(limit/2)+1))
+	}
 
 	ctx, cancel := context.WithTimeout(ctx, backoffDuration)
 	defer cancel()
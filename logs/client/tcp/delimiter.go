@@ -10,6 +10,8 @@ package tcp
 import (
 	"bytes"
 	"encoding/binary"
+
+	logsconfig "flashcat.cloud/categraf/config/logs"
 )
 
 // Delimiter is responsible for adding delimiters to the frames being sent.
@@ -17,8 +19,15 @@ type Delimiter interface {
 	delimit(content []byte) ([]byte, error)
 }
 
-// NewDelimiter returns a delimiter.
-func NewDelimiter(useProto bool) Delimiter {
+// NewDelimiter returns the delimiter of framing, the messages in protobuf are prefixed by their length
+// if the framing is not set.
+func NewDelimiter(framing string, useProto bool) Delimiter {
+	switch framing {
+	case logsconfig.LengthPrefixFraming:
+		return &lengthPrefix
+	case logsconfig.NewlineFraming:
+		return &lineBreak
+	}
 	if useProto {
 		return &lengthPrefix
 	}
@@ -37,7 +37,7 @@ func NewDestination(endpoint logsconfig.Endpoint, useProto bool, destinationsCon
 	prefix := endpoint.APIKey + string(' ')
 	return &Destination{
 		prefixer:            newPrefixer(prefix),
-		delimiter:           NewDelimiter(useProto),
+		delimiter:           NewDelimiter(endpoint.Framing, useProto),
 		connManager:         NewConnectionManager(endpoint),
 		destinationsContext: destinationsContext,
 	}
@@ -66,12 +66,18 @@ func (d *Destination) Send(payload []byte) error {
 		return err
 	}
 
+	// a connection dropped silently by a middlebox blocks the writes once the buffers are full
+	if timeout := d.connManager.endpoint.WriteTimeout; timeout > 0 {
+		d.conn.SetWriteDeadline(time.Now().Add(timeout)) //nolint:errcheck
+	}
 	_, err = d.conn.Write(frame)
 	if err != nil {
 		d.connManager.CloseConnection(d.conn)
+		d.connManager.Broken()
 		d.conn = nil
 		return client.NewRetryableError(err)
 	}
+	d.connManager.Healthy()
 
 	if d.connManager.ShouldReset(d.connCreationTime) {
 		d.connManager.CloseConnection(d.conn)
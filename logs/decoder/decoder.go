@@ -9,15 +9,17 @@ package decoder
 
 import (
 	"bytes"
-	"log"
 	"regexp"
 	"sync/atomic"
 	"time"
 
 	config "flashcat.cloud/categraf/config/logs"
 	"flashcat.cloud/categraf/logs/parser"
+	"flashcat.cloud/categraf/pkg/logger"
 )
 
+var decoderLog = logger.New("logs.decoder")
+
 // defaultContentLenLimit represents the max size for a line,
 // if a line is bigger than this limit, it will be truncated.
 const defaultContentLenLimit = 256 * 1000
@@ -124,10 +126,10 @@ func NewDecoderWithEndLineMatcher(source *config.LogSource, parser parser.Parser
 	if lineHandler == nil {
 		// TODO configure multiline
 		if source.Config.AutoMultiLine {
-			log.Println("Auto multi line log detection enabled")
+			decoderLog.Infof("Auto multi line log detection enabled")
 
 			if multiLinePattern != nil {
-				log.Println("Found a previously detected pattern - using multiline handler")
+				decoderLog.Infof("Found a previously detected pattern - using multiline handler")
 
 				// Save the pattern again for the next rotation
 				detectedPattern.Set(multiLinePattern)
@@ -165,7 +167,7 @@ func buildAutoMultilineHandlerFromConfig(outputChan chan *Message, lineLimit int
 	for _, p := range additionalPatterns {
 		compiled, err := regexp.Compile("^" + p)
 		if err != nil {
-			log.Println("logs_config.auto_multi_line_extra_patterns containing value: ", p, " is not a valid regular expression")
+			decoderLog.Infof("logs_config.auto_multi_line_extra_patterns containing value:  %v  is not a valid regular expression", p)
 			continue
 		}
 		additionalPatternsCompiled = append(additionalPatternsCompiled, compiled)
@@ -9,7 +9,6 @@ package decoder
 
 import (
 	"bytes"
-	"log"
 	"time"
 
 	"flashcat.cloud/categraf/logs/parser"
@@ -67,7 +66,7 @@ func (p *SingleLineParser) process(input *DecodedInput) {
 	// Just parse an pass to the next step
 	content, status, timestamp, _, err := p.parser.Parse(input.content)
 	if err != nil {
-		log.Println(err)
+		decoderLog.Infof("%v", err)
 	}
 	p.lineHandler.Handle(NewMessage(content, status, input.rawDataLen, timestamp))
 }
@@ -155,7 +154,7 @@ func (p *MultiLineParser) run() {
 func (p *MultiLineParser) process(input *DecodedInput) {
 	content, status, timestamp, partial, err := p.parser.Parse(input.content)
 	if err != nil {
-		log.Println(err, string(input.content))
+		decoderLog.Infof("%v %v", err, string(input.content))
 	}
 	// track the raw data length and the timestamp so that the agent tails
 	// from the right place at restart
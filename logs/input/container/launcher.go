@@ -8,14 +8,16 @@
 package container
 
 import (
-	"log"
 	"sync"
 	"time"
 
 	"flashcat.cloud/categraf/logs/restart"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/pkg/retry"
 )
 
+var containerLog = logger.New("logs.input.container")
+
 // Launchable is a retryable wrapper for a restartable
 type Launchable struct {
 	IsAvailable func() (bool, *retry.Retrier)
@@ -60,11 +62,11 @@ func (l *Launcher) shouldRetry() (bool, time.Duration) {
 		}
 	}
 	if retryer == nil {
-		log.Println("Nothing to retry - stopping")
+		containerLog.Infof("Nothing to retry - stopping")
 		return false, 0
 	}
 	nextRetry := time.Until(retryer.NextRetry())
-	log.Printf("Could not find an available a container launcher - will try again in %s", nextRetry.Truncate(time.Second))
+	containerLog.Warnf("Could not find an available a container launcher - will try again in %s", nextRetry.Truncate(time.Second))
 	return true, nextRetry
 
 }
@@ -9,15 +9,17 @@ package file
 
 import (
 	"fmt"
-	"log"
 	"os"
 	"path/filepath"
 	"sort"
 
 	logsconfig "flashcat.cloud/categraf/config/logs"
 	"flashcat.cloud/categraf/logs/status"
+	"flashcat.cloud/categraf/pkg/logger"
 )
 
+var fileLog = logger.New("logs.input.file")
+
 // OpenFilesLimitWarningType is the key of the message generated when too many
 // files are tailed
 const openFilesLimitWarningType = "open_files_limit_warning"
@@ -84,7 +86,7 @@ func (p *Provider) FilesToTail(sources []*logsconfig.LogSource) []*File {
 				source.Messages.AddMessage(source.Config.Path, fmt.Sprintf("%d files tailed out of %d files matching", tailedFileCounter, len(files)))
 			}
 			if shouldLogErrors {
-				log.Println("W! Could not collect files:", err)
+				fileLog.Warnf("Could not collect files: %v", err)
 			}
 			continue
 		}
@@ -112,7 +114,7 @@ func (p *Provider) FilesToTail(sources []*logsconfig.LogSource) []*File {
 	}
 
 	if len(filesToTail) == p.filesLimit {
-		log.Println("W! Reached the limit on the maximum number of files in use: ", p.filesLimit)
+		fileLog.Warnf("Reached the limit on the maximum number of files in use:  %v", p.filesLimit)
 		return filesToTail
 	}
 
@@ -171,10 +173,10 @@ func (p *Provider) searchFiles(pattern string, source *logsconfig.LogSource) ([]
 			return nil, fmt.Errorf("malformed exclusion pattern: %s, %s", excludePattern, err)
 		}
 		for _, excludedPath := range excludedGlob {
-			log.Println("Adding excluded path:", excludedPath)
+			fileLog.Infof("Adding excluded path: %v", excludedPath)
 			excludedPaths[excludedPath]++
 			if excludedPaths[excludedPath] > 1 {
-				log.Println("Overlapping excluded path:", excludedPath)
+				fileLog.Infof("Overlapping excluded path: %v", excludedPath)
 			}
 		}
 	}
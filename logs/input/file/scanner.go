@@ -8,7 +8,6 @@
 package file
 
 import (
-	"log"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -204,7 +203,7 @@ func (s *Scanner) launchTailers(source *logsconfig.LogSource) {
 	files, err := s.fileProvider.CollectFiles(source)
 	if err != nil {
 		source.Status.Error(err)
-		log.Println("W! Could not collect files: ", err)
+		fileLog.Warnf("Could not collect files:  %v", err)
 		return
 	}
 	for _, file := range files {
@@ -260,15 +259,15 @@ func (s *Scanner) startNewTailer(file *File, m logsconfig.TailingMode) bool {
 
 	offset, whence, err := Position(s.registry, tailer.Identifier(), mode)
 	if err != nil {
-		log.Println("W! Could not recover offset for file with path", file.Path, err)
+		fileLog.Warnf("Could not recover offset for file with path %v %v", file.Path, err)
 	}
 
 	if coreconfig.Config.DebugMode {
-		log.Printf("Starting a new tailer for: %s (offset: %d, whence: %d) for tailer key %s\n", file.Path, offset, whence, file.GetScanKey())
+		fileLog.Infof("Starting a new tailer for: %s (offset: %d, whence: %d) for tailer key %s", file.Path, offset, whence, file.GetScanKey())
 	}
 	err = tailer.Start(offset, whence)
 	if err != nil {
-		log.Println(err)
+		fileLog.Infof("%v", err)
 		return false
 	}
 
@@ -346,7 +345,7 @@ func (s *Scanner) handleTailingModeChange(tailerID string, currentTailingMode lo
 	}
 	previousMode, _ := logsconfig.TailingModeFromString(s.registry.GetTailingMode(tailerID))
 	if previousMode != currentTailingMode {
-		log.Printf("Tailing mode changed for %v. Was: %v: Now: %v\n", tailerID, previousMode, currentTailingMode)
+		fileLog.Infof("Tailing mode changed for %v. Was: %v: Now: %v", tailerID, previousMode, currentTailingMode)
 		if currentTailingMode == logsconfig.Beginning {
 			// end -> beginning, the offset will be honored if it exists
 			return logsconfig.Beginning
@@ -366,13 +365,13 @@ func (s *Scanner) stopTailer(tailer *Tailer) {
 // restartTailer safely stops tailer and starts a new one
 // returns true if the new tailer is up and running, false if an error occurred
 func (s *Scanner) restartTailerAfterFileRotation(tailer *Tailer, file *File) bool {
-	log.Println("Log rotation happened to ", file.Path)
+	fileLog.Infof("Log rotation happened to  %v", file.Path)
 	tailer.StopAfterFileRotation()
 	tailer = s.createRotatedTailer(file, tailer.outputChan, tailer.GetDetectedPattern())
 	// force reading file from beginning since it has been log-rotated
 	err := tailer.StartFromBeginning()
 	if err != nil {
-		log.Println(err)
+		fileLog.Infof("%v", err)
 		return false
 	}
 	s.tailers[file.GetScanKey()] = tailer
@@ -11,7 +11,6 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"log"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -175,7 +174,7 @@ func (t *Tailer) readForever() {
 		select {
 		case <-t.stop:
 			if n != 0 && atomic.LoadInt32(&t.didFileRotate) == 1 {
-				log.Println("W! Tailer stopped after rotation close timeout with remaining unread data")
+				fileLog.Warnf("Tailer stopped after rotation close timeout with remaining unread data")
 			}
 			// stop reading data from file
 			return
@@ -232,7 +231,7 @@ func (t *Tailer) startStopTimer() {
 func (t *Tailer) onStop() {
 	t.osFile.Close()
 	t.decoder.Stop()
-	log.Println("Closed", t.file.Path, "for tailer key", t.file.GetScanKey(), "read", t.bytesRead, "bytes and", t.decoder.GetLineCount(), "lines")
+	fileLog.Infof("Closed %v for tailer key %v read %v bytes and %v lines", t.file.Path, t.file.GetScanKey(), t.bytesRead, t.decoder.GetLineCount())
 }
 
 // forwardMessages lets the Tailer forward log messages to the output channel
@@ -10,7 +10,6 @@ package file
 import (
 	"fmt"
 	"io"
-	"log"
 	"path/filepath"
 
 	coreconfig "flashcat.cloud/categraf/config"
@@ -29,7 +28,7 @@ func (t *Tailer) setup(offset int64, whence int) error {
 	t.tags = t.buildTailerTags()
 
 	if coreconfig.Config.DebugMode {
-		log.Println("I! Opening", t.file.Path, "for tailer key", t.file.GetScanKey())
+		fileLog.Infof("Opening %v for tailer key %v", t.file.Path, t.file.GetScanKey())
 	}
 	f, err := openFile(fullpath)
 	if err != nil {
@@ -53,7 +52,7 @@ func (t *Tailer) read() (int, error) {
 	if err != nil && err != io.EOF {
 		// an unexpected error occurred, stop the tailor
 		t.file.Source.Status.Error(err)
-		return 0, fmt.Errorf("E! Unexpected error occurred while reading file: ", err)
+		return 0, fmt.Errorf("E! Unexpected error occurred while reading file: %v", err)
 	}
 	if n == 0 {
 		return 0, nil
@@ -10,7 +10,6 @@ package file
 import (
 	"fmt"
 	"io"
-	"log"
 	"os"
 	"path/filepath"
 
@@ -28,7 +27,7 @@ func (t *Tailer) setup(offset int64, whence int) error {
 	// adds metadata to enable users to filter logs by filename
 	t.tags = t.buildTailerTags()
 
-	log.Println("Opening ", t.fullpath)
+	fileLog.Infof("Opening  %v", t.fullpath)
 	f, err := openFile(t.fullpath)
 	if err != nil {
 		return err
@@ -51,18 +50,18 @@ func (t *Tailer) readAvailable() (int, error) {
 
 	st, err := f.Stat()
 	if err != nil {
-		log.Println("Error stat()ing file", err)
+		fileLog.Warnf("Error stat()ing file %v", err)
 		return 0, err
 	}
 
 	sz := st.Size()
 	offset := t.GetReadOffset()
 	if sz == 0 {
-		log.Println("File size now zero, resetting offset")
+		fileLog.Infof("File size now zero, resetting offset")
 		t.SetReadOffset(0)
 		t.SetDecodedOffset(0)
 	} else if sz < offset {
-		log.Println("Offset off end of file, resetting")
+		fileLog.Infof("Offset off end of file, resetting")
 		t.SetReadOffset(0)
 		t.SetDecodedOffset(0)
 	}
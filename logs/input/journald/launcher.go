@@ -8,13 +8,14 @@
 package journald
 
 import (
-	"log"
-
 	"flashcat.cloud/categraf/logs/auditor"
 	"flashcat.cloud/categraf/logs/pipeline"
 	"flashcat.cloud/categraf/logs/restart"
+	"flashcat.cloud/categraf/pkg/logger"
 )
 
+var journaldLog = logger.New("logs.input.journald")
+
 // Launcher is in charge of starting and stopping new journald tailers
 type Launcher struct {
 	sources          chan *config.LogSource
@@ -52,7 +53,7 @@ func (l *Launcher) run() {
 			}
 			tailer, err := l.setupTailer(source)
 			if err != nil {
-				log.Println("Could not set up journald tailer: ", err)
+				journaldLog.Warnf("Could not set up journald tailer:  %v", err)
 			} else {
 				l.tailers[identifier] = tailer
 			}
@@ -11,7 +11,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"time"
 
 	"github.com/coreos/go-systemd/sdjournal"
@@ -58,14 +57,14 @@ func (t *Tailer) Start(cursor string) error {
 	}
 	t.source.Status.Success()
 	t.source.AddInput(t.journalPath())
-	log.Println("Start tailing journal ", t.journalPath())
+	journaldLog.Infof("Start tailing journal  %v", t.journalPath())
 	go t.tail()
 	return nil
 }
 
 // Stop stops the tailer
 func (t *Tailer) Stop() {
-	log.Println("Stop tailing journal ", t.journalPath())
+	journaldLog.Infof("Stop tailing journal  %v", t.journalPath())
 	t.stop <- struct{}{}
 	t.source.RemoveInput(t.journalPath())
 	<-t.done
@@ -139,7 +138,7 @@ func (t *Tailer) tail() {
 			if err != nil && err != io.EOF {
 				err := fmt.Errorf("cant't tail journal %s: %s", t.journalPath(), err)
 				t.source.Status.Error(err)
-				log.Println(err)
+				journaldLog.Infof("%v", err)
 				return
 			}
 			if n < 1 {
@@ -149,7 +148,7 @@ func (t *Tailer) tail() {
 			}
 			entry, err := t.journal.GetEntry()
 			if err != nil {
-				log.Printf("Could not retrieve journal entry: %s\n", err)
+				journaldLog.Warnf("Could not retrieve journal entry: %s", err)
 				continue
 			}
 			if t.shouldDrop(entry) {
@@ -11,7 +11,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"os"
 	"path/filepath"
 	"strings"
@@ -25,9 +24,12 @@ import (
 	"flashcat.cloud/categraf/logs/util/containers"
 	"flashcat.cloud/categraf/logs/util/kubernetes/kubelet"
 	"flashcat.cloud/categraf/pkg/kubernetes"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/pkg/retry"
 )
 
+var kubernetesLog = logger.New("logs.input.kubernetes")
+
 const (
 	anyLogFile             = "*.log"
 	anyV19LogFile          = "%s_*.log"
@@ -68,10 +70,10 @@ func IsAvailable() (bool, *retry.Retrier) {
 
 	util, retrier := kubelet.GetKubeUtilWithRetrier()
 	if util != nil {
-		log.Println("Kubernetes launcher is available")
+		kubernetesLog.Infof("Kubernetes launcher is available")
 		return true, nil
 	}
-	log.Printf("Kubernetes launcher is not available: %v", retrier.LastError())
+	kubernetesLog.Infof("Kubernetes launcher is not available: %v", retrier.LastError())
 	return false, retrier
 }
 
@@ -79,7 +81,7 @@ func IsAvailable() (bool, *retry.Retrier) {
 func NewLauncher(sources *logsconfig.LogSources, services *service.Services, collectAll bool) *Launcher {
 	kubeutil, err := kubelet.GetKubeUtil()
 	if err != nil {
-		log.Printf("KubeUtil not available, failed to create launcher", err)
+		kubernetesLog.Warnf("KubeUtil not available, failed to create launcher: %v", err)
 		return nil
 	}
 	launcher := &Launcher{
@@ -99,13 +101,13 @@ func NewLauncher(sources *logsconfig.LogSources, services *service.Services, col
 
 // Start starts the launcher
 func (l *Launcher) Start() {
-	log.Println("Starting Kubernetes launcher")
+	kubernetesLog.Infof("Starting Kubernetes launcher")
 	go l.run()
 }
 
 // Stop stops the launcher
 func (l *Launcher) Stop() {
-	log.Println("Stopping Kubernetes launcher")
+	kubernetesLog.Infof("Stopping Kubernetes launcher")
 	l.stopped <- struct{}{}
 }
 
@@ -121,7 +123,7 @@ func (l *Launcher) run() {
 		case ops := <-l.retryOperations:
 			l.addSource(ops.service)
 		case <-l.stopped:
-			log.Println("Kubernetes launcher stopped")
+			kubernetesLog.Infof("Kubernetes launcher stopped")
 			return
 		}
 	}
@@ -153,7 +155,7 @@ func (l *Launcher) scheduleServiceForRetry(svc *service.Service) {
 func (l *Launcher) delayRetry(ops *retryOps) {
 	delay := ops.backoff.NextBackOff()
 	if delay == backoff.Stop {
-		log.Println("Unable to add source for container %v", ops.service.GetEntityID())
+		kubernetesLog.Warnf("Unable to add source for container %v", ops.service.GetEntityID())
 		delete(l.pendingRetries, ops.service.GetEntityID())
 		return
 	}
@@ -169,7 +171,7 @@ func (l *Launcher) addSource(svc *service.Service) {
 	// If the container is already tailed, we don't do anything
 	// That shouldn't happen
 	if _, exists := l.sourcesByContainer[svc.GetEntityID()]; exists {
-		log.Printf("A source already exist for container %v", svc.GetEntityID())
+		kubernetesLog.Infof("A source already exist for container %v", svc.GetEntityID())
 		return
 	}
 
@@ -177,22 +179,22 @@ func (l *Launcher) addSource(svc *service.Service) {
 	if err != nil {
 		if errors.IsRetriable(err) {
 			// Attempt to reschedule the source later
-			log.Println("Failed to fetch pod info for container %v, will retry: %v", svc.Identifier, err)
+			kubernetesLog.Warnf("Failed to fetch pod info for container %v, will retry: %v", svc.Identifier, err)
 			l.scheduleServiceForRetry(svc)
 			return
 		}
-		log.Printf("Could not add source for container %v: %v", svc.Identifier, err)
+		kubernetesLog.Warnf("Could not add source for container %v: %v", svc.Identifier, err)
 		return
 	}
 	container, err := l.kubeutil.GetStatusForContainerID(pod, svc.GetEntityID())
 	if err != nil {
-		log.Println(err)
+		kubernetesLog.Infof("%v", err)
 		return
 	}
 	source, err := l.getSource(pod, container)
 	if err != nil {
 		if err != errCollectAllDisabled {
-			log.Println("Invalid configuration for pod %v, container %v: %v", pod.Metadata.Name, container.Name, err)
+			kubernetesLog.Infof("Invalid configuration for pod %v, container %v: %v", pod.Metadata.Name, container.Name, err)
 		}
 		return
 	}
@@ -259,14 +261,14 @@ func (l *Launcher) getSource(pod *kubernetes.Pod, container kubernetes.Container
 		}
 		if !(pod.Metadata.Annotations[AnnotationCollectKey] == "" ||
 			pod.Metadata.Annotations[AnnotationCollectKey] == "true") {
-			log.Printf("pod %s disable stdout collecting", pod.Metadata.Name)
+			kubernetesLog.Infof("pod %s disable stdout collecting", pod.Metadata.Name)
 			return nil, errCollectAllDisabled
 		}
 		// The logs source is the short image name
 		logsSource := ""
 		shortImageName, err := l.getShortImageName(pod, container.Name)
 		if err != nil {
-			log.Printf("Couldn't get short image for container '%s': %v", container.Name, err)
+			kubernetesLog.Warnf("Couldn't get short image for container '%s': %v", container.Name, err)
 			// Fallback and use `kubernetes` as source name
 			logsSource = kubernetesIntegration
 		} else {
@@ -277,7 +279,7 @@ func (l *Launcher) getSource(pod *kubernetes.Pod, container kubernetes.Container
 		if ok {
 			err = json.Unmarshal([]byte(ruleStr), &rules)
 			if err != nil {
-				log.Printf("pod rule %s unmarshal error %s", ruleStr, err)
+				kubernetesLog.Infof("pod rule %s unmarshal error %s", ruleStr, err)
 			}
 		}
 		topic := pod.Metadata.Annotations[AnnotationTopicKey]
@@ -352,7 +354,7 @@ func buildTags(pod *kubernetes.Pod, container kubernetes.ContainerStatus) []stri
 func getTaggerEntityID(ctrID string) string {
 	taggerEntityID, err := kubelet.KubeContainerIDToTaggerEntityID(ctrID)
 	if err != nil {
-		log.Printf("Could not get tagger entity ID: %v", err)
+		kubernetesLog.Warnf("Could not get tagger entity ID: %v", err)
 		return ctrID
 	}
 	return taggerEntityID
@@ -399,28 +401,28 @@ func (l *Launcher) getPath(basePath string, pod *kubernetes.Pod, container kuber
 		v110Dir := filepath.Join(oldDirectory, container.Name)
 		_, err := os.Stat(v110Dir)
 		if err == nil {
-			log.Printf("Logs path found for container %s, v1.13 >= kubernetes version >= v1.10", container.Name)
+			kubernetesLog.Infof("Logs path found for container %s, v1.13 >= kubernetes version >= v1.10", container.Name)
 			return filepath.Join(v110Dir, anyLogFile)
 		}
 		if !os.IsNotExist(err) {
-			log.Printf("Cannot get file info for %s: %v", v110Dir, err)
+			kubernetesLog.Warnf("Cannot get file info for %s: %v", v110Dir, err)
 		}
 
 		v19Files := filepath.Join(oldDirectory, fmt.Sprintf(anyV19LogFile, container.Name))
 		files, err := filepath.Glob(v19Files)
 		if err == nil && len(files) > 0 {
-			log.Printf("Logs path found for container %s, kubernetes version <= v1.9", container.Name)
+			kubernetesLog.Infof("Logs path found for container %s, kubernetes version <= v1.9", container.Name)
 			return v19Files
 		}
 		if err != nil {
-			log.Printf("Cannot get file info for %s: %v", v19Files, err)
+			kubernetesLog.Warnf("Cannot get file info for %s: %v", v19Files, err)
 		}
 		if len(files) == 0 {
-			log.Printf("Files matching %s not found", v19Files)
+			kubernetesLog.Infof("Files matching %s not found", v19Files)
 		}
 	}
 
-	log.Printf("Using the latest kubernetes logs path for container %s", container.Name)
+	kubernetesLog.Infof("Using the latest kubernetes logs path for container %s", container.Name)
 	return filepath.Join(basePath, l.getPodDirectorySince1_14(pod), container.Name, anyLogFile)
 }
 
@@ -442,7 +444,7 @@ func (l *Launcher) getShortImageName(pod *kubernetes.Pod, containerName string)
 	}
 	_, shortName, _, err := containers.SplitImageName(containerSpec.Image)
 	if err != nil {
-		log.Printf("Cannot parse image name: %v", err)
+		kubernetesLog.Warnf("Cannot parse image name: %v", err)
 	}
 	return shortName, err
 }
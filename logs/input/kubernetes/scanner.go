@@ -5,7 +5,6 @@ package kubernetes
 import (
 	"context"
 	logService "flashcat.cloud/categraf/logs/service"
-	"log"
 	"strings"
 	"sync"
 	"time"
@@ -35,7 +34,7 @@ func (s *Scanner) Scan() {
 	if s.kubelet == nil {
 		s.kubelet, err = kubelet.GetKubeUtil()
 		if err != nil {
-			log.Printf("connect kubelet error %s", err)
+			kubernetesLog.Infof("connect kubelet error %s", err)
 			return
 		}
 	}
@@ -48,7 +47,7 @@ func (s *Scanner) Scan() {
 		case <-ticker.C:
 			pods, err := s.kubelet.GetLocalPodList(ctx)
 			if err != nil {
-				log.Printf("get local pod list error %s", err)
+				kubernetesLog.Infof("get local pod list error %s", err)
 				return
 			}
 			fetched := make(map[string]struct{})
@@ -19,6 +19,7 @@ type Launcher struct {
 	frameSize        int
 	tcpSources       chan *logsconfig.LogSource
 	udpSources       chan *logsconfig.LogSource
+	unixSources      chan *logsconfig.LogSource
 	listeners        []restart.Restartable
 	stop             chan struct{}
 }
@@ -30,6 +31,7 @@ func NewLauncher(sources *logsconfig.LogSources, frameSize int, pipelineProvider
 		frameSize:        frameSize,
 		tcpSources:       sources.GetAddedForType(logsconfig.TCPType),
 		udpSources:       sources.GetAddedForType(logsconfig.UDPType),
+		unixSources:      sources.GetAddedForType(logsconfig.UnixType),
 		stop:             make(chan struct{}),
 	}
 }
@@ -51,6 +53,10 @@ func (l *Launcher) run() {
 			listener := NewUDPListener(l.pipelineProvider, source, l.frameSize)
 			listener.Start()
 			l.listeners = append(l.listeners, listener)
+		case source := <-l.unixSources:
+			listener := NewUnixListener(l.pipelineProvider, source, l.frameSize)
+			listener.Start()
+			l.listeners = append(l.listeners, listener)
 		case <-l.stop:
 			return
 		}
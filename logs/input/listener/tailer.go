@@ -9,15 +9,17 @@ package listener
 
 import (
 	"io"
-	"log"
 	"net"
 
 	logsconfig "flashcat.cloud/categraf/config/logs"
 	"flashcat.cloud/categraf/logs/decoder"
 	"flashcat.cloud/categraf/logs/message"
 	"flashcat.cloud/categraf/logs/parser"
+	"flashcat.cloud/categraf/pkg/logger"
 )
 
+var listenerLog = logger.New("logs.input.listener")
+
 // Tailer reads data from a connection
 type Tailer struct {
 	source     *logsconfig.LogSource
@@ -88,7 +90,7 @@ func (t *Tailer) readForever() {
 			}
 			if err != nil {
 				// an error occurred, stop from reading new data
-				log.Printf("Couldn't read message from connection: %v\n", err)
+				listenerLog.Warnf("Couldn't read message from connection: %v", err)
 				return
 			}
 			t.source.BytesRead.Add(int64(len(data)))
@@ -9,7 +9,6 @@ package listener
 
 import (
 	"fmt"
-	"log"
 	"net"
 	"sync"
 	"time"
@@ -38,7 +37,7 @@ func NewTCPListener(pipelineProvider pipeline.Provider, source *logsconfig.LogSo
 		var err error
 		idleTimeout, err = time.ParseDuration(source.Config.IdleTimeout)
 		if err != nil {
-			log.Printf("Error parsing log's idle_timeout as a duration: %s\n", err)
+			listenerLog.Warnf("Error parsing log's idle_timeout as a duration: %s", err)
 			idleTimeout = 0
 		}
 	}
@@ -55,10 +54,10 @@ func NewTCPListener(pipelineProvider pipeline.Provider, source *logsconfig.LogSo
 
 // Start starts the listener to accepts new incoming connections.
 func (l *TCPListener) Start() {
-	log.Printf("Starting TCP forwarder on port %d, with read buffer size: %d\n", l.source.Config.Port, l.frameSize)
+	listenerLog.Infof("Starting TCP forwarder on port %d, with read buffer size: %d", l.source.Config.Port, l.frameSize)
 	err := l.startListener()
 	if err != nil {
-		log.Printf("Can't start TCP forwarder on port %d: %v\n", l.source.Config.Port, err)
+		listenerLog.Warnf("Can't start TCP forwarder on port %d: %v", l.source.Config.Port, err)
 		l.source.Status.Error(err)
 		return
 	}
@@ -68,7 +67,7 @@ func (l *TCPListener) Start() {
 
 // Stop stops the listener from accepting new connections and all the activer tailers.
 func (l *TCPListener) Stop() {
-	log.Printf("Stopping TCP forwarder on port %d\n", l.source.Config.Port)
+	listenerLog.Infof("Stopping TCP forwarder on port %d", l.source.Config.Port)
 	l.mu.Lock()
 	defer l.mu.Unlock()
 	l.stop <- struct{}{}
@@ -95,11 +94,11 @@ func (l *TCPListener) run() {
 				return
 			case err != nil:
 				// an error occurred, restart the listener.
-				log.Printf("Can't listen on port %d, restarting a listener: %v\n", l.source.Config.Port, err)
+				listenerLog.Warnf("Can't listen on port %d, restarting a listener: %v", l.source.Config.Port, err)
 				l.listener.Close()
 				err := l.startListener()
 				if err != nil {
-					log.Printf("Can't restart listener on port %d: %v\n", l.source.Config.Port, err)
+					listenerLog.Warnf("Can't restart listener on port %d: %v", l.source.Config.Port, err)
 					l.source.Status.Error(err)
 					return
 				}
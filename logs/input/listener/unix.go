@@ -0,0 +1,98 @@
+//go:build !no_logs
+
+package listener
+
+import (
+	"net"
+	"time"
+
+	logsconfig "flashcat.cloud/categraf/config/logs"
+	"flashcat.cloud/categraf/logs/pipeline"
+)
+
+const maxUnixRedialInterval = 30 * time.Second
+
+// A UnixListener connects to a unix socket and reads the lines written by the server,
+// e.g. the af_unix plugin of audispd, it redials when the connection is lost.
+type UnixListener struct {
+	pipelineProvider pipeline.Provider
+	source           *logsconfig.LogSource
+	frameSize        int
+	broken           chan struct{}
+	stop             chan struct{}
+	done             chan struct{}
+}
+
+// NewUnixListener returns an initialized UnixListener
+func NewUnixListener(pipelineProvider pipeline.Provider, source *logsconfig.LogSource, frameSize int) *UnixListener {
+	return &UnixListener{
+		pipelineProvider: pipelineProvider,
+		source:           source,
+		frameSize:        frameSize,
+		broken:           make(chan struct{}, 1),
+		stop:             make(chan struct{}),
+		done:             make(chan struct{}),
+	}
+}
+
+// Start starts reading the socket.
+func (l *UnixListener) Start() {
+	listenerLog.Infof("Starting unix forwarder on %s, with read buffer size: %d", l.source.Config.Path, l.frameSize)
+	go l.run()
+}
+
+// Stop stops reading the socket.
+func (l *UnixListener) Stop() {
+	listenerLog.Infof("Stopping unix forwarder on %s", l.source.Config.Path)
+	close(l.stop)
+	<-l.done
+}
+
+func (l *UnixListener) run() {
+	defer close(l.done)
+
+	interval := time.Second
+	for {
+		conn, err := net.Dial("unix", l.source.Config.Path)
+		if err != nil {
+			listenerLog.Warnf("Can't connect to unix socket %s, retry in %s: %v", l.source.Config.Path, interval, err)
+			l.source.Status.Error(err)
+			select {
+			case <-l.stop:
+				return
+			case <-time.After(interval):
+			}
+			if interval *= 2; interval > maxUnixRedialInterval {
+				interval = maxUnixRedialInterval
+			}
+			continue
+		}
+
+		interval = time.Second
+		l.source.Status.Success()
+		tailer := NewTailer(l.source, conn, l.pipelineProvider.NextPipelineChan(), l.read)
+		tailer.Start()
+
+		select {
+		case <-l.stop:
+			tailer.Stop()
+			return
+		case <-l.broken:
+			tailer.Stop()
+			listenerLog.Infof("Connection to unix socket %s is lost, redialing", l.source.Config.Path)
+		}
+	}
+}
+
+func (l *UnixListener) read(tailer *Tailer) ([]byte, error) {
+	frame := make([]byte, l.frameSize)
+	n, err := tailer.conn.Read(frame)
+	if err != nil {
+		select {
+		case l.broken <- struct{}{}:
+		default:
+		}
+		return nil, err
+	}
+	return frame[:n], nil
+}
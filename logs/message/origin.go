@@ -9,12 +9,14 @@ package message
 
 import (
 	"encoding/json"
-	"log"
 	"strings"
 
 	logsconfig "flashcat.cloud/categraf/config/logs"
+	"flashcat.cloud/categraf/pkg/logger"
 )
 
+var messageLog = logger.New("logs.message")
+
 // Origin represents the Origin of a message
 type Origin struct {
 	Identifier string
@@ -67,18 +69,17 @@ func (o *Origin) TagsToJsonString() string {
 	tagsMap := make(map[string]string)
 	tags := append(o.tags, o.LogSource.Config.Tags...)
 	for _, tag := range tags {
-		pair := strings.FieldsFunc(tag, func(r rune) bool {
-			return r == '=' || r == ':'
-		})
-		if len(pair) == 2 {
-			tagsMap[pair[0]] = pair[1]
+		// values may contain the separators, e.g. user:system:serviceaccount:default:app
+		i := strings.IndexAny(tag, "=:")
+		if i > 0 && i < len(tag)-1 {
+			tagsMap[tag[:i]] = tag[i+1:]
 		}
 	}
 	ret := ""
 	if len(tagsMap) != 0 {
 		data, err := json.Marshal(tagsMap)
 		if err != nil {
-			log.Println("marshal tags error:", err)
+			messageLog.Infof("marshal tags error: %v", err)
 			return ret
 		}
 		ret = string(data)
@@ -115,6 +116,11 @@ func (o *Origin) SetTags(tags []string) {
 	o.tags = tags
 }
 
+// AddTags appends tags to the tags of the origin.
+func (o *Origin) AddTags(tags ...string) {
+	o.tags = append(o.tags, tags...)
+}
+
 // SetSource sets the source of the origin.
 func (o *Origin) SetSource(source string) {
 	o.source = source
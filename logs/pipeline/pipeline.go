@@ -19,8 +19,13 @@ import (
 	"flashcat.cloud/categraf/logs/message"
 	"flashcat.cloud/categraf/logs/processor"
 	"flashcat.cloud/categraf/logs/sender"
+	"flashcat.cloud/categraf/logs/serializers"
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/pkg/spool"
 )
 
+var pipelineLog = logger.New("logs.pipeline")
+
 // Pipeline processes and sends messages to the backend
 type Pipeline struct {
 	InputChan chan *message.Message
@@ -28,52 +33,67 @@ type Pipeline struct {
 	sender    *sender.Sender
 }
 
-// NewPipeline returns a new Pipeline
-func NewPipeline(outputChan chan *message.Message, processingRules []*logsconfig.ProcessingRule, endpoints *logsconfig.Endpoints, destinationsContext *client.DestinationsContext, diagnosticMessageReceiver diagnostic.MessageReceiver, serverless bool) *Pipeline {
+// NewPipeline returns a new Pipeline, the payloads failed to send to the main endpoint are spooled by sp if not nil
+func NewPipeline(outputChan chan *message.Message, processingRules []*logsconfig.ProcessingRule, endpoints *logsconfig.Endpoints, destinationsContext *client.DestinationsContext, diagnosticMessageReceiver diagnostic.MessageReceiver, serverless bool, sp *spool.Spool) *Pipeline {
 	var (
-		destinations *client.Destinations
-		strategy     sender.Strategy
-		encoder      processor.Encoder
+		newDestination func(endpoint logsconfig.Endpoint, contentType string) client.Destination
+		strategy       sender.Strategy
 	)
 	switch endpoints.Type {
 	case "http":
-		main := http.NewDestination(endpoints.Main, http.JSONContentType, destinationsContext, endpoints.BatchMaxConcurrentSend)
-		additionals := []client.Destination{}
-		for _, endpoint := range endpoints.Additionals {
-			additionals = append(additionals, http.NewDestination(endpoint, http.JSONContentType, destinationsContext, endpoints.BatchMaxConcurrentSend))
+		newDestination = func(endpoint logsconfig.Endpoint, contentType string) client.Destination {
+			return http.NewDestination(endpoint, contentType, destinationsContext, endpoints.BatchMaxConcurrentSend)
 		}
-		destinations = client.NewDestinations(main, additionals)
-		strategy = sender.NewBatchStrategy(sender.ArraySerializer, endpoints.BatchWait, endpoints.BatchMaxConcurrentSend, endpoints.BatchMaxSize, endpoints.BatchMaxContentSize, "logs")
-		encoder = processor.JSONEncoder
 	case "kafka":
-		main := kafka.NewDestination(endpoints.Main, http.JSONContentType, destinationsContext, endpoints.BatchMaxConcurrentSend)
-		additionals := []client.Destination{}
-		for _, endpoint := range endpoints.Additionals {
-			additionals = append(additionals, kafka.NewDestination(endpoint, http.JSONContentType, destinationsContext, endpoints.BatchMaxConcurrentSend))
+		newDestination = func(endpoint logsconfig.Endpoint, contentType string) client.Destination {
+			return kafka.NewDestination(endpoint, contentType, destinationsContext, endpoints.BatchMaxConcurrentSend)
 		}
-		destinations = client.NewDestinations(main, additionals)
-		strategy = sender.StreamStrategy
-		encoder = processor.JSONEncoder
 	case "tcp":
-		main := tcp.NewDestination(endpoints.Main, endpoints.UseProto, destinationsContext)
-		additionals := []client.Destination{}
-		for _, endpoint := range endpoints.Additionals {
-			additionals = append(additionals, tcp.NewDestination(endpoint, endpoints.UseProto, destinationsContext))
+		newDestination = func(endpoint logsconfig.Endpoint, contentType string) client.Destination {
+			return tcp.NewDestination(endpoint, endpoints.UseProto, destinationsContext)
 		}
-		destinations = client.NewDestinations(main, additionals)
-		strategy = sender.StreamStrategy
-		encoder = processor.RawEncoder
+	}
+	strategy = sender.StreamStrategy
+	if endpoints.Batched() {
+		strategy = sender.NewBatchStrategy(endpoints.BatchWait, endpoints.BatchMaxConcurrentSend, endpoints.BatchMaxSize, endpoints.BatchMaxContentSize, "logs")
 	}
 
-	senderChan := make(chan *message.Message, logsconfig.ChanSize)
-	sender := sender.NewSender(senderChan, outputChan, destinations, strategy)
-
-	if endpoints.UseProto {
-		encoder = processor.ProtoEncoder
+	// the formats are validated when the endpoints are built
+	destination := func(endpoint logsconfig.Endpoint) sender.Destination {
+		serializer, err := serializers.ForEndpoint(endpoints, endpoint)
+		if err != nil {
+			pipelineLog.Errorf("invalid format of logs endpoint: %v , the default format is used", err)
+			endpoint.Format = ""
+			serializer, _ = serializers.ForEndpoint(endpoints, endpoint)
+		}
+		d := sender.Destination{
+			Destination: newDestination(endpoint, serializer.ContentType()),
+			Serializer:  serializer,
+		}
+		if endpoints.Batched() {
+			// the serialized batches, not only their contents, are limited by max_payload_bytes
+			// and by the max message size of the kafka producer
+			d.MaxPayloadSize = endpoints.BatchMaxContentSize
+			if l, ok := d.Destination.(interface{ MaxPayloadSize() int }); ok && l.MaxPayloadSize() < d.MaxPayloadSize {
+				d.MaxPayloadSize = l.MaxPayloadSize()
+			}
+			// the topics of sources are in the json envelopes
+			format := serializers.FormatOf(endpoints, endpoint)
+			d.SplitByTopic = endpoints.Type == "kafka" && (format == logsconfig.JSONFormat || format == logsconfig.NDJSONFormat)
+		}
+		return d
+	}
+	main := destination(endpoints.Main)
+	additionals := []sender.Destination{}
+	for _, endpoint := range endpoints.Additionals {
+		additionals = append(additionals, destination(endpoint))
 	}
 
+	senderChan := make(chan *message.Message, logsconfig.ChanSize)
+	sender := sender.NewSender(senderChan, outputChan, main, additionals, strategy, sp)
+
 	inputChan := make(chan *message.Message, logsconfig.ChanSize)
-	processor := processor.New(inputChan, senderChan, processingRules, encoder, diagnosticMessageReceiver)
+	processor := processor.New(inputChan, senderChan, processingRules, diagnosticMessageReceiver)
 
 	return &Pipeline{
 		InputChan: inputChan,
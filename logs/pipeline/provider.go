@@ -9,6 +9,7 @@ package pipeline
 
 import (
 	"context"
+	"fmt"
 	"sync/atomic"
 
 	"flashcat.cloud/categraf/logs/diagnostic"
@@ -18,8 +19,12 @@ import (
 	"flashcat.cloud/categraf/logs/client"
 	"flashcat.cloud/categraf/logs/message"
 	"flashcat.cloud/categraf/logs/restart"
+	"flashcat.cloud/categraf/pkg/spool"
 )
 
+// the payloads are spooled as they are sent, in the format of the main endpoint
+const logsSpoolFileSuffix = ".payload"
+
 // Provider provides message channels
 type Provider interface {
 	Start()
@@ -74,12 +79,26 @@ func (p *provider) Start() {
 	p.outputChan = p.auditor.Channel()
 
 	for i := 0; i < p.numberOfPipelines; i++ {
-		pipeline := NewPipeline(p.outputChan, p.processingRules, p.endpoints, p.destinationsContext, p.diagnosticMessageReceiver, p.serverless)
+		pipeline := NewPipeline(p.outputChan, p.processingRules, p.endpoints, p.destinationsContext, p.diagnosticMessageReceiver, p.serverless, p.openSpool(i))
 		pipeline.Start()
 		p.pipelines = append(p.pipelines, pipeline)
 	}
 }
 
+// openSpool opens the spool of the i-th pipeline, which shares spool_max_size_mb with the others,
+// nil if spool_dir is not set or the spool fails to open, then the payloads are retried in memory
+func (p *provider) openSpool(i int) *spool.Spool {
+	if p.endpoints.SpoolDir == "" {
+		return nil
+	}
+	s, err := spool.Open(spool.Dir(p.endpoints.SpoolDir, fmt.Sprintf("logs/%d", i)), logsSpoolFileSuffix, p.endpoints.SpoolMaxSize/int64(p.numberOfPipelines))
+	if err != nil {
+		pipelineLog.Errorf("failed to open the spool of logs: %v", err)
+		return nil
+	}
+	return s
+}
+
 // Stop stops all pipelines in parallel,
 // this call blocks until all pipelines are stopped
 func (p *provider) Stop() {
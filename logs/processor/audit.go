@@ -0,0 +1,185 @@
+//go:build !no_logs
+
+package processor
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	logsconfig "flashcat.cloud/categraf/config/logs"
+)
+
+// auditTags extracts the fields of an audit message into tags
+func auditTags(format string, content []byte) []string {
+	switch format {
+	case logsconfig.K8sAuditFormat:
+		return k8sAuditTags(content)
+	case logsconfig.AuditdFormat:
+		return auditdTags(content)
+	}
+	return nil
+}
+
+// k8sAuditEvent is the part of audit.k8s.io/v1 Event used for tags
+type k8sAuditEvent struct {
+	AuditID string `json:"auditID"`
+	Level   string `json:"level"`
+	Stage   string `json:"stage"`
+	Verb    string `json:"verb"`
+	User    struct {
+		Username string `json:"username"`
+	} `json:"user"`
+	ImpersonatedUser *struct {
+		Username string `json:"username"`
+	} `json:"impersonatedUser"`
+	SourceIPs []string `json:"sourceIPs"`
+	ObjectRef *struct {
+		Resource    string `json:"resource"`
+		Namespace   string `json:"namespace"`
+		Name        string `json:"name"`
+		APIGroup    string `json:"apiGroup"`
+		Subresource string `json:"subresource"`
+	} `json:"objectRef"`
+	ResponseStatus *struct {
+		Code int `json:"code"`
+	} `json:"responseStatus"`
+}
+
+func k8sAuditTags(content []byte) []string {
+	var e k8sAuditEvent
+	if err := json.Unmarshal(content, &e); err != nil || e.Verb == "" {
+		return nil
+	}
+
+	var tags []string
+	add := func(k, v string) {
+		if v != "" {
+			tags = append(tags, k+":"+v)
+		}
+	}
+
+	add("audit_id", e.AuditID)
+	add("level", e.Level)
+	add("stage", e.Stage)
+	add("verb", e.Verb)
+	add("user", e.User.Username)
+	if e.ImpersonatedUser != nil {
+		add("impersonated_user", e.ImpersonatedUser.Username)
+	}
+	if len(e.SourceIPs) > 0 {
+		add("source_ip", e.SourceIPs[0])
+	}
+	if e.ObjectRef != nil {
+		add("resource", e.ObjectRef.Resource)
+		add("namespace", e.ObjectRef.Namespace)
+		add("name", e.ObjectRef.Name)
+		add("api_group", e.ObjectRef.APIGroup)
+		add("subresource", e.ObjectRef.Subresource)
+	}
+	if e.ResponseStatus != nil && e.ResponseStatus.Code != 0 {
+		add("code", strconv.Itoa(e.ResponseStatus.Code))
+	}
+	return tags
+}
+
+// fields of auditd records added to tags
+var auditdFields = []string{"syscall", "success", "exit", "auid", "uid", "euid", "pid", "comm", "exe", "key", "op", "acct", "res", "terminal", "addr", "hostname"}
+
+// fields auditd may encode in hex if they contain spaces or special characters
+var auditdHexFields = map[string]bool{"comm": true, "exe": true, "key": true, "acct": true}
+
+// auditdTags parses records like:
+// type=SYSCALL msg=audit(1364481363.243:24287): arch=c000003e syscall=2 success=no exit=-13 auid=1000 uid=1000 comm="cat" exe="/usr/bin/cat" key="access"
+// type=USER_LOGIN msg=audit(1364475353.159:24270): pid=3280 uid=0 auid=1000 msg='op=login acct="root" exe="/usr/sbin/sshd" hostname=? addr=10.0.0.1 terminal=ssh res=failed'
+// records of the same event share the serial, e.g. 24287
+func auditdTags(content []byte) []string {
+	fields := parseAuditdFields(string(content))
+	typ, has := fields["type"]
+	if !has {
+		return nil
+	}
+
+	tags := []string{"audit_type:" + typ}
+
+	msg := fields["msg"]
+	if strings.HasPrefix(msg, "audit(") {
+		if end := strings.IndexByte(msg, ')'); end > 0 {
+			if i := strings.IndexByte(msg[:end], ':'); i > 0 {
+				tags = append(tags, "audit_serial:"+msg[i+1:end])
+			}
+		}
+	}
+
+	for _, field := range auditdFields {
+		v, has := fields[field]
+		if !has || v == "" || v == "?" || v == "(null)" {
+			continue
+		}
+		tags = append(tags, field+":"+v)
+	}
+	return tags
+}
+
+// parseAuditdFields returns the key value pairs of record,
+// the pairs in msg='...' of user space records are parsed too
+func parseAuditdFields(record string) map[string]string {
+	fields := make(map[string]string)
+	// the enriched fields of log_format=ENRICHED are separated by 0x1d
+	parseAuditdPairs(strings.ReplaceAll(record, "\x1d", " "), fields)
+	return fields
+}
+
+func parseAuditdPairs(s string, fields map[string]string) {
+	for len(s) > 0 {
+		s = strings.TrimLeft(s, " ")
+		eq := strings.IndexByte(s, '=')
+		if eq <= 0 {
+			return
+		}
+		key := s[:eq]
+		if i := strings.LastIndexByte(key, ' '); i >= 0 {
+			key = key[i+1:]
+		}
+		s = s[eq+1:]
+
+		var value string
+		quoted := len(s) > 0 && (s[0] == '"' || s[0] == '\'')
+		if quoted {
+			end := strings.IndexByte(s[1:], s[0])
+			if end < 0 {
+				end = len(s) - 1
+			}
+			value = s[1 : end+1]
+			if end+2 <= len(s) {
+				s = s[end+2:]
+			} else {
+				s = ""
+			}
+		} else {
+			end := strings.IndexByte(s, ' ')
+			if end < 0 {
+				end = len(s)
+			}
+			value = strings.TrimSuffix(s[:end], ":")
+			s = s[end:]
+		}
+
+		if key == "msg" && quoted && strings.Contains(value, "=") {
+			parseAuditdPairs(value, fields)
+			continue
+		}
+
+		if !quoted && auditdHexFields[key] {
+			if bs, err := hex.DecodeString(value); err == nil {
+				value = string(bs)
+			}
+		}
+
+		// the first value wins, e.g. the uid of the record before the uid in msg
+		if _, has := fields[key]; !has {
+			fields[key] = value
+		}
+	}
+}
@@ -0,0 +1,63 @@
+//go:build !no_logs
+
+package processor
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"flashcat.cloud/categraf/logs/message"
+)
+
+// DatadogEncoder is a shared encoder of the payloads of the logs intake of Datadog.
+var DatadogEncoder Encoder = &datadogEncoder{}
+
+// datadogEncoder transforms a message into the JSON accepted by the logs intake of Datadog.
+type datadogEncoder struct{}
+
+// JSON representation of a message in the logs intake of Datadog.
+type datadogPayload struct {
+	Message   string `json:"message"`
+	Status    string `json:"status"`
+	Timestamp int64  `json:"timestamp"`
+	Hostname  string `json:"hostname"`
+	Service   string `json:"service,omitempty"`
+	Source    string `json:"ddsource,omitempty"`
+	Tags      string `json:"ddtags,omitempty"`
+}
+
+// Encode encodes a message into a JSON byte array, the tags are in the form of k:v separated by commas.
+func (d *datadogEncoder) Encode(msg *message.Message, redactedMsg []byte) ([]byte, error) {
+	ts := time.Now().UTC()
+	if !msg.Timestamp.IsZero() {
+		ts = msg.Timestamp
+	}
+	return json.Marshal(datadogPayload{
+		Message:   toValidUtf8(redactedMsg),
+		Status:    msg.GetStatus(),
+		Timestamp: ts.UnixNano() / nanoToMillis,
+		Hostname:  msg.GetHostname(),
+		Service:   msg.Origin.Service(),
+		Source:    msg.Origin.Source(),
+		Tags:      datadogTags(msg.Origin.Tags()),
+	})
+}
+
+// datadogTags joins the tags in the form of k:v, the tags of k=v are converted
+func datadogTags(tags []string) string {
+	var b strings.Builder
+	for _, tag := range tags {
+		if tag == "" {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte(',')
+		}
+		if i := strings.IndexAny(tag, "=:"); i > 0 && tag[i] == '=' {
+			tag = tag[:i] + ":" + tag[i+1:]
+		}
+		b.WriteString(tag)
+	}
+	return b.String()
+}
@@ -9,7 +9,6 @@ package processor
 
 import (
 	"context"
-	"log"
 	"sync"
 
 	logsconfig "flashcat.cloud/categraf/config/logs"
@@ -23,19 +22,17 @@ type Processor struct {
 	inputChan                 chan *message.Message
 	outputChan                chan *message.Message
 	processingRules           []*logsconfig.ProcessingRule
-	encoder                   Encoder
 	done                      chan struct{}
 	diagnosticMessageReceiver diagnostic.MessageReceiver
 	mu                        sync.Mutex
 }
 
 // New returns an initialized Processor.
-func New(inputChan, outputChan chan *message.Message, processingRules []*logsconfig.ProcessingRule, encoder Encoder, diagnosticMessageReceiver diagnostic.MessageReceiver) *Processor {
+func New(inputChan, outputChan chan *message.Message, processingRules []*logsconfig.ProcessingRule, diagnosticMessageReceiver diagnostic.MessageReceiver) *Processor {
 	return &Processor{
 		inputChan:                 inputChan,
 		outputChan:                outputChan,
 		processingRules:           processingRules,
-		encoder:                   encoder,
 		done:                      make(chan struct{}),
 		diagnosticMessageReceiver: diagnosticMessageReceiver,
 	}
@@ -86,15 +83,15 @@ func (p *Processor) run() {
 func (p *Processor) processMessage(msg *message.Message) {
 	if shouldProcess, redactedMsg := p.applyRedactingRules(msg); shouldProcess {
 
+		// the tags are parsed from the redacted content, or the masked values would leak into the tags
+		if format := msg.Origin.LogSource.Config.Format; format != "" {
+			msg.Origin.AddTags(auditTags(format, redactedMsg)...)
+		}
+
 		p.diagnosticMessageReceiver.HandleMessage(*msg, redactedMsg)
 
-		// Encode the message to its final format
-		content, err := p.encoder.Encode(msg, redactedMsg)
-		if err != nil {
-			log.Println("unable to encode msg ", err)
-			return
-		}
-		msg.Content = content
+		// the message is encoded in the formats of the destinations by the sender
+		msg.Content = redactedMsg
 		p.outputChan <- msg
 	}
 }
@@ -9,7 +9,6 @@ package sender
 
 import (
 	"context"
-	"log"
 	"sync"
 	"time"
 
@@ -21,7 +20,6 @@ type batchStrategy struct {
 	buffer *MessageBuffer
 	// pipelineName provides a name for the strategy to differentiate it from other instances in other internal pipelines
 	pipelineName     string
-	serializer       Serializer
 	batchWait        time.Duration
 	climit           chan struct{}  // semaphore for limiting concurrent sends
 	pendingSends     sync.WaitGroup // waitgroup for concurrent sends
@@ -32,13 +30,12 @@ type batchStrategy struct {
 // NewBatchStrategy returns a new batch concurrent strategy with the specified batch & content size limits
 // If `maxConcurrent` > 0, then at most that many payloads will be sent concurrently, else there is no concurrency
 // and the pipeline will block while sending each payload.
-func NewBatchStrategy(serializer Serializer, batchWait time.Duration, maxConcurrent int, maxBatchSize int, maxContentSize int, pipelineName string) Strategy {
+func NewBatchStrategy(batchWait time.Duration, maxConcurrent int, maxBatchSize int, maxContentSize int, pipelineName string) Strategy {
 	if maxConcurrent < 0 {
 		maxConcurrent = 0
 	}
 	return &batchStrategy{
 		buffer:           NewMessageBuffer(maxBatchSize, maxContentSize),
-		serializer:       serializer,
 		batchWait:        batchWait,
 		climit:           make(chan struct{}, maxConcurrent),
 		syncFlushTrigger: make(chan struct{}),
@@ -48,17 +45,23 @@ func NewBatchStrategy(serializer Serializer, batchWait time.Duration, maxConcurr
 
 }
 
+// Flush sends the buffered messages and waits for the payloads to be sent, unless ctx is done first,
+// e.g. the deadline of shutdown
 func (s *batchStrategy) Flush(ctx context.Context) {
 	select {
 	case <-ctx.Done():
 		return
-	default:
-		s.syncFlushTrigger <- struct{}{}
-		<-s.syncFlushDone
+	case s.syncFlushTrigger <- struct{}{}:
+	}
+	select {
+	case <-ctx.Done():
+		// the flush goes on in the background
+		go func() { <-s.syncFlushDone }()
+	case <-s.syncFlushDone:
 	}
 }
 
-func (s *batchStrategy) syncFlush(inputChan chan *message.Message, outputChan chan *message.Message, send func([]byte) error) {
+func (s *batchStrategy) syncFlush(inputChan chan *message.Message, outputChan chan *message.Message, send func([]*message.Message) error) {
 	defer func() {
 		s.flushBuffer(outputChan, send)
 		s.pendingSends.Wait()
@@ -77,7 +80,7 @@ func (s *batchStrategy) syncFlush(inputChan chan *message.Message, outputChan ch
 }
 
 // Send accumulates messages to a buffer and sends them when the buffer is full or outdated.
-func (s *batchStrategy) Send(inputChan chan *message.Message, outputChan chan *message.Message, send func([]byte) error) {
+func (s *batchStrategy) Send(inputChan chan *message.Message, outputChan chan *message.Message, send func([]*message.Message) error) {
 	flushTicker := time.NewTicker(s.batchWait)
 	defer func() {
 		s.flushBuffer(outputChan, send)
@@ -102,7 +105,7 @@ func (s *batchStrategy) Send(inputChan chan *message.Message, outputChan chan *m
 	}
 }
 
-func (s *batchStrategy) processMessage(m *message.Message, outputChan chan *message.Message, send func([]byte) error) {
+func (s *batchStrategy) processMessage(m *message.Message, outputChan chan *message.Message, send func([]*message.Message) error) {
 	if m.Origin != nil {
 		m.Origin.LogSource.LatencyStats.Add(m.GetLatency())
 	}
@@ -114,14 +117,14 @@ func (s *batchStrategy) processMessage(m *message.Message, outputChan chan *mess
 		// it's possible that the m could not be added because the buffer was full
 		// so we need to retry once again
 		if !s.buffer.AddMessage(m) {
-			log.Printf("Dropped message in pipeline=%s reason=too-large ContentLength=%d ContentSizeLimit=%d\n", s.pipelineName, len(m.Content), s.buffer.ContentSizeLimit())
+			senderLog.Infof("Dropped message in pipeline=%s reason=too-large ContentLength=%d ContentSizeLimit=%d", s.pipelineName, len(m.Content), s.buffer.ContentSizeLimit())
 		}
 	}
 }
 
 // flushBuffer sends all the messages that are stored in the buffer and forwards them
 // to the next stage of the pipeline.
-func (s *batchStrategy) flushBuffer(outputChan chan *message.Message, send func([]byte) error) {
+func (s *batchStrategy) flushBuffer(outputChan chan *message.Message, send func([]*message.Message) error) {
 	if s.buffer.IsEmpty() {
 		return
 	}
@@ -141,13 +144,13 @@ func (s *batchStrategy) flushBuffer(outputChan chan *message.Message, send func(
 	}()
 }
 
-func (s *batchStrategy) sendMessages(messages []*message.Message, outputChan chan *message.Message, send func([]byte) error) {
-	err := send(s.serializer.Serialize(messages))
+func (s *batchStrategy) sendMessages(messages []*message.Message, outputChan chan *message.Message, send func([]*message.Message) error) {
+	err := send(messages)
 	if err != nil {
 		if shouldStopSending(err) {
 			return
 		}
-		log.Printf("Could not send payload: %v\n", err)
+		senderLog.Warnf("Could not send payload: %v", err)
 	}
 
 	for _, message := range messages {
@@ -9,41 +9,127 @@ package sender
 
 import (
 	"context"
+	"time"
 
 	"flashcat.cloud/categraf/logs/client"
 	"flashcat.cloud/categraf/logs/message"
+	"flashcat.cloud/categraf/logs/serializers"
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/pkg/spool"
 )
 
+var senderLog = logger.New("logs.sender")
+
 // Strategy should contain all logic to send logs to a remote destination
 // and forward them the next stage of the pipeline.
 type Strategy interface {
-	Send(inputChan chan *message.Message, outputChan chan *message.Message, send func([]byte) error)
+	Send(inputChan chan *message.Message, outputChan chan *message.Message, send func([]*message.Message) error)
 	Flush(ctx context.Context)
 }
 
+// Destination is a destination with the serializer of its payloads.
+type Destination struct {
+	client.Destination
+	Serializer serializers.Serializer
+	// the batches are split until their payloads fit, unlimited if 0
+	MaxPayloadSize int
+	// the batches are split by the topics of log sources, which are in the envelopes of payloads to kafka
+	SplitByTopic bool
+}
+
+// payloads serializes the messages into the payloads of destination, a batch is split in halves
+// while its payload exceeds MaxPayloadSize, the envelopes of messages are larger than their contents
+func (d Destination) payloads(messages []*message.Message) ([][]byte, error) {
+	if !d.SplitByTopic {
+		return d.serialize(messages)
+	}
+	var payloads [][]byte
+	for _, batch := range splitByTopic(messages) {
+		p, err := d.serialize(batch)
+		if err != nil {
+			return nil, err
+		}
+		payloads = append(payloads, p...)
+	}
+	return payloads, nil
+}
+
+func (d Destination) serialize(messages []*message.Message) ([][]byte, error) {
+	payload, err := d.Serializer.Serialize(messages)
+	if err != nil {
+		return nil, err
+	}
+	if d.MaxPayloadSize <= 0 || len(payload) <= d.MaxPayloadSize || len(messages) == 1 {
+		return [][]byte{payload}, nil
+	}
+	half := len(messages) / 2
+	first, err := d.serialize(messages[:half])
+	if err != nil {
+		return nil, err
+	}
+	second, err := d.serialize(messages[half:])
+	if err != nil {
+		return nil, err
+	}
+	return append(first, second...), nil
+}
+
+// splitByTopic groups the messages by the topics of their sources, in the order of the first message of each topic
+func splitByTopic(messages []*message.Message) [][]*message.Message {
+	var (
+		batches [][]*message.Message
+		index   = map[string]int{}
+	)
+	for _, m := range messages {
+		topic := ""
+		if m.Origin != nil && m.Origin.LogSource != nil && m.Origin.LogSource.Config != nil {
+			topic = m.Origin.LogSource.Config.Topic
+		}
+		i, ok := index[topic]
+		if !ok {
+			i = len(batches)
+			index[topic] = i
+			batches = append(batches, nil)
+		}
+		batches[i] = append(batches[i], m)
+	}
+	return batches
+}
+
 // Sender sends logs to different destinations.
 type Sender struct {
-	inputChan    chan *message.Message
-	outputChan   chan *message.Message
-	destinations *client.Destinations
-	strategy     Strategy
-	done         chan struct{}
+	inputChan   chan *message.Message
+	outputChan  chan *message.Message
+	main        Destination
+	additionals []Destination
+	strategy    Strategy
+	done        chan struct{}
+
+	// the payloads failed to send to the main destination are spooled if not nil, and resent by replay
+	spool *spool.Spool
+	stop  chan struct{}
 }
 
 // NewSender returns a new sender.
-func NewSender(inputChan chan *message.Message, outputChan chan *message.Message, destinations *client.Destinations, strategy Strategy) *Sender {
+func NewSender(inputChan chan *message.Message, outputChan chan *message.Message, main Destination, additionals []Destination, strategy Strategy, sp *spool.Spool) *Sender {
 	return &Sender{
-		inputChan:    inputChan,
-		outputChan:   outputChan,
-		destinations: destinations,
-		strategy:     strategy,
-		done:         make(chan struct{}),
+		inputChan:   inputChan,
+		outputChan:  outputChan,
+		main:        main,
+		additionals: additionals,
+		strategy:    strategy,
+		done:        make(chan struct{}),
+		spool:       sp,
+		stop:        make(chan struct{}),
 	}
 }
 
 // Start starts the sender.
 func (s *Sender) Start() {
 	go s.run()
+	if s.spool != nil {
+		go s.replay()
+	}
 }
 
 // Stop stops the sender,
@@ -51,6 +137,7 @@ func (s *Sender) Start() {
 func (s *Sender) Stop() {
 	close(s.inputChan)
 	<-s.done
+	close(s.stop)
 }
 
 // Flush sends synchronously the messages that this sender has to send.
@@ -65,30 +152,99 @@ func (s *Sender) run() {
 	s.strategy.Send(s.inputChan, s.outputChan, s.send)
 }
 
-// send sends a payload to multiple destinations,
-// it will forever retry for the main destination unless the error is not retryable
+// send sends the messages to multiple destinations, serialized in the format of each destination,
+// it will forever retry for the main destination unless the error is not retryable or the payload is spooled,
 // and only try once for additionnal destinations.
-func (s *Sender) send(payload []byte) error {
-	for {
-		err := s.destinations.Main.Send(payload)
-		if err != nil {
-			if _, ok := err.(*client.RetryableError); ok {
-				// could not send the payload because of a client issue,
-				// let's retry
-				continue
+// A batch may be split into several payloads, see Destination.payloads.
+func (s *Sender) send(messages []*message.Message) error {
+	payloads, err := s.main.payloads(messages)
+	if err != nil {
+		return err
+	}
+	var sendErr error
+	for _, payload := range payloads {
+		for {
+			// the payloads are resent in order once the spool is drained
+			if s.spool != nil && s.spool.Len() > 0 {
+				s.put(payload)
+				break
+			}
+			err := s.main.Send(payload)
+			if err != nil {
+				if _, ok := err.(*client.RetryableError); ok {
+					if s.spool != nil {
+						s.put(payload)
+						break
+					}
+					// could not send the payload because of a client issue,
+					// let's retry
+					continue
+				}
+				// the other payloads of a split batch are still sent
+				sendErr = err
 			}
-			return err
+			break
+		}
+	}
+
+	for _, destination := range s.additionals {
+		payloads, err := destination.payloads(messages)
+		if err != nil {
+			senderLog.Warnf("failed to serialize payload of additional destination: %v", err)
+			continue
+		}
+		for _, payload := range payloads {
+			// send in the background so that the agent does not fall behind
+			// for the main destination
+			destination.SendAsync(payload)
 		}
-		break
 	}
 
-	for _, destination := range s.destinations.Additionals {
-		// send in the background so that the agent does not fall behind
-		// for the main destination
-		destination.SendAsync(payload)
+	return sendErr
+}
+
+// put spools the payload, which is dropped if it fails to be spooled, e.g. the disk is full
+func (s *Sender) put(payload []byte) {
+	if err := s.spool.Put(payload, ""); err != nil {
+		senderLog.Errorf("failed to spool payload: %v", err)
 	}
+}
+
+// replay resends the spooled payloads oldest first once the main destination is reachable
+func (s *Sender) replay() {
+	ticker := time.NewTicker(spool.ReplayInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+		}
+
+		for {
+			name, _, payload, err := s.spool.Oldest()
+			if name == "" {
+				break
+			}
+			if err != nil {
+				senderLog.Warnf("drop the broken spooled payload %v: %v", name, err)
+				s.spool.Remove(name)
+				continue
+			}
 
-	return nil
+			err = s.main.Send(payload)
+			if _, ok := err.(*client.RetryableError); ok {
+				break
+			}
+			if shouldStopSending(err) {
+				return
+			}
+			if err != nil {
+				senderLog.Warnf("drop the spooled payload %v rejected: %v", name, err)
+			}
+			s.spool.Remove(name)
+		}
+	}
 }
 
 // shouldStopSending returns true if a component should stop sending logs.
@@ -9,7 +9,6 @@ package sender
 
 import (
 	"context"
-	"log"
 
 	"flashcat.cloud/categraf/logs/message"
 )
@@ -25,18 +24,18 @@ func (s *streamStrategy) Flush(ctx context.Context) {
 }
 
 // Send sends one message at a time and forwards them to the next stage of the pipeline.
-func (s *streamStrategy) Send(inputChan chan *message.Message, outputChan chan *message.Message, send func([]byte) error) {
-	for message := range inputChan {
-		if message.Origin != nil {
-			message.Origin.LogSource.LatencyStats.Add(message.GetLatency())
+func (s *streamStrategy) Send(inputChan chan *message.Message, outputChan chan *message.Message, send func([]*message.Message) error) {
+	for msg := range inputChan {
+		if msg.Origin != nil {
+			msg.Origin.LogSource.LatencyStats.Add(msg.GetLatency())
 		}
-		err := send(message.Content)
+		err := send([]*message.Message{msg})
 		if err != nil {
 			if shouldStopSending(err) {
 				return
 			}
-			log.Printf("Could not send payload: %v\n", err)
+			senderLog.Errorf("could not send payload: %v", err)
 		}
-		outputChan <- message
+		outputChan <- msg
 	}
 }
@@ -0,0 +1,136 @@
+//go:build !no_logs
+
+package serializers
+
+import (
+	"bytes"
+	"fmt"
+
+	logsconfig "flashcat.cloud/categraf/config/logs"
+	"flashcat.cloud/categraf/logs/message"
+	"flashcat.cloud/categraf/logs/processor"
+)
+
+// Serializer turns messages into the payload of a destination.
+type Serializer interface {
+	Serialize(messages []*message.Message) ([]byte, error)
+	ContentType() string
+}
+
+const (
+	textContentType   = "text/plain"
+	jsonContentType   = "application/json"
+	ndjsonContentType = "application/x-ndjson"
+)
+
+// framing joins the encoded messages of a batch
+type framing int
+
+const (
+	// the messages are separated by new lines, a message sent alone is sent as is
+	lineFraming framing = iota
+	// the messages are the elements of a JSON array
+	arrayFraming
+	// every message is followed by a new line
+	ndjsonFraming
+)
+
+type serializer struct {
+	encoder     processor.Encoder
+	framing     framing
+	contentType string
+}
+
+// New returns the serializer of format. The messages are batched by the http destinations by default,
+// and sent one by one by the tcp and kafka destinations unless send_strategy is batch.
+func New(format string, batch bool) (Serializer, error) {
+	var s *serializer
+	switch format {
+	case logsconfig.RawFormat:
+		s = &serializer{encoder: processor.RawEncoder, framing: lineFraming, contentType: textContentType}
+	case logsconfig.JSONFormat:
+		s = &serializer{encoder: processor.JSONEncoder, framing: arrayFraming, contentType: jsonContentType}
+	case logsconfig.NDJSONFormat:
+		s = &serializer{encoder: processor.JSONEncoder, framing: ndjsonFraming, contentType: ndjsonContentType}
+	case logsconfig.DatadogFormat:
+		s = &serializer{encoder: processor.DatadogEncoder, framing: arrayFraming, contentType: jsonContentType}
+	case logsconfig.ProtoFormat:
+		if batch {
+			return nil, fmt.Errorf("format %s can't be sent in batches", format)
+		}
+		s = &serializer{encoder: processor.ProtoEncoder, framing: lineFraming}
+	default:
+		return nil, fmt.Errorf("unknown format %q, must be one of raw, json, ndjson and datadog", format)
+	}
+	if !batch {
+		// the destinations delimit the messages themselves, e.g. by new lines for tcp
+		s.framing = lineFraming
+	}
+	return s, nil
+}
+
+func (s *serializer) ContentType() string {
+	return s.contentType
+}
+
+// Serialize encodes the messages one by one and joins them,
+// for example "{"message":"content1"}", "{"message":"content2"}" are joined into
+// "[{"message":"content1"},{"message":"content2"}]" in arrays,
+// "{"message":"content1"}\n{"message":"content2"}" in lines,
+// and "{"message":"content1"}\n{"message":"content2"}\n" in ndjson
+func (s *serializer) Serialize(messages []*message.Message) ([]byte, error) {
+	if len(messages) == 1 && s.framing == lineFraming {
+		return s.encoder.Encode(messages[0], messages[0].Content)
+	}
+
+	var buffer bytes.Buffer
+	if s.framing == arrayFraming {
+		buffer.WriteByte('[')
+	}
+	n := 0
+	for _, msg := range messages {
+		content, err := s.encoder.Encode(msg, msg.Content)
+		if err != nil {
+			// drop the message only, the others of the batch are still sent
+			continue
+		}
+		if n > 0 {
+			switch s.framing {
+			case arrayFraming:
+				buffer.WriteByte(',')
+			case lineFraming:
+				buffer.WriteByte('\n')
+			}
+		}
+		buffer.Write(content)
+		if s.framing == ndjsonFraming {
+			buffer.WriteByte('\n')
+		}
+		n++
+	}
+	if s.framing == arrayFraming {
+		buffer.WriteByte(']')
+	}
+	return buffer.Bytes(), nil
+}
+
+// ForEndpoint returns the serializer of an endpoint of endpoints in the format of FormatOf.
+func ForEndpoint(endpoints *logsconfig.Endpoints, endpoint logsconfig.Endpoint) (Serializer, error) {
+	return New(FormatOf(endpoints, endpoint), endpoints.Batched())
+}
+
+// FormatOf returns the format of an endpoint of endpoints, the default format of the send type
+// if the format of the endpoint is not set, i.e. json for http and kafka, and raw for tcp.
+func FormatOf(endpoints *logsconfig.Endpoints, endpoint logsconfig.Endpoint) string {
+	if endpoint.Format != "" {
+		return endpoint.Format
+	}
+	switch {
+	case endpoints.UseProto:
+		return logsconfig.ProtoFormat
+	case endpoints.Type == "tcp":
+		return logsconfig.RawFormat
+	default:
+		return logsconfig.JSONFormat
+	}
+}
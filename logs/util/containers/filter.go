@@ -15,8 +15,11 @@ import (
 	"strings"
 
 	coreconfig "flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/pkg/logger"
 )
 
+var containersLog = logger.New("logs.util.containers")
+
 const (
 	// Pause container image names that should be filtered out.
 	// Where appropriate, each constant is loosely structured as
@@ -275,7 +278,7 @@ func (cf Filter) IsExcluded(containerName, containerImage, podNamespace string)
 	for _, r := range cf.ImageExcludeList {
 		match := r.MatchString(containerImage)
 		if coreconfig.Config.DebugMode {
-			log.Printf("D!, exclude item :%+v, container image:%s, %t\n", r, containerImage, match)
+			containersLog.Debugf("exclude item :%+v, container image:%s, %t", r, containerImage, match)
 		}
 		if match {
 			return true
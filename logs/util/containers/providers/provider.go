@@ -8,11 +8,12 @@
 package providers
 
 import (
-	"log"
-
 	"flashcat.cloud/categraf/logs/util/containers"
+	"flashcat.cloud/categraf/pkg/logger"
 )
 
+var providersLog = logger.New("logs.util.containers.providers")
+
 // ContainerImpl without implementation
 // Implementations should call Register() in their init()
 var containerImpl containers.ContainerImplementation
@@ -31,7 +32,7 @@ func Register(impl containers.ContainerImplementation) {
 	if containerImpl == nil {
 		containerImpl = impl
 	} else {
-		log.Printf("Trying to set multiple ContainerImplementation")
+		providersLog.Infof("Trying to set multiple ContainerImplementation")
 	}
 }
 
@@ -12,7 +12,6 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"net"
 	"regexp"
 	"strings"
@@ -63,13 +62,13 @@ func (d *DockerUtil) ListContainers(ctx context.Context, cfg *ContainerListConfi
 			// the inspect should be in the cache already so this is not a problem
 			inspect, err := d.Inspect(ctx, container.ID, false)
 			if err != nil {
-				log.Printf("Error inspecting container %s: %s", container.ID, err)
+				dockerLog.Warnf("Error inspecting container %s: %s", container.ID, err)
 				continue
 			}
 			networkMode, err := GetContainerNetworkMode(ctx, container.ID)
-			log.Printf("container %s network mode: %s", container.Name, networkMode)
+			dockerLog.Infof("container %s network mode: %s", container.Name, networkMode)
 			if err != nil {
-				log.Printf("Failed to get network mode for container %s. Network info will be missing. Error: %s", container.ID, err)
+				dockerLog.Warnf("Failed to get network mode for container %s. Network info will be missing. Error: %s", container.ID, err)
 				continue
 			}
 			// in awsvpc, and host mode, we assume that those ports are listening to all ip addresses
@@ -83,7 +82,7 @@ func (d *DockerUtil) ListContainers(ctx context.Context, cfg *ContainerListConfi
 			if networkMode == containers.HostNetworkMode {
 				ips := GetDockerHostIPs()
 				if len(ips) == 0 {
-					log.Printf("Failed to get host IPs. Container %s will be missing network info: %s", container.Name, err)
+					dockerLog.Warnf("Failed to get host IPs. Container %s will be missing network info: %s", container.Name, err)
 					continue
 				}
 				ipAddr := []containers.NetworkAddress{}
@@ -122,7 +121,7 @@ func (d *DockerUtil) getContainerDetails(ctn *containers.Container) {
 	var err error
 	ctn.StartedAt, err = providers.ContainerImpl().GetContainerStartTime(ctn.ID)
 	if err != nil {
-		log.Printf("ContainerImplementation cannot get StartTime for container %s, err: %s", ctn.ID[:12], err)
+		dockerLog.Infof("ContainerImplementation cannot get StartTime for container %s, err: %s", ctn.ID[:12], err)
 		return
 	}
 }
@@ -151,7 +150,7 @@ func (d *DockerUtil) dockerContainers(ctx context.Context, cfg *ContainerListCon
 				i, err := d.Inspect(ctx, c.ID, false)
 				if err != nil {
 					d.Unlock()
-					log.Printf("Error inspecting container %s: %s", c.ID, err)
+					dockerLog.Warnf("Error inspecting container %s: %s", c.ID, err)
 					continue
 				}
 				d.networkMappings[c.ID] = findDockerNetworks(c.ID, i.State.Pid, c)
@@ -161,7 +160,7 @@ func (d *DockerUtil) dockerContainers(ctx context.Context, cfg *ContainerListCon
 
 		image, err := d.ResolveImageName(ctx, c.Image)
 		if err != nil {
-			log.Printf("Can't resolve image name %s: %s", c.Image, err)
+			dockerLog.Warnf("Can't resolve image name %s: %s", c.Image, err)
 		}
 
 		pauseContainerExcluded := containers.IsPauseContainer(c.Labels)
@@ -228,14 +227,14 @@ func (d *DockerUtil) parseContainerNetworkAddresses(cID string, ports []types.Po
 	addrList := []containers.NetworkAddress{}
 	tempAddrList := []containers.NetworkAddress{}
 	if netSettings == nil || len(netSettings.Networks) == 0 {
-		log.Println("No network settings available from docker")
+		dockerLog.Infof("No network settings available from docker")
 		return addrList
 	}
 	for _, port := range ports {
 		if isExposed(port) {
 			IP := net.ParseIP(port.IP)
 			if IP == nil {
-				log.Println("Unable to parse IP: %v for container: %s", port.IP, container)
+				dockerLog.Warnf("Unable to parse IP: %v for container: %s", port.IP, container)
 				continue
 			}
 			addrList = append(addrList, containers.NetworkAddress{
@@ -253,12 +252,12 @@ func (d *DockerUtil) parseContainerNetworkAddresses(cID string, ports []types.Po
 	// Retieve IPs from network settings for the cached ports
 	for _, network := range netSettings.Networks {
 		if network.IPAddress == "" {
-			log.Println("No IP found for container %s in network %s", container, network.NetworkID)
+			dockerLog.Infof("No IP found for container %s in network %s", container, network.NetworkID)
 			continue
 		}
 		IP := net.ParseIP(network.IPAddress)
 		if IP == nil {
-			log.Println("Unable to parse IP: %v for container: %s", network.IPAddress, container)
+			dockerLog.Warnf("Unable to parse IP: %v for container: %s", network.IPAddress, container)
 			continue
 		}
 		for _, addr := range tempAddrList {
@@ -12,7 +12,6 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"sort"
 	"strings"
 	"sync"
@@ -23,9 +22,12 @@ import (
 
 	"flashcat.cloud/categraf/logs/util/containers/providers"
 	"flashcat.cloud/categraf/pkg/cache"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/pkg/retry"
 )
 
+var dockerLog = logger.New("logs.util.docker")
+
 // DockerUtil wraps interactions with a local docker API.
 type DockerUtil struct {
 	// used to setup the DockerUtil
@@ -89,7 +91,7 @@ func ConnectToDocker(ctx context.Context) (*client.Client, error) {
 		return nil, err
 	}
 
-	log.Println("Successfully connected to Docker server")
+	dockerLog.Infof("Successfully connected to Docker server")
 
 	return cli, nil
 }
@@ -191,7 +193,7 @@ func (d *DockerUtil) ResolveImageName(ctx context.Context, image string) (string
 			sp := strings.SplitN(r.RepoDigests[0], "@", 2)
 			d.imageNameBySha[image] = sp[0]
 		} else {
-			log.Printf("No information in image/inspect to resolve: %s", image)
+			dockerLog.Infof("No information in image/inspect to resolve: %s", image)
 			d.imageNameBySha[image] = image
 		}
 	}
@@ -224,7 +226,7 @@ func (d *DockerUtil) Inspect(ctx context.Context, id string, withSize bool) (typ
 	if hit {
 		container, ok := cached.(types.ContainerJSON)
 		if !ok {
-			log.Println("Invalid inspect cache format, forcing a cache miss")
+			dockerLog.Infof("Invalid inspect cache format, forcing a cache miss")
 		} else {
 			return container, nil
 		}
@@ -12,7 +12,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"strings"
 	"time"
 
@@ -64,11 +63,11 @@ func (d *DockerUtil) processContainerEvent(ctx context.Context, msg events.Messa
 		var err error
 		imageName, err = d.ResolveImageName(ctx, imageName)
 		if err != nil {
-			log.Printf("I! can't resolve image name %s: %s", imageName, err)
+			dockerLog.Infof("can't resolve image name %s: %s", imageName, err)
 		}
 	}
 	if d.cfg.filter.IsExcluded(containerName, imageName, "") {
-		log.Printf("I! events from %s are skipped as the image is excluded for the event collection", containerName)
+		dockerLog.Infof("events from %s are skipped as the image is excluded for the event collection", containerName)
 		return nil, nil
 	}
 
@@ -118,7 +117,7 @@ func (d *DockerUtil) LatestContainerEvents(ctx context.Context, since time.Time)
 		case msg := <-msgChan:
 			event, err := d.processContainerEvent(ctx, msg)
 			if err != nil {
-				log.Println("W! error parsing docker message: %s", err)
+				dockerLog.Warnf("error parsing docker message: %s", err)
 				continue
 			} else if event == nil {
 				continue
@@ -10,7 +10,6 @@ package docker
 import (
 	"context"
 	"io"
-	"log"
 	"strconv"
 	"time"
 
@@ -108,10 +107,10 @@ CONNECT: // Outer loop handles re-connecting in case the docker daemon closes th
 			case err := <-errs:
 				if err == io.EOF {
 					// Silently ignore io.EOF that happens on http connection reset
-					log.Println("D! Got EOF, re-connecting")
+					dockerLog.Debugf("Got EOF, re-connecting")
 				} else {
 					// Else, let's wait 10 seconds and try reconnecting
-					log.Println("W! Got error from docker, waiting for 10 seconds: %s", err)
+					dockerLog.Warnf("Got error from docker, waiting for 10 seconds: %s", err)
 					time.Sleep(10 * time.Second)
 				}
 				cancelFunc()
@@ -120,7 +119,7 @@ CONNECT: // Outer loop handles re-connecting in case the docker daemon closes th
 				latestTimestamp = msg.Time
 				event, err := d.processContainerEvent(ctx, msg)
 				if err != nil {
-					log.Println("D! Skipping event: %s", err)
+					dockerLog.Debugf("Skipping event: %s", err)
 					continue
 				}
 				if event == nil {
@@ -9,7 +9,6 @@ package docker
 
 import (
 	"context"
-	"log"
 	"sync"
 	"time"
 
@@ -38,7 +37,7 @@ func GetDockerUtilWithRetrier() (*DockerUtil, *retry.Retrier) {
 		})
 	}
 	if err := globalDockerUtil.initRetry.TriggerRetry(); err != nil {
-		log.Printf("Docker init error: %s", err)
+		dockerLog.Infof("Docker init error: %s", err)
 		return nil, &globalDockerUtil.initRetry
 	}
 	return globalDockerUtil, nil
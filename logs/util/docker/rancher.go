@@ -8,7 +8,6 @@
 package docker
 
 import (
-	"log"
 	"net"
 )
 
@@ -21,7 +20,7 @@ func FindRancherIPInLabels(labels map[string]string) (string, bool) {
 	if found {
 		ipv4Addr, _, err := net.ParseCIDR(cidr)
 		if err != nil {
-			log.Printf("error while retrieving Rancher IP: %q is not valid", cidr)
+			dockerLog.Warnf("error while retrieving Rancher IP: %q is not valid", cidr)
 			return "", false
 		}
 		return ipv4Addr.String(), true
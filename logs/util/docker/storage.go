@@ -10,7 +10,6 @@ package docker
 import (
 	"errors"
 	"fmt"
-	"log"
 	"math"
 	"regexp"
 	"strconv"
@@ -56,7 +55,7 @@ func (s *StorageStats) GetPercentUsed() float64 {
 	total := s.Total
 	if s.Total != nil && s.Used != nil && s.Free != nil {
 		if *s.Total < *s.Used+*s.Free {
-			log.Println("total lower than free+used, re-computing total")
+			dockerLog.Infof("total lower than free+used, re-computing total")
 			totalValue := *s.Used + *s.Free
 			total = &totalValue
 		}
@@ -89,12 +88,12 @@ func parseStorageStatsFromInfo(info types.Info) ([]*StorageStats, error) {
 		valueString := entry[1]
 		fields := strings.Fields(key)
 		if len(fields) != 3 || strings.ToLower(fields[1]) != "space" {
-			log.Println("ignoring invalid storage stat: %s", key)
+			dockerLog.Infof("ignoring invalid storage stat: %s", key)
 			continue
 		}
 		valueInt, err := parseDiskQuantity(valueString)
 		if err != nil {
-			log.Println("ignoring invalid value %s for stat %s: %s", valueString, key, err)
+			dockerLog.Infof("ignoring invalid value %s for stat %s: %s", valueString, key, err)
 			continue
 		}
 		storageType := strings.ToLower(fields[0])
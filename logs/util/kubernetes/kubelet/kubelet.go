@@ -10,7 +10,6 @@ package kubelet
 import (
 	"context"
 	"fmt"
-	"log"
 	"net/http"
 	"strings"
 	"sync"
@@ -22,9 +21,12 @@ import (
 	"flashcat.cloud/categraf/logs/util/containers/providers"
 	"flashcat.cloud/categraf/pkg/cache"
 	"flashcat.cloud/categraf/pkg/kubernetes"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/pkg/retry"
 )
 
+var kubeletLog = logger.New("logs.util.kubernetes.kubelet")
+
 const (
 	kubeletPodPath         = "/pods"
 	kubeletMetricsPath     = "/metrics"
@@ -127,7 +129,7 @@ func GetKubeUtilWithRetrier() (KubeUtilInterface, *retry.Retrier) {
 	}
 	err := globalKubeUtil.initRetry.TriggerRetry()
 	if err != nil {
-		log.Printf("Kube util init error: %s", err)
+		kubeletLog.Infof("Kube util init error: %s", err)
 		return nil, &globalKubeUtil.initRetry
 	}
 	return globalKubeUtil, nil
@@ -186,7 +188,7 @@ func (ku *KubeUtil) GetLocalPodList(ctx context.Context) ([]*kubernetes.Pod, err
 	if cached, hit := cache.Cache.Get(podListCacheKey); hit {
 		pods, ok = cached.(kubernetes.PodList)
 		if !ok {
-			log.Printf("Invalid pod list cache format, forcing a cache miss")
+			kubeletLog.Infof("Invalid pod list cache format, forcing a cache miss")
 		} else {
 			return pods.Items, nil
 		}
@@ -215,7 +217,7 @@ func (ku *KubeUtil) GetLocalPodList(ctx context.Context) ([]*kubernetes.Pod, err
 			pod.Status.AllContainers = allContainers
 			if !ku.filterPod(pod) {
 				if coreconfig.Config.DebugMode {
-					log.Printf("D! filter include, pod name: %s, pod namespace: %s. pod image:[%v]", pod.Metadata.Name, pod.Metadata.Namespace, pod.Spec.Containers)
+					kubeletLog.Debugf("filter include, pod name: %s, pod namespace: %s. pod image:[%v]", pod.Metadata.Name, pod.Metadata.Namespace, pod.Spec.Containers)
 				}
 				tmpSlice = append(tmpSlice, pod)
 			}
@@ -233,7 +235,7 @@ func (ku *KubeUtil) filterPod(pod *kubernetes.Pod) bool {
 	for _, c := range pod.Status.GetAllContainers() {
 		if ku.filter.IsExcluded(c.Name, c.Image, pod.Metadata.Namespace) {
 			if coreconfig.Config.DebugMode {
-				log.Printf("D! container name:%s image:%s, ns:%s, exclude:true", c.Name, c.Image, pod.Metadata.Namespace)
+				kubeletLog.Debugf("container name:%s image:%s, ns:%s, exclude:true", c.Name, c.Image, pod.Metadata.Namespace)
 			}
 			return true
 		}
@@ -263,7 +265,7 @@ func (ku *KubeUtil) GetPodForContainerID(ctx context.Context, containerID string
 
 	// Retry with cache invalidation
 	if err != nil && errors.IsNotFound(err) {
-		log.Printf("Cannot get container %q: %s, retrying without cache...", containerID, err)
+		kubeletLog.Warnf("Cannot get container %q: %s, retrying without cache...", containerID, err)
 		pods, err = ku.ForceGetLocalPodList(ctx)
 		if err != nil {
 			return nil, err
@@ -277,7 +279,7 @@ func (ku *KubeUtil) GetPodForContainerID(ctx context.Context, containerID string
 	// On some kubelet versions, containers can take up to a second to
 	// register in the podlist, retry a few times before failing
 	if ku.waitOnMissingContainer == 0 {
-		log.Printf("Still cannot get container %q, wait disabled", containerID)
+		kubeletLog.Infof("Still cannot get container %q, wait disabled", containerID)
 		return pod, err
 	}
 	timeout := time.NewTimer(ku.waitOnMissingContainer)
@@ -285,7 +287,7 @@ func (ku *KubeUtil) GetPodForContainerID(ctx context.Context, containerID string
 	retryTicker := time.NewTicker(250 * time.Millisecond)
 	defer retryTicker.Stop()
 	for {
-		log.Printf("Still cannot get container %q: %s, retrying in 250ms", containerID, err)
+		kubeletLog.Infof("Still cannot get container %q: %s, retrying in 250ms", containerID, err)
 		select {
 		case <-retryTicker.C:
 			pods, err = ku.ForceGetLocalPodList(ctx)
@@ -359,7 +361,7 @@ func (ku *KubeUtil) GetPodFromUID(ctx context.Context, podUID string) (*kubernet
 			return pod, nil
 		}
 	}
-	log.Printf("cannot get the pod uid %q: %s, retrying without cache...", podUID, err)
+	kubeletLog.Warnf("cannot get the pod uid %q: %s, retrying without cache...", podUID, err)
 
 	pods, err = ku.ForceGetLocalPodList(ctx)
 	if err != nil {
@@ -9,7 +9,6 @@ package kubelet
 
 import (
 	"context"
-	"log"
 	"net"
 	"time"
 
@@ -31,13 +30,13 @@ func getPotentialKubeletHosts(kubeletHost string) *connectionInfo {
 		configIps, configHostnames := getKubeletHostFromConfig(ctx, kubeletHost)
 		hosts.ips = append(hosts.ips, configIps...)
 		hosts.hostnames = append(hosts.hostnames, configHostnames...)
-		log.Printf("Got potential kubelet connection info from config, ips: %v, hostnames: %v", configIps, configHostnames)
+		kubeletLog.Infof("Got potential kubelet connection info from config, ips: %v, hostnames: %v", configIps, configHostnames)
 	}
 
 	dockerIps, dockerHostnames := getKubeletHostFromDocker(ctx)
 	hosts.ips = append(hosts.ips, dockerIps...)
 	hosts.hostnames = append(hosts.hostnames, dockerHostnames...)
-	log.Printf("Got potential kubelet connection info from docker, ips: %v, hostnames: %v", dockerIps, dockerHostnames)
+	kubeletLog.Infof("Got potential kubelet connection info from docker, ips: %v, hostnames: %v", dockerIps, dockerHostnames)
 
 	dedupeConnectionInfo(&hosts)
 
@@ -48,32 +47,32 @@ func getKubeletHostFromConfig(ctx context.Context, kubeletHost string) ([]string
 	var ips []string
 	var hostnames []string
 	if kubeletHost == "" {
-		log.Printf("kubernetes_kubelet_host is not set")
+		kubeletLog.Infof("kubernetes_kubelet_host is not set")
 		return ips, hostnames
 	}
 
-	log.Printf("Trying to parse kubernetes_kubelet_host: %s", kubeletHost)
+	kubeletLog.Infof("Trying to parse kubernetes_kubelet_host: %s", kubeletHost)
 	kubeletIP := net.ParseIP(kubeletHost)
 	if kubeletIP == nil {
-		log.Printf("Parsing kubernetes_kubelet_host: %s is a hostname, cached, trying to resolve it to ip...", kubeletHost)
+		kubeletLog.Infof("Parsing kubernetes_kubelet_host: %s is a hostname, cached, trying to resolve it to ip...", kubeletHost)
 		hostnames = append(hostnames, kubeletHost)
 		ipAddrs, err := net.DefaultResolver.LookupIPAddr(ctx, kubeletHost)
 		if err != nil {
-			log.Printf("Cannot LookupIP hostname %s: %v", kubeletHost, err)
+			kubeletLog.Warnf("Cannot LookupIP hostname %s: %v", kubeletHost, err)
 		} else {
-			log.Printf("kubernetes_kubelet_host: %s is resolved to: %v", kubeletHost, ipAddrs)
+			kubeletLog.Infof("kubernetes_kubelet_host: %s is resolved to: %v", kubeletHost, ipAddrs)
 			for _, ipAddr := range ipAddrs {
 				ips = append(ips, ipAddr.IP.String())
 			}
 		}
 	} else {
-		log.Printf("Parsed kubernetes_kubelet_host: %s is an address: %v, cached, trying to resolve it to hostname", kubeletHost, kubeletIP)
+		kubeletLog.Infof("Parsed kubernetes_kubelet_host: %s is an address: %v, cached, trying to resolve it to hostname", kubeletHost, kubeletIP)
 		ips = append(ips, kubeletIP.String())
 		addrs, err := net.DefaultResolver.LookupAddr(ctx, kubeletHost)
 		if err != nil {
-			log.Printf("Cannot LookupHost ip %s: %v", kubeletHost, err)
+			kubeletLog.Warnf("Cannot LookupHost ip %s: %v", kubeletHost, err)
 		} else {
-			log.Printf("kubernetes_kubelet_host: %s is resolved to: %v", kubeletHost, addrs)
+			kubeletLog.Infof("kubernetes_kubelet_host: %s is resolved to: %v", kubeletHost, addrs)
 			for _, addr := range addrs {
 				hostnames = append(hostnames, addr)
 			}
@@ -88,17 +87,17 @@ func getKubeletHostFromDocker(ctx context.Context) ([]string, []string) {
 	var hostnames []string
 	dockerHost, err := docker.HostnameProvider(ctx, nil)
 	if err != nil {
-		log.Printf("unable to get hostname from docker, make sure to set the kubernetes_kubelet_host option: %s", err)
+		kubeletLog.Warnf("unable to get hostname from docker, make sure to set the kubernetes_kubelet_host option: %s", err)
 		return ips, hostnames
 	}
 
-	log.Printf("Trying to resolve host name %s provided by docker to ip...", dockerHost)
+	kubeletLog.Infof("Trying to resolve host name %s provided by docker to ip...", dockerHost)
 	hostnames = append(hostnames, dockerHost)
 	ipAddrs, err := net.DefaultResolver.LookupIPAddr(ctx, dockerHost)
 	if err != nil {
-		log.Printf("Cannot resolve host name %s, cached, provided by docker to ip: %s", dockerHost, err)
+		kubeletLog.Warnf("Cannot resolve host name %s, cached, provided by docker to ip: %s", dockerHost, err)
 	} else {
-		log.Printf("Resolved host name %s provided by docker to %v", dockerHost, ipAddrs)
+		kubeletLog.Infof("Resolved host name %s provided by docker to %v", dockerHost, ipAddrs)
 		for _, ipAddr := range ipAddrs {
 			ips = append(ips, ipAddr.IP.String())
 		}
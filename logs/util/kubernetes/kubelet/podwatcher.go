@@ -10,7 +10,6 @@ package kubelet
 import (
 	"context"
 	"hash/fnv"
-	"log"
 	"sort"
 	"strconv"
 	"sync"
@@ -153,7 +152,7 @@ func (w *PodWatcher) computeChanges(podList []*kubernetes.Pod) ([]*kubernetes.Po
 			updatedPods = append(updatedPods, pod)
 		}
 	}
-	log.Printf("Found %d changed pods out of %d", len(updatedPods), len(podList))
+	kubeletLog.Infof("Found %d changed pods out of %d", len(updatedPods), len(podList))
 	return updatedPods, nil
 }
 
@@ -8,10 +8,13 @@
 package tags
 
 import (
-	"log"
 	"strings"
+
+	"flashcat.cloud/categraf/pkg/logger"
 )
 
+var tagsLog = logger.New("logs.util.kubernetes.tags")
+
 // newTagListBuilder returns a tagListBuilder.
 func newTagListBuilder() *tagListBuilder {
 	return &tagListBuilder{
@@ -42,7 +45,7 @@ func (tlb *tagListBuilder) tags() []string {
 // It returns an empty string if one of the arguments is empty.
 func (tlb *tagListBuilder) buildTag(k, v string) string {
 	if k == "" || v == "" {
-		log.Printf("Cannot build tag with empty key or value: key %q - value %q", k, v)
+		tagsLog.Warnf("Cannot build tag with empty key or value: key %q - value %q", k, v)
 		return ""
 	}
 
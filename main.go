@@ -3,8 +3,8 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io"
 	"log"
-	_ "net/http/pprof"
 	"os"
 	"os/signal"
 	"path/filepath"
@@ -13,10 +13,17 @@ import (
 	"gopkg.in/natefinch/lumberjack.v2"
 
 	"flashcat.cloud/categraf/agent"
+	"flashcat.cloud/categraf/alerting"
 	"flashcat.cloud/categraf/api"
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/heartbeat"
+	"flashcat.cloud/categraf/inputs/cronjob"
+	"flashcat.cloud/categraf/leader"
+	"flashcat.cloud/categraf/pkg/logger"
 	"flashcat.cloud/categraf/pkg/osx"
+	"flashcat.cloud/categraf/pkg/pause"
+	"flashcat.cloud/categraf/pkg/state"
+	"flashcat.cloud/categraf/signing"
 	"flashcat.cloud/categraf/writer"
 	"github.com/chai2010/winsvc"
 	"github.com/toolkits/pkg/runner"
@@ -24,17 +31,23 @@ import (
 
 var (
 	appPath      string
+	workDir      string // the working dir before changed to the dir of categraf
 	configDir    = flag.String("configs", osx.GetEnv("CATEGRAF_CONFIGS", "conf"), "Specify configuration directory.(env:CATEGRAF_CONFIGS)")
+	overlays     = flag.String("overlays", osx.GetEnv("CATEGRAF_CONFIG_OVERLAYS", ""), "Config dirs merged onto the configuration directory in order, separated by comma, e.g. conf.d/prod,conf.d/${HOSTNAME}.(env:CATEGRAF_CONFIG_OVERLAYS)")
 	debugMode    = flag.Bool("debug", false, "Is debug mode?")
 	testMode     = flag.Bool("test", false, "Is test mode? print metrics to stdout")
 	interval     = flag.Int64("interval", 0, "Global interval(unit:Second)")
 	showVersion  = flag.Bool("version", false, "Show version.")
 	inputFilters = flag.String("inputs", "", "e.g. cpu:mem:system")
+	cronjobName  = flag.String("cronjob", "", "Run the command after -- as the cron job of the name, and report it to the running categraf")
 )
 
 func init() {
 	// change to current dir
 	var err error
+	if workDir, err = os.Getwd(); err != nil {
+		log.Fatal(err)
+	}
 	if appPath, err = winsvc.GetAppPath(); err != nil {
 		log.Fatal(err)
 	}
@@ -44,45 +57,73 @@ func init() {
 }
 
 func initLog(output string) {
+	var w io.Writer
 	switch {
 	case output == "stdout":
-		log.SetOutput(os.Stdout)
+		w = os.Stdout
 	case output == "stderr":
-		log.SetOutput(os.Stderr)
+		w = os.Stderr
 	case len(output) != 0:
-		log.SetOutput(&lumberjack.Logger{
+		w = &lumberjack.Logger{
 			Filename:   output,
 			MaxSize:    config.Config.Log.MaxSize,
 			MaxAge:     config.Config.Log.MaxAge,
 			MaxBackups: config.Config.Log.MaxBackups,
 			LocalTime:  config.Config.Log.LocalTime,
 			Compress:   config.Config.Log.Compress,
-		})
+		}
 	default:
-		log.SetOutput(os.Stdout)
+		w = os.Stdout
+	}
+
+	level := config.Config.Log.Level
+	if config.Config.DebugMode {
+		level = "debug"
+	}
+
+	opts := logger.Options{Format: config.Config.Log.Format, Level: level, Levels: config.Config.Log.Levels}
+	if err := logger.Init(w, opts); err != nil {
+		log.SetOutput(w)
+		log.Println("E! failed to init logger, the levels and format of log are ignored:", err)
 	}
 }
 
 func main() {
 	flag.Parse()
+	config.Overlays = config.ParseOverlays(*overlays)
 
 	if *showVersion {
 		fmt.Println(config.Version)
 		os.Exit(0)
 	}
 
+	// e.g. categraf config check
+	if ok, code := runCommand(flag.Args()); ok {
+		os.Exit(code)
+	}
+
 	// init configs
 	if err := config.InitConfig(*configDir, *debugMode, *testMode, *interval, *inputFilters); err != nil {
 		log.Fatalln("F! failed to init config:", err)
 	}
 
+	if *cronjobName != "" {
+		os.Exit(cronjob.Wrap(*cronjobName, flag.Args()))
+	}
+
 	doOSsvc()
 	printEnv()
+	initResources()
 
+	initSigning()
 	initWriters()
+	initState()
+	initAlerting()
+	initLeaderElection()
 
 	go api.Start()
 	go heartbeat.Work()
+	go heartbeat.WorkInventory()
 
 	ag, err := agent.NewAgent()
 	if err != nil {
@@ -92,12 +133,37 @@ func main() {
 	runAgent(ag)
 }
 
+func initSigning() {
+	if err := signing.Init(); err != nil {
+		log.Fatalln("F! failed to init signing:", err)
+	}
+}
+
 func initWriters() {
 	if err := writer.InitWriters(); err != nil {
 		log.Fatalln("F! failed to init writer:", err)
 	}
 }
 
+func initAlerting() {
+	if err := alerting.Init(); err != nil {
+		log.Fatalln("F! failed to init alerting:", err)
+	}
+}
+
+func initLeaderElection() {
+	if err := leader.Init(); err != nil {
+		log.Fatalln("F! failed to init leader election:", err)
+	}
+}
+
+func initState() {
+	if err := state.Init(config.Config.Global.StateDir); err != nil {
+		log.Println("W! failed to init state store, state of inputs will not be persisted:", err)
+	}
+	pause.Init(config.Config.Global.PauseFile)
+}
+
 func handleSignal(ag *agent.Agent) {
 
 	sc := make(chan os.Signal, 1)
@@ -120,6 +186,7 @@ EXIT:
 	}
 
 	ag.Stop()
+	leader.Stop()
 	log.Println("I! exited")
 }
 
@@ -1,7 +1,9 @@
 package falcon
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 	"strings"
 
 	"flashcat.cloud/categraf/types"
@@ -40,6 +42,11 @@ func NewParser() *Parser {
 func (p *Parser) Parse(input []byte, slist *types.SampleList) error {
 	var samples []Sample
 
+	input = bytes.TrimSpace(input)
+	if len(input) == 0 {
+		return fmt.Errorf("empty input")
+	}
+
 	if input[0] == '[' {
 		err := json.Unmarshal(input, &samples)
 		if err != nil {
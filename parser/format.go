@@ -0,0 +1,35 @@
+package parser
+
+import (
+	"fmt"
+
+	"flashcat.cloud/categraf/parser/falcon"
+	"flashcat.cloud/categraf/parser/influx"
+	"flashcat.cloud/categraf/parser/json"
+	"flashcat.cloud/categraf/parser/prometheus"
+)
+
+// Format is the data_format of the inputs parsing the messages or the outputs of commands, e.g. kafka_consumer and ssh
+type Format struct {
+	// json | influx | prometheus | falcon
+	DataFormat     string   `toml:"data_format"`
+	JSONNameKey    string   `toml:"json_name_key"`
+	JSONTagKeys    []string `toml:"json_tag_keys"`
+	JSONTimeKey    string   `toml:"json_time_key"`
+	JSONTimeFormat string   `toml:"json_time_format"`
+}
+
+// NewParser returns the parser of data_format, json by default
+func (f *Format) NewParser() (Parser, error) {
+	switch f.DataFormat {
+	case "", "json":
+		return json.NewParser(f.JSONNameKey, f.JSONTagKeys, f.JSONTimeKey, f.JSONTimeFormat), nil
+	case "influx":
+		return influx.NewParser(), nil
+	case "falcon":
+		return falcon.NewParser(), nil
+	case "prom", "prometheus":
+		return prometheus.EmptyParser(), nil
+	}
+	return nil, fmt.Errorf("data_format(%s) not supported", f.DataFormat)
+}
@@ -0,0 +1,188 @@
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"flashcat.cloud/categraf/types"
+)
+
+// Parser flattens the numbers and booleans of a json object, or an array of objects, into samples,
+// e.g. {"host": "a", "cpu": {"user": 1.2}} is cpu_user{host="a"} 1.2 if host is in TagKeys
+type Parser struct {
+	// the string field as the prefix of metric names, e.g. "measurement"
+	NameKey string
+	// the string fields as labels, nested fields are joined by _, e.g. meta_region
+	TagKeys []string
+	// the field of timestamp, the samples are timestamped at the time of parsing if empty
+	TimeKey string
+	// unix | unix_ms | unix_us | unix_ns, or the go layout of time, e.g. 2006-01-02T15:04:05Z07:00
+	TimeFormat string
+}
+
+func NewParser(nameKey string, tagKeys []string, timeKey, timeFormat string) *Parser {
+	if timeFormat == "" {
+		timeFormat = time.RFC3339
+	}
+	return &Parser{
+		NameKey:    nameKey,
+		TagKeys:    tagKeys,
+		TimeKey:    timeKey,
+		TimeFormat: timeFormat,
+	}
+}
+
+func (p *Parser) Parse(input []byte, slist *types.SampleList) error {
+	input = bytes.TrimSpace(input)
+	if len(input) == 0 {
+		return nil
+	}
+
+	var objects []map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(input))
+	decoder.UseNumber()
+
+	if input[0] == '[' {
+		if err := decoder.Decode(&objects); err != nil {
+			return err
+		}
+	} else {
+		var object map[string]interface{}
+		if err := decoder.Decode(&object); err != nil {
+			return err
+		}
+		objects = append(objects, object)
+	}
+
+	for _, object := range objects {
+		if err := p.parseObject(object, slist); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Parser) parseObject(object map[string]interface{}, slist *types.SampleList) error {
+	fields := make(map[string]interface{})
+	flatten("", object, fields)
+
+	labels := make(map[string]string)
+	for _, k := range p.TagKeys {
+		if v, has := fields[k]; has {
+			labels[k] = fmt.Sprint(v)
+			delete(fields, k)
+		}
+	}
+
+	prefix := ""
+	if p.NameKey != "" {
+		if v, has := fields[p.NameKey]; has {
+			prefix = fmt.Sprint(v)
+			delete(fields, p.NameKey)
+		}
+	}
+
+	var ts time.Time
+	if p.TimeKey != "" {
+		v, has := fields[p.TimeKey]
+		if !has {
+			return fmt.Errorf("time key %s not found", p.TimeKey)
+		}
+		delete(fields, p.TimeKey)
+
+		var err error
+		if ts, err = p.parseTime(v); err != nil {
+			return err
+		}
+	}
+
+	for k, v := range fields {
+		var value interface{}
+		switch x := v.(type) {
+		case json.Number:
+			f, err := x.Float64()
+			if err != nil {
+				continue
+			}
+			value = f
+		case bool:
+			value = x
+		default:
+			// the strings not in tag keys are ignored
+			continue
+		}
+
+		s := slist.PushSample(prefix, k, value, labels)
+		if !ts.IsZero() {
+			s.SetTime(ts)
+		}
+	}
+	return nil
+}
+
+func (p *Parser) parseTime(v interface{}) (time.Time, error) {
+	s := fmt.Sprint(v)
+	switch p.TimeFormat {
+	case "unix", "unix_ms", "unix_us", "unix_ns":
+		// the integers of nanoseconds lose precision in float64
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			switch p.TimeFormat {
+			case "unix":
+				return time.Unix(n, 0), nil
+			case "unix_ms":
+				return time.UnixMilli(n), nil
+			case "unix_us":
+				return time.UnixMicro(n), nil
+			}
+			return time.Unix(0, n), nil
+		}
+
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid %s time %s: %v", p.TimeFormat, s, err)
+		}
+		switch p.TimeFormat {
+		case "unix":
+			return time.Unix(0, int64(f*1e9)), nil
+		case "unix_ms":
+			return time.Unix(0, int64(f*1e6)), nil
+		case "unix_us":
+			return time.Unix(0, int64(f*1e3)), nil
+		}
+		return time.Unix(0, int64(f)), nil
+	}
+
+	t, err := time.Parse(p.TimeFormat, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %s of format %s: %v", s, p.TimeFormat, err)
+	}
+	return t, nil
+}
+
+// flatten joins the keys of nested objects and the indexes of arrays by _
+func flatten(prefix string, v interface{}, fields map[string]interface{}) {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		for k, item := range x {
+			flatten(joinKey(prefix, k), item, fields)
+		}
+	case []interface{}:
+		for i, item := range x {
+			flatten(joinKey(prefix, strconv.Itoa(i)), item, fields)
+		}
+	case nil:
+	default:
+		fields[prefix] = x
+	}
+}
+
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "_" + strings.TrimSpace(key)
+}
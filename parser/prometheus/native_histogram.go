@@ -0,0 +1,177 @@
+package prometheus
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"flashcat.cloud/categraf/pkg/prom"
+	"flashcat.cloud/categraf/types"
+	dto "github.com/prometheus/client_model/go"
+)
+
+const (
+	// NativeHistogramClassic expands the native histograms into classic buckets
+	NativeHistogramClassic = "classic"
+	// NativeHistogramSparse forwards the populated native buckets as they are,
+	// each one a sample of <name>_native_bucket with the labels lower and upper
+	NativeHistogramSparse = "sparse"
+)
+
+// NativeHistogramOption tells how the native histograms, which are in the protobuf format only, are converted
+type NativeHistogramOption struct {
+	// classic or sparse, classic by default
+	NativeHistogramMode string `toml:"native_histogram_mode"`
+	// the le of the classic buckets, the upper bounds of the populated native buckets by default
+	NativeHistogramBuckets []float64 `toml:"native_histogram_buckets"`
+}
+
+func (o *NativeHistogramOption) Validate() error {
+	switch o.NativeHistogramMode {
+	case "", NativeHistogramClassic, NativeHistogramSparse:
+	default:
+		return fmt.Errorf("unknown native_histogram_mode %q, must be classic or sparse", o.NativeHistogramMode)
+	}
+	sort.Float64s(o.NativeHistogramBuckets)
+	return nil
+}
+
+// nativeBucket is a populated bucket of a native histogram, the observations are in (lower, upper]
+type nativeBucket struct {
+	lower, upper float64
+	count        float64
+}
+
+func isNativeHistogram(h *dto.Histogram) bool {
+	if len(h.GetBucket()) > 0 {
+		// the histograms exposed in both ways are read by their classic buckets
+		return false
+	}
+	return h.Schema != nil || h.ZeroThreshold != nil || len(h.GetPositiveSpan())+len(h.GetNegativeSpan()) > 0
+}
+
+func (p *Parser) handleNativeHistogram(m *dto.Metric, tags map[string]string, metricName string, slist *types.SampleList) {
+	namePrefix := ""
+	if !strings.HasPrefix(metricName, p.NamePrefix) {
+		namePrefix = p.NamePrefix
+	}
+
+	h := m.GetHistogram()
+	count := float64(h.GetSampleCount())
+	if h.SampleCountFloat != nil {
+		count = h.GetSampleCountFloat()
+	}
+	buckets := nativeBuckets(h)
+
+	samples := make([]*types.Sample, 0, len(buckets)+3)
+	samples = append(samples, types.NewSample("", prom.BuildMetric(namePrefix, metricName, "count"), count, tags))
+	samples = append(samples, types.NewSample("", prom.BuildMetric(namePrefix, metricName, "sum"), h.GetSampleSum(), tags))
+
+	if p.NativeHistogram.NativeHistogramMode == NativeHistogramSparse {
+		for _, b := range buckets {
+			samples = append(samples, types.NewSample("", prom.BuildMetric(namePrefix, metricName, "native_bucket"), b.count, tags,
+				map[string]string{"lower": fmt.Sprint(b.lower), "upper": fmt.Sprint(b.upper)}))
+		}
+		p.setTime(m, samples)
+		slist.PushFrontN(samples)
+		return
+	}
+
+	bounds := p.NativeHistogram.NativeHistogramBuckets
+	if len(bounds) == 0 {
+		bounds = make([]float64, 0, len(buckets))
+		for _, b := range buckets {
+			bounds = append(bounds, b.upper)
+		}
+	}
+
+	// a native bucket across a classic boundary is counted in the next classic bucket
+	var cumulative float64
+	i := 0
+	for _, le := range bounds {
+		for ; i < len(buckets) && buckets[i].upper <= le; i++ {
+			cumulative += buckets[i].count
+		}
+		samples = append(samples, types.NewSample("", prom.BuildMetric(namePrefix, metricName, "bucket"), cumulative, tags, map[string]string{"le": fmt.Sprint(le)}))
+	}
+	samples = append(samples, types.NewSample("", prom.BuildMetric(namePrefix, metricName, "bucket"), count, tags, map[string]string{"le": "+Inf"}))
+	p.setTime(m, samples)
+	slist.PushFrontN(samples)
+}
+
+// nativeBuckets returns the populated buckets in the order of their bounds,
+// i.e. the negative buckets, the zero bucket and then the positive buckets
+func nativeBuckets(h *dto.Histogram) []nativeBucket {
+	schema := h.GetSchema()
+	negatives := spanCounts(h.GetNegativeSpan(), h.GetNegativeDelta(), h.GetNegativeCount())
+	positives := spanCounts(h.GetPositiveSpan(), h.GetPositiveDelta(), h.GetPositiveCount())
+
+	buckets := make([]nativeBucket, 0, len(negatives)+len(positives)+1)
+	for i := len(negatives) - 1; i >= 0; i-- {
+		if negatives[i].count == 0 {
+			continue
+		}
+		index := negatives[i].index
+		buckets = append(buckets, nativeBucket{lower: -upperBound(schema, index), upper: -upperBound(schema, index-1), count: negatives[i].count})
+	}
+
+	zeroCount := float64(h.GetZeroCount())
+	if h.ZeroCountFloat != nil {
+		zeroCount = h.GetZeroCountFloat()
+	}
+	if zeroCount > 0 {
+		buckets = append(buckets, nativeBucket{lower: -h.GetZeroThreshold(), upper: h.GetZeroThreshold(), count: zeroCount})
+	}
+
+	for _, c := range positives {
+		if c.count == 0 {
+			continue
+		}
+		buckets = append(buckets, nativeBucket{lower: upperBound(schema, c.index-1), upper: upperBound(schema, c.index), count: c.count})
+	}
+	return buckets
+}
+
+type indexCount struct {
+	index int32
+	count float64
+}
+
+// spanCounts decodes the counts of the buckets in spans, which are deltas of the previous buckets for integer
+// histograms, and absolute counts for float histograms
+func spanCounts(spans []*dto.BucketSpan, deltas []int64, counts []float64) []indexCount {
+	var (
+		ret   []indexCount
+		index int32
+		j     int
+		last  int64
+	)
+	for _, span := range spans {
+		// the offset of the first span is the index of its first bucket,
+		// the others are the gaps to the previous spans
+		index += span.GetOffset()
+		for k := uint32(0); k < span.GetLength(); k++ {
+			var count float64
+			switch {
+			case j < len(deltas):
+				last += deltas[j]
+				count = float64(last)
+			case j < len(counts):
+				count = counts[j]
+			}
+			ret = append(ret, indexCount{index: index, count: count})
+			index++
+			j++
+		}
+	}
+	return ret
+}
+
+// upperBound returns the upper bound of the bucket of index, the bounds of schema are the powers of 2^(2^-schema)
+func upperBound(schema, index int32) float64 {
+	if schema < 0 {
+		return math.Ldexp(1, int(index)<<-schema)
+	}
+	return math.Exp2(float64(index) / float64(int32(1)<<schema))
+}
@@ -0,0 +1,166 @@
+package prometheus
+
+import (
+	"math"
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"flashcat.cloud/categraf/types"
+)
+
+// the bounds are of the bucket iterators of prometheus, e.g. getBound of model/histogram
+func TestUpperBound(t *testing.T) {
+	for _, tc := range []struct {
+		schema, index int32
+		bound         float64
+	}{
+		{schema: 0, index: 0, bound: 1},
+		{schema: 0, index: 1, bound: 2},
+		{schema: 0, index: 4, bound: 16},
+		{schema: 0, index: -1, bound: 0.5},
+		{schema: 3, index: 1, bound: 1.0905077326652577},
+		{schema: 3, index: 8, bound: 2},
+		{schema: 3, index: -8, bound: 0.5},
+		{schema: 8, index: 256, bound: 2},
+		{schema: -1, index: 1, bound: 4},
+		{schema: -1, index: -1, bound: 0.25},
+		{schema: -2, index: 1, bound: 16},
+		{schema: -4, index: 1, bound: 65536},
+	} {
+		assert.InDelta(t, tc.bound, upperBound(tc.schema, tc.index), 1e-12, "schema %d index %d", tc.schema, tc.index)
+	}
+}
+
+// the histogram of the tests of the cumulative buckets of prometheus, with the negative buckets mirrored
+func testHistogram() *dto.Histogram {
+	return &dto.Histogram{
+		SampleCount:   proto.Uint64(11),
+		SampleSum:     proto.Float64(42),
+		Schema:        proto.Int32(0),
+		ZeroThreshold: proto.Float64(0.001),
+		ZeroCount:     proto.Uint64(2),
+		PositiveSpan: []*dto.BucketSpan{
+			{Offset: proto.Int32(0), Length: proto.Uint32(2)},
+			{Offset: proto.Int32(1), Length: proto.Uint32(2)},
+		},
+		PositiveDelta: []int64{1, 1, -1, 0},
+		NegativeSpan: []*dto.BucketSpan{
+			{Offset: proto.Int32(0), Length: proto.Uint32(2)},
+		},
+		NegativeDelta: []int64{1, 2},
+	}
+}
+
+var testBuckets = []nativeBucket{
+	{lower: -2, upper: -1, count: 3},
+	{lower: -1, upper: -0.5, count: 1},
+	{lower: -0.001, upper: 0.001, count: 2},
+	{lower: 0.5, upper: 1, count: 1},
+	{lower: 1, upper: 2, count: 2},
+	{lower: 4, upper: 8, count: 1},
+	{lower: 8, upper: 16, count: 1},
+}
+
+func TestNativeBuckets(t *testing.T) {
+	assert.Equal(t, testBuckets, nativeBuckets(testHistogram()))
+
+	// the counts of float histograms are absolute
+	h := &dto.Histogram{
+		SampleCountFloat: proto.Float64(11),
+		Schema:           proto.Int32(0),
+		ZeroThreshold:    proto.Float64(0.001),
+		ZeroCountFloat:   proto.Float64(2),
+		PositiveSpan: []*dto.BucketSpan{
+			{Offset: proto.Int32(0), Length: proto.Uint32(2)},
+			{Offset: proto.Int32(1), Length: proto.Uint32(2)},
+		},
+		PositiveCount: []float64{1, 2, 1, 1},
+		NegativeSpan: []*dto.BucketSpan{
+			{Offset: proto.Int32(0), Length: proto.Uint32(2)},
+		},
+		NegativeCount: []float64{1, 3},
+	}
+	assert.Equal(t, testBuckets, nativeBuckets(h))
+
+	// the empty buckets in spans are skipped
+	h = &dto.Histogram{
+		Schema: proto.Int32(1),
+		PositiveSpan: []*dto.BucketSpan{
+			{Offset: proto.Int32(-1), Length: proto.Uint32(3)},
+		},
+		PositiveDelta: []int64{2, -2, 1},
+	}
+	buckets := nativeBuckets(h)
+	require.Len(t, buckets, 2)
+	for i, want := range []nativeBucket{
+		{lower: 0.5, upper: math.Sqrt(0.5), count: 2},
+		{lower: 1, upper: math.Sqrt2, count: 1},
+	} {
+		assert.InDelta(t, want.lower, buckets[i].lower, 1e-12)
+		assert.InDelta(t, want.upper, buckets[i].upper, 1e-12)
+		assert.Equal(t, want.count, buckets[i].count)
+	}
+}
+
+func TestHandleNativeHistogram(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		option NativeHistogramOption
+		// the values of the samples by the labels of buckets
+		buckets map[string]float64
+	}{
+		{
+			name: "classic of the upper bounds of native buckets",
+			buckets: map[string]float64{
+				"le=-1": 3, "le=-0.5": 4, "le=0.001": 6, "le=1": 7, "le=2": 9, "le=8": 10, "le=16": 11, "le=+Inf": 11,
+			},
+		},
+		{
+			name:   "classic of native_histogram_buckets",
+			option: NativeHistogramOption{NativeHistogramBuckets: []float64{1, 5, 10}},
+			// (4, 8] across 5 is counted in le=10, and (8, 16] across 10 in +Inf
+			buckets: map[string]float64{"le=1": 7, "le=5": 9, "le=10": 10, "le=+Inf": 11},
+		},
+		{
+			name:   "sparse",
+			option: NativeHistogramOption{NativeHistogramMode: NativeHistogramSparse},
+			buckets: map[string]float64{
+				"lower=-2,upper=-1": 3, "lower=-1,upper=-0.5": 1, "lower=-0.001,upper=0.001": 2,
+				"lower=0.5,upper=1": 1, "lower=1,upper=2": 2, "lower=4,upper=8": 1, "lower=8,upper=16": 1,
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.NoError(t, tc.option.Validate())
+			p := EmptyParser()
+			p.NativeHistogram = tc.option
+
+			slist := types.NewSampleList()
+			m := &dto.Metric{Histogram: testHistogram()}
+			require.True(t, isNativeHistogram(m.GetHistogram()))
+			p.handleNativeHistogram(m, map[string]string{"job": "api"}, "latency", slist)
+
+			buckets := map[string]float64{}
+			for _, s := range slist.PopBackAll() {
+				assert.Equal(t, "api", s.Labels["job"])
+				switch s.Metric {
+				case "latency_count":
+					assert.EqualValues(t, 11, s.Value)
+				case "latency_sum":
+					assert.EqualValues(t, 42, s.Value)
+				case "latency_bucket":
+					buckets["le="+s.Labels["le"]] = s.Value.(float64)
+				case "latency_native_bucket":
+					buckets["lower="+s.Labels["lower"]+",upper="+s.Labels["upper"]] = s.Value.(float64)
+				default:
+					t.Errorf("unexpected sample %s", s.Metric)
+				}
+			}
+			assert.Equal(t, tc.buckets, buckets)
+		})
+	}
+}
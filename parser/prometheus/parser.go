@@ -5,12 +5,16 @@ import (
 	"math"
 	"net/http"
 	"strings"
+	"time"
 
 	"flashcat.cloud/categraf/pkg/filter"
 	util "flashcat.cloud/categraf/pkg/metrics"
 	"flashcat.cloud/categraf/pkg/prom"
+	"flashcat.cloud/categraf/pkg/relabel"
 	"flashcat.cloud/categraf/types"
 	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/value"
 )
 
 type Parser struct {
@@ -19,6 +23,30 @@ type Parser struct {
 	Header                http.Header
 	IgnoreMetricsFilter   filter.Filter
 	IgnoreLabelKeysFilter filter.Filter
+	// how the native histograms are converted, into classic buckets of their own bounds by default
+	NativeHistogram NativeHistogramOption
+	// the samples are of the timestamps of the exposition if true, otherwise of the time gathered
+	HonorTimestamps bool
+	// drop or forward the stale markers of the exposition, dropped by default like the other NaN values
+	StaleMarkers string
+	// attach the exemplars of the counters and the buckets, which are in the protobuf format only, to their samples
+	Exemplars bool
+	// relabel the samples parsed, like metric_relabel_configs of prometheus, the rules must be validated
+	MetricRelabelConfigs []*relabel.Config
+}
+
+const (
+	StaleMarkersDrop    = "drop"
+	StaleMarkersForward = "forward"
+)
+
+// ValidateStaleMarkers checks the handling of stale markers
+func ValidateStaleMarkers(s string) error {
+	switch s {
+	case "", StaleMarkersDrop, StaleMarkersForward:
+		return nil
+	}
+	return fmt.Errorf("unknown stale_markers %q, must be drop or forward", s)
 }
 
 func NewParser(namePrefix string, defaultTags map[string]string, header http.Header, ignoreMetricsFilter, ignoreLabelKeysFilter filter.Filter) *Parser {
@@ -40,6 +68,12 @@ func (p *Parser) Parse(buf []byte, slist *types.SampleList) error {
 	if err != nil {
 		return err
 	}
+	// the samples are relabeled after all parsed, slist may have samples of others
+	out := slist
+	if len(p.MetricRelabelConfigs) > 0 {
+		slist = types.NewSampleList()
+	}
+
 	// read metrics
 	for metricName, mf := range metricFamilies {
 		if p.IgnoreMetricsFilter != nil && p.IgnoreMetricsFilter.Match(metricName) {
@@ -51,6 +85,8 @@ func (p *Parser) Parse(buf []byte, slist *types.SampleList) error {
 
 			if mf.GetType() == dto.MetricType_SUMMARY {
 				p.HandleSummary(m, tags, metricName, slist)
+			} else if mf.GetType() == dto.MetricType_HISTOGRAM && isNativeHistogram(m.GetHistogram()) {
+				p.handleNativeHistogram(m, tags, metricName, slist)
 			} else if mf.GetType() == dto.MetricType_HISTOGRAM {
 				p.HandleHistogram(m, tags, metricName, slist)
 			} else {
@@ -59,9 +95,30 @@ func (p *Parser) Parse(buf []byte, slist *types.SampleList) error {
 		}
 	}
 
+	if out != slist {
+		out.PushFrontN(p.relabel(slist.PopBackAll()))
+	}
 	return nil
 }
 
+// relabel applies MetricRelabelConfigs to samples, the samples dropped or left without name are removed
+func (p *Parser) relabel(samples []*types.Sample) []*types.Sample {
+	ret := samples[:0]
+	for _, s := range samples {
+		s.Labels[model.MetricNameLabel] = s.Metric
+		if !relabel.Process(s.Labels, p.MetricRelabelConfigs) {
+			continue
+		}
+		s.Metric = s.Labels[model.MetricNameLabel]
+		delete(s.Labels, model.MetricNameLabel)
+		if s.Metric == "" {
+			continue
+		}
+		ret = append(ret, s)
+	}
+	return ret
+}
+
 func (p *Parser) HandleSummary(m *dto.Metric, tags map[string]string, metricName string, slist *types.SampleList) {
 	namePrefix := ""
 	if !strings.HasPrefix(metricName, p.NamePrefix) {
@@ -75,6 +132,7 @@ func (p *Parser) HandleSummary(m *dto.Metric, tags map[string]string, metricName
 	for _, q := range m.GetSummary().Quantile {
 		samples = append(samples, types.NewSample("", prom.BuildMetric(namePrefix, metricName, "quantile"), q.GetValue(), tags, map[string]string{"quantile": fmt.Sprint(q.GetQuantile())}))
 	}
+	p.setTime(m, samples)
 	slist.PushFrontN(samples)
 }
 
@@ -92,22 +150,58 @@ func (p *Parser) HandleHistogram(m *dto.Metric, tags map[string]string, metricNa
 	for _, b := range m.GetHistogram().Bucket {
 		le := fmt.Sprint(b.GetUpperBound())
 		value := float64(b.GetCumulativeCount())
-		samples = append(samples, types.NewSample("", prom.BuildMetric(namePrefix, metricName, "bucket"), value, tags, map[string]string{"le": le}))
+		s := types.NewSample("", prom.BuildMetric(namePrefix, metricName, "bucket"), value, tags, map[string]string{"le": le})
+		s.Exemplar = p.exemplar(b.GetExemplar())
+		samples = append(samples, s)
 	}
+	p.setTime(m, samples)
 	slist.PushFrontN(samples)
 }
 
 func (p *Parser) handleGaugeCounter(m *dto.Metric, tags map[string]string, metricName string, slist *types.SampleList) {
-	fields := getNameAndValue(m, metricName)
+	fields := getNameAndValue(m, metricName, p.StaleMarkers == StaleMarkersForward)
 	for metric, value := range fields {
+		var s *types.Sample
 		if !strings.HasPrefix(metric, p.NamePrefix) {
-			slist.PushFront(types.NewSample("", prom.BuildMetric(p.NamePrefix, metric, ""), value, tags))
+			s = types.NewSample("", prom.BuildMetric(p.NamePrefix, metric, ""), value, tags)
 		} else {
-			slist.PushFront(types.NewSample("", prom.BuildMetric("", metric, ""), value, tags))
+			s = types.NewSample("", prom.BuildMetric("", metric, ""), value, tags)
 		}
+		s.Exemplar = p.exemplar(m.GetCounter().GetExemplar())
+		p.setTime(m, []*types.Sample{s})
+		slist.PushFront(s)
 	}
 }
 
+// setTime sets the timestamp of the exposition to the samples of m if HonorTimestamps
+func (p *Parser) setTime(m *dto.Metric, samples []*types.Sample) {
+	if !p.HonorTimestamps || m.TimestampMs == nil {
+		return
+	}
+	t := time.Unix(0, m.GetTimestampMs()*int64(time.Millisecond))
+	for _, s := range samples {
+		s.SetTime(t)
+	}
+}
+
+// exemplar converts e if the exemplars are gathered, the exemplars without timestamp are of the time of their samples
+func (p *Parser) exemplar(e *dto.Exemplar) *types.Exemplar {
+	if !p.Exemplars || e == nil {
+		return nil
+	}
+	ret := &types.Exemplar{
+		Labels: make(map[string]string, len(e.GetLabel())),
+		Value:  e.GetValue(),
+	}
+	for _, lp := range e.GetLabel() {
+		ret.Labels[lp.GetName()] = lp.GetValue()
+	}
+	if e.Timestamp != nil && e.Timestamp.IsValid() {
+		ret.Timestamp = e.Timestamp.AsTime()
+	}
+	return ret
+}
+
 // Get labels from metric
 func (p *Parser) makeLabels(m *dto.Metric) map[string]string {
 	result := map[string]string{}
@@ -126,21 +220,22 @@ func (p *Parser) makeLabels(m *dto.Metric) map[string]string {
 	return result
 }
 
-// Get name and value from metric
-func getNameAndValue(m *dto.Metric, metricName string) map[string]interface{} {
+// Get name and value from metric, the NaN values are dropped except the stale markers if keepStale
+func getNameAndValue(m *dto.Metric, metricName string, keepStale bool) map[string]interface{} {
 	fields := make(map[string]interface{})
+	var v float64
 	if m.Gauge != nil {
-		if !math.IsNaN(m.GetGauge().GetValue()) {
-			fields[metricName] = m.GetGauge().GetValue()
-		}
+		v = m.GetGauge().GetValue()
 	} else if m.Counter != nil {
-		if !math.IsNaN(m.GetCounter().GetValue()) {
-			fields[metricName] = m.GetCounter().GetValue()
-		}
+		v = m.GetCounter().GetValue()
 	} else if m.Untyped != nil {
-		if !math.IsNaN(m.GetUntyped().GetValue()) {
-			fields[metricName] = m.GetUntyped().GetValue()
-		}
+		v = m.GetUntyped().GetValue()
+	} else {
+		return fields
+	}
+	// the stale marker tells the series is gone, which is distinguished from 0 by the storage
+	if !math.IsNaN(v) || keepStale && value.IsStaleNaN(v) {
+		fields[metricName] = v
 	}
 	return fields
 }
@@ -0,0 +1,132 @@
+package cfg
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/koding/multiconfig"
+	"github.com/toolkits/pkg/file"
+)
+
+// MergeTOML merges the toml documents in order, the tables are merged key by key recursively,
+// the other values are replaced by the later documents, arrays and arrays of tables included,
+// e.g. [[instances]] of an overlay replaces all the instances of the base
+func MergeTOML(docs ...[]byte) ([]byte, error) {
+	merged := make(map[string]interface{})
+	for i, doc := range docs {
+		m := make(map[string]interface{})
+		if _, err := toml.Decode(string(doc), &m); err != nil {
+			return nil, fmt.Errorf("layer %d: %v", i, err)
+		}
+		mergeTable(merged, m)
+	}
+
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(merged); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func mergeTable(dst, src map[string]interface{}) {
+	for k, v := range src {
+		sub, ok := v.(map[string]interface{})
+		if !ok {
+			dst[k] = v
+			continue
+		}
+		if d, ok := dst[k].(map[string]interface{}); ok {
+			mergeTable(d, sub)
+		} else {
+			dst[k] = sub
+		}
+	}
+}
+
+// ReadLayers reads the files directly under the dirs, the dirs not existing are skipped,
+// the toml files of each dir are concatenated as one document as LoadConfigByDir does,
+// then the documents of the dirs are merged by MergeTOML, the json and yaml files are returned as they are
+func ReadLayers(dirs []string) ([]ConfigWithFormat, error) {
+	var (
+		docs   [][]byte
+		others []ConfigWithFormat
+	)
+	for _, dir := range dirs {
+		if !file.IsExist(dir) {
+			continue
+		}
+		files, err := file.FilesUnder(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list files under: %s : %v", dir, err)
+		}
+
+		var doc []byte
+		for _, f := range files {
+			format := GuessFormat(f)
+			if format == TomlFormat && !strings.HasSuffix(f, ".toml") {
+				continue
+			}
+			bs, err := file.ReadBytes(path.Join(dir, f))
+			if err != nil {
+				return nil, err
+			}
+			if format == TomlFormat {
+				doc = append(doc, bs...)
+				doc = append(doc, '\n')
+			} else {
+				others = append(others, ConfigWithFormat{Config: string(bs), Format: format})
+			}
+		}
+		docs = append(docs, doc)
+	}
+
+	if len(docs) == 0 {
+		return others, nil
+	}
+	merged, err := MergeTOML(docs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge layers %v: %v", dirs, err)
+	}
+	return append([]ConfigWithFormat{{Config: string(merged), Format: TomlFormat}}, others...), nil
+}
+
+// LoadConfigByDirs loads the config of the dir and its overlays, see ReadLayers
+func LoadConfigByDirs(dirs []string, configPtr interface{}) error {
+	if len(dirs) == 1 {
+		return LoadConfigByDir(dirs[0], configPtr)
+	}
+
+	configs, err := ReadLayers(dirs)
+	if err != nil {
+		return err
+	}
+
+	loaders := []multiconfig.Loader{
+		&multiconfig.TagLoader{},
+		&multiconfig.EnvironmentLoader{},
+	}
+	var tomlLoader multiconfig.Loader
+	for _, c := range configs {
+		r := bytes.NewReader([]byte(c.Config))
+		switch c.Format {
+		case TomlFormat:
+			tomlLoader = &multiconfig.TOMLLoader{Reader: r}
+		case JsonFormat:
+			loaders = append(loaders, &multiconfig.JSONLoader{Reader: r})
+		case YamlFormat:
+			loaders = append(loaders, &multiconfig.YAMLLoader{Reader: r})
+		}
+	}
+	if tomlLoader != nil {
+		loaders = append(loaders, tomlLoader)
+	}
+
+	m := multiconfig.DefaultLoader{
+		Loader:    multiconfig.MultiLoader(loaders...),
+		Validator: multiconfig.MultiValidator(&multiconfig.RequiredValidator{}),
+	}
+	return m.Load(configPtr)
+}
@@ -0,0 +1,20 @@
+package cloudmeta
+
+import (
+	"context"
+	"net/http"
+)
+
+const aliyunEndpoint = "http://100.100.100.200/latest/meta-data/"
+
+func detectAliyun(ctx context.Context, cli *http.Client) (*Metadata, error) {
+	m := &Metadata{}
+	err := getFields(ctx, cli, aliyunEndpoint, nil,
+		[]string{"instance-id", "region-id", "zone-id", "instance/instance-type", "owner-account-id"},
+		[]*string{&m.InstanceID, &m.Region, &m.Zone, &m.InstanceType, &m.AccountID},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
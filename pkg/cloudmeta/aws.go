@@ -0,0 +1,44 @@
+package cloudmeta
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+const awsEndpoint = "http://169.254.169.254"
+
+// detectAWS uses IMDSv2, falls back to IMDSv1 if the token could not be fetched
+func detectAWS(ctx context.Context, cli *http.Client) (*Metadata, error) {
+	headers := map[string]string{}
+	token, err := request(ctx, cli, http.MethodPut, awsEndpoint+"/latest/api/token", map[string]string{
+		"X-aws-ec2-metadata-token-ttl-seconds": "60",
+	})
+	if err == nil && len(token) > 0 {
+		headers["X-aws-ec2-metadata-token"] = string(token)
+	}
+
+	body, err := request(ctx, cli, http.MethodGet, awsEndpoint+"/latest/dynamic/instance-identity/document", headers)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc struct {
+		InstanceID       string `json:"instanceId"`
+		Region           string `json:"region"`
+		AvailabilityZone string `json:"availabilityZone"`
+		InstanceType     string `json:"instanceType"`
+		AccountID        string `json:"accountId"`
+	}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, err
+	}
+
+	return &Metadata{
+		InstanceID:   doc.InstanceID,
+		Region:       doc.Region,
+		Zone:         doc.AvailabilityZone,
+		InstanceType: doc.InstanceType,
+		AccountID:    doc.AccountID,
+	}, nil
+}
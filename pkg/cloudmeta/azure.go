@@ -0,0 +1,35 @@
+package cloudmeta
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+const azureEndpoint = "http://169.254.169.254/metadata/instance/compute?api-version=2021-02-01"
+
+func detectAzure(ctx context.Context, cli *http.Client) (*Metadata, error) {
+	body, err := request(ctx, cli, http.MethodGet, azureEndpoint, map[string]string{"Metadata": "true"})
+	if err != nil {
+		return nil, err
+	}
+
+	var doc struct {
+		VMID           string `json:"vmId"`
+		Location       string `json:"location"`
+		Zone           string `json:"zone"`
+		VMSize         string `json:"vmSize"`
+		SubscriptionID string `json:"subscriptionId"`
+	}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, err
+	}
+
+	return &Metadata{
+		InstanceID:   doc.VMID,
+		Region:       doc.Location,
+		Zone:         doc.Zone,
+		InstanceType: doc.VMSize,
+		AccountID:    doc.SubscriptionID,
+	}, nil
+}
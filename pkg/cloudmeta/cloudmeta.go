@@ -0,0 +1,155 @@
+package cloudmeta
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	ProviderAWS     = "aws"
+	ProviderAliyun  = "aliyun"
+	ProviderTencent = "tencent"
+	ProviderGCP     = "gcp"
+	ProviderAzure   = "azure"
+)
+
+// Metadata is the instance topology information reported by a cloud metadata service
+type Metadata struct {
+	Provider     string `json:"provider"`
+	InstanceID   string `json:"instance_id"`
+	Region       string `json:"region"`
+	Zone         string `json:"zone"`
+	InstanceType string `json:"instance_type"`
+	AccountID    string `json:"account_id"`
+}
+
+// Labels converts metadata to labels, empty fields are skipped
+func (m *Metadata) Labels(prefix string) map[string]string {
+	ret := make(map[string]string)
+	fields := map[string]string{
+		"cloud_provider": m.Provider,
+		"instance_id":    m.InstanceID,
+		"region":         m.Region,
+		"zone":           m.Zone,
+		"instance_type":  m.InstanceType,
+		"account_id":     m.AccountID,
+	}
+	for k, v := range fields {
+		if v == "" {
+			continue
+		}
+		ret[prefix+k] = v
+	}
+	return ret
+}
+
+type detector func(ctx context.Context, cli *http.Client) (*Metadata, error)
+
+var detectors = map[string]detector{
+	ProviderAWS:     detectAWS,
+	ProviderAliyun:  detectAliyun,
+	ProviderTencent: detectTencent,
+	ProviderGCP:     detectGCP,
+	ProviderAzure:   detectAzure,
+}
+
+// DefaultProviders is the detect order used when no provider is specified
+var DefaultProviders = []string{ProviderAWS, ProviderAliyun, ProviderTencent, ProviderGCP, ProviderAzure}
+
+// Detect queries the metadata services of the given providers concurrently,
+// and returns the result of the first provider (in the given order) which responds
+func Detect(providers []string, timeout time.Duration) (*Metadata, error) {
+	if len(providers) == 0 {
+		providers = DefaultProviders
+	}
+
+	for _, p := range providers {
+		if _, has := detectors[p]; !has {
+			return nil, fmt.Errorf("unknown cloud provider: %s", p)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cli := &http.Client{
+		// metadata services are always link-local, never go through proxy
+		Transport: &http.Transport{Proxy: nil},
+	}
+
+	results := make([]*Metadata, len(providers))
+	wg := new(sync.WaitGroup)
+	for i := range providers {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			m, err := detectors[providers[i]](ctx, cli)
+			if err == nil && m != nil && m.InstanceID != "" {
+				m.Provider = providers[i]
+				results[i] = m
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range results {
+		if results[i] != nil {
+			return results[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no cloud metadata service available, tried: %s", strings.Join(providers, ","))
+}
+
+func request(ctx context.Context, cli *http.Client, method, url string, headers map[string]string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := cli.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("request %s got status code: %d", url, resp.StatusCode)
+	}
+	return body, nil
+}
+
+func get(ctx context.Context, cli *http.Client, url string, headers map[string]string) (string, error) {
+	body, err := request(ctx, cli, http.MethodGet, url, headers)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(body)), nil
+}
+
+// getFields fetches several plain text metadata paths under the same base url,
+// the first path is required, the others are optional
+func getFields(ctx context.Context, cli *http.Client, base string, headers map[string]string, paths []string, dst []*string) error {
+	for i := range paths {
+		v, err := get(ctx, cli, base+paths[i], headers)
+		if err != nil {
+			if i == 0 {
+				return err
+			}
+			continue
+		}
+		*dst[i] = v
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package cloudmeta
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"strings"
+)
+
+const gcpEndpoint = "http://metadata.google.internal/computeMetadata/v1/"
+
+func detectGCP(ctx context.Context, cli *http.Client) (*Metadata, error) {
+	m := &Metadata{}
+	err := getFields(ctx, cli, gcpEndpoint, map[string]string{"Metadata-Flavor": "Google"},
+		[]string{"instance/id", "instance/zone", "instance/machine-type", "project/project-id"},
+		[]*string{&m.InstanceID, &m.Zone, &m.InstanceType, &m.AccountID},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// zone: projects/<num>/zones/us-central1-a
+	// machine-type: projects/<num>/machineTypes/n1-standard-1
+	m.Zone = path.Base(m.Zone)
+	m.InstanceType = path.Base(m.InstanceType)
+	if idx := strings.LastIndex(m.Zone, "-"); idx > 0 {
+		m.Region = m.Zone[:idx]
+	}
+	return m, nil
+}
@@ -0,0 +1,20 @@
+package cloudmeta
+
+import (
+	"context"
+	"net/http"
+)
+
+const tencentEndpoint = "http://metadata.tencentyun.com/latest/meta-data/"
+
+func detectTencent(ctx context.Context, cli *http.Client) (*Metadata, error) {
+	m := &Metadata{}
+	err := getFields(ctx, cli, tencentEndpoint, nil,
+		[]string{"instance-id", "placement/region", "placement/zone", "instance/instance-type", "app-id"},
+		[]*string{&m.InstanceID, &m.Region, &m.Zone, &m.InstanceType, &m.AccountID},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
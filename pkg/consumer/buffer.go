@@ -0,0 +1,98 @@
+// Package consumer buffers the samples of the messages consumed between gathers by the inputs of queues,
+// e.g. kafka_consumer, redis_consumer and amqp_consumer
+package consumer
+
+import (
+	"sync/atomic"
+
+	"flashcat.cloud/categraf/parser"
+	"flashcat.cloud/categraf/types"
+)
+
+type Buffer struct {
+	inputName string
+	parser    parser.Parser
+	// the samples buffered between gathers, the new ones are dropped once it's full
+	maxSamples int
+	// the label of the source of messages, e.g. the topic of kafka, not labeled if empty
+	sourceLabel string
+	samples     *types.SampleList
+
+	messages    uint64
+	parseErrors uint64
+	dropped     uint64
+	// 1 if the source is being consumed
+	up int32
+}
+
+func NewBuffer(inputName string, p parser.Parser, maxSamples int, sourceLabel string) *Buffer {
+	return &Buffer{
+		inputName:   inputName,
+		parser:      p,
+		maxSamples:  maxSamples,
+		sourceLabel: sourceLabel,
+		samples:     types.NewSampleList(),
+	}
+}
+
+// SetUp reports whether the source is being consumed
+func (b *Buffer) SetUp(up bool) {
+	v := int32(0)
+	if up {
+		v = 1
+	}
+	atomic.StoreInt32(&b.up, v)
+}
+
+// Add parses the payload of a message of source into the buffer, the messages failed to parse are counted
+// and skipped, and so are the empty ones, e.g. the tombstones of kafka
+func (b *Buffer) Add(source string, payload []byte) error {
+	atomic.AddUint64(&b.messages, 1)
+	if len(payload) == 0 {
+		return nil
+	}
+
+	slist := types.NewSampleList()
+	if err := b.parser.Parse(payload, slist); err != nil {
+		atomic.AddUint64(&b.parseErrors, 1)
+		return err
+	}
+
+	samples := slist.PopBackAll()
+	if free := b.maxSamples - b.samples.Len(); len(samples) > free {
+		if free < 0 {
+			free = 0
+		}
+		atomic.AddUint64(&b.dropped, uint64(len(samples)-free))
+		samples = samples[:free]
+	}
+
+	if b.sourceLabel != "" {
+		for _, s := range samples {
+			if _, has := s.Labels[b.sourceLabel]; !has {
+				s.Labels[b.sourceLabel] = source
+			}
+		}
+	}
+	b.samples.PushFrontN(samples)
+	return nil
+}
+
+// Invalid counts a message which has no payload to parse, e.g. an entry of redis stream without the field
+func (b *Buffer) Invalid() {
+	atomic.AddUint64(&b.messages, 1)
+	atomic.AddUint64(&b.parseErrors, 1)
+}
+
+// Gather drains the samples consumed since last gather, and pushes the counters of consumer with tags
+func (b *Buffer) Gather(slist *types.SampleList, tags map[string]string) {
+	slist.PushFrontN(b.samples.PopBackAll())
+
+	fields := map[string]interface{}{
+		"up":                    atomic.LoadInt32(&b.up),
+		"messages_total":        atomic.LoadUint64(&b.messages),
+		"parse_errors_total":    atomic.LoadUint64(&b.parseErrors),
+		"dropped_samples_total": atomic.LoadUint64(&b.dropped),
+	}
+	slist.PushSamples(b.inputName, fields, tags)
+}
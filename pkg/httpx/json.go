@@ -0,0 +1,52 @@
+package httpx
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// errorBodyLimit is the bytes of the response body quoted in the errors of unexpected status
+const errorBodyLimit = 200
+
+// DoJSON sends req by client and decodes the json response into v, or discards it if v is nil.
+// The statuses other than 200 and accepted are errors which quote the head of the body, with name as the api,
+// e.g. the path of req without the query which may carry tokens.
+// The response is returned with the body closed for the headers and the status, nil if req failed to send.
+func DoJSON(client *http.Client, req *http.Request, name string, v interface{}, accepted ...int) (*http.Response, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && !containsStatus(accepted, resp.StatusCode) {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, errorBodyLimit))
+		return resp, fmt.Errorf("%s returned HTTP status %s: %q", name, resp.Status, body)
+	}
+
+	if v == nil {
+		io.Copy(io.Discard, resp.Body)
+		return resp, nil
+	}
+	return resp, json.NewDecoder(resp.Body).Decode(v)
+}
+
+// GetJSON is DoJSON of a GET request of u
+func GetJSON(client *http.Client, u, name string, v interface{}, accepted ...int) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return DoJSON(client, req, name, v, accepted...)
+}
+
+func containsStatus(statuses []int, status int) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,152 @@
+package httpx
+
+import (
+	"crypto/sha256"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"flashcat.cloud/categraf/types"
+)
+
+const responseCacheSweepInterval = time.Minute
+
+var responseCacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "response_cache_requests_total",
+	Help: "Number of the responses of scrapes looked up in the response cache, result is not_modified, identical or miss.",
+}, []string{"result"})
+
+func init() {
+	prometheus.MustRegister(responseCacheRequests)
+}
+
+type cachedResponse struct {
+	etag         string
+	lastModified string
+	sum          [sha256.Size]byte
+	samples      []*types.Sample
+	parsed       time.Time
+}
+
+// ResponseCache keeps the validators and the samples parsed of the last response of each key, e.g. the url,
+// the samples are reused in ttl if the response is 304 or the body is identical, so the body is not parsed again
+type ResponseCache struct {
+	sync.Mutex
+	ttl         time.Duration
+	conditional bool
+
+	entries map[string]*cachedResponse
+	swept   time.Time
+}
+
+// NewResponseCache returns nil if ttl is not positive, the methods of a nil cache are no-ops
+func NewResponseCache(ttl time.Duration, conditional bool) *ResponseCache {
+	if ttl <= 0 {
+		return nil
+	}
+	return &ResponseCache{
+		ttl:         ttl,
+		conditional: conditional,
+		entries:     make(map[string]*cachedResponse),
+		swept:       time.Now(),
+	}
+}
+
+// fresh returns the entry of key not expired
+func (c *ResponseCache) fresh(key string) *cachedResponse {
+	e, has := c.entries[key]
+	if !has || time.Since(e.parsed) >= c.ttl {
+		return nil
+	}
+	return e
+}
+
+// SetConditionalHeaders sets If-None-Match and If-Modified-Since of the last response of key,
+// only if its samples are still reusable, so a 304 is always answered by the cache
+func (c *ResponseCache) SetConditionalHeaders(key string, req *http.Request) {
+	if c == nil || !c.conditional {
+		return
+	}
+
+	c.Lock()
+	defer c.Unlock()
+	e := c.fresh(key)
+	if e == nil {
+		return
+	}
+	if e.etag != "" {
+		req.Header.Set("If-None-Match", e.etag)
+	}
+	if e.lastModified != "" {
+		req.Header.Set("If-Modified-Since", e.lastModified)
+	}
+}
+
+// Lookup returns the copies of the samples parsed from the last response of key,
+// if res is 304 or body is identical to the last one
+func (c *ResponseCache) Lookup(key string, res *http.Response, body []byte) ([]*types.Sample, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.Lock()
+	defer c.Unlock()
+	e := c.fresh(key)
+	switch {
+	case e == nil:
+	case res.StatusCode == http.StatusNotModified:
+		responseCacheRequests.WithLabelValues("not_modified").Inc()
+		return copySamples(e.samples), true
+	case sha256.Sum256(body) == e.sum:
+		responseCacheRequests.WithLabelValues("identical").Inc()
+		return copySamples(e.samples), true
+	}
+	responseCacheRequests.WithLabelValues("miss").Inc()
+	return nil, false
+}
+
+// Store keeps the copies of the samples parsed from body of res
+func (c *ResponseCache) Store(key string, res *http.Response, body []byte, samples []*types.Sample) {
+	if c == nil {
+		return
+	}
+
+	c.Lock()
+	defer c.Unlock()
+	c.sweep()
+	c.entries[key] = &cachedResponse{
+		etag:         res.Header.Get("ETag"),
+		lastModified: res.Header.Get("Last-Modified"),
+		sum:          sha256.Sum256(body),
+		samples:      copySamples(samples),
+		parsed:       time.Now(),
+	}
+}
+
+// sweep drops the entries expired, e.g. of the urls not scraped any more
+func (c *ResponseCache) sweep() {
+	if time.Since(c.swept) < responseCacheSweepInterval {
+		return
+	}
+	c.swept = time.Now()
+	for key := range c.entries {
+		if c.fresh(key) == nil {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// copySamples copies the labels as well, since they are modified by the processors and the writers
+func copySamples(samples []*types.Sample) []*types.Sample {
+	ret := make([]*types.Sample, len(samples))
+	for i, s := range samples {
+		labels := make(map[string]string, len(s.Labels))
+		for k, v := range s.Labels {
+			labels[k] = v
+		}
+		ret[i] = &types.Sample{Metric: s.Metric, Timestamp: s.Timestamp, Value: s.Value, Labels: labels}
+	}
+	return ret
+}
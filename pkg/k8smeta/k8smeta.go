@@ -0,0 +1,159 @@
+package k8smeta
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const namespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+// Metadata is the labels of the node and the pod categraf is running in
+type Metadata struct {
+	NodeName       string
+	PodName        string
+	Namespace      string
+	NodeLabels     map[string]string
+	PodLabels      map[string]string
+	PodAnnotations map[string]string
+}
+
+// Options tells where the metadata is read from
+type Options struct {
+	// outside of the cluster, the service account of the pod by default
+	Kubeconfig string
+	// the dir of the downward api volume having files labels and annotations, the pod is requested from
+	// the api server if empty
+	DownwardAPIDir string
+	// the node labels are requested only if true
+	NodeLabels bool
+	Timeout    time.Duration
+}
+
+// Detect reads the metadata, the names are from env NODE_NAME, POD_NAME and POD_NAMESPACE,
+// which are set by the downward api, e.g. env: [{name: NODE_NAME, valueFrom: {fieldRef: {fieldPath: spec.nodeName}}}]
+func Detect(opts Options) (*Metadata, error) {
+	m := &Metadata{
+		NodeName:  os.Getenv("NODE_NAME"),
+		PodName:   os.Getenv("POD_NAME"),
+		Namespace: Namespace(),
+	}
+	if m.PodName == "" {
+		// the hostname of a pod is its name, unless it's in the network of host
+		m.PodName, _ = os.Hostname()
+	}
+
+	if opts.DownwardAPIDir != "" {
+		var err error
+		if m.PodLabels, err = readDownwardAPIFile(filepath.Join(opts.DownwardAPIDir, "labels")); err != nil {
+			return nil, err
+		}
+		if m.PodAnnotations, err = readDownwardAPIFile(filepath.Join(opts.DownwardAPIDir, "annotations")); err != nil {
+			return nil, err
+		}
+		if !opts.NodeLabels {
+			return m, nil
+		}
+	}
+
+	client, err := NewClient(opts.Kubeconfig, opts.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
+	defer cancel()
+
+	if opts.DownwardAPIDir == "" {
+		if m.Namespace == "" {
+			return nil, fmt.Errorf("the namespace of pod is unknown, set env POD_NAMESPACE")
+		}
+		pod, err := client.CoreV1().Pods(m.Namespace).Get(ctx, m.PodName, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get pod %s/%s: %v", m.Namespace, m.PodName, err)
+		}
+		m.PodLabels, m.PodAnnotations = pod.Labels, pod.Annotations
+		if m.NodeName == "" {
+			m.NodeName = pod.Spec.NodeName
+		}
+	}
+
+	if opts.NodeLabels {
+		if m.NodeName == "" {
+			return nil, fmt.Errorf("the node is unknown, set env NODE_NAME")
+		}
+		node, err := client.CoreV1().Nodes().Get(ctx, m.NodeName, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get node %s: %v", m.NodeName, err)
+		}
+		m.NodeLabels = node.Labels
+	}
+	return m, nil
+}
+
+// Namespace returns the namespace of the pod categraf is running in, empty if unknown
+func Namespace() string {
+	if ns := os.Getenv("POD_NAMESPACE"); ns != "" {
+		return ns
+	}
+	if bs, err := os.ReadFile(namespaceFile); err == nil {
+		return strings.TrimSpace(string(bs))
+	}
+	return ""
+}
+
+// NewClient connects to the apiserver by the service account of the pod if kubeconfig is empty
+func NewClient(kubeconfig string, timeout time.Duration) (*kubernetes.Clientset, error) {
+	var (
+		restConfig *rest.Config
+		err        error
+	)
+	if kubeconfig == "" {
+		restConfig, err = rest.InClusterConfig()
+	} else {
+		restConfig, err = clientcmd.BuildConfigFromFlags("", kubeconfig)
+	}
+	if err != nil {
+		return nil, err
+	}
+	restConfig.Timeout = timeout
+	return kubernetes.NewForConfig(restConfig)
+}
+
+// readDownwardAPIFile parses the lines of key="value" of the files of metadata.labels and metadata.annotations
+func readDownwardAPIFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	ret := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		i := strings.Index(line, "=")
+		if i <= 0 {
+			continue
+		}
+		v, err := strconv.Unquote(line[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid line %q", path, line)
+		}
+		ret[line[:i]] = v
+	}
+	return ret, scanner.Err()
+}
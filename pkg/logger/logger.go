@@ -0,0 +1,285 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Level int32
+
+const (
+	DebugLevel Level = iota
+	InfoLevel
+	WarnLevel
+	ErrorLevel
+)
+
+var (
+	levelNames = [...]string{"debug", "info", "warn", "error"}
+	// the prefixes of levels in the lines of the standard log
+	levelPrefixes = [...]string{"D!", "I!", "W!", "E!"}
+)
+
+func (l Level) String() string {
+	if l < DebugLevel || l > ErrorLevel {
+		return "unknown"
+	}
+	return levelNames[l]
+}
+
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug", "d":
+		return DebugLevel, nil
+	case "", "info", "i":
+		return InfoLevel, nil
+	case "warn", "warning", "w":
+		return WarnLevel, nil
+	case "error", "e":
+		return ErrorLevel, nil
+	}
+	return InfoLevel, fmt.Errorf("invalid log level: %s", s)
+}
+
+type Options struct {
+	// text or json
+	Format string
+	Level  string
+	// the levels by components, e.g. {"input.mysql" = "debug"}, which apply to the sub components too
+	Levels map[string]string
+}
+
+var std = struct {
+	sync.RWMutex
+	out    io.Writer
+	json   bool
+	level  Level
+	levels map[string]Level
+}{out: os.Stderr, level: InfoLevel, levels: make(map[string]Level)}
+
+// the lines are written by one write, but out is not always safe for concurrent use
+var writeLock sync.Mutex
+
+// Init writes the logs to out, and so do the lines of the standard log, whose levels
+// are parsed from the prefixes, e.g. "E! failed to ..."
+func Init(out io.Writer, opts Options) error {
+	level, err := ParseLevel(opts.Level)
+	if err != nil {
+		return err
+	}
+
+	levels := make(map[string]Level, len(opts.Levels))
+	for component, s := range opts.Levels {
+		l, err := ParseLevel(s)
+		if err != nil {
+			return fmt.Errorf("component %s: %v", component, err)
+		}
+		levels[component] = l
+	}
+
+	var isJSON bool
+	switch strings.ToLower(opts.Format) {
+	case "", "text":
+	case "json":
+		isJSON = true
+	default:
+		return fmt.Errorf("invalid log format: %s", opts.Format)
+	}
+
+	std.Lock()
+	std.out = out
+	std.json = isJSON
+	std.level = level
+	std.levels = levels
+	std.Unlock()
+
+	log.SetFlags(0)
+	log.SetPrefix("")
+	log.SetOutput(stdWriter{})
+	return nil
+}
+
+// SetLevel changes the level of component at runtime, or the default level if component is empty
+func SetLevel(component string, level Level) {
+	std.Lock()
+	defer std.Unlock()
+	if component == "" {
+		std.level = level
+		return
+	}
+	std.levels[component] = level
+}
+
+// ResetLevel removes the level of component, the default level applies to it again
+func ResetLevel(component string) {
+	std.Lock()
+	defer std.Unlock()
+	delete(std.levels, component)
+}
+
+// Levels returns the default level and the levels of components
+func Levels() (Level, map[string]Level) {
+	std.RLock()
+	defer std.RUnlock()
+	levels := make(map[string]Level, len(std.levels))
+	for k, v := range std.levels {
+		levels[k] = v
+	}
+	return std.level, levels
+}
+
+// Enabled returns whether the logs of level are written for component, the level of
+// input.mysql is looked up by input.mysql, input and the default in order
+func Enabled(component string, level Level) bool {
+	std.RLock()
+	defer std.RUnlock()
+	for c := component; c != ""; {
+		if l, has := std.levels[c]; has {
+			return level >= l
+		}
+		i := strings.LastIndex(c, ".")
+		if i < 0 {
+			break
+		}
+		c = c[:i]
+	}
+	return level >= std.level
+}
+
+// Logger writes logs of a component with the fields of key and value pairs
+type Logger struct {
+	component string
+	fields    []interface{}
+}
+
+func New(component string) *Logger {
+	return &Logger{component: component}
+}
+
+// With returns a logger with the fields appended, e.g. With("instance", 0)
+func (l *Logger) With(kvs ...interface{}) *Logger {
+	fields := make([]interface{}, 0, len(l.fields)+len(kvs))
+	fields = append(fields, l.fields...)
+	fields = append(fields, kvs...)
+	return &Logger{component: l.component, fields: fields}
+}
+
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	l.logf(DebugLevel, format, args...)
+}
+
+func (l *Logger) Infof(format string, args ...interface{}) {
+	l.logf(InfoLevel, format, args...)
+}
+
+func (l *Logger) Warnf(format string, args ...interface{}) {
+	l.logf(WarnLevel, format, args...)
+}
+
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	l.logf(ErrorLevel, format, args...)
+}
+
+func (l *Logger) logf(level Level, format string, args ...interface{}) {
+	if !Enabled(l.component, level) {
+		return
+	}
+	write(level, l.component, fmt.Sprintf(format, args...), l.fields)
+}
+
+func write(level Level, component, msg string, fields []interface{}) {
+	std.RLock()
+	out, isJSON := std.out, std.json
+	std.RUnlock()
+
+	var buf bytes.Buffer
+	now := time.Now()
+	if isJSON {
+		buf.WriteString(`{"time":`)
+		writeJSON(&buf, now.Format(time.RFC3339Nano))
+		buf.WriteString(`,"level":`)
+		writeJSON(&buf, level.String())
+		if component != "" {
+			buf.WriteString(`,"component":`)
+			writeJSON(&buf, component)
+		}
+		buf.WriteString(`,"msg":`)
+		writeJSON(&buf, msg)
+		for i := 0; i+1 < len(fields); i += 2 {
+			buf.WriteString(",")
+			writeJSON(&buf, fmt.Sprint(fields[i]))
+			buf.WriteString(":")
+			writeJSON(&buf, fields[i+1])
+		}
+		buf.WriteString("}\n")
+	} else {
+		buf.WriteString(now.Format("2006/01/02 15:04:05 "))
+		buf.WriteString(levelPrefixes[level])
+		buf.WriteString(" ")
+		if component != "" {
+			buf.WriteString("[" + component + "] ")
+		}
+		buf.WriteString(msg)
+		for i := 0; i+1 < len(fields); i += 2 {
+			buf.WriteString(" " + fmt.Sprint(fields[i]) + "=" + quote(fmt.Sprint(fields[i+1])))
+		}
+		buf.WriteString("\n")
+	}
+
+	writeLock.Lock()
+	out.Write(buf.Bytes())
+	writeLock.Unlock()
+}
+
+func writeJSON(buf *bytes.Buffer, v interface{}) {
+	if err, ok := v.(error); ok {
+		v = err.Error()
+	}
+
+	bs, err := json.Marshal(v)
+	if err != nil {
+		bs, _ = json.Marshal(fmt.Sprint(v))
+	}
+	buf.Write(bs)
+}
+
+func quote(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// stdWriter parses the lines of the standard log, e.g. "E! failed to ...", "D!, ..."
+type stdWriter struct{}
+
+func (stdWriter) Write(p []byte) (int, error) {
+	msg := strings.TrimRight(string(p), "\n")
+	level := InfoLevel
+	for l, prefix := range levelPrefixes {
+		if strings.HasPrefix(msg, prefix) {
+			level = Level(l)
+			msg = strings.TrimLeft(msg[len(prefix):], ",: ")
+			break
+		}
+	}
+
+	// F! of log.Fatal
+	if strings.HasPrefix(msg, "F!") {
+		level = ErrorLevel
+		msg = strings.TrimLeft(msg[2:], ",: ")
+	}
+
+	if Enabled("", level) {
+		write(level, "", msg, nil)
+	}
+	return len(p), nil
+}
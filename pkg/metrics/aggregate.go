@@ -0,0 +1,111 @@
+package metrics
+
+import (
+	"math"
+	"strings"
+	"sync"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Group is the series of a metric family summed by some labels
+type Group struct {
+	Labels map[string]string
+	// the value of counters, gauges and untyped, or the count of histograms and summaries
+	Value float64
+	// the sum of histograms and summaries
+	Sum float64
+}
+
+// SumBy sums the series of mf grouped by the labels, the labels missing of a series are empty,
+// all series are summed into one group without labels, the groups are in the order first seen
+func SumBy(mf *dto.MetricFamily, labels ...string) []*Group {
+	if mf == nil {
+		return nil
+	}
+
+	var groups []*Group
+	index := make(map[string]*Group)
+	values := make([]string, len(labels))
+	for _, m := range mf.GetMetric() {
+		for i := range values {
+			values[i] = ""
+		}
+		for _, pair := range m.GetLabel() {
+			for i, l := range labels {
+				if pair.GetName() == l {
+					values[i] = pair.GetValue()
+				}
+			}
+		}
+
+		key := strings.Join(values, "\xff")
+		g, ok := index[key]
+		if !ok {
+			g = &Group{Labels: make(map[string]string, len(labels))}
+			for i, l := range labels {
+				g.Labels[l] = values[i]
+			}
+			index[key] = g
+			groups = append(groups, g)
+		}
+
+		value, sum := metricValue(m)
+		if !math.IsNaN(value) {
+			g.Value += value
+		}
+		if !math.IsNaN(sum) {
+			g.Sum += sum
+		}
+	}
+	return groups
+}
+
+// Sum sums all series of mf, 0 if it's nil
+func Sum(mf *dto.MetricFamily) float64 {
+	var total float64
+	for _, g := range SumBy(mf) {
+		total += g.Value
+	}
+	return total
+}
+
+func metricValue(m *dto.Metric) (float64, float64) {
+	switch {
+	case m.Counter != nil:
+		return m.GetCounter().GetValue(), 0
+	case m.Gauge != nil:
+		return m.GetGauge().GetValue(), 0
+	case m.Untyped != nil:
+		return m.GetUntyped().GetValue(), 0
+	case m.Histogram != nil:
+		return float64(m.GetHistogram().GetSampleCount()), m.GetHistogram().GetSampleSum()
+	case m.Summary != nil:
+		return float64(m.GetSummary().GetSampleCount()), m.GetSummary().GetSampleSum()
+	}
+	return 0, 0
+}
+
+// Deltas keeps the last values of counters to compute the increases between gathers
+type Deltas struct {
+	sync.Mutex
+	last map[string]float64
+}
+
+func NewDeltas() *Deltas {
+	return &Deltas{last: make(map[string]float64)}
+}
+
+// Delta returns the increase of the counter since the last call of key,
+// false at the first call or after the counter is reset
+func (d *Deltas) Delta(key string, value float64) (float64, bool) {
+	d.Lock()
+	defer d.Unlock()
+
+	last, ok := d.last[key]
+	d.last[key] = value
+	if !ok || value < last {
+		return 0, false
+	}
+	return value - last, true
+}
@@ -0,0 +1,11 @@
+package osx
+
+import "os"
+
+func GetHostSys() string {
+	sysPath := "/sys"
+	if os.Getenv("HOST_SYS") != "" {
+		sysPath = os.Getenv("HOST_SYS")
+	}
+	return sysPath
+}
@@ -0,0 +1,335 @@
+// Package pause keeps the input instances paused by operators, e.g. during maintenance windows.
+//
+// Instances are paused through the admin api or listed in the control file (pause_file of [global]),
+// the ones paused through the api are persisted in the state store, so they survive reloads and restarts.
+// An instance is identified by the name of the input and the id of its config, the same as the labels of
+// categraf_plugin_up, so a pause stays with the instance if the instances are reordered or others are added,
+// an empty id pauses the whole input.
+package pause
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/pkg/state"
+)
+
+var pauseLog = logger.New("pause")
+
+const (
+	stateNamespace = "pause"
+	stateKey       = "inputs"
+
+	fileCheckInterval = 5 * time.Second
+
+	SourceAPI  = "api"
+	SourceFile = "file"
+)
+
+var inputPaused = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "input_paused",
+	Help: "Whether the input instance is paused, instance is empty if the whole input is paused.",
+}, []string{"plugin", "instance"})
+
+func init() {
+	prometheus.MustRegister(inputPaused)
+}
+
+type Entry struct {
+	Input    string `json:"input"`
+	Instance string `json:"instance"`
+	Reason   string `json:"reason,omitempty"`
+	Source   string `json:"source"`
+	Since    int64  `json:"since"`
+	// unix seconds, 0 means paused until resumed
+	Until int64 `json:"until,omitempty"`
+}
+
+func (e Entry) expired(now time.Time) bool {
+	return e.Until > 0 && now.Unix() >= e.Until
+}
+
+type registry struct {
+	sync.RWMutex
+	api  map[string]Entry
+	file map[string]Entry
+
+	path    string
+	modTime time.Time
+	quit    chan struct{}
+}
+
+var reg = &registry{
+	api:  make(map[string]Entry),
+	file: make(map[string]Entry),
+}
+
+func key(input, instance string) string {
+	if instance == "" {
+		return input
+	}
+	return input + "#" + instance
+}
+
+// Init loads the instances paused before the restart, and watches the control file if it's not empty
+func Init(file string) {
+	saved := make(map[string]Entry)
+	state.Get(stateNamespace, stateKey, &saved, 0)
+
+	reg.Lock()
+	now := time.Now()
+	for k, e := range saved {
+		if e.expired(now) {
+			continue
+		}
+		reg.api[k] = e
+		inputPaused.WithLabelValues(e.Input, e.Instance).Set(1)
+	}
+	reg.path = file
+	reg.Unlock()
+
+	if file == "" || reg.quit != nil {
+		return
+	}
+
+	reg.loadFile()
+	reg.quit = make(chan struct{})
+	go reg.watchFile()
+}
+
+func (r *registry) watchFile() {
+	ticker := time.NewTicker(fileCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.quit:
+			return
+		case <-ticker.C:
+			r.loadFile()
+		}
+	}
+}
+
+// loadFile rereads the control file once it's modified, a missing file pauses nothing.
+// every line is the input and the optional id of instance, followed by the reason, e.g.
+//
+//	mysql#3f2a9c1b upgrading the primary
+//	redis
+func (r *registry) loadFile() {
+	var modTime time.Time
+	entries := make(map[string]Entry)
+
+	st, err := os.Stat(r.path)
+	if err == nil {
+		modTime = st.ModTime()
+		r.RLock()
+		unchanged := modTime.Equal(r.modTime)
+		r.RUnlock()
+		if unchanged {
+			return
+		}
+
+		if entries, err = parseFile(r.path, modTime); err != nil {
+			pauseLog.Errorf("failed to read pause file: %s error: %v", r.path, err)
+			return
+		}
+	} else if !os.IsNotExist(err) {
+		pauseLog.Errorf("failed to stat pause file: %s error: %v", r.path, err)
+		return
+	}
+
+	r.Lock()
+	defer r.Unlock()
+	if modTime.Equal(r.modTime) && len(r.file) == 0 {
+		return
+	}
+
+	old := r.file
+	r.file = entries
+	r.modTime = modTime
+	for k, e := range old {
+		if _, has := entries[k]; !has {
+			pauseLog.Infof("input resumed by pause file: %s", k)
+			r.updateGauge(e.Input, e.Instance)
+		}
+	}
+	for k, e := range entries {
+		if _, has := old[k]; !has {
+			pauseLog.Infof("input paused by pause file: %s", k)
+		}
+		inputPaused.WithLabelValues(e.Input, e.Instance).Set(1)
+	}
+}
+
+func parseFile(path string, modTime time.Time) (map[string]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	entries := make(map[string]Entry)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.SplitN(line, " ", 2)
+		input, instance, _ := strings.Cut(fields[0], "#")
+		e := Entry{Input: input, Instance: instance, Source: SourceFile, Since: modTime.Unix()}
+		if len(fields) > 1 {
+			e.Reason = strings.TrimSpace(fields[1])
+		}
+		entries[key(input, instance)] = e
+	}
+	return entries, scanner.Err()
+}
+
+// updateGauge should be called with the lock held, after an entry is removed
+func (r *registry) updateGauge(input, instance string) {
+	k := key(input, instance)
+	if _, has := r.api[k]; has {
+		return
+	}
+	if _, has := r.file[k]; has {
+		return
+	}
+	inputPaused.DeleteLabelValues(input, instance)
+}
+
+// Pause pauses the instance of input, or the whole input if instance is empty, until resumed if d is 0
+func Pause(input, instance string, d time.Duration, reason string) Entry {
+	now := time.Now()
+	e := Entry{Input: input, Instance: instance, Reason: reason, Source: SourceAPI, Since: now.Unix()}
+	if d > 0 {
+		e.Until = now.Add(d).Unix()
+	}
+
+	reg.Lock()
+	reg.api[key(input, instance)] = e
+	reg.save()
+	reg.Unlock()
+
+	inputPaused.WithLabelValues(input, instance).Set(1)
+	pauseLog.Infof("input paused: %s until: %s reason: %s", key(input, instance), untilString(e.Until), reason)
+	return e
+}
+
+// Resume resumes the instance paused through the api, the ones listed in the control file are resumed by editing the file
+func Resume(input, instance string) error {
+	k := key(input, instance)
+
+	reg.Lock()
+	defer reg.Unlock()
+
+	if _, has := reg.api[k]; !has {
+		if _, has = reg.file[k]; has {
+			return fmt.Errorf("%s is paused by pause file %s", k, reg.path)
+		}
+		return fmt.Errorf("%s is not paused", k)
+	}
+
+	delete(reg.api, k)
+	reg.save()
+	reg.updateGauge(input, instance)
+	pauseLog.Infof("input resumed: %s", k)
+	return nil
+}
+
+// save should be called with the lock held
+func (r *registry) save() {
+	if err := state.Put(stateNamespace, stateKey, r.api); err != nil {
+		pauseLog.Errorf("failed to save paused inputs: %v", err)
+	}
+}
+
+// Paused returns true if the instance or the whole input is paused
+func Paused(input, instance string) bool {
+	now := time.Now()
+
+	reg.RLock()
+	expired := false
+	paused := false
+	for _, k := range []string{key(input, ""), key(input, instance)} {
+		if e, has := reg.api[k]; has {
+			if e.expired(now) {
+				expired = true
+			} else {
+				paused = true
+			}
+		}
+		if _, has := reg.file[k]; has {
+			paused = true
+		}
+	}
+	reg.RUnlock()
+
+	if expired {
+		reg.expire(now)
+	}
+	return paused
+}
+
+func (r *registry) expire(now time.Time) {
+	r.Lock()
+	defer r.Unlock()
+
+	changed := false
+	for k, e := range r.api {
+		if e.expired(now) {
+			delete(r.api, k)
+			r.updateGauge(e.Input, e.Instance)
+			changed = true
+			pauseLog.Infof("input resumed, the pause expired: %s", k)
+		}
+	}
+	if changed {
+		r.save()
+	}
+}
+
+// List returns the paused entries sorted by input and instance
+func List() []Entry {
+	now := time.Now()
+
+	reg.RLock()
+	defer reg.RUnlock()
+
+	ret := make([]Entry, 0, len(reg.api)+len(reg.file))
+	for _, e := range reg.api {
+		if !e.expired(now) {
+			ret = append(ret, e)
+		}
+	}
+	for _, e := range reg.file {
+		ret = append(ret, e)
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Input != ret[j].Input {
+			return ret[i].Input < ret[j].Input
+		}
+		if ret[i].Instance != ret[j].Instance {
+			return ret[i].Instance < ret[j].Instance
+		}
+		return ret[i].Source < ret[j].Source
+	})
+	return ret
+}
+
+func untilString(until int64) string {
+	if until == 0 {
+		return "resumed"
+	}
+	return time.Unix(until, 0).Format(time.RFC3339)
+}
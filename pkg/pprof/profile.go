@@ -1,3 +1,5 @@
+//go:build !minimal
+
 package pprof
 
 import (
@@ -5,6 +7,7 @@ import (
 	"log"
 	"net"
 	"net/http"
+	_ "net/http/pprof"
 	"sync/atomic"
 )
 
@@ -0,0 +1,10 @@
+//go:build minimal
+
+package pprof
+
+import "log"
+
+// Go does nothing, pprof is not built into the minimal build to save the size
+func Go() {
+	log.Println("W! pprof is not supported by the minimal build")
+}
@@ -0,0 +1,32 @@
+package protox
+
+import (
+	"fmt"
+)
+
+// RawCodec is a grpc codec passing raw bytes through, messages are encoded
+// and decoded by the caller. It keeps the name "proto" so peers see the standard content-subtype.
+type RawCodec struct{}
+
+func (RawCodec) Marshal(v interface{}) ([]byte, error) {
+	switch b := v.(type) {
+	case []byte:
+		return b, nil
+	case *[]byte:
+		return *b, nil
+	}
+	return nil, fmt.Errorf("raw codec: unsupported message type %T", v)
+}
+
+func (RawCodec) Unmarshal(data []byte, v interface{}) error {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return fmt.Errorf("raw codec: unsupported message type %T", v)
+	}
+	*b = append((*b)[:0], data...)
+	return nil
+}
+
+func (RawCodec) Name() string {
+	return "proto"
+}
@@ -0,0 +1,115 @@
+// Package protox provides helpers to encode and decode protobuf messages on the wire,
+// it is used by the telemetry inputs whose protos are not worth generating code for.
+package protox
+
+import (
+	"fmt"
+	"math"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Field is a decoded protobuf field, only the member matching Type is set
+type Field struct {
+	Num     protowire.Number
+	Type    protowire.Type
+	Varint  uint64
+	Fixed32 uint32
+	Fixed64 uint64
+	Bytes   []byte
+}
+
+func (f Field) String() string {
+	return string(f.Bytes)
+}
+
+func (f Field) Int64() int64 {
+	return int64(f.Varint)
+}
+
+func (f Field) Sint64() int64 {
+	return protowire.DecodeZigZag(f.Varint)
+}
+
+func (f Field) Bool() bool {
+	return f.Varint != 0
+}
+
+func (f Field) Float32() float32 {
+	return math.Float32frombits(f.Fixed32)
+}
+
+func (f Field) Float64() float64 {
+	return math.Float64frombits(f.Fixed64)
+}
+
+// Range iterates the fields of the message b, stops if fn returns error
+func Range(b []byte, fn func(f Field) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		f := Field{Num: num, Type: typ}
+		switch typ {
+		case protowire.VarintType:
+			f.Varint, n = protowire.ConsumeVarint(b)
+		case protowire.Fixed32Type:
+			f.Fixed32, n = protowire.ConsumeFixed32(b)
+		case protowire.Fixed64Type:
+			f.Fixed64, n = protowire.ConsumeFixed64(b)
+		case protowire.BytesType:
+			f.Bytes, n = protowire.ConsumeBytes(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return fmt.Errorf("field %d: %v", num, protowire.ParseError(n))
+		}
+		b = b[n:]
+
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AppendBytes appends a length-delimited field, empty value is skipped
+func AppendBytes(b []byte, num protowire.Number, v []byte) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
+
+// AppendMessage appends an embedded message field even if it is empty
+func AppendMessage(b []byte, num protowire.Number, v []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
+
+// AppendString appends a string field, empty value is skipped
+func AppendString(b []byte, num protowire.Number, v string) []byte {
+	return AppendBytes(b, num, []byte(v))
+}
+
+// AppendVarint appends a varint field, zero value is skipped
+func AppendVarint(b []byte, num protowire.Number, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+// AppendBool appends a bool field, false is skipped
+func AppendBool(b []byte, num protowire.Number, v bool) []byte {
+	if !v {
+		return b
+	}
+	return AppendVarint(b, num, 1)
+}
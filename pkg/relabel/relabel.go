@@ -0,0 +1,164 @@
+package relabel
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/common/model"
+)
+
+const (
+	Replace   = "replace"
+	Keep      = "keep"
+	Drop      = "drop"
+	HashMod   = "hashmod"
+	LabelMap  = "labelmap"
+	LabelDrop = "labeldrop"
+	LabelKeep = "labelkeep"
+)
+
+const (
+	defaultSeparator   = ";"
+	defaultRegex       = "(.*)"
+	defaultReplacement = "$1"
+)
+
+// Config is a rule of the relabeling of prometheus, e.g. of metric_relabel_configs, the metric name is the label __name__
+type Config struct {
+	SourceLabels []string `toml:"source_labels"`
+	// joins the values of source_labels, ; by default
+	Separator string `toml:"separator"`
+	// anchored at both ends, (.*) by default
+	Regex   string `toml:"regex"`
+	Modulus uint64 `toml:"modulus"`
+	// the label written by replace and hashmod, the groups of regex are expanded in it for replace
+	TargetLabel string `toml:"target_label"`
+	// $1 by default, empty to delete target_label
+	Replacement *string `toml:"replacement"`
+	// replace (default) | keep | drop | hashmod | labelmap | labeldrop | labelkeep
+	Action string `toml:"action"`
+
+	regex       *regexp.Regexp
+	replacement string
+}
+
+// Validate checks the rule and compiles its regex, it must be called before Process
+func (c *Config) Validate() error {
+	if c.Action == "" {
+		c.Action = Replace
+	}
+	if c.Separator == "" {
+		c.Separator = defaultSeparator
+	}
+	c.replacement = defaultReplacement
+	if c.Replacement != nil {
+		c.replacement = *c.Replacement
+	}
+
+	regex := c.Regex
+	if regex == "" {
+		regex = defaultRegex
+	}
+	var err error
+	if c.regex, err = regexp.Compile("^(?:" + regex + ")$"); err != nil {
+		return fmt.Errorf("invalid regex %q of relabel action %s: %v", c.Regex, c.Action, err)
+	}
+
+	switch c.Action {
+	case Replace:
+		if c.TargetLabel == "" {
+			return fmt.Errorf("target_label is required by relabel action %s", c.Action)
+		}
+	case HashMod:
+		if c.TargetLabel == "" {
+			return fmt.Errorf("target_label is required by relabel action %s", c.Action)
+		}
+		if c.Modulus == 0 {
+			return fmt.Errorf("modulus is required by relabel action %s", c.Action)
+		}
+	case Keep, Drop, LabelMap:
+	case LabelDrop, LabelKeep:
+		if len(c.SourceLabels) > 0 || c.TargetLabel != "" {
+			return fmt.Errorf("source_labels and target_label are not allowed by relabel action %s", c.Action)
+		}
+	default:
+		return fmt.Errorf("unknown relabel action %q", c.Action)
+	}
+	return nil
+}
+
+// Process applies the rules to labels in order, it returns false if the labels are dropped
+func Process(labels map[string]string, cfgs []*Config) bool {
+	for _, c := range cfgs {
+		if !c.process(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Config) process(labels map[string]string) bool {
+	values := make([]string, len(c.SourceLabels))
+	for i, name := range c.SourceLabels {
+		values[i] = labels[name]
+	}
+	val := strings.Join(values, c.Separator)
+
+	switch c.Action {
+	case Keep:
+		return c.regex.MatchString(val)
+	case Drop:
+		return !c.regex.MatchString(val)
+	case Replace:
+		indexes := c.regex.FindStringSubmatchIndex(val)
+		if indexes == nil {
+			return true
+		}
+		target := string(c.regex.ExpandString(nil, c.TargetLabel, val, indexes))
+		if !model.LabelName(target).IsValid() {
+			return true
+		}
+		res := c.regex.ExpandString(nil, c.replacement, val, indexes)
+		if len(res) == 0 {
+			delete(labels, target)
+		} else {
+			labels[target] = string(res)
+		}
+	case HashMod:
+		sum := md5.Sum([]byte(val))
+		labels[c.TargetLabel] = fmt.Sprint(binary.BigEndian.Uint64(sum[8:]) % c.Modulus)
+	case LabelMap:
+		// the labels are mapped in the order of names, so the result of the conflicting ones is stable
+		for _, name := range sortedNames(labels) {
+			if c.regex.MatchString(name) {
+				labels[c.regex.ReplaceAllString(name, c.replacement)] = labels[name]
+			}
+		}
+	case LabelDrop:
+		for name := range labels {
+			if c.regex.MatchString(name) {
+				delete(labels, name)
+			}
+		}
+	case LabelKeep:
+		for name := range labels {
+			if !c.regex.MatchString(name) {
+				delete(labels, name)
+			}
+		}
+	}
+	return true
+}
+
+func sortedNames(labels map[string]string) []string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
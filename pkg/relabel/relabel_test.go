@@ -0,0 +1,325 @@
+package relabel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+// the cases are of relabel_test.go of prometheus, the results must be the same
+func TestProcess(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		input   map[string]string
+		relabel []*Config
+		// nil if the labels are dropped
+		output map[string]string
+	}{
+		{
+			name:  "replace expands the groups in replacement",
+			input: map[string]string{"a": "foo", "b": "bar", "c": "baz"},
+			relabel: []*Config{{
+				SourceLabels: []string{"a"},
+				Regex:        "f(.*)",
+				TargetLabel:  "d",
+				Replacement:  stringPtr("ch${1}-ch${1}"),
+				Action:       Replace,
+			}},
+			output: map[string]string{"a": "foo", "b": "bar", "c": "baz", "d": "choo-choo"},
+		},
+		{
+			name:  "replace joins the source labels by separator",
+			input: map[string]string{"a": "foo", "b": "bar", "c": "baz"},
+			relabel: []*Config{
+				{
+					SourceLabels: []string{"a", "b"},
+					Regex:        "f(.*);(.*)r",
+					TargetLabel:  "a",
+					Replacement:  stringPtr("b${1}${2}m"),
+					Action:       Replace,
+				},
+				{
+					SourceLabels: []string{"c", "a"},
+					Regex:        "(b).*b(.*)ba(.*)",
+					TargetLabel:  "d",
+					Replacement:  stringPtr("$1$2$2$3"),
+					Action:       Replace,
+				},
+			},
+			output: map[string]string{"a": "boobam", "b": "bar", "c": "baz", "d": "boooom"},
+		},
+		{
+			name:  "replace skips the labels not matched",
+			input: map[string]string{"a": "foo"},
+			relabel: []*Config{{
+				SourceLabels: []string{"a"},
+				Regex:        "no-match",
+				TargetLabel:  "b",
+				Replacement:  stringPtr("bar"),
+				Action:       Replace,
+			}},
+			output: map[string]string{"a": "foo"},
+		},
+		{
+			name:  "replace anchors regex at both ends",
+			input: map[string]string{"a": "foo"},
+			relabel: []*Config{{
+				SourceLabels: []string{"a"},
+				Regex:        "o",
+				TargetLabel:  "b",
+				Replacement:  stringPtr("bar"),
+				Action:       Replace,
+			}},
+			output: map[string]string{"a": "foo"},
+		},
+		{
+			name:  "replace copies the value by default",
+			input: map[string]string{"a": "foo"},
+			relabel: []*Config{{
+				SourceLabels: []string{"a"},
+				TargetLabel:  "b",
+			}},
+			output: map[string]string{"a": "foo", "b": "foo"},
+		},
+		{
+			name:  "replace deletes target_label by the empty replacement",
+			input: map[string]string{"a": "foo", "b": "bar"},
+			relabel: []*Config{{
+				SourceLabels: []string{"a"},
+				Regex:        "f(.*)",
+				TargetLabel:  "b",
+				Replacement:  stringPtr(""),
+				Action:       Replace,
+			}},
+			output: map[string]string{"a": "foo"},
+		},
+		{
+			name:  "replace expands the groups in target_label",
+			input: map[string]string{"a": "some-name-value"},
+			relabel: []*Config{{
+				SourceLabels: []string{"a"},
+				Regex:        "some-([^-]+)-([^,]+)",
+				TargetLabel:  "${1}",
+				Replacement:  stringPtr("${2}"),
+				Action:       Replace,
+			}},
+			output: map[string]string{"a": "some-name-value", "name": "value"},
+		},
+		{
+			name:  "replace skips the invalid target_label",
+			input: map[string]string{"a": "some-name-value"},
+			relabel: []*Config{{
+				SourceLabels: []string{"a"},
+				Regex:        "some-([^-]+)-([^,]+)",
+				TargetLabel:  "${3}",
+				Replacement:  stringPtr("${1}"),
+				Action:       Replace,
+			}},
+			output: map[string]string{"a": "some-name-value"},
+		},
+		{
+			name:  "replace renames the metric by __name__",
+			input: map[string]string{"__name__": "http_requests_total", "job": "api"},
+			relabel: []*Config{{
+				SourceLabels: []string{"__name__"},
+				Regex:        "http_(.*)",
+				TargetLabel:  "__name__",
+				Replacement:  stringPtr("api_$1"),
+			}},
+			output: map[string]string{"__name__": "api_requests_total", "job": "api"},
+		},
+		{
+			name:  "keep drops the labels not matched",
+			input: map[string]string{"a": "foo"},
+			relabel: []*Config{{
+				SourceLabels: []string{"a"},
+				Regex:        "no-match",
+				Action:       Keep,
+			}},
+			output: nil,
+		},
+		{
+			name:  "keep anchors regex at both ends",
+			input: map[string]string{"a": "foo"},
+			relabel: []*Config{{
+				SourceLabels: []string{"a"},
+				Regex:        "f",
+				Action:       Keep,
+			}},
+			output: nil,
+		},
+		{
+			name:  "keep keeps the labels matched",
+			input: map[string]string{"a": "foo"},
+			relabel: []*Config{{
+				SourceLabels: []string{"a"},
+				Regex:        "f.*",
+				Action:       Keep,
+			}},
+			output: map[string]string{"a": "foo"},
+		},
+		{
+			name:  "drop drops the labels matched",
+			input: map[string]string{"a": "foo"},
+			relabel: []*Config{
+				{
+					SourceLabels: []string{"a"},
+					Regex:        ".*o.*",
+					Action:       Drop,
+				},
+				{
+					SourceLabels: []string{"a"},
+					Regex:        "f(.*)",
+					TargetLabel:  "d",
+					Replacement:  stringPtr("ch$1-ch$1"),
+					Action:       Replace,
+				},
+			},
+			output: nil,
+		},
+		{
+			name:  "drop matches the missing source label as empty",
+			input: map[string]string{"a": "foo"},
+			relabel: []*Config{{
+				SourceLabels: []string{"b"},
+				Action:       Drop,
+			}},
+			output: nil,
+		},
+		{
+			name:  "drop keeps the labels not matched",
+			input: map[string]string{"a": "foo"},
+			relabel: []*Config{{
+				SourceLabels: []string{"a"},
+				Regex:        "no-match",
+				Action:       Drop,
+			}},
+			output: map[string]string{"a": "foo"},
+		},
+		{
+			name:  "hashmod",
+			input: map[string]string{"a": "foo", "b": "bar", "c": "baz"},
+			relabel: []*Config{{
+				SourceLabels: []string{"c"},
+				TargetLabel:  "d",
+				Separator:    ";",
+				Action:       HashMod,
+				Modulus:      1000,
+			}},
+			output: map[string]string{"a": "foo", "b": "bar", "c": "baz", "d": "976"},
+		},
+		{
+			name:  "hashmod of the value with newline",
+			input: map[string]string{"a": "foo\nbar"},
+			relabel: []*Config{{
+				SourceLabels: []string{"a"},
+				TargetLabel:  "b",
+				Separator:    ";",
+				Action:       HashMod,
+				Modulus:      1000,
+			}},
+			output: map[string]string{"a": "foo\nbar", "b": "734"},
+		},
+		{
+			name:  "labelmap",
+			input: map[string]string{"a": "foo", "b1": "bar", "b2": "baz"},
+			relabel: []*Config{{
+				Regex:       "(b.*)",
+				Replacement: stringPtr("bar_${1}"),
+				Action:      LabelMap,
+			}},
+			output: map[string]string{"a": "foo", "b1": "bar", "b2": "baz", "bar_b1": "bar", "bar_b2": "baz"},
+		},
+		{
+			name:  "labelmap of the groups",
+			input: map[string]string{"__meta_my_bar": "aaa", "__meta_my_baz": "bbb", "__meta_other": "ccc"},
+			relabel: []*Config{{
+				Regex:       "__meta_(my.*)",
+				Replacement: stringPtr("${1}"),
+				Action:      LabelMap,
+			}},
+			output: map[string]string{"__meta_my_bar": "aaa", "__meta_my_baz": "bbb", "__meta_other": "ccc", "my_bar": "aaa", "my_baz": "bbb"},
+		},
+		{
+			name:  "labeldrop",
+			input: map[string]string{"a": "foo", "b1": "bar", "b2": "baz"},
+			relabel: []*Config{{
+				Regex:  "(b.*)",
+				Action: LabelDrop,
+			}},
+			output: map[string]string{"a": "foo"},
+		},
+		{
+			name:  "labelkeep",
+			input: map[string]string{"a": "foo", "b1": "bar", "b2": "baz"},
+			relabel: []*Config{{
+				Regex:  "(b.*)",
+				Action: LabelKeep,
+			}},
+			output: map[string]string{"b1": "bar", "b2": "baz"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, c := range tc.relabel {
+				require.NoError(t, c.Validate())
+			}
+			labels := make(map[string]string, len(tc.input))
+			for k, v := range tc.input {
+				labels[k] = v
+			}
+
+			kept := Process(labels, tc.relabel)
+			if tc.output == nil {
+				assert.False(t, kept)
+				return
+			}
+			assert.True(t, kept)
+			assert.Equal(t, tc.output, labels)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		cfg  *Config
+		err  string
+	}{
+		{
+			name: "replace without target_label",
+			cfg:  &Config{SourceLabels: []string{"a"}},
+			err:  "target_label is required by relabel action replace",
+		},
+		{
+			name: "hashmod without modulus",
+			cfg:  &Config{SourceLabels: []string{"a"}, TargetLabel: "b", Action: HashMod},
+			err:  "modulus is required by relabel action hashmod",
+		},
+		{
+			name: "labeldrop with source_labels",
+			cfg:  &Config{SourceLabels: []string{"a"}, Action: LabelDrop},
+			err:  "source_labels and target_label are not allowed by relabel action labeldrop",
+		},
+		{
+			name: "invalid regex",
+			cfg:  &Config{Regex: "(", Action: Keep},
+			err:  `invalid regex "(" of relabel action keep`,
+		},
+		{
+			name: "unknown action",
+			cfg:  &Config{Action: "rename"},
+			err:  `unknown relabel action "rename"`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.cfg.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.err)
+		})
+	}
+}
@@ -0,0 +1,258 @@
+package scaffold
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+const importPrefix = "\t_ \"flashcat.cloud/categraf/inputs/"
+
+var nameRegexp = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+type plugin struct {
+	Name   string
+	Struct string
+}
+
+// NewInput creates the skeleton of input name under the root of the repository, i.e.
+// the sources with a test, the readme and the sample config, and registers it in agent/metrics_agent.go
+func NewInput(root, name string) ([]string, error) {
+	if !nameRegexp.MatchString(name) {
+		return nil, fmt.Errorf("invalid name of input: %s, lower case letters, digits and underscores are allowed", name)
+	}
+
+	agentFile := filepath.Join(root, "agent", "metrics_agent.go")
+	if _, err := os.Stat(agentFile); err != nil {
+		return nil, fmt.Errorf("%s is not the root of categraf repository: %v", root, err)
+	}
+
+	dir := filepath.Join(root, "inputs", name)
+	if _, err := os.Stat(dir); err == nil {
+		return nil, fmt.Errorf("input %s exists: %s", name, dir)
+	}
+
+	p := plugin{Name: name, Struct: structName(name)}
+	files := []struct {
+		path string
+		tpl  string
+	}{
+		{filepath.Join(dir, name+".go"), inputTemplate},
+		{filepath.Join(dir, name+"_test.go"), testTemplate},
+		{filepath.Join(dir, "README.md"), readmeTemplate},
+		{filepath.Join(root, "conf", "input."+name, name+".toml"), configTemplate},
+	}
+
+	created := make([]string, 0, len(files)+1)
+	for _, f := range files {
+		var buf bytes.Buffer
+		if err := template.Must(template.New(f.path).Parse(f.tpl)).Execute(&buf, p); err != nil {
+			return created, err
+		}
+
+		if err := os.MkdirAll(filepath.Dir(f.path), 0755); err != nil {
+			return created, err
+		}
+
+		if err := os.WriteFile(f.path, buf.Bytes(), 0644); err != nil {
+			return created, err
+		}
+		created = append(created, f.path)
+	}
+
+	if err := addImport(agentFile, name); err != nil {
+		return created, err
+	}
+	return append(created, agentFile), nil
+}
+
+// addImport inserts the blank import of input name in order
+func addImport(file, name string) error {
+	bs, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	line := importPrefix + name + "\""
+	lines := strings.Split(string(bs), "\n")
+	at, last := -1, -1
+	for i, l := range lines {
+		if !strings.HasPrefix(l, importPrefix) {
+			continue
+		}
+		last = i
+		if at < 0 && l > line {
+			at = i
+		}
+	}
+
+	if last < 0 {
+		return fmt.Errorf("imports of inputs not found in %s", file)
+	}
+
+	if at < 0 {
+		at = last + 1
+	}
+
+	lines = append(lines[:at], append([]string{line}, lines[at:]...)...)
+	return os.WriteFile(file, []byte(strings.Join(lines, "\n")), 0644)
+}
+
+// structName converts my_input to MyInput
+func structName(name string) string {
+	var b strings.Builder
+	for _, s := range strings.Split(name, "_") {
+		if s != "" {
+			b.WriteString(strings.ToUpper(s[:1]) + s[1:])
+		}
+	}
+	return b.String()
+}
+
+const inputTemplate = `package {{.Name}}
+
+import (
+	"log"
+	"time"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/inputs"
+	"flashcat.cloud/categraf/types"
+)
+
+const inputName = "{{.Name}}"
+
+type {{.Struct}} struct {
+	config.PluginConfig
+	Instances []*Instance ` + "`toml:\"instances\"`" + `
+}
+
+func init() {
+	inputs.Add(inputName, func() inputs.Input {
+		return &{{.Struct}}{}
+	})
+}
+
+func (p *{{.Struct}}) Clone() inputs.Input {
+	return &{{.Struct}}{}
+}
+
+func (p *{{.Struct}}) Name() string {
+	return inputName
+}
+
+func (p *{{.Struct}}) GetInstances() []inputs.Instance {
+	ret := make([]inputs.Instance, len(p.Instances))
+	for i := 0; i < len(p.Instances); i++ {
+		ret[i] = p.Instances[i]
+	}
+	return ret
+}
+
+type Instance struct {
+	config.InstanceConfig
+
+	// TODO: the options of an instance, e.g. the address of the target
+	Targets []string        ` + "`toml:\"targets\"`" + `
+	Timeout config.Duration ` + "`toml:\"timeout\"`" + `
+}
+
+func (ins *Instance) Init() error {
+	// the instances without targets are skipped quietly
+	if len(ins.Targets) == 0 {
+		return types.ErrInstancesEmpty
+	}
+
+	if ins.Timeout == 0 {
+		ins.Timeout = config.Duration(5 * time.Second)
+	}
+	return nil
+}
+
+func (ins *Instance) Gather(slist *types.SampleList) {
+	for _, target := range ins.Targets {
+		tags := map[string]string{"target": target}
+
+		// TODO: gather the metrics of target
+		if err := ins.gather(slist, target, tags); err != nil {
+			log.Println("E! failed to gather {{.Name}} target:", target, "error:", err)
+			slist.PushSample(inputName, "up", 0, tags)
+			continue
+		}
+		slist.PushSample(inputName, "up", 1, tags)
+	}
+}
+
+func (ins *Instance) gather(slist *types.SampleList, target string, tags map[string]string) error {
+	return nil
+}
+`
+
+const testTemplate = `package {{.Name}}
+
+import (
+	"testing"
+
+	"flashcat.cloud/categraf/types"
+)
+
+func TestGather(t *testing.T) {
+	ins := &Instance{Targets: []string{"localhost"}}
+	if err := ins.Init(); err != nil {
+		t.Fatal(err)
+	}
+
+	slist := types.NewSampleList()
+	ins.Gather(slist)
+
+	samples := slist.PopBackAll()
+	if len(samples) == 0 {
+		t.Fatal("no samples gathered")
+	}
+
+	for _, s := range samples {
+		if s.Metric == inputName+"_up" && s.Value != 1 {
+			t.Errorf("target %s is down", s.Labels["target"])
+		}
+	}
+}
+`
+
+const readmeTemplate = `# {{.Name}}
+
+TODO: 插件的用途、原理和依赖的权限。
+
+## Configuration
+
+` + "```toml" + `
+[[instances]]
+targets = ["localhost"]
+# timeout = "5s"
+` + "```" + `
+
+## 指标
+
+| 指标 | 说明 |
+| --- | --- |
+| {{.Name}}_up | 采集是否成功，标签 target |
+`
+
+const configTemplate = `# # collect interval
+# interval = 15
+
+[[instances]]
+# # the targets to gather
+# targets = ["localhost"]
+
+# # timeout of each target
+# timeout = "5s"
+
+# # interval = global.interval * interval_times
+# interval_times = 1
+
+# labels = { }
+`
@@ -0,0 +1,189 @@
+// Package spool keeps the payloads failed to send on disk, e.g. the remote writes of writers and the logs,
+// so they survive the outages of backends and the restarts of categraf
+package spool
+
+import (
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"flashcat.cloud/categraf/pkg/logger"
+)
+
+var spoolLog = logger.New("spool")
+
+const (
+	DefaultMaxSizeMB = 512
+	ReplayInterval   = 5 * time.Second
+)
+
+// Spool keeps the payloads failed to send on disk, one file per payload,
+// files are replayed oldest first and the oldest are evicted when the size exceeds maxSize
+type Spool struct {
+	sync.Mutex
+	dir     string
+	suffix  string
+	maxSize int64
+	size    int64
+	files   []spoolFile
+	// the timestamp of the newest file, the names are kept in order even if the clock steps back
+	last int64
+}
+
+type spoolFile struct {
+	name string
+	size int64
+}
+
+// Dir returns the spool dir of a destination under base, e.g. of the url of a writer
+func Dir(base, destination string) string {
+	h := fnv.New64a()
+	h.Write([]byte(destination))
+	return filepath.Join(base, strconv.FormatUint(h.Sum64(), 16))
+}
+
+// Open opens the spool dir of the files of suffix, e.g. .snappy, payloads spooled before restart are kept
+func Open(dir, suffix string, maxSize int64) (*Spool, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Spool{dir: dir, suffix: suffix, maxSize: maxSize}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		// the payloads were being written when categraf exited
+		if strings.HasSuffix(e.Name(), suffix+".tmp") {
+			if err := os.Remove(filepath.Join(dir, e.Name())); err != nil {
+				spoolLog.Warnf("failed to remove partial payload: %v", err)
+			}
+			continue
+		}
+		if !strings.HasSuffix(e.Name(), suffix) {
+			continue
+		}
+		s.files = append(s.files, spoolFile{name: e.Name(), size: e.Size()})
+		s.size += e.Size()
+	}
+
+	// file names are zero padded timestamps
+	sort.Slice(s.files, func(i, j int) bool {
+		return s.files[i].name < s.files[j].name
+	})
+
+	// the new payloads are named after the newest one, e.g. if the clock is behind after restart
+	if len(s.files) > 0 {
+		newest := s.files[len(s.files)-1].name
+		if i := strings.IndexByte(newest, '.'); i > 0 {
+			newest = newest[:i]
+		}
+		if ts, err := strconv.ParseInt(newest, 10, 64); err == nil {
+			s.last = ts
+		}
+	}
+
+	if len(s.files) > 0 {
+		spoolLog.Infof("spool %s: %d payloads of %d bytes to resend", dir, len(s.files), s.size)
+	}
+	return s, nil
+}
+
+// Put spools a payload, the key is kept in the file name, e.g. the tenant of 01700000000000000000.74656e616e742d61.snappy
+func (s *Spool) Put(data []byte, key string) error {
+	s.Lock()
+	defer s.Unlock()
+
+	ts := time.Now().UnixNano()
+	if ts <= s.last {
+		ts = s.last + 1
+	}
+	s.last = ts
+
+	name := fmt.Sprintf("%020d%s", ts, s.suffix)
+	if key != "" {
+		name = fmt.Sprintf("%020d.%s%s", ts, hex.EncodeToString([]byte(key)), s.suffix)
+	}
+	tmp := filepath.Join(s.dir, name+".tmp")
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, filepath.Join(s.dir, name)); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	s.files = append(s.files, spoolFile{name: name, size: int64(len(data))})
+	s.size += int64(len(data))
+
+	for s.size > s.maxSize && len(s.files) > 1 {
+		spoolLog.Warnf("spool %s exceeds %d bytes, evict the oldest payload %s", s.dir, s.maxSize, s.files[0].name)
+		s.removeLocked(s.files[0].name)
+	}
+	return nil
+}
+
+// Oldest returns the name of the oldest spooled payload, its key and the payload, name is empty if nothing spooled
+func (s *Spool) Oldest() (string, string, []byte, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if len(s.files) == 0 {
+		return "", "", nil, nil
+	}
+
+	name := s.files[0].name
+	var key string
+	if parts := strings.SplitN(strings.TrimSuffix(name, s.suffix), ".", 2); len(parts) == 2 {
+		bs, err := hex.DecodeString(parts[1])
+		if err != nil {
+			return name, "", nil, fmt.Errorf("invalid key in name: %v", err)
+		}
+		key = string(bs)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(s.dir, name))
+	return name, key, data, err
+}
+
+// Remove removes the spooled payload, e.g. once it's resent
+func (s *Spool) Remove(name string) {
+	s.Lock()
+	s.removeLocked(name)
+	s.Unlock()
+}
+
+func (s *Spool) removeLocked(name string) {
+	for i := range s.files {
+		if s.files[i].name != name {
+			continue
+		}
+		s.size -= s.files[i].size
+		s.files = append(s.files[:i], s.files[i+1:]...)
+		break
+	}
+
+	if err := os.Remove(filepath.Join(s.dir, name)); err != nil && !os.IsNotExist(err) {
+		spoolLog.Warnf("failed to remove spooled payload: %v", err)
+	}
+}
+
+// Len returns the number of spooled payloads
+func (s *Spool) Len() int {
+	s.Lock()
+	defer s.Unlock()
+	return len(s.files)
+}
@@ -0,0 +1,187 @@
+// Package state is a small file based store, stateful inputs persist their state here
+// (e.g. last counter values, index maps, offsets), so a restart doesn't produce rate spikes or duplicate work.
+//
+// Every namespace is saved as a json file in the state directory, written atomically on Flush.
+// The states are small and read once at start, so the files are rewritten whole instead of kept in an embedded db.
+// The store works in memory only if it's not initialized or the directory is not writable.
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"flashcat.cloud/categraf/pkg/logger"
+)
+
+var stateLog = logger.New("state")
+
+const flushInterval = 10 * time.Second
+
+type entry struct {
+	Value   json.RawMessage `json:"value"`
+	SavedAt int64           `json:"saved_at"`
+}
+
+type Store struct {
+	sync.Mutex
+	dir   string
+	data  map[string]map[string]entry
+	dirty map[string]bool
+	quit  chan struct{}
+}
+
+var defaultStore = &Store{
+	data:  make(map[string]map[string]entry),
+	dirty: make(map[string]bool),
+}
+
+// Init loads the state files in dir and starts flushing periodically
+func Init(dir string) error {
+	if dir == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create state dir %s: %v", dir, err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+
+	s := defaultStore
+	s.Lock()
+	defer s.Unlock()
+
+	s.dir = dir
+	for _, f := range files {
+		bs, err := os.ReadFile(f)
+		if err != nil {
+			stateLog.Warnf("failed to read state file: %s error: %v", f, err)
+			continue
+		}
+
+		m := make(map[string]entry)
+		if err = json.Unmarshal(bs, &m); err != nil {
+			stateLog.Warnf("failed to parse state file: %s error: %v", f, err)
+			continue
+		}
+		s.data[strings.TrimSuffix(filepath.Base(f), ".json")] = m
+	}
+
+	if s.quit == nil {
+		s.quit = make(chan struct{})
+		go s.loopFlush()
+	}
+	return nil
+}
+
+// Get decodes the value of key in namespace into v, returns false if not found or older than maxAge (0 means no limit)
+func Get(namespace, key string, v interface{}, maxAge time.Duration) bool {
+	s := defaultStore
+	s.Lock()
+	e, has := s.data[namespace][key]
+	s.Unlock()
+
+	if !has {
+		return false
+	}
+
+	if maxAge > 0 && time.Since(time.Unix(e.SavedAt, 0)) > maxAge {
+		return false
+	}
+
+	if err := json.Unmarshal(e.Value, v); err != nil {
+		stateLog.Warnf("failed to decode state: %s %s error: %v", namespace, key, err)
+		return false
+	}
+	return true
+}
+
+// Put saves v as the value of key in namespace, it's written to disk on the next flush
+func Put(namespace, key string, v interface{}) error {
+	bs, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	s := defaultStore
+	s.Lock()
+	defer s.Unlock()
+
+	m, has := s.data[namespace]
+	if !has {
+		m = make(map[string]entry)
+		s.data[namespace] = m
+	}
+	m[key] = entry{Value: bs, SavedAt: time.Now().Unix()}
+	s.dirty[namespace] = true
+	return nil
+}
+
+// Delete removes key in namespace
+func Delete(namespace, key string) {
+	s := defaultStore
+	s.Lock()
+	defer s.Unlock()
+
+	if _, has := s.data[namespace][key]; has {
+		delete(s.data[namespace], key)
+		s.dirty[namespace] = true
+	}
+}
+
+// Flush writes the changed namespaces to disk
+func Flush() {
+	defaultStore.flush()
+}
+
+func (s *Store) loopFlush() {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.quit:
+			return
+		case <-ticker.C:
+			s.flush()
+		}
+	}
+}
+
+func (s *Store) flush() {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.dir == "" {
+		return
+	}
+
+	for ns := range s.dirty {
+		if err := s.write(ns); err != nil {
+			stateLog.Errorf("failed to write state of %s error: %v", ns, err)
+			continue
+		}
+		delete(s.dirty, ns)
+	}
+}
+
+func (s *Store) write(namespace string) error {
+	bs, err := json.Marshal(s.data[namespace])
+	if err != nil {
+		return err
+	}
+
+	file := filepath.Join(s.dir, namespace+".json")
+	tmp := file + ".tmp"
+	if err = os.WriteFile(tmp, bs, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, file)
+}
@@ -4,8 +4,11 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
+	"log"
 	"os"
 	"strings"
+	"sync"
+	"time"
 
 	"flashcat.cloud/categraf/pkg/choice"
 )
@@ -199,3 +202,58 @@ func (c *ServerConfig) verifyPeerCertificate(rawCerts [][]byte, verifiedChains [
 
 	return fmt.Errorf("peer certificate not in allowed DNS Name list: %v", cert.DNSNames)
 }
+
+// ReloadCertificate reloads the client certificate of config in handshakes once the files are
+// modified, e.g. the certificates rotated by cert-manager, the last one is kept if the reloading fails
+func ReloadCertificate(config *tls.Config, certFile, keyFile string) error {
+	r := &certReloader{certFile: certFile, keyFile: keyFile}
+	if err := r.reload(); err != nil {
+		return err
+	}
+
+	config.Certificates = nil
+	config.GetClientCertificate = r.get
+	return nil
+}
+
+type certReloader struct {
+	sync.Mutex
+	certFile string
+	keyFile  string
+	modTime  time.Time
+	cert     *tls.Certificate
+}
+
+func (r *certReloader) get(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.lastModified().After(r.modTime) {
+		if err := r.reload(); err != nil {
+			log.Println("W! failed to reload client certificate, the last one is used:", err)
+		}
+	}
+	return r.cert, nil
+}
+
+func (r *certReloader) lastModified() time.Time {
+	var last time.Time
+	for _, f := range []string{r.certFile, r.keyFile} {
+		if st, err := os.Stat(f); err == nil && st.ModTime().After(last) {
+			last = st.ModTime()
+		}
+	}
+	return last
+}
+
+func (r *certReloader) reload() error {
+	modTime := r.lastModified()
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("could not load keypair %s:%s: %v", r.certFile, r.keyFile, err)
+	}
+
+	r.cert = &cert
+	r.modTime = modTime
+	return nil
+}
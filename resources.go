@@ -0,0 +1,85 @@
+package main
+
+import (
+	"math"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/writer"
+)
+
+const defaultWatchdogInterval = 10 * time.Second
+
+var resourcesLog = logger.New("resources")
+
+// initResources limits the memory and cpus of categraf, see config.Resources
+func initResources() {
+	conf := config.Config.Resources
+	if conf == nil {
+		return
+	}
+
+	// the environment variables of go runtime take precedence
+	if conf.MemoryLimitMB > 0 && os.Getenv("GOMEMLIMIT") == "" {
+		debug.SetMemoryLimit(conf.MemoryLimitMB * 1024 * 1024)
+		resourcesLog.Infof("memory limit of go runtime: %d MiB", conf.MemoryLimitMB)
+	}
+
+	procs := conf.MaxProcs
+	if procs <= 0 && conf.Cgroup != "" && conf.CgroupCPUs > 0 {
+		procs = int(math.Ceil(conf.CgroupCPUs))
+	}
+	if procs > 0 && os.Getenv("GOMAXPROCS") == "" {
+		runtime.GOMAXPROCS(procs)
+		resourcesLog.Infof("max procs of go runtime: %d", procs)
+	}
+
+	if conf.Cgroup != "" {
+		if err := placeCgroup(conf.Cgroup, conf.CgroupCPUs, conf.CgroupMemoryMB); err != nil {
+			resourcesLog.Errorf("failed to move categraf into cgroup: %s error: %v", conf.Cgroup, err)
+		} else {
+			resourcesLog.Infof("categraf is moved into cgroup: %s", conf.Cgroup)
+		}
+	}
+
+	if conf.RSSThresholdMB > 0 {
+		interval := time.Duration(conf.WatchdogInterval)
+		if interval <= 0 {
+			interval = defaultWatchdogInterval
+		}
+		go watchMemory(conf.RSSThresholdMB*1024*1024, interval)
+	}
+}
+
+// watchMemory drops the caches and flushes the buffers once rss is above threshold
+func watchMemory(threshold int64, interval time.Duration) {
+	p, err := process.NewProcess(int32(os.Getpid()))
+	if err != nil {
+		resourcesLog.Errorf("failed to watch memory of categraf: %v", err)
+		return
+	}
+
+	for {
+		time.Sleep(interval)
+
+		mem, err := p.MemoryInfo()
+		if err != nil {
+			resourcesLog.Warnf("failed to get memory of categraf: %v", err)
+			continue
+		}
+
+		if int64(mem.RSS) < threshold {
+			continue
+		}
+
+		writer.FreeMemory()
+		debug.FreeOSMemory()
+		resourcesLog.Warnf("rss %d MiB is above rss_threshold_mb %d, caches dropped and buffers flushed", mem.RSS>>20, threshold>>20)
+	}
+}
@@ -0,0 +1,51 @@
+//go:build linux
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+const (
+	cgroupRoot = "/sys/fs/cgroup"
+	// the period of cpu.max in microseconds
+	cgroupCPUPeriod = 100000
+)
+
+// placeCgroup creates the cgroup (v2) of path with the limits, and moves categraf into it
+func placeCgroup(path string, cpus float64, memoryMB int64) error {
+	if _, err := os.Stat(filepath.Join(cgroupRoot, "cgroup.controllers")); err != nil {
+		return fmt.Errorf("cgroup v2 is not mounted on %s", cgroupRoot)
+	}
+
+	dir := path
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(cgroupRoot, path)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	// the controllers are enabled by the parent, which may be done already
+	os.WriteFile(filepath.Join(filepath.Dir(dir), "cgroup.subtree_control"), []byte("+cpu +memory"), 0644)
+
+	if cpus > 0 {
+		quota := strconv.Itoa(int(cpus*cgroupCPUPeriod)) + " " + strconv.Itoa(cgroupCPUPeriod)
+		if err := os.WriteFile(filepath.Join(dir, "cpu.max"), []byte(quota), 0644); err != nil {
+			return fmt.Errorf("failed to limit cpu: %v", err)
+		}
+	}
+
+	if memoryMB > 0 {
+		max := strconv.FormatInt(memoryMB*1024*1024, 10)
+		if err := os.WriteFile(filepath.Join(dir, "memory.max"), []byte(max), 0644); err != nil {
+			return fmt.Errorf("failed to limit memory: %v", err)
+		}
+	}
+
+	return os.WriteFile(filepath.Join(dir, "cgroup.procs"), []byte(strconv.Itoa(os.Getpid())), 0644)
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package main
+
+import "fmt"
+
+func placeCgroup(path string, cpus float64, memoryMB int64) error {
+	return fmt.Errorf("cgroup is only supported on linux")
+}
@@ -0,0 +1,25 @@
+#!/bin/sh
+# prints agent/inputs_minimal_extra.go, which registers the inputs given besides
+# the ones of agent/inputs_minimal.go into the minimal build, e.g.
+#   scripts/minimal_inputs.sh redis mysql > agent/inputs_minimal_extra.go
+
+cd "$(dirname "$0")/.." || exit 1
+
+echo "//go:build minimal"
+echo
+echo "// Code generated by scripts/minimal_inputs.sh. DO NOT EDIT."
+echo
+echo "package agent"
+
+[ $# -eq 0 ] && exit 0
+
+echo
+echo "import ("
+for name in "$@"; do
+	if [ ! -d "inputs/$name" ]; then
+		echo "unknown input: $name" >&2
+		exit 1
+	fi
+	echo "	_ \"flashcat.cloud/categraf/inputs/$name\""
+done
+echo ")"
@@ -0,0 +1,67 @@
+package carbon
+
+import (
+	"bytes"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/prompb"
+
+	"flashcat.cloud/categraf/types"
+)
+
+// the characters not allowed in the names and the tags of graphite
+var replacer = strings.NewReplacer(" ", "_", ";", "_", "=", "_", "~", "_", "!", "_", "^", "_")
+
+// Serializer encodes the series in the plaintext protocol of graphite with tags (graphite 1.1+), e.g.
+// cpu_usage_idle;cpu=cpu-total;ident=web01 98.5 1700000000, the timestamps are in seconds
+type Serializer struct {
+	precision string
+}
+
+func NewSerializer(precision string) *Serializer {
+	return &Serializer{precision: precision}
+}
+
+func (s *Serializer) Serialize(series []prompb.TimeSeries) ([]byte, error) {
+	var buf bytes.Buffer
+	for i := range series {
+		path := s.path(series[i].Labels)
+		for _, v := range series[i].Samples {
+			if math.IsNaN(v.Value) || math.IsInf(v.Value, 0) {
+				continue
+			}
+			buf.WriteString(path)
+			buf.WriteByte(' ')
+			buf.WriteString(strconv.FormatFloat(v.Value, 'f', -1, 64))
+			buf.WriteByte(' ')
+			buf.WriteString(strconv.FormatInt(types.TimeOf(v.Timestamp, s.precision).Unix(), 10))
+			buf.WriteByte('\n')
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *Serializer) path(labels []prompb.Label) string {
+	var name string
+	tags := make([]string, 0, len(labels))
+	for _, l := range labels {
+		if l.Name == model.MetricNameLabel {
+			name = replacer.Replace(l.Value)
+		} else if l.Value != "" {
+			tags = append(tags, replacer.Replace(l.Name)+"="+replacer.Replace(l.Value))
+		}
+	}
+	sort.Strings(tags)
+	if len(tags) == 0 {
+		return name
+	}
+	return name + ";" + strings.Join(tags, ";")
+}
+
+func (s *Serializer) Headers() map[string]string {
+	return map[string]string{"Content-Type": "text/plain; charset=utf-8"}
+}
@@ -0,0 +1,65 @@
+package influx
+
+import (
+	"sort"
+
+	"github.com/influxdata/line-protocol/v2/lineprotocol"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/prompb"
+
+	"flashcat.cloud/categraf/types"
+)
+
+const fieldKey = "value"
+
+// Serializer encodes the series in influx line protocol, the measurement is the name of metric,
+// with the field value, the timestamps are in ns, e.g. for /api/v2/write of influxdb
+type Serializer struct {
+	precision string
+}
+
+func NewSerializer(precision string) *Serializer {
+	return &Serializer{precision: precision}
+}
+
+func (s *Serializer) Serialize(series []prompb.TimeSeries) ([]byte, error) {
+	var enc lineprotocol.Encoder
+	enc.SetPrecision(lineprotocol.Nanosecond)
+
+	for i := range series {
+		name, labels := splitName(series[i].Labels)
+		for _, sample := range series[i].Samples {
+			value, ok := lineprotocol.FloatValue(sample.Value)
+			if !ok {
+				// NaN and Inf are not supported by line protocol
+				continue
+			}
+			enc.StartLine(name)
+			for _, l := range labels {
+				enc.AddTag(l.Name, l.Value)
+			}
+			enc.AddField(fieldKey, value)
+			enc.EndLine(types.TimeOf(sample.Timestamp, s.precision))
+		}
+	}
+	return enc.Bytes(), enc.Err()
+}
+
+func (s *Serializer) Headers() map[string]string {
+	return map[string]string{"Content-Type": "text/plain; charset=utf-8"}
+}
+
+// splitName returns the name of metric and the other labels sorted, as line protocol requires
+func splitName(labels []prompb.Label) (string, []prompb.Label) {
+	var name string
+	ret := make([]prompb.Label, 0, len(labels))
+	for _, l := range labels {
+		if l.Name == model.MetricNameLabel {
+			name = l.Value
+		} else if l.Value != "" {
+			ret = append(ret, l)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Name < ret[j].Name })
+	return name, ret
+}
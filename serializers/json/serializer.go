@@ -0,0 +1,52 @@
+package json
+
+import (
+	"encoding/json"
+	"math"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/prompb"
+)
+
+// Serializer encodes the series as an array of samples, e.g.
+// [{"metric":"cpu_usage_idle","labels":{"cpu":"cpu-total"},"timestamp":1700000000000,"value":98.5}],
+// the timestamps are in the precision of the writer
+type Serializer struct{}
+
+func NewSerializer() *Serializer {
+	return &Serializer{}
+}
+
+type sample struct {
+	Metric    string            `json:"metric"`
+	Labels    map[string]string `json:"labels"`
+	Timestamp int64             `json:"timestamp"`
+	Value     float64           `json:"value"`
+}
+
+func (s *Serializer) Serialize(series []prompb.TimeSeries) ([]byte, error) {
+	samples := make([]sample, 0, len(series))
+	for i := range series {
+		var name string
+		labels := make(map[string]string, len(series[i].Labels))
+		for _, l := range series[i].Labels {
+			if l.Name == model.MetricNameLabel {
+				name = l.Value
+			} else {
+				labels[l.Name] = l.Value
+			}
+		}
+		for _, v := range series[i].Samples {
+			// NaN and Inf are not supported by json
+			if math.IsNaN(v.Value) || math.IsInf(v.Value, 0) {
+				continue
+			}
+			samples = append(samples, sample{Metric: name, Labels: labels, Timestamp: v.Timestamp, Value: v.Value})
+		}
+	}
+	return json.Marshal(samples)
+}
+
+func (s *Serializer) Headers() map[string]string {
+	return map[string]string{"Content-Type": "application/json"}
+}
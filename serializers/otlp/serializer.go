@@ -0,0 +1,58 @@
+package otlp
+
+import (
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/prompb"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/types"
+)
+
+// Serializer encodes the series as the gauges of an OTLP/HTTP protobuf request,
+// e.g. for /v1/metrics of opentelemetry collector, the labels are the attributes of the data points
+type Serializer struct {
+	precision string
+}
+
+func NewSerializer(precision string) *Serializer {
+	return &Serializer{precision: precision}
+}
+
+func (s *Serializer) Serialize(series []prompb.TimeSeries) ([]byte, error) {
+	md := pmetric.NewMetrics()
+	sm := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty()
+	sm.Scope().SetName("categraf")
+	sm.Scope().SetVersion(config.Version)
+
+	metrics := sm.Metrics()
+	metrics.EnsureCapacity(len(series))
+	for i := range series {
+		m := metrics.AppendEmpty()
+		m.SetDataType(pmetric.MetricDataTypeGauge)
+
+		attrs := pcommon.NewMap()
+		for _, l := range series[i].Labels {
+			if l.Name == model.MetricNameLabel {
+				m.SetName(l.Value)
+			} else {
+				attrs.UpsertString(l.Name, l.Value)
+			}
+		}
+
+		points := m.Gauge().DataPoints()
+		for _, v := range series[i].Samples {
+			dp := points.AppendEmpty()
+			dp.SetDoubleVal(v.Value)
+			dp.SetTimestamp(pcommon.NewTimestampFromTime(types.TimeOf(v.Timestamp, s.precision)))
+			attrs.CopyTo(dp.Attributes())
+		}
+	}
+	return pmetricotlp.NewRequestFromMetrics(md).MarshalProto()
+}
+
+func (s *Serializer) Headers() map[string]string {
+	return map[string]string{"Content-Type": "application/x-protobuf"}
+}
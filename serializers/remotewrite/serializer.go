@@ -0,0 +1,47 @@
+package remotewrite
+
+import (
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+)
+
+// Serializer encodes the series in prometheus remote write 1.0, i.e. snappy compressed protobuf
+type Serializer struct{}
+
+func NewSerializer() *Serializer {
+	return &Serializer{}
+}
+
+func (s *Serializer) Serialize(series []prompb.TimeSeries) ([]byte, error) {
+	return Encode(series)
+}
+
+func (s *Serializer) Headers() map[string]string {
+	return map[string]string{
+		"Content-Encoding":                  "snappy",
+		"Content-Type":                      "application/x-protobuf",
+		"X-Prometheus-Remote-Write-Version": "0.1.0",
+	}
+}
+
+func Encode(series []prompb.TimeSeries) ([]byte, error) {
+	data, err := proto.Marshal(&prompb.WriteRequest{Timeseries: series})
+	if err != nil {
+		return nil, err
+	}
+	return snappy.Encode(nil, data), nil
+}
+
+func Decode(data []byte) ([]prompb.TimeSeries, error) {
+	raw, err := snappy.Decode(nil, data)
+	if err != nil {
+		return nil, err
+	}
+
+	var req prompb.WriteRequest
+	if err := proto.Unmarshal(raw, &req); err != nil {
+		return nil, err
+	}
+	return req.Timeseries, nil
+}
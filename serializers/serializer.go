@@ -0,0 +1,48 @@
+// Package serializers encodes the series of the requests of writers in the wire formats of the backends,
+// so a writer only sends the payloads.
+package serializers
+
+import (
+	"fmt"
+
+	"github.com/prometheus/prometheus/prompb"
+
+	"flashcat.cloud/categraf/serializers/carbon"
+	"flashcat.cloud/categraf/serializers/influx"
+	"flashcat.cloud/categraf/serializers/json"
+	"flashcat.cloud/categraf/serializers/remotewrite"
+)
+
+type Serializer interface {
+	// Serialize returns the payload of a request, the timestamps of series are in the precision of the writer
+	Serialize(series []prompb.TimeSeries) ([]byte, error)
+	// Headers returns the headers of the requests, e.g. Content-Type
+	Headers() map[string]string
+}
+
+const RemoteWrite = "remote_write"
+
+// creators of the formats, some of which are left out by build tags, e.g. otlp of the minimal build
+var creators = map[string]func(precision string) Serializer{}
+
+func add(format string, creator func(precision string) Serializer) {
+	creators[format] = creator
+}
+
+func init() {
+	add(RemoteWrite, func(string) Serializer { return remotewrite.NewSerializer() })
+	add("influx", func(precision string) Serializer { return influx.NewSerializer(precision) })
+	add("json", func(string) Serializer { return json.NewSerializer() })
+	add("carbon", func(precision string) Serializer { return carbon.NewSerializer(precision) })
+}
+
+// New returns the serializer of format, remote_write by default
+func New(format, precision string) (Serializer, error) {
+	if format == "" {
+		format = RemoteWrite
+	}
+	if creator, has := creators[format]; has {
+		return creator(precision), nil
+	}
+	return nil, fmt.Errorf("format(%s) not supported", format)
+}
@@ -0,0 +1,9 @@
+//go:build !minimal
+
+package serializers
+
+import "flashcat.cloud/categraf/serializers/otlp"
+
+func init() {
+	add("otlp", func(precision string) Serializer { return otlp.NewSerializer(precision) })
+}
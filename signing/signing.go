@@ -0,0 +1,240 @@
+package signing
+
+import (
+	"bufio"
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/pkg/logger"
+)
+
+var signingLog = logger.New("signing")
+
+// Header carries the signature of a request, e.g.
+// keyId="k2",algorithm="hmac-sha256",timestamp="1700000000",host="web01",signature="base64"
+//
+// the signed string is the lines of the method, the path with query, the timestamp, the host,
+// and the hex sha256 of the body as sent, i.e. compressed if it's encoded
+const Header = "X-Categraf-Signature"
+
+// the key files are checked for changes at most once in it
+const reloadInterval = time.Minute
+
+type signer struct {
+	sync.Mutex
+	conf *config.Signing
+
+	keyID     string
+	algorithm string
+	hmacKey   []byte
+	key       crypto.Signer
+
+	checked  time.Time
+	modTimes map[string]time.Time
+}
+
+var s *signer
+
+// Init loads the keys of config.Config.Signing, Sign is a no-op if signing is disabled
+func Init() error {
+	conf := config.Config.Signing
+	if conf == nil || !conf.Enable {
+		return nil
+	}
+
+	switch conf.Mode {
+	case "", "hmac":
+		conf.Mode = "hmac"
+		if conf.KeyFile == "" && (conf.KeyID == "" || conf.Key == "") {
+			return fmt.Errorf("signing: key_file, or key_id and key are required")
+		}
+	case "cert":
+		if conf.TLSCert == "" || conf.TLSKey == "" {
+			return fmt.Errorf("signing: tls_cert and tls_key are required")
+		}
+	default:
+		return fmt.Errorf("signing: invalid mode %s, hmac or cert", conf.Mode)
+	}
+
+	sg := &signer{conf: conf, modTimes: make(map[string]time.Time)}
+	if err := sg.load(); err != nil {
+		return fmt.Errorf("signing: %v", err)
+	}
+	sg.checked = time.Now()
+
+	s = sg
+	signingLog.Infof("signing payloads by %s key: %s", conf.Mode, sg.keyID)
+	return nil
+}
+
+// Sign sets Header of req, body is the payload sent
+func Sign(req *http.Request, body []byte) {
+	if s == nil {
+		return
+	}
+	s.reload()
+
+	ts := time.Now().Unix()
+	host := config.Config.GetHostname()
+	digest := sha256.Sum256(body)
+	msg := strings.Join([]string{req.Method, req.URL.RequestURI(), fmt.Sprint(ts), host, hex.EncodeToString(digest[:])}, "\n")
+
+	s.Lock()
+	keyID, algorithm := s.keyID, s.algorithm
+	sig, err := s.sign([]byte(msg))
+	s.Unlock()
+	if err != nil {
+		signingLog.Errorf("failed to sign request of %s error: %v", req.URL.Host, err)
+		return
+	}
+
+	req.Header.Set(Header, fmt.Sprintf(`keyId="%s",algorithm="%s",timestamp="%d",host="%s",signature="%s"`,
+		keyID, algorithm, ts, host, base64.StdEncoding.EncodeToString(sig)))
+}
+
+func (sg *signer) sign(msg []byte) ([]byte, error) {
+	if sg.hmacKey != nil {
+		mac := hmac.New(sha256.New, sg.hmacKey)
+		mac.Write(msg)
+		return mac.Sum(nil), nil
+	}
+
+	// ed25519 signs the message itself, the others sign the digest
+	if _, ok := sg.key.(ed25519.PrivateKey); ok {
+		return sg.key.Sign(rand.Reader, msg, crypto.Hash(0))
+	}
+	digest := sha256.Sum256(msg)
+	return sg.key.Sign(rand.Reader, digest[:], crypto.SHA256)
+}
+
+// reload loads the keys again if the files changed, the keys in use are kept if the files are broken
+func (sg *signer) reload() {
+	sg.Lock()
+	defer sg.Unlock()
+
+	if time.Since(sg.checked) < reloadInterval {
+		return
+	}
+	sg.checked = time.Now()
+
+	changed := false
+	for _, f := range sg.files() {
+		info, err := os.Stat(f)
+		if err != nil {
+			signingLog.Errorf("failed to stat signing key file %s error: %v", f, err)
+			return
+		}
+		if !info.ModTime().Equal(sg.modTimes[f]) {
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+
+	keyID := sg.keyID
+	if err := sg.load(); err != nil {
+		signingLog.Errorf("failed to reload signing keys, the key %s is still used, error: %v", keyID, err)
+		return
+	}
+	if sg.keyID != keyID {
+		signingLog.Infof("signing key is rotated from %s to %s", keyID, sg.keyID)
+	}
+}
+
+func (sg *signer) files() []string {
+	if sg.conf.Mode == "cert" {
+		return []string{sg.conf.TLSCert, sg.conf.TLSKey}
+	}
+	if sg.conf.KeyFile != "" {
+		return []string{sg.conf.KeyFile}
+	}
+	return nil
+}
+
+func (sg *signer) load() error {
+	for _, f := range sg.files() {
+		info, err := os.Stat(f)
+		if err != nil {
+			return err
+		}
+		sg.modTimes[f] = info.ModTime()
+	}
+
+	if sg.conf.Mode == "cert" {
+		return sg.loadCert()
+	}
+	if sg.conf.KeyFile == "" {
+		sg.keyID, sg.algorithm, sg.hmacKey = sg.conf.KeyID, "hmac-sha256", []byte(sg.conf.Key)
+		return nil
+	}
+	return sg.loadKeyFile()
+}
+
+// loadKeyFile takes the last key of the file
+func (sg *signer) loadKeyFile() error {
+	bs, err := os.ReadFile(sg.conf.KeyFile)
+	if err != nil {
+		return err
+	}
+
+	var keyID, key string
+	scanner := bufio.NewScanner(bytes.NewReader(bs))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return fmt.Errorf("%s:%d: a key is \"<key_id> <secret>\"", sg.conf.KeyFile, n)
+		}
+		keyID, key = fields[0], fields[1]
+	}
+	if keyID == "" {
+		return fmt.Errorf("no key in %s", sg.conf.KeyFile)
+	}
+
+	sg.keyID, sg.algorithm, sg.hmacKey = keyID, "hmac-sha256", []byte(key)
+	return nil
+}
+
+func (sg *signer) loadCert() error {
+	cert, err := tls.LoadX509KeyPair(sg.conf.TLSCert, sg.conf.TLSKey)
+	if err != nil {
+		return err
+	}
+
+	var algorithm string
+	switch cert.PrivateKey.(type) {
+	case *rsa.PrivateKey:
+		algorithm = "rsa-sha256"
+	case *ecdsa.PrivateKey:
+		algorithm = "ecdsa-sha256"
+	case ed25519.PrivateKey:
+		algorithm = "ed25519"
+	default:
+		return fmt.Errorf("unsupported private key %T", cert.PrivateKey)
+	}
+
+	fingerprint := sha256.Sum256(cert.Certificate[0])
+	sg.keyID, sg.algorithm, sg.hmacKey = hex.EncodeToString(fingerprint[:]), algorithm, nil
+	sg.key = cert.PrivateKey.(crypto.Signer)
+	return nil
+}
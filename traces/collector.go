@@ -11,6 +11,9 @@ import (
 
 	"flashcat.cloud/categraf/config"
 	"flashcat.cloud/categraf/config/traces"
+
+	// processors implemented by categraf
+	_ "flashcat.cloud/categraf/traces/spanmetrics"
 )
 
 // Collector simply wrapped the OpenTelemetry Collector, which means you can get a full support
@@ -0,0 +1,91 @@
+//go:build !no_traces
+
+package spanmetrics
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+
+	"flashcat.cloud/categraf/config/traces"
+)
+
+const (
+	// The value of "type" key in configuration.
+	typeStr = "categraf_spanmetrics"
+
+	defaultFlushInterval = 15 * time.Second
+	defaultMaxSeries     = 10000
+)
+
+var defaultBuckets = []time.Duration{
+	2 * time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// Config defines the configuration of the span metrics processor.
+//
+//	Buckets:       upper bounds of the duration histogram.
+//	Dimensions:    span or resource attributes added as labels, e.g. http.route, missing ones are skipped.
+//	FlushInterval: how often the metrics are written to the metrics writers.
+//	MaxSeries:     limit of the series, spans of new series are not counted once reached.
+type Config struct {
+	config.ProcessorSettings `mapstructure:",squash"`
+
+	Buckets       []time.Duration `mapstructure:"latency_histogram_buckets"`
+	Dimensions    []string        `mapstructure:"dimensions"`
+	FlushInterval time.Duration   `mapstructure:"flush_interval"`
+	MaxSeries     int             `mapstructure:"max_series"`
+}
+
+func init() {
+	traces.RegisterProcessorFactory(NewFactory())
+}
+
+// NewFactory creates a factory for the span metrics processor.
+func NewFactory() component.ProcessorFactory {
+	return component.NewProcessorFactory(
+		typeStr,
+		createDefaultConfig,
+		component.WithTracesProcessor(createTracesProcessor))
+}
+
+func createDefaultConfig() config.Processor {
+	return &Config{
+		ProcessorSettings: config.NewProcessorSettings(config.NewComponentID(typeStr)),
+		Buckets:           defaultBuckets,
+		Dimensions:        []string{"http.method", "http.route"},
+		FlushInterval:     defaultFlushInterval,
+		MaxSeries:         defaultMaxSeries,
+	}
+}
+
+func createTracesProcessor(
+	_ context.Context,
+	_ component.ProcessorCreateSettings,
+	cfg config.Processor,
+	nextConsumer consumer.Traces,
+) (component.TracesProcessor, error) {
+	p := newProcessor(cfg.(*Config))
+	return processorhelper.NewTracesProcessor(
+		cfg,
+		nextConsumer,
+		p.processTraces,
+		processorhelper.WithCapabilities(consumer.Capabilities{MutatesData: false}),
+		processorhelper.WithStart(p.start),
+		processorhelper.WithShutdown(p.shutdown))
+}
@@ -0,0 +1,212 @@
+//go:build !no_traces
+
+package spanmetrics
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/types"
+	"flashcat.cloud/categraf/writer"
+)
+
+const metricPrefix = "span_metrics"
+
+var spanmetricsLog = logger.New("spanmetrics")
+
+// processor derives RED metrics from spans passing through, the spans are not modified.
+// the metrics are cumulative, and written to the metrics writers every flush interval
+type processor struct {
+	cfg     *Config
+	bounds  []float64
+	mu      sync.Mutex
+	series  map[string]*series
+	dropped bool
+	quit    chan struct{}
+	done    sync.WaitGroup
+}
+
+type series struct {
+	labels  map[string]string
+	calls   uint64
+	errors  uint64
+	buckets []uint64 // the last one is +Inf
+	sum     float64
+}
+
+func newProcessor(cfg *Config) *processor {
+	buckets := append([]time.Duration{}, cfg.Buckets...)
+	if len(buckets) == 0 {
+		buckets = defaultBuckets
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i] < buckets[j] })
+
+	bounds := make([]float64, len(buckets))
+	for i, b := range buckets {
+		bounds[i] = b.Seconds()
+	}
+
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = defaultFlushInterval
+	}
+	if cfg.MaxSeries <= 0 {
+		cfg.MaxSeries = defaultMaxSeries
+	}
+
+	return &processor{
+		cfg:    cfg,
+		bounds: bounds,
+		series: make(map[string]*series),
+		quit:   make(chan struct{}),
+	}
+}
+
+func (p *processor) start(context.Context, component.Host) error {
+	p.done.Add(1)
+	go p.loopFlush()
+	return nil
+}
+
+func (p *processor) shutdown(context.Context) error {
+	close(p.quit)
+	p.done.Wait()
+	return nil
+}
+
+func (p *processor) loopFlush() {
+	defer p.done.Done()
+	ticker := time.NewTicker(p.cfg.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.quit:
+			p.flush()
+			return
+		case <-ticker.C:
+			p.flush()
+		}
+	}
+}
+
+func (p *processor) processTraces(_ context.Context, td ptrace.Traces) (ptrace.Traces, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	rss := td.ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		rs := rss.At(i)
+		resAttrs := rs.Resource().Attributes()
+		service := "unknown"
+		if v, ok := resAttrs.Get("service.name"); ok {
+			service = v.AsString()
+		}
+
+		sss := rs.ScopeSpans()
+		for j := 0; j < sss.Len(); j++ {
+			spans := sss.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				p.record(service, resAttrs, spans.At(k))
+			}
+		}
+	}
+
+	return td, nil
+}
+
+func (p *processor) record(service string, resAttrs pcommon.Map, span ptrace.Span) {
+	labels := map[string]string{
+		"service":     service,
+		"operation":   span.Name(),
+		"span_kind":   strings.TrimPrefix(span.Kind().String(), "SPAN_KIND_"),
+		"status_code": strings.TrimPrefix(span.Status().Code().String(), "STATUS_CODE_"),
+	}
+	for _, dim := range p.cfg.Dimensions {
+		v, ok := span.Attributes().Get(dim)
+		if !ok {
+			v, ok = resAttrs.Get(dim)
+		}
+		if ok {
+			labels[dim] = v.AsString()
+		}
+	}
+
+	key := seriesKey(labels)
+	s, has := p.series[key]
+	if !has {
+		if len(p.series) >= p.cfg.MaxSeries {
+			if !p.dropped {
+				spanmetricsLog.Warnf("span metrics series exceed max_series: %d, new series are dropped", p.cfg.MaxSeries)
+				p.dropped = true
+			}
+			return
+		}
+		s = &series{labels: labels, buckets: make([]uint64, len(p.bounds)+1)}
+		p.series[key] = s
+	}
+
+	duration := span.EndTimestamp().AsTime().Sub(span.StartTimestamp().AsTime()).Seconds()
+	s.calls++
+	if span.Status().Code() == ptrace.StatusCodeError {
+		s.errors++
+	}
+	s.sum += duration
+	s.buckets[sort.SearchFloat64s(p.bounds, duration)]++
+}
+
+func seriesKey(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, k := range keys {
+		sb.WriteString(k)
+		sb.WriteByte(0xff)
+		sb.WriteString(labels[k])
+		sb.WriteByte(0xff)
+	}
+	return sb.String()
+}
+
+func (p *processor) flush() {
+	slist := types.NewSampleList()
+
+	p.mu.Lock()
+	for _, s := range p.series {
+		slist.PushSample(metricPrefix, "calls_total", s.calls, s.labels)
+		slist.PushSample(metricPrefix, "errors_total", s.errors, s.labels)
+		slist.PushSample(metricPrefix, "duration_seconds_sum", s.sum, s.labels)
+		slist.PushSample(metricPrefix, "duration_seconds_count", s.calls, s.labels)
+
+		var cumulative uint64
+		for i, count := range s.buckets {
+			cumulative += count
+			le := "+Inf"
+			if i < len(p.bounds) {
+				le = strconv.FormatFloat(p.bounds[i], 'f', -1, 64)
+			}
+			slist.PushSample(metricPrefix, "duration_seconds_bucket", cumulative, s.labels, map[string]string{"le": le})
+		}
+	}
+	p.mu.Unlock()
+
+	if slist.Len() == 0 {
+		return
+	}
+
+	// add global labels, agent_hostname and timestamp the same as inputs
+	var ic config.InternalConfig
+	writer.WriteSamples(ic.Process(slist).PopBackAll())
+}
@@ -87,6 +87,18 @@ func (sl *SafeList[T]) PopBackAll() []T {
 	return items
 }
 
+// Range calls fn for each item from back to front, stops if fn returns false
+func (sl *SafeList[T]) Range(fn func(T) bool) {
+	sl.RLock()
+	defer sl.RUnlock()
+	for e := sl.L.Back(); e != nil; e = e.Prev() {
+		item, ok := e.Value.(T)
+		if ok && !fn(item) {
+			return
+		}
+	}
+}
+
 func (sl *SafeList[T]) RemoveAll() {
 	sl.Lock()
 	sl.L.Init()
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"fmt"
 	"strings"
 	"time"
 
@@ -15,6 +16,17 @@ type Sample struct {
 	Timestamp time.Time         `json:"timestamp"`
 	Value     interface{}       `json:"value"`
 	Labels    map[string]string `json:"labels"`
+	// the exemplar of the sample if any, e.g. the trace of a request counted by a bucket,
+	// which is forwarded by the remote write writers only
+	Exemplar *Exemplar `json:"exemplar,omitempty"`
+}
+
+// Exemplar is an observation of a sample, of which labels link it to a trace usually
+type Exemplar struct {
+	Labels map[string]string `json:"labels"`
+	Value  float64           `json:"value"`
+	// the time of the observation, or the timestamp of the sample if zero
+	Timestamp time.Time `json:"timestamp"`
 }
 
 var (
@@ -45,21 +57,124 @@ func NewSample(prefix, metric string, value interface{}, labels ...map[string]st
 	return s
 }
 
+// ValueOptions controls how values which are not plain numbers are serialized
+type ValueOptions struct {
+	// Uint64As is one of float(default), wrap, drop
+	//   float: convert to float64, precision is lost above 2^53
+	//   wrap: keep the value modulo 2^53, exact but looks like a counter reset when wrapping
+	//   drop: drop values above 2^53
+	Uint64As string
+	// BoolAs is one of number(default), drop
+	BoolAs string
+}
+
+const maxExactFloat = 1 << 53
+
+func (opts ValueOptions) Validate() error {
+	switch opts.Uint64As {
+	case "", "float", "wrap", "drop":
+	default:
+		return fmt.Errorf("invalid uint64_as: %s", opts.Uint64As)
+	}
+	switch opts.BoolAs {
+	case "", "number", "drop":
+	default:
+		return fmt.Errorf("invalid bool_as: %s", opts.BoolAs)
+	}
+	return nil
+}
+
+// toFloat64 returns false if the value should be dropped
+func (opts ValueOptions) toFloat64(val interface{}) (float64, bool) {
+	var u uint64
+	switch v := val.(type) {
+	case uint64:
+		u = v
+	case uint:
+		u = uint64(v)
+	case bool:
+		if opts.BoolAs == "drop" {
+			return 0, false
+		}
+		if v {
+			return 1, true
+		}
+		return 0, true
+	default:
+		value, err := conv.ToFloat64(val)
+		return value, err == nil
+	}
+
+	if u < maxExactFloat {
+		return float64(u), true
+	}
+
+	switch opts.Uint64As {
+	case "wrap":
+		return float64(u % maxExactFloat), true
+	case "drop":
+		return 0, false
+	default:
+		return float64(u), true
+	}
+}
+
+// CheckPrecision checks the timestamp precision, one of s, ms, us, ns
+func CheckPrecision(precision string) error {
+	switch precision {
+	case "s", "ms", "us", "ns":
+		return nil
+	default:
+		return fmt.Errorf("invalid precision: %s", precision)
+	}
+}
+
+// TimestampIn returns the timestamp of the sample in the given precision, defaults to ms
+func (item *Sample) TimestampIn(precision string) int64 {
+	return timestampIn(item.Timestamp, precision)
+}
+
+func timestampIn(t time.Time, precision string) int64 {
+	switch precision {
+	case "s":
+		return t.Unix()
+	case "us":
+		return t.UnixMicro()
+	case "ns":
+		return t.UnixNano()
+	default:
+		return t.UnixMilli()
+	}
+}
+
+// TimeOf is the reverse of TimestampIn
+func TimeOf(ts int64, precision string) time.Time {
+	switch precision {
+	case "s":
+		return time.Unix(ts, 0)
+	case "us":
+		return time.UnixMicro(ts)
+	case "ns":
+		return time.Unix(0, ts)
+	default:
+		return time.UnixMilli(ts)
+	}
+}
+
 func (item *Sample) ConvertTimeSeries(precision string) *prompb.TimeSeries {
-	value, err := conv.ToFloat64(item.Value)
-	if err != nil {
+	return item.ConvertTimeSeriesWith(precision, ValueOptions{})
+}
+
+func (item *Sample) ConvertTimeSeriesWith(precision string, opts ValueOptions) *prompb.TimeSeries {
+	value, ok := opts.toFloat64(item.Value)
+	if !ok {
 		// If the Labels is empty, it means it is abnormal data
 		return nil
 	}
 
 	pt := prompb.TimeSeries{}
-	timestamp := item.Timestamp.UnixMilli()
-	if precision == "s" {
-		timestamp = item.Timestamp.Unix()
-	}
-
 	pt.Samples = append(pt.Samples, prompb.Sample{
-		Timestamp: timestamp,
+		Timestamp: item.TimestampIn(precision),
 		Value:     value,
 	})
 
@@ -80,6 +195,25 @@ func (item *Sample) ConvertTimeSeries(precision string) *prompb.TimeSeries {
 	return &pt
 }
 
+// ConvertExemplar returns the exemplar of the sample in prompb, in the precision of the sample timestamp
+func (item *Sample) ConvertExemplar(precision string) (prompb.Exemplar, bool) {
+	e := item.Exemplar
+	if e == nil {
+		return prompb.Exemplar{}, false
+	}
+
+	pe := prompb.Exemplar{Value: e.Value}
+	if e.Timestamp.IsZero() {
+		pe.Timestamp = item.TimestampIn(precision)
+	} else {
+		pe.Timestamp = timestampIn(e.Timestamp, precision)
+	}
+	for k, v := range e.Labels {
+		pe.Labels = append(pe.Labels, prompb.Label{Name: k, Value: v})
+	}
+	return pe, true
+}
+
 func (s *Sample) SetTime(t time.Time) *Sample {
 	if t.IsZero() || zeroTime.Equal(t) {
 		return s
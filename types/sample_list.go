@@ -1,28 +1,181 @@
 package types
 
 import (
-	"container/list"
+	"sync"
 )
 
+const defaultSlabSize = 64
+
+var slabPool = sync.Pool{
+	New: func() interface{} {
+		s := make([]*Sample, 0, defaultSlabSize)
+		return &s
+	},
+}
+
+// SampleList is a thread-safe FIFO of samples: PushFront appends new samples, PopBack* take the oldest ones.
+// Samples are held in a slab (a slice reused across pops) instead of a linked list,
+// so pushing costs no allocation unless the slab grows, slabs are pooled across lists.
 type SampleList struct {
-	SafeList[*Sample]
+	sync.RWMutex
+	buf  []*Sample
+	head int
 }
 
 func NewSampleList() *SampleList {
-	return &SampleList{*NewSafeList[*Sample]()}
+	return &SampleList{}
+}
+
+// grow makes room for n more samples, reuses the space of popped samples if possible
+func (l *SampleList) grow(n int) {
+	if l.buf == nil {
+		l.buf = (*slabPool.Get().(*[]*Sample))[:0]
+	}
+
+	if len(l.buf)+n <= cap(l.buf) {
+		return
+	}
+
+	if l.head > 0 {
+		size := copy(l.buf, l.buf[l.head:])
+		for i := size; i < len(l.buf); i++ {
+			l.buf[i] = nil
+		}
+		l.buf = l.buf[:size]
+		l.head = 0
+	}
+}
+
+// reset releases the references of popped samples, the slab is kept for reuse
+func (l *SampleList) reset() {
+	for i := range l.buf {
+		l.buf[i] = nil
+	}
+	l.buf = l.buf[:0]
+	l.head = 0
+}
+
+func (l *SampleList) PushFront(v *Sample) {
+	l.Lock()
+	l.grow(1)
+	l.buf = append(l.buf, v)
+	l.Unlock()
+}
+
+func (l *SampleList) PushFrontN(vs []*Sample) {
+	if len(vs) == 0 {
+		return
+	}
+	l.Lock()
+	l.grow(len(vs))
+	l.buf = append(l.buf, vs...)
+	l.Unlock()
+}
+
+func (l *SampleList) PopBack() **Sample {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.head >= len(l.buf) {
+		return nil
+	}
+
+	v := l.buf[l.head]
+	l.buf[l.head] = nil
+	l.head++
+	if l.head == len(l.buf) {
+		l.reset()
+	}
+	return &v
+}
+
+func (l *SampleList) PopBackN(n int) []*Sample {
+	l.Lock()
+	defer l.Unlock()
+
+	count := len(l.buf) - l.head
+	if count == 0 || n <= 0 {
+		return nil
+	}
+	if count > n {
+		count = n
+	}
+
+	items := make([]*Sample, count)
+	copy(items, l.buf[l.head:l.head+count])
+	for i := l.head; i < l.head+count; i++ {
+		l.buf[i] = nil
+	}
+	l.head += count
+	if l.head == len(l.buf) {
+		l.reset()
+	}
+	return items
+}
+
+// PopBackAll takes all samples, the returned slice is owned by the caller,
+// pass it to ReleaseSamples when done to reuse it as a slab
+func (l *SampleList) PopBackAll() []*Sample {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.head >= len(l.buf) {
+		return nil
+	}
+
+	items := l.buf[l.head:]
+	l.buf = nil
+	l.head = 0
+	return items
+}
+
+// ReleaseSamples puts the slice returned by PopBackAll back to the pool, it must not be used afterwards
+func ReleaseSamples(items []*Sample) {
+	if cap(items) == 0 || cap(items) > 64*1024 {
+		return
+	}
+	for i := range items {
+		items[i] = nil
+	}
+	items = items[:0]
+	slabPool.Put(&items)
+}
+
+func (l *SampleList) RemoveAll() {
+	l.Lock()
+	l.reset()
+	l.Unlock()
+}
+
+func (l *SampleList) Len() int {
+	l.RLock()
+	size := len(l.buf) - l.head
+	l.RUnlock()
+	return size
+}
+
+// Range calls fn for each sample from the oldest to the newest, stops if fn returns false
+func (l *SampleList) Range(fn func(*Sample) bool) {
+	l.RLock()
+	defer l.RUnlock()
+	for i := l.head; i < len(l.buf); i++ {
+		if !fn(l.buf[i]) {
+			return
+		}
+	}
 }
 
-func (l *SampleList) PushSample(prefix, metric string, value interface{}, labels ...map[string]string) *list.Element {
+func (l *SampleList) PushSample(prefix, metric string, value interface{}, labels ...map[string]string) *Sample {
 	v := NewSample(prefix, metric, value, labels...)
-	e := l.PushFront(v)
-	return e
+	l.PushFront(v)
+	return v
 }
 
 func (l *SampleList) PushSamples(prefix string, fields map[string]interface{}, labels ...map[string]string) {
-	vs := make([]*Sample, 0, len(fields))
+	l.Lock()
+	l.grow(len(fields))
 	for metric, value := range fields {
-		v := NewSample(prefix, metric, value, labels...)
-		vs = append(vs, v)
+		l.buf = append(l.buf, NewSample(prefix, metric, value, labels...))
 	}
-	l.PushFrontN(vs)
+	l.Unlock()
 }
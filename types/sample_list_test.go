@@ -0,0 +1,135 @@
+package types
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// the samples are popped in the order pushed, across the compactions and the growth of the slab
+func TestSampleListOrder(t *testing.T) {
+	l := NewSampleList()
+	var want []int
+	for i := 0; i < 5*defaultSlabSize; i++ {
+		l.PushFront(NewSample("", "m", i))
+		want = append(want, i)
+		if i%3 == 2 {
+			v := l.PopBack()
+			require.NotNil(t, v)
+			assert.Equal(t, want[0], (*v).Value)
+			want = want[1:]
+		}
+	}
+	require.Equal(t, len(want), l.Len())
+
+	for _, s := range l.PopBackN(10) {
+		assert.Equal(t, want[0], s.Value)
+		want = want[1:]
+	}
+	l.PushFrontN([]*Sample{NewSample("", "m", -1)})
+	want = append(want, -1)
+
+	got := l.PopBackAll()
+	require.Len(t, got, len(want))
+	for i, s := range got {
+		assert.Equal(t, want[i], s.Value)
+	}
+	assert.Equal(t, 0, l.Len())
+	assert.Nil(t, l.PopBack())
+	ReleaseSamples(got)
+}
+
+var benchSizes = []int{100, 10000}
+
+func benchSamples(n int) []*Sample {
+	samples := make([]*Sample, n)
+	for i := range samples {
+		samples[i] = NewSample("", "m"+strconv.Itoa(i), i)
+	}
+	return samples
+}
+
+// a gather pushes the samples one by one, and the writer drains them, the slab is reused by the next gather
+func BenchmarkSampleListPushFront(b *testing.B) {
+	for _, n := range benchSizes {
+		samples := benchSamples(n)
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				l := NewSampleList()
+				for _, s := range samples {
+					l.PushFront(s)
+				}
+				ReleaseSamples(l.PopBackAll())
+			}
+		})
+	}
+}
+
+func BenchmarkSampleListPushFrontN(b *testing.B) {
+	for _, n := range benchSizes {
+		samples := benchSamples(n)
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				l := NewSampleList()
+				l.PushFrontN(samples)
+				ReleaseSamples(l.PopBackAll())
+			}
+		})
+	}
+}
+
+// the queue of a writer is pushed and popped in batches and never drained
+func BenchmarkSampleListPopBackN(b *testing.B) {
+	for _, n := range benchSizes {
+		samples := benchSamples(n)
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			b.ReportAllocs()
+			l := NewSampleList()
+			l.PushFrontN(samples)
+			for i := 0; i < b.N; i++ {
+				l.PushFrontN(samples)
+				for l.Len() > n {
+					l.PopBackN(1000)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkSampleListPushSamples(b *testing.B) {
+	fields := map[string]interface{}{}
+	for i := 0; i < 20; i++ {
+		fields["field"+strconv.Itoa(i)] = i
+	}
+	tags := map[string]string{"host": "localhost", "region": "cn"}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l := NewSampleList()
+		for j := 0; j < 100; j++ {
+			l.PushSamples("input", fields, tags)
+		}
+		ReleaseSamples(l.PopBackAll())
+	}
+}
+
+// SafeList, the linked list which SampleList was built on, is the baseline of the allocations
+func BenchmarkSafeListPushFront(b *testing.B) {
+	for _, n := range benchSizes {
+		samples := benchSamples(n)
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				l := NewSafeList[*Sample]()
+				for _, s := range samples {
+					l.PushFront(s)
+				}
+				l.PopBackAll()
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package writer
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/prompb"
+
+	"flashcat.cloud/categraf/config"
+)
+
+const defaultMetricsTTL = 5 * time.Minute
+
+// SeriesCache keeps the last value of every series written recently,
+// so the agent can be scraped by prometheus in addition to remote write
+type SeriesCache struct {
+	sync.RWMutex
+	ttl    time.Duration
+	series map[string]*cachedSeries
+}
+
+type cachedSeries struct {
+	name      string
+	labels    []prompb.Label
+	value     float64
+	timestamp int64 // ms
+	updated   time.Time
+}
+
+var cache *SeriesCache
+
+func initSeriesCache() {
+	conf := config.Config.HTTP
+	if conf == nil || !conf.Enable || !conf.ExposeMetrics {
+		return
+	}
+
+	ttl := time.Duration(conf.MetricsTTL)
+	if ttl <= 0 {
+		ttl = defaultMetricsTTL
+	}
+
+	cache = &SeriesCache{
+		ttl:    ttl,
+		series: make(map[string]*cachedSeries),
+	}
+	go cache.loopClean()
+}
+
+// ExposeEnabled reports whether the series cache is enabled
+func ExposeEnabled() bool {
+	return cache != nil
+}
+
+func (c *SeriesCache) put(items []*prompb.TimeSeries) {
+	now := time.Now()
+	c.Lock()
+	defer c.Unlock()
+
+	for _, item := range items {
+		if len(item.Samples) == 0 {
+			continue
+		}
+
+		sort.Slice(item.Labels, func(i, j int) bool {
+			return item.Labels[i].Name < item.Labels[j].Name
+		})
+
+		var sb strings.Builder
+		var name string
+		labels := make([]prompb.Label, 0, len(item.Labels))
+		for _, l := range item.Labels {
+			sb.WriteString(l.Name)
+			sb.WriteByte(0xff)
+			sb.WriteString(l.Value)
+			sb.WriteByte(0xff)
+			if l.Name == model.MetricNameLabel {
+				name = l.Value
+				continue
+			}
+			labels = append(labels, l)
+		}
+
+		c.series[sb.String()] = &cachedSeries{
+			name:      name,
+			labels:    labels,
+			value:     item.Samples[0].Value,
+			timestamp: item.Samples[0].Timestamp,
+			updated:   now,
+		}
+	}
+}
+
+func (c *SeriesCache) loopClean() {
+	for {
+		time.Sleep(time.Minute)
+		deadline := time.Now().Add(-c.ttl)
+		c.Lock()
+		for key, s := range c.series {
+			if s.updated.Before(deadline) {
+				delete(c.series, key)
+			}
+		}
+		c.Unlock()
+	}
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+// WriteText writes series not expired in prometheus text exposition format
+func WriteText(w io.Writer) error {
+	if cache == nil {
+		return nil
+	}
+
+	deadline := time.Now().Add(-cache.ttl)
+	cache.RLock()
+	arr := make([]*cachedSeries, 0, len(cache.series))
+	for _, s := range cache.series {
+		if s.updated.After(deadline) {
+			arr = append(arr, s)
+		}
+	}
+	cache.RUnlock()
+
+	// group by metric name, the type is unknown after converted to remote write series
+	sort.Slice(arr, func(i, j int) bool {
+		if arr[i].name != arr[j].name {
+			return arr[i].name < arr[j].name
+		}
+		return labelsLess(arr[i].labels, arr[j].labels)
+	})
+
+	bw := bufio.NewWriter(w)
+	last := ""
+	for _, s := range arr {
+		if s.name != last {
+			bw.WriteString("# TYPE ")
+			bw.WriteString(s.name)
+			bw.WriteString(" untyped\n")
+			last = s.name
+		}
+
+		bw.WriteString(s.name)
+		if len(s.labels) > 0 {
+			bw.WriteByte('{')
+			for i, l := range s.labels {
+				if i > 0 {
+					bw.WriteByte(',')
+				}
+				bw.WriteString(l.Name)
+				bw.WriteString(`="`)
+				labelValueEscaper.WriteString(bw, l.Value)
+				bw.WriteByte('"')
+			}
+			bw.WriteByte('}')
+		}
+		bw.WriteByte(' ')
+		bw.WriteString(strconv.FormatFloat(s.value, 'g', -1, 64))
+		bw.WriteByte(' ')
+		bw.WriteString(strconv.FormatInt(s.timestamp, 10))
+		bw.WriteByte('\n')
+	}
+
+	return bw.Flush()
+}
+
+func labelsLess(a, b []prompb.Label) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i].Name != b[i].Name {
+			return a[i].Name < b[i].Name
+		}
+		if a[i].Value != b[i].Value {
+			return a[i].Value < b[i].Value
+		}
+	}
+	return len(a) < len(b)
+}
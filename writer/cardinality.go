@@ -0,0 +1,132 @@
+package writer
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/types"
+)
+
+const (
+	overflowTagValue            = "__overflow__"
+	defaultMaxValuesPerTag      = 10000
+	defaultCardinalityResetTime = time.Hour
+)
+
+var (
+	tagCardinalityOverflow = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "tag_cardinality_overflow_total",
+		Help: "Number of samples whose tag value was replaced or dropped because the tag exceeded max_values_per_tag.",
+	}, []string{"tag"})
+
+	tagCardinalityValues = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "tag_cardinality_values",
+		Help: "Number of distinct values tracked for the tag.",
+	}, []string{"tag"})
+)
+
+func init() {
+	prometheus.MustRegister(tagCardinalityOverflow, tagCardinalityValues)
+}
+
+// cardinalityLimiter tracks distinct values of every tag key, values beyond the limit
+// are replaced with __overflow__ or the tag is dropped, known values are kept
+type cardinalityLimiter struct {
+	sync.Mutex
+	max      int
+	drop     bool
+	excludes map[string]struct{}
+	values   map[string]map[string]struct{}
+	warned   map[string]struct{}
+}
+
+var limiter *cardinalityLimiter
+
+func initCardinalityLimiter() {
+	conf := config.Config.CardinalityLimit
+	if conf == nil || !conf.Enable {
+		return
+	}
+
+	l := &cardinalityLimiter{
+		max:      conf.MaxValuesPerTag,
+		drop:     conf.Action == "drop",
+		excludes: make(map[string]struct{}, len(conf.ExcludeTags)),
+	}
+	if l.max <= 0 {
+		l.max = defaultMaxValuesPerTag
+	}
+	for _, tag := range conf.ExcludeTags {
+		l.excludes[tag] = struct{}{}
+	}
+	l.reset()
+
+	interval := time.Duration(conf.ResetInterval)
+	if interval <= 0 {
+		interval = defaultCardinalityResetTime
+	}
+
+	limiter = l
+	go limiter.loopReset(interval)
+}
+
+func (l *cardinalityLimiter) reset() {
+	l.values = make(map[string]map[string]struct{})
+	l.warned = make(map[string]struct{})
+	tagCardinalityValues.Reset()
+}
+
+// loopReset forgets tracked values periodically, so tags recover after the bad values are gone
+func (l *cardinalityLimiter) loopReset(interval time.Duration) {
+	for {
+		time.Sleep(interval)
+		l.Lock()
+		l.reset()
+		l.Unlock()
+	}
+}
+
+func (l *cardinalityLimiter) apply(samples []*types.Sample) {
+	l.Lock()
+	defer l.Unlock()
+
+	for _, sample := range samples {
+		if sample == nil {
+			continue
+		}
+		for k, v := range sample.Labels {
+			if _, has := l.excludes[k]; has {
+				continue
+			}
+
+			vals, has := l.values[k]
+			if !has {
+				vals = make(map[string]struct{})
+				l.values[k] = vals
+			}
+			if _, has := vals[v]; has {
+				continue
+			}
+			if len(vals) < l.max {
+				vals[v] = struct{}{}
+				tagCardinalityValues.WithLabelValues(k).Set(float64(len(vals)))
+				continue
+			}
+
+			if l.drop {
+				delete(sample.Labels, k)
+			} else {
+				sample.Labels[k] = overflowTagValue
+			}
+			tagCardinalityOverflow.WithLabelValues(k).Inc()
+
+			if _, has := l.warned[k]; !has {
+				l.warned[k] = struct{}{}
+				writerLog.Warnf("tag %s exceeds %d distinct values, new values are limited, e.g. metric: %s value: %s", k, l.max, sample.Metric, v)
+			}
+		}
+	}
+}
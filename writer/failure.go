@@ -0,0 +1,115 @@
+package writer
+
+import (
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"flashcat.cloud/categraf/alerting"
+	"flashcat.cloud/categraf/config"
+)
+
+const (
+	failureAlertRule         = "writers_failing"
+	defaultFailureAlertAfter = 5 * time.Minute
+	failureCheckInterval     = 10 * time.Second
+)
+
+// health tracks the results of the requests of a writer, including the spooled ones resent
+type health struct {
+	// unix nanoseconds
+	lastSuccess int64
+	lastFailure int64
+	lastError   atomic.Value
+}
+
+func newHealth() *health {
+	// the writer is failing since started if no request ever succeeds
+	return &health{lastSuccess: time.Now().UnixNano()}
+}
+
+func (h *health) observe(err error) {
+	now := time.Now().UnixNano()
+	if err == nil {
+		atomic.StoreInt64(&h.lastSuccess, now)
+		return
+	}
+	atomic.StoreInt64(&h.lastFailure, now)
+	h.lastError.Store(err.Error())
+}
+
+// initFailureAlert fires the actions of writer_opt.failure_alert when all writers are failing
+func initFailureAlert() error {
+	conf := config.Config.WriterOpt.FailureAlert
+	if conf == nil || !conf.Enable || len(writers.writerMap) == 0 {
+		return nil
+	}
+
+	notifier, err := alerting.NewNotifier(failureAlertRule, conf.Actions)
+	if err != nil {
+		return err
+	}
+
+	after := time.Duration(conf.After)
+	if after <= 0 {
+		after = defaultFailureAlertAfter
+	}
+	go loopCheckFailure(notifier, after, time.Duration(conf.RepeatInterval))
+	return nil
+}
+
+func loopCheckFailure(notifier *alerting.Notifier, after, repeat time.Duration) {
+	var (
+		firing   bool
+		notified time.Time
+	)
+
+	for {
+		time.Sleep(failureCheckInterval)
+
+		now := time.Now()
+		failing, lastSuccess, labels := writersFailing(now, after)
+		switch {
+		case failing && (!firing || repeat > 0 && now.Sub(notified) >= repeat):
+			firing, notified = true, now
+			notifier.Notify("firing", labels, now.Sub(lastSuccess).Seconds())
+		case !failing && firing:
+			firing = false
+			notifier.Notify("resolved", labels, 0)
+		}
+	}
+}
+
+// writersFailing returns true if no request of any writer succeeded within after, and the requests
+// failed since, together with the last success of all writers, the urls and the last error
+func writersFailing(now time.Time, after time.Duration) (bool, time.Time, map[string]string) {
+	var (
+		lastSuccess int64
+		lastFailure int64
+		lastError   string
+		urls        []string
+	)
+
+	for url, w := range writers.writerMap {
+		success := atomic.LoadInt64(&w.health.lastSuccess)
+		failure := atomic.LoadInt64(&w.health.lastFailure)
+		if success > lastSuccess {
+			lastSuccess = success
+		}
+		if failure > lastFailure {
+			lastFailure = failure
+			lastError, _ = w.health.lastError.Load().(string)
+		}
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+
+	labels := map[string]string{"writers": strings.Join(urls, ",")}
+	if lastFailure <= lastSuccess || now.Sub(time.Unix(0, lastSuccess)) < after {
+		return false, time.Unix(0, lastSuccess), labels
+	}
+
+	labels["error"] = lastError
+	return true, time.Unix(0, lastSuccess), labels
+}
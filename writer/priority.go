@@ -0,0 +1,111 @@
+package writer
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/prometheus/prompb"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/types"
+)
+
+var queueDroppedSamples = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "writer_queue_dropped_samples_total",
+	Help: "Number of samples dropped or evicted because the queue of writers was full.",
+}, []string{"priority"})
+
+func init() {
+	prometheus.MustRegister(queueDroppedSamples)
+}
+
+var priorities = []config.Priority{config.PriorityHigh, config.PriorityNormal, config.PriorityLow}
+
+// priorityQueue holds the series of every priority, which share the size of chan_size, the series are popped
+// in order of priority, and if it's full, the oldest series of lower priorities are evicted for the higher ones
+type priorityQueue struct {
+	sync.Mutex
+	maxSize int
+	queues  map[config.Priority]*types.SafeList[*prompb.TimeSeries]
+}
+
+func newPriorityQueue(maxSize int) *priorityQueue {
+	q := &priorityQueue{
+		maxSize: maxSize,
+		queues:  make(map[config.Priority]*types.SafeList[*prompb.TimeSeries], len(priorities)),
+	}
+	for _, p := range priorities {
+		q.queues[p] = types.NewSafeList[*prompb.TimeSeries]()
+	}
+	return q
+}
+
+func (q *priorityQueue) Len() int {
+	n := 0
+	for _, l := range q.queues {
+		n += l.Len()
+	}
+	return n
+}
+
+// PushFrontN returns false if the series are dropped, the series of high priority are never dropped
+func (q *priorityQueue) PushFrontN(items []*prompb.TimeSeries, priority config.Priority) bool {
+	if len(items) == 0 {
+		return true
+	}
+
+	q.Lock()
+	defer q.Unlock()
+	return q.pushFrontN(items, priority)
+}
+
+// fits tells if n series of priority would be pushed by pushFrontN, q is locked by callers
+func (q *priorityQueue) fits(n int, priority config.Priority) bool {
+	if priority == config.PriorityHigh {
+		return true
+	}
+	size, lower := 0, 0
+	for p, l := range q.queues {
+		size += l.Len()
+		if p < priority {
+			lower += l.Len()
+		}
+	}
+	// the lower priorities are evicted for the series, or enough of them to leave room
+	return size+n-q.maxSize <= lower || size-lower < q.maxSize
+}
+
+// pushFrontN is PushFrontN with q locked by callers
+func (q *priorityQueue) pushFrontN(items []*prompb.TimeSeries, priority config.Priority) bool {
+	if len(items) == 0 {
+		return true
+	}
+
+	if need := q.Len() + len(items) - q.maxSize; need > 0 {
+		for i := len(priorities) - 1; i >= 0 && priorities[i] < priority && need > 0; i-- {
+			evicted := q.queues[priorities[i]].PopBackN(need)
+			need -= len(evicted)
+			queueDroppedSamples.WithLabelValues(priorities[i].String()).Add(float64(len(evicted)))
+		}
+	}
+
+	if q.Len() >= q.maxSize && priority != config.PriorityHigh {
+		queueDroppedSamples.WithLabelValues(priority.String()).Add(float64(len(items)))
+		return false
+	}
+
+	q.queues[priority].PushFrontN(items)
+	return true
+}
+
+// PopBackN pops the oldest n series, high priority first
+func (q *priorityQueue) PopBackN(n int) []*prompb.TimeSeries {
+	var ret []*prompb.TimeSeries
+	for _, p := range priorities {
+		if len(ret) >= n {
+			break
+		}
+		ret = append(ret, q.queues[p].PopBackN(n-len(ret))...)
+	}
+	return ret
+}
@@ -0,0 +1,308 @@
+package writer
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/pkg/conv"
+	"flashcat.cloud/categraf/pkg/filter"
+	"flashcat.cloud/categraf/types"
+)
+
+const (
+	defaultMetricNamePattern    = `^[a-z_:][a-z0-9_:]*$`
+	defaultMaxTrackedMetrics    = 1000
+	maxRegistryLookupCacheItems = 100000
+
+	violationUnknown         = "unknown"
+	violationMiscased        = "miscased"
+	violationNaming          = "naming"
+	violationNegativeCounter = "negative_counter"
+)
+
+var metricRegistryViolations = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "metric_registry_violations_total",
+	Help: "Number of samples violating the metric registry, reason is unknown, miscased, naming or negative_counter.",
+}, []string{"metric", "reason"})
+
+func init() {
+	prometheus.MustRegister(metricRegistryViolations)
+}
+
+type metricDeclaration struct {
+	config.MetricDeclaration
+	name filter.Filter
+}
+
+// metricRegistry validates the samples against the declared metrics, the lookups by name are cached
+type metricRegistry struct {
+	sync.Mutex
+	drop     bool
+	pattern  *regexp.Regexp
+	excludes filter.Filter
+	maxNames int
+
+	// the names declared without globs, lowercased to the declared names for the miscased ones
+	exact map[string]*metricDeclaration
+	lower map[string]string
+	globs []*metricDeclaration
+
+	lookups map[string]*metricDeclaration
+	tracked map[string]struct{}
+	warned  map[string]struct{}
+}
+
+var registry *metricRegistry
+
+func initMetricRegistry() error {
+	conf := config.Config.MetricRegistry
+	if conf == nil || !conf.Enable {
+		return nil
+	}
+
+	r := &metricRegistry{
+		drop:     conf.Action == "drop",
+		maxNames: conf.MaxTrackedMetrics,
+		exact:    make(map[string]*metricDeclaration),
+		lower:    make(map[string]string),
+		lookups:  make(map[string]*metricDeclaration),
+		tracked:  make(map[string]struct{}),
+		warned:   make(map[string]struct{}),
+	}
+	if conf.Action != "" && conf.Action != "flag" && conf.Action != "drop" {
+		return fmt.Errorf("invalid action %s, flag or drop", conf.Action)
+	}
+	if r.maxNames <= 0 {
+		r.maxNames = defaultMaxTrackedMetrics
+	}
+
+	pattern := conf.NamePattern
+	if pattern == "" {
+		pattern = defaultMetricNamePattern
+	}
+	var err error
+	if r.pattern, err = regexp.Compile(pattern); err != nil {
+		return fmt.Errorf("failed to compile name_pattern: %v", err)
+	}
+	if r.excludes, err = filter.Compile(conf.ExcludeMetrics); err != nil {
+		return fmt.Errorf("failed to compile exclude_metrics: %v", err)
+	}
+
+	n := 0
+	for _, pattern := range conf.Files {
+		files, err := filepath.Glob(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid files %s: %v", pattern, err)
+		}
+		for _, f := range files {
+			var rf struct {
+				Metrics []config.MetricDeclaration `toml:"metrics"`
+			}
+			if _, err := toml.DecodeFile(f, &rf); err != nil {
+				return fmt.Errorf("failed to load %s: %v", f, err)
+			}
+			for _, m := range rf.Metrics {
+				if err := r.declare(m); err != nil {
+					return fmt.Errorf("%s: %v", f, err)
+				}
+				n++
+			}
+		}
+	}
+	if n == 0 {
+		return fmt.Errorf("no metrics declared in files %v", conf.Files)
+	}
+
+	registry = r
+	writerLog.Infof("metric registry loaded, metrics declared: %v", n)
+	return nil
+}
+
+func (r *metricRegistry) declare(m config.MetricDeclaration) error {
+	switch m.Type {
+	case "", "counter", "gauge", "histogram", "summary", "untyped":
+	default:
+		return fmt.Errorf("metric %s: invalid type %s", m.Name, m.Type)
+	}
+
+	d := &metricDeclaration{MetricDeclaration: m}
+	if !strings.ContainsAny(m.Name, "*?[") {
+		if !r.pattern.MatchString(m.Name) {
+			writerLog.Warnf("metric %s of the registry does not match name_pattern %s", m.Name, r.pattern)
+		}
+		if m.Unit != "" && !hasUnitSuffix(m.Name, m.Unit) {
+			writerLog.Warnf("metric %s of the registry does not have the suffix of its unit %s", m.Name, m.Unit)
+		}
+		r.exact[m.Name] = d
+		r.lower[strings.ToLower(m.Name)] = m.Name
+		return nil
+	}
+
+	f, err := filter.Compile([]string{m.Name})
+	if err != nil {
+		return fmt.Errorf("metric %s: %v", m.Name, err)
+	}
+	d.name = f
+	r.globs = append(r.globs, d)
+	return nil
+}
+
+func hasUnitSuffix(name, unit string) bool {
+	name = strings.TrimSuffix(name, "_total")
+	return strings.HasSuffix(name, "_"+unit)
+}
+
+// seriesSuffixes are the suffixes of the series of histograms and summaries, which are declared by their base names
+var seriesSuffixes = map[string][]string{
+	"histogram": {"_bucket", "_sum", "_count"},
+	"summary":   {"_sum", "_count"},
+}
+
+// lookup returns nil if the metric is not declared, the series of histograms and summaries,
+// e.g. http_request_duration_seconds_bucket, resolve to the declarations of their base names
+func (r *metricRegistry) lookup(metric string) *metricDeclaration {
+	if d, has := r.exact[metric]; has {
+		return d
+	}
+	if d, has := r.lookups[metric]; has {
+		return d
+	}
+
+	found := r.match(metric)
+	if found == nil {
+		found = r.matchSeries(metric)
+	}
+	if len(r.lookups) >= maxRegistryLookupCacheItems {
+		r.lookups = make(map[string]*metricDeclaration)
+	}
+	r.lookups[metric] = found
+	return found
+}
+
+func (r *metricRegistry) match(metric string) *metricDeclaration {
+	if d, has := r.exact[metric]; has {
+		return d
+	}
+	for _, d := range r.globs {
+		if d.name.Match(metric) {
+			return d
+		}
+	}
+	return nil
+}
+
+func (r *metricRegistry) matchSeries(metric string) *metricDeclaration {
+	for _, suffix := range seriesSuffixes["histogram"] {
+		base := strings.TrimSuffix(metric, suffix)
+		if base == metric {
+			continue
+		}
+		d := r.match(base)
+		if d == nil {
+			continue
+		}
+		for _, s := range seriesSuffixes[d.Type] {
+			if s == suffix {
+				return d
+			}
+		}
+	}
+	return nil
+}
+
+// apply returns the samples passing the registry, a new slice is returned if the violations are dropped
+// as samples may be released by callers, the samples of negative counters are flagged only
+func (r *metricRegistry) apply(samples []*types.Sample) []*types.Sample {
+	r.Lock()
+	defer r.Unlock()
+
+	var ret []*types.Sample
+	if r.drop {
+		ret = make([]*types.Sample, 0, len(samples))
+	}
+	for _, sample := range samples {
+		if sample == nil {
+			continue
+		}
+		reason := ""
+		if r.excludes == nil || !r.excludes.Match(sample.Metric) {
+			reason = r.validate(sample)
+		}
+		if reason != "" {
+			r.flag(sample.Metric, reason)
+		}
+		if r.drop && (reason == "" || reason == violationNegativeCounter) {
+			ret = append(ret, sample)
+		}
+	}
+
+	if !r.drop {
+		return samples
+	}
+	return ret
+}
+
+func (r *metricRegistry) validate(sample *types.Sample) string {
+	d := r.lookup(sample.Metric)
+	if d == nil {
+		if r.miscased(sample.Metric) != "" {
+			return violationMiscased
+		}
+		if !r.pattern.MatchString(sample.Metric) {
+			return violationNaming
+		}
+		return violationUnknown
+	}
+
+	if d.Type == "counter" {
+		if v, err := conv.ToFloat64(sample.Value); err == nil && v < 0 {
+			return violationNegativeCounter
+		}
+	}
+	return ""
+}
+
+// miscased returns the declared name of the metric differing in case only, with the suffix of the series
+// of histograms and summaries, empty if none
+func (r *metricRegistry) miscased(metric string) string {
+	lower := strings.ToLower(metric)
+	if name, has := r.lower[lower]; has {
+		return name
+	}
+	for _, suffix := range seriesSuffixes["histogram"] {
+		if base := strings.TrimSuffix(lower, suffix); base != lower {
+			if name, has := r.lower[base]; has {
+				return name + suffix
+			}
+		}
+	}
+	return ""
+}
+
+func (r *metricRegistry) flag(metric, reason string) {
+	name := metric
+	if _, has := r.tracked[metric]; !has {
+		if len(r.tracked) < r.maxNames {
+			r.tracked[metric] = struct{}{}
+		} else {
+			name = overflowTagValue
+		}
+	}
+	metricRegistryViolations.WithLabelValues(name, reason).Inc()
+
+	if _, has := r.warned[metric]; !has && len(r.warned) < r.maxNames {
+		r.warned[metric] = struct{}{}
+		if reason == violationMiscased {
+			writerLog.Warnf("metric %s violates the metric registry: %s, declared as %s", metric, reason, r.miscased(metric))
+		} else {
+			writerLog.Warnf("metric %s violates the metric registry: %s", metric, reason)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package writer
+
+import (
+	"fmt"
+
+	"github.com/prometheus/prometheus/prompb"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/pkg/filter"
+)
+
+const defaultTenantHeader = "X-Scope-OrgID"
+
+// router selects the series sent to a writer and splits them by tenant
+type router struct {
+	label  string
+	values filter.Filter
+	// route_default: the values routed to the other writers of label
+	others []filter.Filter
+
+	tenant *config.WriterTenant
+}
+
+func newRouter(opt config.WriterOption) (*router, error) {
+	r := &router{label: opt.RouteLabel, tenant: opt.Tenant}
+
+	if opt.RouteLabel != "" {
+		if len(opt.RouteValues) == 0 && !opt.RouteDefault {
+			return nil, fmt.Errorf("route_values or route_default is required if route_label configured")
+		}
+		if len(opt.RouteValues) > 0 && opt.RouteDefault {
+			return nil, fmt.Errorf("route_values and route_default are exclusive")
+		}
+		values, err := filter.Compile(opt.RouteValues)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile route_values: %v", err)
+		}
+		r.values = values
+	} else if len(opt.RouteValues) > 0 || opt.RouteDefault {
+		return nil, fmt.Errorf("route_label is required if route_values or route_default configured")
+	}
+
+	if opt.Tenant != nil {
+		if opt.Tenant.Label == "" {
+			return nil, fmt.Errorf("tenant.label is required")
+		}
+		if opt.Tenant.Header == "" {
+			opt.Tenant.Header = defaultTenantHeader
+		}
+	}
+
+	if r.label == "" && r.tenant == nil {
+		return nil, nil
+	}
+	return r, nil
+}
+
+// linkRouters gives the writers of route_default the values routed to the other writers
+func linkRouters(ws []Writer) error {
+	for _, w := range ws {
+		if w.router == nil || !w.Opts.RouteDefault {
+			continue
+		}
+		for _, o := range ws {
+			if o.router != nil && o.router.label == w.router.label && o.router.values != nil {
+				w.router.others = append(w.router.others, o.router.values)
+			}
+		}
+		if len(w.router.others) == 0 {
+			return fmt.Errorf("writer %s: no writer routed by route_label %s for route_default", w.Opts.Url, w.router.label)
+		}
+	}
+	return nil
+}
+
+// route returns the series sent to the writer, items is not modified as it's shared by the writers
+func (r *router) route(items []prompb.TimeSeries) []prompb.TimeSeries {
+	if r.label == "" {
+		return items
+	}
+
+	ret := make([]prompb.TimeSeries, 0, len(items))
+	for i := range items {
+		if r.match(labelValue(items[i].Labels, r.label)) {
+			ret = append(ret, items[i])
+		}
+	}
+	return ret
+}
+
+func (r *router) match(value string) bool {
+	if r.values != nil {
+		return r.values.Match(value)
+	}
+	for _, f := range r.others {
+		if f.Match(value) {
+			return false
+		}
+	}
+	return true
+}
+
+// splitTenants groups the series by tenant, the series without tenant are dropped
+func (r *router) splitTenants(items []prompb.TimeSeries) map[string][]prompb.TimeSeries {
+	t := r.tenant
+	ret := make(map[string][]prompb.TimeSeries)
+	for i := range items {
+		tenant := t.Default
+		if value := labelValue(items[i].Labels, t.Label); value != "" {
+			tenant = value
+			if mapped, ok := t.Tenants[value]; ok {
+				tenant = mapped
+			}
+		}
+		if tenant == "" {
+			continue
+		}
+
+		ts := items[i]
+		if t.DropLabel {
+			ts.Labels = dropLabel(ts.Labels, t.Label)
+		}
+		ret[tenant] = append(ret[tenant], ts)
+	}
+	return ret
+}
+
+func labelValue(labels []prompb.Label, name string) string {
+	for i := range labels {
+		if labels[i].Name == name {
+			return labels[i].Value
+		}
+	}
+	return ""
+}
+
+// dropLabel returns a copy of labels without name
+func dropLabel(labels []prompb.Label, name string) []prompb.Label {
+	ret := make([]prompb.Label, 0, len(labels))
+	for i := range labels {
+		if labels[i].Name != name {
+			ret = append(ret, labels[i])
+		}
+	}
+	return ret
+}
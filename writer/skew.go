@@ -0,0 +1,88 @@
+package writer
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/prometheus/prompb"
+
+	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/serializers/remotewrite"
+)
+
+// the Date header is in seconds, smaller skews are ignored
+const minTimeSkew = 5 * time.Second
+
+// measureTimeSkew compares the Date header of backend with the local clock
+func (w Writer) measureTimeSkew(resp *http.Response) {
+	if !config.Config.WriterOpt.CorrectTimeSkew {
+		return
+	}
+
+	date, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return
+	}
+
+	skew := date.Sub(time.Now())
+	if skew > -minTimeSkew && skew < minTimeSkew {
+		skew = 0
+	}
+
+	old := atomic.SwapInt64(w.skew, skew.Milliseconds())
+	if old == 0 && skew != 0 {
+		writerLog.Warnf("clock of %s is %s ahead of the local clock, timestamps are corrected", w.Opts.Url, skew)
+	}
+}
+
+// correctTimeSkew returns a copy of items with timestamps shifted by the skew
+func (w Writer) correctTimeSkew(items []prompb.TimeSeries) ([]prompb.TimeSeries, bool) {
+	skew := atomic.LoadInt64(w.skew)
+	if skew == 0 {
+		return items, false
+	}
+
+	switch w.serializeOptions().precision {
+	case "s":
+		skew /= 1000
+	case "us":
+		skew *= 1000
+	case "ns":
+		skew *= 1000000
+	}
+
+	ret := make([]prompb.TimeSeries, len(items))
+	for i := range items {
+		ret[i].Labels = items[i].Labels
+		ret[i].Samples = make([]prompb.Sample, len(items[i].Samples))
+		for j, s := range items[i].Samples {
+			s.Timestamp += skew
+			ret[i].Samples[j] = s
+		}
+		if len(items[i].Exemplars) > 0 {
+			ret[i].Exemplars = make([]prompb.Exemplar, len(items[i].Exemplars))
+			for j, e := range items[i].Exemplars {
+				e.Timestamp += skew
+				ret[i].Exemplars[j] = e
+			}
+		}
+	}
+	return ret, true
+}
+
+// spooledPayload corrects the timestamps of a spooled request with the current skew,
+// and serializes it in the format of the writer, the requests are spooled in remote write
+func (w Writer) spooledPayload(data []byte) ([]byte, error) {
+	if atomic.LoadInt64(w.skew) == 0 && w.isRemoteWrite() {
+		return data, nil
+	}
+
+	items, err := remotewrite.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	items, _ = w.correctTimeSkew(items)
+	return w.serializer.Serialize(items)
+}
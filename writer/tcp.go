@@ -0,0 +1,49 @@
+package writer
+
+import (
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const defaultTCPTimeout = 5 * time.Second
+
+// send writes data to the connection of tcp://host:port, e.g. carbon, or posts it to the url
+func (w Writer) send(data []byte, tenant string) (bool, error) {
+	if !strings.HasPrefix(w.Opts.Url, "tcp://") {
+		return w.post(data, tenant)
+	}
+	return w.sendTCP(data)
+}
+
+// sendTCP connects in each request, so the requests are spread over the receivers behind a load balancer,
+// all failures are retried since there is no response
+func (w Writer) sendTCP(data []byte) (bool, error) {
+	u, err := url.Parse(w.Opts.Url)
+	if err != nil {
+		return false, err
+	}
+
+	dialTimeout, timeout := time.Duration(w.Opts.DialTimeout)*time.Millisecond, time.Duration(w.Opts.Timeout)*time.Millisecond
+	if dialTimeout <= 0 {
+		dialTimeout = defaultTCPTimeout
+	}
+	if timeout <= 0 {
+		timeout = defaultTCPTimeout
+	}
+
+	conn, err := net.DialTimeout("tcp", u.Host, dialTimeout)
+	if err != nil {
+		return true, err
+	}
+	defer conn.Close()
+
+	if err := conn.SetWriteDeadline(time.Now().Add(timeout)); err != nil {
+		return true, err
+	}
+	if _, err := conn.Write(data); err != nil {
+		return true, err
+	}
+	return false, nil
+}
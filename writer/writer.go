@@ -4,81 +4,239 @@ import (
 	"bytes"
 	"context"
 	"fmt"
-	"log"
 	"net"
 	"net/http"
 	"time"
 
-	"github.com/golang/protobuf/proto"
-	"github.com/golang/snappy"
 	"github.com/prometheus/client_golang/api"
 	"github.com/prometheus/prometheus/prompb"
 
 	"flashcat.cloud/categraf/config"
+	"flashcat.cloud/categraf/pkg/logger"
+	"flashcat.cloud/categraf/pkg/spool"
+	"flashcat.cloud/categraf/serializers"
+	"flashcat.cloud/categraf/serializers/remotewrite"
+	"flashcat.cloud/categraf/signing"
+	"flashcat.cloud/categraf/types"
 )
 
+var writerLog = logger.New("writer")
+
+// the requests are spooled in remote write, which is snappy compressed
+const spoolFileSuffix = ".snappy"
+
 type Writer struct {
 	Opts   config.WriterOption
 	Client api.Client
+
+	// requests failed to send are spooled if spool_dir configured
+	spool *spool.Spool
+	// clock skew in ms, the clock of backend minus the local clock
+	skew *int64
+	// the results of requests, for writer_opt.failure_alert
+	health *health
+	// nil if the writer takes all samples of one tenant
+	router *router
+	// encodes the requests in the format of the writer
+	serializer serializers.Serializer
 }
 
 // newWriter creates a new Writer from config.WriterOption
 func newWriter(opt config.WriterOption) (Writer, error) {
+	if err := (types.ValueOptions{Uint64As: opt.Uint64As, BoolAs: opt.BoolAs}).Validate(); err != nil {
+		return Writer{}, fmt.Errorf("writer %s: %v", opt.Url, err)
+	}
+	if opt.Precision != "" {
+		if err := types.CheckPrecision(opt.Precision); err != nil {
+			return Writer{}, fmt.Errorf("writer %s: %v", opt.Url, err)
+		}
+	}
+
 	cli, err := api.NewClient(api.Config{
 		Address: opt.Url,
-		RoundTripper: &http.Transport{
+		RoundTripper: opt.RoundTripper(&http.Transport{
 			// TLSClientConfig: tlsConfig,
 			Proxy: http.ProxyFromEnvironment,
 			DialContext: (&net.Dialer{
 				Timeout: time.Duration(opt.DialTimeout) * time.Millisecond,
 			}).DialContext,
 			ResponseHeaderTimeout: time.Duration(opt.Timeout) * time.Millisecond,
-			MaxIdleConnsPerHost:   opt.MaxIdleConnsPerHost,
-		},
+		}),
 	})
 
 	if err != nil {
 		return Writer{}, err
 	}
 
-	return Writer{
+	r, err := newRouter(opt)
+	if err != nil {
+		return Writer{}, fmt.Errorf("writer %s: %v", opt.Url, err)
+	}
+
+	w := Writer{
 		Opts:   opt,
 		Client: cli,
-	}, nil
+		skew:   new(int64),
+		health: newHealth(),
+		router: r,
+	}
+
+	if w.serializer, err = serializers.New(opt.Format, w.serializeOptions().precision); err != nil {
+		return Writer{}, fmt.Errorf("writer %s: %v", opt.Url, err)
+	}
+
+	if dir := config.Config.WriterOpt.SpoolDir; dir != "" {
+		maxSize := config.Config.WriterOpt.SpoolMaxSizeMB
+		if maxSize <= 0 {
+			maxSize = spool.DefaultMaxSizeMB
+		}
+		w.spool, err = spool.Open(spool.Dir(dir, opt.Url), spoolFileSuffix, maxSize*1024*1024)
+		if err != nil {
+			return Writer{}, fmt.Errorf("writer %s: failed to open spool: %v", opt.Url, err)
+		}
+	}
+
+	return w, nil
+}
+
+// serializeOptions controls how samples are converted to prompb.TimeSeries for a writer
+type serializeOptions struct {
+	precision string
+	value     types.ValueOptions
+	// the exemplars of the samples are sent, by the remote write writers only
+	exemplars bool
+}
+
+func (w Writer) serializeOptions() serializeOptions {
+	opts := serializeOptions{
+		precision: w.Opts.Precision,
+		value:     types.ValueOptions{Uint64As: w.Opts.Uint64As, BoolAs: w.Opts.BoolAs},
+		exemplars: w.isRemoteWrite() && !w.Opts.DropExemplars,
+	}
+	// normalize defaults, so writers with default options share one group
+	if opts.precision == "" {
+		opts.precision = config.Config.Global.Precision
+	}
+	if opts.value.Uint64As == "float" {
+		opts.value.Uint64As = ""
+	}
+	if opts.value.BoolAs == "number" {
+		opts.value.BoolAs = ""
+	}
+	return opts
 }
 
 func (w Writer) Write(items []prompb.TimeSeries) {
-	if len(items) == 0 {
+	if w.router == nil {
+		w.write(items, "")
 		return
 	}
 
-	req := &prompb.WriteRequest{
-		Timeseries: items,
+	items = w.router.route(items)
+	if w.router.tenant == nil {
+		w.write(items, "")
+		return
+	}
+	for tenant, series := range w.router.splitTenants(items) {
+		w.write(series, tenant)
 	}
+}
 
-	data, err := proto.Marshal(req)
+// write sends the series in a request, with the header of tenant if not empty
+func (w Writer) write(items []prompb.TimeSeries, tenant string) {
+	if len(items) == 0 {
+		return
+	}
+
+	corrected, shifted := w.correctTimeSkew(items)
+	data, err := w.serializer.Serialize(corrected)
 	if err != nil {
-		log.Println("W! marshal prom data to proto got error:", err, "data:", items)
+		writerLog.Warnf("serialize series of %v got error: %v data: %v", w.Opts.Url, err, items)
+		return
+	}
+
+	retry, err := w.send(data, tenant)
+	w.health.observe(err)
+	if err == nil {
 		return
 	}
 
-	if err := w.post(snappy.Encode(nil, data)); err != nil {
-		log.Println("W! post to", w.Opts.Url, "got error:", err)
-		log.Println("W! example timeseries:", items[0].String())
+	writerLog.Warnf("post to %v got error: %v", w.Opts.Url, err)
+	writerLog.Warnf("example timeseries: %v", items[0].String())
+
+	if !retry || w.spool == nil {
+		return
+	}
+
+	// spool the original timestamps in remote write, the skew is corrected again when resending,
+	// and the request is serialized in the format of the writer then
+	if shifted || !w.isRemoteWrite() {
+		if data, err = remotewrite.Encode(items); err != nil {
+			return
+		}
+	}
+	if err := w.spool.Put(data, tenant); err != nil {
+		writerLog.Errorf("failed to spool request of %v : %v", w.Opts.Url, err)
+	}
+}
+
+func (w Writer) isRemoteWrite() bool {
+	return w.Opts.Format == "" || w.Opts.Format == serializers.RemoteWrite
+}
+
+// loopReplay resends the spooled requests oldest first once the backend is reachable
+func (w Writer) loopReplay() {
+	for {
+		time.Sleep(spool.ReplayInterval)
+
+		for {
+			name, tenant, data, err := w.spool.Oldest()
+			if name == "" {
+				break
+			}
+			if err == nil {
+				data, err = w.spooledPayload(data)
+			}
+			if err != nil {
+				writerLog.Warnf("drop the broken spooled request %v of %v : %v", name, w.Opts.Url, err)
+				w.spool.Remove(name)
+				continue
+			}
+
+			retry, err := w.send(data, tenant)
+			w.health.observe(err)
+			if err != nil && retry {
+				break
+			}
+			if err != nil {
+				writerLog.Warnf("drop the spooled request %v rejected by %v : %v", name, w.Opts.Url, err)
+			}
+			w.spool.Remove(name)
+		}
+	}
+}
+
+// tenantHeader returns the header of tenants, the default one if the writer is not split to tenants any more,
+// e.g. the spool files of tenants are replayed after the tenant config is removed
+func (w Writer) tenantHeader() string {
+	if w.router == nil || w.router.tenant == nil {
+		return defaultTenantHeader
 	}
+	return w.router.tenant.Header
 }
 
-func (w Writer) post(req []byte) error {
+// post returns retry true if the request may succeed later, e.g. network errors and 5xx
+func (w Writer) post(req []byte, tenant string) (bool, error) {
 	httpReq, err := http.NewRequest("POST", w.Opts.Url, bytes.NewReader(req))
 	if err != nil {
-		log.Println("W! create remote write request got error:", err)
-		return err
+		writerLog.Warnf("create remote write request got error: %v", err)
+		return false, err
 	}
 
-	httpReq.Header.Add("Content-Encoding", "snappy")
-	httpReq.Header.Set("Content-Type", "application/x-protobuf")
+	for k, v := range w.serializer.Headers() {
+		httpReq.Header.Set(k, v)
+	}
 	httpReq.Header.Set("User-Agent", "categraf")
-	httpReq.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
 
 	for i := 0; i < len(w.Opts.Headers); i += 2 {
 		httpReq.Header.Add(w.Opts.Headers[i], w.Opts.Headers[i+1])
@@ -90,17 +248,23 @@ func (w Writer) post(req []byte) error {
 	if w.Opts.BasicAuthUser != "" {
 		httpReq.SetBasicAuth(w.Opts.BasicAuthUser, w.Opts.BasicAuthPass)
 	}
+	if tenant != "" {
+		httpReq.Header.Set(w.tenantHeader(), tenant)
+	}
+	signing.Sign(httpReq, req)
 
 	resp, body, err := w.Client.Do(context.Background(), httpReq)
 	if err != nil {
-		log.Println("W! push data with remote write request got error:", err, "response body:", string(body))
-		return err
+		writerLog.Warnf("push data with remote write request got error: %v response body: %v", err, string(body))
+		return true, err
 	}
 
+	w.measureTimeSkew(resp)
+
 	if resp.StatusCode >= 400 {
 		err = fmt.Errorf("push data with remote write request got status code: %v, response body: %s", resp.StatusCode, string(body))
-		return err
+		return resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests, err
 	}
 
-	return nil
+	return false, nil
 }
@@ -16,54 +16,195 @@ import (
 // Writers manage all writers and metric queue
 type Writers struct {
 	writerMap map[string]Writer
-	queue     *types.SafeListLimited[*prompb.TimeSeries]
+	groups    []*writerGroup
+}
+
+// writerGroup holds writers sharing the same serialization options,
+// samples are converted once per group
+type writerGroup struct {
+	opts    serializeOptions
+	writers []Writer
+	queue   *priorityQueue
+	// the pending batch is flushed without waiting for linger
+	flushCh chan struct{}
 }
 
 var writers Writers
 
 func InitWriters() error {
 	writerMap := map[string]Writer{}
+	groupMap := map[serializeOptions]*writerGroup{}
+	var groups []*writerGroup
+
 	opts := config.Config.Writers
+	ws := make([]Writer, 0, len(opts))
 	for _, opt := range opts {
 		writer, err := newWriter(opt)
 		if err != nil {
 			return err
 		}
-		writerMap[opt.Url] = writer
+		ws = append(ws, writer)
+	}
+	if err := linkRouters(ws); err != nil {
+		return err
+	}
+
+	for _, writer := range ws {
+		writerMap[writer.Opts.Url] = writer
+
+		serializeOpts := writer.serializeOptions()
+		group, has := groupMap[serializeOpts]
+		if !has {
+			group = &writerGroup{
+				opts:    serializeOpts,
+				queue:   newPriorityQueue(config.Config.WriterOpt.ChanSize),
+				flushCh: make(chan struct{}, 1),
+			}
+			groupMap[serializeOpts] = group
+			groups = append(groups, group)
+		}
+		group.writers = append(group.writers, writer)
 	}
 
 	writers = Writers{
 		writerMap: writerMap,
-		queue:     types.NewSafeListLimited[*prompb.TimeSeries](config.Config.WriterOpt.ChanSize),
+		groups:    groups,
+	}
+
+	initSeriesCache()
+	initCardinalityLimiter()
+	if err := initMetricRegistry(); err != nil {
+		return fmt.Errorf("metric_registry: %v", err)
+	}
+	if err := initFailureAlert(); err != nil {
+		return fmt.Errorf("writer_opt.failure_alert: %v", err)
 	}
 
-	go writers.LoopRead()
+	for _, group := range groups {
+		go group.LoopRead()
+	}
+	for _, w := range writerMap {
+		if w.spool != nil {
+			go w.loopReplay()
+		}
+	}
 	return nil
 }
 
-func (ws Writers) LoopRead() {
+func (g *writerGroup) LoopRead() {
+	var (
+		batch = config.Config.WriterOpt.Batch
+		// 0 is unlimited
+		maxBytes = config.Config.WriterOpt.BatchMaxBytes
+		linger   = time.Duration(config.Config.WriterOpt.Linger)
+
+		pending []prompb.TimeSeries
+		size    int
+		first   time.Time
+	)
+
+	flush := func() {
+		writeTimeSeries(g.writers, pending)
+		pending, size = nil, 0
+	}
+
 	for {
-		series := ws.queue.PopBackN(config.Config.WriterOpt.Batch)
-		if len(series) == 0 {
-			time.Sleep(time.Millisecond * 400)
+		n := batch - len(pending)
+		series := g.queue.PopBackN(n)
+		for i := 0; i < len(series); i++ {
+			if len(pending) == 0 {
+				first = time.Now()
+			}
+			pending = append(pending, *series[i])
+			size += series[i].Size()
+			if maxBytes > 0 && size >= maxBytes {
+				flush()
+			}
+		}
+
+		if len(pending) >= batch {
+			flush()
 			continue
 		}
 
-		items := make([]prompb.TimeSeries, len(series))
-		for i := 0; i < len(series); i++ {
-			items[i] = *series[i]
+		// more samples are queued, some were flushed by bytes
+		if len(series) == n {
+			continue
 		}
 
-		WriteTimeSeries(items)
+		wait := time.Millisecond * 400
+		if len(pending) > 0 {
+			left := linger - time.Since(first)
+			if left <= 0 {
+				flush()
+				continue
+			}
+			if left < wait {
+				wait = left
+			}
+		}
+
+		// the queue is drained, wait for more samples
+		select {
+		case <-time.After(wait):
+		case <-g.flushCh:
+			if len(pending) > 0 {
+				flush()
+			}
+		}
+	}
+}
+
+// FreeMemory drops the series cache and flushes the pending batches, e.g. when categraf is running out
+// of memory. The values admitted by cardinality_limit are kept, which are reset by the limiter itself only,
+// otherwise the explosion of tag values driving rss up would be admitted again on every check of the watchdog
+func FreeMemory() {
+	if cache != nil {
+		cache.Lock()
+		cache.series = make(map[string]*cachedSeries)
+		cache.Unlock()
+	}
+
+	for _, group := range writers.groups {
+		select {
+		case group.flushCh <- struct{}{}:
+		default:
+		}
 	}
 }
 
+func convertSamples(samples []*types.Sample, opts serializeOptions) []*prompb.TimeSeries {
+	items := make([]*prompb.TimeSeries, 0, len(samples))
+	for _, sample := range samples {
+		if sample == nil {
+			continue
+		}
+		item := sample.ConvertTimeSeriesWith(opts.precision, opts.value)
+		if item == nil || len(item.Labels) == 0 {
+			continue
+		}
+		if opts.exemplars {
+			if e, ok := sample.ConvertExemplar(opts.precision); ok {
+				item.Exemplars = append(item.Exemplars, e)
+			}
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
 // WriteSample convert sample to prompb.TimeSeries and write to queue
 // Note: Use WriteSamples for batch write for better performance
 func WriteSample(sample *types.Sample) {
-	if sample == nil {
+	if sample == nil || config.Config.Blocklist.Match(sample) {
 		return
 	}
+	if registry != nil && len(registry.apply([]*types.Sample{sample})) == 0 {
+		return
+	}
+	if limiter != nil {
+		limiter.apply([]*types.Sample{sample})
+	}
 	if config.Config.TestMode {
 		printTestMetric(sample)
 		return
@@ -72,18 +213,28 @@ func WriteSample(sample *types.Sample) {
 		printTestMetric(sample)
 	}
 
-	item := sample.ConvertTimeSeries(config.Config.Global.Precision)
-	if item == nil || len(item.Labels) == 0 {
-		return
-	}
-	writers.queue.PushFront(item)
+	pushSamples([]*types.Sample{sample}, config.PriorityNormal)
 }
 
 // WriteSamples convert samples to []prompb.TimeSeries and batch write to queue
 func WriteSamples(samples []*types.Sample) {
+	WriteSamplesWithPriority(samples, config.PriorityNormal)
+}
+
+// WriteSamplesWithPriority is WriteSamples, the samples of low priority are dropped first if the queue is full
+func WriteSamplesWithPriority(samples []*types.Sample, priority config.Priority) {
+	if len(config.Config.Blocklist) > 0 {
+		samples = filterSamples(samples)
+	}
+	if registry != nil {
+		samples = registry.apply(samples)
+	}
 	if len(samples) == 0 {
 		return
 	}
+	if limiter != nil {
+		limiter.apply(samples)
+	}
 	if config.Config.TestMode {
 		printTestMetrics(samples)
 		return
@@ -92,15 +243,55 @@ func WriteSamples(samples []*types.Sample) {
 		printTestMetrics(samples)
 	}
 
-	items := make([]*prompb.TimeSeries, 0, len(samples))
+	pushSamples(samples, priority)
+}
+
+// WriteSamplesNow writes samples to the writers synchronously without the queues, e.g. by categraf replay,
+// the samples are filtered by the global blocklist, but not limited
+func WriteSamplesNow(samples []*types.Sample) {
+	if len(config.Config.Blocklist) > 0 {
+		samples = filterSamples(samples)
+	}
+	if len(samples) == 0 {
+		return
+	}
+	if config.Config.TestMode {
+		printTestMetrics(samples)
+		return
+	}
+
+	for _, group := range writers.groups {
+		items := convertSamples(samples, group.opts)
+		timeSeries := make([]prompb.TimeSeries, len(items))
+		for i := range items {
+			timeSeries[i] = *items[i]
+		}
+		writeTimeSeries(group.writers, timeSeries)
+	}
+}
+
+// filterSamples drops samples matching the global blocklist,
+// a new slice is returned as samples may be released by callers
+func filterSamples(samples []*types.Sample) []*types.Sample {
+	ret := make([]*types.Sample, 0, len(samples))
 	for _, sample := range samples {
-		item := sample.ConvertTimeSeries(config.Config.Global.Precision)
-		if item == nil || len(item.Labels) == 0 {
+		if sample == nil || config.Config.Blocklist.Match(sample) {
 			continue
 		}
-		items = append(items, item)
+		ret = append(ret, sample)
+	}
+	return ret
+}
+
+func pushSamples(samples []*types.Sample, priority config.Priority) {
+	for _, group := range writers.groups {
+		group.queue.PushFrontN(convertSamples(samples, group.opts), priority)
+	}
+
+	// the exposed series always use the default serialization with ms timestamps
+	if cache != nil {
+		cache.put(convertSamples(samples, serializeOptions{precision: "ms"}))
 	}
-	writers.queue.PushFrontN(items)
 }
 
 // WriteTimeSeries write prompb.TimeSeries to all writers
@@ -109,13 +300,56 @@ func WriteTimeSeries(timeSeries []prompb.TimeSeries) {
 		return
 	}
 
+	all := make([]Writer, 0, len(writers.writerMap))
+	for _, w := range writers.writerMap {
+		all = append(all, w)
+	}
+	writeTimeSeries(all, timeSeries)
+}
+
+// QueueTimeSeries pushes prompb.TimeSeries to the queues of all writers instead of writing them synchronously,
+// false is returned and nothing is queued if any queue is full, so the retries of senders are not duplicated
+func QueueTimeSeries(timeSeries []prompb.TimeSeries) bool {
+	if len(timeSeries) == 0 {
+		return true
+	}
+
+	items := make([]*prompb.TimeSeries, len(timeSeries))
+	for i := range timeSeries {
+		items[i] = &timeSeries[i]
+	}
+
+	// the queues are locked in the order of groups, the others lock only one queue at a time
+	for _, group := range writers.groups {
+		group.queue.Lock()
+		defer group.queue.Unlock()
+	}
+
+	ok := true
+	for _, group := range writers.groups {
+		if !group.queue.fits(len(items), config.PriorityNormal) {
+			queueDroppedSamples.WithLabelValues(config.PriorityNormal.String()).Add(float64(len(items)))
+			ok = false
+		}
+	}
+	if !ok {
+		return false
+	}
+
+	for _, group := range writers.groups {
+		group.queue.pushFrontN(items, config.PriorityNormal)
+	}
+	return true
+}
+
+func writeTimeSeries(ws []Writer, timeSeries []prompb.TimeSeries) {
 	wg := sync.WaitGroup{}
-	for key := range writers.writerMap {
+	for i := range ws {
 		wg.Add(1)
-		go func(key string) {
+		go func(w Writer) {
 			defer wg.Done()
-			writers.writerMap[key].Write(timeSeries)
-		}(key)
+			w.Write(timeSeries)
+		}(ws[i])
 	}
 	wg.Wait()
 }